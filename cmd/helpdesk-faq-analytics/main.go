@@ -0,0 +1,142 @@
+// The purpose of this tool is to report on the questions stored in the
+// helpdesk FAQ over a time window: which topics are asked about the most,
+// and which questions keep being asked again.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/logrusutil"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	helpdeskfaq "github.com/openshift/ci-tools/pkg/helpdesk-faq"
+	"github.com/openshift/ci-tools/pkg/util"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+type options struct {
+	logLevel   string
+	window     time.Duration
+	similarity float64
+	top        int
+	output     string
+}
+
+func gatherOptions() (options, error) {
+	o := options{}
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.StringVar(&o.logLevel, "log-level", "info", "Level at which to log output.")
+	fs.DurationVar(&o.window, "window", 30*24*time.Hour, "How far back from now questions are reported on")
+	fs.Float64Var(&o.similarity, "similarity", 0.6, "Share of subject words two questions must have in common to be reported as duplicates, between 0 and 1")
+	fs.IntVar(&o.top, "top", 20, "Number of topics and duplicate groups to report, all of them when 0")
+	fs.StringVar(&o.output, "output", outputText, fmt.Sprintf("Format of the report, %s or %s", outputText, outputJSON))
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		return o, fmt.Errorf("failed to parse flags: %w", err)
+	}
+	return o, nil
+}
+
+func validateOptions(o options) error {
+	if _, err := logrus.ParseLevel(o.logLevel); err != nil {
+		return fmt.Errorf("invalid --log-level: %w", err)
+	}
+	if o.window <= 0 {
+		return fmt.Errorf("--window must be positive")
+	}
+	if o.similarity <= 0 || o.similarity > 1 {
+		return fmt.Errorf("--similarity must be greater than 0 and at most 1")
+	}
+	if o.top < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+	if o.output != outputText && o.output != outputJSON {
+		return fmt.Errorf("--output must be %s or %s", outputText, outputJSON)
+	}
+	return nil
+}
+
+func writeText(w io.Writer, report helpdeskfaq.Report) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "%d questions asked from %s to %s\n\n", report.Questions, report.Start.Format(time.RFC3339), report.End.Format(time.RFC3339))
+	fmt.Fprintln(tw, "TOPIC\tQUESTIONS")
+	for _, topic := range report.Topics {
+		name := topic.Topic
+		if name == "" {
+			name = "(no topic)"
+		}
+		fmt.Fprintf(tw, "%s\t%d\n", name, topic.Count)
+	}
+	fmt.Fprintln(tw, "\nTIMES ASKED\tTOPIC\tSUBJECT")
+	for _, duplicate := range report.Duplicates {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", duplicate.Count, duplicate.Topic, duplicate.Subject)
+	}
+	return tw.Flush()
+}
+
+func main() {
+	logrusutil.ComponentInit()
+	o, err := gatherOptions()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed go gather options")
+	}
+	if err := validateOptions(o); err != nil {
+		logrus.WithError(err).Fatal("invalid options")
+	}
+	level, _ := logrus.ParseLevel(o.logLevel)
+	logrus.SetLevel(level)
+
+	clusterConfig, err := util.LoadClusterConfig()
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load cluster config")
+	}
+	kubeClient, err := ctrlruntimeclient.New(clusterConfig, ctrlruntimeclient.Options{})
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to create client")
+	}
+	client := helpdeskfaq.NewCMClient(kubeClient)
+	serialized, err := client.GetSerializedFAQItems()
+	if err != nil {
+		logrus.WithError(err).Fatal("unable to get helpdesk-faq items")
+	}
+	var items []helpdeskfaq.FaqItem
+	for _, raw := range serialized {
+		item := helpdeskfaq.FaqItem{}
+		if err := json.Unmarshal([]byte(raw), &item); err != nil {
+			logrus.WithError(err).Fatal("unable to unmarshall faq item")
+		}
+		items = append(items, item)
+	}
+
+	end := time.Now().UTC()
+	report := helpdeskfaq.NewReport(items, end.Add(-o.window), end, o.similarity)
+	if o.top > 0 && len(report.Topics) > o.top {
+		report.Topics = report.Topics[:o.top]
+	}
+	if o.top > 0 && len(report.Duplicates) > o.top {
+		report.Duplicates = report.Duplicates[:o.top]
+	}
+
+	switch o.output {
+	case outputJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	default:
+		err = writeText(os.Stdout, report)
+	}
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to write the report")
+	}
+}
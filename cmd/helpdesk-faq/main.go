@@ -32,6 +32,11 @@ type Page struct {
 	Data []helpdeskfaq.FaqItem `json:"data"`
 }
 
+// TopicPage lists the items as a tree of their topics
+type TopicPage struct {
+	Data []*helpdeskfaq.TopicNode `json:"data"`
+}
+
 func gatherOptions() (options, error) {
 	o := options{}
 	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
@@ -82,31 +87,49 @@ func router(client helpdeskfaq.FaqItemClient) *http.ServeMux {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
+			faqItem.SortAnswers()
 			page.Data = append(page.Data, *faqItem)
 		}
 
-		if callbackName := r.URL.Query().Get("callback"); callbackName != "" {
-			bytes, err := json.Marshal(page)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-			w.Header().Set("Content-Type", "application/javascript")
-			template.JSEscape(w, []byte(callbackName))
-			if n, err := fmt.Fprintf(w, "(%s);", string(bytes)); err != nil {
-				logrus.WithError(err).WithField("n", n).Error("failed to write content")
-			}
-		} else {
-			w.Header().Set("Content-Type", "application/json")
-			if err := json.NewEncoder(w).Encode(page); err != nil {
-				logrus.WithError(err).WithField("page", page).Error("failed to encode page")
-			}
+		writePage(w, r, page)
+	})
+
+	handler.HandleFunc("/api/v1/faq-topics", func(w http.ResponseWriter, r *http.Request) {
+		logrus.WithField("path", "/api/v1/faq-topics").Info("serving")
+
+		topics, err := client.GetFAQTopicTree()
+		if err != nil {
+			logrus.WithError(err).Error("unable to get helpdesk-faq topics")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
+		writePage(w, r, TopicPage{Data: topics})
 	})
 
 	return handler
 }
 
+// writePage serves the page as JSON, or as JSONP when a callback is requested
+func writePage(w http.ResponseWriter, r *http.Request, page interface{}) {
+	if callbackName := r.URL.Query().Get("callback"); callbackName != "" {
+		bytes, err := json.Marshal(page)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/javascript")
+		template.JSEscape(w, []byte(callbackName))
+		if n, err := fmt.Fprintf(w, "(%s);", string(bytes)); err != nil {
+			logrus.WithError(err).WithField("n", n).Error("failed to write content")
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(page); err != nil {
+			logrus.WithError(err).WithField("page", page).Error("failed to encode page")
+		}
+	}
+}
+
 func main() {
 	logrusutil.ComponentInit()
 	o, err := gatherOptions()
@@ -40,6 +40,7 @@ import (
 	eventrouter "github.com/openshift/ci-tools/pkg/slack/events/router"
 	interactionhandler "github.com/openshift/ci-tools/pkg/slack/interactions"
 	interactionrouter "github.com/openshift/ci-tools/pkg/slack/interactions/router"
+	"github.com/openshift/ci-tools/pkg/slack/users"
 	"github.com/openshift/ci-tools/pkg/util"
 )
 
@@ -60,6 +61,7 @@ type options struct {
 	helpdeskAlias           string
 	forumChannelId          string
 	requireWorkflowsInForum bool
+	faqConfigPath           string
 }
 
 func (o *options) Validate() error {
@@ -104,6 +106,7 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.helpdeskAlias, "helpdesk-alias", "@dptp-helpdesk", "Alias for helpdesk user(s) beginning with '@'")
 	fs.StringVar(&o.forumChannelId, "forum-channel-id", "CBN38N3MW", "Channel ID for #forum-ocp-testplatform")
 	fs.BoolVar(&o.requireWorkflowsInForum, "require-workflows-in-forum", true, "Require the use of workflows in the designated forum channel")
+	fs.StringVar(&o.faqConfigPath, "faq-config-path", "", "Path to the helpdesk FAQ handler config file, reloaded whenever it changes. Defaults apply when unset.")
 
 	if err := fs.Parse(args); err != nil {
 		logrus.WithError(err).Fatal("Could not parse args.")
@@ -181,6 +184,15 @@ func main() {
 		}
 	}
 
+	faqConfigAgent, err := helpdesk.NewFAQConfigAgent(o.faqConfigPath, helpdesk.DefaultFAQConfig(o.forumChannelId, o.helpdeskAlias), users.NewResolver(slackClient), kubeClient)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not load helpdesk FAQ config.")
+	}
+	if err := faqConfigAgent.Start(); err != nil {
+		logrus.WithError(err).Fatal("Could not watch helpdesk FAQ config.")
+	}
+	faqEventBuffer := helpdesk.NewFAQEventBuffer(kubeClient)
+
 	metrics.ExposeMetrics("slack-bot", config.PushGateway{}, o.instrumentationOptions.MetricsPort)
 	simplifier := simplifypath.NewSimplifier(l("", // shadow element mimicing the root
 		l(""), // for black-box health checks
@@ -198,8 +210,12 @@ func main() {
 	// handle the root to allow for a simple uptime probe
 	mux.Handle("/", handler(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) { writer.WriteHeader(http.StatusOK) })))
 	mux.Handle("/slack/interactive-endpoint", handler(handleInteraction(secret.GetTokenGenerator(o.slackSigningSecretPath), interactionrouter.ForModals(issueFiler, slackClient))))
-	mux.Handle("/slack/events-endpoint", handler(handleEvent(secret.GetTokenGenerator(o.slackSigningSecretPath), eventrouter.ForEvents(slackClient, kubeClient, configAgent.Config, gcsClient, keywordsConfig, o.helpdeskAlias, o.forumChannelId, o.requireWorkflowsInForum))))
+	mux.Handle("/slack/events-endpoint", handler(handleEvent(secret.GetTokenGenerator(o.slackSigningSecretPath), eventrouter.ForEvents(slackClient, kubeClient, configAgent.Config, gcsClient, keywordsConfig, o.helpdeskAlias, o.forumChannelId, o.requireWorkflowsInForum, faqConfigAgent, faqEventBuffer))))
 	server := &http.Server{Addr: ":" + strconv.Itoa(o.port), Handler: mux}
+	// the handlers have to exist before the events left pending by a previous run are replayed
+	if err := faqEventBuffer.Start(); err != nil {
+		logrus.WithError(err).Fatal("Could not load the helpdesk FAQ event buffer.")
+	}
 
 	health.ServeReady()
 
@@ -0,0 +1,144 @@
+package helpdesk_faq
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var subjectWordRegex = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// stopWords don't tell questions apart, they are ignored when comparing subjects
+var stopWords = sets.New[string]("a", "an", "and", "are", "can", "do", "does", "for", "how", "i", "in", "is", "it", "my", "of", "on", "or", "the", "to", "what", "when", "why", "with")
+
+// Report summarizes the questions asked in a time window
+type Report struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Questions int       `json:"questions"`
+	// Topics are the topics questions were asked about, the most asked first
+	Topics []TopicCount `json:"topics"`
+	// Duplicates are the groups of questions with near-identical subjects, the largest first
+	Duplicates []DuplicateCluster `json:"duplicates"`
+}
+
+type TopicCount struct {
+	Topic string `json:"topic"`
+	Count int    `json:"count"`
+}
+
+// DuplicateCluster is a group of questions whose subjects are alike
+type DuplicateCluster struct {
+	// Subject is the subject of the first question asked
+	Subject string `json:"subject"`
+	Topic   string `json:"topic"`
+	Count   int    `json:"count"`
+	// Timestamps identify the questions, oldest first
+	Timestamps []string `json:"timestamps"`
+}
+
+// QuestionTime returns when the question was asked, from the Slack timestamp of its message
+func QuestionTime(item FaqItem) (time.Time, error) {
+	seconds, _, _ := strings.Cut(item.Timestamp, ".")
+	unix, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: %w", item.Timestamp, err)
+	}
+	return time.Unix(unix, 0).UTC(), nil
+}
+
+// NewReport counts the questions asked in [start, end) per topic and clusters the ones whose subjects have a
+// similarity of at least threshold, between 0 and 1.  Items with an invalid timestamp are skipped.
+func NewReport(items []FaqItem, start, end time.Time, threshold float64) Report {
+	report := Report{Start: start, End: end, Topics: []TopicCount{}, Duplicates: []DuplicateCluster{}}
+
+	var inWindow []FaqItem
+	for _, item := range items {
+		asked, err := QuestionTime(item)
+		if err != nil || asked.Before(start) || !asked.Before(end) {
+			continue
+		}
+		inWindow = append(inWindow, item)
+	}
+	sort.Slice(inWindow, func(i, j int) bool {
+		return inWindow[i].Timestamp < inWindow[j].Timestamp
+	})
+	report.Questions = len(inWindow)
+
+	counts := map[string]int{}
+	for _, item := range inWindow {
+		counts[item.Question.Topic]++
+	}
+	for topic, count := range counts {
+		report.Topics = append(report.Topics, TopicCount{Topic: topic, Count: count})
+	}
+	sort.Slice(report.Topics, func(i, j int) bool {
+		if report.Topics[i].Count != report.Topics[j].Count {
+			return report.Topics[i].Count > report.Topics[j].Count
+		}
+		return report.Topics[i].Topic < report.Topics[j].Topic
+	})
+
+	for _, cluster := range clusterSubjects(inWindow, threshold) {
+		if cluster.Count > 1 {
+			report.Duplicates = append(report.Duplicates, cluster)
+		}
+	}
+	sort.SliceStable(report.Duplicates, func(i, j int) bool {
+		return report.Duplicates[i].Count > report.Duplicates[j].Count
+	})
+	return report
+}
+
+// clusterSubjects adds every question to the first cluster whose first subject is alike, or starts a new cluster.
+// Comparing against the first subject only keeps a chain of slightly different subjects from drifting apart.
+func clusterSubjects(items []FaqItem, threshold float64) []DuplicateCluster {
+	var clusters []DuplicateCluster
+	var clusterWords []sets.Set[string]
+	for _, item := range items {
+		words := subjectWords(item.Question.Subject)
+		found := false
+		for i := range clusters {
+			if similarity(words, clusterWords[i]) >= threshold {
+				clusters[i].Count++
+				clusters[i].Timestamps = append(clusters[i].Timestamps, item.Timestamp)
+				found = true
+				break
+			}
+		}
+		if !found {
+			clusters = append(clusters, DuplicateCluster{
+				Subject:    item.Question.Subject,
+				Topic:      item.Question.Topic,
+				Count:      1,
+				Timestamps: []string{item.Timestamp},
+			})
+			clusterWords = append(clusterWords, words)
+		}
+	}
+	return clusters
+}
+
+func subjectWords(subject string) sets.Set[string] {
+	words := sets.New[string]()
+	for _, word := range subjectWordRegex.FindAllString(strings.ToLower(subject), -1) {
+		if !stopWords.Has(word) {
+			words.Insert(word)
+		}
+	}
+	return words
+}
+
+// similarity is the Jaccard index of the words of two subjects
+func similarity(a, b sets.Set[string]) float64 {
+	union := a.Union(b).Len()
+	if union == 0 {
+		return 0
+	}
+	return float64(a.Intersection(b).Len()) / float64(union)
+}
@@ -0,0 +1,49 @@
+package helpdesk_faq
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewReport(t *testing.T) {
+	start := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	item := func(day int, topic, subject string) FaqItem {
+		asked := start.Add(time.Duration(day) * 24 * time.Hour).Unix()
+		return FaqItem{
+			Timestamp: strconv.FormatInt(asked, 10) + ".000100",
+			Question:  Question{Topic: topic, Subject: subject},
+		}
+	}
+	items := []FaqItem{
+		item(-1, "prow", "How do I retest my job?"),
+		item(0, "prow", "How do I retest a job"),
+		item(1, "ci-operator", "Image build fails with out of memory"),
+		item(2, "prow", "Retest my job"),
+		item(3, "ci-operator/images", "Image build fails out of memory"),
+		item(4, "boskos", "Quota leaks in aws"),
+		item(7, "prow", "How do I retest my job?"),
+		{Timestamp: "not-a-timestamp", Question: Question{Topic: "prow", Subject: "Retest my job"}},
+	}
+
+	expected := Report{
+		Start:     start,
+		End:       start.Add(7 * 24 * time.Hour),
+		Questions: 5,
+		Topics: []TopicCount{
+			{Topic: "prow", Count: 2},
+			{Topic: "boskos", Count: 1},
+			{Topic: "ci-operator", Count: 1},
+			{Topic: "ci-operator/images", Count: 1},
+		},
+		Duplicates: []DuplicateCluster{
+			{Subject: "How do I retest a job", Topic: "prow", Count: 2, Timestamps: []string{items[1].Timestamp, items[3].Timestamp}},
+			{Subject: "Image build fails with out of memory", Topic: "ci-operator", Count: 2, Timestamps: []string{items[2].Timestamp, items[4].Timestamp}},
+		},
+	}
+	if diff := cmp.Diff(expected, NewReport(items, expected.Start, expected.End, 0.6)); diff != "" {
+		t.Fatalf("report doesn't match expected, diff: %s", diff)
+	}
+}
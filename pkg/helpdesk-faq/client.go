@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -20,6 +21,8 @@ const (
 
 type FaqItemClient interface {
 	GetSerializedFAQItems() ([]string, error)
+	// GetFAQTopicTree returns the items filed under the hierarchy of their topics
+	GetFAQTopicTree() ([]*TopicNode, error)
 	GetFAQItemIfExists(timestamp string) (*FaqItem, error)
 	UpsertItem(item FaqItem) error
 	RemoveItem(timestamp string) error
@@ -58,6 +61,27 @@ func (c *ConfigMapClient) GetSerializedFAQItems() ([]string, error) {
 	return items, nil
 }
 
+func (c *ConfigMapClient) GetFAQTopicTree() ([]*TopicNode, error) {
+	serialized, err := c.GetSerializedFAQItems()
+	if err != nil {
+		return nil, err
+	}
+	var items []FaqItem
+	for _, rawFaqItem := range serialized {
+		faqItem := FaqItem{}
+		if err := json.Unmarshal([]byte(rawFaqItem), &faqItem); err != nil {
+			return nil, fmt.Errorf("unable to unmarshall faqItem: %w", err)
+		}
+		faqItem.SortAnswers()
+		items = append(items, faqItem)
+	}
+	// the ConfigMap data doesn't keep the items in any order, list the oldest questions first in every topic
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Timestamp < items[j].Timestamp
+	})
+	return BuildTopicTree(items), nil
+}
+
 func (c *ConfigMapClient) GetFAQItemIfExists(timestamp string) (*FaqItem, error) {
 	configMap, err := c.getConfigMap()
 	if err != nil {
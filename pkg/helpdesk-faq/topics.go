@@ -0,0 +1,72 @@
+package helpdesk_faq
+
+import (
+	"sort"
+	"strings"
+)
+
+// TopicSeparator separates the levels of nested topics, like ci-operator/images
+const TopicSeparator = "/"
+
+// SplitTopic returns the levels of the topic from the outermost one, ignoring blanks around the separators
+func SplitTopic(topic string) []string {
+	var levels []string
+	for _, level := range strings.Split(topic, TopicSeparator) {
+		if level = strings.TrimSpace(level); level != "" {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// TopicNode is a level of the topic hierarchy, holding the items filed under exactly that topic
+type TopicNode struct {
+	Name string `json:"name"`
+	// Topic is the full path of the node, e.g. ci-operator/images
+	Topic    string       `json:"topic"`
+	Items    []FaqItem    `json:"items,omitempty"`
+	Children []*TopicNode `json:"children,omitempty"`
+}
+
+// BuildTopicTree files the items under the hierarchy of their topics. Nodes are sorted by name, and items keep
+// the order they were given in.
+func BuildTopicTree(items []FaqItem) []*TopicNode {
+	root := &TopicNode{}
+	for _, item := range items {
+		node := root
+		for _, level := range SplitTopic(item.Question.Topic) {
+			node = node.child(level)
+		}
+		node.Items = append(node.Items, item)
+	}
+	root.sort()
+	if len(root.Items) > 0 {
+		// items without a topic are listed last, under a node without a name
+		return append(root.Children, &TopicNode{Items: root.Items})
+	}
+	return root.Children
+}
+
+func (n *TopicNode) child(name string) *TopicNode {
+	for _, child := range n.Children {
+		if strings.EqualFold(child.Name, name) {
+			return child
+		}
+	}
+	topic := name
+	if n.Topic != "" {
+		topic = n.Topic + TopicSeparator + name
+	}
+	child := &TopicNode{Name: name, Topic: topic}
+	n.Children = append(n.Children, child)
+	return child
+}
+
+func (n *TopicNode) sort() {
+	sort.Slice(n.Children, func(i, j int) bool {
+		return strings.ToLower(n.Children[i].Name) < strings.ToLower(n.Children[j].Name)
+	})
+	for _, child := range n.Children {
+		child.sort()
+	}
+}
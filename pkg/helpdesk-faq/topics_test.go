@@ -0,0 +1,57 @@
+package helpdesk_faq
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildTopicTree(t *testing.T) {
+	item := func(timestamp, topic string) FaqItem {
+		return FaqItem{Timestamp: timestamp, Question: Question{Topic: topic}}
+	}
+	testCases := []struct {
+		name     string
+		items    []FaqItem
+		expected []*TopicNode
+	}{
+		{
+			name:  "flat topics",
+			items: []FaqItem{item("1", "Prow"), item("2", "Boskos"), item("3", "Prow")},
+			expected: []*TopicNode{
+				{Name: "Boskos", Topic: "Boskos", Items: []FaqItem{item("2", "Boskos")}},
+				{Name: "Prow", Topic: "Prow", Items: []FaqItem{item("1", "Prow"), item("3", "Prow")}},
+			},
+		},
+		{
+			name:  "nested topics share their parent",
+			items: []FaqItem{item("1", "ci-operator/tests"), item("2", "ci-operator"), item("3", "ci-operator/images")},
+			expected: []*TopicNode{
+				{
+					Name:  "ci-operator",
+					Topic: "ci-operator",
+					Items: []FaqItem{item("2", "ci-operator")},
+					Children: []*TopicNode{
+						{Name: "images", Topic: "ci-operator/images", Items: []FaqItem{item("3", "ci-operator/images")}},
+						{Name: "tests", Topic: "ci-operator/tests", Items: []FaqItem{item("1", "ci-operator/tests")}},
+					},
+				},
+			},
+		},
+		{
+			name:  "items without a topic are listed last",
+			items: []FaqItem{item("1", ""), item("2", "Prow")},
+			expected: []*TopicNode{
+				{Name: "Prow", Topic: "Prow", Items: []FaqItem{item("2", "Prow")}},
+				{Items: []FaqItem{item("1", "")}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, BuildTopicTree(tc.items)); diff != "" {
+				t.Fatalf("topic tree doesn't match expected, diff: %s", diff)
+			}
+		})
+	}
+}
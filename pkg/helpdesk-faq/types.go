@@ -1,9 +1,13 @@
 package helpdesk_faq
 
+import "sort"
+
 type FaqItem struct {
 	Question  Question `json:"question"`
 	Timestamp string   `json:"timestamp"`
 	Answers   []Answer `json:"answers"`
+	// DocLinks point at the canonical documentation of the question, they were checked to be reachable when added
+	DocLinks []DocLink `json:"docLinks,omitempty"`
 }
 
 type Question struct {
@@ -17,6 +21,33 @@ type Answer struct {
 	Author    string `json:"author"`
 	Timestamp string `json:"timestamp"`
 	Body      string `json:"body"`
+	// Attribution is empty for answers marked before attribution existed, or when the rotation couldn't be determined
+	Attribution AnswerAttribution `json:"attribution,omitempty"`
+}
+
+// DocLink is a documentation page a curator attached to the item
+type DocLink struct {
+	URL string `json:"url"`
+	// Author is the curator who attached the link
+	Author string `json:"author"`
+	// Timestamp is the one of the message the link was taken from
+	Timestamp string `json:"timestamp"`
+}
+
+type AnswerAttribution string
+
+const (
+	// AnswerAttributionHelpdesk is an official answer, given by a member of the helpdesk rotation
+	AnswerAttributionHelpdesk AnswerAttribution = "helpdesk"
+	// AnswerAttributionCommunity is an answer given by anyone else
+	AnswerAttributionCommunity AnswerAttribution = "community"
+)
+
+// SortAnswers moves the official helpdesk answers first, keeping the order the answers were given in otherwise
+func (i *FaqItem) SortAnswers() {
+	sort.SliceStable(i.Answers, func(a, b int) bool {
+		return i.Answers[a].Attribution == AnswerAttributionHelpdesk && i.Answers[b].Attribution != AnswerAttributionHelpdesk
+	})
 }
 
 //TODO(sgoeddel): We probably need a "contributing info" emoji and section as well for when the question isn't entirely summarized in one prompt
@@ -1,12 +1,15 @@
 package jobrunaggregator
 
 import (
+	"fmt"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatoranalyzer"
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunbigqueryloader"
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunhistoricaldataanalyzer"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobruntablecreator"
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobruntestcaseanalyzer"
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobtableprimer"
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/releasebigqueryloader"
@@ -14,8 +17,11 @@ import (
 
 func NewJobAggregatorCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:  "job-run-aggregator",
-		Long: `Commands associated with CI job run aggregation`,
+		Use: "job-run-aggregator",
+		Long: `Commands associated with CI job run aggregation.
+
+Shell completions, including the values of flags like --platform or --payload-tag, are generated by the
+completion command for bash, zsh and fish.`,
 	}
 
 	// Add some millisecond precision to log timestamps, useful for debugging performance.
@@ -26,13 +32,29 @@ func NewJobAggregatorCommand() *cobra.Command {
 	log.SetFormatter(formatter)
 	log.SetLevel(log.DebugLevel)
 
+	logLevel := log.DebugLevel.String()
+	cmd.PersistentFlags().StringVar(&logLevel, "log-level", logLevel, "Level at which to log output (trace,debug,info,warn,error)")
+	cmd.PersistentPreRunE = func(*cobra.Command, []string) error {
+		level, err := log.ParseLevel(logLevel)
+		if err != nil {
+			return fmt.Errorf("invalid --log-level: %w", err)
+		}
+		log.SetLevel(level)
+		return nil
+	}
+
 	cmd.AddCommand(jobrunbigqueryloader.NewBigQueryDisruptionUploadFlagsCommand())
 	cmd.AddCommand(jobrunbigqueryloader.NewBigQueryAlertUploadFlagsCommand())
+	cmd.AddCommand(jobrunbigqueryloader.NewDisruptionValidateCommand())
 	cmd.AddCommand(jobrunaggregatoranalyzer.NewJobRunsAnalyzerCommand())
+	cmd.AddCommand(jobrunaggregatoranalyzer.NewJobRunsRenderCommand())
 	cmd.AddCommand(jobtableprimer.NewPrimeJobTableCommand())
+	cmd.AddCommand(jobruntablecreator.NewTableCreateCommand())
+	cmd.AddCommand(jobruntablecreator.NewSchemaVerifyCommand())
 
 	cmd.AddCommand(releasebigqueryloader.NewBigQueryReleaseTableCreateFlagsCommand())
 	cmd.AddCommand(releasebigqueryloader.NewBigQueryReleaseUploadFlagsCommand())
+	cmd.AddCommand(releasebigqueryloader.NewAcceptanceHistoryCommand())
 
 	cmd.AddCommand(jobruntestcaseanalyzer.NewJobRunsTestCaseAnalyzerCommand())
 
@@ -2,7 +2,6 @@ package jobrunaggregatoranalyzer
 
 import (
 	"context"
-	"encoding/xml"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -46,22 +45,37 @@ type JobRunAggregatorAnalyzerOptions struct {
 	jobStateQuerySource string
 	prowJobMatcherFunc  jobrunaggregatorlib.ProwJobMatcherFunc
 
+	// adaptiveWait uses ciDataClient to look up how long the job usually takes, instead of always waiting until the timeout
+	ciDataClient jobrunaggregatorlib.CIDataClient
+	adaptiveWait bool
+
 	staticJobRunIdentifiers []jobrunaggregatorlib.JobRunIdentifier
 	gcsBucket               string
+
+	// gateOverride, when set, force-accepts failed aggregated tests.  Every override is recorded with gateOverrideInserter.
+	gateOverride         *jobrunaggregatorlib.GateOverride
+	gateOverrideInserter jobrunaggregatorlib.BigQueryInserter
+	// gateResultInserter keeps the verdict of every aggregated test, it is nil when results aren't retained
+	gateResultInserter jobrunaggregatorlib.BigQueryInserter
+
+	// testOwners names the component responsible for failed tests
+	testOwners *jobrunaggregatorlib.TestOwners
+	// testRenames gives renamed tests their current name, so they are compared against their full history
+	testRenames *jobrunaggregatorlib.TestRenames
+
+	// notifier is told the verdict, it is nil when no notification target is configured
+	notifier jobrunaggregatorlib.Notifier
 }
 
 func (o *JobRunAggregatorAnalyzerOptions) loadStaticJobRuns(ctx context.Context) ([]jobrunaggregatorapi.JobRunInfo, error) {
-	var jobRuns []jobrunaggregatorapi.JobRunInfo
+	jobRunIDs := make([]string, 0, len(o.staticJobRunIdentifiers))
 	for _, job := range o.staticJobRunIdentifiers {
-		jobRun, err := o.jobRunLocator.FindJob(ctx, job.JobRunID)
-		if err != nil {
-			// Do not fail when one job fetch fails
-			logrus.WithError(err).Errorf("error finding job %s", job.JobRunID)
-			continue
-		}
-		if jobRun != nil {
-			jobRuns = append(jobRuns, jobRun)
-		}
+		jobRunIDs = append(jobRunIDs, job.JobRunID)
+	}
+	jobRuns, err := o.jobRunLocator.FindJobs(ctx, jobRunIDs)
+	if err != nil {
+		// Do not fail when some job fetches fail
+		logrus.WithError(err).Error("error finding jobs")
 	}
 	return jobRuns, nil
 }
@@ -137,7 +151,16 @@ func (o *JobRunAggregatorAnalyzerOptions) Run(ctx context.Context) error {
 
 	var jobRunWaiter jobrunaggregatorlib.JobRunWaiter
 	if o.jobStateQuerySource == jobrunaggregatorlib.JobStateQuerySourceBigQuery || o.prowJobClient == nil {
-		jobRunWaiter = &jobrunaggregatorlib.BigQueryJobRunWaiter{JobRunGetter: o, TimeToStopWaiting: timeToStopWaiting}
+		bigQueryJobRunWaiter := &jobrunaggregatorlib.BigQueryJobRunWaiter{JobRunGetter: o, TimeToStopWaiting: timeToStopWaiting}
+		if o.adaptiveWait {
+			// long jobs may wait past the usual cap, but never past the timeout of the command
+			bigQueryJobRunWaiter.AdaptiveWait = &jobrunaggregatorlib.AdaptiveWait{
+				CIDataClient:        o.ciDataClient,
+				JobRunStartEstimate: o.jobRunStartEstimate,
+				Latest:              o.jobRunStartEstimate.Add(o.timeout - 20*time.Minute),
+			}
+		}
+		jobRunWaiter = bigQueryJobRunWaiter
 	} else {
 		jobRunWaiter = &jobrunaggregatorlib.ClusterJobRunWaiter{
 			ProwJobClient:      o.prowJobClient,
@@ -178,6 +201,7 @@ func (o *JobRunAggregatorAnalyzerOptions) Run(ctx context.Context) error {
 	}
 
 	currentAggregationJunit := &aggregatedJobRunJunit{
+		gcsBucket:        o.gcsBucket,
 		jobGCSBucketRoot: filepath.Join("logs", o.jobName),
 	}
 	if len(o.explicitGCSPrefix) > 0 {
@@ -203,7 +227,7 @@ func (o *JobRunAggregatorAnalyzerOptions) Run(ctx context.Context) error {
 			// get the flag to see if masternodes have been updated
 			clusterData, err := jobRun.GetOpenShiftTestsFilesWithPrefix(ctx, "cluster-data")
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Could not fetch cluster data for %s - %v\n", jobRun.GetJobRunID(), err)
+				logrus.WithError(err).WithFields(logrus.Fields{"job": jobRun.GetJobName(), "jobRunID": jobRun.GetJobRunID()}).Error("Could not fetch cluster data")
 			}
 			updatedFlag := jobrunaggregatorlib.GetMasterNodesUpdatedStatusFromClusterData(clusterData)
 
@@ -218,6 +242,7 @@ func (o *JobRunAggregatorAnalyzerOptions) Run(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+		o.testRenames.RenameTestCases(currJunit.combinedJunit)
 		prowJob, err := currJunit.jobRun.GetProwJob(ctx)
 		if err != nil {
 			return err
@@ -265,11 +290,24 @@ func (o *JobRunAggregatorAnalyzerOptions) Run(ctx context.Context) error {
 	// TODO this is the spot where we would add an alertSuite that aggregates the alerts firing in our clusters to prevent
 	//  allowing more and more failing alerts through just because one fails.
 
-	currentAggrationJunitXML, err := xml.Marshal(currentAggregationJunitSuites)
-	if err != nil {
+	if err := o.applyGateOverride(ctx, &junit.TestSuite{Children: currentAggregationJunitSuites.Suites}); err != nil {
+		return err
+	}
+	o.testOwners.AnnotateFailures(&junit.TestSuite{Children: currentAggregationJunitSuites.Suites})
+	o.recordGateResults(ctx, &junit.TestSuite{Children: currentAggregationJunitSuites.Suites})
+
+	// save the state first so that the outputs can be regenerated with the render command later
+	state := &analysisState{
+		JobName:        o.jobName,
+		PayloadTag:     o.payloadTag,
+		AggregatedTime: o.clock.Now(),
+		Configuration:  aggregationConfiguration,
+		Suites:         currentAggregationJunitSuites,
+	}
+	if err := writeAnalysisState(state, currentAggregationDir); err != nil {
 		return err
 	}
-	if err := os.WriteFile(filepath.Join(currentAggregationDir, "junit-aggregated.xml"), currentAggrationJunitXML, 0644); err != nil {
+	if err := writeRenderedOutputs(state, currentAggregationDir, o.workingDir); err != nil {
 		return err
 	}
 
@@ -279,9 +317,18 @@ func (o *JobRunAggregatorAnalyzerOptions) Run(ctx context.Context) error {
 	fakeSuite := &junit.TestSuite{Children: currentAggregationJunitSuites.Suites}
 	jobrunaggregatorlib.OutputTestCaseFailures([]string{"root"}, fakeSuite)
 
-	summaryHTML := htmlForTestRuns(o.jobName, fakeSuite)
-	if err := os.WriteFile(filepath.Join(o.workingDir, "aggregation-testrun-summary.html"), []byte(summaryHTML), 0644); err != nil {
-		return err
+	if o.notifier != nil {
+		// notifications are informational, failing to send them must not change the verdict
+		if err := o.notifier.Notify(ctx, &jobrunaggregatorlib.PayloadVerdict{
+			Analyzer:     "analyze-job-runs",
+			JobName:      o.jobName,
+			PayloadTag:   o.payloadTag,
+			AnalyzedTime: state.AggregatedTime,
+			Passed:       !hasFailedTestCase(fakeSuite),
+			Tests:        jobrunaggregatorlib.PayloadTestResultsFromSuite(fakeSuite),
+		}); err != nil {
+			alog.WithError(err).Warn("failed to notify the verdict")
+		}
 	}
 
 	if hasFailedTestCase(fakeSuite) {
@@ -292,6 +339,47 @@ func (o *JobRunAggregatorAnalyzerOptions) Run(ctx context.Context) error {
 	return nil
 }
 
+// applyGateOverride force-accepts failed aggregated tests when an override was supplied and records who did it and
+// what the original verdict was.  An override that can't be recorded must not take effect, so failing to store the
+// audit rows fails the aggregation.
+func (o *JobRunAggregatorAnalyzerOptions) applyGateOverride(ctx context.Context, suite *junit.TestSuite) error {
+	if o.gateOverride == nil {
+		return nil
+	}
+	rows := jobrunaggregatorlib.ApplyGateOverride(o.gateOverride, o.jobName, o.payloadTag, suite)
+	if len(rows) == 0 {
+		logrus.WithField("issuedBy", o.gateOverride.IssuedBy).Info("gate override supplied, but no failed tests matched")
+		return nil
+	}
+	if o.gateOverrideInserter == nil {
+		return fmt.Errorf("gate override issued by %s cannot be recorded, so it is not applied", o.gateOverride.IssuedBy)
+	}
+	if err := o.gateOverrideInserter.Put(ctx, rows); err != nil {
+		return fmt.Errorf("failed to record the gate override issued by %s, so it is not applied: %w", o.gateOverride.IssuedBy, err)
+	}
+	for _, row := range rows {
+		logrus.WithFields(logrus.Fields{
+			"issuedBy": row.IssuedBy,
+			"reason":   row.Reason,
+			"suite":    row.TestSuiteName,
+			"test":     row.TestName,
+		}).Warn("failed aggregated test accepted by gate override")
+	}
+	return nil
+}
+
+// recordGateResults stores the final verdict of every aggregated test.  The verdict stands even when it can't be
+// stored, so failing to store it is only logged.
+func (o *JobRunAggregatorAnalyzerOptions) recordGateResults(ctx context.Context, suite *junit.TestSuite) {
+	if o.gateResultInserter == nil {
+		return
+	}
+	rows := jobrunaggregatorlib.NewGateResultRows("analyze-job-runs", o.jobName, o.payloadTag, suite, o.clock.Now())
+	if err := o.gateResultInserter.Put(ctx, rows); err != nil {
+		logrus.WithError(err).Warn("failed to record gate results")
+	}
+}
+
 func hasFailedTestCase(suite *junit.TestSuite) bool {
 	for _, testCase := range suite.TestCases {
 		if testCase.FailureOutput != nil {
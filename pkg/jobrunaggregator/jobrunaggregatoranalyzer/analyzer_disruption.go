@@ -3,10 +3,10 @@ package jobrunaggregatoranalyzer
 import (
 	"context"
 	"fmt"
-	"os"
 	"path"
 	"strings"
 
+	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -72,7 +72,7 @@ func (o *JobRunAggregatorAnalyzerOptions) CalculateDisruptionTestSuite(ctx conte
 
 	default:
 		// ignore the errors if we have at least three results
-		fmt.Fprintf(os.Stderr, "Could not fetch backend disruption data for all runs %v\n", err)
+		logrus.WithError(err).Error("Could not fetch backend disruption data for all runs")
 	}
 
 	testCaseNamePatternToDisruptionCheckFn := map[string]disruptionJunitCheckFunc{
@@ -103,7 +103,7 @@ func (o *JobRunAggregatorAnalyzerOptions) CalculateDisruptionTestSuite(ctx conte
 
 			testCaseName := fmt.Sprintf(testCaseNamePattern, backendName)
 			testSuiteName := "aggregated-disruption"
-			junitTestCase, err := disruptionToJUnitTestCase(testCaseName, testSuiteName, jobGCSBucketRoot, failedJobRunIDs, successfulJobRunIDs, status, message)
+			junitTestCase, err := disruptionToJUnitTestCase(testCaseName, testSuiteName, o.gcsBucket, jobGCSBucketRoot, failedJobRunIDs, successfulJobRunIDs, status, message)
 			if err != nil {
 				return nil, err
 			}
@@ -126,7 +126,7 @@ func checkPercentileDisruption(passFailCalculator baseline, percentile, graceSec
 
 type disruptionJunitCheckFunc func(ctx context.Context, jobRunIDToAvailabilityResultForBackend map[string]jobrunaggregatorlib.AvailabilityResult, backend, masterNodesUpdated string) (failedJobRunsIDs []string, successfulJobRunIDs []string, status testCaseStatus, message string, err error)
 
-func disruptionToJUnitTestCase(testCaseName, testSuiteName, jobGCSBucketRoot string, failedJobRunIDs, successfulJobRunIDs []string, status testCaseStatus, message string) (*junit.TestCase, error) {
+func disruptionToJUnitTestCase(testCaseName, testSuiteName, gcsBucket, jobGCSBucketRoot string, failedJobRunIDs, successfulJobRunIDs []string, status testCaseStatus, message string) (*junit.TestCase, error) {
 	junitTestCase := &junit.TestCase{
 		Name: testCaseName,
 	}
@@ -137,8 +137,8 @@ func disruptionToJUnitTestCase(testCaseName, testSuiteName, jobGCSBucketRoot str
 		Summary:       message,
 	}
 	for _, jobRunID := range failedJobRunIDs {
-		humanURL := jobrunaggregatorapi.GetHumanURLForLocation(path.Join(jobGCSBucketRoot, jobRunID), "test-platform-results")
-		gcsArtifactURL := jobrunaggregatorapi.GetGCSArtifactURLForLocation(path.Join(jobGCSBucketRoot, jobRunID), "test-platform-results")
+		humanURL := jobrunaggregatorapi.GetHumanURLForLocation(path.Join(jobGCSBucketRoot, jobRunID), gcsBucket)
+		gcsArtifactURL := jobrunaggregatorapi.GetGCSArtifactURLForLocation(path.Join(jobGCSBucketRoot, jobRunID), gcsBucket)
 		currDetails.Failures = append(currDetails.Failures, jobrunaggregatorlib.TestCaseFailure{
 			JobRunID:       jobRunID,
 			HumanURL:       humanURL,
@@ -146,8 +146,8 @@ func disruptionToJUnitTestCase(testCaseName, testSuiteName, jobGCSBucketRoot str
 		})
 	}
 	for _, jobRunID := range successfulJobRunIDs {
-		humanURL := jobrunaggregatorapi.GetHumanURLForLocation(path.Join(jobGCSBucketRoot, jobRunID), "test-platform-results")
-		gcsArtifactURL := jobrunaggregatorapi.GetGCSArtifactURLForLocation(path.Join(jobGCSBucketRoot, jobRunID), "test-platform-results")
+		humanURL := jobrunaggregatorapi.GetHumanURLForLocation(path.Join(jobGCSBucketRoot, jobRunID), gcsBucket)
+		gcsArtifactURL := jobrunaggregatorapi.GetGCSArtifactURLForLocation(path.Join(jobGCSBucketRoot, jobRunID), gcsBucket)
 		currDetails.Passes = append(currDetails.Passes, jobrunaggregatorlib.TestCasePass{
 			JobRunID:       jobRunID,
 			HumanURL:       humanURL,
@@ -156,11 +156,9 @@ func disruptionToJUnitTestCase(testCaseName, testSuiteName, jobGCSBucketRoot str
 	}
 
 	currDetails.Summary = message
-	detailsBytes, err := yaml.Marshal(currDetails)
-	if err != nil {
+	if err := jobrunaggregatorlib.SetTestCaseDetails(junitTestCase, &currDetails); err != nil {
 		return nil, err
 	}
-	junitTestCase.SystemOut = string(detailsBytes)
 
 	switch status {
 	case testCaseFailed:
@@ -190,7 +188,7 @@ func getDisruptionByJobRunID(ctx context.Context, finishedJobsToAggregate []jobr
 			continue
 		}
 		if len(rawBackendDisruptionData) == 0 {
-			fmt.Fprintf(os.Stderr, "Could not fetch backend disruption data for %s\n", jobRun.GetJobRunID())
+			logrus.WithFields(logrus.Fields{"job": jobRun.GetJobName(), "jobRunID": jobRun.GetJobRunID()}).Error("Could not fetch backend disruption data")
 			continue
 		}
 
@@ -16,6 +16,7 @@ import (
 
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+	"github.com/openshift/ci-tools/pkg/junit"
 )
 
 const (
@@ -33,6 +34,19 @@ func TestParseStaticJobRunInfo(t *testing.T) {
 	assert.Equal(t, 10, len(jobRunInfo), "Invalid JobRunInfo length")
 }
 
+func TestDisruptionTestCaseLinksToBucket(t *testing.T) {
+	testCase, err := disruptionToJUnitTestCase("kube-api disruption", "BackendDisruption", "mirror-bucket", "logs/"+testJobName, []string{"1"}, []string{"2"}, testCaseFailed, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	details, err := jobrunaggregatorlib.GetTestCaseDetails(testCase)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, "https://prow.ci.openshift.org/view/gs/mirror-bucket/logs/"+testJobName+"/1", details.Failures[0].HumanURL)
+	assert.Equal(t, "https://prow.ci.openshift.org/view/gs/mirror-bucket/logs/"+testJobName+"/2", details.Passes[0].HumanURL)
+}
+
 func TestAnalyzer(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -70,14 +84,15 @@ func TestAnalyzer(t *testing.T) {
 			defer os.RemoveAll(workDir)
 
 			// matches what we do in the JobRunLocator:
-			startPayloadJobRunWindow := payloadStartTime.Add(-1 * jobrunaggregatorlib.JobSearchWindowStartOffset)
-			endPayloadJobRunWindow := payloadStartTime.Add(jobrunaggregatorlib.JobSearchWindowEndOffset)
+			startPayloadJobRunWindow := payloadStartTime.Add(-1 * jobrunaggregatorlib.DefaultJobSearchWindow.StartOffset)
+			endPayloadJobRunWindow := payloadStartTime.Add(jobrunaggregatorlib.DefaultJobSearchWindow.EndOffset)
 
 			mockDataClient := jobrunaggregatorlib.NewMockCIDataClient(mockCtrl)
 			mockDataClient.EXPECT().GetJobRunForJobNameBeforeTime(gomock.Any(), testJobName, startPayloadJobRunWindow).Return("1000", nil).Times(1)
 			mockDataClient.EXPECT().GetJobRunForJobNameAfterTime(gomock.Any(), testJobName, endPayloadJobRunWindow).Return("2000", nil).Times(1)
 
 			mockGCSClient := jobrunaggregatorlib.NewMockCIGCSClient(mockCtrl)
+			mockGCSClient.EXPECT().ForBucket("bucketname").Return(mockGCSClient).Times(1)
 			mockGCSClient.EXPECT().ReadRelatedJobRuns(
 				gomock.Any(),
 				testJobName,
@@ -86,8 +101,12 @@ func TestAnalyzer(t *testing.T) {
 				"2000",
 				gomock.Any()).Return(tc.jobRunInfos, nil).Times(1)
 
+			jobRunIDs := []string{}
 			for _, ri := range tc.jobRunInfos {
-				mockGCSClient.EXPECT().ReadJobRunFromGCS(gomock.Any(), gomock.Any(), testJobName, ri.GetJobRunID(), gomock.Any()).Return(ri, nil)
+				jobRunIDs = append(jobRunIDs, ri.GetJobRunID())
+			}
+			if len(jobRunIDs) > 0 {
+				mockGCSClient.EXPECT().ReadJobRunsFromGCS(gomock.Any(), gomock.Any(), testJobName, jobRunIDs, gomock.Any()).Return(tc.jobRunInfos, nil)
 			}
 
 			analyzer := JobRunAggregatorAnalyzerOptions{
@@ -95,6 +114,7 @@ func TestAnalyzer(t *testing.T) {
 					testJobName,
 					testPayloadtag,
 					payloadStartTime,
+					jobrunaggregatorlib.DefaultJobSearchWindow,
 					mockDataClient,
 					mockGCSClient,
 					"bucketname",
@@ -143,3 +163,24 @@ func buildFakeJobRunInfo(mockCtrl *gomock.Controller,
 	mockJRI.EXPECT().GetProwJob(gomock.Any()).Return(prowJob, nil).AnyTimes()
 	return mockJRI
 }
+
+// failingGateOverrideInserter fails to store the rows it is given
+type failingGateOverrideInserter struct{}
+
+func (failingGateOverrideInserter) Put(ctx context.Context, src interface{}) error {
+	return fmt.Errorf("quota exceeded")
+}
+
+func TestApplyGateOverrideFailsClosed(t *testing.T) {
+	suite := &junit.TestSuite{Children: []*junit.TestSuite{{
+		Name:      "aggregated-disruption",
+		TestCases: []*junit.TestCase{{Name: "kube-api disruption", FailureOutput: &junit.FailureOutput{Message: "too much disruption"}}},
+	}}}
+	o := &JobRunAggregatorAnalyzerOptions{
+		jobName:              testJobName,
+		payloadTag:           testPayloadtag,
+		gateOverride:         &jobrunaggregatorlib.GateOverride{IssuedBy: "release-architect", Reason: "known infra outage"},
+		gateOverrideInserter: failingGateOverrideInserter{},
+	}
+	assert.ErrorContains(t, o.applyGateOverride(context.TODO(), suite), "quota exceeded")
+}
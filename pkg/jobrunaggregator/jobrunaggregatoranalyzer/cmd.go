@@ -13,11 +13,13 @@ import (
 	prowjobclientset "k8s.io/test-infra/prow/client/clientset/versioned"
 	"k8s.io/utils/clock"
 
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
 )
 
 type JobRunsAnalyzerFlags struct {
 	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
+	QueryCost       *jobrunaggregatorlib.QueryCostFlags
 	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
 
 	JobName                     string
@@ -28,16 +30,41 @@ type JobRunsAnalyzerFlags struct {
 	Timeout                     time.Duration
 	EstimatedJobStartTimeString string
 	JobStateQuerySource         string
+	AdaptiveWait                bool
 
 	StaticJobRunIdentifierPath string
 	StaticJobRunIdentifierJSON string
 	GCSBucket                  string
+
+	GateOverridePath string
+	GateOverrideJSON string
+
+	TestOwnershipFile string
+	TestRenameFile    string
+
+	// the Record and Cache flags write to the dataset, which local runs must not do unless asked to
+	RecordGateResults   bool
+	CacheLocatedJobRuns bool
+
+	Notifier         *jobrunaggregatorlib.NotifierFlags
+	JunitParseBudget *jobrunaggregatorlib.JunitParseBudgetFlags
+	ArtifactCache    *jobrunaggregatorlib.ArtifactCacheFlags
+	QueryCache       *jobrunaggregatorlib.QueryCacheFlags
+	JobSearchWindow  *jobrunaggregatorlib.JobSearchWindowFlags
+	S3               *jobrunaggregatorlib.S3Flags
 }
 
 func NewJobRunsAnalyzerFlags() *JobRunsAnalyzerFlags {
 	return &JobRunsAnalyzerFlags{
-		DataCoordinates: jobrunaggregatorlib.NewBigQueryDataCoordinates(),
-		Authentication:  jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+		DataCoordinates:  jobrunaggregatorlib.NewBigQueryDataCoordinates(),
+		QueryCost:        jobrunaggregatorlib.NewQueryCostFlags(),
+		Authentication:   jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+		Notifier:         jobrunaggregatorlib.NewNotifierFlags(),
+		JunitParseBudget: jobrunaggregatorlib.NewJunitParseBudgetFlags(),
+		ArtifactCache:    jobrunaggregatorlib.NewArtifactCacheFlags(),
+		QueryCache:       jobrunaggregatorlib.NewQueryCacheFlags(),
+		JobSearchWindow:  jobrunaggregatorlib.NewJobSearchWindowFlags(),
+		S3:               jobrunaggregatorlib.NewS3Flags(),
 
 		WorkingDir:                  "job-aggregator-working-dir",
 		EstimatedJobStartTimeString: time.Now().Format(kubeTimeSerializationLayout),
@@ -49,7 +76,14 @@ const kubeTimeSerializationLayout = time.RFC3339
 
 func (f *JobRunsAnalyzerFlags) BindFlags(fs *pflag.FlagSet) {
 	f.DataCoordinates.BindFlags(fs)
+	f.QueryCost.BindFlags(fs)
 	f.Authentication.BindFlags(fs)
+	f.Notifier.BindFlags(fs)
+	f.JunitParseBudget.BindFlags(fs)
+	f.ArtifactCache.BindFlags(fs)
+	f.QueryCache.BindFlags(fs)
+	f.JobSearchWindow.BindFlags(fs)
+	f.S3.BindFlags(fs)
 
 	fs.StringVar(&f.JobName, "job", f.JobName, "The name of the job to inspect, like periodic-ci-openshift-release-master-ci-4.9-e2e-gcp-upgrade")
 	fs.StringVar(&f.WorkingDir, "working-dir", f.WorkingDir, "The directory to store caches, output, and the like.")
@@ -59,12 +93,20 @@ func (f *JobRunsAnalyzerFlags) BindFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&f.Timeout, "timeout", f.Timeout, "Time to wait for aggregation to complete.")
 	fs.StringVar(&f.EstimatedJobStartTimeString, "job-start-time", f.EstimatedJobStartTimeString, fmt.Sprintf("Start time in RFC822Z: %s", kubeTimeSerializationLayout))
 	fs.StringVar(&f.JobStateQuerySource, "query-source", jobrunaggregatorlib.JobStateQuerySourceBigQuery, "The source from which job states are found. It is either bigquery or cluster")
+	fs.BoolVar(&f.AdaptiveWait, "adaptive-wait", f.AdaptiveWait, "Stop waiting for unfinished job runs based on how long runs of the job historically take instead of a fixed time.  Only applies to --query-source=bigquery, never waits longer than --timeout allows")
 
 	// optional for local use or potentially gangway results
 	fs.StringVar(&f.StaticJobRunIdentifierPath, "static-run-info-path", f.StaticJobRunIdentifierPath, "The optional path to a file containing JSON formatted JobRunIdentifier array used for aggregated analysis")
 	fs.StringVar(&f.StaticJobRunIdentifierJSON, "static-run-info-json", f.StaticJobRunIdentifierJSON, "The optional JSON formatted string of JobRunIdentifier array used for aggregated analysis")
 
 	fs.StringVar(&f.GCSBucket, "google-storage-bucket", "test-platform-results", "The optional GCS Bucket holding test artifacts")
+
+	fs.StringVar(&f.GateOverridePath, "gate-override-path", f.GateOverridePath, "The optional path to a file (like a mounted ConfigMap key) containing a JSON formatted GateOverride used to force-accept failed aggregated tests")
+	fs.StringVar(&f.GateOverrideJSON, "gate-override-json", f.GateOverrideJSON, "The optional JSON formatted GateOverride used to force-accept failed aggregated tests")
+	fs.StringVar(&f.TestOwnershipFile, "test-ownership-file", f.TestOwnershipFile, "The optional path to a YAML list of {pattern, component, team} used to name the owner of failed aggregated tests")
+	fs.StringVar(&f.TestRenameFile, "test-rename-file", f.TestRenameFile, "The optional path to a YAML list of {from, to} test names. Old names in junit and in historical data are replaced by the new ones")
+	fs.BoolVar(&f.RecordGateResults, "record-gate-results", f.RecordGateResults, "Record the verdict of every aggregated test in the GateResults table")
+	fs.BoolVar(&f.CacheLocatedJobRuns, "cache-located-job-runs", f.CacheLocatedJobRuns, "Reuse the job runs of the payload located by earlier analyzers, and record the ones this aggregation locates, in the LocatedJobRuns table")
 }
 
 func NewJobRunsAnalyzerCommand() *cobra.Command {
@@ -97,6 +139,9 @@ func NewJobRunsAnalyzerCommand() *cobra.Command {
 	}
 
 	f.BindFlags(cmd.Flags())
+	if err := cmd.RegisterFlagCompletionFunc("payload-tag", jobrunaggregatorlib.CompletePayloadTags(f.Authentication, f.DataCoordinates)); err != nil {
+		logrus.WithError(err).Fatal("Failed to register the completion of --payload-tag")
+	}
 
 	return cmd
 }
@@ -115,9 +160,30 @@ func (f *JobRunsAnalyzerFlags) Validate() error {
 	if err := f.DataCoordinates.Validate(); err != nil {
 		return err
 	}
+	if err := f.QueryCost.Validate(); err != nil {
+		return err
+	}
 	if err := f.Authentication.Validate(); err != nil {
 		return err
 	}
+	if err := f.Notifier.Validate(); err != nil {
+		return err
+	}
+	if err := f.JunitParseBudget.Validate(); err != nil {
+		return err
+	}
+	if err := f.ArtifactCache.Validate(); err != nil {
+		return err
+	}
+	if err := f.QueryCache.Validate(); err != nil {
+		return err
+	}
+	if err := f.JobSearchWindow.Validate(); err != nil {
+		return err
+	}
+	if err := f.S3.Validate(); err != nil {
+		return err
+	}
 	if len(f.PayloadTag) > 0 && len(f.AggregationID) > 0 {
 		return fmt.Errorf("cannot specify both --payload-tag and --aggregation-id")
 	}
@@ -132,6 +198,9 @@ func (f *JobRunsAnalyzerFlags) Validate() error {
 			return fmt.Errorf("unknown query-source %s, valid values are: %+q", f.JobStateQuerySource, sets.List(jobrunaggregatorlib.KnownQuerySources))
 		}
 	}
+	if len(f.GateOverridePath) > 0 && len(f.GateOverrideJSON) > 0 {
+		return fmt.Errorf("cannot specify both --gate-override-path and --gate-override-json")
+	}
 
 	return nil
 }
@@ -148,11 +217,27 @@ func (f *JobRunsAnalyzerFlags) ToOptions(ctx context.Context) (*JobRunAggregator
 	if err != nil {
 		return nil, err
 	}
-	ciDataClient := jobrunaggregatorlib.NewRetryingCIDataClient(
-		jobrunaggregatorlib.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
+	ciDataClient := f.QueryCache.Wrap(
+		jobrunaggregatorlib.NewRetryingCIDataClient(
+			f.QueryCost.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
+		),
+		*f.DataCoordinates,
+		f.WorkingDir,
 	)
 
-	ciGCSClient, err := f.Authentication.NewCIGCSClient(ctx, f.GCSBucket)
+	gcsOptions := jobrunaggregatorlib.CIGCSClientOptions{
+		StartingJobRunID: f.JobSearchWindow.StartingJobRunID,
+		JobRunArtifactOptions: jobrunaggregatorapi.JobRunArtifactOptions{
+			JunitParseBudget: f.JunitParseBudget.ToBudget(),
+			ArtifactCache:    f.ArtifactCache.NewArtifactCache(f.WorkingDir),
+		},
+	}
+	var ciGCSClient jobrunaggregatorlib.CIGCSClient
+	if f.S3.Enabled() {
+		ciGCSClient, err = f.S3.NewCIGCSClient(f.GCSBucket, gcsOptions)
+	} else {
+		ciGCSClient, err = f.Authentication.NewCIGCSClient(ctx, f.GCSBucket, gcsOptions)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -165,13 +250,34 @@ func (f *JobRunsAnalyzerFlags) ToOptions(ctx context.Context) (*JobRunAggregator
 		}
 	}
 
+	gateOverride, err := jobrunaggregatorlib.GetGateOverride(f.GateOverrideJSON, f.GateOverridePath)
+	if err != nil {
+		return nil, err
+	}
+	testOwners, err := jobrunaggregatorlib.NewTestOwners(f.TestOwnershipFile)
+	if err != nil {
+		return nil, err
+	}
+	testRenames, err := jobrunaggregatorlib.NewTestRenames(f.TestRenameFile)
+	if err != nil {
+		return nil, err
+	}
+	notifier, err := f.Notifier.ToNotifier()
+	if err != nil {
+		return nil, err
+	}
+	ciDataSet := bigQueryClient.Dataset(f.DataCoordinates.DataSetID)
+
 	var jobRunLocator jobrunaggregatorlib.JobRunLocator
 	var prowJobMatcherFunc jobrunaggregatorlib.ProwJobMatcherFunc
+	var matchID string
 	if len(f.PayloadTag) > 0 {
+		matchID = f.PayloadTag
 		jobRunLocator = jobrunaggregatorlib.NewPayloadAnalysisJobLocatorForReleaseController(
 			f.JobName,
 			f.PayloadTag,
 			estimatedStartTime,
+			f.JobSearchWindow.ToJobSearchWindow(),
 			ciDataClient,
 			ciGCSClient,
 			f.GCSBucket,
@@ -179,11 +285,13 @@ func (f *JobRunsAnalyzerFlags) ToOptions(ctx context.Context) (*JobRunAggregator
 		prowJobMatcherFunc = jobrunaggregatorlib.NewProwJobMatcherFuncForReleaseController(f.JobName, f.PayloadTag)
 	}
 	if len(f.AggregationID) > 0 {
+		matchID = f.AggregationID
 		jobRunLocator = jobrunaggregatorlib.NewPayloadAnalysisJobLocatorForPR(
 			f.JobName,
 			f.AggregationID,
 			jobrunaggregatorlib.ProwJobAggregationIDLabel,
 			estimatedStartTime,
+			f.JobSearchWindow.ToJobSearchWindow(),
 			ciDataClient,
 			ciGCSClient,
 			f.GCSBucket,
@@ -191,6 +299,20 @@ func (f *JobRunsAnalyzerFlags) ToOptions(ctx context.Context) (*JobRunAggregator
 		)
 		prowJobMatcherFunc = jobrunaggregatorlib.NewProwJobMatcherFuncForPR(f.JobName, f.AggregationID, jobrunaggregatorlib.ProwJobAggregationIDLabel)
 	}
+	if f.CacheLocatedJobRuns {
+		jobRunLocator = jobrunaggregatorlib.NewCachingJobRunLocator(
+			jobRunLocator,
+			f.JobName,
+			matchID,
+			prowJobMatcherFunc,
+			ciDataClient,
+			ciDataSet.Table(jobrunaggregatorapi.LocatedJobRunsTableName).Inserter(),
+		)
+	}
+	var gateResultInserter jobrunaggregatorlib.BigQueryInserter
+	if f.RecordGateResults {
+		gateResultInserter = ciDataSet.Table(jobrunaggregatorapi.GateResultsTableName).Inserter()
+	}
 
 	var prowJobClient *prowjobclientset.Clientset
 	if f.JobStateQuerySource != jobrunaggregatorlib.JobStateQuerySourceBigQuery {
@@ -203,7 +325,7 @@ func (f *JobRunsAnalyzerFlags) ToOptions(ctx context.Context) (*JobRunAggregator
 	return &JobRunAggregatorAnalyzerOptions{
 		explicitGCSPrefix:       f.ExplicitGCSPrefix,
 		jobRunLocator:           jobRunLocator,
-		passFailCalculator:      newWeeklyAverageFromTenDaysAgo(f.JobName, estimatedStartTime, 6, ciDataClient),
+		passFailCalculator:      newWeeklyAverageFromTenDaysAgo(f.JobName, estimatedStartTime, 6, ciDataClient, testRenames),
 		jobName:                 f.JobName,
 		payloadTag:              f.PayloadTag,
 		workingDir:              f.WorkingDir,
@@ -213,7 +335,15 @@ func (f *JobRunsAnalyzerFlags) ToOptions(ctx context.Context) (*JobRunAggregator
 		prowJobClient:           prowJobClient,
 		jobStateQuerySource:     f.JobStateQuerySource,
 		prowJobMatcherFunc:      prowJobMatcherFunc,
+		ciDataClient:            ciDataClient,
+		adaptiveWait:            f.AdaptiveWait,
 		staticJobRunIdentifiers: staticJobRunIdentifiers,
 		gcsBucket:               f.GCSBucket,
+		gateOverride:            gateOverride,
+		gateOverrideInserter:    ciDataSet.Table(jobrunaggregatorapi.GateOverridesTableName).Inserter(),
+		gateResultInserter:      gateResultInserter,
+		testOwners:              testOwners,
+		testRenames:             testRenames,
+		notifier:                notifier,
 	}, nil
 }
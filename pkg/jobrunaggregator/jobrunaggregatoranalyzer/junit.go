@@ -7,8 +7,6 @@ import (
 	"sort"
 	"strings"
 
-	"gopkg.in/yaml.v2"
-
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
@@ -42,6 +40,7 @@ func newJobRunJunit(ctx context.Context, jobRun jobrunaggregatorapi.JobRunInfo)
 }
 
 type aggregatedJobRunJunit struct {
+	gcsBucket                string
 	jobGCSBucketRoot         string
 	aggregationNameToJobRuns map[string][]*jobRunJunit
 
@@ -75,7 +74,7 @@ func (a *aggregatedJobRunJunit) aggregateAllJobRuns() (*junit.TestSuites, error)
 	for _, aggregationName := range sets.StringKeySet(a.aggregationNameToJobRuns).List() {
 		jobRunJunits := a.aggregationNameToJobRuns[aggregationName]
 		for _, currJobRunJunit := range jobRunJunits {
-			if err := combineTestSuites(combined, a.jobGCSBucketRoot, currJobRunJunit.jobRun.GetJobRunID(), currJobRunJunit.combinedJunit); err != nil {
+			if err := combineTestSuites(combined, a.gcsBucket, a.jobGCSBucketRoot, currJobRunJunit.jobRun.GetJobRunID(), currJobRunJunit.combinedJunit); err != nil {
 				return nil, err
 			}
 		}
@@ -89,17 +88,17 @@ func (a *aggregatedJobRunJunit) aggregateAllJobRuns() (*junit.TestSuites, error)
 	return a.combinedJunit, nil
 }
 
-func combineTestSuites(combined *junit.TestSuites, jobGCSBucketRoot, toAddJobRunID string, toAdd *junit.TestSuites) error {
+func combineTestSuites(combined *junit.TestSuites, gcsBucket, jobGCSBucketRoot, toAddJobRunID string, toAdd *junit.TestSuites) error {
 	for _, suiteToAdd := range toAdd.Suites {
 		combinedSuite := ensureSuiteInSuites(combined, suiteToAdd.Name)
-		if err := combineTestSuite([]string{}, combinedSuite, jobGCSBucketRoot, toAddJobRunID, suiteToAdd); err != nil {
+		if err := combineTestSuite([]string{}, combinedSuite, gcsBucket, jobGCSBucketRoot, toAddJobRunID, suiteToAdd); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func combineTestSuite(parentSuiteNames []string, combined *junit.TestSuite, jobGCSBucketRoot, toAddJobRunID string, toAdd *junit.TestSuite) error {
+func combineTestSuite(parentSuiteNames []string, combined *junit.TestSuite, gcsBucket, jobGCSBucketRoot, toAddJobRunID string, toAdd *junit.TestSuite) error {
 	currentSuiteNames := []string{}
 	currentSuiteNames = append(currentSuiteNames, parentSuiteNames...)
 	currentSuiteNames = append(currentSuiteNames, combined.Name)
@@ -107,14 +106,14 @@ func combineTestSuite(parentSuiteNames []string, combined *junit.TestSuite, jobG
 
 	for _, testCaseToAdd := range toAdd.TestCases {
 		combinedTestCase := ensureTestCaseInSuite(combined, testCaseToAdd.Name)
-		if err := aggregateTestCase(suiteAsSingleString, combinedTestCase, jobGCSBucketRoot, toAddJobRunID, testCaseToAdd); err != nil {
+		if err := aggregateTestCase(suiteAsSingleString, combinedTestCase, gcsBucket, jobGCSBucketRoot, toAddJobRunID, testCaseToAdd); err != nil {
 			return err
 		}
 	}
 
 	for _, suiteToAdd := range toAdd.Children {
 		combinedSuite := ensureSuiteInSuite(combined, suiteToAdd.Name)
-		if err := combineTestSuite(currentSuiteNames, combinedSuite, jobGCSBucketRoot, toAddJobRunID, suiteToAdd); err != nil {
+		if err := combineTestSuite(currentSuiteNames, combinedSuite, gcsBucket, jobGCSBucketRoot, toAddJobRunID, suiteToAdd); err != nil {
 			return err
 		}
 	}
@@ -182,26 +181,23 @@ func ensureTestCaseInSuite(o *junit.TestSuite, name string) *junit.TestCase {
 	return ret
 }
 
-func aggregateTestCase(testSuiteName string, combined *junit.TestCase, jobGCSBucketRoot, toAddJobRunID string, toAdd *junit.TestCase) error {
-	currDetails := &jobrunaggregatorlib.TestCaseDetails{
-		Name:          toAdd.Name,
-		TestSuiteName: testSuiteName,
-	}
-	if len(combined.SystemOut) > 0 {
-		if err := yaml.Unmarshal([]byte(combined.SystemOut), currDetails); err != nil {
-			return err
-		}
+func aggregateTestCase(testSuiteName string, combined *junit.TestCase, gcsBucket, jobGCSBucketRoot, toAddJobRunID string, toAdd *junit.TestCase) error {
+	currDetails, err := jobrunaggregatorlib.GetTestCaseDetails(combined)
+	if err != nil {
+		return err
 	}
+	currDetails.Name = toAdd.Name
+	currDetails.TestSuiteName = testSuiteName
 
 	switch {
 	case toAdd.FailureOutput != nil:
-		humanURL := jobrunaggregatorapi.GetHumanURLForLocation(path.Join(jobGCSBucketRoot, toAddJobRunID), "test-platform-results")
+		humanURL := jobrunaggregatorapi.GetHumanURLForLocation(path.Join(jobGCSBucketRoot, toAddJobRunID), gcsBucket)
 		currDetails.Failures = append(
 			currDetails.Failures,
 			jobrunaggregatorlib.TestCaseFailure{
 				JobRunID:       toAddJobRunID,
 				HumanURL:       humanURL,
-				GCSArtifactURL: jobrunaggregatorapi.GetGCSArtifactURLForLocation(path.Join(jobGCSBucketRoot, toAddJobRunID), "test-platform-results"),
+				GCSArtifactURL: jobrunaggregatorapi.GetGCSArtifactURLForLocation(path.Join(jobGCSBucketRoot, toAddJobRunID), gcsBucket),
 			})
 
 	case toAdd.SkipMessage != nil:
@@ -209,8 +205,8 @@ func aggregateTestCase(testSuiteName string, combined *junit.TestCase, jobGCSBuc
 			currDetails.Skips,
 			jobrunaggregatorlib.TestCaseSkip{
 				JobRunID:       toAddJobRunID,
-				HumanURL:       jobrunaggregatorapi.GetHumanURLForLocation(path.Join(jobGCSBucketRoot, toAddJobRunID), "test-platform-results"),
-				GCSArtifactURL: jobrunaggregatorapi.GetGCSArtifactURLForLocation(path.Join(jobGCSBucketRoot, toAddJobRunID), "test-platform-results"),
+				HumanURL:       jobrunaggregatorapi.GetHumanURLForLocation(path.Join(jobGCSBucketRoot, toAddJobRunID), gcsBucket),
+				GCSArtifactURL: jobrunaggregatorapi.GetGCSArtifactURLForLocation(path.Join(jobGCSBucketRoot, toAddJobRunID), gcsBucket),
 			})
 
 	default:
@@ -218,16 +214,11 @@ func aggregateTestCase(testSuiteName string, combined *junit.TestCase, jobGCSBuc
 			currDetails.Passes,
 			jobrunaggregatorlib.TestCasePass{
 				JobRunID:       toAddJobRunID,
-				HumanURL:       jobrunaggregatorapi.GetHumanURLForLocation(path.Join(jobGCSBucketRoot, toAddJobRunID), "test-platform-results"),
-				GCSArtifactURL: jobrunaggregatorapi.GetGCSArtifactURLForLocation(path.Join(jobGCSBucketRoot, toAddJobRunID), "test-platform-results"),
+				HumanURL:       jobrunaggregatorapi.GetHumanURLForLocation(path.Join(jobGCSBucketRoot, toAddJobRunID), gcsBucket),
+				GCSArtifactURL: jobrunaggregatorapi.GetGCSArtifactURLForLocation(path.Join(jobGCSBucketRoot, toAddJobRunID), gcsBucket),
 			})
 
 	}
 
-	detailsYaml, err := yaml.Marshal(currDetails)
-	if err != nil {
-		return nil
-	}
-	combined.SystemOut = string(detailsYaml)
-	return nil
+	return jobrunaggregatorlib.SetTestCaseDetails(combined, currDetails)
 }
@@ -8,7 +8,7 @@ import (
 	"sync"
 	"time"
 
-	"gopkg.in/yaml.v2"
+	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 
@@ -60,14 +60,13 @@ func assignPassFailForTestSuite(ctx context.Context, jobName string, parentTestS
 
 	for i := range combined.TestCases {
 		currTestCase := combined.TestCases[i]
-		currDetails := &jobrunaggregatorlib.TestCaseDetails{}
-		if err := yaml.Unmarshal([]byte(currTestCase.SystemOut), currDetails); err != nil {
+		currDetails, err := jobrunaggregatorlib.GetTestCaseDetails(currTestCase)
+		if err != nil {
 			return err
 		}
 
 		var status testCaseStatus
 		var message string
-		var err error
 		// TODO once we are ready to stop failing on aggregating the availability tests, we write something here to ignore
 		//  the aggregated tests when they fail.  In actuality, this may never be the case, since we're likely to make the
 		//  individual tests nearly always pass.
@@ -80,11 +79,9 @@ func assignPassFailForTestSuite(ctx context.Context, jobName string, parentTestS
 		}
 
 		currDetails.Summary = message
-		detailsBytes, err := yaml.Marshal(currDetails)
-		if err != nil {
+		if err := jobrunaggregatorlib.SetTestCaseDetails(currTestCase, currDetails); err != nil {
 			return err
 		}
-		currTestCase.SystemOut = string(detailsBytes)
 
 		if status == testCaseFailed {
 			currTestCase.FailureOutput = &junit.FailureOutput{
@@ -152,6 +149,8 @@ type weeklyAverageFromTenDays struct {
 	startDay                time.Time
 	minimumNumberOfAttempts int
 	bigQueryClient          jobrunaggregatorlib.CIDataClient
+	// testRenames gives the historical rows of renamed tests their current name
+	testRenames *jobrunaggregatorlib.TestRenames
 
 	queryTestRunsOnce        sync.Once
 	queryTestRunsErr         error
@@ -168,7 +167,7 @@ type TestKey struct {
 	CombinedTestSuiteName string
 }
 
-func newWeeklyAverageFromTenDaysAgo(jobName string, startDay time.Time, minimumNumberOfAttempts int, bigQueryClient jobrunaggregatorlib.CIDataClient) baseline {
+func newWeeklyAverageFromTenDaysAgo(jobName string, startDay time.Time, minimumNumberOfAttempts int, bigQueryClient jobrunaggregatorlib.CIDataClient, testRenames *jobrunaggregatorlib.TestRenames) baseline {
 	tenDayAgo := jobrunaggregatorlib.GetUTCDay(startDay).Add(-10 * 24 * time.Hour)
 
 	return &weeklyAverageFromTenDays{
@@ -176,6 +175,7 @@ func newWeeklyAverageFromTenDaysAgo(jobName string, startDay time.Time, minimumN
 		startDay:                 tenDayAgo,
 		minimumNumberOfAttempts:  minimumNumberOfAttempts,
 		bigQueryClient:           bigQueryClient,
+		testRenames:              testRenames,
 		queryTestRunsOnce:        sync.Once{},
 		queryTestRunsErr:         nil,
 		aggregatedTestRunsByName: nil,
@@ -191,6 +191,7 @@ func (a *weeklyAverageFromTenDays) getAggregatedTestRuns(ctx context.Context) (m
 			a.queryTestRunsErr = err
 			return
 		}
+		rows = a.testRenames.RenameAggregatedTestRuns(rows)
 		for i := range rows {
 			row := rows[i]
 			key := TestKey{
@@ -252,7 +253,7 @@ func (a *weeklyAverageFromTenDays) getNormalizedFallBackJobName(ctx context.Cont
 			fromReleaseMajor, err1 := getMajor(job.FromRelease.StringVal)
 			fromReleaseMinor, err2 := getMinor(job.FromRelease.StringVal)
 			if err1 != nil || err2 != nil {
-				fmt.Printf("Error parsing from release %s. Will not fall back to previous release data.\n", job.FromRelease)
+				logrus.WithField("job", job.JobName).Warnf("Error parsing from release %s. Will not fall back to previous release data.", job.FromRelease)
 				return jobName, nil
 			}
 			targetFromRelease = fmt.Sprintf("%d.%d", fromReleaseMajor, fromReleaseMinor-1)
@@ -261,7 +262,7 @@ func (a *weeklyAverageFromTenDays) getNormalizedFallBackJobName(ctx context.Cont
 			toReleaseMajor, err1 := getMajor(job.Release)
 			toReleaseMinor, err2 := getMinor(job.Release)
 			if err1 != nil || err2 != nil {
-				fmt.Printf("Error parsing release %s. Will not fall back to previous release data.\n", job.Release)
+				logrus.WithField("job", job.JobName).Warnf("Error parsing release %s. Will not fall back to previous release data.", job.Release)
 				return jobName, nil
 			}
 			targetToRelease = fmt.Sprintf("%d.%d", toReleaseMajor, toReleaseMinor-1)
@@ -392,7 +393,7 @@ func (a *weeklyAverageFromTenDays) checkDisruptionMean(ctx context.Context, jobR
 
 	// We allow one "mulligan" by throwing away at most one outlier > our p95.
 	if float64(max) > historicalDisruptionStatistic.rowData.P95 {
-		fmt.Printf("%s throwing away one outlier (outlier=%ds p95=%fs)\n", backend, max, historicalDisruptionStatistic.rowData.P95)
+		logrus.WithField("backend", backend).Infof("throwing away one outlier (outlier=%ds p95=%fs)", max, historicalDisruptionStatistic.rowData.P95)
 		totalRuns--
 		totalDisruption -= max
 	}
@@ -405,8 +406,13 @@ func (a *weeklyAverageFromTenDays) checkDisruptionMean(ctx context.Context, jobR
 		successRuns,
 		failureRuns,
 	)
-	fmt.Printf("%s disruption calculated for current runs (%s runs=%d totalDisruptionSecs=%ds mean=%.2fs max=%ds)\n",
-		backend, historicalString, totalRuns, totalDisruption, meanDisruption, max)
+	logrus.WithFields(logrus.Fields{
+		"backend":             backend,
+		"runs":                totalRuns,
+		"totalDisruptionSecs": totalDisruption,
+		"mean":                meanDisruption,
+		"max":                 max,
+	}).Infof("disruption calculated for current runs (%s)", historicalString)
 
 	if meanDisruption > disruptionThreshold {
 		return failedJobRunsIDs, successfulJobRunIDs, testCaseFailed, fmt.Sprintf(
@@ -592,7 +598,7 @@ func (a *weeklyAverageFromTenDays) CheckFailed(ctx context.Context, jobName stri
 	aggregatedTestRunsByName, err := a.getAggregatedTestRuns(ctx)
 	missingAllHistoricalData := false
 	if err != nil {
-		fmt.Printf("error getting past reliability data, assume 99%% pass: %v\n", err)
+		logrus.WithError(err).Warn("error getting past reliability data, assume 99% pass")
 		missingAllHistoricalData = true
 	}
 
@@ -608,7 +614,7 @@ func (a *weeklyAverageFromTenDays) CheckFailed(ctx context.Context, jobName stri
 	case missingAllHistoricalData:
 		workingPercentage = 99
 	case !ok:
-		fmt.Printf("missing historical data for %v, arbitrarily assigning 70%% because David thought it was better than failing\n", testCaseDetails.Name)
+		logrus.WithField("test", testCaseDetails.Name).Warn("missing historical data, arbitrarily assigning 70% because David thought it was better than failing")
 		workingPercentage = 70
 	default:
 		workingPercentage = int(averageTestResult.WorkingPercentage)
@@ -0,0 +1,209 @@
+package jobrunaggregatoranalyzer
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+const (
+	analysisStateFileName     = "aggregation-state.json"
+	aggregatedJunitFileName   = "junit-aggregated.xml"
+	aggregatedSummaryHTMLName = "aggregation-testrun-summary.html"
+	aggregatedSummaryMDName   = "aggregation-testrun-summary.md"
+)
+
+// analysisState is everything needed to regenerate the outputs of an aggregation without re-running it.
+type analysisState struct {
+	JobName        string
+	PayloadTag     string
+	AggregatedTime time.Time
+	Configuration  *AggregationConfiguration
+	Suites         *junit.TestSuites
+}
+
+func writeAnalysisState(state *analysisState, dir string) error {
+	stateJSON, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, analysisStateFileName), stateJSON, 0644)
+}
+
+func readAnalysisState(path string) (*analysisState, error) {
+	stateJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	state := &analysisState{}
+	if err := json.Unmarshal(stateJSON, state); err != nil {
+		return nil, fmt.Errorf("failed to parse analysis state %q: %w", path, err)
+	}
+	if state.Suites == nil {
+		return nil, fmt.Errorf("analysis state %q does not contain any test suites", path)
+	}
+	return state, nil
+}
+
+// writeRenderedOutputs writes the junit into junitDir and the human-readable summaries into summaryDir.
+func writeRenderedOutputs(state *analysisState, junitDir, summaryDir string) error {
+	junitXML, err := xml.Marshal(state.Suites)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(junitDir, aggregatedJunitFileName), junitXML, 0644); err != nil {
+		return err
+	}
+
+	fakeSuite := &junit.TestSuite{Children: state.Suites.Suites}
+	summaryHTML := htmlForTestRuns(state.JobName, fakeSuite)
+	if err := os.WriteFile(filepath.Join(summaryDir, aggregatedSummaryHTMLName), []byte(summaryHTML), 0644); err != nil {
+		return err
+	}
+	summaryMarkdown := markdownForTestRuns(state.JobName, state.PayloadTag, fakeSuite)
+	return os.WriteFile(filepath.Join(summaryDir, aggregatedSummaryMDName), []byte(summaryMarkdown), 0644)
+}
+
+// markdownForTestRuns is a short summary suitable for pasting into a bug or a pull request.
+func markdownForTestRuns(jobName, payloadTag string, suite *junit.TestSuite) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "## Aggregation of %s", escapeMarkdown(jobName))
+	if len(payloadTag) > 0 {
+		fmt.Fprintf(sb, " for %s", escapeMarkdown(payloadTag))
+	}
+	sb.WriteString("\n\n| Suite | Tests | Failed | Skipped |\n| ----- | ----- | ------ | ------- |\n")
+	failedTests := []string{}
+	for _, child := range suite.Children {
+		var total, failed, skipped int
+		walkTestCases(child, func(testCase *junit.TestCase) {
+			total++
+			switch {
+			case testCase.SkipMessage != nil:
+				skipped++
+			case failedOnly(testCase):
+				failed++
+				failedTests = append(failedTests, fmt.Sprintf("%s: %s", escapeMarkdown(child.Name), escapeMarkdown(testCase.Name)))
+			}
+		})
+		fmt.Fprintf(sb, "| %s | %d | %d | %d |\n", escapeMarkdown(child.Name), total, failed, skipped)
+	}
+	if len(failedTests) > 0 {
+		sb.WriteString("\n### Failed Tests\n\n")
+		for _, failedTest := range failedTests {
+			fmt.Fprintf(sb, "* %s\n", failedTest)
+		}
+	}
+	return sb.String()
+}
+
+// markdownEscaper keeps names on their line and in their table cell
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ", "\r", " ")
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+type JobRunsRenderFlags struct {
+	StateFile string
+	OutputDir string
+}
+
+func NewJobRunsRenderFlags() *JobRunsRenderFlags {
+	return &JobRunsRenderFlags{
+		OutputDir: "job-aggregator-render-dir",
+	}
+}
+
+func (f *JobRunsRenderFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.StateFile, "state-file", f.StateFile, fmt.Sprintf("The %s written by a previous analyze-job-runs, downloaded from its working directory or artifacts", analysisStateFileName))
+	fs.StringVar(&f.OutputDir, "output-dir", f.OutputDir, "The directory to write the regenerated junit, HTML and Markdown to.")
+}
+
+func NewJobRunsRenderCommand() *cobra.Command {
+	f := NewJobRunsRenderFlags()
+
+	cmd := &cobra.Command{
+		Use:          "render",
+		Long:         `Regenerate the junit, HTML and Markdown outputs of analyze-job-runs from its saved state, without re-running the analysis.`,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			if err := f.Validate(); err != nil {
+				logrus.WithError(err).Fatal("Flags are invalid")
+			}
+			o, err := f.ToOptions(ctx)
+			if err != nil {
+				logrus.WithError(err).Fatal("Failed to build runtime options")
+			}
+
+			if err := o.Run(ctx); err != nil {
+				logrus.WithError(err).Fatal("Command failed")
+			}
+
+			return nil
+		},
+
+		Args: jobrunaggregatorlib.NoArgs,
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+// Validate checks to see if the user-input is likely to produce functional runtime options
+func (f *JobRunsRenderFlags) Validate() error {
+	if len(f.StateFile) == 0 {
+		return fmt.Errorf("missing --state-file: like job-aggregator-working-dir/<job>/<payload>/%s", analysisStateFileName)
+	}
+	if len(f.OutputDir) == 0 {
+		return fmt.Errorf("missing --output-dir")
+	}
+	return nil
+}
+
+// ToOptions goes from the user input to the runtime values need to run the command.
+func (f *JobRunsRenderFlags) ToOptions(ctx context.Context) (*JobRunsRenderOptions, error) {
+	return &JobRunsRenderOptions{
+		stateFile: f.StateFile,
+		outputDir: f.OutputDir,
+	}, nil
+}
+
+type JobRunsRenderOptions struct {
+	stateFile string
+	outputDir string
+}
+
+func (o *JobRunsRenderOptions) Run(ctx context.Context) error {
+	state, err := readAnalysisState(o.stateFile)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(o.outputDir, 0755); err != nil {
+		return fmt.Errorf("error creating output directory %q: %w", o.outputDir, err)
+	}
+	if err := writeRenderedOutputs(state, o.outputDir, o.outputDir); err != nil {
+		return err
+	}
+	logrus.WithFields(logrus.Fields{
+		"job":     state.JobName,
+		"payload": state.PayloadTag,
+		"output":  o.outputDir,
+	}).Info("rendered aggregation outputs")
+	return nil
+}
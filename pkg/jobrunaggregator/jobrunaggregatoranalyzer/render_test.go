@@ -0,0 +1,78 @@
+package jobrunaggregatoranalyzer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+func TestRenderFromAnalysisState(t *testing.T) {
+	dir := t.TempDir()
+	state := &analysisState{
+		JobName:    "some-job",
+		PayloadTag: "4.15.0-0.nightly-2023-11-01-000000",
+		Suites: &junit.TestSuites{
+			Suites: []*junit.TestSuite{
+				{
+					Name: "openshift-tests",
+					TestCases: []*junit.TestCase{
+						{Name: "passing-test"},
+						{Name: "failing-test", FailureOutput: &junit.FailureOutput{Message: "failed"}},
+					},
+				},
+			},
+		},
+	}
+	if err := writeAnalysisState(state, dir); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+
+	o := &JobRunsRenderOptions{
+		stateFile: filepath.Join(dir, analysisStateFileName),
+		outputDir: filepath.Join(dir, "rendered"),
+	}
+	if err := o.Run(context.TODO()); err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	for _, fileName := range []string{aggregatedJunitFileName, aggregatedSummaryHTMLName, aggregatedSummaryMDName} {
+		if _, err := os.Stat(filepath.Join(o.outputDir, fileName)); err != nil {
+			t.Errorf("expected %s to be rendered: %v", fileName, err)
+		}
+	}
+	markdown, err := os.ReadFile(filepath.Join(o.outputDir, aggregatedSummaryMDName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"| openshift-tests | 2 | 1 | 0 |", "* openshift-tests: failing-test"} {
+		if !strings.Contains(string(markdown), expected) {
+			t.Errorf("expected %q in:\n%s", expected, markdown)
+		}
+	}
+}
+
+func TestMarkdownForTestRunsEscapesNames(t *testing.T) {
+	suite := &junit.TestSuite{Children: []*junit.TestSuite{{
+		Name: "suite|with|pipes",
+		TestCases: []*junit.TestCase{
+			{Name: "test with | pipe\nand a newline", FailureOutput: &junit.FailureOutput{Message: "failed"}},
+		},
+	}}}
+	markdown := markdownForTestRuns("some-job", "4.15.0-0.nightly-2023-11-01-000000", suite)
+	for _, expected := range []string{
+		`| suite\|with\|pipes | 1 | 1 | 0 |`,
+		`* suite\|with\|pipes: test with \| pipe and a newline`,
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("expected %q in:\n%s", expected, markdown)
+		}
+	}
+	// the title, the table header and separator, one row for the suite, and the failed test under its heading
+	if lines := strings.Split(strings.TrimSpace(markdown), "\n"); len(lines) != 9 {
+		t.Errorf("expected 9 lines, got %d in:\n%s", len(lines), markdown)
+	}
+}
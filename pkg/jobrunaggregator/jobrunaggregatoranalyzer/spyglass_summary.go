@@ -2,10 +2,10 @@ package jobrunaggregatoranalyzer
 
 import (
 	"fmt"
+	"html"
+	"sort"
 	"strings"
 
-	"gopkg.in/yaml.v2"
-
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
@@ -18,6 +18,8 @@ func htmlForTestRuns(jobName string, suite *junit.TestSuite) string {
 <html>
 <body>
 `
+	html += htmlForVerdicts(jobName, suite.Children)
+
 	failedHTML := htmlForTestSuite(jobName, []string{}, suite, failedOnly)
 	if len(failedHTML) > 0 {
 		html += `
@@ -120,8 +122,10 @@ func htmlForTestCase(jobName string, parents []string, testCase *junit.TestCase,
 
 	var failureHTML string
 	var flakeHTML string
-	currDetails := &jobrunaggregatorlib.TestCaseDetails{}
-	_ = yaml.Unmarshal([]byte(testCase.SystemOut), currDetails)
+	currDetails, err := jobrunaggregatorlib.GetTestCaseDetails(testCase)
+	if err != nil {
+		currDetails = &jobrunaggregatorlib.TestCaseDetails{}
+	}
 
 	if len(currDetails.Failures) == 0 && !strings.Contains(currDetails.Summary, ": we require at least") {
 		return ""
@@ -180,3 +184,112 @@ func htmlForTestCase(jobName string, parents []string, testCase *junit.TestCase,
 
 	return html
 }
+
+// maxWorstJobRuns limits how many job runs are linked from the verdict section.
+const maxWorstJobRuns = 5
+
+type jobRunFailureCount struct {
+	jobRunID string
+	humanURL string
+	failures int
+}
+
+// htmlForVerdicts renders one verdict line per synthetic suite, followed by links to the job runs that contributed
+// the most failures to failed tests.  This is the summary you want to read first.
+func htmlForVerdicts(jobName string, suites []*junit.TestSuite) string {
+	if len(suites) == 0 {
+		return ""
+	}
+
+	ret := `
+<h2>Verdict</h2>
+<ul>
+`
+	jobRunFailures := map[string]*jobRunFailureCount{}
+	for _, suite := range suites {
+		if suite == nil {
+			continue
+		}
+		var total, failed, skipped int
+		failedTestNames := []string{}
+		walkTestCases(suite, func(testCase *junit.TestCase) {
+			total++
+			switch {
+			case testCase.SkipMessage != nil:
+				skipped++
+			case failedOnly(testCase):
+				failed++
+				failedTestNames = append(failedTestNames, testCase.Name)
+				countFailedJobRuns(testCase, jobRunFailures)
+			}
+		})
+
+		verdict := "Passed"
+		if failed > 0 {
+			verdict = "Failed"
+		}
+		ret += fmt.Sprintf("<li><b>%s</b>: %s, %d tests, %d passed, %d failed, %d skipped", html.EscapeString(suite.Name), verdict, total, total-failed-skipped, failed, skipped)
+		if len(failedTestNames) > 0 {
+			ret += "\n<ol>\n"
+			for _, testName := range failedTestNames {
+				ret += fmt.Sprintf("<li>%s</li>\n", html.EscapeString(testName))
+			}
+			ret += "</ol>\n"
+		}
+		ret += "</li>\n"
+	}
+	ret += "</ul>\n"
+
+	worstJobRuns := make([]*jobRunFailureCount, 0, len(jobRunFailures))
+	for _, jobRun := range jobRunFailures {
+		worstJobRuns = append(worstJobRuns, jobRun)
+	}
+	sort.Slice(worstJobRuns, func(i, j int) bool {
+		if worstJobRuns[i].failures != worstJobRuns[j].failures {
+			return worstJobRuns[i].failures > worstJobRuns[j].failures
+		}
+		return worstJobRuns[i].jobRunID < worstJobRuns[j].jobRunID
+	})
+	if len(worstJobRuns) > maxWorstJobRuns {
+		worstJobRuns = worstJobRuns[:maxWorstJobRuns]
+	}
+	if len(worstJobRuns) > 0 {
+		ret += "<h3>Job runs with the most failed tests</h3>\n<ol>\n"
+		for _, jobRun := range worstJobRuns {
+			ret += fmt.Sprintf(`<li><a target="_blank" href="%s">%s/%s</a> failed %d tests</li>`+"\n", html.EscapeString(jobRun.humanURL), html.EscapeString(jobName), html.EscapeString(jobRun.jobRunID), jobRun.failures)
+		}
+		ret += "</ol>\n"
+	}
+	ret += "<br/>\n"
+
+	return ret
+}
+
+func walkTestCases(suite *junit.TestSuite, fn func(*junit.TestCase)) {
+	for _, testCase := range suite.TestCases {
+		fn(testCase)
+	}
+	for _, child := range suite.Children {
+		walkTestCases(child, fn)
+	}
+}
+
+// countFailedJobRuns attributes a failed test case to the job runs that failed it, ignoring job runs that flaked.
+func countFailedJobRuns(testCase *junit.TestCase, jobRunFailures map[string]*jobRunFailureCount) {
+	currDetails, err := jobrunaggregatorlib.GetTestCaseDetails(testCase)
+	if err != nil {
+		return
+	}
+	failedJobRuns := getFailedJobNames(currDetails)
+	counted := sets.Set[string]{}
+	for _, failure := range currDetails.Failures {
+		if !failedJobRuns.Has(failure.JobRunID) || counted.Has(failure.JobRunID) {
+			continue
+		}
+		counted.Insert(failure.JobRunID)
+		if _, ok := jobRunFailures[failure.JobRunID]; !ok {
+			jobRunFailures[failure.JobRunID] = &jobRunFailureCount{jobRunID: failure.JobRunID, humanURL: failure.HumanURL}
+		}
+		jobRunFailures[failure.JobRunID].failures++
+	}
+}
@@ -0,0 +1,81 @@
+package jobrunaggregatoranalyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+func TestHTMLForVerdicts(t *testing.T) {
+	failedDetails := `name: failing-test
+failures:
+- jobrunid: "1"
+  humanurl: https://prow/1
+- jobrunid: "2"
+  humanurl: https://prow/2
+passes:
+- jobrunid: "2"
+  humanurl: https://prow/2
+`
+	suites := []*junit.TestSuite{
+		{
+			Name: "aggregated-disruption",
+			TestCases: []*junit.TestCase{
+				{Name: "passing-test"},
+			},
+		},
+		{
+			Name: "openshift-tests",
+			Children: []*junit.TestSuite{
+				{
+					Name: "nested",
+					TestCases: []*junit.TestCase{
+						{Name: "failing-test", SystemOut: failedDetails, FailureOutput: &junit.FailureOutput{Message: "failed"}},
+						{Name: "skipped-test", SkipMessage: &junit.SkipMessage{}},
+					},
+				},
+			},
+		},
+	}
+
+	html := htmlForVerdicts("some-job", suites)
+	for _, expected := range []string{
+		"<b>aggregated-disruption</b>: Passed, 1 tests, 1 passed, 0 failed, 0 skipped",
+		"<b>openshift-tests</b>: Failed, 2 tests, 0 passed, 1 failed, 1 skipped",
+		"<li>failing-test</li>",
+		`<a target="_blank" href="https://prow/1">some-job/1</a> failed 1 tests`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("expected %q in:\n%s", expected, html)
+		}
+	}
+	// job run 2 flaked, so it must not be listed as a worst run
+	if strings.Contains(html, "some-job/2") {
+		t.Errorf("did not expect flaked job run in:\n%s", html)
+	}
+}
+
+func TestHTMLForVerdictsEscapesNames(t *testing.T) {
+	suites := []*junit.TestSuite{
+		{
+			Name: "suite <a>",
+			TestCases: []*junit.TestCase{
+				{Name: "[sig-node] pods <script>alert(1)</script> & more", FailureOutput: &junit.FailureOutput{Message: "failed"}},
+			},
+		},
+	}
+
+	html := htmlForVerdicts("some-job", suites)
+	for _, expected := range []string{
+		"<b>suite &lt;a&gt;</b>: Failed",
+		"<li>[sig-node] pods &lt;script&gt;alert(1)&lt;/script&gt; &amp; more</li>",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("expected %q in:\n%s", expected, html)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Errorf("did not expect unescaped markup in:\n%s", html)
+	}
+}
@@ -0,0 +1,273 @@
+package jobrunaggregatorapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ArtifactCache keeps job run artifacts on disk, so that analyzing the same payload again doesn't download the
+// same gigabytes from GCS again.  Only artifacts that don't change once written may be cached.  When the cache
+// grows over maxBytes, the least recently used artifacts are removed.
+//
+// Artifacts are stored gzip-compressed, and an index next to the artifacts of each job run records their sha256 so
+// that artifacts truncated when the pod was evicted, or otherwise corrupted, are downloaded again instead of used.
+type ArtifactCache struct {
+	dir      string
+	maxBytes int64
+
+	lock sync.Mutex
+	// size is the number of bytes in dir, it is negative until dir was walked
+	size int64
+}
+
+func NewArtifactCache(dir string, maxBytes int64) *ArtifactCache {
+	return &ArtifactCache{dir: dir, maxBytes: maxBytes, size: -1}
+}
+
+const (
+	// artifactCacheIndexName is the index of the artifacts cached for a job run, in the directory of the job run
+	artifactCacheIndexName = "index.json"
+	// compressedArtifactSuffix is appended to the names of the cached artifacts
+	compressedArtifactSuffix = ".gz"
+)
+
+// artifactCacheIndexEntry describes a cached artifact.  The generation is only set for artifacts that may still
+// change, which are cached by generation as <object>@<generation>.
+type artifactCacheIndexEntry struct {
+	Object     string `json:"object"`
+	Generation string `json:"generation,omitempty"`
+	SHA256     string `json:"sha256"`
+}
+
+// artifactCacheIndex holds the entries of the artifacts of a job run by the object name they were cached with
+type artifactCacheIndex map[string]artifactCacheIndexEntry
+
+func (c *ArtifactCache) path(jobName, jobRunID, objectName string) (string, bool) {
+	if len(jobName) == 0 || len(jobRunID) == 0 || len(objectName) == 0 {
+		return "", false
+	}
+	for _, name := range []string{jobName, jobRunID, objectName} {
+		if strings.Contains(name, "..") {
+			return "", false
+		}
+	}
+	return filepath.Join(c.dir, jobName, jobRunID, filepath.FromSlash(objectName)+compressedArtifactSuffix), true
+}
+
+func (c *ArtifactCache) indexPath(jobName, jobRunID string) string {
+	return filepath.Join(c.dir, jobName, jobRunID, artifactCacheIndexName)
+}
+
+// readIndex returns an empty index when the job run has none or it can't be read, its artifacts are then missed
+func (c *ArtifactCache) readIndex(jobName, jobRunID string) artifactCacheIndex {
+	index := artifactCacheIndex{}
+	content, err := os.ReadFile(c.indexPath(jobName, jobRunID))
+	if err != nil {
+		return index
+	}
+	if err := json.Unmarshal(content, &index); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{"job": jobName, "jobRunID": jobRunID}).Warn("ignoring corrupted artifact cache index")
+		return artifactCacheIndex{}
+	}
+	return index
+}
+
+// Get returns the cached content of the object of the job run.
+func (c *ArtifactCache) Get(jobName, jobRunID, objectName string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	cachePath, ok := c.path(jobName, jobRunID, objectName)
+	if !ok {
+		return nil, false
+	}
+	entry, ok := c.readIndex(jobName, jobRunID)[objectName]
+	if !ok {
+		return nil, false
+	}
+	compressed, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, false
+	}
+	content, err := decompress(compressed)
+	if err == nil && sha256Sum(content) != entry.SHA256 {
+		err = fmt.Errorf("sha256 does not match the index")
+	}
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{"job": jobName, "jobRunID": jobRunID, "object": objectName}).Warn("removing corrupted cached artifact")
+		_ = os.Remove(cachePath)
+		return nil, false
+	}
+	// the modification time orders the eviction
+	now := time.Now()
+	_ = os.Chtimes(cachePath, now, now)
+	return content, true
+}
+
+// Put stores the content of the object of the job run, then evicts the least recently used artifacts if the cache
+// is over its size.  Failing to cache is not an error for the caller, who already has the content.
+func (c *ArtifactCache) Put(jobName, jobRunID, objectName string, content []byte) {
+	if c == nil {
+		return
+	}
+	cachePath, ok := c.path(jobName, jobRunID, objectName)
+	if !ok {
+		return
+	}
+	logger := logrus.WithFields(logrus.Fields{"job": jobName, "jobRunID": jobRunID, "object": objectName})
+	compressed, err := compress(content)
+	if err != nil {
+		logger.WithError(err).Warn("failed to compress artifact")
+		return
+	}
+	if err := WriteFileAtomically(cachePath, compressed); err != nil {
+		logger.WithError(err).Warn("failed to cache artifact")
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	// the artifact is only used once indexed, so a pod evicted before this leaves an artifact that is never read
+	index := c.readIndex(jobName, jobRunID)
+	object, generation, _ := strings.Cut(objectName, "@")
+	index[objectName] = artifactCacheIndexEntry{Object: object, Generation: generation, SHA256: sha256Sum(content)}
+	indexContent, err := json.Marshal(index)
+	if err == nil {
+		err = WriteFileAtomically(c.indexPath(jobName, jobRunID), indexContent)
+	}
+	if err != nil {
+		logger.WithError(err).Warn("failed to index cached artifact")
+		return
+	}
+
+	if c.size < 0 {
+		// the walk includes the artifact just written
+		c.size = 0
+		if err := c.evict(); err != nil {
+			logger.WithError(err).Warn("failed to evict cached artifacts")
+		}
+		return
+	}
+	c.size += int64(len(compressed))
+	if c.size > c.maxBytes {
+		if err := c.evict(); err != nil {
+			logger.WithError(err).Warn("failed to evict cached artifacts")
+		}
+	}
+}
+
+func compress(content []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	writer := gzip.NewWriter(buf)
+	if _, err := writer.Write(content); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(compressed []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func sha256Sum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+type cachedArtifact struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// evict walks the cache to learn its size, then removes the least recently used artifacts until it fits maxBytes.
+func (c *ArtifactCache) evict() error {
+	var artifacts []cachedArtifact
+	var size int64
+	err := filepath.WalkDir(c.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		// the indexes are small and never evicted, so they are left out of the size
+		if entry.IsDir() || entry.Name() == artifactCacheIndexName {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, cachedArtifact{path: path, size: info.Size(), modTime: info.ModTime()})
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to walk the artifact cache %q: %w", c.dir, err)
+	}
+
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].modTime.Before(artifacts[j].modTime)
+	})
+	for _, artifact := range artifacts {
+		if size <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(artifact.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			c.size = size
+			return err
+		}
+		size -= artifact.size
+	}
+	c.size = size
+	return nil
+}
+
+// WriteFileAtomically keeps the other commands sharing the working dir from reading a partially written file
+func WriteFileAtomically(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
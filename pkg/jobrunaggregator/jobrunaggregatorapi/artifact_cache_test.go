@@ -0,0 +1,86 @@
+package jobrunaggregatorapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtifactCache(t *testing.T) {
+	dir := t.TempDir()
+	// fits the first two artifacts but not the third
+	cache := NewArtifactCache(dir, compressedSize(t, "12345")+compressedSize(t, "1234"))
+
+	_, ok := cache.Get("job-a", "1", "artifacts/junit/junit_e2e.xml")
+	assert.False(t, ok, "nothing is cached yet")
+
+	cache.Put("job-a", "1", "artifacts/junit/junit_e2e.xml", []byte("12345"))
+	content, ok := cache.Get("job-a", "1", "artifacts/junit/junit_e2e.xml")
+	assert.True(t, ok)
+	assert.Equal(t, "12345", string(content))
+	assert.FileExists(t, filepath.Join(dir, "job-a", "1", "artifacts", "junit", "junit_e2e.xml.gz"))
+
+	// the first artifact was used more recently than the second, so the second is evicted first
+	cache.Put("job-a", "2", "finished.json", []byte("1234"))
+	old := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, "job-a", "2", "finished.json.gz"), old, old))
+	cache.Get("job-a", "1", "artifacts/junit/junit_e2e.xml")
+	cache.Put("job-b", "3", "finished.json", []byte("1234"))
+
+	_, ok = cache.Get("job-a", "2", "finished.json")
+	assert.False(t, ok, "the least recently used artifact should have been evicted")
+	_, ok = cache.Get("job-a", "1", "artifacts/junit/junit_e2e.xml")
+	assert.True(t, ok)
+	_, ok = cache.Get("job-b", "3", "finished.json")
+	assert.True(t, ok)
+
+	cache.Put("job-a", "1", "../../escape.xml", []byte("1"))
+	assert.NoFileExists(t, filepath.Join(dir, "..", "escape.xml"))
+
+	var disabled *ArtifactCache
+	disabled.Put("job-a", "1", "finished.json", []byte("1"))
+	_, ok = disabled.Get("job-a", "1", "finished.json")
+	assert.False(t, ok, "a nil cache caches nothing")
+}
+
+func TestArtifactCacheCorruption(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewArtifactCache(dir, 1024)
+
+	cache.Put("job-a", "1", "prowjob.json@1234", []byte("{}"))
+	index := cache.readIndex("job-a", "1")
+	assert.Equal(t, artifactCacheIndexEntry{Object: "prowjob.json", Generation: "1234", SHA256: sha256Sum([]byte("{}"))}, index["prowjob.json@1234"])
+
+	// truncated, like when the pod is evicted while writing
+	cachePath := filepath.Join(dir, "job-a", "1", "prowjob.json@1234.gz")
+	compressed, err := os.ReadFile(cachePath)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(cachePath, compressed[:len(compressed)/2], 0644))
+	_, ok := cache.Get("job-a", "1", "prowjob.json@1234")
+	assert.False(t, ok, "a truncated artifact should not be used")
+	assert.NoFileExists(t, cachePath)
+
+	// valid gzip with content that doesn't match the index
+	cache.Put("job-a", "1", "finished.json", []byte(`{"passed": true}`))
+	tampered, err := compress([]byte(`{"passed": false}`))
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "job-a", "1", "finished.json.gz"), tampered, 0644))
+	_, ok = cache.Get("job-a", "1", "finished.json")
+	assert.False(t, ok, "an artifact not matching its sha256 should not be used")
+
+	// artifacts missing from the index are not trusted
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "job-a", "1", "unindexed.xml.gz"), tampered, 0644))
+	_, ok = cache.Get("job-a", "1", "unindexed.xml")
+	assert.False(t, ok)
+}
+
+func compressedSize(t *testing.T, content string) int64 {
+	compressed, err := compress([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return int64(len(compressed))
+}
@@ -0,0 +1,76 @@
+package jobrunaggregatorapi
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// compileArtifactGlob turns a glob over the paths of artifacts relative to their job run into a regular expression.
+// * and ? match within a directory, **/ matches any number of directories, and a glob without any / matches the
+// base name of artifacts at any depth, so that cluster-data*.json finds the file wherever the step put it.
+func compileArtifactGlob(glob string) (*regexp.Regexp, error) {
+	if len(glob) == 0 {
+		return nil, fmt.Errorf("empty artifact glob")
+	}
+	expression := strings.Builder{}
+	expression.WriteString("^")
+	if !strings.Contains(glob, "/") {
+		expression.WriteString("(.*/)?")
+	}
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expression.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expression.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			expression.WriteString("[^/]*")
+		case glob[i] == '?':
+			expression.WriteString("[^/]")
+		default:
+			expression.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	expression.WriteString("$")
+	return regexp.Compile(expression.String())
+}
+
+func (j *gcsJobRun) ListArtifacts(ctx context.Context, glob string) ([]string, error) {
+	regex, err := compileArtifactGlob(glob)
+	if err != nil {
+		return nil, err
+	}
+	// verifies we have loaded the available file for the job run
+	if err := j.validateJobRunFromGCS(ctx); err != nil {
+		return nil, err
+	}
+
+	ret := []string{}
+	for _, name := range j.gcsFileNames {
+		if regex.MatchString(strings.TrimPrefix(strings.TrimPrefix(name, j.jobRunGCSBucketRoot), "/")) {
+			ret = append(ret, name)
+		}
+	}
+	return ret, nil
+}
+
+func (j *gcsJobRun) GetArtifacts(ctx context.Context, glob string) (map[string][]byte, error) {
+	names, err := j.ListArtifacts(ctx, glob)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[string][]byte{}
+	for _, name := range names {
+		content, err := j.GetContent(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		ret[name] = content
+	}
+	return ret, nil
+}
@@ -0,0 +1,56 @@
+package jobrunaggregatorapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileArtifactGlob(t *testing.T) {
+	tests := []struct {
+		glob       string
+		matches    []string
+		nonMatches []string
+	}{
+		{
+			glob: "artifacts/**/e2e-intervals*.json",
+			matches: []string{
+				"artifacts/e2e-intervals_everything.json",
+				"artifacts/e2e-aws/openshift-e2e-test/artifacts/junit/e2e-intervals_everything.json",
+			},
+			nonMatches: []string{
+				"e2e-intervals_everything.json",
+				"artifacts/e2e-aws/e2e-intervals_everything.xml",
+			},
+		},
+		{
+			glob: "cluster-data*.json",
+			matches: []string{
+				"cluster-data_e2e.json",
+				"artifacts/e2e-aws/openshift-e2e-test/artifacts/junit/cluster-data_e2e.json",
+			},
+			nonMatches: []string{
+				"artifacts/e2e-aws/cluster-data_e2e.json.gz",
+			},
+		},
+		{
+			glob:       "artifacts/*/build-log.txt",
+			matches:    []string{"artifacts/e2e-aws/build-log.txt"},
+			nonMatches: []string{"artifacts/e2e-aws/gather/build-log.txt", "build-log.txt"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.glob, func(t *testing.T) {
+			regex, err := compileArtifactGlob(tc.glob)
+			if !assert.NoError(t, err) {
+				return
+			}
+			for _, name := range tc.matches {
+				assert.True(t, regex.MatchString(name), "expected %q to match", name)
+			}
+			for _, name := range tc.nonMatches {
+				assert.False(t, regex.MatchString(name), "expected %q not to match", name)
+			}
+		})
+	}
+}
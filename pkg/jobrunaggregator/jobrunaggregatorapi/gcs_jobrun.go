@@ -3,17 +3,20 @@ package jobrunaggregatorapi
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
-	"google.golang.org/api/iterator"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	prowjobv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
@@ -23,7 +26,7 @@ import (
 
 type gcsJobRun struct {
 	// retrieval mechanisms
-	bkt *storage.BucketHandle
+	bkt ObjectBucket
 
 	jobRunGCSBucketRoot string
 	jobName             string
@@ -35,15 +38,36 @@ type gcsJobRun struct {
 	pathToContent map[string][]byte
 
 	jobRunGCSBucket string
+
+	options JobRunArtifactOptions
+}
+
+// JobRunArtifactOptions control how the artifacts of a job run are read.
+type JobRunArtifactOptions struct {
+	// JunitParseBudget bounds the work spent on every junit file of the job run.
+	JunitParseBudget JunitParseBudget
+	// ArtifactCache keeps the artifacts that were downloaded, nil disables caching.
+	ArtifactCache *ArtifactCache
+}
+
+var DefaultJobRunArtifactOptions = JobRunArtifactOptions{
+	JunitParseBudget: DefaultJunitParseBudget,
+}
+
+func NewGCSJobRun(bkt *storage.BucketHandle, jobGCSBucketRoot string, jobName, jobRunID string, jobRunGCSBucket string, options JobRunArtifactOptions) JobRunInfo {
+	return NewObjectBucketJobRun(NewGCSObjectBucket(bkt), jobGCSBucketRoot, jobName, jobRunID, jobRunGCSBucket, options)
 }
 
-func NewGCSJobRun(bkt *storage.BucketHandle, jobGCSBucketRoot string, jobName, jobRunID string, jobRunGCSBucket string) JobRunInfo {
+// NewObjectBucketJobRun reads the job run from any object storage laid out like the GCS bucket of Prow, like an S3
+// bucket of a Prow deployment outside of GCP.
+func NewObjectBucketJobRun(bkt ObjectBucket, jobGCSBucketRoot string, jobName, jobRunID string, jobRunGCSBucket string, options JobRunArtifactOptions) JobRunInfo {
 	return &gcsJobRun{
 		bkt:                 bkt,
 		jobRunGCSBucketRoot: path.Join(jobGCSBucketRoot, jobRunID),
 		jobName:             jobName,
 		jobRunID:            jobRunID,
 		jobRunGCSBucket:     jobRunGCSBucket,
+		options:             options,
 	}
 }
 
@@ -148,49 +172,23 @@ func (j *gcsJobRun) writeCache(ctx context.Context, parentDir string) error {
 }
 
 func (j *gcsJobRun) GetJobRunFromGCS(ctx context.Context) error {
-	query := &storage.Query{
-		// This ends up being the equivalent of:
-		// https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/logs/periodic-ci-openshift-release-master-nightly-4.9-upgrade-from-stable-4.8-e2e-metal-ipi-upgrade/1671747590984568832
-		// the next directory step is based on some bit of metadata I don't recognize
-		Prefix: j.jobRunGCSBucketRoot,
-
-		// TODO this field is apparently missing from this level of go/storage
-		// Omit owner and ACL fields for performance
-		// Projection: storage.ProjectionNoACL,
-	}
-
-	// Only retrieve the name and creation time for performance
-	if err := query.SetAttrSelection([]string{"Name", "Created"}); err != nil {
+	// This ends up being the equivalent of:
+	// https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/logs/periodic-ci-openshift-release-master-nightly-4.9-upgrade-from-stable-4.8-e2e-metal-ipi-upgrade/1671747590984568832
+	// this will list *all* files with the prefix.
+	names, err := j.bkt.ListObjects(ctx, j.jobRunGCSBucketRoot)
+	if err != nil {
 		return err
 	}
 
-	// Returns an iterator which iterates over the bucket query results.
-	// this will list *all* files with the query prefix.
-	it := j.bkt.Objects(ctx, query)
-
 	// Find the query results we're the most interested in.
-	for {
-		attrs, err := it.Next()
-		if err == iterator.Done {
-			// we're done adding values
-			break
-		}
-		if err != nil {
-			return err
-		}
-
-		// if we have a directory then skip
-		if len(attrs.Name) == 0 {
-			continue
-		}
-
+	for _, name := range names {
 		// add the name
-		j.AddGCSProwJobFileNames(attrs.Name)
+		j.AddGCSProwJobFileNames(name)
 
 		// see if it is a junit
-		if strings.HasSuffix(attrs.Name, ".xml") && strings.Contains(attrs.Name, "/junit") {
-			logrus.Debugf("found %s", attrs.Name)
-			j.AddGCSJunitPaths(attrs.Name)
+		if strings.HasSuffix(name, ".xml") && strings.Contains(name, "/junit") {
+			logrus.Debugf("found %s", name)
+			j.AddGCSJunitPaths(name)
 		}
 	}
 
@@ -216,51 +214,90 @@ func (j *gcsJobRun) GetCombinedJUnitTestSuites(ctx context.Context) (*junit.Test
 
 	testSuites := &junit.TestSuites{}
 	for _, junitFile := range j.GetGCSJunitPaths() {
-		logrus.Debug("getting junit file content content from GCS")
-		junitContent, err := j.GetContent(ctx, junitFile)
-		if err != nil {
-			return nil, fmt.Errorf("error getting content for jobrun/%v/%v %q: %w", j.GetJobName(), j.GetJobRunID(), junitFile, err)
-		}
-		// if the file was retrieve, but the content was empty, there is no work to be done.
-		if len(junitContent) == 0 {
+		currTestSuites, exceededReason, err := j.parseJunit(ctx, junitFile)
+		if errors.Is(err, errEmptyJunit) {
+			// if the file was retrieve, but the content was empty, there is no work to be done.
 			continue
 		}
-
-		// try as testsuites first just in case we are one
-		currTestSuites := &junit.TestSuites{}
-		testSuitesErr := xml.Unmarshal(junitContent, currTestSuites)
-		if testSuitesErr == nil {
-			// if this a test suites, add them here
-			testSuites.Suites = append(testSuites.Suites, currTestSuites.Suites...)
-			continue
-		}
-		if isParseFloatError(testSuitesErr) {
+		if isParseFloatError(err) {
 			// this was a testsuites, but we cannot read the file.  There is no choice to ignore errors so we suppress here
-			fmt.Fprintf(os.Stderr, "error parsing testsuites: %v", testSuitesErr)
+			logrus.WithError(err).WithFields(logrus.Fields{"job": j.GetJobName(), "jobRunID": j.GetJobRunID()}).Error("error parsing testsuites")
 			continue
 		}
-
-		currTestSuite := &junit.TestSuite{}
-		if testSuiteErr := xml.Unmarshal(junitContent, currTestSuite); testSuiteErr != nil {
-			// If we get an error reading from just one of the junits, don't end the world, just log it.
-			fmt.Printf("error parsing junit for jobrun/%v/%v %q: testsuiteError=%v  testsuitesError=%v",
-				j.GetJobName(), j.GetJobRunID(), junitFile, testSuiteErr.Error(), testSuitesErr.Error())
+		if errors.Is(err, errMalformedJunit) {
+			// If we get an error parsing just one of the junits, don't end the world, just log it.
+			logrus.WithError(err).WithFields(logrus.Fields{"job": j.GetJobName(), "jobRunID": j.GetJobRunID(), "junitFile": junitFile}).Error("error parsing junit")
 			continue
 		}
-		testSuites.Suites = append(testSuites.Suites, currTestSuite)
+		if err != nil {
+			// failing to read a junit must not look like a job run without its tests
+			return nil, err
+		}
+		if len(exceededReason) > 0 {
+			logrus.Warnf("junit for jobrun/%v/%v %q exceeded the %s budget and was only partially parsed", j.GetJobName(), j.GetJobRunID(), junitFile, exceededReason)
+			junitParseBudgetExceededTotal.With(prometheus.Labels{"job_name": j.GetJobName(), "reason": exceededReason}).Inc()
+		}
+		testSuites.Suites = append(testSuites.Suites, currTestSuites...)
 	}
 
 	return testSuites, nil
 }
 
+// parseJunit parses the junit file within the budget.  Under an output budget, junit files that aren't at hand
+// already are parsed as they are downloaded.
+func (j *gcsJobRun) parseJunit(ctx context.Context, junitFile string) ([]*junit.TestSuite, string, error) {
+	_, inMemory := j.pathToContent[junitFile]
+	if j.options.JunitParseBudget.MaxOutputBytes <= 0 || inMemory {
+		logrus.Debug("getting junit file content content from GCS")
+		junitContent, err := j.GetContent(ctx, junitFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("error getting content for jobrun/%v/%v %q: %w", j.GetJobName(), j.GetJobRunID(), junitFile, err)
+		}
+		if len(junitContent) == 0 {
+			return nil, "", errEmptyJunit
+		}
+		return parseJunitWithBudget(junitFile, junitContent, j.options.JunitParseBudget, time.Now)
+	}
+
+	if junitContent, ok := j.options.ArtifactCache.Get(j.jobName, j.jobRunID, j.cacheObjectName(junitFile)); ok {
+		return parseJunitWithBudget(junitFile, junitContent, j.options.JunitParseBudget, time.Now)
+	}
+	logrus.Debug("streaming junit file content from GCS")
+	version, err := j.getCurrentVersion(ctx, junitFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("error getting content for jobrun/%v/%v %q: %w", j.GetJobName(), j.GetJobRunID(), junitFile, err)
+	}
+	reader, err := j.bkt.OpenObject(ctx, junitFile, version)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading GCS content for jobrun/%v/%v at %q: %w", j.GetJobName(), j.GetJobRunID(), junitFile, err)
+	}
+	defer reader.Close()
+	counter := &countingReader{reader: reader}
+	suites, exceededReason, err := parseJunitReaderWithBudget(junitFile, counter, j.options.JunitParseBudget, time.Now)
+	artifactBytesDownloadedTotal.Add(float64(counter.count))
+	if err != nil && !errors.Is(err, errEmptyJunit) && !errors.Is(err, errMalformedJunit) {
+		return nil, "", fmt.Errorf("error reading GCS content for jobrun/%v/%v at %q: %w", j.GetJobName(), j.GetJobRunID(), junitFile, err)
+	}
+	return suites, exceededReason, err
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += n
+	return n, err
+}
+
 func isParseFloatError(err error) bool {
 	if err == nil {
 		return false
 	}
-	if strings.HasPrefix(err.Error(), "strconv.ParseFloat:") {
-		return true
-	}
-	return false
+	var numErr *strconv.NumError
+	return errors.As(err, &numErr) && numErr.Func == "ParseFloat"
 }
 
 func (j *gcsJobRun) GetOpenShiftTestsFilesWithPrefix(ctx context.Context, prefix string) (map[string]string, error) {
@@ -301,12 +338,33 @@ func (j *gcsJobRun) GetProwJob(ctx context.Context) (*prowjobv1.ProwJob, error)
 	if len(j.gcsProwJobPath) == 0 {
 		return nil, fmt.Errorf("missing prowjob path to GCS content for jobrun/%v/%v", j.GetJobName(), j.GetJobRunID())
 	}
+	if prowBytes, ok := j.options.ArtifactCache.Get(j.jobName, j.jobRunID, j.cacheObjectName(j.gcsProwJobPath)); ok {
+		return ParseProwJob(prowBytes)
+	}
 	logrus.Debugf("Fetching latest prowjob content from gcs: %s", j.gcsProwJobPath)
-	prowBytes, err := j.getCurrentContent(ctx, j.gcsProwJobPath)
+	prowBytes, err := j.getGenerationContent(ctx, j.gcsProwJobPath)
+	if err != nil {
+		return nil, err
+	}
+	prowJob, err := ParseProwJob(prowBytes)
 	if err != nil {
 		return nil, err
 	}
-	return ParseProwJob(prowBytes)
+	// the prowjob keeps changing until the job run completes
+	if prowJob.Status.CompletionTime != nil {
+		j.options.ArtifactCache.Put(j.jobName, j.jobRunID, j.cacheObjectName(j.gcsProwJobPath), prowBytes)
+	}
+	return prowJob, nil
+}
+
+// cacheObjectName names the object relative to the job run, which the artifact cache is already keyed by
+func (j *gcsJobRun) cacheObjectName(path string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(path, j.jobRunGCSBucketRoot), "/")
+}
+
+// isImmutableArtifact tells the artifacts that never change once they exist, which are the only ones cached
+func isImmutableArtifact(objectName string) bool {
+	return strings.HasSuffix(objectName, ".xml") || path.Base(objectName) == "finished.json"
 }
 
 func (j *gcsJobRun) GetContent(ctx context.Context, path string) ([]byte, error) {
@@ -317,7 +375,7 @@ func (j *gcsJobRun) GetContent(ctx context.Context, path string) ([]byte, error)
 		return content, nil
 	}
 
-	newContent, err := j.getCurrentContent(ctx, path)
+	newContent, err := j.getContentThroughCache(ctx, path)
 	if err != nil {
 		return nil, err
 	}
@@ -329,28 +387,77 @@ func (j *gcsJobRun) GetContent(ctx context.Context, path string) ([]byte, error)
 	return newContent, nil
 }
 
-func (j *gcsJobRun) getCurrentContent(ctx context.Context, path string) ([]byte, error) {
-	// Get an Object handle for the path
-	obj := j.bkt.Object(path)
+// getContentThroughCache reads the artifacts that can't change from the artifact cache when they were downloaded
+// before, other artifacts are cached by generation
+func (j *gcsJobRun) getContentThroughCache(ctx context.Context, path string) ([]byte, error) {
+	if !isImmutableArtifact(path) {
+		return j.getGenerationContent(ctx, path)
+	}
+	objectName := j.cacheObjectName(path)
+	if content, ok := j.options.ArtifactCache.Get(j.jobName, j.jobRunID, objectName); ok {
+		return content, nil
+	}
+	content, err := j.getCurrentContent(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > 0 {
+		j.options.ArtifactCache.Put(j.jobName, j.jobRunID, objectName, content)
+	}
+	return content, nil
+}
 
-	// use the object attributes to try to get the latest generation to try to retrieve the data without getting a cached
-	// version of data that does not match the latest content.  I don't know if this will work, but in the easy case
-	// it doesn't seem to fail.
-	objAttrs, err := obj.Attrs(ctx)
+func (j *gcsJobRun) getCurrentContent(ctx context.Context, path string) ([]byte, error) {
+	version, err := j.getCurrentVersion(ctx, path)
 	if err != nil {
-		return nil, fmt.Errorf("error reading GCS attributes for jobrun/%v/%v at %q: %w", j.GetJobName(), j.GetJobRunID(), path, err)
+		return nil, err
 	}
-	obj = obj.Generation(objAttrs.Generation)
+	return j.readObject(ctx, path, version)
+}
 
-	// Get an io.Reader for the object.
-	gcsReader, err := obj.NewReader(ctx)
+// getGenerationContent reads the latest generation of an artifact that may still change, like the prowjob of an
+// unfinished job run, from the artifact cache when that generation was downloaded before.
+func (j *gcsJobRun) getGenerationContent(ctx context.Context, path string) ([]byte, error) {
+	version, err := j.getCurrentVersion(ctx, path)
 	if err != nil {
-		return nil, fmt.Errorf("error reading GCS content for jobrun/%v/%v at %q: %w", j.GetJobName(), j.GetJobRunID(), path, err)
+		return nil, err
+	}
+	objectName := fmt.Sprintf("%s@%s", j.cacheObjectName(path), version)
+	if content, ok := j.options.ArtifactCache.Get(j.jobName, j.jobRunID, objectName); ok {
+		return content, nil
+	}
+	content, err := j.readObject(ctx, path, version)
+	if err != nil {
+		return nil, err
 	}
-	defer gcsReader.Close()
+	if len(content) > 0 {
+		j.options.ArtifactCache.Put(j.jobName, j.jobRunID, objectName, content)
+	}
+	return content, nil
+}
 
-	return io.ReadAll(gcsReader)
+func (j *gcsJobRun) getCurrentVersion(ctx context.Context, path string) (string, error) {
+	// use the latest generation to try to retrieve the data without getting a cached version of data that does not
+	// match the latest content.  I don't know if this will work, but in the easy case it doesn't seem to fail.
+	version, err := j.bkt.GetVersion(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("error reading GCS attributes for jobrun/%v/%v at %q: %w", j.GetJobName(), j.GetJobRunID(), path, err)
+	}
+	return version, nil
+}
 
+func (j *gcsJobRun) readObject(ctx context.Context, path, version string) ([]byte, error) {
+	reader, err := j.bkt.OpenObject(ctx, path, version)
+	if err != nil {
+		return nil, fmt.Errorf("error reading GCS content for jobrun/%v/%v at %q: %w", j.GetJobName(), j.GetJobRunID(), path, err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading GCS content for jobrun/%v/%v at %q: %w", j.GetJobName(), j.GetJobRunID(), path, err)
+	}
+	artifactBytesDownloadedTotal.Add(float64(len(content)))
+	return content, nil
 }
 
 func (j *gcsJobRun) getAllContent(ctx context.Context) (map[string][]byte, error) {
@@ -392,9 +499,16 @@ func (j *gcsJobRun) GetGCSArtifactURL() string {
 	return GetGCSArtifactURLForLocation(j.jobRunGCSBucketRoot, j.jobRunGCSBucket)
 }
 
+// IsFinished tells whether the job run uploaded its finished.json, which happens once its artifacts are uploaded
+// however long the job run took.
 func (j *gcsJobRun) IsFinished(ctx context.Context) bool {
 	content, err := j.GetContent(ctx, fmt.Sprintf("%s/finished.json", j.jobRunGCSBucketRoot))
 	if err != nil {
+		// a missing finished.json is the usual unfinished job run, other errors are worth knowing about since the job
+		// run is reported unfinished until they go away
+		if !errors.Is(err, storage.ErrObjectNotExist) {
+			logrus.WithError(err).Warnf("failed to read finished.json of jobrun/%v/%v", j.GetJobName(), j.GetJobRunID())
+		}
 		return false
 	}
 	if len(content) == 0 {
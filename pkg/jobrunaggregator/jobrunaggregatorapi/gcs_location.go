@@ -0,0 +1,72 @@
+package jobrunaggregatorapi
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	// periodicLogsDir holds the job runs of periodics and postsubmits, like logs/<job>/<run>
+	periodicLogsDir = "logs"
+	// presubmitLogsDir holds the job runs of presubmits, like pr-logs/pull/<org_repo>/<pr>/<job>/<run>
+	presubmitLogsDir = "pr-logs"
+	// batchPullRequest replaces <org_repo>/<pr> for presubmits testing several pull requests at once
+	batchPullRequest = "batch"
+)
+
+// JobRunGCSLocation is where the artifacts of a job, or of one of its job runs, are uploaded
+type JobRunGCSLocation struct {
+	// OrgRepo and PullRequest are only set for presubmits, Batch for presubmits of several pull requests
+	OrgRepo     string
+	PullRequest string
+	Batch       bool
+
+	JobName string
+	// JobRunID is empty for the location of the job
+	JobRunID string
+}
+
+// JobGCSPrefix returns the prefix holding every job run of the job, without trailing slash
+func (l JobRunGCSLocation) JobGCSPrefix() string {
+	switch {
+	case l.Batch:
+		return path.Join(presubmitLogsDir, "pull", batchPullRequest, l.JobName)
+	case len(l.PullRequest) > 0:
+		return path.Join(presubmitLogsDir, "pull", l.OrgRepo, l.PullRequest, l.JobName)
+	default:
+		return path.Join(periodicLogsDir, l.JobName)
+	}
+}
+
+// JobRunGCSPrefix returns the prefix holding the artifacts of the job run, without trailing slash
+func (l JobRunGCSLocation) JobRunGCSPrefix() string {
+	return path.Join(l.JobGCSPrefix(), l.JobRunID)
+}
+
+// ParseJobRunGCSLocation parses a path under logs/ or pr-logs/, down to the job, a job run, or any artifact of a job
+// run.  The job run ID is taken from its position in the layout, so nested artifact paths are not mistaken for it.
+func ParseJobRunGCSLocation(gcsPath string) (JobRunGCSLocation, error) {
+	parts := strings.Split(strings.Trim(gcsPath, "/"), "/")
+	location := JobRunGCSLocation{}
+	var rest []string
+	switch {
+	case parts[0] == periodicLogsDir && len(parts) >= 2:
+		rest = parts[1:]
+	case parts[0] == presubmitLogsDir && len(parts) >= 4 && parts[1] == "pull" && parts[2] == batchPullRequest:
+		location.Batch = true
+		rest = parts[3:]
+	case parts[0] == presubmitLogsDir && len(parts) >= 5 && parts[1] == "pull":
+		location.OrgRepo = parts[2]
+		location.PullRequest = parts[3]
+		rest = parts[4:]
+	default:
+		return JobRunGCSLocation{}, fmt.Errorf("%q is neither under %s/<job> nor under %s/pull/<org_repo>/<pr>/<job>", gcsPath, periodicLogsDir, presubmitLogsDir)
+	}
+
+	location.JobName = rest[0]
+	if len(rest) > 1 {
+		location.JobRunID = rest[1]
+	}
+	return location, nil
+}
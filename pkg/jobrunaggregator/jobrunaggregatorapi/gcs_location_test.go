@@ -0,0 +1,69 @@
+package jobrunaggregatorapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJobRunGCSLocation(t *testing.T) {
+	tests := []struct {
+		name      string
+		gcsPath   string
+		expected  JobRunGCSLocation
+		jobPrefix string
+		expectErr bool
+	}{
+		{
+			name:      "periodic job",
+			gcsPath:   "logs/periodic-e2e-aws/",
+			expected:  JobRunGCSLocation{JobName: "periodic-e2e-aws"},
+			jobPrefix: "logs/periodic-e2e-aws",
+		},
+		{
+			name:      "artifact of a periodic job run",
+			gcsPath:   "logs/periodic-e2e-aws/1000/artifacts/e2e-aws/junit.xml",
+			expected:  JobRunGCSLocation{JobName: "periodic-e2e-aws", JobRunID: "1000"},
+			jobPrefix: "logs/periodic-e2e-aws",
+		},
+		{
+			name:    "presubmit job run",
+			gcsPath: "pr-logs/pull/openshift_origin/123/pull-ci-e2e-aws/2000/prowjob.json",
+			expected: JobRunGCSLocation{
+				OrgRepo:     "openshift_origin",
+				PullRequest: "123",
+				JobName:     "pull-ci-e2e-aws",
+				JobRunID:    "2000",
+			},
+			jobPrefix: "pr-logs/pull/openshift_origin/123/pull-ci-e2e-aws",
+		},
+		{
+			name:      "batch job run",
+			gcsPath:   "pr-logs/pull/batch/pull-ci-e2e-aws/3000/",
+			expected:  JobRunGCSLocation{Batch: true, JobName: "pull-ci-e2e-aws", JobRunID: "3000"},
+			jobPrefix: "pr-logs/pull/batch/pull-ci-e2e-aws",
+		},
+		{
+			name:      "presubmit without job",
+			gcsPath:   "pr-logs/pull/openshift_origin/123",
+			expectErr: true,
+		},
+		{
+			name:      "unknown layout",
+			gcsPath:   "artifacts/periodic-e2e-aws/1000",
+			expectErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			location, err := ParseJobRunGCSLocation(tc.gcsPath)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, location)
+			assert.Equal(t, tc.jobPrefix, location.JobGCSPrefix())
+		})
+	}
+}
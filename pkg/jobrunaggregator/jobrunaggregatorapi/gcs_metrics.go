@@ -0,0 +1,30 @@
+package jobrunaggregatorapi
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	gcsObjectsListedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "jobrunaggregator_gcs_objects_listed_total",
+			Help: "Number of objects and directories listed from GCS.",
+		},
+	)
+	gcsListingRetriesTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "jobrunaggregator_gcs_listing_retries_total",
+			Help: "Number of pages of GCS listings listed again after GCS throttled or failed.",
+		},
+	)
+	artifactBytesDownloadedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "jobrunaggregator_artifact_bytes_downloaded_total",
+			Help: "Number of bytes of job run artifacts downloaded from the object storage, excluding the artifact cache hits.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(gcsObjectsListedTotal, gcsListingRetriesTotal, artifactBytesDownloadedTotal)
+}
@@ -0,0 +1,24 @@
+package jobrunaggregatorapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// saveWithInsertID saves the columns of row like the BigQuery inserter does for structs, with an insertID derived from
+// the keys identifying the row.  The insertID is best effort: BigQuery drops the rows streamed again with the same
+// insertID for about a minute, so it makes retried inserts idempotent.  A job run loaded again later is not
+// deduplicated, the loaders prevent that with their checkpoints and by skipping the job runs already in the table.
+func saveWithInsertID(row interface{}, keys ...string) (map[string]bigquery.Value, string, error) {
+	schema, err := bigquery.InferSchema(row)
+	if err != nil {
+		return nil, "", err
+	}
+	// the sum bounds the length of the insertID however long the keys are
+	sum := sha256.Sum256([]byte(strings.Join(keys, "\x00")))
+	saver := &bigquery.StructSaver{Struct: row, Schema: schema, InsertID: hex.EncodeToString(sum[:])}
+	return saver.Save()
+}
@@ -0,0 +1,93 @@
+package jobrunaggregatorapi
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackendDisruptionRowSave(t *testing.T) {
+	row := BackendDisruptionRow{
+		BackendName:       "kube-api-new-connections",
+		DisruptionSeconds: 3,
+		JobName:           bigquery.NullString{StringVal: "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn-upgrade", Valid: true},
+		JobRunName:        "1765432109876543210",
+	}
+	values, insertID, err := row.Save()
+	assert.NoError(t, err)
+	assert.Len(t, insertID, 64)
+	assert.Equal(t, "kube-api-new-connections", values["BackendName"])
+	assert.Equal(t, 3, values["DisruptionSeconds"])
+	assert.Len(t, values, 10)
+
+	retried := row
+	retried.DisruptionSeconds = 4
+	_, retriedInsertID, err := retried.Save()
+	assert.NoError(t, err)
+	assert.Equal(t, insertID, retriedInsertID, "the same backend of the same job run must keep its insertID")
+
+	otherBackend := row
+	otherBackend.BackendName = "openshift-api-new-connections"
+	_, otherInsertID, err := otherBackend.Save()
+	assert.NoError(t, err)
+	assert.NotEqual(t, insertID, otherInsertID)
+}
+
+func TestAlertRowSave(t *testing.T) {
+	row := AlertRow{Name: "KubePodNotReady", Namespace: "openshift-etcd", Level: "Warning", JobRunName: "1765432109876543210"}
+	_, insertID, err := row.Save()
+	assert.NoError(t, err)
+
+	// the keys are separated, so that their values can't be shifted from one to the other
+	shifted := AlertRow{Name: "KubePodNotReadyopenshift-etcd", Level: "Warning", JobRunName: "1765432109876543210"}
+	_, shiftedInsertID, err := shifted.Save()
+	assert.NoError(t, err)
+	assert.NotEqual(t, insertID, shiftedInsertID)
+}
+
+func TestTestRunRowSave(t *testing.T) {
+	row := TestRunRow{
+		TestName:        "install should succeed: overall",
+		TestSuite:       "cluster install",
+		Status:          "Failed",
+		JobName:         "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn",
+		JobRunName:      "1765432109876543210",
+		JobRunStartTime: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+	values, insertID, err := row.Save()
+	assert.NoError(t, err)
+	assert.Len(t, insertID, 64)
+	assert.Len(t, values, 6)
+
+	retried := row
+	retried.Status = "Passed"
+	_, retriedInsertID, err := retried.Save()
+	assert.NoError(t, err)
+	assert.Equal(t, insertID, retriedInsertID, "the same test of the same job run must keep its insertID")
+
+	otherSuite := row
+	otherSuite.TestSuite = "openshift-tests"
+	_, otherInsertID, err := otherSuite.Save()
+	assert.NoError(t, err)
+	assert.NotEqual(t, insertID, otherInsertID)
+}
+
+func TestJobRunRowSave(t *testing.T) {
+	row := JobRunRow{Name: "1765432109876543210", JobName: "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn", Status: "success"}
+	values, insertID, err := row.Save()
+	assert.NoError(t, err)
+	assert.Len(t, values, 8)
+
+	retried := row
+	retried.Status = "failure"
+	_, retriedInsertID, err := retried.Save()
+	assert.NoError(t, err)
+	assert.Equal(t, insertID, retriedInsertID, "the same job run must keep its insertID")
+
+	// the table is part of the keys, so a job run doesn't share its insertID with the test runs of the same names
+	_, testRunInsertID, err := TestRunRow{JobRunName: row.Name}.Save()
+	assert.NoError(t, err)
+	assert.NotEqual(t, insertID, testRunInsertID)
+}
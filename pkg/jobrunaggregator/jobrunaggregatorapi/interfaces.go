@@ -39,6 +39,12 @@ type JobRunInfo interface {
 	// and returns that content indexed by local filename.  This is useful for things like back-disruption and alerts.
 	GetOpenShiftTestsFilesWithPrefix(ctx context.Context, prefix string) (map[string]string, error)
 	GetContent(ctx context.Context, path string) ([]byte, error)
+	// ListArtifacts returns the names of the artifacts of the job run matching the glob, relative to the job run, like
+	// artifacts/**/e2e-intervals*.json.  Globs without any / match the base name at any depth, like cluster-data*.json.
+	// The names can be passed to GetContent.
+	ListArtifacts(ctx context.Context, glob string) ([]string, error)
+	// GetArtifacts downloads the artifacts matching the glob like ListArtifacts, indexed by name
+	GetArtifacts(ctx context.Context, glob string) (map[string][]byte, error)
 	ClearAllContent()
 
 	WriteCache(ctx context.Context, parentDir string) error
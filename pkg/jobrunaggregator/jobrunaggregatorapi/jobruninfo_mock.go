@@ -82,6 +82,21 @@ func (mr *MockJobRunInfoMockRecorder) ClearAllContent() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearAllContent", reflect.TypeOf((*MockJobRunInfo)(nil).ClearAllContent))
 }
 
+// GetArtifacts mocks base method.
+func (m *MockJobRunInfo) GetArtifacts(arg0 context.Context, arg1 string) (map[string][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArtifacts", arg0, arg1)
+	ret0, _ := ret[0].(map[string][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArtifacts indicates an expected call of GetArtifacts.
+func (mr *MockJobRunInfoMockRecorder) GetArtifacts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArtifacts", reflect.TypeOf((*MockJobRunInfo)(nil).GetArtifacts), arg0, arg1)
+}
+
 // GetCombinedJUnitTestSuites mocks base method.
 func (m *MockJobRunInfo) GetCombinedJUnitTestSuites(arg0 context.Context) (*junit.TestSuites, error) {
 	m.ctrl.T.Helper()
@@ -254,6 +269,21 @@ func (mr *MockJobRunInfoMockRecorder) IsFinished(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsFinished", reflect.TypeOf((*MockJobRunInfo)(nil).IsFinished), arg0)
 }
 
+// ListArtifacts mocks base method.
+func (m *MockJobRunInfo) ListArtifacts(arg0 context.Context, arg1 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListArtifacts", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListArtifacts indicates an expected call of ListArtifacts.
+func (mr *MockJobRunInfoMockRecorder) ListArtifacts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListArtifacts", reflect.TypeOf((*MockJobRunInfo)(nil).ListArtifacts), arg0, arg1)
+}
+
 // SetGCSProwJobPath mocks base method.
 func (m *MockJobRunInfo) SetGCSProwJobPath(arg0 string) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,176 @@
+package jobrunaggregatorapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+const (
+	// JunitParseBudgetTestSuiteName is the suite of the marker test case added for every junit file that was
+	// only partially parsed.
+	JunitParseBudgetTestSuiteName = "junit parse budget"
+
+	junitParseBudgetExceededSize     = "size"
+	junitParseBudgetExceededDuration = "duration"
+)
+
+// JunitParseBudget bounds the work spent on a single junit file.  A few job runs produce junit files of hundreds of
+// megabytes, parsing them stalls the analyzers for everyone else.  Zero values are unbounded.
+type JunitParseBudget struct {
+	MaxBytes    int
+	MaxDuration time.Duration
+	// MaxOutputBytes truncates the system-out and system-err of every test case.  Setting it also streams junit
+	// files from GCS instead of downloading them whole, which keeps them out of the artifact cache.
+	MaxOutputBytes int
+}
+
+var DefaultJunitParseBudget = JunitParseBudget{
+	MaxBytes:    100 * 1024 * 1024,
+	MaxDuration: 2 * time.Minute,
+}
+
+var junitParseBudgetExceededTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "jobrunaggregator_junit_parse_budget_exceeded_total",
+		Help: "Number of junit files only partially parsed because they exceeded the size or duration budget.",
+	},
+	[]string{"job_name", "reason"},
+)
+
+func init() {
+	prometheus.MustRegister(junitParseBudgetExceededTotal)
+}
+
+var (
+	errJunitParseDeadlineExceeded = errors.New("junit parse deadline exceeded")
+	errJunitParseSizeExceeded     = errors.New("junit parse size exceeded")
+
+	// errEmptyJunit is returned for junit files without any content, there is nothing to parse in them.
+	errEmptyJunit = errors.New("empty junit")
+	// errMalformedJunit is returned for junit files that were read whole but are not valid junit.  Errors reading
+	// the file are never wrapped in it, so that a failed download is not mistaken for a file without tests.
+	errMalformedJunit = errors.New("malformed junit")
+)
+
+// readErrorReader remembers the first error of the underlying reader, so that it can be told apart from the errors of
+// the decoder reading from it.
+type readErrorReader struct {
+	reader io.Reader
+	count  int
+	err    error
+}
+
+func (r *readErrorReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += n
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// sizeLimitReader fails reads past the first remaining bytes, unless the content ends there.  Content that parses
+// within the limit, like when only trailing whitespace is past it, is never read further.
+type sizeLimitReader struct {
+	reader    io.Reader
+	remaining int
+}
+
+func (r *sizeLimitReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		probe := make([]byte, 1)
+		for {
+			n, err := r.reader.Read(probe)
+			if n > 0 {
+				return 0, errJunitParseSizeExceeded
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	if len(p) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= n
+	return n, err
+}
+
+// deadlineReader fails reads once the deadline passed, which is the only way to interrupt an xml.Decoder.
+type deadlineReader struct {
+	reader   io.Reader
+	deadline time.Time
+	now      func() time.Time
+}
+
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	if r.now().After(r.deadline) {
+		return 0, errJunitParseDeadlineExceeded
+	}
+	// small reads keep the decoder coming back to check the deadline
+	if len(p) > 4096 {
+		p = p[:4096]
+	}
+	return r.reader.Read(p)
+}
+
+// parseJunitWithBudget parses junitContent as either <testsuites> or a single <testsuite>.  When the budget is
+// exceeded, the suites parsed so far are returned along with a skipped marker test case naming the file, instead of
+// an error.  exceededReason is empty when the whole file was parsed.  Content that isn't valid junit fails with
+// errMalformedJunit, empty content with errEmptyJunit.
+func parseJunitWithBudget(junitFile string, junitContent []byte, budget JunitParseBudget, now func() time.Time) (suites []*junit.TestSuite, exceededReason string, err error) {
+	return parseJunitReaderWithBudget(junitFile, bytes.NewReader(junitContent), budget, now)
+}
+
+// parseJunitReaderWithBudget is parseJunitWithBudget for junit read as it is parsed, like straight from GCS.  The
+// suites are decoded one test case at a time, so that only the outputs within the budget are ever kept in memory.
+func parseJunitReaderWithBudget(junitFile string, reader io.Reader, budget JunitParseBudget, now func() time.Time) (suites []*junit.TestSuite, exceededReason string, err error) {
+	source := &readErrorReader{reader: reader}
+	reader = source
+	if budget.MaxBytes > 0 {
+		reader = &sizeLimitReader{reader: reader, remaining: budget.MaxBytes}
+	}
+	if budget.MaxDuration > 0 {
+		reader = &deadlineReader{reader: reader, deadline: now().Add(budget.MaxDuration), now: now}
+	}
+	err = junit.DecodeTestSuites(reader, junit.DecodeOptions{MaxOutputBytes: budget.MaxOutputBytes}, func(suite *junit.TestSuite) {
+		suites = append(suites, suite)
+	})
+	switch {
+	case errors.Is(err, errJunitParseDeadlineExceeded):
+		exceededReason = junitParseBudgetExceededDuration
+	case errors.Is(err, errJunitParseSizeExceeded):
+		exceededReason = junitParseBudgetExceededSize
+	case source.err != nil:
+		return nil, "", fmt.Errorf("error reading junit %q: %w", junitFile, source.err)
+	case err == io.EOF && source.count == 0:
+		return nil, "", errEmptyJunit
+	case err != nil:
+		return nil, "", fmt.Errorf("%w %q: %w", errMalformedJunit, junitFile, err)
+	}
+	if len(exceededReason) == 0 {
+		return suites, "", nil
+	}
+
+	marker := &junit.TestCase{
+		Name: fmt.Sprintf("junit file %s exceeded the %s budget and was only partially parsed", junitFile, exceededReason),
+		SkipMessage: &junit.SkipMessage{
+			Message: fmt.Sprintf("budget is %d bytes and %v, test results after the cutoff are missing", budget.MaxBytes, budget.MaxDuration),
+		},
+	}
+	suites = append(suites, &junit.TestSuite{
+		Name:       JunitParseBudgetTestSuiteName,
+		NumTests:   1,
+		NumSkipped: 1,
+		TestCases:  []*junit.TestCase{marker},
+	})
+	return suites, exceededReason, nil
+}
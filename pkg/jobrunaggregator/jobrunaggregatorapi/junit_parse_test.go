@@ -0,0 +1,109 @@
+package jobrunaggregatorapi
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testJunitSuites = `<testsuites>
+<testsuite name="openshift-tests" tests="3">
+<testcase name="first passes"></testcase>
+<testcase name="second fails"><failure message="boom">output</failure></testcase>
+<testcase name="third passes"></testcase>
+</testsuite>
+</testsuites>`
+
+func TestParseJunitWithBudget(t *testing.T) {
+	start := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("within budget", func(t *testing.T) {
+		suites, exceededReason, err := parseJunitWithBudget("junit.xml", []byte(testJunitSuites), DefaultJunitParseBudget, time.Now)
+		assert.NoError(t, err)
+		assert.Empty(t, exceededReason)
+		assert.Len(t, suites, 1)
+		assert.Len(t, suites[0].TestCases, 3)
+	})
+
+	t.Run("single testsuite", func(t *testing.T) {
+		suites, exceededReason, err := parseJunitWithBudget("junit.xml", []byte(`<testsuite name="e2e"><testcase name="only"></testcase></testsuite>`), DefaultJunitParseBudget, time.Now)
+		assert.NoError(t, err)
+		assert.Empty(t, exceededReason)
+		assert.Len(t, suites, 1)
+		assert.Equal(t, "e2e", suites[0].Name)
+	})
+
+	t.Run("unparseable", func(t *testing.T) {
+		_, _, err := parseJunitWithBudget("junit.xml", []byte(`<testsuites><testsuite>`), DefaultJunitParseBudget, time.Now)
+		assert.Error(t, err)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		// cut inside the failing test case, which must not be reported as a pass
+		cutoff := strings.Index(testJunitSuites, "<failure")
+		suites, exceededReason, err := parseJunitWithBudget("artifacts/junit.xml", []byte(testJunitSuites), JunitParseBudget{MaxBytes: cutoff}, time.Now)
+		assert.NoError(t, err)
+		assert.Equal(t, "size", exceededReason)
+		assert.Len(t, suites, 2)
+		assert.Len(t, suites[0].TestCases, 1)
+		assert.Equal(t, "first passes", suites[0].TestCases[0].Name)
+		assert.Equal(t, JunitParseBudgetTestSuiteName, suites[1].Name)
+		assert.Equal(t, "junit file artifacts/junit.xml exceeded the size budget and was only partially parsed", suites[1].TestCases[0].Name)
+		assert.NotNil(t, suites[1].TestCases[0].SkipMessage)
+	})
+
+	t.Run("too slow", func(t *testing.T) {
+		now := start
+		clock := func() time.Time {
+			// every read takes a minute
+			now = now.Add(time.Minute)
+			return now
+		}
+		suites, exceededReason, err := parseJunitWithBudget("junit.xml", []byte(testJunitSuites), JunitParseBudget{MaxDuration: 30 * time.Second}, clock)
+		assert.NoError(t, err)
+		assert.Equal(t, "duration", exceededReason)
+		assert.Len(t, suites, 1)
+		assert.Equal(t, JunitParseBudgetTestSuiteName, suites[0].Name)
+	})
+}
+
+func TestParseJunitReaderWithBudget(t *testing.T) {
+	t.Run("within size budget", func(t *testing.T) {
+		suites, exceededReason, err := parseJunitReaderWithBudget("junit.xml", strings.NewReader(testJunitSuites+"\n\n"), JunitParseBudget{MaxBytes: len(testJunitSuites)}, time.Now)
+		assert.NoError(t, err)
+		assert.Empty(t, exceededReason)
+		assert.Len(t, suites, 1)
+	})
+
+	t.Run("outputs truncated", func(t *testing.T) {
+		junitWithOutput := `<testsuite name="e2e"><testcase name="noisy"><system-out>` + strings.Repeat("x", 100) + `</system-out></testcase></testsuite>`
+		suites, exceededReason, err := parseJunitReaderWithBudget("junit.xml", strings.NewReader(junitWithOutput), JunitParseBudget{MaxOutputBytes: 10}, time.Now)
+		assert.NoError(t, err)
+		assert.Empty(t, exceededReason)
+		if assert.Len(t, suites, 1) && assert.Len(t, suites[0].TestCases, 1) {
+			assert.True(t, strings.HasPrefix(suites[0].TestCases[0].SystemOut, strings.Repeat("x", 10)+"\n"))
+		}
+	})
+	t.Run("empty", func(t *testing.T) {
+		_, _, err := parseJunitReaderWithBudget("junit.xml", strings.NewReader(""), JunitParseBudget{}, time.Now)
+		assert.ErrorIs(t, err, errEmptyJunit)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, _, err := parseJunitReaderWithBudget("junit.xml", strings.NewReader("<testsuite><testcase"), JunitParseBudget{}, time.Now)
+		assert.ErrorIs(t, err, errMalformedJunit)
+	})
+
+	t.Run("read error is not malformed", func(t *testing.T) {
+		readErr := errors.New("connection reset by peer")
+		reader := io.MultiReader(strings.NewReader(`<testsuite name="e2e"><testcase name="one"/>`), iotest.ErrReader(readErr))
+		_, _, err := parseJunitReaderWithBudget("junit.xml", reader, JunitParseBudget{}, time.Now)
+		assert.ErrorIs(t, err, readErr)
+		assert.NotErrorIs(t, err, errMalformedJunit)
+	})
+}
@@ -0,0 +1,142 @@
+package jobrunaggregatorapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// ObjectBucket is the object storage the artifacts of job runs are read from.  Missing objects are reported with
+// errors wrapping storage.ErrObjectNotExist whatever the storage.
+type ObjectBucket interface {
+	// ListObjects returns the names of all the objects whose name starts with prefix
+	ListObjects(ctx context.Context, prefix string) ([]string, error)
+	// GetVersion returns the current version of the object, which changes whenever its content does
+	GetVersion(ctx context.Context, name string) (string, error)
+	// OpenObject opens the version of the object for reading, the caller closes it
+	OpenObject(ctx context.Context, name, version string) (io.ReadCloser, error)
+}
+
+type gcsObjectBucket struct {
+	bkt *storage.BucketHandle
+}
+
+func NewGCSObjectBucket(bkt *storage.BucketHandle) ObjectBucket {
+	return &gcsObjectBucket{bkt: bkt}
+}
+
+func (b *gcsObjectBucket) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	query := &storage.Query{Prefix: prefix}
+	// Only retrieve the name for performance
+	if err := query.SetAttrSelection([]string{"Name"}); err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	err := ListGCSObjects(ctx, b.bkt, query, func(attrs *storage.ObjectAttrs) (bool, error) {
+		// if we have a directory then skip
+		if len(attrs.Name) > 0 {
+			names = append(names, attrs.Name)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+const gcsListingPageSize = 1000
+
+var (
+	// gcsListingBackoff spaces the attempts to list a page of objects while GCS throttles or fails
+	gcsListingBackoff = wait.Backoff{
+		Steps:    6,
+		Duration: time.Second,
+		Factor:   2.0,
+		Jitter:   0.2,
+		Cap:      time.Minute,
+	}
+	// gcsListingLimiter bounds the pages of objects listed per second by the whole process, so that concurrent
+	// listings don't get throttled in the first place
+	gcsListingLimiter = rate.NewLimiter(rate.Limit(20), 20)
+)
+
+// ListGCSObjects calls fn with the objects matching the query, until fn returns false.  Unlike iterating over
+// bkt.Objects, a page failing on throttling or server errors is retried instead of ending the listing.
+func ListGCSObjects(ctx context.Context, bkt *storage.BucketHandle, query *storage.Query, fn func(attrs *storage.ObjectAttrs) (bool, error)) error {
+	pageToken := ""
+	for {
+		var page []*storage.ObjectAttrs
+		var nextPageToken string
+		attempt := 0
+		err := retry.OnError(gcsListingBackoff, isTransientGCSError, func() error {
+			if attempt++; attempt > 1 {
+				gcsListingRetriesTotal.Inc()
+			}
+			if err := gcsListingLimiter.Wait(ctx); err != nil {
+				return err
+			}
+			page = nil
+			var err error
+			nextPageToken, err = iterator.NewPager(bkt.Objects(ctx, query), gcsListingPageSize, pageToken).NextPage(&page)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		gcsObjectsListedTotal.Add(float64(len(page)))
+		for _, attrs := range page {
+			more, err := fn(attrs)
+			if err != nil || !more {
+				return err
+			}
+		}
+		if len(nextPageToken) == 0 {
+			return nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+func isTransientGCSError(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError {
+		logrus.WithError(err).Warn("retrying GCS listing")
+		return true
+	}
+	return false
+}
+
+// GetVersion returns the generation of the object
+func (b *gcsObjectBucket) GetVersion(ctx context.Context, name string) (string, error) {
+	attrs, err := b.bkt.Object(name).Attrs(ctx)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(attrs.Generation, 10), nil
+}
+
+func (b *gcsObjectBucket) OpenObject(ctx context.Context, name, version string) (io.ReadCloser, error) {
+	generation, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid generation %q: %w", version, err)
+	}
+	return b.bkt.Object(name).Generation(generation).NewReader(ctx)
+}
@@ -0,0 +1,16 @@
+package jobrunaggregatorapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/googleapi"
+)
+
+func TestIsTransientGCSError(t *testing.T) {
+	assert.True(t, isTransientGCSError(&googleapi.Error{Code: 429}))
+	assert.True(t, isTransientGCSError(fmt.Errorf("listing: %w", &googleapi.Error{Code: 503})))
+	assert.False(t, isTransientGCSError(&googleapi.Error{Code: 403}))
+	assert.False(t, isTransientGCSError(fmt.Errorf("boom")))
+}
@@ -0,0 +1,77 @@
+package jobrunaggregatorapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+type s3ObjectBucket struct {
+	client s3iface.S3API
+	bucket string
+}
+
+// NewS3ObjectBucket reads the artifacts of job runs from an S3 compatible bucket, like a MinIO one.
+func NewS3ObjectBucket(client s3iface.S3API, bucket string) ObjectBucket {
+	return &s3ObjectBucket{client: client, bucket: bucket}
+}
+
+func (b *s3ObjectBucket) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	names := []string{}
+	err := b.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			names = append(names, aws.StringValue(object.Key))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// GetVersion returns the ETag of the object
+func (b *s3ObjectBucket) GetVersion(ctx context.Context, name string) (string, error) {
+	head, err := b.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(name),
+	})
+	if err != nil {
+		return "", toObjectBucketError(err)
+	}
+	return aws.StringValue(head.ETag), nil
+}
+
+func (b *s3ObjectBucket) OpenObject(ctx context.Context, name, version string) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(name),
+	}
+	if len(version) > 0 {
+		input.IfMatch = aws.String(version)
+	}
+	object, err := b.client.GetObjectWithContext(ctx, input)
+	if err != nil {
+		return nil, toObjectBucketError(err)
+	}
+	return object.Body, nil
+}
+
+// toObjectBucketError reports missing objects the way GCS does, which HeadObject only tells with a 404
+func toObjectBucketError(err error) error {
+	var requestErr awserr.RequestFailure
+	if errors.As(err, &requestErr) && (requestErr.StatusCode() == 404 || requestErr.Code() == s3.ErrCodeNoSuchKey) {
+		return fmt.Errorf("%w: %v", storage.ErrObjectNotExist, err)
+	}
+	return err
+}
@@ -128,3 +128,18 @@ type KnownAlertRow struct {
 	LastObserved   time.Time
 	Results        int
 }
+
+// JobRunDurationStatisticsRow summarizes how long the finished runs of a job took.  TIMESTAMP_DIFF returns INT64
+// and BigQuery only loads INTEGER columns into integer fields.
+type JobRunDurationStatisticsRow struct {
+	JobName            string
+	P95DurationSeconds int64
+	MaxDurationSeconds int64
+}
+
+// JobRunSuccessStatisticsRow counts how many of the finished runs of a job succeeded.
+type JobRunSuccessStatisticsRow struct {
+	JobName           string
+	JobRuns           int64
+	SuccessfulJobRuns int64
+}
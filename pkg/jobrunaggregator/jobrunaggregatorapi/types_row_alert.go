@@ -22,3 +22,8 @@ type AlertRow struct {
 	MasterNodesUpdated bigquery.NullString
 	JobRunStatus       bigquery.NullString
 }
+
+// Save identifies the row by job run and alert, so that the rows of a retried insert are not duplicated
+func (r AlertRow) Save() (map[string]bigquery.Value, string, error) {
+	return saveWithInsertID(r, AlertsTableName, r.JobRunName, r.Name, r.Namespace, r.Level)
+}
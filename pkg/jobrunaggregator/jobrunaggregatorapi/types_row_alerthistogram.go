@@ -0,0 +1,20 @@
+package jobrunaggregatorapi
+
+import (
+	"time"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// AlertHistogramRow counts the job runs of a job that fired an alert for the same seconds, and when the first and the
+// last of them started.  The rows are aggregated from the Alerts table.
+type AlertHistogramRow struct {
+	JobName        bigquery.NullString
+	AlertName      string
+	AlertNamespace string
+	AlertLevel     string
+	AlertSeconds   int
+	JobRuns        int
+	FirstObserved  time.Time
+	LastObserved   time.Time
+}
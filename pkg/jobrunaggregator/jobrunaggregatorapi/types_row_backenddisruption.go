@@ -18,3 +18,8 @@ type BackendDisruptionRow struct {
 	MasterNodesUpdated bigquery.NullString
 	JobRunStatus       bigquery.NullString
 }
+
+// Save identifies the row by job run and backend, so that the rows of a retried insert are not duplicated
+func (r BackendDisruptionRow) Save() (map[string]bigquery.Value, string, error) {
+	return saveWithInsertID(r, BackendDisruptionTableName, r.JobRunName, r.BackendName)
+}
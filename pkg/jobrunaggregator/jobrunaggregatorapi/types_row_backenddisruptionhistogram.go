@@ -0,0 +1,20 @@
+package jobrunaggregatorapi
+
+import (
+	"time"
+
+	"cloud.google.com/go/bigquery"
+)
+
+const BackendDisruptionHistogramViewName = "BackendDisruptionHistogram"
+
+// BackendDisruptionHistogramRow counts the job runs of a job that started the same day and were disrupted for the
+// same seconds on a backend.  The rows are read from the materialized view of the BackendDisruption table.
+type BackendDisruptionHistogramRow struct {
+	JobName            bigquery.NullString
+	MasterNodesUpdated bigquery.NullString
+	BackendName        string
+	JobRunStartDay     time.Time
+	DisruptionSeconds  int
+	JobRuns            int
+}
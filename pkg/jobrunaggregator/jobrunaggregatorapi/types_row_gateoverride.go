@@ -0,0 +1,26 @@
+package jobrunaggregatorapi
+
+import (
+	"time"
+)
+
+const (
+	GateOverridesTableName = "GateOverrides"
+)
+
+// GateOverrideRow records a single test case that was force-accepted by a release architect,
+// along with the verdict it would have had without the override.
+type GateOverrideRow struct {
+	OverrideTime time.Time
+	// JobName is the aggregated job for analyze-job-runs, it is empty for analyze-test-case which spans many jobs
+	JobName         string
+	PayloadTag      string
+	TestSuiteName   string
+	TestName        string
+	OriginalVerdict string
+	OriginalMessage string
+	IssuedBy        string
+	Reason          string
+	// TestGroup is the test group for analyze-test-case, it is empty for analyze-job-runs
+	TestGroup string
+}
@@ -0,0 +1,35 @@
+package jobrunaggregatorapi
+
+import (
+	"time"
+)
+
+const (
+	GateResultsTableName = "GateResults"
+
+	GateVerdictPassed  = "Passed"
+	GateVerdictFailed  = "Failed"
+	GateVerdictSkipped = "Skipped"
+)
+
+// GateResultRow records the verdict of a single test case of the synthetic suites an analyzer produced for a payload,
+// so that gate outcomes can be compared across payloads without parsing junit.
+type GateResultRow struct {
+	ResultTime time.Time
+	// Analyzer is the command which produced the suite, like analyze-job-runs or analyze-test-case
+	Analyzer string
+	// JobName is the aggregated job for analyze-job-runs, the test group for analyze-test-case
+	JobName string
+	// PayloadTag is the payload tag, or the aggregation or payload invocation id for PR payloads
+	PayloadTag    string
+	TestSuiteName string
+	// TestName is the name of the test case, which names the checker that produced it
+	TestName string
+	Verdict  string
+	Message  string
+	// EvidenceBundle is the location of the evidence bundle of a failed test case, when one was uploaded
+	EvidenceBundle string
+	// ReportOnlyVerdict is the verdict the checker reached in report-only mode, Verdict is then always Passed.  It is
+	// empty for checkers that gate.
+	ReportOnlyVerdict string
+}
@@ -0,0 +1,33 @@
+package jobrunaggregatorapi
+
+import (
+	"time"
+)
+
+const (
+	HistoricalDataSnapshotsTableName = "HistoricalDataSnapshots"
+)
+
+// HistoricalDataSnapshotRow is one entry of the historical data published by a single run of the
+// historical data analyzer.  Keeping every snapshot allows trend queries on the published percentiles themselves.
+type HistoricalDataSnapshotRow struct {
+	SnapshotTime time.Time
+	// DataType is either alerts or disruptions
+	DataType string
+	// Source describes where the new data came from, like the BigQuery dataset or the local file that was read.
+	Source        string
+	TargetRelease string
+	Key           string
+	Name          string
+	Release       string
+	FromRelease   string
+	Platform      string
+	Architecture  string
+	Network       string
+	Topology      string
+	JobRuns       int
+	P50           float64
+	P75           float64
+	P95           float64
+	P99           float64
+}
@@ -27,4 +27,7 @@ type JobRowWithVariants struct {
 	Topology                    string
 	Release                     string
 	FromRelease                 bigquery.NullString
+	// Optional jobs are reported on, but their failures don't reject payloads.  It is false when the view
+	// doesn't provide the column.
+	Optional bool
 }
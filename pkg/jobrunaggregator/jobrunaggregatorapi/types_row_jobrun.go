@@ -6,6 +6,10 @@ import (
 	"cloud.google.com/go/bigquery"
 )
 
+const (
+	JobRunsTableName = "JobRuns"
+)
+
 type JobRunRow struct {
 	Name               string
 	JobName            string
@@ -17,6 +21,11 @@ type JobRunRow struct {
 	MasterNodesUpdated bigquery.NullString
 }
 
+// Save identifies the row by job run, so that the row of a retried insert is not duplicated
+func (r JobRunRow) Save() (map[string]bigquery.Value, string, error) {
+	return saveWithInsertID(r, JobRunsTableName, r.Name)
+}
+
 // TestPlatformProwJobRow is a transient struct for processing results from the bigquery jobs table populated
 // by testplatform. ProwJob kube resources are stored here after we upload job artifacts to GCS.
 type TestPlatformProwJobRow struct {
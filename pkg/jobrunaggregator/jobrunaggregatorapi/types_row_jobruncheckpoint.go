@@ -0,0 +1,21 @@
+package jobrunaggregatorapi
+
+import (
+	"time"
+)
+
+const (
+	JobRunCheckpointsTableName = "JobRunCheckpoints"
+)
+
+// JobRunCheckpointRow is committed by a loader once every job run of the job up to JobRunName that completed before
+// CompletedBefore was loaded, so that its next run skips them.  Job runs of the job that completed later are loaded
+// whatever their ID, because job runs don't complete in the order they started.  Rows are only ever appended, the
+// latest one of a loader and a job wins.
+type JobRunCheckpointRow struct {
+	Loader          string
+	JobName         string
+	JobRunName      string
+	CompletedBefore time.Time
+	CommittedTime   time.Time
+}
@@ -0,0 +1,27 @@
+package jobrunaggregatorapi
+
+import (
+	"time"
+)
+
+const (
+	JobRunLoadExceptionsTableName = "JobRunLoadExceptions"
+
+	// JobRunLoadExceptionSkip keeps the loaders from ever loading the job run, usually because its artifacts are corrupt.
+	JobRunLoadExceptionSkip = "skip"
+	// JobRunLoadExceptionReprocess makes the loaders load the job run again, even though it was already loaded.
+	JobRunLoadExceptionReprocess = "reprocess"
+	// JobRunLoadExceptionReprocessed is recorded by the loaders once a reprocess request has been carried out.
+	JobRunLoadExceptionReprocessed = "reprocessed"
+)
+
+// JobRunLoadExceptionRow is an operator managed exception to how the loaders pick the job runs they load.  Rows are
+// only ever appended: a reprocess request is pending until a reprocessed row for the same job run is added after it.
+type JobRunLoadExceptionRow struct {
+	JobName    string
+	JobRunName string
+	// Action is one of skip, reprocess or reprocessed
+	Action      string
+	Reason      string
+	CreatedTime time.Time
+}
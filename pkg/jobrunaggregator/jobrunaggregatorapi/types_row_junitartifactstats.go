@@ -0,0 +1,29 @@
+package jobrunaggregatorapi
+
+import (
+	"time"
+)
+
+const (
+	JunitArtifactStatsTableName = "JunitArtifactStats"
+)
+
+// JunitArtifactStatsRow describes the junit artifacts of a single job run, so that the jobs producing pathological
+// artifacts can be found.
+type JunitArtifactStatsRow struct {
+	JobName         string
+	JobRunName      string
+	JobRunStartTime time.Time
+	JobRunEndTime   time.Time
+	Release         string
+
+	JunitFileCount        int
+	JunitTotalBytes       int64
+	LargestJunitFile      string
+	LargestJunitFileBytes int64
+	TestCaseCount         int
+	// ParseDurationSeconds is how long it took to parse every junit file of the job run, once downloaded.
+	ParseDurationSeconds float64
+	// TruncatedJunitFiles counts the junit files that end before their XML is complete.  They are skipped by the analyzers.
+	TruncatedJunitFiles int
+}
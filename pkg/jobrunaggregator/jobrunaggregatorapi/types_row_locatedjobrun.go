@@ -0,0 +1,19 @@
+package jobrunaggregatorapi
+
+import (
+	"time"
+)
+
+const (
+	LocatedJobRunsTableName = "LocatedJobRuns"
+)
+
+// LocatedJobRunRow records a job run the locator found for a payload tag or a payload invocation id, so that the
+// analyzers running later for the same payload don't have to search GCS again.
+type LocatedJobRunRow struct {
+	JobName string
+	// MatchID is the payload tag, or the aggregation or payload invocation id for PR payloads
+	MatchID     string
+	JobRunID    string
+	LocatedTime time.Time
+}
@@ -0,0 +1,17 @@
+package jobrunaggregatorapi
+
+import (
+	"time"
+)
+
+const (
+	SchemaMigrationsTableName = "SchemaMigrations"
+)
+
+// SchemaMigrationRow is recorded every time columns are added to an existing table to match its declared schema
+type SchemaMigrationRow struct {
+	TableName string
+	// AddedColumns are the paths of the added columns, like parent.child for the columns of records
+	AddedColumns []string
+	AppliedTime  time.Time
+}
@@ -0,0 +1,31 @@
+package jobrunaggregatorapi
+
+import (
+	"time"
+)
+
+const (
+	TestCaseAnalysisTableName = "TestCaseAnalysis"
+)
+
+// TestCaseAnalysisRow is the outcome of a test case produced by a checker of analyze-test-case, along with the job
+// run counts it was decided on, so gating outcomes can be trended over time.
+type TestCaseAnalysisRow struct {
+	AnalysisTime time.Time
+	// PayloadTag is the payload tag, or the payload invocation id for PR payloads
+	PayloadTag string
+	TestGroup  string
+	// Checker is the suite of the checker, like minimum-required-passes-checker
+	Checker       string
+	TestSuiteName string
+	TestName      string
+	Verdict       string
+	// ReportOnlyVerdict is the verdict the checker reached in report-only mode, Verdict is then always Passed.  It is
+	// empty for checkers that gate.
+	ReportOnlyVerdict string
+	Passes            int
+	Failures          int
+	Skips             int
+	// DurationSeconds is how long the checker took to decide the test case
+	DurationSeconds float64
+}
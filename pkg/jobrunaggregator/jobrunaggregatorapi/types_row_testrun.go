@@ -0,0 +1,25 @@
+package jobrunaggregatorapi
+
+import (
+	"time"
+
+	"cloud.google.com/go/bigquery"
+)
+
+const TestRunsTableName = "TestRuns"
+
+// TestRunRow is the result of a single test case of a job run.  It carries the start of the job run, which the
+// table is partitioned by, so that queries for a window of job runs don't have to join JobRuns to prune it.
+type TestRunRow struct {
+	TestName        string
+	TestSuite       string
+	Status          string
+	JobName         string
+	JobRunName      string
+	JobRunStartTime time.Time
+}
+
+// Save identifies the row by job run and test, so that the rows of a retried insert are not duplicated
+func (r TestRunRow) Save() (map[string]bigquery.Value, string, error) {
+	return saveWithInsertID(r, TestRunsTableName, r.JobRunName, r.TestSuite, r.TestName)
+}
@@ -0,0 +1,61 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// adaptiveWaitLookback is how far back job run durations are considered.
+	adaptiveWaitLookback = 14 * 24 * time.Hour
+	// adaptiveWaitGracePeriod covers the delay between a job run finishing and its results being available.
+	adaptiveWaitGracePeriod = 15 * time.Minute
+)
+
+// AdaptiveWait derives when to stop waiting for the runs of each job from how long that job historically takes,
+// so that short jobs are not waited on for hours and long jobs are not cut off by a one-size-fits-all timeout.
+type AdaptiveWait struct {
+	CIDataClient        CIDataClient
+	JobRunStartEstimate time.Time
+	// Latest bounds every stop-waiting time, usually because the command itself times out shortly after.
+	Latest time.Time
+}
+
+// StopWaitingTimes returns the time to stop waiting for each of the given jobs.  Jobs without history are missing
+// from the result, callers should fall back to their fixed stop-waiting time for those.
+func (a *AdaptiveWait) StopWaitingTimes(ctx context.Context, jobNames []string) (map[string]time.Time, error) {
+	rows, err := a.CIDataClient.ListJobRunDurationStatistics(ctx, jobNames, a.JobRunStartEstimate.Add(-adaptiveWaitLookback))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job run durations: %w", err)
+	}
+	ret := map[string]time.Time{}
+	for _, row := range rows {
+		stopWaiting := adaptiveStopWaitingTime(float64(row.P95DurationSeconds), float64(row.MaxDurationSeconds), a.JobRunStartEstimate, a.Latest)
+		logrus.WithFields(logrus.Fields{
+			"job":         row.JobName,
+			"p95":         time.Duration(row.P95DurationSeconds) * time.Second,
+			"max":         time.Duration(row.MaxDurationSeconds) * time.Second,
+			"stopWaiting": stopWaiting.UTC().Format(time.RFC3339),
+		}).Info("computed adaptive stop-waiting time")
+		ret[row.JobName] = stopWaiting
+	}
+	return ret, nil
+}
+
+// adaptiveStopWaitingTime allows a quarter more than the 95th percentile duration, which tolerates slow runs without
+// letting a handful of runs that hung until the prow timeout dominate.  No run ever took longer than the maximum, so
+// there is no point waiting beyond it.
+func adaptiveStopWaitingTime(p95DurationSeconds, maxDurationSeconds float64, jobRunStartEstimate, latest time.Time) time.Time {
+	expectedSeconds := p95DurationSeconds * 1.25
+	if maxDurationSeconds < expectedSeconds {
+		expectedSeconds = maxDurationSeconds
+	}
+	stopWaiting := jobRunStartEstimate.Add(time.Duration(expectedSeconds)*time.Second + adaptiveWaitGracePeriod)
+	if !latest.IsZero() && stopWaiting.After(latest) {
+		return latest
+	}
+	return stopWaiting
+}
@@ -0,0 +1,41 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func TestAdaptiveWaitStopWaitingTimes(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	start := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	latest := start.Add(5 * time.Hour)
+	jobNames := []string{"install-only", "long-upgrade", "hung-once", "new-job"}
+
+	mockCIDataClient := NewMockCIDataClient(mockCtrl)
+	mockCIDataClient.EXPECT().ListJobRunDurationStatistics(ctx, jobNames, start.Add(-adaptiveWaitLookback)).Return([]jobrunaggregatorapi.JobRunDurationStatisticsRow{
+		// 95th percentile plus a quarter, plus the grace period
+		{JobName: "install-only", P95DurationSeconds: 3600, MaxDurationSeconds: 7200},
+		// capped by the command timeout
+		{JobName: "long-upgrade", P95DurationSeconds: 5 * 3600, MaxDurationSeconds: 6 * 3600},
+		// no run ever took longer than the maximum
+		{JobName: "hung-once", P95DurationSeconds: 3600, MaxDurationSeconds: 4000},
+	}, nil)
+
+	adaptiveWait := &AdaptiveWait{CIDataClient: mockCIDataClient, JobRunStartEstimate: start, Latest: latest}
+	actual, err := adaptiveWait.StopWaitingTimes(ctx, jobNames)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{
+		"install-only": start.Add(75*time.Minute + adaptiveWaitGracePeriod),
+		"long-upgrade": latest,
+		"hung-once":    start.Add(4000*time.Second + adaptiveWaitGracePeriod),
+	}, actual)
+}
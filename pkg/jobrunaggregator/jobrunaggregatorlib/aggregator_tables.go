@@ -0,0 +1,258 @@
+package jobrunaggregatorlib
+
+import (
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// AggregatorTableSpecs are the jobs the aggregator knows of, and the tables the loaders write job runs to.  Those grow
+// with every job run, so they are partitioned by the start of the job runs, which the queries always filter on, and
+// clustered by job, and by test for the test runs which are looked up by test.
+var AggregatorTableSpecs = []TableSpec{
+	{
+		Name:        jobrunaggregatorapi.JobsTableName,
+		Description: "Jobs whose runs are loaded and aggregated, maintained by prime-job-table",
+		Row:         jobrunaggregatorapi.JobRow{},
+		ColumnDescriptions: map[string]string{
+			"JobName":                     "Name of the job",
+			"GCSBucketName":               "Bucket the artifacts of the job runs are uploaded to",
+			"GCSJobHistoryLocationPrefix": "Prefix of the job runs of the job in the bucket, e.g. logs/<job name>",
+			"CollectDisruption":           "Whether the disruption of the job runs is loaded",
+			"CollectTestRuns":             "Whether the test results of the job runs are loaded",
+		},
+	},
+	{
+		Name:        jobrunaggregatorapi.JobRunsTableName,
+		Description: "Job runs of the jobs of the Jobs table",
+		Row:         jobrunaggregatorapi.JobRunRow{},
+		ColumnDescriptions: map[string]string{
+			"Name":               "Prow build ID of the job run",
+			"JobName":            "Name of the job",
+			"Status":             "Overall status of the job run, e.g. success or failure",
+			"StartTime":          "Time the job run started",
+			"EndTime":            "Time the job run completed",
+			"ReleaseTag":         "Payload the job run tested",
+			"Cluster":            "Build cluster the job run ran on",
+			"MasterNodesUpdated": "Whether the control plane nodes were updated during an upgrade",
+		},
+		PartitionColumn: "StartTime",
+		ClusterColumns:  []string{"JobName"},
+	},
+	{
+		Name:        jobrunaggregatorapi.BackendDisruptionTableName,
+		Description: "Seconds of disruption of every backend polled by job runs",
+		Row:         jobrunaggregatorapi.BackendDisruptionRow{},
+		ColumnDescriptions: map[string]string{
+			"BackendName":        "Name of the polled backend, e.g. kube-api-new-connections",
+			"DisruptionSeconds":  "Seconds the backend was unavailable during the job run",
+			"JobName":            "Name of the job",
+			"JobRunName":         "Prow build ID of the job run",
+			"JobRunStartTime":    "Time the job run started",
+			"JobRunEndTime":      "Time the job run completed",
+			"Cluster":            "Build cluster the job run ran on",
+			"ReleaseTag":         "Payload the job run tested",
+			"MasterNodesUpdated": "Whether the control plane nodes were updated during an upgrade",
+			"JobRunStatus":       "Overall status of the job run, e.g. success or failure",
+		},
+		PartitionColumn: "JobRunStartTime",
+		ClusterColumns:  []string{"JobName", "BackendName"},
+	},
+	{
+		Name:        jobrunaggregatorapi.TestRunsTableName,
+		Description: "Results of the test cases of job runs",
+		Row:         jobrunaggregatorapi.TestRunRow{},
+		ColumnDescriptions: map[string]string{
+			"TestName":        "Name of the test case",
+			"TestSuite":       "Name of the suite of the test case",
+			"Status":          "Result of the test case, e.g. Passed, Failed or Skipped",
+			"JobName":         "Name of the job",
+			"JobRunName":      "Prow build ID of the job run",
+			"JobRunStartTime": "Time the job run started",
+		},
+		PartitionColumn: "JobRunStartTime",
+		ClusterColumns:  []string{"JobName", "TestName"},
+	},
+	{
+		Name:        jobrunaggregatorapi.AlertsTableName,
+		Description: "Seconds every alert fired during job runs, with zeros for the known alerts that didn't fire",
+		Row:         jobrunaggregatorapi.AlertRow{},
+		ColumnDescriptions: map[string]string{
+			"Name":               "Name of the alert, e.g. KubePodNotReady",
+			"Namespace":          "Namespace the alert fired in",
+			"Level":              "Severity of the alert, e.g. Warning or Critical",
+			"AlertSeconds":       "Seconds the alert fired during the job run",
+			"JobName":            "Name of the job",
+			"JobRunName":         "Prow build ID of the job run",
+			"JobRunStartTime":    "Time the job run started",
+			"JobRunEndTime":      "Time the job run completed",
+			"Cluster":            "Build cluster the job run ran on",
+			"ReleaseTag":         "Payload the job run tested",
+			"MasterNodesUpdated": "Whether the control plane nodes were updated during an upgrade",
+			"JobRunStatus":       "Overall status of the job run, e.g. success or failure",
+		},
+		PartitionColumn: "JobRunStartTime",
+		ClusterColumns:  []string{"JobName", "Name"},
+	},
+}
+
+// LoaderTableSpecs are the tables the loaders keep track of the job runs they load with.  The checkpoints and the
+// exceptions are small, so they are only clustered by job.
+var LoaderTableSpecs = []TableSpec{
+	{
+		Name:        jobrunaggregatorapi.JobRunCheckpointsTableName,
+		Description: "Job runs up to which every job run of a job was loaded by a loader, the latest row wins",
+		Row:         jobrunaggregatorapi.JobRunCheckpointRow{},
+		ColumnDescriptions: map[string]string{
+			"Loader":          "Name of the loader, e.g. alerts or disruptions",
+			"JobName":         "Name of the job",
+			"JobRunName":      "Prow build ID of the last loaded job run",
+			"CompletedBefore": "Job runs completed before this time were loaded",
+			"CommittedTime":   "Time the checkpoint was committed",
+		},
+		ClusterColumns: []string{"Loader", "JobName"},
+	},
+	{
+		Name:        jobrunaggregatorapi.JobRunLoadExceptionsTableName,
+		Description: "Job runs the loaders must skip or load again, managed by operators",
+		Row:         jobrunaggregatorapi.JobRunLoadExceptionRow{},
+		ColumnDescriptions: map[string]string{
+			"JobName":     "Name of the job",
+			"JobRunName":  "Prow build ID of the job run",
+			"Action":      "One of skip, reprocess or reprocessed",
+			"Reason":      "Why the exception was added",
+			"CreatedTime": "Time the exception was added",
+		},
+		ClusterColumns: []string{"JobName"},
+	},
+	{
+		Name:        jobrunaggregatorapi.JunitArtifactStatsTableName,
+		Description: "Size and parse time of the junit artifacts of job runs, to find the jobs producing pathological ones",
+		Row:         jobrunaggregatorapi.JunitArtifactStatsRow{},
+		ColumnDescriptions: map[string]string{
+			"JobName":               "Name of the job",
+			"JobRunName":            "Prow build ID of the job run",
+			"JobRunStartTime":       "Time the job run started",
+			"JobRunEndTime":         "Time the job run completed",
+			"Release":               "Release of the job run",
+			"JunitFileCount":        "Number of junit files of the job run",
+			"JunitTotalBytes":       "Total size of the junit files",
+			"LargestJunitFile":      "Path of the largest junit file",
+			"LargestJunitFileBytes": "Size of the largest junit file",
+			"TestCaseCount":         "Number of test cases in the junit files",
+			"ParseDurationSeconds":  "Seconds it took to parse the junit files once downloaded",
+			"TruncatedJunitFiles":   "Number of junit files ending before their XML is complete",
+		},
+		PartitionColumn: "JobRunStartTime",
+		ClusterColumns:  []string{"JobName"},
+	},
+}
+
+// AnalyzerTableSpecs are the tables the analyzers record their outcomes to, for auditing and for trends across
+// payloads and runs.
+var AnalyzerTableSpecs = []TableSpec{
+	{
+		Name:        jobrunaggregatorapi.GateOverridesTableName,
+		Description: "Failed tests release architects force-accepted with a gate override",
+		Row:         jobrunaggregatorapi.GateOverrideRow{},
+		ColumnDescriptions: map[string]string{
+			"OverrideTime":    "Time the override was applied",
+			"JobName":         "Aggregated job for analyze-job-runs, empty for analyze-test-case",
+			"PayloadTag":      "Payload tag, or the aggregation or payload invocation ID for PR payloads",
+			"TestSuiteName":   "Name of the suite of the overridden test",
+			"TestName":        "Name of the overridden test",
+			"OriginalVerdict": "Verdict of the test without the override",
+			"OriginalMessage": "Failure message of the test without the override",
+			"IssuedBy":        "Who issued the override",
+			"Reason":          "Why the failure was accepted",
+			"TestGroup":       "Test group for analyze-test-case, empty for analyze-job-runs",
+		},
+	},
+	{
+		Name:        jobrunaggregatorapi.HistoricalDataSnapshotsTableName,
+		Description: "Historical data published by every run of the historical data analyzer",
+		Row:         jobrunaggregatorapi.HistoricalDataSnapshotRow{},
+		ColumnDescriptions: map[string]string{
+			"SnapshotTime":  "Time the historical data was published",
+			"DataType":      "Either alerts or disruptions",
+			"Source":        "Where the new data came from, the BigQuery dataset or the file that was read",
+			"TargetRelease": "Release the historical data was compared for",
+			"Key":           "Key of the entry in the published data",
+			"Name":          "Alert or backend name of the entry",
+			"Release":       "Release of the job runs of the entry",
+			"FromRelease":   "Release the job runs upgraded from",
+			"Platform":      "Platform of the job runs, e.g. aws",
+			"Architecture":  "Architecture of the job runs, e.g. amd64",
+			"Network":       "Network of the job runs, e.g. ovn",
+			"Topology":      "Topology of the job runs, e.g. ha",
+			"JobRuns":       "Number of job runs the percentiles were computed from",
+			"P50":           "Published 50th percentile, in seconds",
+			"P75":           "Published 75th percentile, in seconds",
+			"P95":           "Published 95th percentile, in seconds",
+			"P99":           "Published 99th percentile, in seconds",
+		},
+		PartitionColumn: "SnapshotTime",
+		ClusterColumns:  []string{"DataType", "TargetRelease"},
+	},
+	{
+		Name:        jobrunaggregatorapi.GateResultsTableName,
+		Description: "Verdict of every test of the suites the analyzers produced for payloads",
+		Row:         jobrunaggregatorapi.GateResultRow{},
+		ColumnDescriptions: map[string]string{
+			"ResultTime":        "Time the verdict was reached",
+			"Analyzer":          "Command which produced the suite, e.g. analyze-job-runs or analyze-test-case",
+			"JobName":           "Aggregated job for analyze-job-runs, test group for analyze-test-case",
+			"PayloadTag":        "Payload tag, or the aggregation or payload invocation ID for PR payloads",
+			"TestSuiteName":     "Names of the nested suites of the test",
+			"TestName":          "Name of the test, which names the checker that produced it",
+			"Verdict":           "Passed, Failed or Skipped",
+			"Message":           "Failure or skip message of the test",
+			"EvidenceBundle":    "Location of the evidence bundle of a failed test, when one was uploaded",
+			"ReportOnlyVerdict": "Verdict the checker reached in report-only mode, empty for checkers that gate",
+		},
+		PartitionColumn: "ResultTime",
+		ClusterColumns:  []string{"Analyzer", "JobName", "PayloadTag"},
+	},
+	{
+		Name:        jobrunaggregatorapi.TestCaseAnalysisTableName,
+		Description: "Outcome of every checker of analyze-test-case, with the job run counts it was decided on",
+		Row:         jobrunaggregatorapi.TestCaseAnalysisRow{},
+		ColumnDescriptions: map[string]string{
+			"AnalysisTime":      "Time the analysis completed",
+			"PayloadTag":        "Payload tag, or the payload invocation ID for PR payloads",
+			"TestGroup":         "Test group that was analyzed",
+			"Checker":           "Suite of the checker, e.g. minimum-required-passes-checker",
+			"TestSuiteName":     "Name of the suite of the test case",
+			"TestName":          "Name of the test case",
+			"Verdict":           "Passed, Failed or Skipped",
+			"ReportOnlyVerdict": "Verdict the checker reached in report-only mode, empty for checkers that gate",
+			"Passes":            "Number of job runs the test case passed in",
+			"Failures":          "Number of job runs the test case failed in",
+			"Skips":             "Number of job runs the test case was skipped in",
+			"DurationSeconds":   "Seconds the checker took to decide the test case",
+		},
+		PartitionColumn: "AnalysisTime",
+		ClusterColumns:  []string{"TestGroup", "TestName"},
+	},
+	{
+		Name:        jobrunaggregatorapi.LocatedJobRunsTableName,
+		Description: "Job runs the locator found for payloads, so that later analyzers don't search GCS again",
+		Row:         jobrunaggregatorapi.LocatedJobRunRow{},
+		ColumnDescriptions: map[string]string{
+			"JobName":     "Name of the job",
+			"MatchID":     "Payload tag, or the aggregation or payload invocation ID for PR payloads",
+			"JobRunID":    "Prow build ID of the job run",
+			"LocatedTime": "Time the job run was found",
+		},
+		PartitionColumn: "LocatedTime",
+		ClusterColumns:  []string{"MatchID", "JobName"},
+	},
+}
+
+// DeclaredTableSpecs are all the tables created by create-tables, the table of the schema migrations first so that it
+// can record the migrations of the others.
+func DeclaredTableSpecs() []TableSpec {
+	specs := []TableSpec{SchemaMigrationsTableSpec}
+	specs = append(specs, AggregatorTableSpecs...)
+	specs = append(specs, LoaderTableSpecs...)
+	specs = append(specs, AnalyzerTableSpecs...)
+	return append(specs, ReleaseTableSpecs...)
+}
@@ -0,0 +1,50 @@
+package jobrunaggregatorlib
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// artifactCacheDirName is the directory of the artifact cache in the working dir
+const artifactCacheDirName = "artifact-cache"
+
+type ArtifactCacheFlags struct {
+	// Dir is shared by the commands run on the same host, like the aggregator, the analyzers and the loaders
+	Dir      string
+	MaxBytes int64
+}
+
+func NewArtifactCacheFlags() *ArtifactCacheFlags {
+	return &ArtifactCacheFlags{
+		MaxBytes: 10 * 1024 * 1024 * 1024,
+	}
+}
+
+func (f *ArtifactCacheFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.Dir, "artifact-cache-dir", f.Dir, fmt.Sprintf("The directory of the cache of downloaded junit and prowjob files, like one shared by all the commands run on the host. Defaults to the %s directory of the working dir of the command, if it has one.", artifactCacheDirName))
+	fs.Int64Var(&f.MaxBytes, "artifact-cache-max-bytes", f.MaxBytes, "The size the cache of downloaded junit and prowjob files is kept under, by removing the least recently used files. 0 disables the cache.")
+}
+
+func (f *ArtifactCacheFlags) Validate() error {
+	if f.MaxBytes < 0 {
+		return fmt.Errorf("--artifact-cache-max-bytes must not be negative")
+	}
+	return nil
+}
+
+// NewArtifactCache returns the artifact cache for the job runs read by the clients built from these flags, nil when
+// caching is disabled.  Commands without a working dir pass an empty one, they only cache with --artifact-cache-dir.
+func (f *ArtifactCacheFlags) NewArtifactCache(workingDir string) *jobrunaggregatorapi.ArtifactCache {
+	dir := f.Dir
+	if len(dir) == 0 && len(workingDir) > 0 {
+		dir = filepath.Join(workingDir, artifactCacheDirName)
+	}
+	if f.MaxBytes == 0 || len(dir) == 0 {
+		return nil
+	}
+	return jobrunaggregatorapi.NewArtifactCache(dir, f.MaxBytes)
+}
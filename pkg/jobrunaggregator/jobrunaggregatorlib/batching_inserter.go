@@ -0,0 +1,189 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"google.golang.org/api/googleapi"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// DefaultInsertBatchSize is the number of rows per streaming insert recommended by BigQuery
+const DefaultInsertBatchSize = 500
+
+type InsertBatchFlags struct {
+	BatchSize int
+}
+
+func NewInsertBatchFlags() *InsertBatchFlags {
+	return &InsertBatchFlags{
+		BatchSize: DefaultInsertBatchSize,
+	}
+}
+
+func (f *InsertBatchFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&f.BatchSize, "insert-batch-size", f.BatchSize, "The number of rows accumulated before they are inserted into BigQuery at once. 1 inserts the rows of every job run on their own.")
+}
+
+func (f *InsertBatchFlags) Validate() error {
+	if f.BatchSize < 1 {
+		return fmt.Errorf("--insert-batch-size must be at least 1")
+	}
+	return nil
+}
+
+// insertBackoff retries the rows of a batch that BigQuery did not insert
+var insertBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: 2 * time.Second,
+	Factor:   2.0,
+	Jitter:   0.1,
+	Cap:      60 * time.Second,
+}
+
+// errRowsNotInserted is returned when only some rows of a batch were inserted, the others are retried
+var errRowsNotInserted = errors.New("rows were not inserted")
+
+// BatchingInserter inserts the rows put into it in batches instead of one streaming insert per Put, which is easier
+// on the streaming insert quota.  Rows are only inserted once a batch is full, so Flush must be called once all rows
+// are put.
+type BatchingInserter interface {
+	BigQueryInserter
+	// Flush inserts the rows accumulated so far, however few.  It also returns the errors of the batches inserted by
+	// Put since the previous Flush, since those may hold the rows of other callers.
+	Flush(ctx context.Context) error
+}
+
+type batchingInserter struct {
+	delegate  BigQueryInserter
+	batchSize int
+
+	// lock also serializes the inserts, which holds off the callers of Put while a batch is retried
+	lock sync.Mutex
+	rows []interface{}
+	errs []error
+}
+
+func NewBatchingInserter(delegate BigQueryInserter, batchSize int) BatchingInserter {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	return &batchingInserter{
+		delegate:  delegate,
+		batchSize: batchSize,
+	}
+}
+
+// Put accepts a row or a slice of rows like the BigQuery inserter does
+func (b *batchingInserter) Put(ctx context.Context, src interface{}) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	srcVal := reflect.ValueOf(src)
+	if srcVal.Kind() != reflect.Slice {
+		b.rows = append(b.rows, src)
+	} else {
+		for i := 0; i < srcVal.Len(); i++ {
+			b.rows = append(b.rows, srcVal.Index(i).Interface())
+		}
+	}
+
+	for len(b.rows) >= b.batchSize {
+		batch := b.rows[:b.batchSize]
+		b.rows = b.rows[b.batchSize:]
+		if err := b.insert(ctx, batch); err != nil {
+			b.errs = append(b.errs, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *batchingInserter) Flush(ctx context.Context) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	errs := b.errs
+	b.errs = nil
+	if len(b.rows) > 0 {
+		batch := b.rows
+		b.rows = nil
+		errs = append(errs, b.insert(ctx, batch))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// insert retries the rows of the batch that failed to insert, but not the invalid ones which would keep failing
+func (b *batchingInserter) insert(ctx context.Context, rows []interface{}) error {
+	invalidRowErrs := []error{}
+	err := retry.OnError(insertBackoff, isRetriableInsertError, func() error {
+		err := b.delegate.Put(ctx, rows)
+		var rowErrs bigquery.PutMultiError
+		if !errors.As(err, &rowErrs) {
+			return err
+		}
+
+		failedRows := []interface{}{}
+		for i := range rowErrs {
+			rowErr := rowErrs[i]
+			if rowErr.RowIndex < 0 || rowErr.RowIndex >= len(rows) {
+				return err
+			}
+			if isInvalidRow(rowErr) {
+				invalidRowErrs = append(invalidRowErrs, &rowErr)
+				continue
+			}
+			failedRows = append(failedRows, rows[rowErr.RowIndex])
+		}
+		if len(failedRows) == 0 {
+			return nil
+		}
+		logrus.WithField("rows", len(rows)).Warnf("retrying %d rows that were not inserted", len(failedRows))
+		rows = failedRows
+		return fmt.Errorf("%w: %d rows, first error: %v", errRowsNotInserted, len(failedRows), err)
+	})
+	return utilerrors.NewAggregate(append(invalidRowErrs, err))
+}
+
+// isInvalidRow tells the rows BigQuery refused, rather than the ones it stopped inserting because of them
+func isInvalidRow(rowErr bigquery.RowInsertionError) bool {
+	for _, err := range rowErr.Errors {
+		var bigQueryErr *bigquery.Error
+		if errors.As(err, &bigQueryErr) && bigQueryErr.Reason == "invalid" {
+			return true
+		}
+	}
+	return false
+}
+
+func isRetriableInsertError(err error) bool {
+	if errors.Is(err, errRowsNotInserted) {
+		return true
+	}
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError {
+		logrus.WithError(err).Warn("hit a retriable insert error")
+		return true
+	}
+	for _, item := range apiErr.Errors {
+		if item.Reason == "quotaExceeded" || item.Reason == "rateLimitExceeded" {
+			logrus.WithError(err).Warn("hit an insert quota error")
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type batchedRow struct {
+	Name string
+}
+
+// failingInserter records the batches it is given, and fails the rows of failures in the order of the calls
+type failingInserter struct {
+	batches  [][]string
+	failures []map[string]string
+}
+
+func (f *failingInserter) Put(ctx context.Context, src interface{}) error {
+	rows := src.([]interface{})
+	names := []string{}
+	for _, r := range rows {
+		names = append(names, r.(batchedRow).Name)
+	}
+	f.batches = append(f.batches, names)
+
+	if len(f.failures) == 0 {
+		return nil
+	}
+	failures := f.failures[0]
+	f.failures = f.failures[1:]
+	rowErrs := bigquery.PutMultiError{}
+	for i, name := range names {
+		if reason, ok := failures[name]; ok {
+			rowErrs = append(rowErrs, bigquery.RowInsertionError{RowIndex: i, Errors: bigquery.MultiError{&bigquery.Error{Reason: reason}}})
+		}
+	}
+	if len(rowErrs) == 0 {
+		return nil
+	}
+	return rowErrs
+}
+
+func TestBatchingInserter(t *testing.T) {
+	previousInsertBackoff := insertBackoff
+	insertBackoff = wait.Backoff{Steps: 3, Duration: time.Millisecond}
+	t.Cleanup(func() { insertBackoff = previousInsertBackoff })
+
+	tests := []struct {
+		name            string
+		batchSize       int
+		puts            []interface{}
+		failures        []map[string]string
+		expectedBatches [][]string
+		expectErr       bool
+	}{
+		{
+			name:            "rows are inserted by batch and flushed",
+			batchSize:       2,
+			puts:            []interface{}{[]batchedRow{{"a"}, {"b"}, {"c"}}, batchedRow{"d"}, []batchedRow{{"e"}}},
+			expectedBatches: [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+		},
+		{
+			name:            "stopped rows are retried",
+			batchSize:       3,
+			puts:            []interface{}{[]batchedRow{{"a"}, {"b"}, {"c"}}},
+			failures:        []map[string]string{{"b": "stopped", "c": "stopped"}, {"c": "backendError"}},
+			expectedBatches: [][]string{{"a", "b", "c"}, {"b", "c"}, {"c"}},
+		},
+		{
+			name:            "invalid rows are not retried",
+			batchSize:       3,
+			puts:            []interface{}{[]batchedRow{{"a"}, {"b"}, {"c"}}},
+			failures:        []map[string]string{{"a": "stopped", "b": "invalid"}},
+			expectedBatches: [][]string{{"a", "b", "c"}, {"a"}},
+			expectErr:       true,
+		},
+		{
+			name:            "rows failing every attempt",
+			batchSize:       1,
+			puts:            []interface{}{batchedRow{"a"}},
+			failures:        []map[string]string{{"a": "stopped"}, {"a": "stopped"}, {"a": "stopped"}},
+			expectedBatches: [][]string{{"a"}, {"a"}, {"a"}},
+			expectErr:       true,
+		},
+		{
+			name:      "nothing to flush",
+			batchSize: 2,
+			puts:      []interface{}{[]batchedRow{}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			delegate := &failingInserter{failures: tc.failures}
+			inserter := NewBatchingInserter(delegate, tc.batchSize)
+			errs := []error{}
+			for _, put := range tc.puts {
+				errs = append(errs, inserter.Put(context.TODO(), put))
+			}
+			// the errors of the batches inserted by Put are returned again by Flush
+			assert.Equal(t, tc.expectErr, inserter.Flush(context.TODO()) != nil)
+			assert.Equal(t, tc.expectErr, errors.Join(errs...) != nil)
+			assert.Equal(t, tc.expectedBatches, delegate.batches)
+		})
+	}
+}
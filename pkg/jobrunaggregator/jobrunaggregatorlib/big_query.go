@@ -5,12 +5,17 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 
+	"k8s.io/apimachinery/pkg/util/sets"
+
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
 )
 
@@ -64,9 +69,32 @@ type BigQueryInserter interface {
 	Put(ctx context.Context, src interface{}) (err error)
 }
 
+// DryRunOutputFlags keep the rows dry runs would insert, for commands that have a --dry-run
+type DryRunOutputFlags struct {
+	Dir string
+}
+
+func NewDryRunOutputFlags() *DryRunOutputFlags {
+	return &DryRunOutputFlags{}
+}
+
+func (f *DryRunOutputFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.Dir, "dry-run-output-dir", f.Dir, "With --dry-run, write the rows that would be inserted into <table>.json files in this directory, as newline-delimited JSON that `bq load --source_format=NEWLINE_DELIMITED_JSON` accepts. Files are replaced on every run.")
+}
+
+// NewInserter returns the dry run inserter of the table, writing to the output dir when there is one
+func (f *DryRunOutputFlags) NewInserter(out io.Writer, table string) BigQueryInserter {
+	if len(f.Dir) == 0 {
+		return NewDryRunInserter(out, table)
+	}
+	return NewNDJSONDryRunInserter(out, table, f.Dir)
+}
+
 type dryRunInserter struct {
 	table string
 	out   io.Writer
+	// dir receives the rows as newline-delimited JSON when set
+	dir string
 }
 
 func NewDryRunInserter(out io.Writer, table string) BigQueryInserter {
@@ -76,7 +104,57 @@ func NewDryRunInserter(out io.Writer, table string) BigQueryInserter {
 	}
 }
 
+// NewNDJSONDryRunInserter also writes the rows to <dir>/<table>.json, one JSON object per line with the columns of
+// the table, so that dry runs can be diffed or loaded later.  The file is replaced by the first Put of the process.
+func NewNDJSONDryRunInserter(out io.Writer, table, dir string) BigQueryInserter {
+	return dryRunInserter{
+		table: table,
+		out:   out,
+		dir:   dir,
+	}
+}
+
+var (
+	// dryRunFilesLock serializes the writes of the inserters of a table, which are shared by concurrent uploaders
+	dryRunFilesLock    sync.Mutex
+	dryRunFilesWritten = sets.New[string]()
+)
+
+func (d dryRunInserter) writeNDJSON(src interface{}) error {
+	content, _, err := encodeRowsAsNDJSON(src)
+	if err != nil {
+		return fmt.Errorf("failed to encode rows for %s: %w", d.table, err)
+	}
+
+	dryRunFilesLock.Lock()
+	defer dryRunFilesLock.Unlock()
+	path := filepath.Join(d.dir, d.table+".json")
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !dryRunFilesWritten.Has(path) {
+		if err := os.MkdirAll(d.dir, 0755); err != nil {
+			return err
+		}
+		flags |= os.O_TRUNC
+		dryRunFilesWritten.Insert(path)
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write rows for %s: %w", d.table, err)
+	}
+	return file.Close()
+}
+
 func (d dryRunInserter) Put(ctx context.Context, src interface{}) (err error) {
+	if len(d.dir) > 0 {
+		if err := d.writeNDJSON(src); err != nil {
+			return err
+		}
+	}
+
 	srcVal := reflect.ValueOf(src)
 	if srcVal.Kind() != reflect.Slice {
 		logrus.Debugf("INSERT into %s: %v", d.table, src)
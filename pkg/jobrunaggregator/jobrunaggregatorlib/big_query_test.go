@@ -0,0 +1,40 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func TestNDJSONDryRunInserter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dry-run")
+	path := filepath.Join(dir, jobrunaggregatorapi.JobRunLoadExceptionsTableName+".json")
+	// files of previous runs are replaced
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.WriteFile(path, []byte("{}\n"), 0644))
+
+	createdTime := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	inserter := (&DryRunOutputFlags{Dir: dir}).NewInserter(io.Discard, jobrunaggregatorapi.JobRunLoadExceptionsTableName)
+	assert.NoError(t, inserter.Put(context.TODO(), []jobrunaggregatorapi.JobRunLoadExceptionRow{
+		{JobName: "periodic-e2e-aws", JobRunName: "1000", Action: "skip", CreatedTime: createdTime},
+	}))
+	assert.NoError(t, inserter.Put(context.TODO(), jobrunaggregatorapi.JobRunLoadExceptionRow{JobName: "periodic-e2e-aws", JobRunName: "2000", Action: "reprocess", CreatedTime: createdTime}))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Action":"skip","CreatedTime":"2023-10-01T12:00:00Z","JobName":"periodic-e2e-aws","JobRunName":"1000","Reason":""}
+{"Action":"reprocess","CreatedTime":"2023-10-01T12:00:00Z","JobName":"periodic-e2e-aws","JobRunName":"2000","Reason":""}
+`, string(content))
+
+	// without a dir, nothing is written
+	assert.NoError(t, (&DryRunOutputFlags{}).NewInserter(io.Discard, "Other").Put(context.TODO(), jobrunaggregatorapi.JobRunLoadExceptionRow{}))
+	_, err = os.Stat(filepath.Join(dir, "Other.json"))
+	assert.True(t, os.IsNotExist(err))
+}
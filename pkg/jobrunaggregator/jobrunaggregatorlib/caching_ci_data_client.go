@@ -0,0 +1,193 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// queryCacheDirName is the directory of the query cache in the working dir
+const queryCacheDirName = "query-cache"
+
+type QueryCacheFlags struct {
+	TTL time.Duration
+	Dir string
+}
+
+func NewQueryCacheFlags() *QueryCacheFlags {
+	return &QueryCacheFlags{
+		TTL: time.Hour,
+	}
+}
+
+func (f *QueryCacheFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&f.TTL, "query-cache-ttl", f.TTL, "How long the results of queries of tables that rarely change, like the list of jobs, are reused before querying BigQuery again. 0 disables the cache.")
+	fs.StringVar(&f.Dir, "query-cache-dir", f.Dir, fmt.Sprintf("The directory the results of cached queries are kept in, so that later invocations reuse them. Defaults to the %s directory of the working dir of the command, if it has one, otherwise results are only cached in memory.", queryCacheDirName))
+}
+
+func (f *QueryCacheFlags) Validate() error {
+	if f.TTL < 0 {
+		return fmt.Errorf("--query-cache-ttl must not be negative")
+	}
+	return nil
+}
+
+// Wrap caches the queries of delegate, which reads the given data set.  Commands without a working dir pass an empty
+// one, they only cache on disk with --query-cache-dir.
+func (f *QueryCacheFlags) Wrap(delegate CIDataClient, dataCoordinates BigQueryDataCoordinates, workingDir string) CIDataClient {
+	if f.TTL == 0 {
+		return delegate
+	}
+	dir := f.Dir
+	if len(dir) == 0 && len(workingDir) > 0 {
+		dir = filepath.Join(workingDir, queryCacheDirName)
+	}
+	if len(dir) > 0 {
+		// data sets don't share results
+		dir = filepath.Join(dir, dataCoordinates.ProjectID, dataCoordinates.DataSetID)
+	}
+	return NewCachingCIDataClient(delegate, f.TTL, dir)
+}
+
+// cachingCIDataClient caches the queries of tables that rarely change, the other queries go to the delegate.
+type cachingCIDataClient struct {
+	CIDataClient
+
+	ttl time.Duration
+	// dir keeps results for later invocations, they are only kept in memory when it is empty
+	dir string
+	now func() time.Time
+
+	lock sync.Mutex
+	// queries holds the cached results by query name
+	queries map[string]*cachedQuery
+}
+
+type cachedQuery struct {
+	// lock makes concurrent callers wait for the result of the first one instead of querying too
+	lock       sync.Mutex
+	storedTime time.Time
+	rows       interface{}
+}
+
+// cachedQueryFile is the content of the file of a query in the cache dir
+type cachedQueryFile struct {
+	StoredTime time.Time       `json:"storedTime"`
+	Rows       json.RawMessage `json:"rows"`
+}
+
+var _ CIDataClient = &cachingCIDataClient{}
+
+// NewCachingCIDataClient reuses the results of ListAllJobs, ListReleases and ListAllKnownAlerts for ttl, in memory and
+// in dir when it is set.
+func NewCachingCIDataClient(delegate CIDataClient, ttl time.Duration, dir string) CIDataClient {
+	return &cachingCIDataClient{
+		CIDataClient: delegate,
+		ttl:          ttl,
+		dir:          dir,
+		now:          time.Now,
+		queries:      map[string]*cachedQuery{},
+	}
+}
+
+func (c *cachingCIDataClient) ListAllJobs(ctx context.Context) ([]jobrunaggregatorapi.JobRowWithVariants, error) {
+	return cached(ctx, c, "ListAllJobs", c.CIDataClient.ListAllJobs)
+}
+
+func (c *cachingCIDataClient) ListReleases(ctx context.Context) ([]jobrunaggregatorapi.ReleaseRow, error) {
+	return cached(ctx, c, "ListReleases", c.CIDataClient.ListReleases)
+}
+
+func (c *cachingCIDataClient) ListAllKnownAlerts(ctx context.Context) ([]*jobrunaggregatorapi.KnownAlertRow, error) {
+	return cached(ctx, c, "ListAllKnownAlerts", c.CIDataClient.ListAllKnownAlerts)
+}
+
+func (c *cachingCIDataClient) query(name string) *cachedQuery {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.queries[name]; !ok {
+		c.queries[name] = &cachedQuery{}
+	}
+	return c.queries[name]
+}
+
+// cached returns the result of the query from memory, then from the cache dir, and only runs it when neither is
+// fresher than the ttl.  Errors are not cached.
+func cached[T any](ctx context.Context, c *cachingCIDataClient, name string, query func(context.Context) (T, error)) (T, error) {
+	logger := logrus.WithField("query", name)
+	cache := c.query(name)
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if cache.rows != nil && c.now().Sub(cache.storedTime) < c.ttl {
+		return cache.rows.(T), nil
+	}
+	if rows, storedTime, ok := readCachedQuery[T](c.path(name)); ok && c.now().Sub(storedTime) < c.ttl {
+		logger.WithField("stored", storedTime).Info("reusing cached query results")
+		cache.rows, cache.storedTime = rows, storedTime
+		return rows, nil
+	}
+
+	rows, err := query(ctx)
+	if err != nil {
+		return rows, err
+	}
+	cache.rows, cache.storedTime = rows, c.now()
+	if err := writeCachedQuery(c.path(name), rows, cache.storedTime); err != nil {
+		// the cache is an optimization, the next invocation queries again
+		logger.WithError(err).Warn("failed to cache query results")
+	}
+	return rows, nil
+}
+
+func (c *cachingCIDataClient) path(name string) string {
+	if len(c.dir) == 0 {
+		return ""
+	}
+	return filepath.Join(c.dir, name+".json")
+}
+
+// readCachedQuery misses when there is no cache dir, or the file is missing or can't be read
+func readCachedQuery[T any](path string) (T, time.Time, bool) {
+	var rows T
+	if len(path) == 0 {
+		return rows, time.Time{}, false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return rows, time.Time{}, false
+	}
+	file := cachedQueryFile{}
+	if err := json.Unmarshal(content, &file); err == nil {
+		err = json.Unmarshal(file.Rows, &rows)
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("path", path).Warn("ignoring corrupted cached query results")
+		return rows, time.Time{}, false
+	}
+	return rows, file.StoredTime, true
+}
+
+func writeCachedQuery(path string, rows interface{}, storedTime time.Time) error {
+	if len(path) == 0 {
+		return nil
+	}
+	rowsContent, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(cachedQueryFile{StoredTime: storedTime, Rows: rowsContent})
+	if err != nil {
+		return err
+	}
+	return jobrunaggregatorapi.WriteFileAtomically(path, content)
+}
@@ -0,0 +1,115 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func TestCachingCIDataClient(t *testing.T) {
+	jobs := []jobrunaggregatorapi.JobRowWithVariants{
+		{JobName: "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn-upgrade", FromRelease: bigquery.NullString{StringVal: "4.15", Valid: true}},
+		{JobName: "periodic-ci-openshift-release-master-ci-4.16-e2e-gcp-ovn"},
+	}
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		// run lists all jobs with the clients it creates, and returns the expected number of queries
+		run func(t *testing.T, newClient func(dir string, now time.Time) CIDataClient) int
+	}{
+		{
+			name: "cached in memory",
+			run: func(t *testing.T, newClient func(dir string, now time.Time) CIDataClient) int {
+				client := newClient("", start)
+				for i := 0; i < 3; i++ {
+					actual, err := client.ListAllJobs(context.TODO())
+					assert.NoError(t, err)
+					assert.Equal(t, jobs, actual)
+				}
+				return 1
+			},
+		},
+		{
+			name: "cached on disk for later clients",
+			run: func(t *testing.T, newClient func(dir string, now time.Time) CIDataClient) int {
+				dir := t.TempDir()
+				for _, now := range []time.Time{start, start.Add(30 * time.Minute)} {
+					actual, err := newClient(dir, now).ListAllJobs(context.TODO())
+					assert.NoError(t, err)
+					assert.Equal(t, jobs, actual)
+				}
+				return 1
+			},
+		},
+		{
+			name: "queried again once expired",
+			run: func(t *testing.T, newClient func(dir string, now time.Time) CIDataClient) int {
+				dir := t.TempDir()
+				for _, now := range []time.Time{start, start.Add(2 * time.Hour)} {
+					actual, err := newClient(dir, now).ListAllJobs(context.TODO())
+					assert.NoError(t, err)
+					assert.Equal(t, jobs, actual)
+				}
+				return 2
+			},
+		},
+		{
+			name: "corrupted file is queried again",
+			run: func(t *testing.T, newClient func(dir string, now time.Time) CIDataClient) int {
+				dir := t.TempDir()
+				assert.NoError(t, os.WriteFile(filepath.Join(dir, "ListAllJobs.json"), []byte(`{"storedTime":`), 0644))
+				actual, err := newClient(dir, start).ListAllJobs(context.TODO())
+				assert.NoError(t, err)
+				assert.Equal(t, jobs, actual)
+				return 1
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			delegate := NewMockCIDataClient(mockCtrl)
+			queries := 0
+			delegate.EXPECT().ListAllJobs(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]jobrunaggregatorapi.JobRowWithVariants, error) {
+				queries++
+				return jobs, nil
+			}).AnyTimes()
+			newClient := func(dir string, now time.Time) CIDataClient {
+				client := NewCachingCIDataClient(delegate, time.Hour, dir).(*cachingCIDataClient)
+				client.now = func() time.Time { return now }
+				return client
+			}
+			assert.Equal(t, tc.run(t, newClient), queries)
+		})
+	}
+}
+
+func TestCachingCIDataClientDoesNotCacheErrors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	delegate := NewMockCIDataClient(mockCtrl)
+	releases := []jobrunaggregatorapi.ReleaseRow{{Release: "4.16", Major: 4, Minor: 16}}
+	gomock.InOrder(
+		delegate.EXPECT().ListReleases(gomock.Any()).Return(nil, errors.New("quota exceeded")),
+		delegate.EXPECT().ListReleases(gomock.Any()).Return(releases, nil),
+	)
+
+	client := NewCachingCIDataClient(delegate, time.Hour, t.TempDir())
+	_, err := client.ListReleases(context.TODO())
+	assert.Error(t, err)
+	actual, err := client.ListReleases(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, releases, actual)
+	actual, err = client.ListReleases(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, releases, actual)
+}
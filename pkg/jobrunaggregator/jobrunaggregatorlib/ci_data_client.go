@@ -25,14 +25,26 @@ type AggregationJobClient interface {
 	// This is useful for bounding a query of GCS buckets in a window.
 	// nil means that no jobRun as found after the specified time.
 	GetJobRunForJobNameAfterTime(ctx context.Context, jobName string, targetTime time.Time) (string, error)
+	// ListJobRunNamesBetween lists the job runs of the job that started in [start, end) from the JobRuns table, by
+	// start time.  The loaders only upload job runs once they finished.
+	ListJobRunNamesBetween(ctx context.Context, jobName string, start, end time.Time) ([]string, error)
+	// ListJobRunNamesInRange lists the job runs of the job from startingJobRunID up to endingJobRunID from the JobRuns
+	// table, by ID like the GCS listings.  Empty IDs leave the range unbounded.
+	ListJobRunNamesInRange(ctx context.Context, jobName, startingJobRunID, endingJobRunID string) ([]string, error)
 
 	// GetBackendDisruptionRowCountByJob gets the row count for disruption data for one job
 	GetBackendDisruptionRowCountByJob(ctx context.Context, jobName, masterNodesUpdated string) (uint64, error)
 
 	// GetBackendDisruptionStatisticsByJob gets the mean and p95 disruption per backend from the week from 10 days ago.
 	GetBackendDisruptionStatisticsByJob(ctx context.Context, jobName, masterNodesUpdated string) ([]jobrunaggregatorapi.BackendDisruptionStatisticsRow, error)
+	// ListBackendDisruptionHistogramForJob lists the rows of the BackendDisruptionHistogram view of the job for the
+	// days in [start, end).  An empty masterNodesUpdated lists the rows regardless of it.
+	ListBackendDisruptionHistogramForJob(ctx context.Context, jobName, masterNodesUpdated string, start, end time.Time) ([]jobrunaggregatorapi.BackendDisruptionHistogramRow, error)
 
 	ListAggregatedTestRunsForJob(ctx context.Context, frequency, jobName string, startDay time.Time) ([]jobrunaggregatorapi.AggregatedTestRunRow, error)
+
+	// ListLocatedJobRuns lists the job runs a previous analyzer located for the job and the payload tag or invocation id.
+	ListLocatedJobRuns(ctx context.Context, jobName, matchID string) ([]jobrunaggregatorapi.LocatedJobRunRow, error)
 }
 
 type JobLister interface {
@@ -40,13 +52,33 @@ type JobLister interface {
 
 	// ListProwJobRunsSince lists from the testplatform BigQuery dataset in a separate project from
 	// where we normally operate. Job runs are inserted here just after their GCS artifacts are uploaded.
-	// This function is used for importing runs we do not yet have into our tables.
-	ListProwJobRunsSince(ctx context.Context, since *time.Time) ([]*jobrunaggregatorapi.TestPlatformProwJobRow, error)
+	// This function is used for importing runs we do not yet have into our tables.  The job runs the loader already
+	// loaded according to the checkpoints, by job name, are left out.
+	ListProwJobRunsSince(ctx context.Context, since *time.Time, checkpoints map[string]jobrunaggregatorapi.JobRunCheckpointRow) ([]*jobrunaggregatorapi.TestPlatformProwJobRow, error)
+
+	// ListJobRunLoadExceptions lists the job runs operators asked the loaders to skip or reprocess.
+	ListJobRunLoadExceptions(ctx context.Context) ([]jobrunaggregatorapi.JobRunLoadExceptionRow, error)
+
+	// ListJobRunCheckpoints lists the latest checkpoint the loader committed for every job, by job name.
+	ListJobRunCheckpoints(ctx context.Context, loader string) (map[string]jobrunaggregatorapi.JobRunCheckpointRow, error)
+
+	// DeleteJobRunRowsFromTables deletes the rows of the given job runs from every table in a single transaction, so
+	// that reprocessed job runs are not counted twice.  When the rows of one of the tables can't be deleted, no rows
+	// are deleted at all.  It returns the number of deleted rows.
+	DeleteJobRunRowsFromTables(ctx context.Context, tables []string, jobRunNames []string) (int64, error)
 }
 
 type HistoricalDataClient interface {
 	ListDisruptionHistoricalData(ctx context.Context) ([]jobrunaggregatorapi.HistoricalData, error)
 	ListAlertHistoricalData(ctx context.Context) ([]*jobrunaggregatorapi.AlertHistoricalDataRow, error)
+
+	// ListBackendDisruptionHistogram lists the rows of the BackendDisruptionHistogram view of every job for the days
+	// in [start, end).  The histograms of separate days can be merged, so the days can be queried concurrently and
+	// the historical data computed with NewDisruptionHistoricalData.
+	ListBackendDisruptionHistogram(ctx context.Context, start, end time.Time) ([]jobrunaggregatorapi.BackendDisruptionHistogramRow, error)
+	// ListAlertHistogram counts the job runs of every job that started in [start, end) by alert and seconds, from the
+	// Alerts table.  The historical data is computed from it with NewAlertHistoricalData.
+	ListAlertHistogram(ctx context.Context, start, end time.Time) ([]jobrunaggregatorapi.AlertHistogramRow, error)
 }
 
 type CIDataClient interface {
@@ -56,6 +88,17 @@ type CIDataClient interface {
 
 	// these deal with release tags
 	ListReleaseTags(ctx context.Context) (sets.Set[string], error)
+	// ListReleaseTagsForStream lists the accepted and rejected payloads of a release stream created since the given time, newest first.
+	ListReleaseTagsForStream(ctx context.Context, release, stream, architecture string, since time.Time) ([]jobrunaggregatorapi.ReleaseTagRow, error)
+	// ListReleaseMilestoneTags lists the accepted engineering, feature and release candidates and the GA payloads,
+	// like 4.16.0-ec.1, 4.16.0-fc.0, 4.16.0-rc.2 or 4.16.0, of the given releases, oldest first.
+	ListReleaseMilestoneTags(ctx context.Context, releases []string) ([]jobrunaggregatorapi.ReleaseTagRow, error)
+	// ListReleaseJobRunsForReleaseTags lists the job runs the release controller ran to decide on the given payloads.
+	ListReleaseJobRunsForReleaseTags(ctx context.Context, releaseTags []string) ([]jobrunaggregatorapi.ReleaseJobRunRow, error)
+	// ListGateOverridesForPayloadTags lists the test cases release architects force-accepted for the given payloads.
+	ListGateOverridesForPayloadTags(ctx context.Context, payloadTags []string) ([]jobrunaggregatorapi.GateOverrideRow, error)
+	// ListGateResultsForPayloadTags lists the verdicts the given analyzer recorded for the job name and the payloads.
+	ListGateResultsForPayloadTags(ctx context.Context, analyzer, jobName string, payloadTags []string) ([]jobrunaggregatorapi.GateResultRow, error)
 
 	// GetLastJobRunEndTimeFromTable returns the last uploaded job runs EndTime in the given table.
 	GetLastJobRunEndTimeFromTable(ctx context.Context, table string) (*time.Time, error)
@@ -66,11 +109,22 @@ type CIDataClient interface {
 
 	// ListReleases lists all releases from the new release table
 	ListReleases(ctx context.Context) ([]jobrunaggregatorapi.ReleaseRow, error)
+
+	// ListJobsWithoutSuccessfulRunsSince lists the jobs that ran since the given time, but never succeeded.
+	ListJobsWithoutSuccessfulRunsSince(ctx context.Context, since time.Time) (sets.Set[string], error)
+
+	// ListJobRunDurationStatistics summarizes the duration of the runs of the given jobs that started since the given time.
+	ListJobRunDurationStatistics(ctx context.Context, jobNames []string, since time.Time) ([]jobrunaggregatorapi.JobRunDurationStatisticsRow, error)
+
+	// ListJobRunSuccessStatistics counts the finished and the successful runs of the given jobs that started since the given time.
+	ListJobRunSuccessStatistics(ctx context.Context, jobNames []string, since time.Time) ([]jobrunaggregatorapi.JobRunSuccessStatisticsRow, error)
 }
 
 type ciDataClient struct {
 	dataCoordinates BigQueryDataCoordinates
 	client          *bigquery.Client
+	// maxBytesBilled fails the queries that would bill more bytes, 0 is unbounded
+	maxBytesBilled int64
 }
 
 type RowCount struct {
@@ -125,7 +179,7 @@ ORDER BY
     BackendName
 `)
 	query := c.client.Query(queryString)
-	disruptionRow, err := query.Read(ctx)
+	disruptionRow, err := c.readQuery(ctx, "ListDisruptionHistoricalData", query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query disruption tables with %q: %w", queryString, err)
 	}
@@ -171,7 +225,7 @@ func (c *ciDataClient) ListAlertHistoricalData(ctx context.Context) ([]*jobrunag
         Release, AlertName, AlertNamespace, AlertLevel, FromRelease, Topology, Platform, Network
     `)
 	query := c.client.Query(queryString)
-	disruptionRow, err := query.Read(ctx)
+	disruptionRow, err := c.readQuery(ctx, "ListAlertHistoricalData", query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query disruption tables with %q: %w", queryString, err)
 	}
@@ -216,7 +270,7 @@ ORDER BY JobName ASC
 `)
 
 	query := c.client.Query(queryString)
-	jobRows, err := query.Read(ctx)
+	jobRows, err := c.readQuery(ctx, "ListAllJobs", query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query job table with %q: %w", queryString, err)
 	}
@@ -236,6 +290,130 @@ ORDER BY JobName ASC
 	return jobs, nil
 }
 
+func (c *ciDataClient) ListJobRunLoadExceptions(ctx context.Context) ([]jobrunaggregatorapi.JobRunLoadExceptionRow, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(
+		`SELECT JobName, JobRunName, Action, Reason, CreatedTime
+FROM DATA_SET_LOCATION.` + jobrunaggregatorapi.JobRunLoadExceptionsTableName + `
+ORDER BY CreatedTime ASC
+`)
+
+	query := c.client.Query(queryString)
+	exceptionRows, err := c.readQuery(ctx, "ListJobRunLoadExceptions", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job run load exceptions with %q: %w", queryString, err)
+	}
+	exceptions := []jobrunaggregatorapi.JobRunLoadExceptionRow{}
+	for {
+		exception := jobrunaggregatorapi.JobRunLoadExceptionRow{}
+		err = exceptionRows.Next(&exception)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		exceptions = append(exceptions, exception)
+	}
+
+	return exceptions, nil
+}
+
+func (c *ciDataClient) DeleteJobRunRowsFromTables(ctx context.Context, tables []string, jobRunNames []string) (int64, error) {
+	// rows still in the streaming buffer, inserted within the last 30 minutes or so, cannot be deleted and fail the
+	// whole statement.  Reprocessed job runs were loaded long before, but when they weren't, the rows already deleted
+	// from the other tables must come back, or nothing would load them again.
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(deleteJobRunRowsScript(tables))
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "JobRunNames", Value: jobRunNames},
+	}
+	job, err := query.Run(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete job run rows with %q: %w", queryString, err)
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete job run rows with %q: %w", queryString, err)
+	}
+	if err := status.Err(); err != nil {
+		return 0, fmt.Errorf("failed to delete job run rows with %q: %w", queryString, err)
+	}
+
+	// every statement of the script runs in a child job, the deleted rows are counted by the DELETE ones
+	var deleted int64
+	children := job.Children(ctx)
+	for {
+		child, err := children.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			// the rows are deleted, only their count is missing
+			logrus.WithError(err).Warning("failed to count the deleted job run rows")
+			break
+		}
+		if child.LastStatus() == nil {
+			continue
+		}
+		if statistics, ok := child.LastStatus().Statistics.Details.(*bigquery.QueryStatistics); ok && statistics.StatementType == "DELETE" {
+			deleted += statistics.NumDMLAffectedRows
+		}
+	}
+	return deleted, nil
+}
+
+// deleteJobRunRowsScript deletes the rows of the @JobRunNames job runs from the tables in a transaction, which is
+// rolled back when any of the deletions fails.
+func deleteJobRunRowsScript(tables []string) string {
+	lines := []string{
+		"BEGIN",
+		"  BEGIN TRANSACTION;",
+	}
+	for _, table := range tables {
+		lines = append(lines, "  DELETE FROM DATA_SET_LOCATION."+table+" WHERE JobRunName IN UNNEST(@JobRunNames);")
+	}
+	lines = append(lines,
+		"  COMMIT TRANSACTION;",
+		"EXCEPTION WHEN ERROR THEN",
+		"  ROLLBACK TRANSACTION;",
+		"  RAISE USING MESSAGE = @@error.message;",
+		"END;",
+	)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (c *ciDataClient) ListJobRunCheckpoints(ctx context.Context, loader string) (map[string]jobrunaggregatorapi.JobRunCheckpointRow, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(
+		`SELECT Loader, JobName, JobRunName, CompletedBefore, CommittedTime
+FROM DATA_SET_LOCATION.` + jobrunaggregatorapi.JobRunCheckpointsTableName + `
+WHERE Loader = @Loader
+QUALIFY ROW_NUMBER() OVER (PARTITION BY JobName ORDER BY CommittedTime DESC) = 1
+`)
+
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "Loader", Value: loader},
+	}
+	checkpointRows, err := c.readQuery(ctx, "ListJobRunCheckpoints", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job run checkpoints with %q: %w", queryString, err)
+	}
+	checkpoints := map[string]jobrunaggregatorapi.JobRunCheckpointRow{}
+	for {
+		checkpoint := jobrunaggregatorapi.JobRunCheckpointRow{}
+		err = checkpointRows.Next(&checkpoint)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		checkpoints[checkpoint.JobName] = checkpoint
+	}
+
+	return checkpoints, nil
+}
+
 // GetLastJobRunEndTimeFromTable retrieves the last imported job end time.
 func (c *ciDataClient) GetLastJobRunEndTimeFromTable(ctx context.Context, table string) (*time.Time, error) {
 	// Caution here, these tables can be large, especially for alerts. Do not query additional columns.
@@ -252,7 +430,7 @@ func (c *ciDataClient) GetLastJobRunEndTimeFromTable(ctx context.Context, table
 	}
 
 	query := c.client.Query(queryString)
-	rows, err := query.Read(ctx)
+	rows, err := c.readQuery(ctx, "GetLastJobRunEndTimeFromTable", query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query job table with %q: %w", queryString, err)
 	}
@@ -283,7 +461,7 @@ ORDER BY JobRunEndTime ASC
 	query.QueryConfig.Parameters = []bigquery.QueryParameter{
 		{Name: "Since", Value: *since},
 	}
-	jobRows, err := query.Read(ctx)
+	jobRows, err := c.readQuery(ctx, "ListUploadedJobRunIDsSinceFromTable", query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query job table with %q: %w", queryString, err)
 	}
@@ -308,7 +486,29 @@ ORDER BY JobRunEndTime ASC
 	return jobRunIDs, nil
 }
 
-func (c *ciDataClient) ListProwJobRunsSince(ctx context.Context, since *time.Time) ([]*jobrunaggregatorapi.TestPlatformProwJobRow, error) {
+// prowJobRunCheckpoint is the query parameter of a job run checkpoint, BigQuery infers the STRUCT from its fields
+type prowJobRunCheckpoint struct {
+	JobName         string
+	JobRunName      string
+	CompletedBefore time.Time
+}
+
+// prowJobRunCheckpoints returns the checkpoints as a query parameter, sorted by job name so that queries with the
+// same checkpoints are identical.  It is never nil, BigQuery needs an empty array rather than a NULL.
+func prowJobRunCheckpoints(checkpoints map[string]jobrunaggregatorapi.JobRunCheckpointRow) []prowJobRunCheckpoint {
+	ret := []prowJobRunCheckpoint{}
+	for _, jobName := range sets.List(sets.KeySet(checkpoints)) {
+		checkpoint := checkpoints[jobName]
+		ret = append(ret, prowJobRunCheckpoint{
+			JobName:         jobName,
+			JobRunName:      checkpoint.JobRunName,
+			CompletedBefore: checkpoint.CompletedBefore,
+		})
+	}
+	return ret
+}
+
+func (c *ciDataClient) ListProwJobRunsSince(ctx context.Context, since *time.Time, checkpoints map[string]jobrunaggregatorapi.JobRunCheckpointRow) ([]*jobrunaggregatorapi.TestPlatformProwJobRow, error) {
 	// NOTE: this query is going to a different GCP project and data set to list the
 	// prow jobs stored by testplatform.
 	queryString := `SELECT 
@@ -321,16 +521,23 @@ func (c *ciDataClient) ListProwJobRunsSince(ctx context.Context, since *time.Tim
 			TIMESTAMP(prowjob_start) AS prowjob_start_ts, 
 			TIMESTAMP(prowjob_completion) AS prowjob_completion_ts ` +
 		"FROM `openshift-gce-devel.ci_analysis_us.jobs` " +
-		`WHERE TIMESTAMP(prowjob_completion) > @Since 
+		// job run IDs are numbers growing with time, the longer one is the later one
+		`LEFT JOIN UNNEST(@Checkpoints) AS checkpoint ON checkpoint.JobName = prowjob_job_name
+           WHERE TIMESTAMP(prowjob_completion) > @Since 
            AND prowjob_url IS NOT NULL 
            AND prowjob_start is NOT NULL
            AND prowjob_completion is NOT NULL
+           AND (checkpoint.JobName IS NULL
+             OR TIMESTAMP(prowjob_completion) >= checkpoint.CompletedBefore
+             OR LENGTH(prowjob_build_id) > LENGTH(checkpoint.JobRunName)
+             OR (LENGTH(prowjob_build_id) = LENGTH(checkpoint.JobRunName) AND prowjob_build_id > checkpoint.JobRunName))
            ORDER BY prowjob_completion_ts`
 	query := c.client.Query(queryString)
 	query.QueryConfig.Parameters = []bigquery.QueryParameter{
 		{Name: "Since", Value: *since},
+		{Name: "Checkpoints", Value: prowJobRunCheckpoints(checkpoints)},
 	}
-	jobRows, err := query.Read(ctx)
+	jobRows, err := c.readQuery(ctx, "ListProwJobRunsSince", query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query job table with %q: %w", queryString, err)
 	}
@@ -380,7 +587,7 @@ AND
 		{Name: "JobName", Value: jobName},
 	}
 
-	it, err := query.Read(ctx)
+	it, err := c.readQuery(ctx, "GetBackendDisruptionRowCountByJob", query)
 	if err != nil {
 		return 0, err
 	}
@@ -395,7 +602,146 @@ AND
 	return uint64(rowCount.TotalRows), nil
 }
 
+// GetBackendDisruptionStatisticsByJob computes the statistics from the BackendDisruptionHistogram view, and only
+// computes them from the BackendDisruption table until create-tables created the view.
 func (c *ciDataClient) GetBackendDisruptionStatisticsByJob(ctx context.Context, jobName, masterNodesUpdated string) ([]jobrunaggregatorapi.BackendDisruptionStatisticsRow, error) {
+	start, end := disruptionStatisticsWindow(time.Now())
+	histogram, err := c.ListBackendDisruptionHistogramForJob(ctx, jobName, masterNodesUpdated, start, end)
+	if isNotFound(err) {
+		logrus.WithError(err).Warnf("%s is missing, computing the disruption statistics from %s", jobrunaggregatorapi.BackendDisruptionHistogramViewName, jobrunaggregatorapi.BackendDisruptionTableName)
+		return c.getBackendDisruptionStatisticsByJobFromTable(ctx, jobName, masterNodesUpdated)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewBackendDisruptionStatistics(histogram), nil
+}
+
+func (c *ciDataClient) ListBackendDisruptionHistogramForJob(ctx context.Context, jobName, masterNodesUpdated string, start, end time.Time) ([]jobrunaggregatorapi.BackendDisruptionHistogramRow, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(`
+SELECT *
+FROM
+    DATA_SET_LOCATION.BackendDisruptionHistogram
+WHERE
+    JobRunStartDay >= @Start
+AND
+    JobRunStartDay < @End
+AND
+    JobName = @JobName
+AND
+    (@MasterNodesUpdated = '' OR MasterNodesUpdated = @MasterNodesUpdated)
+`)
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "JobName", Value: jobName},
+		{Name: "MasterNodesUpdated", Value: masterNodesUpdated},
+		{Name: "Start", Value: start},
+		{Name: "End", Value: end},
+	}
+
+	it, err := c.readQuery(ctx, "ListBackendDisruptionHistogramForJob", query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []jobrunaggregatorapi.BackendDisruptionHistogramRow{}
+	for {
+		row := jobrunaggregatorapi.BackendDisruptionHistogramRow{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (c *ciDataClient) ListBackendDisruptionHistogram(ctx context.Context, start, end time.Time) ([]jobrunaggregatorapi.BackendDisruptionHistogramRow, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(`
+SELECT *
+FROM
+    DATA_SET_LOCATION.BackendDisruptionHistogram
+WHERE
+    JobRunStartDay >= @Start
+AND
+    JobRunStartDay < @End
+`)
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "Start", Value: start},
+		{Name: "End", Value: end},
+	}
+
+	it, err := c.readQuery(ctx, "ListBackendDisruptionHistogram", query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []jobrunaggregatorapi.BackendDisruptionHistogramRow{}
+	for {
+		row := jobrunaggregatorapi.BackendDisruptionHistogramRow{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (c *ciDataClient) ListAlertHistogram(ctx context.Context, start, end time.Time) ([]jobrunaggregatorapi.AlertHistogramRow, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(`
+SELECT
+    JobName,
+    Name AS AlertName,
+    Namespace AS AlertNamespace,
+    Level AS AlertLevel,
+    AlertSeconds,
+    COUNT(*) AS JobRuns,
+    MIN(JobRunStartTime) AS FirstObserved,
+    MAX(JobRunStartTime) AS LastObserved
+FROM
+    DATA_SET_LOCATION.` + jobrunaggregatorapi.AlertsTableName + `
+WHERE
+    JobRunStartTime >= @Start
+AND
+    JobRunStartTime < @End
+GROUP BY
+    JobName, Name, Namespace, Level, AlertSeconds
+`)
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "Start", Value: start},
+		{Name: "End", Value: end},
+	}
+
+	it, err := c.readQuery(ctx, "ListAlertHistogram", query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []jobrunaggregatorapi.AlertHistogramRow{}
+	for {
+		row := jobrunaggregatorapi.AlertHistogramRow{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (c *ciDataClient) getBackendDisruptionStatisticsByJobFromTable(ctx context.Context, jobName, masterNodesUpdated string) ([]jobrunaggregatorapi.BackendDisruptionStatisticsRow, error) {
 	rows := make([]jobrunaggregatorapi.BackendDisruptionStatisticsRow, 0)
 	masterNodesUpdatedSQL := buildMasterNodesUpdatedSQL("BackendDisruption", masterNodesUpdated)
 
@@ -747,7 +1093,7 @@ ON
 		{Name: "JobName", Value: jobName},
 	}
 
-	it, err := query.Read(ctx)
+	it, err := c.readQuery(ctx, "GetBackendDisruptionStatisticsByJob", query)
 	if err != nil {
 		return nil, err
 	}
@@ -782,7 +1128,7 @@ func (c *ciDataClient) ListReleaseTags(ctx context.Context) (sets.Set[string], e
 	set := sets.Set[string]{}
 	queryString := c.dataCoordinates.SubstituteDataSetLocation(`SELECT distinct(ReleaseTag) FROM DATA_SET_LOCATION.ReleaseTags`)
 	query := c.client.Query(queryString)
-	it, err := query.Read(ctx)
+	it, err := c.readQuery(ctx, "ListReleaseTags", query)
 	if err != nil {
 		return nil, err
 	}
@@ -802,11 +1148,271 @@ func (c *ciDataClient) ListReleaseTags(ctx context.Context) (sets.Set[string], e
 	return set, nil
 }
 
+func (c *ciDataClient) ListReleaseTagsForStream(ctx context.Context, release, stream, architecture string, since time.Time) ([]jobrunaggregatorapi.ReleaseTagRow, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(`
+SELECT *
+FROM DATA_SET_LOCATION.ReleaseTags
+WHERE release = @Release AND stream = @Stream AND architecture = @Architecture AND releaseTime >= @Since
+ORDER BY releaseTime DESC
+`)
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "Release", Value: release},
+		{Name: "Stream", Value: stream},
+		{Name: "Architecture", Value: architecture},
+		{Name: "Since", Value: since},
+	}
+	it, err := c.readQuery(ctx, "ListReleaseTagsForStream", query)
+	if err != nil {
+		return nil, err
+	}
+	ret := []jobrunaggregatorapi.ReleaseTagRow{}
+	for {
+		row := jobrunaggregatorapi.ReleaseTagRow{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, row)
+	}
+	return ret, nil
+}
+
+func (c *ciDataClient) ListReleaseMilestoneTags(ctx context.Context, releases []string) ([]jobrunaggregatorapi.ReleaseTagRow, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(`
+SELECT *
+FROM DATA_SET_LOCATION.ReleaseTags
+WHERE release IN UNNEST(@Releases)
+  AND phase = "Accepted"
+  AND REGEXP_CONTAINS(releaseTag, r"^[0-9]+\.[0-9]+\.0(-(ec|fc|rc)\.[0-9]+)?$")
+ORDER BY releaseTime ASC
+`)
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "Releases", Value: releases},
+	}
+	it, err := c.readQuery(ctx, "ListReleaseMilestoneTags", query)
+	if err != nil {
+		return nil, err
+	}
+	ret := []jobrunaggregatorapi.ReleaseTagRow{}
+	for {
+		row := jobrunaggregatorapi.ReleaseTagRow{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, row)
+	}
+	return ret, nil
+}
+
+func (c *ciDataClient) ListReleaseJobRunsForReleaseTags(ctx context.Context, releaseTags []string) ([]jobrunaggregatorapi.ReleaseJobRunRow, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(`
+SELECT *
+FROM DATA_SET_LOCATION.ReleaseJobRuns
+WHERE releaseTag IN UNNEST(@ReleaseTags)
+ORDER BY releaseTag, jobName
+`)
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "ReleaseTags", Value: releaseTags},
+	}
+	it, err := c.readQuery(ctx, "ListReleaseJobRunsForReleaseTags", query)
+	if err != nil {
+		return nil, err
+	}
+	ret := []jobrunaggregatorapi.ReleaseJobRunRow{}
+	for {
+		row := jobrunaggregatorapi.ReleaseJobRunRow{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, row)
+	}
+	return ret, nil
+}
+
+func (c *ciDataClient) ListGateOverridesForPayloadTags(ctx context.Context, payloadTags []string) ([]jobrunaggregatorapi.GateOverrideRow, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(`
+SELECT *
+FROM DATA_SET_LOCATION.GateOverrides
+WHERE PayloadTag IN UNNEST(@PayloadTags)
+ORDER BY OverrideTime
+`)
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "PayloadTags", Value: payloadTags},
+	}
+	it, err := c.readQuery(ctx, "ListGateOverridesForPayloadTags", query)
+	if err != nil {
+		return nil, err
+	}
+	ret := []jobrunaggregatorapi.GateOverrideRow{}
+	for {
+		row := jobrunaggregatorapi.GateOverrideRow{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, row)
+	}
+	return ret, nil
+}
+
+func (c *ciDataClient) ListGateResultsForPayloadTags(ctx context.Context, analyzer, jobName string, payloadTags []string) ([]jobrunaggregatorapi.GateResultRow, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(`
+SELECT *
+FROM DATA_SET_LOCATION.GateResults
+WHERE Analyzer = @Analyzer AND JobName = @JobName AND PayloadTag IN UNNEST(@PayloadTags)
+ORDER BY ResultTime
+`)
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "Analyzer", Value: analyzer},
+		{Name: "JobName", Value: jobName},
+		{Name: "PayloadTags", Value: payloadTags},
+	}
+	it, err := c.readQuery(ctx, "ListGateResultsForPayloadTags", query)
+	if err != nil {
+		return nil, err
+	}
+	ret := []jobrunaggregatorapi.GateResultRow{}
+	for {
+		row := jobrunaggregatorapi.GateResultRow{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, row)
+	}
+	return ret, nil
+}
+
+func (c *ciDataClient) ListJobsWithoutSuccessfulRunsSince(ctx context.Context, since time.Time) (sets.Set[string], error) {
+	set := sets.Set[string]{}
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(`
+SELECT JobName
+FROM DATA_SET_LOCATION.JobRuns
+WHERE StartTime >= @Since
+GROUP BY JobName
+HAVING COUNTIF(Status = 'success') = 0
+`)
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "Since", Value: since},
+	}
+	it, err := c.readQuery(ctx, "ListJobsWithoutSuccessfulRunsSince", query)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		row := struct {
+			JobName string
+		}{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		set.Insert(row.JobName)
+	}
+
+	return set, nil
+}
+
+func (c *ciDataClient) ListJobRunDurationStatistics(ctx context.Context, jobNames []string, since time.Time) ([]jobrunaggregatorapi.JobRunDurationStatisticsRow, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(`
+SELECT
+	JobName,
+	APPROX_QUANTILES(TIMESTAMP_DIFF(EndTime, StartTime, SECOND), 100)[OFFSET(95)] AS P95DurationSeconds,
+	MAX(TIMESTAMP_DIFF(EndTime, StartTime, SECOND)) AS MaxDurationSeconds
+FROM DATA_SET_LOCATION.JobRuns
+WHERE StartTime >= @Since AND EndTime IS NOT NULL AND JobName IN UNNEST(@JobNames)
+GROUP BY JobName
+`)
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "Since", Value: since},
+		{Name: "JobNames", Value: jobNames},
+	}
+	it, err := c.readQuery(ctx, "ListJobRunDurationStatistics", query)
+	if err != nil {
+		return nil, err
+	}
+	ret := []jobrunaggregatorapi.JobRunDurationStatisticsRow{}
+	for {
+		row := jobrunaggregatorapi.JobRunDurationStatisticsRow{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, row)
+	}
+	return ret, nil
+}
+
+func (c *ciDataClient) ListJobRunSuccessStatistics(ctx context.Context, jobNames []string, since time.Time) ([]jobrunaggregatorapi.JobRunSuccessStatisticsRow, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(`
+SELECT
+	JobName,
+	COUNT(*) AS JobRuns,
+	COUNTIF(Status = 'success') AS SuccessfulJobRuns
+FROM DATA_SET_LOCATION.JobRuns
+WHERE StartTime >= @Since AND EndTime IS NOT NULL AND JobName IN UNNEST(@JobNames)
+GROUP BY JobName
+`)
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "Since", Value: since},
+		{Name: "JobNames", Value: jobNames},
+	}
+	it, err := c.readQuery(ctx, "ListJobRunSuccessStatistics", query)
+	if err != nil {
+		return nil, err
+	}
+	ret := []jobrunaggregatorapi.JobRunSuccessStatisticsRow{}
+	for {
+		row := jobrunaggregatorapi.JobRunSuccessStatisticsRow{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, row)
+	}
+	return ret, nil
+}
+
 func (c *ciDataClient) ListReleases(ctx context.Context) ([]jobrunaggregatorapi.ReleaseRow, error) {
 	releases := []jobrunaggregatorapi.ReleaseRow{}
 	queryString := c.dataCoordinates.SubstituteDataSetLocation(`SELECT * FROM DATA_SET_LOCATION.Releases ORDER BY DevelStartDate DESC`)
 	query := c.client.Query(queryString)
-	it, err := query.Read(ctx)
+	it, err := c.readQuery(ctx, "ListReleases", query)
 	if err != nil {
 		return nil, err
 	}
@@ -853,7 +1459,7 @@ LIMIT 1
 		{Name: "TimeCutOff", Value: targetTime},
 		{Name: "JobName", Value: jobName},
 	}
-	rowIterator, err := query.Read(ctx)
+	rowIterator, err := c.readQuery(ctx, "GetJobRunForJobNameBeforeTime", query)
 	if err != nil {
 		return "", err
 	}
@@ -883,7 +1489,7 @@ LIMIT 1
 		{Name: "TimeCutOff", Value: targetTime},
 		{Name: "JobName", Value: jobName},
 	}
-	rowIterator, err := query.Read(ctx)
+	rowIterator, err := c.readQuery(ctx, "GetJobRunForJobNameAfterTime", query)
 	if err != nil {
 		return "", err
 	}
@@ -899,6 +1505,105 @@ LIMIT 1
 	return ret.Name, nil
 }
 
+func (c *ciDataClient) ListJobRunNamesBetween(ctx context.Context, jobName string, start, end time.Time) ([]string, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(
+		`SELECT Name
+FROM DATA_SET_LOCATION.JobRuns
+WHERE JobRuns.StartTime >= @Start and JobRuns.StartTime < @End and JobRuns.JobName = @JobName
+ORDER BY JobRuns.StartTime ASC
+`)
+
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "Start", Value: start},
+		{Name: "End", Value: end},
+		{Name: "JobName", Value: jobName},
+	}
+	rowIterator, err := c.readQuery(ctx, "ListJobRunNamesBetween", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job runs with %q: %w", queryString, err)
+	}
+
+	jobRunIDs := []string{}
+	for {
+		row := &jobrunaggregatorapi.JobRunRow{}
+		err := rowIterator.Next(row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		jobRunIDs = append(jobRunIDs, row.Name)
+	}
+	return jobRunIDs, nil
+}
+
+func (c *ciDataClient) ListJobRunNamesInRange(ctx context.Context, jobName, startingJobRunID, endingJobRunID string) ([]string, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(
+		`SELECT Name
+FROM DATA_SET_LOCATION.JobRuns
+WHERE JobRuns.JobName = @JobName and (@StartingJobRunID = "" or JobRuns.Name >= @StartingJobRunID) and (@EndingJobRunID = "" or JobRuns.Name < @EndingJobRunID)
+ORDER BY JobRuns.Name ASC
+`)
+
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "JobName", Value: jobName},
+		{Name: "StartingJobRunID", Value: startingJobRunID},
+		{Name: "EndingJobRunID", Value: endingJobRunID},
+	}
+	rowIterator, err := c.readQuery(ctx, "ListJobRunNamesInRange", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job runs with %q: %w", queryString, err)
+	}
+
+	jobRunIDs := []string{}
+	for {
+		row := &jobrunaggregatorapi.JobRunRow{}
+		err := rowIterator.Next(row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		jobRunIDs = append(jobRunIDs, row.Name)
+	}
+	return jobRunIDs, nil
+}
+
+func (c *ciDataClient) ListLocatedJobRuns(ctx context.Context, jobName, matchID string) ([]jobrunaggregatorapi.LocatedJobRunRow, error) {
+	queryString := c.dataCoordinates.SubstituteDataSetLocation(`
+SELECT *
+FROM DATA_SET_LOCATION.` + jobrunaggregatorapi.LocatedJobRunsTableName + `
+WHERE JobName = @JobName AND MatchID = @MatchID
+ORDER BY JobRunID
+`)
+	query := c.client.Query(queryString)
+	query.QueryConfig.Parameters = []bigquery.QueryParameter{
+		{Name: "JobName", Value: jobName},
+		{Name: "MatchID", Value: matchID},
+	}
+	it, err := c.readQuery(ctx, "ListLocatedJobRuns", query)
+	if err != nil {
+		return nil, err
+	}
+	ret := []jobrunaggregatorapi.LocatedJobRunRow{}
+	for {
+		row := jobrunaggregatorapi.LocatedJobRunRow{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, row)
+	}
+	return ret, nil
+}
+
 func (c *ciDataClient) ListAggregatedTestRunsForJob(ctx context.Context, frequency, jobName string, startDay time.Time) ([]jobrunaggregatorapi.AggregatedTestRunRow, error) {
 	frequencyTable, err := c.tableForFrequency(frequency)
 	if err != nil {
@@ -917,7 +1622,7 @@ WHERE TABLE_NAME.JobName = @JobName
 	query.QueryConfig.Parameters = []bigquery.QueryParameter{
 		{Name: "JobName", Value: jobName},
 	}
-	rows, err := query.Read(ctx)
+	rows, err := c.readQuery(ctx, "ListAggregatedTestRunsForJob", query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query job table with %q: %w", queryString, err)
 	}
@@ -950,7 +1655,7 @@ func (c *ciDataClient) ListAllKnownAlerts(ctx context.Context) ([]*jobrunaggrega
 `)
 
 	query := c.client.Query(queryString)
-	alertsRows, err := query.Read(ctx)
+	alertsRows, err := c.readQuery(ctx, "ListAllKnownAlerts", query)
 	if err != nil {
 		err = fmt.Errorf("failed to query Alerts_AllKnown view with %q: %w", queryString, err)
 		logrus.Error(err.Error())
@@ -39,6 +39,21 @@ func (m *MockCIDataClient) EXPECT() *MockCIDataClientMockRecorder {
 	return m.recorder
 }
 
+// DeleteJobRunRowsFromTables mocks base method.
+func (m *MockCIDataClient) DeleteJobRunRowsFromTables(arg0 context.Context, arg1, arg2 []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteJobRunRowsFromTables", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteJobRunRowsFromTables indicates an expected call of DeleteJobRunRowsFromTables.
+func (mr *MockCIDataClientMockRecorder) DeleteJobRunRowsFromTables(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteJobRunRowsFromTables", reflect.TypeOf((*MockCIDataClient)(nil).DeleteJobRunRowsFromTables), arg0, arg1, arg2)
+}
+
 // GetBackendDisruptionRowCountByJob mocks base method.
 func (m *MockCIDataClient) GetBackendDisruptionRowCountByJob(arg0 context.Context, arg1, arg2 string) (uint64, error) {
 	m.ctrl.T.Helper()
@@ -144,6 +159,21 @@ func (mr *MockCIDataClientMockRecorder) ListAggregatedTestRunsForJob(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAggregatedTestRunsForJob", reflect.TypeOf((*MockCIDataClient)(nil).ListAggregatedTestRunsForJob), arg0, arg1, arg2, arg3)
 }
 
+// ListAlertHistogram mocks base method.
+func (m *MockCIDataClient) ListAlertHistogram(arg0 context.Context, arg1, arg2 time.Time) ([]jobrunaggregatorapi.AlertHistogramRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAlertHistogram", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]jobrunaggregatorapi.AlertHistogramRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAlertHistogram indicates an expected call of ListAlertHistogram.
+func (mr *MockCIDataClientMockRecorder) ListAlertHistogram(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAlertHistogram", reflect.TypeOf((*MockCIDataClient)(nil).ListAlertHistogram), arg0, arg1, arg2)
+}
+
 // ListAlertHistoricalData mocks base method.
 func (m *MockCIDataClient) ListAlertHistoricalData(arg0 context.Context) ([]*jobrunaggregatorapi.AlertHistoricalDataRow, error) {
 	m.ctrl.T.Helper()
@@ -189,6 +219,36 @@ func (mr *MockCIDataClientMockRecorder) ListAllKnownAlerts(arg0 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllKnownAlerts", reflect.TypeOf((*MockCIDataClient)(nil).ListAllKnownAlerts), arg0)
 }
 
+// ListBackendDisruptionHistogram mocks base method.
+func (m *MockCIDataClient) ListBackendDisruptionHistogram(arg0 context.Context, arg1, arg2 time.Time) ([]jobrunaggregatorapi.BackendDisruptionHistogramRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBackendDisruptionHistogram", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]jobrunaggregatorapi.BackendDisruptionHistogramRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBackendDisruptionHistogram indicates an expected call of ListBackendDisruptionHistogram.
+func (mr *MockCIDataClientMockRecorder) ListBackendDisruptionHistogram(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBackendDisruptionHistogram", reflect.TypeOf((*MockCIDataClient)(nil).ListBackendDisruptionHistogram), arg0, arg1, arg2)
+}
+
+// ListBackendDisruptionHistogramForJob mocks base method.
+func (m *MockCIDataClient) ListBackendDisruptionHistogramForJob(arg0 context.Context, arg1, arg2 string, arg3, arg4 time.Time) ([]jobrunaggregatorapi.BackendDisruptionHistogramRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBackendDisruptionHistogramForJob", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]jobrunaggregatorapi.BackendDisruptionHistogramRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBackendDisruptionHistogramForJob indicates an expected call of ListBackendDisruptionHistogramForJob.
+func (mr *MockCIDataClientMockRecorder) ListBackendDisruptionHistogramForJob(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBackendDisruptionHistogramForJob", reflect.TypeOf((*MockCIDataClient)(nil).ListBackendDisruptionHistogramForJob), arg0, arg1, arg2, arg3, arg4)
+}
+
 // ListDisruptionHistoricalData mocks base method.
 func (m *MockCIDataClient) ListDisruptionHistoricalData(arg0 context.Context) ([]jobrunaggregatorapi.HistoricalData, error) {
 	m.ctrl.T.Helper()
@@ -204,19 +264,184 @@ func (mr *MockCIDataClientMockRecorder) ListDisruptionHistoricalData(arg0 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDisruptionHistoricalData", reflect.TypeOf((*MockCIDataClient)(nil).ListDisruptionHistoricalData), arg0)
 }
 
+// ListGateOverridesForPayloadTags mocks base method.
+func (m *MockCIDataClient) ListGateOverridesForPayloadTags(arg0 context.Context, arg1 []string) ([]jobrunaggregatorapi.GateOverrideRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGateOverridesForPayloadTags", arg0, arg1)
+	ret0, _ := ret[0].([]jobrunaggregatorapi.GateOverrideRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGateOverridesForPayloadTags indicates an expected call of ListGateOverridesForPayloadTags.
+func (mr *MockCIDataClientMockRecorder) ListGateOverridesForPayloadTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGateOverridesForPayloadTags", reflect.TypeOf((*MockCIDataClient)(nil).ListGateOverridesForPayloadTags), arg0, arg1)
+}
+
+// ListGateResultsForPayloadTags mocks base method.
+func (m *MockCIDataClient) ListGateResultsForPayloadTags(arg0 context.Context, arg1, arg2 string, arg3 []string) ([]jobrunaggregatorapi.GateResultRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGateResultsForPayloadTags", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]jobrunaggregatorapi.GateResultRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGateResultsForPayloadTags indicates an expected call of ListGateResultsForPayloadTags.
+func (mr *MockCIDataClientMockRecorder) ListGateResultsForPayloadTags(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGateResultsForPayloadTags", reflect.TypeOf((*MockCIDataClient)(nil).ListGateResultsForPayloadTags), arg0, arg1, arg2, arg3)
+}
+
+// ListJobRunCheckpoints mocks base method.
+func (m *MockCIDataClient) ListJobRunCheckpoints(arg0 context.Context, arg1 string) (map[string]jobrunaggregatorapi.JobRunCheckpointRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRunCheckpoints", arg0, arg1)
+	ret0, _ := ret[0].(map[string]jobrunaggregatorapi.JobRunCheckpointRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRunCheckpoints indicates an expected call of ListJobRunCheckpoints.
+func (mr *MockCIDataClientMockRecorder) ListJobRunCheckpoints(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRunCheckpoints", reflect.TypeOf((*MockCIDataClient)(nil).ListJobRunCheckpoints), arg0, arg1)
+}
+
+// ListJobRunDurationStatistics mocks base method.
+func (m *MockCIDataClient) ListJobRunDurationStatistics(arg0 context.Context, arg1 []string, arg2 time.Time) ([]jobrunaggregatorapi.JobRunDurationStatisticsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRunDurationStatistics", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]jobrunaggregatorapi.JobRunDurationStatisticsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRunDurationStatistics indicates an expected call of ListJobRunDurationStatistics.
+func (mr *MockCIDataClientMockRecorder) ListJobRunDurationStatistics(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRunDurationStatistics", reflect.TypeOf((*MockCIDataClient)(nil).ListJobRunDurationStatistics), arg0, arg1, arg2)
+}
+
+// ListJobRunLoadExceptions mocks base method.
+func (m *MockCIDataClient) ListJobRunLoadExceptions(arg0 context.Context) ([]jobrunaggregatorapi.JobRunLoadExceptionRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRunLoadExceptions", arg0)
+	ret0, _ := ret[0].([]jobrunaggregatorapi.JobRunLoadExceptionRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRunLoadExceptions indicates an expected call of ListJobRunLoadExceptions.
+func (mr *MockCIDataClientMockRecorder) ListJobRunLoadExceptions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRunLoadExceptions", reflect.TypeOf((*MockCIDataClient)(nil).ListJobRunLoadExceptions), arg0)
+}
+
+// ListJobRunNamesBetween mocks base method.
+func (m *MockCIDataClient) ListJobRunNamesBetween(arg0 context.Context, arg1 string, arg2, arg3 time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRunNamesBetween", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRunNamesBetween indicates an expected call of ListJobRunNamesBetween.
+func (mr *MockCIDataClientMockRecorder) ListJobRunNamesBetween(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRunNamesBetween", reflect.TypeOf((*MockCIDataClient)(nil).ListJobRunNamesBetween), arg0, arg1, arg2, arg3)
+}
+
+// ListJobRunNamesInRange mocks base method.
+func (m *MockCIDataClient) ListJobRunNamesInRange(arg0 context.Context, arg1, arg2, arg3 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRunNamesInRange", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRunNamesInRange indicates an expected call of ListJobRunNamesInRange.
+func (mr *MockCIDataClientMockRecorder) ListJobRunNamesInRange(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRunNamesInRange", reflect.TypeOf((*MockCIDataClient)(nil).ListJobRunNamesInRange), arg0, arg1, arg2, arg3)
+}
+
+// ListJobRunSuccessStatistics mocks base method.
+func (m *MockCIDataClient) ListJobRunSuccessStatistics(arg0 context.Context, arg1 []string, arg2 time.Time) ([]jobrunaggregatorapi.JobRunSuccessStatisticsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRunSuccessStatistics", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]jobrunaggregatorapi.JobRunSuccessStatisticsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRunSuccessStatistics indicates an expected call of ListJobRunSuccessStatistics.
+func (mr *MockCIDataClientMockRecorder) ListJobRunSuccessStatistics(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRunSuccessStatistics", reflect.TypeOf((*MockCIDataClient)(nil).ListJobRunSuccessStatistics), arg0, arg1, arg2)
+}
+
+// ListJobsWithoutSuccessfulRunsSince mocks base method.
+func (m *MockCIDataClient) ListJobsWithoutSuccessfulRunsSince(arg0 context.Context, arg1 time.Time) (sets.Set[string], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobsWithoutSuccessfulRunsSince", arg0, arg1)
+	ret0, _ := ret[0].(sets.Set[string])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobsWithoutSuccessfulRunsSince indicates an expected call of ListJobsWithoutSuccessfulRunsSince.
+func (mr *MockCIDataClientMockRecorder) ListJobsWithoutSuccessfulRunsSince(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobsWithoutSuccessfulRunsSince", reflect.TypeOf((*MockCIDataClient)(nil).ListJobsWithoutSuccessfulRunsSince), arg0, arg1)
+}
+
+// ListLocatedJobRuns mocks base method.
+func (m *MockCIDataClient) ListLocatedJobRuns(arg0 context.Context, arg1, arg2 string) ([]jobrunaggregatorapi.LocatedJobRunRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLocatedJobRuns", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]jobrunaggregatorapi.LocatedJobRunRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLocatedJobRuns indicates an expected call of ListLocatedJobRuns.
+func (mr *MockCIDataClientMockRecorder) ListLocatedJobRuns(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLocatedJobRuns", reflect.TypeOf((*MockCIDataClient)(nil).ListLocatedJobRuns), arg0, arg1, arg2)
+}
+
 // ListProwJobRunsSince mocks base method.
-func (m *MockCIDataClient) ListProwJobRunsSince(arg0 context.Context, arg1 *time.Time) ([]*jobrunaggregatorapi.TestPlatformProwJobRow, error) {
+func (m *MockCIDataClient) ListProwJobRunsSince(arg0 context.Context, arg1 *time.Time, arg2 map[string]jobrunaggregatorapi.JobRunCheckpointRow) ([]*jobrunaggregatorapi.TestPlatformProwJobRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListProwJobRunsSince", arg0, arg1)
+	ret := m.ctrl.Call(m, "ListProwJobRunsSince", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]*jobrunaggregatorapi.TestPlatformProwJobRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListProwJobRunsSince indicates an expected call of ListProwJobRunsSince.
-func (mr *MockCIDataClientMockRecorder) ListProwJobRunsSince(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockCIDataClientMockRecorder) ListProwJobRunsSince(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProwJobRunsSince", reflect.TypeOf((*MockCIDataClient)(nil).ListProwJobRunsSince), arg0, arg1, arg2)
+}
+
+// ListReleaseJobRunsForReleaseTags mocks base method.
+func (m *MockCIDataClient) ListReleaseJobRunsForReleaseTags(arg0 context.Context, arg1 []string) ([]jobrunaggregatorapi.ReleaseJobRunRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReleaseJobRunsForReleaseTags", arg0, arg1)
+	ret0, _ := ret[0].([]jobrunaggregatorapi.ReleaseJobRunRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReleaseJobRunsForReleaseTags indicates an expected call of ListReleaseJobRunsForReleaseTags.
+func (mr *MockCIDataClientMockRecorder) ListReleaseJobRunsForReleaseTags(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProwJobRunsSince", reflect.TypeOf((*MockCIDataClient)(nil).ListProwJobRunsSince), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReleaseJobRunsForReleaseTags", reflect.TypeOf((*MockCIDataClient)(nil).ListReleaseJobRunsForReleaseTags), arg0, arg1)
 }
 
 // ListReleaseTags mocks base method.
@@ -234,6 +459,36 @@ func (mr *MockCIDataClientMockRecorder) ListReleaseTags(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReleaseTags", reflect.TypeOf((*MockCIDataClient)(nil).ListReleaseTags), arg0)
 }
 
+// ListReleaseTagsForStream mocks base method.
+func (m *MockCIDataClient) ListReleaseTagsForStream(arg0 context.Context, arg1, arg2, arg3 string, arg4 time.Time) ([]jobrunaggregatorapi.ReleaseTagRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReleaseTagsForStream", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]jobrunaggregatorapi.ReleaseTagRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReleaseTagsForStream indicates an expected call of ListReleaseTagsForStream.
+func (mr *MockCIDataClientMockRecorder) ListReleaseTagsForStream(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReleaseTagsForStream", reflect.TypeOf((*MockCIDataClient)(nil).ListReleaseTagsForStream), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ListReleaseMilestoneTags mocks base method.
+func (m *MockCIDataClient) ListReleaseMilestoneTags(arg0 context.Context, arg1 []string) ([]jobrunaggregatorapi.ReleaseTagRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReleaseMilestoneTags", arg0, arg1)
+	ret0, _ := ret[0].([]jobrunaggregatorapi.ReleaseTagRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReleaseMilestoneTags indicates an expected call of ListReleaseMilestoneTags.
+func (mr *MockCIDataClientMockRecorder) ListReleaseMilestoneTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReleaseMilestoneTags", reflect.TypeOf((*MockCIDataClient)(nil).ListReleaseMilestoneTags), arg0, arg1)
+}
+
 // ListReleases mocks base method.
 func (m *MockCIDataClient) ListReleases(arg0 context.Context) ([]jobrunaggregatorapi.ReleaseRow, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,82 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func TestProwJobRunCheckpoints(t *testing.T) {
+	completedBefore := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	checkpoints := map[string]jobrunaggregatorapi.JobRunCheckpointRow{
+		"job-b": {Loader: "alert", JobName: "job-b", JobRunName: "2000", CompletedBefore: completedBefore, CommittedTime: completedBefore.Add(time.Hour)},
+		"job-a": {Loader: "alert", JobName: "job-a", JobRunName: "1000", CompletedBefore: completedBefore.Add(-time.Hour), CommittedTime: completedBefore},
+	}
+	assert.Equal(t, []prowJobRunCheckpoint{
+		{JobName: "job-a", JobRunName: "1000", CompletedBefore: completedBefore.Add(-time.Hour)},
+		{JobName: "job-b", JobRunName: "2000", CompletedBefore: completedBefore},
+	}, prowJobRunCheckpoints(checkpoints))
+
+	// loaders without checkpoints query with an empty array
+	assert.Equal(t, []prowJobRunCheckpoint{}, prowJobRunCheckpoints(nil))
+}
+
+func TestDeleteJobRunRowsScript(t *testing.T) {
+	// a failure to delete from the second table, like rows in the streaming buffer, rolls back the first deletion
+	assert.Equal(t, `BEGIN
+  BEGIN TRANSACTION;
+  DELETE FROM DATA_SET_LOCATION.Alerts WHERE JobRunName IN UNNEST(@JobRunNames);
+  DELETE FROM DATA_SET_LOCATION.JunitArtifactStats WHERE JobRunName IN UNNEST(@JobRunNames);
+  COMMIT TRANSACTION;
+EXCEPTION WHEN ERROR THEN
+  ROLLBACK TRANSACTION;
+  RAISE USING MESSAGE = @@error.message;
+END;
+`, deleteJobRunRowsScript([]string{jobrunaggregatorapi.AlertsTableName, jobrunaggregatorapi.JunitArtifactStatsTableName}))
+}
+
+func TestListJobRunDurationStatistics(t *testing.T) {
+	// the durations come back as INTEGER columns, like TIMESTAMP_DIFF returns them
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/queries") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+  "kind": "bigquery#queryResponse",
+  "jobReference": {"projectId": "test-project", "jobId": "job", "location": "US"},
+  "jobComplete": true,
+  "totalRows": "1",
+  "schema": {"fields": [
+    {"name": "JobName", "type": "STRING"},
+    {"name": "P95DurationSeconds", "type": "INTEGER"},
+    {"name": "MaxDurationSeconds", "type": "INTEGER"}
+  ]},
+  "rows": [{"f": [{"v": "periodic-4.16-aws"}, {"v": "3600"}, {"v": "7200"}]}]
+}`))
+	}))
+	defer server.Close()
+
+	ctx := context.TODO()
+	client, err := bigquery.NewClient(ctx, "test-project", option.WithEndpoint(server.URL), option.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciDataClient := NewCIDataClient(BigQueryDataCoordinates{ProjectID: "test-project", DataSetID: "ci_data"}, client)
+
+	actual, err := ciDataClient.ListJobRunDurationStatistics(ctx, []string{"periodic-4.16-aws"}, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, []jobrunaggregatorapi.JobRunDurationStatisticsRow{
+		{JobName: "periodic-4.16-aws", P95DurationSeconds: 3600, MaxDurationSeconds: 7200},
+	}, actual)
+}
@@ -0,0 +1,90 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// payloadTagCompletionTimeout bounds the BigQuery query run while the shell waits for completions
+	payloadTagCompletionTimeout = 10 * time.Second
+	// maxPayloadTagCompletions keeps the most recent payload tags, older ones are rarely analyzed again
+	maxPayloadTagCompletions = 50
+)
+
+// CompletionFunc completes the value of a flag.
+type CompletionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// CompleteValues completes the values starting with what has been typed so far.
+func CompleteValues(values sets.Set[string]) CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterCompletions(sets.List(values), "", toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// CompleteCommaSeparatedValues completes the last value of a comma-separated list, without repeating the values
+// already in the list.
+func CompleteCommaSeparatedValues(values sets.Set[string]) CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		typed := strings.Split(toComplete, ",")
+		remaining := values.Clone().Delete(typed[:len(typed)-1]...)
+		prefix := strings.Join(typed[:len(typed)-1], ",")
+		if len(prefix) > 0 {
+			prefix += ","
+		}
+		return filterCompletions(sets.List(remaining), prefix, typed[len(typed)-1]), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+func filterCompletions(values []string, prefix, toComplete string) []string {
+	completions := []string{}
+	for _, value := range values {
+		if strings.HasPrefix(value, toComplete) {
+			completions = append(completions, prefix+value)
+		}
+	}
+	return completions
+}
+
+// CompletePayloadTags completes the payload tags known in BigQuery, newest first.  The flags of the command are parsed
+// by the time completions are requested, so the credentials and the dataset are the ones given on the command line.
+// Only a service account can be used: the OAuth flow would prompt in the middle of the completion.
+func CompletePayloadTags(authentication *GoogleAuthenticationFlags, dataCoordinates *BigQueryDataCoordinates) CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(authentication.GoogleServiceAccountCredentialFile) == 0 {
+			cobra.CompDebugln("payload tags are only completed with --google-service-account-credential-file", false)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), payloadTagCompletionTimeout)
+		defer cancel()
+
+		bigQueryClient, err := authentication.NewBigQueryClient(ctx, dataCoordinates.ProjectID)
+		if err != nil {
+			cobra.CompDebugln(err.Error(), false)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		defer bigQueryClient.Close()
+		releaseTags, err := NewCIDataClient(*dataCoordinates, bigQueryClient).ListReleaseTags(ctx)
+		if err != nil {
+			cobra.CompDebugln(err.Error(), false)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return recentPayloadTags(sets.List(releaseTags), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// recentPayloadTags keeps the newest matching tags, payload tags end with their creation time so they sort by age
+// within a stream.
+func recentPayloadTags(releaseTags []string, toComplete string) []string {
+	completions := filterCompletions(releaseTags, "", toComplete)
+	sort.Sort(sort.Reverse(sort.StringSlice(completions)))
+	if len(completions) > maxPayloadTagCompletions {
+		completions = completions[:maxPayloadTagCompletions]
+	}
+	return completions
+}
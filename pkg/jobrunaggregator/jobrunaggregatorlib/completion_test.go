@@ -0,0 +1,52 @@
+package jobrunaggregatorlib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestCompleteCommaSeparatedValues(t *testing.T) {
+	values := sets.New[string]("install", "overall", "upgrade")
+
+	tests := []struct {
+		toComplete string
+		expected   []string
+	}{
+		{toComplete: "", expected: []string{"install", "overall", "upgrade"}},
+		{toComplete: "up", expected: []string{"upgrade"}},
+		{toComplete: "install,", expected: []string{"install,overall", "install,upgrade"}},
+		{toComplete: "install,o", expected: []string{"install,overall"}},
+		{toComplete: "install,overall,upgrade,", expected: []string{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.toComplete, func(t *testing.T) {
+			actual, directive := CompleteCommaSeparatedValues(values)(&cobra.Command{}, nil, tc.toComplete)
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, cobra.ShellCompDirectiveNoFileComp|cobra.ShellCompDirectiveNoSpace, directive)
+		})
+	}
+}
+
+func TestRecentPayloadTags(t *testing.T) {
+	releaseTags := []string{
+		"4.15.0-0.nightly-2023-10-01-000000",
+		"4.15.0-0.nightly-2023-10-02-000000",
+		"4.15.0-0.ci-2023-10-02-000000",
+		"4.14.0-0.nightly-2023-10-02-000000",
+	}
+	assert.Equal(t,
+		[]string{"4.15.0-0.nightly-2023-10-02-000000", "4.15.0-0.nightly-2023-10-01-000000"},
+		recentPayloadTags(releaseTags, "4.15.0-0.nightly"))
+
+	many := []string{}
+	for i := 0; i < 2*maxPayloadTagCompletions; i++ {
+		many = append(many, fmt.Sprintf("4.15.0-0.nightly-%04d", i))
+	}
+	recent := recentPayloadTags(many, "")
+	assert.Len(t, recent, maxPayloadTagCompletions)
+	assert.Equal(t, fmt.Sprintf("4.15.0-0.nightly-%04d", 2*maxPayloadTagCompletions-1), recent[0])
+}
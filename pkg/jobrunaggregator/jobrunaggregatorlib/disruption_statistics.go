@@ -0,0 +1,97 @@
+package jobrunaggregatorlib
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// disruptionStatisticsWindow is the week of whole days from 10 days ago that the disruption of job runs is compared
+// to, which leaves out the latest days whose job runs may not be loaded yet.
+func disruptionStatisticsWindow(now time.Time) (time.Time, time.Time) {
+	day := 24 * time.Hour
+	return now.UTC().Add(-10 * day).Truncate(day), now.UTC().Add(-3 * day).Truncate(day)
+}
+
+type disruptionBucket struct {
+	seconds int
+	jobRuns int
+}
+
+// NewBackendDisruptionStatistics computes the statistics of every backend of the histogram like the BigQuery
+// aggregations do: the mean and the sample standard deviation of the disruption, and its percentiles interpolated
+// like PERCENTILE_CONT.
+func NewBackendDisruptionStatistics(histogram []jobrunaggregatorapi.BackendDisruptionHistogramRow) []jobrunaggregatorapi.BackendDisruptionStatisticsRow {
+	// the days and the master nodes are summed up
+	jobRunsByBackend := map[string]map[int]int{}
+	for _, row := range histogram {
+		if row.JobRuns <= 0 {
+			continue
+		}
+		if _, ok := jobRunsByBackend[row.BackendName]; !ok {
+			jobRunsByBackend[row.BackendName] = map[int]int{}
+		}
+		jobRunsByBackend[row.BackendName][row.DisruptionSeconds] += row.JobRuns
+	}
+
+	rows := []jobrunaggregatorapi.BackendDisruptionStatisticsRow{}
+	for backendName, jobRunsBySeconds := range jobRunsByBackend {
+		buckets := []disruptionBucket{}
+		for seconds, jobRuns := range jobRunsBySeconds {
+			buckets = append(buckets, disruptionBucket{seconds: seconds, jobRuns: jobRuns})
+		}
+		sort.Slice(buckets, func(i, j int) bool { return buckets[i].seconds < buckets[j].seconds })
+		rows = append(rows, newBackendDisruptionStatisticsRow(backendName, buckets))
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].BackendName < rows[j].BackendName })
+	return rows
+}
+
+// newBackendDisruptionStatisticsRow takes the buckets sorted by seconds
+func newBackendDisruptionStatisticsRow(backendName string, buckets []disruptionBucket) jobrunaggregatorapi.BackendDisruptionStatisticsRow {
+	row := jobrunaggregatorapi.BackendDisruptionStatisticsRow{BackendName: backendName}
+
+	jobRuns, sum := 0, 0.0
+	for _, bucket := range buckets {
+		jobRuns += bucket.jobRuns
+		sum += float64(bucket.seconds * bucket.jobRuns)
+	}
+	row.Mean = sum / float64(jobRuns)
+	// STDDEV is NULL for a single job run, which reads as 0
+	if jobRuns > 1 {
+		squares := 0.0
+		for _, bucket := range buckets {
+			squares += float64(bucket.jobRuns) * math.Pow(float64(bucket.seconds)-row.Mean, 2)
+		}
+		row.StandardDeviation = math.Sqrt(squares / float64(jobRuns-1))
+	}
+
+	percentiles := reflect.ValueOf(&row).Elem()
+	for percentile := 1; percentile < 100; percentile++ {
+		percentiles.FieldByName(fmt.Sprintf("P%d", percentile)).SetFloat(interpolatePercentile(buckets, jobRuns, float64(percentile)/100))
+	}
+	return row
+}
+
+// interpolatePercentile interpolates the percentile of the seconds of the jobRuns of the buckets sorted by seconds like
+// PERCENTILE_CONT does.
+func interpolatePercentile(buckets []disruptionBucket, jobRuns int, percentile float64) float64 {
+	// secondsAt is the disruption of the job run at index when they are sorted by disruption
+	secondsAt := func(index int) float64 {
+		for _, bucket := range buckets {
+			if index < bucket.jobRuns {
+				return float64(bucket.seconds)
+			}
+			index -= bucket.jobRuns
+		}
+		return float64(buckets[len(buckets)-1].seconds)
+	}
+	position := percentile * float64(jobRuns-1)
+	lower := math.Floor(position)
+	lowerSeconds, upperSeconds := secondsAt(int(lower)), secondsAt(int(math.Ceil(position)))
+	return lowerSeconds + (position-lower)*(upperSeconds-lowerSeconds)
+}
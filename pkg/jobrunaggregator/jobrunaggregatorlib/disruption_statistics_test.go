@@ -0,0 +1,69 @@
+package jobrunaggregatorlib
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func TestDisruptionStatisticsWindow(t *testing.T) {
+	start, end := disruptionStatisticsWindow(time.Date(2024, 3, 15, 13, 30, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC), end)
+}
+
+// percentileCont is PERCENTILE_CONT over the disruption of every job run, which the histogram must match
+func percentileCont(seconds []int, percentile int) float64 {
+	sorted := append([]int{}, seconds...)
+	sort.Ints(sorted)
+	position := float64(percentile) / 100 * float64(len(sorted)-1)
+	lower, upper := sorted[int(math.Floor(position))], sorted[int(math.Ceil(position))]
+	return float64(lower) + (position-math.Floor(position))*float64(upper-lower)
+}
+
+func TestNewBackendDisruptionStatistics(t *testing.T) {
+	day := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	histogram := []jobrunaggregatorapi.BackendDisruptionHistogramRow{
+		{BackendName: "kube-api-new-connections", JobRunStartDay: day, DisruptionSeconds: 0, JobRuns: 5},
+		{BackendName: "kube-api-new-connections", JobRunStartDay: day, DisruptionSeconds: 3, JobRuns: 2},
+		{BackendName: "kube-api-new-connections", JobRunStartDay: day.AddDate(0, 0, 1), DisruptionSeconds: 0, JobRuns: 2},
+		{BackendName: "kube-api-new-connections", JobRunStartDay: day.AddDate(0, 0, 1), DisruptionSeconds: 12, JobRuns: 1},
+		{BackendName: "ingress-to-console-new-connections", JobRunStartDay: day, DisruptionSeconds: 7, JobRuns: 1},
+	}
+	// the job runs the histogram counts
+	seconds := []int{0, 0, 0, 0, 0, 3, 3, 0, 0, 12}
+
+	rows := NewBackendDisruptionStatistics(histogram)
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 backends, got %d", len(rows))
+	}
+
+	single := rows[0]
+	assert.Equal(t, "ingress-to-console-new-connections", single.BackendName)
+	assert.Equal(t, 7.0, single.Mean)
+	assert.Equal(t, 0.0, single.StandardDeviation)
+	assert.Equal(t, 7.0, single.P1)
+	assert.Equal(t, 7.0, single.P99)
+
+	row := rows[1]
+	assert.Equal(t, "kube-api-new-connections", row.BackendName)
+	assert.InDelta(t, 1.8, row.Mean, 1e-9)
+	// sqrt((7 * 1.8^2 + 2 * 1.2^2 + 10.2^2) / 9)
+	assert.InDelta(t, 3.79473, row.StandardDeviation, 1e-5)
+	for percentile, actual := range map[int]float64{
+		1: row.P1, 50: row.P50, 75: row.P75, 80: row.P80, 85: row.P85, 90: row.P90, 95: row.P95, 99: row.P99,
+	} {
+		assert.InDelta(t, percentileCont(seconds, percentile), actual, 1e-9, "P%d", percentile)
+	}
+	assert.InDelta(t, 7.95, row.P95, 1e-9)
+}
+
+func TestNewBackendDisruptionStatisticsEmpty(t *testing.T) {
+	assert.Empty(t, NewBackendDisruptionStatistics(nil))
+	assert.Empty(t, NewBackendDisruptionStatistics([]jobrunaggregatorapi.BackendDisruptionHistogramRow{{BackendName: "kube-api-new-connections"}}))
+}
@@ -0,0 +1,107 @@
+package jobrunaggregatorlib
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var knownDisruptionConnectionTypes = sets.New[string]("New", "Reused")
+
+// requiredBackendDisruptionFields are the fields of every backend the loader needs.  openshift-tests writes more, like
+// BackendName or Protocol, those are not validated.
+var requiredBackendDisruptionFields = []string{"Name", "ConnectionType", "DisruptedDuration"}
+
+// backendDisruptionFields holds the raw fields of a backend-disruption file, to tell missing fields from zero values.
+type backendDisruptionFields struct {
+	BackendDisruptions map[string]map[string]json.RawMessage
+}
+
+// DisruptionProblem is something wrong with the backend-disruption data of a job run.
+type DisruptionProblem struct {
+	// File is empty for problems spanning all files, like a missing backend.
+	File    string
+	Backend string
+	Problem string
+}
+
+func (p DisruptionProblem) String() string {
+	switch {
+	case len(p.File) == 0:
+		return fmt.Sprintf("backend %s: %s", p.Backend, p.Problem)
+	case len(p.Backend) == 0:
+		return fmt.Sprintf("%s: %s", p.File, p.Problem)
+	default:
+		return fmt.Sprintf("%s: backend %s: %s", p.File, p.Backend, p.Problem)
+	}
+}
+
+// ValidateBackendDisruptionData checks the backend-disruption files of a job run, keyed by file name, for problems
+// that GetServerAvailabilityResultsFromDirectData would otherwise drop or upload as-is: files missing required fields
+// or holding values of the wrong type, negative or absurdly long durations, and required backends that are not reported by any file.
+func ValidateBackendDisruptionData(backendDisruptionData map[string]string, requiredBackends sets.Set[string], maxDisruption time.Duration) []DisruptionProblem {
+	problems := []DisruptionProblem{}
+	seenBackends := sets.Set[string]{}
+
+	fileNames := make([]string, 0, len(backendDisruptionData))
+	for fileName := range backendDisruptionData {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	for _, fileName := range fileNames {
+		disruptionJSON := backendDisruptionData[fileName]
+		if len(disruptionJSON) == 0 {
+			problems = append(problems, DisruptionProblem{File: fileName, Problem: "file is empty"})
+			continue
+		}
+		allDisruptions := &BackendDisruptionList{}
+		if err := json.Unmarshal([]byte(disruptionJSON), allDisruptions); err != nil {
+			problems = append(problems, DisruptionProblem{File: fileName, Problem: fmt.Sprintf("does not match the BackendDisruptionList schema: %v", err)})
+			continue
+		}
+		fields := &backendDisruptionFields{}
+		if err := json.Unmarshal([]byte(disruptionJSON), fields); err != nil {
+			problems = append(problems, DisruptionProblem{File: fileName, Problem: fmt.Sprintf("does not match the BackendDisruptionList schema: %v", err)})
+			continue
+		}
+		if len(allDisruptions.BackendDisruptions) == 0 {
+			problems = append(problems, DisruptionProblem{File: fileName, Problem: "contains no backend disruptions"})
+			continue
+		}
+
+		for _, key := range sets.List(sets.KeySet(allDisruptions.BackendDisruptions)) {
+			disruption := allDisruptions.BackendDisruptions[key]
+			if disruption == nil {
+				problems = append(problems, DisruptionProblem{File: fileName, Backend: key, Problem: "has no data"})
+				continue
+			}
+			for _, field := range requiredBackendDisruptionFields {
+				if _, ok := fields.BackendDisruptions[key][field]; !ok {
+					problems = append(problems, DisruptionProblem{File: fileName, Backend: key, Problem: fmt.Sprintf("is missing the required field %s", field)})
+				}
+			}
+			seenBackends.Insert(disruption.Name)
+			if disruption.Name != key {
+				problems = append(problems, DisruptionProblem{File: fileName, Backend: key, Problem: fmt.Sprintf("is keyed differently than its name %q", disruption.Name)})
+			}
+			if !knownDisruptionConnectionTypes.Has(disruption.ConnectionType) {
+				problems = append(problems, DisruptionProblem{File: fileName, Backend: key, Problem: fmt.Sprintf("unknown connection type %q, expected one of %v", disruption.ConnectionType, sets.List(knownDisruptionConnectionTypes))})
+			}
+			switch duration := disruption.DisruptedDuration.Duration; {
+			case duration < 0:
+				problems = append(problems, DisruptionProblem{File: fileName, Backend: key, Problem: fmt.Sprintf("negative disruption of %s", duration)})
+			case maxDisruption > 0 && duration > maxDisruption:
+				problems = append(problems, DisruptionProblem{File: fileName, Backend: key, Problem: fmt.Sprintf("disruption of %s is longer than the maximum of %s", duration, maxDisruption)})
+			}
+		}
+	}
+
+	for _, backend := range sets.List(requiredBackends.Difference(seenBackends)) {
+		problems = append(problems, DisruptionProblem{Backend: backend, Problem: "required backend is missing"})
+	}
+	return problems
+}
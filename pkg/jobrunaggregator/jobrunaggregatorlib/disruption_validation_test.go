@@ -0,0 +1,80 @@
+package jobrunaggregatorlib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestValidateBackendDisruptionData(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     map[string]string
+		required sets.Set[string]
+		expected []string
+	}{
+		{
+			name:     "valid",
+			data:     map[string]string{"backend-disruption_upgrade.json": `{"BackendDisruptions":{"kube-api-new-connections":{"Name":"kube-api-new-connections","ConnectionType":"New","DisruptedDuration":"3s"}}}`},
+			required: sets.New[string]("kube-api-new-connections"),
+			expected: []string{},
+		},
+		{
+			name: "schema problems",
+			data: map[string]string{
+				"backend-disruption_a.json": `{"BackendDisruptions":{"kube-api-new-connections":{"Name":"kube-api","ConnectionType":"Fresh","DisruptedDuration":"3s"}}}`,
+				"backend-disruption_b.json": `{"Disruptions":{}}`,
+				"backend-disruption_c.json": ``,
+				"backend-disruption_d.json": `{"BackendDisruptions":{"kube-api-new-connections":{"Name":"kube-api-new-connections","DisruptedDuration":3}}}`,
+			},
+			expected: []string{
+				`backend-disruption_a.json: backend kube-api-new-connections: is keyed differently than its name "kube-api"`,
+				`backend-disruption_a.json: backend kube-api-new-connections: unknown connection type "Fresh", expected one of [New Reused]`,
+				`backend-disruption_b.json: contains no backend disruptions`,
+				`backend-disruption_c.json: file is empty`,
+				`backend-disruption_d.json: does not match the BackendDisruptionList schema: json: cannot unmarshal number into Go value of type string`,
+			},
+		},
+		{
+			name: "missing required fields",
+			data: map[string]string{
+				"backend-disruption_upgrade.json": `{"BackendDisruptions":{"kube-api-new-connections":{"Name":"kube-api-new-connections","DisruptedDuration":"3s"}}}`,
+			},
+			expected: []string{
+				`backend-disruption_upgrade.json: backend kube-api-new-connections: is missing the required field ConnectionType`,
+				`backend-disruption_upgrade.json: backend kube-api-new-connections: unknown connection type "", expected one of [New Reused]`,
+			},
+		},
+		{
+			name: "fields openshift-tests adds are allowed",
+			data: map[string]string{
+				"backend-disruption_upgrade.json": `{"BackendDisruptions":{"kube-api-new-connections":{"Name":"kube-api-new-connections","BackendName":"kube-api","ConnectionType":"New","Protocol":"http1","TargetAPI":"kube-api","LoadBalancerType":"external","DisruptedDuration":"3s"}}}`,
+			},
+			expected: []string{},
+		},
+		{
+			name: "suspicious values and missing backends",
+			data: map[string]string{
+				"backend-disruption_upgrade.json": `{"BackendDisruptions":{"kube-api-new-connections":{"Name":"kube-api-new-connections","ConnectionType":"New","DisruptedDuration":"-3s"},"oauth-api-new-connections":{"Name":"oauth-api-new-connections","ConnectionType":"New","DisruptedDuration":"2h"}}}`,
+			},
+			required: sets.New[string]("kube-api-new-connections", "openshift-api-new-connections"),
+			expected: []string{
+				"backend-disruption_upgrade.json: backend kube-api-new-connections: negative disruption of -3s",
+				"backend-disruption_upgrade.json: backend oauth-api-new-connections: disruption of 2h0m0s is longer than the maximum of 1h0m0s",
+				"backend openshift-api-new-connections: required backend is missing",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := []string{}
+			for _, problem := range ValidateBackendDisruptionData(tc.data, tc.required, time.Hour) {
+				actual = append(actual, problem.String())
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// EvidenceBundlePropertyName is the name of the junit test case property holding the location of the evidence bundle.
+const EvidenceBundlePropertyName = "evidence-bundle"
+
+// ReportOnlyVerdictPropertyName is the name of the junit test case property holding the verdict a checker in
+// report-only mode reached, one of the GateVerdict values.  Such test cases always pass.
+const ReportOnlyVerdictPropertyName = "report-only-verdict"
+
+// buildLogExcerptLines is how much of the end of the build log is kept, it is where the failure usually shows
+const buildLogExcerptLines = 100
+
+var unsafeObjectNameRegex = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// EvidenceBundle gathers what a person triaging a failed gate looks at first, for every job run that failed the test.
+type EvidenceBundle struct {
+	TestName      string
+	TestSuiteName string
+	Summary       string
+	CollectedTime time.Time
+	JobRuns       []EvidenceJobRun
+}
+
+type EvidenceJobRun struct {
+	JobName        string
+	JobRunID       string
+	HumanURL       string
+	GCSArtifactURL string
+
+	// ProwJob is nil when the prowjob couldn't be read
+	ProwJob *EvidenceProwJob `json:",omitempty"`
+	// JunitFailures are the failures of the test in the job run
+	JunitFailures []EvidenceJunitFailure `json:",omitempty"`
+	// BuildLogExcerpt is the end of the build log of the job run
+	BuildLogExcerpt string `json:",omitempty"`
+	// Errors lists what couldn't be collected, a partial bundle is better than none
+	Errors []string `json:",omitempty"`
+}
+
+type EvidenceProwJob struct {
+	Name           string
+	State          string
+	Description    string
+	Cluster        string
+	StartTime      time.Time
+	CompletionTime *time.Time `json:",omitempty"`
+}
+
+type EvidenceJunitFailure struct {
+	TestSuiteName string
+	TestName      string
+	Message       string
+	Output        string
+}
+
+// NewEvidenceJobRun collects the prowjob and the build log excerpt of the job run along with the given junit failures.
+func NewEvidenceJobRun(ctx context.Context, jobRun jobrunaggregatorapi.JobRunInfo, testSuiteName string, failures []*junit.TestCase) EvidenceJobRun {
+	evidence := EvidenceJobRun{
+		JobName:        jobRun.GetJobName(),
+		JobRunID:       jobRun.GetJobRunID(),
+		HumanURL:       jobRun.GetHumanURL(),
+		GCSArtifactURL: jobRun.GetGCSArtifactURL(),
+	}
+	for _, failure := range failures {
+		junitFailure := EvidenceJunitFailure{TestSuiteName: testSuiteName, TestName: failure.Name}
+		if failure.FailureOutput != nil {
+			junitFailure.Message = failure.FailureOutput.Message
+			junitFailure.Output = failure.FailureOutput.Output
+		}
+		evidence.JunitFailures = append(evidence.JunitFailures, junitFailure)
+	}
+
+	prowJob, err := jobRun.GetProwJob(ctx)
+	if err != nil {
+		evidence.Errors = append(evidence.Errors, fmt.Sprintf("failed to read prowjob: %v", err))
+	} else {
+		evidence.ProwJob = &EvidenceProwJob{
+			Name:        prowJob.Name,
+			State:       string(prowJob.Status.State),
+			Description: prowJob.Status.Description,
+			Cluster:     prowJob.Spec.Cluster,
+			StartTime:   prowJob.Status.StartTime.Time,
+		}
+		if prowJob.Status.CompletionTime != nil {
+			evidence.ProwJob.CompletionTime = &prowJob.Status.CompletionTime.Time
+		}
+	}
+
+	// the prowjob sits at the root of the job run, next to the build log
+	buildLogPath := path.Join(path.Dir(jobRun.GetGCSProwJobPath()), "build-log.txt")
+	buildLog, err := jobRun.GetContent(ctx, buildLogPath)
+	if err != nil {
+		evidence.Errors = append(evidence.Errors, fmt.Sprintf("failed to read build log: %v", err))
+	} else {
+		evidence.BuildLogExcerpt = tailLines(string(buildLog), buildLogExcerptLines)
+	}
+	return evidence
+}
+
+func tailLines(content string, count int) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(lines) > count {
+		lines = lines[len(lines)-count:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// EvidenceUploader stores evidence bundles and returns where they can be found.
+type EvidenceUploader interface {
+	Upload(ctx context.Context, name string, bundle *EvidenceBundle) (string, error)
+}
+
+type gcsEvidenceUploader struct {
+	bucket     *storage.BucketHandle
+	bucketName string
+	prefix     string
+}
+
+// NewGCSEvidenceUploader stores the bundles as JSON objects under location, which is like gs://bucket/some/prefix.
+func NewGCSEvidenceUploader(client *storage.Client, location string) (EvidenceUploader, error) {
+	bucketName, prefix, err := ParseGCSLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	return &gcsEvidenceUploader{
+		bucket:     client.Bucket(bucketName),
+		bucketName: bucketName,
+		prefix:     prefix,
+	}, nil
+}
+
+// ParseGCSLocation splits gs://bucket/some/prefix into its bucket and its prefix.
+func ParseGCSLocation(location string) (string, string, error) {
+	trimmed := strings.TrimPrefix(location, "gs://")
+	bucketName, prefix, _ := strings.Cut(trimmed, "/")
+	if trimmed == location || len(bucketName) == 0 {
+		return "", "", fmt.Errorf("%q is not like gs://<bucket>[/<prefix>]", location)
+	}
+	return bucketName, strings.Trim(prefix, "/"), nil
+}
+
+func (u *gcsEvidenceUploader) Upload(ctx context.Context, name string, bundle *EvidenceBundle) (string, error) {
+	content, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal evidence bundle: %w", err)
+	}
+	objectName := path.Join(u.prefix, name)
+	writer := u.bucket.Object(objectName).NewWriter(ctx)
+	writer.ContentType = "application/json"
+	if _, err := writer.Write(content); err != nil {
+		_ = writer.Close()
+		return "", fmt.Errorf("failed to write evidence bundle %s: %w", objectName, err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to write evidence bundle %s: %w", objectName, err)
+	}
+	return fmt.Sprintf("gs://%s/%s", u.bucketName, objectName), nil
+}
+
+// EvidenceBundleObjectName names the bundle of a test case of the analysis of a payload, it is stable across runs
+// so that analyzing a payload again replaces the bundles.
+func EvidenceBundleObjectName(matchID, testSuiteName, testName string) string {
+	name := unsafeObjectNameRegex.ReplaceAllString(testSuiteName+"_"+testName, "-")
+	return path.Join(unsafeObjectNameRegex.ReplaceAllString(matchID, "-"), strings.Trim(name, "-")+".json")
+}
+
+// SetEvidenceBundleLocation references the bundle from the test case, in a property for tools and in the output for humans.
+func SetEvidenceBundleLocation(testCase *junit.TestCase, location string) {
+	property := getTestCaseProperty(testCase, EvidenceBundlePropertyName)
+	if property == nil {
+		property = &junit.TestSuiteProperty{Name: EvidenceBundlePropertyName}
+		testCase.Properties = append(testCase.Properties, property)
+	}
+	property.Value = location
+	if len(testCase.SystemOut) > 0 && !strings.HasSuffix(testCase.SystemOut, "\n") {
+		testCase.SystemOut += "\n"
+	}
+	testCase.SystemOut += fmt.Sprintf("evidence bundle: %s\n", location)
+}
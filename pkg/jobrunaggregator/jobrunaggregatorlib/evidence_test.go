@@ -0,0 +1,56 @@
+package jobrunaggregatorlib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+func TestParseGCSLocation(t *testing.T) {
+	tests := []struct {
+		location       string
+		expectedBucket string
+		expectedPrefix string
+		expectedErr    bool
+	}{
+		{location: "gs://test-platform-results", expectedBucket: "test-platform-results"},
+		{location: "gs://test-platform-results/evidence/", expectedBucket: "test-platform-results", expectedPrefix: "evidence"},
+		{location: "test-platform-results/evidence", expectedErr: true},
+		{location: "gs:///evidence", expectedErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.location, func(t *testing.T) {
+			bucket, prefix, err := ParseGCSLocation(tc.location)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedBucket, bucket)
+			assert.Equal(t, tc.expectedPrefix, prefix)
+		})
+	}
+}
+
+func TestEvidenceBundleObjectName(t *testing.T) {
+	assert.Equal(t,
+		"4.15.0-0.nightly-2023-10-01-000000/cluster-install_install-should-succeed-overall.json",
+		EvidenceBundleObjectName("4.15.0-0.nightly-2023-10-01-000000", "cluster install", "install should succeed: overall"))
+}
+
+func TestSetEvidenceBundleLocation(t *testing.T) {
+	testCase := &junit.TestCase{SystemOut: "passes: 1, failures: 1, skips: 0"}
+	SetEvidenceBundleLocation(testCase, "gs://bucket/old.json")
+	SetEvidenceBundleLocation(testCase, "gs://bucket/new.json")
+
+	assert.Len(t, testCase.Properties, 1)
+	assert.Equal(t, "gs://bucket/new.json", testCase.Properties[0].Value)
+	assert.Contains(t, testCase.SystemOut, "passes: 1, failures: 1, skips: 0\nevidence bundle: gs://bucket/old.json\n")
+}
+
+func TestTailLines(t *testing.T) {
+	assert.Equal(t, "b\nc", tailLines("a\nb\nc\n", 2))
+	assert.Equal(t, "a", tailLines("a\n", 2))
+}
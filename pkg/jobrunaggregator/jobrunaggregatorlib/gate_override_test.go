@@ -0,0 +1,92 @@
+package jobrunaggregatorlib
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+func TestApplyGateOverride(t *testing.T) {
+	newSuite := func() *junit.TestSuite {
+		return &junit.TestSuite{
+			Name:      "payload-cross-jobs",
+			NumTests:  3,
+			NumFailed: 2,
+			Children: []*junit.TestSuite{
+				{
+					Name:      "minimum-required-passes-checker",
+					NumTests:  3,
+					NumFailed: 2,
+					TestCases: []*junit.TestCase{
+						{Name: "install", FailureOutput: &junit.FailureOutput{Message: "required minimum successful count 3, got 1"}, SystemOut: "name: install\n"},
+						{Name: "upgrade", FailureOutput: &junit.FailureOutput{Message: "required minimum successful count 3, got 2"}},
+						{Name: "overall"},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name              string
+		override          *GateOverride
+		expectedTests     []string
+		expectedNumFailed uint
+	}{
+		{
+			name:              "no override",
+			expectedNumFailed: 2,
+		},
+		{
+			name:              "override everything",
+			override:          &GateOverride{IssuedBy: "release-architect", Reason: "known infra outage"},
+			expectedTests:     []string{"install", "upgrade"},
+			expectedNumFailed: 0,
+		},
+		{
+			name:              "override one test",
+			override:          &GateOverride{IssuedBy: "release-architect", Reason: "known infra outage", TestNames: []string{"upgrade"}},
+			expectedTests:     []string{"upgrade"},
+			expectedNumFailed: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			suite := newSuite()
+			rows := ApplyGateOverride(tc.override, "install", "4.15.0-0.nightly-2023-11-01-000000", suite)
+
+			var overridden []string
+			for _, row := range rows {
+				overridden = append(overridden, row.TestName)
+				assert.Equal(t, "failed", row.OriginalVerdict)
+				assert.Equal(t, tc.override.IssuedBy, row.IssuedBy)
+			}
+			assert.Equal(t, tc.expectedTests, overridden)
+			assert.Equal(t, tc.expectedNumFailed, suite.NumFailed)
+			assert.Equal(t, tc.expectedNumFailed, suite.Children[0].NumFailed)
+			assert.Equal(t, uint(3), suite.NumTests)
+
+			for _, testCase := range suite.Children[0].TestCases {
+				if testCase.FailureOutput == nil && testCase.Name != "overall" {
+					assert.True(t, strings.Contains(testCase.SystemOut, "issuedby: release-architect"), testCase.SystemOut)
+				}
+			}
+		})
+	}
+}
+
+func TestGetGateOverride(t *testing.T) {
+	override, err := GetGateOverride("", "")
+	assert.NoError(t, err)
+	assert.Nil(t, override)
+
+	_, err = GetGateOverride(`{"reason": "flaky infra"}`, "")
+	assert.Error(t, err)
+
+	override, err = GetGateOverride(`{"issuedBy": "someone", "reason": "flaky infra", "testNames": ["install"]}`, "")
+	assert.NoError(t, err)
+	assert.Equal(t, &GateOverride{IssuedBy: "someone", Reason: "flaky infra", TestNames: []string{"install"}}, override)
+}
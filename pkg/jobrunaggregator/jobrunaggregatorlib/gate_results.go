@@ -0,0 +1,120 @@
+package jobrunaggregatorlib
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// NewGateResultRows lists the verdict of every test case in the suite tree.  The suite names of nested suites are
+// joined with TestSuitesSeparator.
+func NewGateResultRows(analyzer, jobName, payloadTag string, suite *junit.TestSuite, now time.Time) []jobrunaggregatorapi.GateResultRow {
+	rows := []jobrunaggregatorapi.GateResultRow{}
+	if suite == nil {
+		return rows
+	}
+	addGateResultRows(analyzer, jobName, payloadTag, now, nil, suite, &rows)
+	return rows
+}
+
+func addGateResultRows(analyzer, jobName, payloadTag string, now time.Time, parents []string, suite *junit.TestSuite, rows *[]jobrunaggregatorapi.GateResultRow) {
+	suiteNames := parents
+	if len(suite.Name) > 0 {
+		suiteNames = append(append([]string{}, parents...), suite.Name)
+	}
+	for _, testCase := range suite.TestCases {
+		row := jobrunaggregatorapi.GateResultRow{
+			ResultTime:    now,
+			Analyzer:      analyzer,
+			JobName:       jobName,
+			PayloadTag:    payloadTag,
+			TestSuiteName: strings.Join(suiteNames, TestSuitesSeparator),
+			TestName:      testCase.Name,
+			Verdict:       jobrunaggregatorapi.GateVerdictPassed,
+		}
+		switch {
+		case testCase.SkipMessage != nil:
+			row.Verdict = jobrunaggregatorapi.GateVerdictSkipped
+			row.Message = testCase.SkipMessage.Message
+		case IsFailedTestCase(testCase):
+			row.Verdict = jobrunaggregatorapi.GateVerdictFailed
+			row.Message = testCase.FailureOutput.Message
+		}
+		if property := getTestCaseProperty(testCase, EvidenceBundlePropertyName); property != nil {
+			row.EvidenceBundle = property.Value
+		}
+		row.ReportOnlyVerdict = GetReportOnlyVerdict(testCase)
+		*rows = append(*rows, row)
+	}
+	for _, child := range suite.Children {
+		addGateResultRows(analyzer, jobName, payloadTag, now, suiteNames, child, rows)
+	}
+}
+
+// GateResultChange is a test case whose verdict differs between two consecutive payloads.
+type GateResultChange struct {
+	TestSuiteName  string
+	TestName       string
+	FromPayloadTag string
+	FromVerdict    string
+	ToPayloadTag   string
+	ToVerdict      string
+}
+
+// CompareGateResults walks the payloads in the given order, oldest first, and returns every change of verdict of a
+// test case from a payload to the next payload the test case was checked for.  When a payload was analyzed more
+// than once, its latest result counts.
+func CompareGateResults(rows []jobrunaggregatorapi.GateResultRow, payloadTags []string) []GateResultChange {
+	type testKey struct {
+		suite string
+		test  string
+	}
+	latest := map[testKey]map[string]jobrunaggregatorapi.GateResultRow{}
+	for _, row := range rows {
+		key := testKey{suite: row.TestSuiteName, test: row.TestName}
+		if latest[key] == nil {
+			latest[key] = map[string]jobrunaggregatorapi.GateResultRow{}
+		}
+		if previous, ok := latest[key][row.PayloadTag]; !ok || previous.ResultTime.Before(row.ResultTime) {
+			latest[key][row.PayloadTag] = row
+		}
+	}
+
+	keys := make([]testKey, 0, len(latest))
+	for key := range latest {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].suite != keys[j].suite {
+			return keys[i].suite < keys[j].suite
+		}
+		return keys[i].test < keys[j].test
+	})
+
+	changes := []GateResultChange{}
+	for _, key := range keys {
+		var previous jobrunaggregatorapi.GateResultRow
+		checked := false
+		for _, payloadTag := range payloadTags {
+			row, ok := latest[key][payloadTag]
+			if !ok {
+				continue
+			}
+			if checked && previous.Verdict != row.Verdict {
+				changes = append(changes, GateResultChange{
+					TestSuiteName:  key.suite,
+					TestName:       key.test,
+					FromPayloadTag: previous.PayloadTag,
+					FromVerdict:    previous.Verdict,
+					ToPayloadTag:   row.PayloadTag,
+					ToVerdict:      row.Verdict,
+				})
+			}
+			previous, checked = row, true
+		}
+	}
+	return changes
+}
@@ -0,0 +1,120 @@
+package jobrunaggregatorlib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+func TestNewGateResultRows(t *testing.T) {
+	now := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	suite := &junit.TestSuite{
+		Name: "payload-cross-jobs",
+		Children: []*junit.TestSuite{
+			{
+				Name: "minimum-required-passes-checker",
+				TestCases: []*junit.TestCase{
+					{
+						Name:          "install",
+						FailureOutput: &junit.FailureOutput{Message: "required minimum successful count 3, got 1"},
+						Properties:    []*junit.TestSuiteProperty{{Name: EvidenceBundlePropertyName, Value: "gs://bucket/evidence/install.json"}},
+					},
+					{Name: "upgrade", FailureOutput: &junit.FailureOutput{}},
+					{Name: "overall", SkipMessage: &junit.SkipMessage{Message: "no job runs"}},
+					{Name: "e2e", Properties: []*junit.TestSuiteProperty{{Name: ReportOnlyVerdictPropertyName, Value: jobrunaggregatorapi.GateVerdictFailed}}},
+				},
+			},
+		},
+	}
+
+	expected := []jobrunaggregatorapi.GateResultRow{
+		{
+			ResultTime:     now,
+			Analyzer:       "analyze-test-case",
+			JobName:        "install",
+			PayloadTag:     "4.15.0-0.nightly-2023-10-01-000000",
+			TestSuiteName:  "payload-cross-jobs" + TestSuitesSeparator + "minimum-required-passes-checker",
+			TestName:       "install",
+			Verdict:        jobrunaggregatorapi.GateVerdictFailed,
+			Message:        "required minimum successful count 3, got 1",
+			EvidenceBundle: "gs://bucket/evidence/install.json",
+		},
+		{
+			ResultTime:    now,
+			Analyzer:      "analyze-test-case",
+			JobName:       "install",
+			PayloadTag:    "4.15.0-0.nightly-2023-10-01-000000",
+			TestSuiteName: "payload-cross-jobs" + TestSuitesSeparator + "minimum-required-passes-checker",
+			TestName:      "upgrade",
+			Verdict:       jobrunaggregatorapi.GateVerdictPassed,
+		},
+		{
+			ResultTime:    now,
+			Analyzer:      "analyze-test-case",
+			JobName:       "install",
+			PayloadTag:    "4.15.0-0.nightly-2023-10-01-000000",
+			TestSuiteName: "payload-cross-jobs" + TestSuitesSeparator + "minimum-required-passes-checker",
+			TestName:      "overall",
+			Verdict:       jobrunaggregatorapi.GateVerdictSkipped,
+			Message:       "no job runs",
+		},
+		{
+			ResultTime:        now,
+			Analyzer:          "analyze-test-case",
+			JobName:           "install",
+			PayloadTag:        "4.15.0-0.nightly-2023-10-01-000000",
+			TestSuiteName:     "payload-cross-jobs" + TestSuitesSeparator + "minimum-required-passes-checker",
+			TestName:          "e2e",
+			Verdict:           jobrunaggregatorapi.GateVerdictPassed,
+			ReportOnlyVerdict: jobrunaggregatorapi.GateVerdictFailed,
+		},
+	}
+	assert.Equal(t, expected, NewGateResultRows("analyze-test-case", "install", "4.15.0-0.nightly-2023-10-01-000000", suite, now))
+}
+
+func TestCompareGateResults(t *testing.T) {
+	start := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	row := func(payloadTag, testName, verdict string, hours int) jobrunaggregatorapi.GateResultRow {
+		return jobrunaggregatorapi.GateResultRow{
+			ResultTime:    start.Add(time.Duration(hours) * time.Hour),
+			PayloadTag:    payloadTag,
+			TestSuiteName: "minimum-required-passes-checker",
+			TestName:      testName,
+			Verdict:       verdict,
+		}
+	}
+	rows := []jobrunaggregatorapi.GateResultRow{
+		row("p1", "install", jobrunaggregatorapi.GateVerdictPassed, 0),
+		row("p2", "install", jobrunaggregatorapi.GateVerdictFailed, 1),
+		// the payload was analyzed again, the latest result counts
+		row("p2", "install", jobrunaggregatorapi.GateVerdictPassed, 2),
+		row("p3", "install", jobrunaggregatorapi.GateVerdictFailed, 3),
+		row("p1", "upgrade", jobrunaggregatorapi.GateVerdictPassed, 0),
+		// the test wasn't checked for p2
+		row("p3", "upgrade", jobrunaggregatorapi.GateVerdictFailed, 3),
+	}
+
+	expected := []GateResultChange{
+		{
+			TestSuiteName:  "minimum-required-passes-checker",
+			TestName:       "install",
+			FromPayloadTag: "p2",
+			FromVerdict:    jobrunaggregatorapi.GateVerdictPassed,
+			ToPayloadTag:   "p3",
+			ToVerdict:      jobrunaggregatorapi.GateVerdictFailed,
+		},
+		{
+			TestSuiteName:  "minimum-required-passes-checker",
+			TestName:       "upgrade",
+			FromPayloadTag: "p1",
+			FromVerdict:    jobrunaggregatorapi.GateVerdictPassed,
+			ToPayloadTag:   "p3",
+			ToVerdict:      jobrunaggregatorapi.GateVerdictFailed,
+		},
+	}
+	assert.Equal(t, expected, CompareGateResults(rows, []string{"p1", "p2", "p3"}))
+}
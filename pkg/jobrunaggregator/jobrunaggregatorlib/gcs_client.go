@@ -2,36 +2,235 @@ package jobrunaggregatorlib
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
-	"google.golang.org/api/iterator"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
 )
 
+// CIGCSClientOptions control how a CIGCSClient lists and reads job runs.
+type CIGCSClientOptions struct {
+	// StartingJobRunID is where job runs are listed from when no job run is known to start before the search window,
+	// like for jobs missing from BigQuery.  Listing from "0", the default, scans every job run the job ever had.
+	StartingJobRunID string
+	jobrunaggregatorapi.JobRunArtifactOptions
+}
+
+var DefaultCIGCSClientOptions = CIGCSClientOptions{
+	StartingJobRunID:      "0",
+	JobRunArtifactOptions: jobrunaggregatorapi.DefaultJobRunArtifactOptions,
+}
+
+func (o CIGCSClientOptions) listingStartingJobRunID() string {
+	if len(o.StartingJobRunID) == 0 {
+		return DefaultCIGCSClientOptions.StartingJobRunID
+	}
+	return o.StartingJobRunID
+}
+
+var gcsJobListingSeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "jobrunaggregator_gcs_job_listing_seconds",
+		Help:    "Seconds spent listing the job runs of a job from GCS, including reading their prowjobs when matching them.",
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 12),
+	},
+	[]string{"job_name"},
+)
+
+func init() {
+	prometheus.MustRegister(gcsJobListingSeconds)
+}
+
+// jobRunIDFromPrefix returns the ID of the job run whose artifacts are under jobRunPrefix, from its position in the
+// logs/ or pr-logs/ layout.  Explicit prefixes in another layout always end with the job run ID.
+func jobRunIDFromPrefix(jobRunPrefix string) string {
+	if location, err := jobrunaggregatorapi.ParseJobRunGCSLocation(jobRunPrefix); err == nil && len(location.JobRunID) > 0 {
+		return location.JobRunID
+	}
+	return filepath.Base(jobRunPrefix)
+}
+
 type CIGCSClient interface {
-	ReadJobRunFromGCS(ctx context.Context, jobGCSRootLocation, jobName, jobRunID string, logger logrus.FieldLogger) (jobrunaggregatorapi.JobRunInfo, error)
+	// ForBucket returns a client reading job runs from bucketName with the same credentials, for jobs uploading to
+	// a bucket other than the one the client was created for
+	ForBucket(bucketName string) CIGCSClient
+	// ListJobRunNamesBetween returns the IDs of the job runs under gcsPrefix that started in [start, end).  Like for
+	// ReadRelatedJobRuns, only the job runs from startingJobRunID up to endingJobRunID are listed, empty IDs leave the
+	// listing unbounded.
+	ListJobRunNamesBetween(ctx context.Context, gcsPrefix, startingJobRunID, endingJobRunID string, start, end time.Time) ([]string, error)
+	ReadJobRunFromGCS(ctx context.Context, jobGCSRootLocation, jobName, jobRunID string, logger logrus.FieldLogger, opts ...ReadJobRunOption) (jobrunaggregatorapi.JobRunInfo, error)
+	// ReadJobRunsFromGCS reads the job runs of jobRunIDs with a single listing of the job and concurrent reads of their
+	// prowjobs.  Job runs that don't exist, or have no prowjob yet, are left out.  The job runs that could be read are
+	// returned, sorted by ID, along with the errors of the others.
+	ReadJobRunsFromGCS(ctx context.Context, jobGCSRootLocation, jobName string, jobRunIDs []string, logger logrus.FieldLogger) ([]jobrunaggregatorapi.JobRunInfo, error)
 	ReadRelatedJobRuns(ctx context.Context, jobName, gcsPrefix, startingJobRunID, endingJobRunID string,
 		matcherFunc ProwJobMatcherFunc) ([]jobrunaggregatorapi.JobRunInfo, error)
 }
 
+// ReadJobRunOptions tune how ReadJobRunFromGCS reads a job run
+type ReadJobRunOptions struct {
+	// SkipProwJobValidation returns the job run without reading its prowjob.json to check that it exists.  A job run
+	// that doesn't exist then fails when its prowjob or artifacts are read.
+	SkipProwJobValidation bool
+}
+
+type ReadJobRunOption func(options *ReadJobRunOptions)
+
+// WithoutProwJobValidation is for bulk consumers that only need the artifacts of job runs they know exist, or that
+// read the prowjob themselves right away, to save a read per job run.
+func WithoutProwJobValidation() ReadJobRunOption {
+	return func(options *ReadJobRunOptions) {
+		options.SkipProwJobValidation = true
+	}
+}
+
+func NewReadJobRunOptions(opts ...ReadJobRunOption) ReadJobRunOptions {
+	options := ReadJobRunOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// jobRunReadParallelism is how many prowjobs ReadJobRunsFromGCS reads at a time
+const jobRunReadParallelism = 10
+
+// jobRunListingBounds returns the sorted unique jobRunIDs, with the job run IDs to list the job from, included, and
+// up to, excluded.  "/" sorts before any digit, so the directory of the last job run is listed but no later one.
+func jobRunListingBounds(jobRunIDs []string) ([]string, string, string) {
+	sorted := sets.List(sets.New[string](jobRunIDs...))
+	return sorted, sorted[0], sorted[len(sorted)-1] + "0"
+}
+
+// readJobRunsConcurrently reads the job runs of the listed job run directories, keeping those of wanted in the order
+// of the listing.  read returns a nil job run for the job runs to leave out.
+func readJobRunsConcurrently(ctx context.Context, jobRunPrefixes []string, wanted sets.Set[string], read func(ctx context.Context, jobRunPrefix string) (jobrunaggregatorapi.JobRunInfo, error)) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	toRead := []string{}
+	for _, jobRunPrefix := range jobRunPrefixes {
+		if wanted.Has(jobRunIDFromPrefix(jobRunPrefix)) {
+			toRead = append(toRead, jobRunPrefix)
+		}
+	}
+
+	jobRuns := make([]jobrunaggregatorapi.JobRunInfo, len(toRead))
+	errs := make([]error, len(toRead))
+	indexes := make(chan int, len(toRead))
+	for i := range toRead {
+		indexes <- i
+	}
+	close(indexes)
+	wg := sync.WaitGroup{}
+	for i := 0; i < jobRunReadParallelism && i < len(toRead); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				jobRuns[i], errs[i] = read(ctx, toRead[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	ret := []jobrunaggregatorapi.JobRunInfo{}
+	for _, jobRun := range jobRuns {
+		if jobRun != nil {
+			ret = append(ret, jobRun)
+		}
+	}
+	return ret, utilerrors.NewAggregate(errs)
+}
+
 type ciGCSClient struct {
 	gcsClient     *storage.Client
 	gcsBucketName string
+	options       CIGCSClientOptions
+}
+
+func (o *ciGCSClient) ForBucket(bucketName string) CIGCSClient {
+	if len(bucketName) == 0 || bucketName == o.gcsBucketName {
+		return o
+	}
+	return &ciGCSClient{
+		gcsClient:     o.gcsClient,
+		gcsBucketName: bucketName,
+		options:       o.options,
+	}
 }
 
-func (o *ciGCSClient) ReadJobRunFromGCS(ctx context.Context, jobGCSRootLocation, jobName, jobRunID string, logger logrus.FieldLogger) (jobrunaggregatorapi.JobRunInfo, error) {
+func (o *ciGCSClient) ListJobRunNamesBetween(ctx context.Context, gcsPrefix, startingJobRunID, endingJobRunID string, start, end time.Time) ([]string, error) {
+	defer observeJobListing(filepath.Base(gcsPrefix), time.Now())
+	if len(startingJobRunID) == 0 {
+		startingJobRunID = o.options.listingStartingJobRunID()
+	}
+	query := &storage.Query{
+		Prefix:      fmt.Sprintf("%s/", gcsPrefix),
+		StartOffset: fmt.Sprintf("%s/%s", gcsPrefix, startingJobRunID),
+		// restrict the query to just one level down, the job run directories
+		Delimiter: "/",
+	}
+	if len(endingJobRunID) > 0 {
+		query.EndOffset = fmt.Sprintf("%s/%s", gcsPrefix, endingJobRunID)
+	}
+	logrus.WithFields(logrus.Fields{"prefix": query.Prefix, "start": start, "end": end}).Debug("listing job run names")
+
+	bkt := o.gcsClient.Bucket(o.gcsBucketName)
+	jobRunIDs := []string{}
+	err := jobrunaggregatorapi.ListGCSObjects(ctx, bkt, query, func(attrs *storage.ObjectAttrs) (bool, error) {
+		if len(attrs.Name) > 0 {
+			return true, nil
+		}
+
+		// started.json is uploaded when the job run starts, directories themselves have no creation time
+		startedAttrs, err := bkt.Object(fmt.Sprintf("%s%s", attrs.Prefix, "started.json")).Attrs(ctx)
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			logrus.Debugf("skipping %s without started.json", attrs.Prefix)
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to read started.json attributes of %q: %w", attrs.Prefix, err)
+		}
+		if startedAttrs.Created.Before(start) {
+			return true, nil
+		}
+		// job run IDs grow with time, so no later job run can be in the window either
+		if !startedAttrs.Created.Before(end) {
+			return false, nil
+		}
+		jobRunIDs = append(jobRunIDs, jobRunIDFromPrefix(attrs.Prefix))
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jobRunIDs, nil
+}
+
+func (o *ciGCSClient) ReadJobRunFromGCS(ctx context.Context, jobGCSRootLocation, jobName, jobRunID string, logger logrus.FieldLogger, opts ...ReadJobRunOption) (jobrunaggregatorapi.JobRunInfo, error) {
 	logger.Debugf("reading job run %s/%s", jobGCSRootLocation, jobRunID)
 
 	bkt := o.gcsClient.Bucket(o.gcsBucketName)
 	prowJobPath := fmt.Sprintf("%s/%s/prowjob.json", jobGCSRootLocation, jobRunID)
-	jobRunId := filepath.Base(filepath.Dir(prowJobPath))
+	jobRunId := jobRunIDFromPrefix(filepath.Dir(prowJobPath))
 
-	jobRun := jobrunaggregatorapi.NewGCSJobRun(bkt, jobGCSRootLocation, jobName, jobRunId, o.gcsBucketName)
+	jobRun := jobrunaggregatorapi.NewGCSJobRun(bkt, jobGCSRootLocation, jobName, jobRunId, o.gcsBucketName, o.options.JobRunArtifactOptions)
 	jobRun.SetGCSProwJobPath(prowJobPath)
+	if NewReadJobRunOptions(opts...).SkipProwJobValidation {
+		return jobRun, nil
+	}
 	_, err := jobRun.GetProwJob(ctx)
 	if err != nil {
 		logger.WithError(err).Error("failed to get prowjob")
@@ -41,9 +240,53 @@ func (o *ciGCSClient) ReadJobRunFromGCS(ctx context.Context, jobGCSRootLocation,
 	return jobRun, nil
 }
 
+func (o *ciGCSClient) ReadJobRunsFromGCS(ctx context.Context, jobGCSRootLocation, jobName string, jobRunIDs []string, logger logrus.FieldLogger) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	if len(jobRunIDs) == 0 {
+		return nil, nil
+	}
+	defer observeJobListing(jobName, time.Now())
+
+	sortedJobRunIDs, startingJobRunID, endingJobRunID := jobRunListingBounds(jobRunIDs)
+	query := &storage.Query{
+		Prefix:      fmt.Sprintf("%s/", jobGCSRootLocation),
+		StartOffset: fmt.Sprintf("%s/%s", jobGCSRootLocation, startingJobRunID),
+		EndOffset:   fmt.Sprintf("%s/%s", jobGCSRootLocation, endingJobRunID),
+		// restrict the query to just one level down, the job run directories
+		Delimiter: "/",
+	}
+	logger.WithFields(logrus.Fields{"startOffset": query.StartOffset, "endOffset": query.EndOffset}).Debugf("listing %d job runs", len(sortedJobRunIDs))
+
+	bkt := o.gcsClient.Bucket(o.gcsBucketName)
+	jobRunPrefixes := []string{}
+	err := jobrunaggregatorapi.ListGCSObjects(ctx, bkt, query, func(attrs *storage.ObjectAttrs) (bool, error) {
+		if len(attrs.Name) == 0 {
+			jobRunPrefixes = append(jobRunPrefixes, attrs.Prefix)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return readJobRunsConcurrently(ctx, jobRunPrefixes, sets.New[string](sortedJobRunIDs...), func(ctx context.Context, jobRunPrefix string) (jobrunaggregatorapi.JobRunInfo, error) {
+		jobRunID := jobRunIDFromPrefix(jobRunPrefix)
+		jobRun := jobrunaggregatorapi.NewGCSJobRun(bkt, jobGCSRootLocation, jobName, jobRunID, o.gcsBucketName, o.options.JobRunArtifactOptions)
+		jobRun.SetGCSProwJobPath(jobRunPrefix + "prowjob.json")
+		if _, err := jobRun.GetProwJob(ctx); err != nil {
+			if errors.Is(err, storage.ErrObjectNotExist) {
+				logger.Debugf("skipping %s without prowjob", jobRunPrefix)
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get prowjob for %q/%q: %w", jobName, jobRunID, err)
+		}
+		return jobRun, nil
+	})
+}
+
 func (o *ciGCSClient) ReadRelatedJobRuns(ctx context.Context,
 	jobName, gcsPrefix, startingJobRunID, endingJobRunID string,
 	matcherFunc ProwJobMatcherFunc) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	defer observeJobListing(jobName, time.Now())
 
 	logrus.Debugf("searching GCS for related job runs in %s between %s and %s", gcsPrefix, startingJobRunID, endingJobRunID)
 	query := &storage.Query{
@@ -57,9 +300,8 @@ func (o *ciGCSClient) ReadRelatedJobRuns(ctx context.Context,
 	}
 
 	if startingJobRunID == "" {
-		// For debugging, you can set this to a jobID that is not that far away from
-		// jobs related to what you are trying to aggregate.
-		query.StartOffset = fmt.Sprintf("%s/%s", gcsPrefix, "0")
+		// without a job run before the search window, every job run up to the starting job run ID is skipped
+		query.StartOffset = fmt.Sprintf("%s/%s", gcsPrefix, o.options.listingStartingJobRunID())
 	} else {
 		query.StartOffset = fmt.Sprintf("%s/%s", gcsPrefix, startingJobRunID)
 	}
@@ -70,46 +312,44 @@ func (o *ciGCSClient) ReadRelatedJobRuns(ctx context.Context,
 	// restrict the query to just one level down
 	query.Delimiter = "/"
 
-	fmt.Printf("  starting from %v, ending at %q\n", query.StartOffset, query.EndOffset)
+	logrus.WithFields(logrus.Fields{"startOffset": query.StartOffset, "endOffset": query.EndOffset}).Debug("listing job runs")
 
 	// Returns an iterator which iterates over the bucket query results.
 	// This will list all the folders under the prefix
 	bkt := o.gcsClient.Bucket(o.gcsBucketName)
-	it := bkt.Objects(ctx, query)
 
 	// Find the query results we're the most interested in. In this case, we're interested in files called prowjob.json
-	// so that we only get each jobrun once and we queue them in a channel
+	// so that we only get each jobrun once
 	relatedJobRuns := []jobrunaggregatorapi.JobRunInfo{}
-	for {
-		attrs, err := it.Next()
-		if err == iterator.Done {
-			// we're done adding values, so close the channel
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
+	err := jobrunaggregatorapi.ListGCSObjects(ctx, bkt, query, func(attrs *storage.ObjectAttrs) (bool, error) {
 		// we are only interested in directories for this pass since we know the file we want
 		if len(attrs.Name) > 0 {
-			continue
+			return true, nil
 		}
 
 		// we only need prowjob.json at this time
 		prowJobPath := fmt.Sprintf("%s%s", attrs.Prefix, "prowjob.json")
-		logrus.Debugf("found %s", attrs.Name)
-		jobRunId := filepath.Base(filepath.Dir(prowJobPath))
-		jobRun := jobrunaggregatorapi.NewGCSJobRun(bkt, gcsPrefix, jobName, jobRunId, o.gcsBucketName)
+		logrus.Debugf("found %s", attrs.Prefix)
+		jobRunId := jobRunIDFromPrefix(attrs.Prefix)
+		jobRun := jobrunaggregatorapi.NewGCSJobRun(bkt, gcsPrefix, jobName, jobRunId, o.gcsBucketName, o.options.JobRunArtifactOptions)
 		jobRun.SetGCSProwJobPath(prowJobPath)
 
 		prowJob, err := jobRun.GetProwJob(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get prowjob for %q/%q: %w", jobName, jobRunId, err)
+			return false, fmt.Errorf("failed to get prowjob for %q/%q: %w", jobName, jobRunId, err)
 		}
 
 		if matcherFunc(prowJob) {
 			relatedJobRuns = append(relatedJobRuns, jobRun)
 		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return relatedJobRuns, nil
 }
+
+func observeJobListing(jobName string, start time.Time) {
+	gcsJobListingSeconds.WithLabelValues(jobName).Observe(time.Since(start).Seconds())
+}
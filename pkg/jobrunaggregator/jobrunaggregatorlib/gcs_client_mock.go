@@ -7,6 +7,7 @@ package jobrunaggregatorlib
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	logrus "github.com/sirupsen/logrus"
@@ -37,19 +38,68 @@ func (m *MockCIGCSClient) EXPECT() *MockCIGCSClientMockRecorder {
 	return m.recorder
 }
 
+// ForBucket mocks base method.
+func (m *MockCIGCSClient) ForBucket(arg0 string) CIGCSClient {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForBucket", arg0)
+	ret0, _ := ret[0].(CIGCSClient)
+	return ret0
+}
+
+// ForBucket indicates an expected call of ForBucket.
+func (mr *MockCIGCSClientMockRecorder) ForBucket(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForBucket", reflect.TypeOf((*MockCIGCSClient)(nil).ForBucket), arg0)
+}
+
+// ListJobRunNamesBetween mocks base method.
+func (m *MockCIGCSClient) ListJobRunNamesBetween(arg0 context.Context, arg1, arg2, arg3 string, arg4, arg5 time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRunNamesBetween", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRunNamesBetween indicates an expected call of ListJobRunNamesBetween.
+func (mr *MockCIGCSClientMockRecorder) ListJobRunNamesBetween(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRunNamesBetween", reflect.TypeOf((*MockCIGCSClient)(nil).ListJobRunNamesBetween), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
 // ReadJobRunFromGCS mocks base method.
-func (m *MockCIGCSClient) ReadJobRunFromGCS(arg0 context.Context, arg1, arg2, arg3 string, arg4 logrus.FieldLogger) (jobrunaggregatorapi.JobRunInfo, error) {
+func (m *MockCIGCSClient) ReadJobRunFromGCS(arg0 context.Context, arg1, arg2, arg3 string, arg4 logrus.FieldLogger, arg5 ...ReadJobRunOption) (jobrunaggregatorapi.JobRunInfo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadJobRunFromGCS", arg0, arg1, arg2, arg3, arg4)
+	varargs := []interface{}{arg0, arg1, arg2, arg3, arg4}
+	for _, a := range arg5 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReadJobRunFromGCS", varargs...)
 	ret0, _ := ret[0].(jobrunaggregatorapi.JobRunInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ReadJobRunFromGCS indicates an expected call of ReadJobRunFromGCS.
-func (mr *MockCIGCSClientMockRecorder) ReadJobRunFromGCS(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+func (mr *MockCIGCSClientMockRecorder) ReadJobRunFromGCS(arg0, arg1, arg2, arg3, arg4 interface{}, arg5 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2, arg3, arg4}, arg5...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadJobRunFromGCS", reflect.TypeOf((*MockCIGCSClient)(nil).ReadJobRunFromGCS), varargs...)
+}
+
+// ReadJobRunsFromGCS mocks base method.
+func (m *MockCIGCSClient) ReadJobRunsFromGCS(arg0 context.Context, arg1, arg2 string, arg3 []string, arg4 logrus.FieldLogger) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadJobRunsFromGCS", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]jobrunaggregatorapi.JobRunInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadJobRunsFromGCS indicates an expected call of ReadJobRunsFromGCS.
+func (mr *MockCIGCSClientMockRecorder) ReadJobRunsFromGCS(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadJobRunFromGCS", reflect.TypeOf((*MockCIGCSClient)(nil).ReadJobRunFromGCS), arg0, arg1, arg2, arg3, arg4)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadJobRunsFromGCS", reflect.TypeOf((*MockCIGCSClient)(nil).ReadJobRunsFromGCS), arg0, arg1, arg2, arg3, arg4)
 }
 
 // ReadRelatedJobRuns mocks base method.
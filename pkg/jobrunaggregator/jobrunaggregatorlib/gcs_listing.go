@@ -0,0 +1,73 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// JobRunNamesListing is the outcome of listing the job runs of one job
+type JobRunNamesListing struct {
+	JobName   string
+	JobRunIDs []string
+	Err       error
+}
+
+// JobRunBounds finds the job runs started right before and after a window, to only list the job runs in between from
+// GCS, like the CIDataClient does from the JobRuns table
+type JobRunBounds interface {
+	GetJobRunForJobNameBeforeTime(ctx context.Context, jobName string, targetTime time.Time) (string, error)
+	GetJobRunForJobNameAfterTime(ctx context.Context, jobName string, targetTime time.Time) (string, error)
+}
+
+// ListJobRunNamesConcurrently lists the job runs started in [start, end) of every job of jobGCSPrefixes, which maps job
+// names to their GCS prefix, with at most parallelism listings at a time.  The listings are sent as they complete,
+// one per job, and the channel is closed once all jobs are listed.  When bounds is set, only the job runs between
+// those it finds around the window are listed, instead of every job run of the job.
+func ListJobRunNamesConcurrently(ctx context.Context, client CIGCSClient, bounds JobRunBounds, jobGCSPrefixes map[string]string, start, end time.Time, parallelism int) <-chan JobRunNamesListing {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	jobNames := make(chan string, len(jobGCSPrefixes))
+	for jobName := range jobGCSPrefixes {
+		jobNames <- jobName
+	}
+	close(jobNames)
+
+	listings := make(chan JobRunNamesListing, len(jobGCSPrefixes))
+	wg := sync.WaitGroup{}
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for jobName := range jobNames {
+				listing := JobRunNamesListing{JobName: jobName}
+				if err := ctx.Err(); err != nil {
+					listing.Err = err
+				} else {
+					listing.JobRunIDs, listing.Err = listJobRunNamesBetween(ctx, client, bounds, jobName, jobGCSPrefixes[jobName], start, end)
+				}
+				listings <- listing
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(listings)
+	}()
+	return listings
+}
+
+func listJobRunNamesBetween(ctx context.Context, client CIGCSClient, bounds JobRunBounds, jobName, gcsPrefix string, start, end time.Time) ([]string, error) {
+	startingJobRunID, endingJobRunID := "", ""
+	if bounds != nil {
+		var err error
+		if startingJobRunID, err = bounds.GetJobRunForJobNameBeforeTime(ctx, jobName, start); err != nil {
+			return nil, err
+		}
+		if endingJobRunID, err = bounds.GetJobRunForJobNameAfterTime(ctx, jobName, end); err != nil {
+			return nil, err
+		}
+	}
+	return client.ListJobRunNamesBetween(ctx, gcsPrefix, startingJobRunID, endingJobRunID, start, end)
+}
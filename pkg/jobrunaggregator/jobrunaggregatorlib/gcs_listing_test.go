@@ -0,0 +1,65 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListJobRunNamesConcurrently(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	start := time.Date(2023, 5, 4, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	client := NewMockCIGCSClient(mockCtrl)
+	client.EXPECT().ListJobRunNamesBetween(gomock.Any(), "logs/job-a", "", "", start, end).Return([]string{"1", "2"}, nil)
+	client.EXPECT().ListJobRunNamesBetween(gomock.Any(), "logs/job-b", "", "", start, end).Return(nil, fmt.Errorf("boom"))
+	client.EXPECT().ListJobRunNamesBetween(gomock.Any(), "logs/job-c", "", "", start, end).Return([]string{}, nil)
+
+	listings := map[string]JobRunNamesListing{}
+	for listing := range ListJobRunNamesConcurrently(context.TODO(), client, nil, map[string]string{
+		"job-a": "logs/job-a",
+		"job-b": "logs/job-b",
+		"job-c": "logs/job-c",
+	}, start, end, 2) {
+		listings[listing.JobName] = listing
+	}
+
+	assert.Len(t, listings, 3)
+	assert.Equal(t, []string{"1", "2"}, listings["job-a"].JobRunIDs)
+	assert.NoError(t, listings["job-a"].Err)
+	assert.Error(t, listings["job-b"].Err)
+	assert.Empty(t, listings["job-c"].JobRunIDs)
+}
+
+func TestListJobRunNamesConcurrentlyWithinBounds(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	start := time.Date(2023, 5, 4, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	bounds := NewMockCIDataClient(mockCtrl)
+	bounds.EXPECT().GetJobRunForJobNameBeforeTime(gomock.Any(), "job-a", start).Return("1000", nil)
+	bounds.EXPECT().GetJobRunForJobNameAfterTime(gomock.Any(), "job-a", end).Return("2000", nil)
+	bounds.EXPECT().GetJobRunForJobNameBeforeTime(gomock.Any(), "job-b", start).Return("", fmt.Errorf("boom"))
+	client := NewMockCIGCSClient(mockCtrl)
+	client.EXPECT().ListJobRunNamesBetween(gomock.Any(), "logs/job-a", "1000", "2000", start, end).Return([]string{"1500"}, nil)
+
+	listings := map[string]JobRunNamesListing{}
+	for listing := range ListJobRunNamesConcurrently(context.TODO(), client, bounds, map[string]string{
+		"job-a": "logs/job-a",
+		"job-b": "logs/job-b",
+	}, start, end, 2) {
+		listings[listing.JobName] = listing
+	}
+
+	assert.Equal(t, []string{"1500"}, listings["job-a"].JobRunIDs)
+	assert.NoError(t, listings["job-a"].Err)
+	// the job isn't listed when its bounds can't be found
+	assert.Error(t, listings["job-b"].Err)
+}
@@ -0,0 +1,128 @@
+// Package gcstesting fakes the GCS bucket of Prow in memory, so that tests read job runs through the same code as
+// the commands do.
+package gcstesting
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/storage"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// FakeBucket is an in-memory jobrunaggregatorapi.ObjectBucket.  The version of an object is the number of times it
+// was written.
+type FakeBucket struct {
+	lock     sync.Mutex
+	objects  map[string][]byte
+	versions map[string]int
+}
+
+var _ jobrunaggregatorapi.ObjectBucket = &FakeBucket{}
+
+func NewFakeBucket(objects map[string]string) *FakeBucket {
+	bucket := &FakeBucket{objects: map[string][]byte{}, versions: map[string]int{}}
+	for name, content := range objects {
+		bucket.Put(name, []byte(content))
+	}
+	return bucket
+}
+
+// NewFakeBucketFromDir fills the bucket with the files under dir, named by their path relative to dir, like
+// testdata/logs/<job>/<job run ID>/prowjob.json for the logs/<job>/<job run ID>/prowjob.json object.
+func NewFakeBucketFromDir(dir string) (*FakeBucket, error) {
+	bucket := NewFakeBucket(nil)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		bucket.Put(filepath.ToSlash(name), content)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the fake bucket from %q: %w", dir, err)
+	}
+	return bucket, nil
+}
+
+// Put writes the object, like a job run uploading its artifacts while the test runs
+func (b *FakeBucket) Put(name string, content []byte) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.objects[name] = content
+	b.versions[name]++
+}
+
+func (b *FakeBucket) ListObjects(_ context.Context, prefix string) ([]string, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	names := []string{}
+	for name := range b.objects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (b *FakeBucket) GetVersion(_ context.Context, name string) (string, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	version, ok := b.versions[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", storage.ErrObjectNotExist, name)
+	}
+	return strconv.Itoa(version), nil
+}
+
+func (b *FakeBucket) OpenObject(_ context.Context, name, version string) (io.ReadCloser, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	content, ok := b.objects[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", storage.ErrObjectNotExist, name)
+	}
+	if current := strconv.Itoa(b.versions[name]); len(version) > 0 && version != current {
+		return nil, fmt.Errorf("version %s of %s was overwritten by version %s", version, name, current)
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// jobRunIDs returns the sorted IDs of the job runs under gcsPrefix
+func (b *FakeBucket) jobRunIDs(gcsPrefix string) []string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	ids := map[string]bool{}
+	for name := range b.objects {
+		rest := strings.TrimPrefix(name, gcsPrefix+"/")
+		if rest == name || !strings.Contains(rest, "/") {
+			continue
+		}
+		ids[rest[:strings.Index(rest, "/")]] = true
+	}
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
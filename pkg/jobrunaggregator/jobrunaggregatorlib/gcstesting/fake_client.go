@@ -0,0 +1,132 @@
+package gcstesting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/sirupsen/logrus"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+)
+
+// FakeCIGCSClient lists and reads the job runs of a FakeBucket like the GCS client does with the Prow bucket
+type FakeCIGCSClient struct {
+	Bucket     *FakeBucket
+	BucketName string
+
+	// OtherBuckets are returned by ForBucket by name, buckets missing from it are empty
+	OtherBuckets map[string]*FakeBucket
+}
+
+var _ jobrunaggregatorlib.CIGCSClient = &FakeCIGCSClient{}
+
+func NewFakeCIGCSClient(bucket *FakeBucket) *FakeCIGCSClient {
+	return &FakeCIGCSClient{Bucket: bucket, BucketName: "test-platform-results"}
+}
+
+func (c *FakeCIGCSClient) ForBucket(bucketName string) jobrunaggregatorlib.CIGCSClient {
+	if len(bucketName) == 0 || bucketName == c.BucketName {
+		return c
+	}
+	bucket, ok := c.OtherBuckets[bucketName]
+	if !ok {
+		bucket = NewFakeBucket(nil)
+	}
+	return &FakeCIGCSClient{Bucket: bucket, BucketName: bucketName, OtherBuckets: c.OtherBuckets}
+}
+
+func (c *FakeCIGCSClient) newJobRun(gcsPrefix, jobName, jobRunID string) jobrunaggregatorapi.JobRunInfo {
+	jobRun := jobrunaggregatorapi.NewObjectBucketJobRun(c.Bucket, gcsPrefix, jobName, jobRunID, c.BucketName, jobrunaggregatorapi.DefaultJobRunArtifactOptions)
+	jobRun.SetGCSProwJobPath(fmt.Sprintf("%s/%s/prowjob.json", gcsPrefix, jobRunID))
+	return jobRun
+}
+
+// ListJobRunNamesBetween tells when job runs started from the timestamp of their started.json
+func (c *FakeCIGCSClient) ListJobRunNamesBetween(ctx context.Context, gcsPrefix, startingJobRunID, endingJobRunID string, start, end time.Time) ([]string, error) {
+	jobRunIDs := []string{}
+	for _, jobRunID := range c.Bucket.jobRunIDs(gcsPrefix) {
+		if jobRunID < startingJobRunID || (len(endingJobRunID) > 0 && jobRunID >= endingJobRunID) {
+			continue
+		}
+		reader, err := c.Bucket.OpenObject(ctx, fmt.Sprintf("%s/%s/started.json", gcsPrefix, jobRunID), "")
+		if err != nil {
+			continue
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		started := struct {
+			Timestamp int64 `json:"timestamp"`
+		}{}
+		if err := json.Unmarshal(content, &started); err != nil {
+			return nil, fmt.Errorf("failed to parse started.json of %s/%s: %w", gcsPrefix, jobRunID, err)
+		}
+		if startTime := time.Unix(started.Timestamp, 0); !startTime.Before(start) && startTime.Before(end) {
+			jobRunIDs = append(jobRunIDs, jobRunID)
+		}
+	}
+	return jobRunIDs, nil
+}
+
+func (c *FakeCIGCSClient) ReadJobRunFromGCS(ctx context.Context, jobGCSRootLocation, jobName, jobRunID string, logger logrus.FieldLogger, opts ...jobrunaggregatorlib.ReadJobRunOption) (jobrunaggregatorapi.JobRunInfo, error) {
+	jobRun := c.newJobRun(jobGCSRootLocation, jobName, jobRunID)
+	if jobrunaggregatorlib.NewReadJobRunOptions(opts...).SkipProwJobValidation {
+		return jobRun, nil
+	}
+	if _, err := jobRun.GetProwJob(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get prowjob for %q/%q: %w", jobName, jobRunID, err)
+	}
+	return jobRun, nil
+}
+
+// ReadJobRunsFromGCS leaves out the job runs without prowjob like the GCS client does, but reads the others one at a
+// time
+func (c *FakeCIGCSClient) ReadJobRunsFromGCS(ctx context.Context, jobGCSRootLocation, jobName string, jobRunIDs []string, logger logrus.FieldLogger) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	wanted := sets.New[string](jobRunIDs...)
+	jobRuns := []jobrunaggregatorapi.JobRunInfo{}
+	errs := []error{}
+	for _, jobRunID := range c.Bucket.jobRunIDs(jobGCSRootLocation) {
+		if !wanted.Has(jobRunID) {
+			continue
+		}
+		jobRun := c.newJobRun(jobGCSRootLocation, jobName, jobRunID)
+		if _, err := jobRun.GetProwJob(ctx); err != nil {
+			if !errors.Is(err, storage.ErrObjectNotExist) {
+				errs = append(errs, fmt.Errorf("failed to get prowjob for %q/%q: %w", jobName, jobRunID, err))
+			}
+			continue
+		}
+		jobRuns = append(jobRuns, jobRun)
+	}
+	return jobRuns, utilerrors.NewAggregate(errs)
+}
+
+// ReadRelatedJobRuns lists the job runs from startingJobRunID, included, to endingJobRunID, excluded, like the GCS
+// client does
+func (c *FakeCIGCSClient) ReadRelatedJobRuns(ctx context.Context, jobName, gcsPrefix, startingJobRunID, endingJobRunID string, matcherFunc jobrunaggregatorlib.ProwJobMatcherFunc) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	relatedJobRuns := []jobrunaggregatorapi.JobRunInfo{}
+	for _, jobRunID := range c.Bucket.jobRunIDs(gcsPrefix) {
+		if jobRunID < startingJobRunID || (len(endingJobRunID) > 0 && jobRunID >= endingJobRunID) {
+			continue
+		}
+		jobRun := c.newJobRun(gcsPrefix, jobName, jobRunID)
+		prowJob, err := jobRun.GetProwJob(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get prowjob for %q/%q: %w", jobName, jobRunID, err)
+		}
+		if matcherFunc(prowJob) {
+			relatedJobRuns = append(relatedJobRuns, jobRun)
+		}
+	}
+	return relatedJobRuns, nil
+}
@@ -0,0 +1,56 @@
+package gcstesting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	prowjobv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+)
+
+func TestFakeCIGCSClient(t *testing.T) {
+	ctx := context.TODO()
+	bucket, err := NewFakeBucketFromDir("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewFakeCIGCSClient(bucket)
+
+	jobRuns, err := client.ReadRelatedJobRuns(ctx, "periodic-e2e-aws", "logs/periodic-e2e-aws", "1000", "", func(prowJob *prowjobv1.ProwJob) bool {
+		return prowJob.Labels["release.openshift.io/analysis"] == "4.15.0-0.nightly-2023-10-01-000000"
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, jobRuns, 2) {
+		assert.True(t, jobRuns[0].IsFinished(ctx))
+		testSuites, err := jobRuns[1].GetCombinedJUnitTestSuites(ctx)
+		assert.NoError(t, err)
+		if assert.Len(t, testSuites.Suites, 1) && assert.Len(t, testSuites.Suites[0].TestCases, 2) {
+			assert.NotNil(t, testSuites.Suites[0].TestCases[1].FailureOutput)
+		}
+		artifacts, err := jobRuns[1].GetArtifacts(ctx, "artifacts/**/junit_*.xml")
+		assert.NoError(t, err)
+		assert.Contains(t, artifacts, "logs/periodic-e2e-aws/2000/artifacts/e2e-aws/junit/junit_e2e.xml")
+	}
+
+	jobRun, err := client.ReadJobRunFromGCS(ctx, "logs/periodic-e2e-aws", "periodic-e2e-aws", "3000", nil)
+	assert.NoError(t, err)
+	assert.False(t, jobRun.IsFinished(ctx))
+	bucket.Put("logs/periodic-e2e-aws/3000/finished.json", []byte(`{"passed": true}`))
+	assert.True(t, jobRun.IsFinished(ctx))
+
+	// job runs are not validated when asked not to, so a missing one only fails once read
+	missing, err := client.ReadJobRunFromGCS(ctx, "logs/periodic-e2e-aws", "periodic-e2e-aws", "4000", nil, jobrunaggregatorlib.WithoutProwJobValidation())
+	assert.NoError(t, err)
+	_, err = missing.GetProwJob(ctx)
+	assert.Error(t, err)
+	_, err = client.ReadJobRunFromGCS(ctx, "logs/periodic-e2e-aws", "periodic-e2e-aws", "4000", nil)
+	assert.Error(t, err)
+
+	jobRunIDs, err := client.ListJobRunNamesBetween(ctx, "logs/periodic-e2e-aws", "", "", time.Date(2023, 10, 1, 1, 30, 0, 0, time.UTC), time.Date(2023, 10, 2, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2000"}, jobRunIDs)
+}
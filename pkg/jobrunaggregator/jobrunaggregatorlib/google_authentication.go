@@ -96,7 +96,7 @@ func (f *GoogleAuthenticationFlags) NewGCSClient(ctx context.Context) (*storage.
 	)
 }
 
-func (f *GoogleAuthenticationFlags) NewCIGCSClient(ctx context.Context, gcsBucketName string) (CIGCSClient, error) {
+func (f *GoogleAuthenticationFlags) NewCIGCSClient(ctx context.Context, gcsBucketName string, options CIGCSClientOptions) (CIGCSClient, error) {
 	gcsClient, err := f.NewGCSClient(ctx)
 	if err != nil {
 		return nil, err
@@ -105,6 +105,7 @@ func (f *GoogleAuthenticationFlags) NewCIGCSClient(ctx context.Context, gcsBucke
 	return &ciGCSClient{
 		gcsClient:     gcsClient,
 		gcsBucketName: gcsBucketName,
+		options:       options,
 	}, nil
 }
 
@@ -0,0 +1,208 @@
+package jobrunaggregatorlib
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// historicalDataBuckets sums up the job runs of the histograms of the same disruption or alert and job variants
+type historicalDataBuckets struct {
+	jobRunsBySeconds map[int]int
+	firstObserved    time.Time
+	lastObserved     time.Time
+}
+
+func (b *historicalDataBuckets) add(seconds, jobRuns int) {
+	if b.jobRunsBySeconds == nil {
+		b.jobRunsBySeconds = map[int]int{}
+	}
+	b.jobRunsBySeconds[seconds] += jobRuns
+}
+
+// percentiles returns the job runs and the P50, P75, P95 and P99 of the seconds, formatted like the historical data
+// queries format them.
+func (b *historicalDataBuckets) percentiles() (int, [4]string) {
+	buckets := []disruptionBucket{}
+	jobRuns := 0
+	for seconds, count := range b.jobRunsBySeconds {
+		buckets = append(buckets, disruptionBucket{seconds: seconds, jobRuns: count})
+		jobRuns += count
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].seconds < buckets[j].seconds })
+
+	ret := [4]string{}
+	for i, percentile := range []float64{0.50, 0.75, 0.95, 0.99} {
+		ret[i] = formatHistoricalPercentile(interpolatePercentile(buckets, jobRuns, percentile))
+	}
+	return jobRuns, ret
+}
+
+// formatHistoricalPercentile keeps the trailing zero of whole numbers, like the JSON downloaded from the BigQuery UI.
+func formatHistoricalPercentile(seconds float64) string {
+	formatted := strconv.FormatFloat(seconds, 'f', -1, 64)
+	if !strings.Contains(formatted, ".") {
+		formatted += ".0"
+	}
+	return formatted
+}
+
+// historicalJobDataByJobName returns the variants of the jobs by name.  Jobs without a release are left out: the
+// historical data is compared by release, and their rows could not be told apart from the rows of other releases.
+func historicalJobDataByJobName(jobs []jobrunaggregatorapi.JobRowWithVariants) map[string]jobrunaggregatorapi.HistoricalJobData {
+	ret := map[string]jobrunaggregatorapi.HistoricalJobData{}
+	for _, job := range jobs {
+		if len(job.Release) == 0 {
+			continue
+		}
+		ret[job.JobName] = jobrunaggregatorapi.HistoricalJobData{
+			Release:      job.Release,
+			FromRelease:  job.FromRelease.StringVal,
+			Platform:     job.Platform,
+			Architecture: job.Architecture,
+			Network:      job.Network,
+			Topology:     job.Topology,
+		}
+	}
+	return ret
+}
+
+type disruptionHistoricalDataKey struct {
+	backendName string
+	jobData     jobrunaggregatorapi.HistoricalJobData
+}
+
+// NewDisruptionHistoricalData computes the percentiles of the disruption of every backend by release and job variants
+// from the histogram of the jobs, like the BackendDisruptionPercentilesByDate view does.  The histograms of separate
+// days can be concatenated, so that the days are queried concurrently.  Like ListDisruptionHistoricalData, only the
+// job runs which updated master nodes, or don't know if they did, are considered for upgrades.
+func NewDisruptionHistoricalData(jobs []jobrunaggregatorapi.JobRowWithVariants, histogram []jobrunaggregatorapi.BackendDisruptionHistogramRow) []jobrunaggregatorapi.HistoricalData {
+	jobDataByJobName := historicalJobDataByJobName(jobs)
+
+	bucketsByKey := map[disruptionHistoricalDataKey]*historicalDataBuckets{}
+	for _, row := range histogram {
+		jobData, ok := jobDataByJobName[row.JobName.StringVal]
+		if !ok || row.JobRuns <= 0 {
+			continue
+		}
+		if row.MasterNodesUpdated.Valid && row.MasterNodesUpdated.StringVal == "N" && len(jobData.FromRelease) > 0 {
+			continue
+		}
+		jobData.MasterNodesUpdated = row.MasterNodesUpdated
+		key := disruptionHistoricalDataKey{backendName: row.BackendName, jobData: jobData}
+		if _, ok := bucketsByKey[key]; !ok {
+			bucketsByKey[key] = &historicalDataBuckets{}
+		}
+		bucketsByKey[key].add(row.DisruptionSeconds, row.JobRuns)
+	}
+
+	rows := []*jobrunaggregatorapi.DisruptionHistoricalDataRow{}
+	for key, buckets := range bucketsByKey {
+		row := &jobrunaggregatorapi.DisruptionHistoricalDataRow{
+			BackendName:       key.backendName,
+			HistoricalJobData: key.jobData,
+		}
+		var percentiles [4]string
+		row.JobRuns, percentiles = buckets.percentiles()
+		row.P50, row.P75, row.P95, row.P99 = percentiles[0], percentiles[1], percentiles[2], percentiles[3]
+		rows = append(rows, row)
+	}
+	// sorted like ListDisruptionHistoricalData
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		for _, fields := range [][2]string{
+			{a.Release, b.Release},
+			{a.FromRelease, b.FromRelease},
+			{a.MasterNodesUpdated.StringVal, b.MasterNodesUpdated.StringVal},
+			{a.Platform, b.Platform},
+			{a.Architecture, b.Architecture},
+			{a.Network, b.Network},
+			{a.Topology, b.Topology},
+		} {
+			if fields[0] != fields[1] {
+				return fields[0] < fields[1]
+			}
+		}
+		return a.BackendName < b.BackendName
+	})
+	return jobrunaggregatorapi.ConvertToHistoricalData(rows)
+}
+
+type alertHistoricalDataKey struct {
+	alertName      string
+	alertNamespace string
+	alertLevel     string
+	jobData        jobrunaggregatorapi.HistoricalJobData
+}
+
+// NewAlertHistoricalData computes the percentiles of the seconds every alert fired by release and job variants from
+// the histogram of the jobs, like the Alerts_Unified_LastWeek_P95 view does.  The histograms of separate days can be
+// concatenated, so that the days are queried concurrently.
+func NewAlertHistoricalData(jobs []jobrunaggregatorapi.JobRowWithVariants, histogram []jobrunaggregatorapi.AlertHistogramRow) []*jobrunaggregatorapi.AlertHistoricalDataRow {
+	jobDataByJobName := historicalJobDataByJobName(jobs)
+
+	bucketsByKey := map[alertHistoricalDataKey]*historicalDataBuckets{}
+	for _, row := range histogram {
+		jobData, ok := jobDataByJobName[row.JobName.StringVal]
+		if !ok || row.JobRuns <= 0 {
+			continue
+		}
+		key := alertHistoricalDataKey{
+			alertName:      row.AlertName,
+			alertNamespace: row.AlertNamespace,
+			alertLevel:     row.AlertLevel,
+			jobData:        jobData,
+		}
+		buckets, ok := bucketsByKey[key]
+		if !ok {
+			buckets = &historicalDataBuckets{firstObserved: row.FirstObserved, lastObserved: row.LastObserved}
+			bucketsByKey[key] = buckets
+		}
+		buckets.add(row.AlertSeconds, row.JobRuns)
+		if row.FirstObserved.Before(buckets.firstObserved) {
+			buckets.firstObserved = row.FirstObserved
+		}
+		if row.LastObserved.After(buckets.lastObserved) {
+			buckets.lastObserved = row.LastObserved
+		}
+	}
+
+	rows := []*jobrunaggregatorapi.AlertHistoricalDataRow{}
+	for key, buckets := range bucketsByKey {
+		row := &jobrunaggregatorapi.AlertHistoricalDataRow{
+			AlertName:         key.alertName,
+			AlertNamespace:    key.alertNamespace,
+			AlertLevel:        key.alertLevel,
+			FirstObserved:     buckets.firstObserved,
+			LastObserved:      buckets.lastObserved,
+			HistoricalJobData: key.jobData,
+		}
+		var percentiles [4]string
+		row.JobRuns, percentiles = buckets.percentiles()
+		row.P50, row.P75, row.P95, row.P99 = percentiles[0], percentiles[1], percentiles[2], percentiles[3]
+		rows = append(rows, row)
+	}
+	// sorted like ListAlertHistoricalData, then by architecture which it leaves unordered
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		for _, fields := range [][2]string{
+			{a.Release, b.Release},
+			{a.AlertName, b.AlertName},
+			{a.AlertNamespace, b.AlertNamespace},
+			{a.AlertLevel, b.AlertLevel},
+			{a.FromRelease, b.FromRelease},
+			{a.Topology, b.Topology},
+			{a.Platform, b.Platform},
+			{a.Network, b.Network},
+		} {
+			if fields[0] != fields[1] {
+				return fields[0] < fields[1]
+			}
+		}
+		return a.Architecture < b.Architecture
+	})
+	return rows
+}
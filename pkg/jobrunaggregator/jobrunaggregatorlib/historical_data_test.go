@@ -0,0 +1,79 @@
+package jobrunaggregatorlib
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+var historicalDataTestJobs = []jobrunaggregatorapi.JobRowWithVariants{
+	{JobName: "periodic-4.16-aws", Release: "4.16", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"},
+	{JobName: "periodic-4.16-aws-serial", Release: "4.16", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"},
+	{JobName: "periodic-4.16-aws-upgrade", Release: "4.16", FromRelease: bigquery.NullString{StringVal: "4.15", Valid: true}, Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"},
+	{JobName: "periodic-no-release-aws", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"},
+}
+
+func TestNewDisruptionHistoricalData(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	jobName := func(name string) bigquery.NullString { return bigquery.NullString{StringVal: name, Valid: true} }
+	updated := bigquery.NullString{StringVal: "Y", Valid: true}
+	notUpdated := bigquery.NullString{StringVal: "N", Valid: true}
+	histogram := []jobrunaggregatorapi.BackendDisruptionHistogramRow{
+		// the days and the jobs of the same variants are merged
+		{JobName: jobName("periodic-4.16-aws"), MasterNodesUpdated: notUpdated, BackendName: "kube-api", JobRunStartDay: day, DisruptionSeconds: 0, JobRuns: 2},
+		{JobName: jobName("periodic-4.16-aws"), MasterNodesUpdated: notUpdated, BackendName: "kube-api", JobRunStartDay: day.AddDate(0, 0, 1), DisruptionSeconds: 4, JobRuns: 1},
+		{JobName: jobName("periodic-4.16-aws-serial"), MasterNodesUpdated: notUpdated, BackendName: "kube-api", JobRunStartDay: day, DisruptionSeconds: 10, JobRuns: 2},
+		// upgrades which did not update the master nodes are left out
+		{JobName: jobName("periodic-4.16-aws-upgrade"), MasterNodesUpdated: updated, BackendName: "kube-api", JobRunStartDay: day, DisruptionSeconds: 2, JobRuns: 1},
+		{JobName: jobName("periodic-4.16-aws-upgrade"), MasterNodesUpdated: notUpdated, BackendName: "kube-api", JobRunStartDay: day, DisruptionSeconds: 100, JobRuns: 3},
+		// jobs without a release are left out rather than counted in every release
+		{JobName: jobName("periodic-no-release-aws"), MasterNodesUpdated: notUpdated, BackendName: "kube-api", JobRunStartDay: day, DisruptionSeconds: 100, JobRuns: 3},
+		{JobName: jobName("periodic-unknown"), MasterNodesUpdated: notUpdated, BackendName: "kube-api", JobRunStartDay: day, DisruptionSeconds: 100, JobRuns: 3},
+	}
+
+	jobData := jobrunaggregatorapi.HistoricalJobData{Release: "4.16", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	expectedInstall := jobData
+	expectedInstall.MasterNodesUpdated = notUpdated
+	expectedInstall.JobRuns = 5
+	expectedUpgrade := jobData
+	expectedUpgrade.FromRelease = "4.15"
+	expectedUpgrade.MasterNodesUpdated = updated
+	expectedUpgrade.JobRuns = 1
+	expected := jobrunaggregatorapi.ConvertToHistoricalData([]*jobrunaggregatorapi.DisruptionHistoricalDataRow{
+		// PERCENTILE_CONT of 0, 0, 4, 10 and 10
+		{BackendName: "kube-api", HistoricalJobData: expectedInstall, P50: "4.0", P75: "10.0", P95: "10.0", P99: "10.0"},
+		{BackendName: "kube-api", HistoricalJobData: expectedUpgrade, P50: "2.0", P75: "2.0", P95: "2.0", P99: "2.0"},
+	})
+
+	actual := NewDisruptionHistoricalData(historicalDataTestJobs, histogram)
+	assert.Equal(t, expected, actual)
+}
+
+func TestNewAlertHistoricalData(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	jobName := func(name string) bigquery.NullString { return bigquery.NullString{StringVal: name, Valid: true} }
+	histogram := []jobrunaggregatorapi.AlertHistogramRow{
+		{JobName: jobName("periodic-4.16-aws"), AlertName: "KubePodNotReady", AlertNamespace: "openshift-etcd", AlertLevel: "Warning", AlertSeconds: 30, JobRuns: 1, FirstObserved: day.Add(time.Hour), LastObserved: day.Add(time.Hour)},
+		{JobName: jobName("periodic-4.16-aws-serial"), AlertName: "KubePodNotReady", AlertNamespace: "openshift-etcd", AlertLevel: "Warning", AlertSeconds: 0, JobRuns: 1, FirstObserved: day, LastObserved: day.AddDate(0, 0, 2)},
+		{JobName: jobName("periodic-no-release-aws"), AlertName: "KubePodNotReady", AlertNamespace: "openshift-etcd", AlertLevel: "Warning", AlertSeconds: 500, JobRuns: 7, FirstObserved: day, LastObserved: day},
+		{JobName: jobName("periodic-4.16-aws-upgrade"), AlertName: "AlertmanagerDown", AlertNamespace: "openshift-monitoring", AlertLevel: "Critical", AlertSeconds: 60, JobRuns: 2, FirstObserved: day, LastObserved: day},
+	}
+
+	jobData := jobrunaggregatorapi.HistoricalJobData{Release: "4.16", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	expectedInstall := jobData
+	expectedInstall.JobRuns = 2
+	expectedUpgrade := jobData
+	expectedUpgrade.FromRelease = "4.15"
+	expectedUpgrade.JobRuns = 2
+	expected := []*jobrunaggregatorapi.AlertHistoricalDataRow{
+		{AlertName: "AlertmanagerDown", AlertNamespace: "openshift-monitoring", AlertLevel: "Critical", FirstObserved: day, LastObserved: day, HistoricalJobData: expectedUpgrade, P50: "60.0", P75: "60.0", P95: "60.0", P99: "60.0"},
+		{AlertName: "KubePodNotReady", AlertNamespace: "openshift-etcd", AlertLevel: "Warning", FirstObserved: day, LastObserved: day.AddDate(0, 0, 2), HistoricalJobData: expectedInstall, P50: "15.0", P75: "22.5", P95: "28.5", P99: "29.7"},
+	}
+
+	actual := NewAlertHistoricalData(historicalDataTestJobs, histogram)
+	assert.Equal(t, expected, actual)
+}
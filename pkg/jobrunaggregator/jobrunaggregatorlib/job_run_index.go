@@ -0,0 +1,78 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// JobRunIndex lists the job runs of a job that started in a window, or between two job runs, without walking GCS, like
+// the CIDataClient does from the JobRuns table the loaders keep up to date
+type JobRunIndex interface {
+	ListJobRunNamesBetween(ctx context.Context, jobName string, start, end time.Time) ([]string, error)
+	ListJobRunNamesInRange(ctx context.Context, jobName, startingJobRunID, endingJobRunID string) ([]string, error)
+}
+
+// indexedCIGCSClient answers the listings of job runs from an index, and everything else from GCS
+type indexedCIGCSClient struct {
+	CIGCSClient
+	index JobRunIndex
+}
+
+// NewIndexedCIGCSClient lists job runs from the index instead of the bucket, which is much faster for jobs with many
+// job runs.  The index only knows finished job runs, so job runs still running are not listed.
+func NewIndexedCIGCSClient(client CIGCSClient, index JobRunIndex) CIGCSClient {
+	return &indexedCIGCSClient{CIGCSClient: client, index: index}
+}
+
+func (o *indexedCIGCSClient) ForBucket(bucketName string) CIGCSClient {
+	return NewIndexedCIGCSClient(o.CIGCSClient.ForBucket(bucketName), o.index)
+}
+
+// ListJobRunNamesBetween doesn't need the job run IDs bounding the listing, the index is searched by start time
+func (o *indexedCIGCSClient) ListJobRunNamesBetween(ctx context.Context, gcsPrefix, _, _ string, start, end time.Time) ([]string, error) {
+	jobName := path.Base(gcsPrefix)
+	if location, err := jobrunaggregatorapi.ParseJobRunGCSLocation(gcsPrefix); err == nil {
+		jobName = location.JobName
+	}
+	defer observeJobListing(jobName, time.Now())
+	return o.index.ListJobRunNamesBetween(ctx, jobName, start, end)
+}
+
+// ReadRelatedJobRuns lists the job runs between the job run IDs from the index, and only reads their prowjobs from GCS
+// to match them
+func (o *indexedCIGCSClient) ReadRelatedJobRuns(ctx context.Context, jobName, gcsPrefix, startingJobRunID, endingJobRunID string, matcherFunc ProwJobMatcherFunc) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	defer observeJobListing(jobName, time.Now())
+
+	jobRunIDs, err := o.index.ListJobRunNamesInRange(ctx, jobName, startingJobRunID, endingJobRunID)
+	if err != nil {
+		return nil, err
+	}
+	logrus.WithFields(logrus.Fields{"job": jobName, "startingJobRunID": startingJobRunID, "endingJobRunID": endingJobRunID}).Debugf("found %d job runs in the index", len(jobRunIDs))
+
+	jobRunPrefixes := []string{}
+	for _, jobRunID := range jobRunIDs {
+		jobRunPrefixes = append(jobRunPrefixes, fmt.Sprintf("%s/%s/", gcsPrefix, jobRunID))
+	}
+	return readJobRunsConcurrently(ctx, jobRunPrefixes, sets.New[string](jobRunIDs...), func(ctx context.Context, jobRunPrefix string) (jobrunaggregatorapi.JobRunInfo, error) {
+		jobRun, err := o.CIGCSClient.ReadJobRunFromGCS(ctx, gcsPrefix, jobName, jobRunIDFromPrefix(jobRunPrefix), logrus.StandardLogger())
+		if err != nil {
+			return nil, err
+		}
+		prowJob, err := jobRun.GetProwJob(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get prowjob for %q/%q: %w", jobName, jobRun.GetJobRunID(), err)
+		}
+		if !matcherFunc(prowJob) {
+			return nil, nil
+		}
+		return jobRun, nil
+	})
+}
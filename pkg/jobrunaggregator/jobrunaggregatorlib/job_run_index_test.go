@@ -0,0 +1,61 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	prowjobv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func TestIndexedCIGCSClient(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	start := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	mockDataClient := NewMockCIDataClient(mockCtrl)
+	mockDataClient.EXPECT().ListJobRunNamesBetween(gomock.Any(), "periodic-e2e-aws", start, end).Return([]string{"1000", "2000"}, nil).Times(1)
+	mockDataClient.EXPECT().ListJobRunNamesBetween(gomock.Any(), "pull-ci-e2e-aws", start, end).Return([]string{"3000"}, nil).Times(1)
+	mockGCSClient := NewMockCIGCSClient(mockCtrl)
+	mockGCSClient.EXPECT().ForBucket("qe-private-deck").Return(mockGCSClient).Times(1)
+
+	client := NewIndexedCIGCSClient(mockGCSClient, mockDataClient)
+	jobRunIDs, err := client.ListJobRunNamesBetween(context.TODO(), "logs/periodic-e2e-aws", "", "", start, end)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1000", "2000"}, jobRunIDs)
+
+	// the index is kept for other buckets, and the job name is read from pr-logs prefixes
+	jobRunIDs, err = client.ForBucket("qe-private-deck").ListJobRunNamesBetween(context.TODO(), "pr-logs/pull/openshift_origin/123/pull-ci-e2e-aws", "", "", start, end)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3000"}, jobRunIDs)
+}
+
+func TestIndexedCIGCSClientReadRelatedJobRuns(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDataClient := NewMockCIDataClient(mockCtrl)
+	mockDataClient.EXPECT().ListJobRunNamesInRange(gomock.Any(), "periodic-e2e-aws", "1000", "4000").Return([]string{"1000", "2000", "3000"}, nil).Times(1)
+	mockGCSClient := NewMockCIGCSClient(mockCtrl)
+	jobRuns := map[string]*jobrunaggregatorapi.MockJobRunInfo{}
+	for _, jobRunID := range []string{"1000", "2000", "3000"} {
+		jobRun := jobrunaggregatorapi.NewMockJobRunInfo(mockCtrl)
+		jobRun.EXPECT().GetProwJob(gomock.Any()).Return(&prowjobv1.ProwJob{ObjectMeta: metav1.ObjectMeta{Name: jobRunID}}, nil).AnyTimes()
+		jobRuns[jobRunID] = jobRun
+		// the job runs are read without listing the bucket
+		mockGCSClient.EXPECT().ReadJobRunFromGCS(gomock.Any(), "logs/periodic-e2e-aws", "periodic-e2e-aws", jobRunID, gomock.Any()).Return(jobRun, nil).Times(1)
+	}
+
+	client := NewIndexedCIGCSClient(mockGCSClient, mockDataClient)
+	matcher := func(prowJob *prowjobv1.ProwJob) bool { return prowJob.Name != "2000" }
+	actual, err := client.ReadRelatedJobRuns(context.TODO(), "periodic-e2e-aws", "logs/periodic-e2e-aws", "1000", "4000", matcher)
+	assert.NoError(t, err)
+	assert.Equal(t, []jobrunaggregatorapi.JobRunInfo{jobRuns["1000"], jobRuns["3000"]}, actual)
+}
@@ -0,0 +1,53 @@
+package jobrunaggregatorlib
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// JobSearchWindowFlags tune which job runs of a job are listed from GCS, like to widen the window of a backfill or
+// to narrow the one of a dry run.
+type JobSearchWindowFlags struct {
+	StartOffset      time.Duration
+	EndOffset        time.Duration
+	StartingJobRunID string
+}
+
+func NewJobSearchWindowFlags() *JobSearchWindowFlags {
+	return &JobSearchWindowFlags{
+		StartOffset: JobSearchWindowStartOffset,
+		EndOffset:   JobSearchWindowEndOffset,
+	}
+}
+
+func (f *JobSearchWindowFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&f.StartOffset, "job-search-window-start-offset", f.StartOffset, "How long before the start time of the jobs their job runs are searched from")
+	fs.DurationVar(&f.EndOffset, "job-search-window-end-offset", f.EndOffset, "How long after the start time of the jobs their job runs are searched until")
+	fs.StringVar(&f.StartingJobRunID, "gcs-starting-job-run-id", f.StartingJobRunID, "The job run ID GCS is listed from for jobs whose job runs before the search window are unknown, like jobs missing from BigQuery.  Defaults to listing every job run of the job")
+}
+
+func (f *JobSearchWindowFlags) Validate() error {
+	if f.StartOffset < 0 {
+		return fmt.Errorf("--job-search-window-start-offset must not be negative")
+	}
+	if f.EndOffset <= 0 {
+		return fmt.Errorf("--job-search-window-end-offset must be positive")
+	}
+	if len(f.StartingJobRunID) > 0 {
+		if _, err := strconv.ParseUint(f.StartingJobRunID, 10, 64); err != nil {
+			return fmt.Errorf("--gcs-starting-job-run-id must be a job run ID: %w", err)
+		}
+	}
+	return nil
+}
+
+// ToJobSearchWindow returns the search window of the job run locators.
+func (f *JobSearchWindowFlags) ToJobSearchWindow() JobSearchWindow {
+	return JobSearchWindow{
+		StartOffset: f.StartOffset,
+		EndOffset:   f.EndOffset,
+	}
+}
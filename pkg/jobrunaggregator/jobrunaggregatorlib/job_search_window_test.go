@@ -0,0 +1,25 @@
+package jobrunaggregatorlib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobSearchWindowFlags(t *testing.T) {
+	f := NewJobSearchWindowFlags()
+	assert.NoError(t, f.Validate())
+	assert.Equal(t, DefaultJobSearchWindow, f.ToJobSearchWindow())
+
+	f.EndOffset = 0
+	assert.Error(t, f.Validate())
+	f.EndOffset = 17 * time.Hour
+	f.StartingJobRunID = "not-a-job-run"
+	assert.Error(t, f.Validate())
+
+	f.StartOffset = 24 * time.Hour
+	f.StartingJobRunID = "1475614363518767104"
+	assert.NoError(t, f.Validate())
+	assert.Equal(t, JobSearchWindow{StartOffset: 24 * time.Hour, EndOffset: 17 * time.Hour}, f.ToJobSearchWindow())
+}
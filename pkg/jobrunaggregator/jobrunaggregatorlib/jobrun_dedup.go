@@ -0,0 +1,46 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// DeduplicateJobRuns keeps a single job run per job run id, in the order they were located.  The search windows of
+// consecutive payloads overlap, so the same job run can be located more than once, e.g. when two analyzers record the
+// job runs they located for the same payload.
+func DeduplicateJobRuns(jobRuns []jobrunaggregatorapi.JobRunInfo) []jobrunaggregatorapi.JobRunInfo {
+	seen := map[string]bool{}
+	deduplicated := []jobrunaggregatorapi.JobRunInfo{}
+	for _, jobRun := range jobRuns {
+		if seen[jobRun.GetJobRunID()] {
+			logrus.Debugf("dropping duplicate job run %s/%s", jobRun.GetJobName(), jobRun.GetJobRunID())
+			continue
+		}
+		seen[jobRun.GetJobRunID()] = true
+		deduplicated = append(deduplicated, jobRun)
+	}
+	return deduplicated
+}
+
+// FilterJobRunsByProwJob deduplicates the job runs and keeps the ones whose prowjob matches, which is how job runs of
+// a previous payload that were rebuilt inside the search window of the current one are told apart: by the payload
+// annotation or label of their prowjob rather than by when they ran.
+func FilterJobRunsByProwJob(ctx context.Context, jobRuns []jobrunaggregatorapi.JobRunInfo, prowJobMatcher ProwJobMatcherFunc) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	matching := []jobrunaggregatorapi.JobRunInfo{}
+	for _, jobRun := range DeduplicateJobRuns(jobRuns) {
+		prowJob, err := jobRun.GetProwJob(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get prowjob for %q/%q: %w", jobRun.GetJobName(), jobRun.GetJobRunID(), err)
+		}
+		if !prowJobMatcher(prowJob) {
+			logrus.Infof("dropping job run %s/%s, its prowjob belongs to another payload", jobRun.GetJobName(), jobRun.GetJobRunID())
+			continue
+		}
+		matching = append(matching, jobRun)
+	}
+	return matching, nil
+}
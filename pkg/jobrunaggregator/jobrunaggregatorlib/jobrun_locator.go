@@ -12,15 +12,29 @@ import (
 )
 
 var (
-	// JobSearchWindowStartOffset defines the start offset of the job search window.
+	// JobSearchWindowStartOffset defines the default start offset of the job search window.
 	JobSearchWindowStartOffset time.Duration = 1 * time.Hour
-	// JobSearchWindowEndOffset defines the end offset of the job search window.
+	// JobSearchWindowEndOffset defines the default end offset of the job search window.
 	JobSearchWindowEndOffset time.Duration = 4 * time.Hour
 )
 
+// JobSearchWindow is how long before and after the start time of the jobs their job runs are searched for.
+type JobSearchWindow struct {
+	StartOffset time.Duration
+	EndOffset   time.Duration
+}
+
+var DefaultJobSearchWindow = JobSearchWindow{
+	StartOffset: JobSearchWindowStartOffset,
+	EndOffset:   JobSearchWindowEndOffset,
+}
+
 type JobRunLocator interface {
 	FindRelatedJobs(ctx context.Context) ([]jobrunaggregatorapi.JobRunInfo, error)
 	FindJob(ctx context.Context, jobRunID string) (jobrunaggregatorapi.JobRunInfo, error)
+	// FindJobs reads many job runs of the job at once, leaving out those that don't exist.  The job runs found are
+	// returned along with the errors reading the others.
+	FindJobs(ctx context.Context, jobRunIDs []string) ([]jobrunaggregatorapi.JobRunInfo, error)
 }
 
 // ProwJobMatcherFunc defines a function signature for matching prow jobs. The function is
@@ -40,7 +54,8 @@ type analysisJobAggregator struct {
 	prowJobMatcher ProwJobMatcherFunc
 	// startTime is the time when the analysis jobs were started.  We'll look plus or minus a day from here to bound the
 	// bigquery dataset.
-	startTime time.Time
+	startTime    time.Time
+	searchWindow JobSearchWindow
 
 	ciDataClient  AggregationJobClient
 	ciGCSClient   CIGCSClient
@@ -52,6 +67,7 @@ func NewPayloadAnalysisJobLocator(
 	jobName string,
 	prowJobMatcher ProwJobMatcherFunc,
 	startTime time.Time,
+	searchWindow JobSearchWindow,
 	ciDataClient AggregationJobClient,
 	ciGCSClient CIGCSClient,
 	gcsBucketName string,
@@ -61,8 +77,9 @@ func NewPayloadAnalysisJobLocator(
 		jobName:        jobName,
 		prowJobMatcher: prowJobMatcher,
 		startTime:      startTime,
+		searchWindow:   searchWindow,
 		ciDataClient:   ciDataClient,
-		ciGCSClient:    ciGCSClient,
+		ciGCSClient:    ciGCSClient.ForBucket(gcsBucketName),
 		gcsBucketName:  gcsBucketName,
 		gcsPrefix:      gcsPrefix,
 	}
@@ -71,8 +88,8 @@ func NewPayloadAnalysisJobLocator(
 // FindRelatedJobs returns a slice of JobRunInfo which has info contained in GCS buckets
 // used to determine pass/fail.
 func (a *analysisJobAggregator) FindRelatedJobs(ctx context.Context) ([]jobrunaggregatorapi.JobRunInfo, error) {
-	startOfJobRunWindow := a.startTime.Add(-1 * JobSearchWindowStartOffset)
-	endOfJobRunWindow := a.startTime.Add(JobSearchWindowEndOffset)
+	startOfJobRunWindow := a.startTime.Add(-1 * a.searchWindow.StartOffset)
+	endOfJobRunWindow := a.startTime.Add(a.searchWindow.EndOffset)
 	startingJobRunID, err := a.ciDataClient.GetJobRunForJobNameBeforeTime(ctx, a.jobName, startOfJobRunWindow)
 	if err != nil {
 		return nil, err
@@ -82,9 +99,17 @@ func (a *analysisJobAggregator) FindRelatedJobs(ctx context.Context) ([]jobrunag
 		return nil, err
 	}
 
-	return a.ciGCSClient.ReadRelatedJobRuns(ctx, a.jobName, a.gcsPrefix, startingJobRunID, endingJobRunID, a.prowJobMatcher)
+	jobRuns, err := a.ciGCSClient.ReadRelatedJobRuns(ctx, a.jobName, a.gcsPrefix, startingJobRunID, endingJobRunID, a.prowJobMatcher)
+	if err != nil {
+		return nil, err
+	}
+	return DeduplicateJobRuns(jobRuns), nil
 }
 
 func (a *analysisJobAggregator) FindJob(ctx context.Context, jobRunID string) (jobrunaggregatorapi.JobRunInfo, error) {
 	return a.ciGCSClient.ReadJobRunFromGCS(ctx, a.gcsPrefix, a.jobName, jobRunID, logrus.New())
 }
+
+func (a *analysisJobAggregator) FindJobs(ctx context.Context, jobRunIDs []string) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	return a.ciGCSClient.ReadJobRunsFromGCS(ctx, a.gcsPrefix, a.jobName, jobRunIDs, logrus.New())
+}
@@ -0,0 +1,111 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// cachingJobRunLocator remembers the job runs located for a payload in BigQuery.  The disruption, alert and test case
+// analyzers all look for the job runs of the same payload, only the first one has to search GCS for them.
+type cachingJobRunLocator struct {
+	delegate JobRunLocator
+
+	jobName string
+	matchID string
+	// prowJobMatcher checks that the cached job runs still belong to the payload
+	prowJobMatcher ProwJobMatcherFunc
+
+	ciDataClient          AggregationJobClient
+	locatedJobRunInserter BigQueryInserter
+	now                   func() time.Time
+}
+
+// NewCachingJobRunLocator wraps the locator of the job runs of jobName matching matchID, the payload tag or the
+// invocation id, which prowJobMatcher matches.
+func NewCachingJobRunLocator(
+	delegate JobRunLocator,
+	jobName, matchID string,
+	prowJobMatcher ProwJobMatcherFunc,
+	ciDataClient AggregationJobClient,
+	locatedJobRunInserter BigQueryInserter) JobRunLocator {
+
+	return &cachingJobRunLocator{
+		delegate:              delegate,
+		jobName:               jobName,
+		matchID:               matchID,
+		prowJobMatcher:        prowJobMatcher,
+		ciDataClient:          ciDataClient,
+		locatedJobRunInserter: locatedJobRunInserter,
+		now:                   time.Now,
+	}
+}
+
+func (c *cachingJobRunLocator) FindRelatedJobs(ctx context.Context) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	logger := logrus.WithFields(logrus.Fields{"job": c.jobName, "match": c.matchID})
+
+	located, err := c.ciDataClient.ListLocatedJobRuns(ctx, c.jobName, c.matchID)
+	if err != nil {
+		// the cache is an optimization, searching GCS still gives the right answer
+		logger.WithError(err).Warning("failed to list located job runs, searching GCS")
+	}
+	if len(located) > 0 {
+		jobRuns := []jobrunaggregatorapi.JobRunInfo{}
+		for _, row := range located {
+			jobRun, err := c.delegate.FindJob(ctx, row.JobRunID)
+			if err != nil {
+				return nil, err
+			}
+			if jobRun != nil {
+				jobRuns = append(jobRuns, jobRun)
+			}
+		}
+		// a job run may have been recorded by several analyzers, and a job run of another payload must not count
+		jobRuns, err = FilterJobRunsByProwJob(ctx, jobRuns, c.prowJobMatcher)
+		if err != nil {
+			return nil, err
+		}
+		logger.Infof("found %d job runs located by a previous analyzer", len(jobRuns))
+		return jobRuns, nil
+	}
+
+	jobRuns, err := c.delegate.FindRelatedJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// job runs are launched together, but until they have all finished some of them may not be in GCS yet, so only a
+	// complete set is recorded.
+	if len(jobRuns) == 0 {
+		return jobRuns, nil
+	}
+	for _, jobRun := range jobRuns {
+		if !jobRun.IsFinished(ctx) {
+			return jobRuns, nil
+		}
+	}
+	now := c.now()
+	rows := []jobrunaggregatorapi.LocatedJobRunRow{}
+	for _, jobRun := range jobRuns {
+		rows = append(rows, jobrunaggregatorapi.LocatedJobRunRow{
+			JobName:     c.jobName,
+			MatchID:     c.matchID,
+			JobRunID:    jobRun.GetJobRunID(),
+			LocatedTime: now,
+		})
+	}
+	if err := c.locatedJobRunInserter.Put(ctx, rows); err != nil {
+		logger.WithError(err).Warning("failed to record located job runs")
+	}
+	return jobRuns, nil
+}
+
+func (c *cachingJobRunLocator) FindJob(ctx context.Context, jobRunID string) (jobrunaggregatorapi.JobRunInfo, error) {
+	return c.delegate.FindJob(ctx, jobRunID)
+}
+
+func (c *cachingJobRunLocator) FindJobs(ctx context.Context, jobRunIDs []string) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	return c.delegate.FindJobs(ctx, jobRunIDs)
+}
@@ -0,0 +1,173 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	prowjobv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+type fakeJobRunLocator struct {
+	relatedJobs     []jobrunaggregatorapi.JobRunInfo
+	jobsByID        map[string]jobrunaggregatorapi.JobRunInfo
+	findRelatedJobs int
+}
+
+func (f *fakeJobRunLocator) FindRelatedJobs(ctx context.Context) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	f.findRelatedJobs++
+	return f.relatedJobs, nil
+}
+
+func (f *fakeJobRunLocator) FindJob(ctx context.Context, jobRunID string) (jobrunaggregatorapi.JobRunInfo, error) {
+	return f.jobsByID[jobRunID], nil
+}
+
+func (f *fakeJobRunLocator) FindJobs(ctx context.Context, jobRunIDs []string) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	jobRuns := []jobrunaggregatorapi.JobRunInfo{}
+	for _, jobRunID := range jobRunIDs {
+		if jobRun, ok := f.jobsByID[jobRunID]; ok {
+			jobRuns = append(jobRuns, jobRun)
+		}
+	}
+	return jobRuns, nil
+}
+
+type fakeInserter struct {
+	rows []interface{}
+}
+
+func (f *fakeInserter) Put(ctx context.Context, src interface{}) error {
+	f.rows = append(f.rows, src)
+	return nil
+}
+
+func newFinishedJobRun(mockCtrl *gomock.Controller, jobName, jobRunID, payloadTag string, finished bool) *jobrunaggregatorapi.MockJobRunInfo {
+	jobRun := jobrunaggregatorapi.NewMockJobRunInfo(mockCtrl)
+	jobRun.EXPECT().GetJobName().Return(jobName).AnyTimes()
+	jobRun.EXPECT().GetJobRunID().Return(jobRunID).AnyTimes()
+	jobRun.EXPECT().IsFinished(gomock.Any()).Return(finished).AnyTimes()
+	jobRun.EXPECT().GetProwJob(gomock.Any()).Return(&prowjobv1.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				ProwJobJobNameAnnotation:    jobName,
+				ProwJobPayloadTagAnnotation: payloadTag,
+			},
+		},
+	}, nil).AnyTimes()
+	return jobRun
+}
+
+func TestCachingJobRunLocator(t *testing.T) {
+	now := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	jobName := "periodic-ci-openshift-release-master-nightly-4.15-e2e-aws-ovn-upgrade"
+	payloadTag := "4.15.0-0.nightly-2023-10-01-000000"
+	previousPayloadTag := "4.15.0-0.nightly-2023-09-30-000000"
+
+	tests := []struct {
+		name             string
+		located          []jobrunaggregatorapi.LocatedJobRunRow
+		finished         bool
+		expectedIDs      []string
+		expectedSearches int
+		expectedRows     []jobrunaggregatorapi.LocatedJobRunRow
+	}{
+		{
+			name:             "cache hit skips the search",
+			located:          []jobrunaggregatorapi.LocatedJobRunRow{{JobName: jobName, MatchID: payloadTag, JobRunID: "2"}},
+			finished:         true,
+			expectedIDs:      []string{"2"},
+			expectedSearches: 0,
+		},
+		{
+			name: "job runs recorded twice are counted once",
+			located: []jobrunaggregatorapi.LocatedJobRunRow{
+				{JobName: jobName, MatchID: payloadTag, JobRunID: "2"},
+				{JobName: jobName, MatchID: payloadTag, JobRunID: "2"},
+			},
+			finished:         true,
+			expectedIDs:      []string{"2"},
+			expectedSearches: 0,
+		},
+		{
+			name: "cached job runs of the previous payload are dropped",
+			located: []jobrunaggregatorapi.LocatedJobRunRow{
+				{JobName: jobName, MatchID: payloadTag, JobRunID: "0"},
+				{JobName: jobName, MatchID: payloadTag, JobRunID: "1"},
+			},
+			finished:         true,
+			expectedIDs:      []string{"1"},
+			expectedSearches: 0,
+		},
+		{
+			name:             "finished job runs are recorded",
+			finished:         true,
+			expectedIDs:      []string{"1", "2"},
+			expectedSearches: 1,
+			expectedRows: []jobrunaggregatorapi.LocatedJobRunRow{
+				{JobName: jobName, MatchID: payloadTag, JobRunID: "1", LocatedTime: now},
+				{JobName: jobName, MatchID: payloadTag, JobRunID: "2", LocatedTime: now},
+			},
+		},
+		{
+			name:             "unfinished job runs are not recorded",
+			finished:         false,
+			expectedIDs:      []string{"1", "2"},
+			expectedSearches: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			// job run 0 is a rebuild of the previous payload, which started inside the search window
+			jobRun0 := newFinishedJobRun(mockCtrl, jobName, "0", previousPayloadTag, tc.finished)
+			jobRun1 := newFinishedJobRun(mockCtrl, jobName, "1", payloadTag, tc.finished)
+			jobRun2 := newFinishedJobRun(mockCtrl, jobName, "2", payloadTag, tc.finished)
+			delegate := &fakeJobRunLocator{
+				relatedJobs: []jobrunaggregatorapi.JobRunInfo{jobRun1, jobRun2},
+				jobsByID:    map[string]jobrunaggregatorapi.JobRunInfo{"0": jobRun0, "1": jobRun1, "2": jobRun2},
+			}
+			mockDataClient := NewMockCIDataClient(mockCtrl)
+			mockDataClient.EXPECT().ListLocatedJobRuns(gomock.Any(), jobName, payloadTag).Return(tc.located, nil)
+			inserter := &fakeInserter{}
+
+			locator := &cachingJobRunLocator{
+				delegate:              delegate,
+				jobName:               jobName,
+				matchID:               payloadTag,
+				prowJobMatcher:        NewProwJobMatcherFuncForReleaseController(jobName, payloadTag),
+				ciDataClient:          mockDataClient,
+				locatedJobRunInserter: inserter,
+				now:                   func() time.Time { return now },
+			}
+			jobRuns, err := locator.FindRelatedJobs(context.TODO())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := []string{}
+			for _, jobRun := range jobRuns {
+				ids = append(ids, jobRun.GetJobRunID())
+			}
+			if !reflect.DeepEqual(tc.expectedIDs, ids) {
+				t.Errorf("expected job runs %v, got %v", tc.expectedIDs, ids)
+			}
+			if delegate.findRelatedJobs != tc.expectedSearches {
+				t.Errorf("expected %d searches, got %d", tc.expectedSearches, delegate.findRelatedJobs)
+			}
+			switch {
+			case tc.expectedRows == nil && len(inserter.rows) > 0:
+				t.Errorf("expected nothing recorded, got %v", inserter.rows)
+			case tc.expectedRows != nil && (len(inserter.rows) != 1 || !reflect.DeepEqual(tc.expectedRows, inserter.rows[0])):
+				t.Errorf("expected %v recorded, got %v", tc.expectedRows, inserter.rows)
+			}
+		})
+	}
+}
@@ -42,6 +42,7 @@ func NewProwJobMatcherFuncForPR(matchJobName, matchID, matchLabel string) ProwJo
 func NewPayloadAnalysisJobLocatorForPR(
 	jobName, matchID, matchLabel string,
 	startTime time.Time,
+	searchWindow JobSearchWindow,
 	ciDataClient AggregationJobClient,
 	ciGCSClient CIGCSClient,
 	gcsBucketName string,
@@ -51,6 +52,7 @@ func NewPayloadAnalysisJobLocatorForPR(
 		jobName,
 		NewProwJobMatcherFuncForPR(jobName, matchID, matchLabel),
 		startTime,
+		searchWindow,
 		ciDataClient,
 		ciGCSClient,
 		gcsBucketName,
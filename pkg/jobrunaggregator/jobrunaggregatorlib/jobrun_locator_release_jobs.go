@@ -36,6 +36,7 @@ func NewProwJobMatcherFuncForReleaseController(matchJobName, matchPayloadTag str
 func NewPayloadAnalysisJobLocatorForReleaseController(
 	jobName, payloadTag string,
 	startTime time.Time,
+	searchWindow JobSearchWindow,
 	ciDataClient AggregationJobClient,
 	ciGCSClient CIGCSClient,
 	gcsBucketName string) JobRunLocator {
@@ -44,6 +45,7 @@ func NewPayloadAnalysisJobLocatorForReleaseController(
 		jobName,
 		NewProwJobMatcherFuncForReleaseController(jobName, payloadTag),
 		startTime,
+		searchWindow,
 		ciDataClient,
 		ciGCSClient,
 		gcsBucketName,
@@ -1,8 +1,21 @@
 package jobrunaggregatorlib
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
 // TestSuitesSeparator defines the separator to use when combine multiple level of suite names
 var TestSuitesSeparator = "|||"
 
+// TestCaseDetailsPropertyName is the name of the junit test case property holding the JSON serialized TestCaseDetails.
+const TestCaseDetailsPropertyName = "test-case-details"
+
 type TestCaseDetails struct {
 	Name          string
 	TestSuiteName string
@@ -12,6 +25,10 @@ type TestCaseDetails struct {
 	Passes   []TestCasePass
 	Failures []TestCaseFailure
 	Skips    []TestCaseSkip
+	// Flakes are the job runs that failed the test and then passed it on a rerun, they are also counted in Passes
+	Flakes []TestCaseFlake `json:",omitempty" yaml:",omitempty"`
+	// Unreadable are the job runs whose junit could not be read in time, they are neither passes nor failures
+	Unreadable []TestCaseUnreadable `json:",omitempty" yaml:",omitempty"`
 	//NeverExecuted []TestCaseNeverExecuted
 }
 
@@ -33,8 +50,120 @@ type TestCaseSkip struct {
 	GCSArtifactURL string
 }
 
+type TestCaseFlake struct {
+	JobRunID       string
+	HumanURL       string
+	GCSArtifactURL string
+}
+
+type TestCaseUnreadable struct {
+	JobRunID       string
+	HumanURL       string
+	GCSArtifactURL string
+}
+
 type TestCaseNeverExecuted struct {
 	JobRunID       string
 	HumanURL       string
 	GCSArtifactURL string
 }
+
+// UpdateTestCountsInSuite recounts the tests and failures of the suite tree from its test cases.
+func UpdateTestCountsInSuite(suite *junit.TestSuite) {
+	var numTests, numFailed uint
+	for _, test := range suite.TestCases {
+		numTests++
+		if test.FailureOutput != nil {
+			numFailed++
+		}
+	}
+	for _, child := range suite.Children {
+		UpdateTestCountsInSuite(child)
+		numTests += child.NumTests
+		numFailed += child.NumFailed
+	}
+	suite.NumTests = numTests
+	suite.NumFailed = numFailed
+}
+
+// SetTestCaseDetails stores the details as a JSON property of the test case, where tools like Sippy can parse them,
+// and replaces the SystemOut with a short summary for humans.
+func SetTestCaseDetails(testCase *junit.TestCase, details *TestCaseDetails) error {
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to marshal details of test case %q: %w", testCase.Name, err)
+	}
+	property := getTestCaseProperty(testCase, TestCaseDetailsPropertyName)
+	if property == nil {
+		property = &junit.TestSuiteProperty{Name: TestCaseDetailsPropertyName}
+		testCase.Properties = append(testCase.Properties, property)
+	}
+	property.Value = string(detailsJSON)
+	testCase.SystemOut = details.HumanSummary()
+	return nil
+}
+
+// GetTestCaseDetails reads the details stored by SetTestCaseDetails.  Test cases written before the details moved to
+// a property carry them as YAML in the SystemOut, those are still understood.
+func GetTestCaseDetails(testCase *junit.TestCase) (*TestCaseDetails, error) {
+	details := &TestCaseDetails{}
+	if property := getTestCaseProperty(testCase, TestCaseDetailsPropertyName); property != nil {
+		if err := json.Unmarshal([]byte(property.Value), details); err != nil {
+			return nil, fmt.Errorf("failed to parse details of test case %q: %w", testCase.Name, err)
+		}
+		return details, nil
+	}
+	if len(testCase.SystemOut) > 0 {
+		if err := yaml.Unmarshal([]byte(testCase.SystemOut), details); err != nil {
+			return nil, fmt.Errorf("failed to parse details of test case %q: %w", testCase.Name, err)
+		}
+	}
+	return details, nil
+}
+
+// GetReportOnlyVerdict returns the verdict the checker of the test case reached in report-only mode, or an empty
+// string when the checker gates.
+func GetReportOnlyVerdict(testCase *junit.TestCase) string {
+	if property := getTestCaseProperty(testCase, ReportOnlyVerdictPropertyName); property != nil {
+		return property.Value
+	}
+	return ""
+}
+
+// IsFailedTestCase tells whether the test case failed.  Some aggregated tests carry an empty failure, those are not
+// treated as failures.
+func IsFailedTestCase(testCase *junit.TestCase) bool {
+	return testCase.FailureOutput != nil && (len(testCase.FailureOutput.Message) > 0 || len(testCase.FailureOutput.Output) > 0)
+}
+
+func getTestCaseProperty(testCase *junit.TestCase, name string) *junit.TestSuiteProperty {
+	for _, property := range testCase.Properties {
+		if property.Name == name {
+			return property
+		}
+	}
+	return nil
+}
+
+// HumanSummary is a short description of the details, listing the failed job runs so they are easy to open.
+func (d *TestCaseDetails) HumanSummary() string {
+	sb := &strings.Builder{}
+	if len(d.Summary) > 0 {
+		fmt.Fprintf(sb, "%s\n", d.Summary)
+	}
+	fmt.Fprintf(sb, "passes: %d, failures: %d, skips: %d", len(d.Passes), len(d.Failures), len(d.Skips))
+	if len(d.Flakes) > 0 {
+		fmt.Fprintf(sb, ", flakes: %d", len(d.Flakes))
+	}
+	if len(d.Unreadable) > 0 {
+		fmt.Fprintf(sb, ", unreadable: %d", len(d.Unreadable))
+	}
+	sb.WriteString("\n")
+	if len(d.Failures) > 0 {
+		sb.WriteString("failed job runs:\n")
+		for _, failure := range d.Failures {
+			fmt.Fprintf(sb, "  %s\n", failure.HumanURL)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,51 @@
+package jobrunaggregatorlib
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+type JunitParseBudgetFlags struct {
+	MaxBytes       int
+	MaxDuration    time.Duration
+	MaxOutputBytes int
+}
+
+func NewJunitParseBudgetFlags() *JunitParseBudgetFlags {
+	return &JunitParseBudgetFlags{
+		MaxBytes:    jobrunaggregatorapi.DefaultJunitParseBudget.MaxBytes,
+		MaxDuration: jobrunaggregatorapi.DefaultJunitParseBudget.MaxDuration,
+	}
+}
+
+func (f *JunitParseBudgetFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&f.MaxBytes, "junit-max-bytes", f.MaxBytes, "The size a single junit file is parsed up to. Larger files are only partially parsed and get a skipped marker test case. 0 is unbounded.")
+	fs.DurationVar(&f.MaxDuration, "junit-parse-timeout", f.MaxDuration, "The time spent parsing a single junit file. Slower files are only partially parsed and get a skipped marker test case. 0 is unbounded.")
+	fs.IntVar(&f.MaxOutputBytes, "junit-max-output-bytes", f.MaxOutputBytes, "The size the system-out and system-err of every junit test case are truncated to. When set, junit files are also parsed while they are downloaded instead of being held in memory, and they bypass the artifact cache. 0 is unbounded.")
+}
+
+func (f *JunitParseBudgetFlags) Validate() error {
+	if f.MaxBytes < 0 {
+		return fmt.Errorf("--junit-max-bytes must not be negative")
+	}
+	if f.MaxDuration < 0 {
+		return fmt.Errorf("--junit-parse-timeout must not be negative")
+	}
+	if f.MaxOutputBytes < 0 {
+		return fmt.Errorf("--junit-max-output-bytes must not be negative")
+	}
+	return nil
+}
+
+// ToBudget returns the budget for the junit of the job runs read by the clients built from these flags.
+func (f *JunitParseBudgetFlags) ToBudget() jobrunaggregatorapi.JunitParseBudget {
+	return jobrunaggregatorapi.JunitParseBudget{
+		MaxBytes:       f.MaxBytes,
+		MaxDuration:    f.MaxDuration,
+		MaxOutputBytes: f.MaxOutputBytes,
+	}
+}
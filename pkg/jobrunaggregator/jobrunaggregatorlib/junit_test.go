@@ -0,0 +1,59 @@
+package jobrunaggregatorlib
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+func TestTestCaseDetails(t *testing.T) {
+	details := &TestCaseDetails{
+		Name:          "install should succeed: overall",
+		TestSuiteName: "cluster install",
+		Summary:       "Passed 1 times, failed 1 times.",
+		Passes:        []TestCasePass{{JobRunID: "1", HumanURL: "https://prow/1"}},
+		Failures:      []TestCaseFailure{{JobRunID: "2", HumanURL: "https://prow/2"}},
+	}
+	testCase := &junit.TestCase{Name: details.Name}
+	assert.NoError(t, SetTestCaseDetails(testCase, details))
+	// setting the details twice must not duplicate the property
+	assert.NoError(t, SetTestCaseDetails(testCase, details))
+
+	assert.Len(t, testCase.Properties, 1)
+	assert.Equal(t, "Passed 1 times, failed 1 times.\npasses: 1, failures: 1, skips: 0\nfailed job runs:\n  https://prow/2\n", testCase.SystemOut)
+
+	// the details must survive a round trip through the junit XML
+	junitXML, err := xml.Marshal(testCase)
+	assert.NoError(t, err)
+	roundTripped := &junit.TestCase{}
+	assert.NoError(t, xml.Unmarshal(junitXML, roundTripped))
+	actual, err := GetTestCaseDetails(roundTripped)
+	assert.NoError(t, err)
+	assert.Equal(t, details, actual)
+}
+
+func TestGetTestCaseDetailsFromYAMLSystemOut(t *testing.T) {
+	testCase := &junit.TestCase{
+		Name:      "install should succeed",
+		SystemOut: strings.Join([]string{"name: install should succeed", "failures:", "- jobrunid: \"2\""}, "\n"),
+	}
+	details, err := GetTestCaseDetails(testCase)
+	assert.NoError(t, err)
+	assert.Equal(t, "install should succeed", details.Name)
+	assert.Equal(t, []TestCaseFailure{{JobRunID: "2"}}, details.Failures)
+
+	details, err = GetTestCaseDetails(&junit.TestCase{Name: "empty"})
+	assert.NoError(t, err)
+	assert.Equal(t, &TestCaseDetails{}, details)
+}
+
+func TestIsFailedTestCase(t *testing.T) {
+	assert.False(t, IsFailedTestCase(&junit.TestCase{Name: "passed"}))
+	assert.False(t, IsFailedTestCase(&junit.TestCase{Name: "empty failure", FailureOutput: &junit.FailureOutput{}}))
+	assert.True(t, IsFailedTestCase(&junit.TestCase{Name: "failed", FailureOutput: &junit.FailureOutput{Message: "failed"}}))
+	assert.True(t, IsFailedTestCase(&junit.TestCase{Name: "failed without message", FailureOutput: &junit.FailureOutput{Output: "output"}}))
+}
@@ -0,0 +1,172 @@
+package jobrunaggregatorlib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+)
+
+const gcsURLScheme = "gs://"
+
+// LoadJobFlags switch the loaders from streaming inserts to load jobs, which are much cheaper for backfills
+type LoadJobFlags struct {
+	// StagingLocation is the gs://bucket/prefix the rows are written to before being loaded
+	StagingLocation string
+}
+
+func NewLoadJobFlags() *LoadJobFlags {
+	return &LoadJobFlags{}
+}
+
+func (f *LoadJobFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.StagingLocation, "load-job-staging-location", f.StagingLocation, "A gs://bucket/prefix to stage rows in as newline-delimited JSON, and load them into BigQuery with load jobs instead of streaming inserts. Every batch is a load job and a table only allows 1500 a day, so use it with a large --insert-batch-size.")
+}
+
+func (f *LoadJobFlags) Validate() error {
+	if !f.Enabled() {
+		return nil
+	}
+	if _, _, err := parseGCSURL(f.StagingLocation); err != nil {
+		return fmt.Errorf("--load-job-staging-location: %w", err)
+	}
+	return nil
+}
+
+func (f *LoadJobFlags) Enabled() bool {
+	return len(f.StagingLocation) > 0
+}
+
+// NewInserter returns an inserter loading the rows into table from the staging location
+func (f *LoadJobFlags) NewInserter(storageClient *storage.Client, table *bigquery.Table) (BigQueryInserter, error) {
+	bucket, prefix, err := parseGCSURL(f.StagingLocation)
+	if err != nil {
+		return nil, err
+	}
+	return NewLoadJobInserter(table, storageClient.Bucket(bucket), bucket, prefix), nil
+}
+
+// parseGCSURL splits a gs://bucket/prefix URL, the prefix is optional
+func parseGCSURL(gcsURL string) (string, string, error) {
+	bucketAndPrefix, ok := strings.CutPrefix(gcsURL, gcsURLScheme)
+	if !ok {
+		return "", "", fmt.Errorf("%q should look like %sbucket/prefix", gcsURL, gcsURLScheme)
+	}
+	bucket, prefix, _ := strings.Cut(bucketAndPrefix, "/")
+	if len(bucket) == 0 {
+		return "", "", fmt.Errorf("%q has no bucket", gcsURL)
+	}
+	return bucket, strings.Trim(prefix, "/"), nil
+}
+
+type loadJobInserter struct {
+	table         *bigquery.Table
+	stagingBucket *storage.BucketHandle
+	bucketName    string
+	prefix        string
+
+	// staged numbers the staged files, whose names must not collide when batches are put at once
+	staged atomic.Int64
+}
+
+// NewLoadJobInserter writes the rows of every Put to a newline-delimited JSON file under prefix, and waits for a load
+// job to append them to table.  The file is removed once loaded.
+func NewLoadJobInserter(table *bigquery.Table, stagingBucket *storage.BucketHandle, bucketName, prefix string) BigQueryInserter {
+	return &loadJobInserter{
+		table:         table,
+		stagingBucket: stagingBucket,
+		bucketName:    bucketName,
+		prefix:        prefix,
+	}
+}
+
+func (l *loadJobInserter) Put(ctx context.Context, src interface{}) error {
+	content, count, err := encodeRowsAsNDJSON(src)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	objectName := path.Join(l.prefix, l.table.TableID, fmt.Sprintf("%s-%d.json", time.Now().UTC().Format("20060102T150405"), l.staged.Add(1)))
+	logger := logrus.WithFields(logrus.Fields{"table": l.table.TableID, "rows": count, "object": objectName})
+	object := l.stagingBucket.Object(objectName)
+	writer := object.NewWriter(ctx)
+	writer.ContentType = "application/json"
+	if _, err := writer.Write(content); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to stage rows for %s: %w", l.table.TableID, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to stage rows for %s: %w", l.table.TableID, err)
+	}
+	defer func() {
+		if err := object.Delete(context.Background()); err != nil {
+			logger.WithError(err).Warn("failed to remove staged rows")
+		}
+	}()
+
+	gcsRef := bigquery.NewGCSReference(fmt.Sprintf("%s%s/%s", gcsURLScheme, l.bucketName, objectName))
+	gcsRef.SourceFormat = bigquery.JSON
+	loader := l.table.LoaderFrom(gcsRef)
+	loader.CreateDisposition = bigquery.CreateNever
+	loader.WriteDisposition = bigquery.WriteAppend
+
+	logger.Info("loading staged rows")
+	job, err := loader.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start loading rows into %s: %w", l.table.TableID, err)
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for load job %s: %w", job.ID(), err)
+	}
+	if err := status.Err(); err != nil {
+		return fmt.Errorf("load job %s failed: %w", job.ID(), err)
+	}
+	return nil
+}
+
+// encodeRowsAsNDJSON writes a row or a slice of rows as one JSON object per line, with the columns and values the
+// streaming inserts would send, and returns the number of rows
+func encodeRowsAsNDJSON(src interface{}) ([]byte, int, error) {
+	rows := []interface{}{src}
+	if srcVal := reflect.ValueOf(src); srcVal.Kind() == reflect.Slice {
+		rows = make([]interface{}, 0, srcVal.Len())
+		for i := 0; i < srcVal.Len(); i++ {
+			rows = append(rows, srcVal.Index(i).Interface())
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	for i, row := range rows {
+		saver, ok := row.(bigquery.ValueSaver)
+		if !ok {
+			schema, err := bigquery.InferSchema(row)
+			if err != nil {
+				return nil, 0, fmt.Errorf("row %d: %w", i, err)
+			}
+			saver = &bigquery.StructSaver{Struct: row, Schema: schema}
+		}
+		values, _, err := saver.Save()
+		if err != nil {
+			return nil, 0, fmt.Errorf("row %d: %w", i, err)
+		}
+		if err := encoder.Encode(values); err != nil {
+			return nil, 0, fmt.Errorf("row %d: %w", i, err)
+		}
+	}
+	return buf.Bytes(), len(rows), nil
+}
@@ -0,0 +1,42 @@
+package jobrunaggregatorlib
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func TestEncodeRowsAsNDJSON(t *testing.T) {
+	createdTime := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	content, count, err := encodeRowsAsNDJSON([]*jobrunaggregatorapi.BackendDisruptionRow{
+		{
+			BackendName:       "kube-api-new-connections",
+			DisruptionSeconds: 3,
+			JobName:           bigquery.NullString{StringVal: "periodic-e2e-aws", Valid: true},
+			JobRunName:        "1000",
+			JobRunStartTime:   bigquery.NullTimestamp{Timestamp: createdTime, Valid: true},
+		},
+		{
+			BackendName: "image-registry",
+			JobRunName:  "2000",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Contains(t, lines[0], `"JobName":"periodic-e2e-aws"`)
+		assert.Contains(t, lines[0], `"JobRunStartTime":"2023-10-01T12:00:00Z"`)
+		assert.Contains(t, lines[1], `"JobName":null`)
+	}
+
+	content, count, err = encodeRowsAsNDJSON(jobrunaggregatorapi.JobRunLoadExceptionRow{JobName: "periodic-e2e-aws", JobRunName: "1000", Action: "skip", CreatedTime: createdTime})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, `{"Action":"skip","CreatedTime":"2023-10-01T12:00:00Z","JobName":"periodic-e2e-aws","JobRunName":"1000","Reason":""}`+"\n", string(content))
+}
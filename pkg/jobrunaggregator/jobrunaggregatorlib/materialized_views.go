@@ -0,0 +1,197 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/sirupsen/logrus"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// MaterializedViewSpec describes a materialized view, which BigQuery keeps up to date with the tables it selects
+// from.  Its schema is the one of its query, so unlike a TableSpec it is not inferred from Row.
+type MaterializedViewSpec struct {
+	Name        string
+	Description string
+	// Query selects the rows of the view, DATA_SET_LOCATION stands for the dataset of the view.  BigQuery restricts
+	// it to aggregations it can update incrementally, so no CURRENT_TIMESTAMP, window functions or PERCENTILE_CONT.
+	Query string
+	// Row is the struct the rows of the view are read into, the query must select all of its columns
+	Row interface{}
+	// RefreshInterval is how often BigQuery refreshes the view at most.  Queries of a stale view still see the
+	// latest rows of its tables, they just scan more.
+	RefreshInterval time.Duration
+
+	// PartitionColumn partitions the view by day of this column, which must be a day of the partition column of
+	// the table it selects from.
+	PartitionColumn string
+	ClusterColumns  []string
+}
+
+// BackendDisruptionHistogramViewSpec counts the job runs by disruption seconds, so that the disruption statistics of a
+// job are computed from a few rows per day instead of from every job run with window functions.
+var BackendDisruptionHistogramViewSpec = MaterializedViewSpec{
+	Name:        jobrunaggregatorapi.BackendDisruptionHistogramViewName,
+	Description: "Job runs of the BackendDisruption table by job, backend, start day and seconds of disruption",
+	Query: `
+SELECT
+    JobName,
+    MasterNodesUpdated,
+    BackendName,
+    TIMESTAMP_TRUNC(JobRunStartTime, DAY) AS JobRunStartDay,
+    DisruptionSeconds,
+    COUNT(*) AS JobRuns
+FROM
+    DATA_SET_LOCATION.BackendDisruption
+GROUP BY
+    JobName, MasterNodesUpdated, BackendName, JobRunStartDay, DisruptionSeconds
+`,
+	Row:             jobrunaggregatorapi.BackendDisruptionHistogramRow{},
+	RefreshInterval: time.Hour,
+	PartitionColumn: "JobRunStartDay",
+	ClusterColumns:  []string{"JobName", "BackendName"},
+}
+
+// DeclaredMaterializedViewSpecs are the views created by create-tables after the tables they select from.
+func DeclaredMaterializedViewSpecs() []MaterializedViewSpec {
+	return []MaterializedViewSpec{BackendDisruptionHistogramViewSpec}
+}
+
+// NewMaterializedViewMetadata sets the query of the spec to select from the tables of dataCoordinates.
+func NewMaterializedViewMetadata(spec MaterializedViewSpec, dataCoordinates BigQueryDataCoordinates) *bigquery.TableMetadata {
+	metadata := &bigquery.TableMetadata{
+		Name:        spec.Name,
+		Description: spec.Description,
+		MaterializedView: &bigquery.MaterializedViewDefinition{
+			Query:           strings.TrimSpace(dataCoordinates.SubstituteDataSetLocation(spec.Query)),
+			EnableRefresh:   true,
+			RefreshInterval: spec.RefreshInterval,
+		},
+	}
+	if len(spec.PartitionColumn) > 0 {
+		metadata.TimePartitioning = &bigquery.TimePartitioning{
+			Type:  bigquery.DayPartitioningType,
+			Field: spec.PartitionColumn,
+		}
+	}
+	if len(spec.ClusterColumns) > 0 {
+		metadata.Clustering = &bigquery.Clustering{Fields: spec.ClusterColumns}
+	}
+	return metadata
+}
+
+func dataCoordinatesOf(dataSet *bigquery.Dataset) BigQueryDataCoordinates {
+	return BigQueryDataCoordinates{ProjectID: dataSet.ProjectID, DataSetID: dataSet.DatasetID}
+}
+
+// CreateOrReplaceMaterializedView creates the view of the spec when it is missing, and replaces it when its query
+// changed, since BigQuery can't change the query of a view.  Its rows are derived from its tables, so replacing it
+// loses nothing but the time BigQuery takes to compute them again.  The changes are printed to out, and only printed
+// when dryRun is set.
+func CreateOrReplaceMaterializedView(ctx context.Context, dataSet *bigquery.Dataset, spec MaterializedViewSpec, dryRun bool, out io.Writer) error {
+	logger := logrus.WithField("view", spec.Name)
+	declared := NewMaterializedViewMetadata(spec, dataCoordinatesOf(dataSet))
+
+	view := dataSet.Table(spec.Name)
+	live, err := view.Metadata(ctx)
+	if isNotFound(err) {
+		fmt.Fprintf(out, "%s: created\n", spec.Name)
+		if dryRun {
+			return nil
+		}
+		return view.Create(ctx, declared)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the metadata of %s: %w", spec.Name, err)
+	}
+	if live.MaterializedView == nil {
+		return fmt.Errorf("%s exists but is not a materialized view, it has to be removed by hand", spec.Name)
+	}
+
+	if live.MaterializedView.Query != declared.MaterializedView.Query {
+		fmt.Fprintf(out, "%s: ! query changed, replaced\n", spec.Name)
+		if dryRun {
+			return nil
+		}
+		if err := view.Delete(ctx); err != nil {
+			return fmt.Errorf("failed to delete %s to replace its query: %w", spec.Name, err)
+		}
+		return view.Create(ctx, declared)
+	}
+
+	if live.MaterializedView.EnableRefresh == declared.MaterializedView.EnableRefresh && live.MaterializedView.RefreshInterval == declared.MaterializedView.RefreshInterval {
+		logger.Info("view is up to date")
+		return nil
+	}
+	fmt.Fprintf(out, "%s: refreshed every %s\n", spec.Name, spec.RefreshInterval)
+	if dryRun {
+		return nil
+	}
+	if _, err := view.Update(ctx, bigquery.TableMetadataToUpdate{MaterializedView: declared.MaterializedView}, live.ETag); err != nil {
+		return fmt.Errorf("failed to update the refresh of %s: %w", spec.Name, err)
+	}
+	return nil
+}
+
+// CreateOrReplaceMaterializedViews creates or replaces the views of the specs in order.  A view failing doesn't keep
+// the others from being created.
+func CreateOrReplaceMaterializedViews(ctx context.Context, dataSet *bigquery.Dataset, specs []MaterializedViewSpec, dryRun bool, out io.Writer) error {
+	errs := []error{}
+	for _, spec := range specs {
+		if err := CreateOrReplaceMaterializedView(ctx, dataSet, spec, dryRun, out); err != nil {
+			logrus.WithError(err).WithField("view", spec.Name).Error("failed to create or replace view")
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// VerifyMaterializedView prints how the view of the spec differs from its declaration, and tells whether it drifted:
+// it is missing, its query changed, or its columns don't match Row, which fails reading its rows.
+func VerifyMaterializedView(ctx context.Context, dataSet *bigquery.Dataset, spec MaterializedViewSpec, out io.Writer) (bool, error) {
+	declared := NewMaterializedViewMetadata(spec, dataCoordinatesOf(dataSet))
+	schema, err := bigquery.InferSchema(spec.Row)
+	if err != nil {
+		return false, fmt.Errorf("failed to infer the schema of %s: %w", spec.Name, err)
+	}
+	live, err := dataSet.Table(spec.Name).Metadata(ctx)
+	if isNotFound(err) {
+		fmt.Fprintf(out, "%s: ! view is missing\n", spec.Name)
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read the metadata of %s: %w", spec.Name, err)
+	}
+	if live.MaterializedView == nil {
+		fmt.Fprintf(out, "%s: ! not a materialized view\n", spec.Name)
+		return true, nil
+	}
+
+	drifted := false
+	if live.MaterializedView.Query != declared.MaterializedView.Query {
+		fmt.Fprintf(out, "%s: ! query differs from the declared one\n", spec.Name)
+		drifted = true
+	}
+	// BigQuery derives the modes of the columns from the query, reading Row only needs the columns to be there
+	diff := DiffTableSchema(nullableSchema(live.Schema), nullableSchema(schema))
+	diff.Print(out, spec.Name)
+	printPartitioningDiff(out, spec.Name, live, declared)
+	return drifted || diff.Drifted(), nil
+}
+
+func nullableSchema(schema bigquery.Schema) bigquery.Schema {
+	nullable := bigquery.Schema{}
+	for _, field := range schema {
+		nullableField := *field
+		nullableField.Required = false
+		nullable = append(nullable, &nullableField)
+	}
+	return nullable
+}
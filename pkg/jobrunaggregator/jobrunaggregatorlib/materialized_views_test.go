@@ -0,0 +1,38 @@
+package jobrunaggregatorlib
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMaterializedViewMetadata(t *testing.T) {
+	metadata := NewMaterializedViewMetadata(BackendDisruptionHistogramViewSpec, BigQueryDataCoordinates{ProjectID: "project", DataSetID: "data_set"})
+
+	assert.True(t, strings.HasPrefix(metadata.MaterializedView.Query, "SELECT"), "the query is trimmed so that it compares with the stored one")
+	assert.Contains(t, metadata.MaterializedView.Query, "project.data_set.BackendDisruption")
+	assert.True(t, metadata.MaterializedView.EnableRefresh)
+	assert.Equal(t, time.Hour, metadata.MaterializedView.RefreshInterval)
+	assert.Equal(t, &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: "JobRunStartDay"}, metadata.TimePartitioning)
+	assert.Equal(t, &bigquery.Clustering{Fields: []string{"JobName", "BackendName"}}, metadata.Clustering)
+	assert.Nil(t, metadata.Schema, "the schema of a view comes from its query")
+}
+
+// TestDeclaredMaterializedViewSpecs keeps the queries in sync with the row types they are read into
+func TestDeclaredMaterializedViewSpecs(t *testing.T) {
+	for _, spec := range DeclaredMaterializedViewSpecs() {
+		schema, err := bigquery.InferSchema(spec.Row)
+		if err != nil {
+			t.Fatalf("%s: %v", spec.Name, err)
+		}
+		for _, field := range schema {
+			assert.Contains(t, spec.Query, field.Name, "%s: column %s is not selected", spec.Name, field.Name)
+		}
+		for _, column := range append([]string{spec.PartitionColumn}, spec.ClusterColumns...) {
+			assert.NotNil(t, findField(schema, column), "%s: %s is not one of its columns", spec.Name, column)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package jobrunaggregatorlib
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/expfmt"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+)
+
+// MetricsFlags expose the metrics of an analyzer.  Analyzers don't live long enough to be scraped reliably, so their
+// metrics can be pushed to a Prometheus pushgateway once the analysis is over instead of, or as well as, served.
+type MetricsFlags struct {
+	Port           int
+	PushGatewayURL string
+}
+
+func NewMetricsFlags() *MetricsFlags {
+	return &MetricsFlags{}
+}
+
+func (f *MetricsFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&f.Port, "metrics-port", f.Port, "When set, metrics are served on this port at /metrics for as long as the analysis runs")
+	fs.StringVar(&f.PushGatewayURL, "metrics-pushgateway-url", f.PushGatewayURL, "When set, like http://pushgateway:9091, metrics are pushed to this Prometheus pushgateway once the analysis is over")
+}
+
+func (f *MetricsFlags) Validate() error {
+	if f.Port < 0 || f.Port > 65535 {
+		return fmt.Errorf("--metrics-port must be between 0 and 65535")
+	}
+	if len(f.PushGatewayURL) > 0 {
+		pushGatewayURL, err := url.Parse(f.PushGatewayURL)
+		if err != nil {
+			return fmt.Errorf("invalid --metrics-pushgateway-url: %w", err)
+		}
+		if pushGatewayURL.Scheme != "http" && pushGatewayURL.Scheme != "https" {
+			return fmt.Errorf("--metrics-pushgateway-url must be an http or https URL")
+		}
+	}
+	return nil
+}
+
+// ToMetricsExporter starts serving metrics when a port is set, then returns the exporter pushing them under the job
+// name.  It returns nil when metrics are not pushed.
+func (f *MetricsFlags) ToMetricsExporter(job string) *MetricsExporter {
+	if f.Port > 0 {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		server := &http.Server{Addr: ":" + strconv.Itoa(f.Port), Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logrus.WithError(err).Warn("failed to serve metrics")
+			}
+		}()
+	}
+	if len(f.PushGatewayURL) == 0 {
+		return nil
+	}
+	return &MetricsExporter{
+		pushGatewayURL: strings.TrimSuffix(f.PushGatewayURL, "/"),
+		job:            job,
+		gatherer:       prometheus.DefaultGatherer,
+		client:         &http.Client{Timeout: time.Minute},
+	}
+}
+
+// MetricsExporter pushes the metrics of the process to a Prometheus pushgateway.
+type MetricsExporter struct {
+	pushGatewayURL string
+	job            string
+	gatherer       prometheus.Gatherer
+	client         *http.Client
+}
+
+// Push replaces the metrics the pushgateway holds for this job and host with the current ones.  Every pod running
+// an analyzer is its own instance, so concurrent analyses don't overwrite each other.
+func (e *MetricsExporter) Push(ctx context.Context) error {
+	if e == nil {
+		return nil
+	}
+	metricFamilies, err := e.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("failed to gather metrics: %w", err)
+	}
+	buf := &bytes.Buffer{}
+	encoder := expfmt.NewEncoder(buf, expfmt.FmtText)
+	for _, metricFamily := range metricFamilies {
+		if err := encoder.Encode(metricFamily); err != nil {
+			return fmt.Errorf("failed to encode metrics: %w", err)
+		}
+	}
+
+	instance, err := os.Hostname()
+	if err != nil || len(instance) == 0 {
+		instance = "unknown"
+	}
+	pushURL := fmt.Sprintf("%s/metrics/job/%s/instance/%s", e.pushGatewayURL, url.PathEscape(e.job), url.PathEscape(instance))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, pushURL, buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", string(expfmt.FmtText))
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to push metrics to %s: %s", pushURL, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsExporterPush(t *testing.T) {
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		content, _ := io.ReadAll(r.Body)
+		body = string(content)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "jobrunaggregator_test_total", Help: "test"})
+	registry.MustRegister(counter)
+	counter.Inc()
+
+	exporter := (&MetricsFlags{PushGatewayURL: server.URL + "/"}).ToMetricsExporter("analyze-test-case")
+	exporter.gatherer = registry
+	assert.NoError(t, exporter.Push(context.TODO()))
+	assert.Equal(t, http.MethodPut, method)
+	assert.True(t, strings.HasPrefix(path, "/metrics/job/analyze-test-case/instance/"), path)
+	assert.Contains(t, body, "jobrunaggregator_test_total 1")
+
+	var disabled *MetricsExporter
+	assert.NoError(t, disabled.Push(context.TODO()), "metrics are not pushed without a pushgateway")
+	assert.Nil(t, (&MetricsFlags{}).ToMetricsExporter("analyze-test-case"))
+}
+
+func TestMetricsFlagsValidate(t *testing.T) {
+	assert.NoError(t, (&MetricsFlags{Port: 8080, PushGatewayURL: "http://pushgateway:9091"}).Validate())
+	assert.Error(t, (&MetricsFlags{Port: -1}).Validate())
+	assert.Error(t, (&MetricsFlags{PushGatewayURL: "pushgateway:9091"}).Validate())
+}
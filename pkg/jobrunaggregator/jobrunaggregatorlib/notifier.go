@@ -0,0 +1,245 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// PayloadVerdict is the outcome of the analysis of a payload, as told to every Notifier.
+type PayloadVerdict struct {
+	// Analyzer is the command that produced the verdict, like analyze-job-runs or analyze-test-case.
+	Analyzer string `json:"analyzer"`
+	// JobName is empty for analyses spanning multiple jobs.
+	JobName             string              `json:"jobName,omitempty"`
+	PayloadTag          string              `json:"payloadTag,omitempty"`
+	PayloadInvocationID string              `json:"payloadInvocationID,omitempty"`
+	AnalyzedTime        time.Time           `json:"analyzedTime"`
+	Passed              bool                `json:"passed"`
+	Tests               []PayloadTestResult `json:"tests"`
+}
+
+type PayloadTestResult struct {
+	TestSuiteName string `json:"testSuiteName"`
+	TestName      string `json:"testName"`
+	Passed        bool   `json:"passed"`
+	Passes        int    `json:"passes"`
+	Failures      int    `json:"failures"`
+	Skips         int    `json:"skips"`
+	// FailedJobRunURLs link to the job runs the test failed in
+	FailedJobRunURLs []string `json:"failedJobRunURLs,omitempty"`
+}
+
+// Subject is a one line summary of the verdict.
+func (v *PayloadVerdict) Subject() string {
+	outcome := "passed"
+	if !v.Passed {
+		outcome = "failed"
+	}
+	target := v.PayloadTag
+	if len(target) == 0 {
+		target = v.PayloadInvocationID
+	}
+	if len(v.JobName) > 0 {
+		target = fmt.Sprintf("%s for %s", target, v.JobName)
+	}
+	return fmt.Sprintf("%s %s %s", v.Analyzer, outcome, target)
+}
+
+// FailedTests lists the tests that failed the analysis.
+func (v *PayloadVerdict) FailedTests() []PayloadTestResult {
+	ret := []PayloadTestResult{}
+	for _, test := range v.Tests {
+		if !test.Passed {
+			ret = append(ret, test)
+		}
+	}
+	return ret
+}
+
+// PayloadTestResultsFromSuite lists the result of every test case in the suite tree.  Pass counts come from the
+// TestCaseDetails when there are some.
+func PayloadTestResultsFromSuite(suite *junit.TestSuite) []PayloadTestResult {
+	ret := []PayloadTestResult{}
+	addPayloadTestResults(nil, suite, &ret)
+	return ret
+}
+
+func addPayloadTestResults(parents []string, suite *junit.TestSuite, results *[]PayloadTestResult) {
+	suiteNames := parents
+	if len(suite.Name) > 0 {
+		suiteNames = append(append([]string{}, parents...), suite.Name)
+	}
+	for _, testCase := range suite.TestCases {
+		result := PayloadTestResult{
+			TestSuiteName: strings.Join(suiteNames, TestSuitesSeparator),
+			TestName:      testCase.Name,
+			Passed:        !IsFailedTestCase(testCase),
+		}
+		if details, err := GetTestCaseDetails(testCase); err == nil {
+			result.Passes = len(details.Passes)
+			result.Failures = len(details.Failures)
+			result.Skips = len(details.Skips)
+			for _, failure := range details.Failures {
+				if len(failure.HumanURL) > 0 {
+					result.FailedJobRunURLs = append(result.FailedJobRunURLs, failure.HumanURL)
+				}
+			}
+		}
+		*results = append(*results, result)
+	}
+	for _, child := range suite.Children {
+		addPayloadTestResults(suiteNames, child, results)
+	}
+}
+
+// Notifier is told the verdict at the end of every analysis.  Notifications are informational, a failing Notifier
+// must not change the verdict.
+type Notifier interface {
+	Notify(ctx context.Context, verdict *PayloadVerdict) error
+}
+
+// Notifiers tells every Notifier, even when some of them fail.
+type Notifiers []Notifier
+
+func (n Notifiers) Notify(ctx context.Context, verdict *PayloadVerdict) error {
+	errs := []error{}
+	for _, notifier := range n {
+		if err := notifier.Notify(ctx, verdict); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// failuresOnlyNotifier only tells its delegate about failed verdicts.
+type failuresOnlyNotifier struct {
+	delegate Notifier
+}
+
+func (n failuresOnlyNotifier) Notify(ctx context.Context, verdict *PayloadVerdict) error {
+	if verdict.Passed {
+		return nil
+	}
+	return n.delegate.Notify(ctx, verdict)
+}
+
+type NotifierFlags struct {
+	SippyEndpoint string
+
+	WebhookURLs []string
+	// SlackWebhookURLFile holds a Slack incoming webhook URL, which is a secret
+	SlackWebhookURLFile string
+	// SlackTokenFile holds the token of a Slack app allowed to post to SlackChannel
+	SlackTokenFile string
+	SlackChannel   string
+
+	EmailTo              []string
+	EmailFrom            string
+	SMTPServer           string
+	SMTPUsername         string
+	SMTPPasswordFile     string
+	NotifyOnlyOnFailures bool
+}
+
+func NewNotifierFlags() *NotifierFlags {
+	return &NotifierFlags{}
+}
+
+func (f *NotifierFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.SippyEndpoint, "sippy-endpoint", f.SippyEndpoint, "The optional Sippy ingestion URL the verdict and per-test pass counts are posted to after the analysis")
+	fs.StringSliceVar(&f.WebhookURLs, "notify-webhook-url", f.WebhookURLs, "A URL the JSON verdict is posted to after the analysis. Can be repeated.")
+	fs.StringVar(&f.SlackWebhookURLFile, "notify-slack-webhook-url-file", f.SlackWebhookURLFile, "The optional path to a file containing a Slack incoming webhook URL the verdict is summarized to after the analysis")
+	fs.StringVar(&f.SlackTokenFile, "notify-slack-token-file", f.SlackTokenFile, "The optional path to a file containing the token of a Slack app the verdict is summarized to --notify-slack-channel with after the analysis")
+	fs.StringVar(&f.SlackChannel, "notify-slack-channel", f.SlackChannel, "The Slack channel, like the TRT channel, the verdict is posted to with --notify-slack-token-file")
+	fs.StringSliceVar(&f.EmailTo, "notify-email-to", f.EmailTo, "An email address the verdict is mailed to after the analysis. Can be repeated. Requires --notify-smtp-server and --notify-email-from.")
+	fs.StringVar(&f.EmailFrom, "notify-email-from", f.EmailFrom, "The sender of verdict emails")
+	fs.StringVar(&f.SMTPServer, "notify-smtp-server", f.SMTPServer, "The host:port of the SMTP server verdict emails are sent through")
+	fs.StringVar(&f.SMTPUsername, "notify-smtp-username", f.SMTPUsername, "The optional user to authenticate to the SMTP server as")
+	fs.StringVar(&f.SMTPPasswordFile, "notify-smtp-password-file", f.SMTPPasswordFile, "The path to a file containing the password of --notify-smtp-username")
+	fs.BoolVar(&f.NotifyOnlyOnFailures, "notify-only-on-failures", f.NotifyOnlyOnFailures, "Only notify the webhook, Slack and email targets when the analysis failed. Sippy is always told.")
+}
+
+func (f *NotifierFlags) Validate() error {
+	for _, webhookURL := range append([]string{f.SippyEndpoint}, f.WebhookURLs...) {
+		if len(webhookURL) == 0 {
+			continue
+		}
+		if _, err := url.ParseRequestURI(webhookURL); err != nil {
+			return fmt.Errorf("invalid notification URL %q: %w", webhookURL, err)
+		}
+	}
+	if (len(f.SlackTokenFile) > 0) != (len(f.SlackChannel) > 0) {
+		return fmt.Errorf("--notify-slack-token-file and --notify-slack-channel must be specified together")
+	}
+	if len(f.EmailTo) > 0 {
+		if len(f.SMTPServer) == 0 || len(f.EmailFrom) == 0 {
+			return fmt.Errorf("--notify-email-to requires --notify-smtp-server and --notify-email-from")
+		}
+		for _, address := range append([]string{f.EmailFrom}, f.EmailTo...) {
+			if _, err := mail.ParseAddress(address); err != nil {
+				return fmt.Errorf("invalid email address %q: %w", address, err)
+			}
+		}
+	}
+	if len(f.SMTPUsername) > 0 && len(f.SMTPPasswordFile) == 0 {
+		return fmt.Errorf("--notify-smtp-username requires --notify-smtp-password-file")
+	}
+	return nil
+}
+
+// ToNotifier returns a Notifier telling every configured target.
+func (f *NotifierFlags) ToNotifier() (Notifier, error) {
+	targets := Notifiers{}
+	for _, webhookURL := range f.WebhookURLs {
+		targets = append(targets, newWebhookNotifier(webhookURL))
+	}
+	if len(f.SlackWebhookURLFile) > 0 {
+		slackWebhookURL, err := os.ReadFile(f.SlackWebhookURLFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the Slack webhook URL: %w", err)
+		}
+		targets = append(targets, newSlackNotifier(strings.TrimSpace(string(slackWebhookURL))))
+	}
+	if len(f.SlackTokenFile) > 0 {
+		slackToken, err := os.ReadFile(f.SlackTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the Slack token: %w", err)
+		}
+		targets = append(targets, newSlackChannelNotifier(strings.TrimSpace(string(slackToken)), f.SlackChannel))
+	}
+	if len(f.EmailTo) > 0 {
+		var password string
+		if len(f.SMTPPasswordFile) > 0 {
+			passwordBytes, err := os.ReadFile(f.SMTPPasswordFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the SMTP password: %w", err)
+			}
+			password = strings.TrimSpace(string(passwordBytes))
+		}
+		targets = append(targets, newEmailNotifier(f.SMTPServer, f.SMTPUsername, password, f.EmailFrom, f.EmailTo))
+	}
+
+	ret := Notifiers{}
+	if sippyExporter := NewSippyExporter(f.SippyEndpoint); sippyExporter != nil {
+		ret = append(ret, sippyExporter)
+	}
+	if len(targets) > 0 {
+		if f.NotifyOnlyOnFailures {
+			ret = append(ret, failuresOnlyNotifier{delegate: targets})
+		} else {
+			ret = append(ret, targets...)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,159 @@
+package jobrunaggregatorlib
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// webhookNotifier posts the verdict as JSON, for receivers that do their own formatting.
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhookNotifier(url string) *webhookNotifier {
+	return &webhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: time.Minute},
+	}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, verdict *PayloadVerdict) error {
+	return postJSON(ctx, n.client, n.url, "webhook", verdict)
+}
+
+// slackNotifier posts a short summary of the verdict to a Slack incoming webhook.
+type slackNotifier struct {
+	webhookURL string
+	client     *http.Client
+}
+
+func newSlackNotifier(webhookURL string) *slackNotifier {
+	return &slackNotifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: time.Minute},
+	}
+}
+
+const (
+	// maxListedFailedTests keeps Slack messages and emails readable when most tests failed.
+	maxListedFailedTests = 10
+	// maxListedFailedJobRuns is the number of failed job runs linked per failed test
+	maxListedFailedJobRuns = 5
+)
+
+func (n *slackNotifier) Notify(ctx context.Context, verdict *PayloadVerdict) error {
+	return postJSON(ctx, n.client, n.webhookURL, "slack", map[string]string{"text": slackMessage(verdict)})
+}
+
+// slackPoster is the part of the Slack client the slackChannelNotifier uses
+type slackPoster interface {
+	PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error)
+}
+
+// slackChannelNotifier posts the same summary as the slackNotifier to a channel as a Slack app, for workspaces
+// where incoming webhooks are not allowed.
+type slackChannelNotifier struct {
+	client  slackPoster
+	channel string
+}
+
+func newSlackChannelNotifier(token, channel string) *slackChannelNotifier {
+	return &slackChannelNotifier{
+		client:  slack.New(token),
+		channel: channel,
+	}
+}
+
+func (n *slackChannelNotifier) Notify(ctx context.Context, verdict *PayloadVerdict) error {
+	if _, _, err := n.client.PostMessageContext(ctx, n.channel, slack.MsgOptionText(slackMessage(verdict), false)); err != nil {
+		return fmt.Errorf("failed to post verdict to slack channel %s: %w", n.channel, err)
+	}
+	return nil
+}
+
+func slackMessage(verdict *PayloadVerdict) string {
+	icon := ":white_check_mark:"
+	if !verdict.Passed {
+		icon = ":x:"
+	}
+	message := &bytes.Buffer{}
+	fmt.Fprintf(message, "%s %s", icon, verdict.Subject())
+	writeFailedTests(message, verdict, "\n", "• `%s`", "    <%s|%s>")
+	return message.String()
+}
+
+// emailNotifier mails the verdict through an SMTP server.
+type emailNotifier struct {
+	server string
+	auth   smtp.Auth
+	from   string
+	to     []string
+	// sendMail is smtp.SendMail outside of unit tests
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func newEmailNotifier(server, username, password, from string, to []string) *emailNotifier {
+	ret := &emailNotifier{
+		server:   server,
+		from:     from,
+		to:       to,
+		sendMail: smtp.SendMail,
+	}
+	if len(username) > 0 {
+		host := server
+		if i := strings.LastIndex(server, ":"); i >= 0 {
+			host = server[:i]
+		}
+		ret.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return ret
+}
+
+func (n *emailNotifier) Notify(ctx context.Context, verdict *PayloadVerdict) error {
+	message := &bytes.Buffer{}
+	fmt.Fprintf(message, "From: %s\r\n", n.from)
+	fmt.Fprintf(message, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(message, "Subject: %s\r\n", verdict.Subject())
+	fmt.Fprintf(message, "Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	fmt.Fprintf(message, "%s at %s.\r\n", verdict.Subject(), verdict.AnalyzedTime.UTC().Format(time.RFC3339))
+	writeFailedTests(message, verdict, "\r\n", "  %s", "    %[1]s")
+	if err := n.sendMail(n.server, n.auth, n.from, n.to, message.Bytes()); err != nil {
+		return fmt.Errorf("failed to email verdict: %w", err)
+	}
+	return nil
+}
+
+// writeFailedTests lists the failed test names, each formatted with testFormat on its own line, followed by the job runs
+// they failed in, each formatted with jobRunFormat from the URL and the ID of the job run.
+func writeFailedTests(message *bytes.Buffer, verdict *PayloadVerdict, newline, testFormat, jobRunFormat string) {
+	failedTests := verdict.FailedTests()
+	if len(failedTests) == 0 {
+		return
+	}
+	fmt.Fprintf(message, "%s%d failed tests:", newline, len(failedTests))
+	for i, test := range failedTests {
+		if i == maxListedFailedTests {
+			fmt.Fprintf(message, "%s…and %d more", newline, len(failedTests)-maxListedFailedTests)
+			break
+		}
+		message.WriteString(newline)
+		fmt.Fprintf(message, testFormat, test.TestName)
+		for j, jobRunURL := range test.FailedJobRunURLs {
+			if j == maxListedFailedJobRuns {
+				fmt.Fprintf(message, "%s…and %d more job runs", newline, len(test.FailedJobRunURLs)-maxListedFailedJobRuns)
+				break
+			}
+			message.WriteString(newline)
+			fmt.Fprintf(message, jobRunFormat, jobRunURL, path.Base(jobRunURL))
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNotifier struct {
+	verdicts []*PayloadVerdict
+	err      error
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, verdict *PayloadVerdict) error {
+	n.verdicts = append(n.verdicts, verdict)
+	return n.err
+}
+
+func failedVerdict() *PayloadVerdict {
+	return &PayloadVerdict{
+		Analyzer:     "analyze-job-runs",
+		JobName:      "periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn-upgrade",
+		PayloadTag:   "4.15.0-0.ci-2023-10-01-000000",
+		AnalyzedTime: time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC),
+		Passed:       false,
+		Tests: []PayloadTestResult{
+			{TestSuiteName: "upgrade", TestName: "cluster upgrade should succeed", Passed: true},
+			{TestSuiteName: "upgrade", TestName: "disruption/kube-api should be available", Passed: false},
+		},
+	}
+}
+
+func TestNotifiersNotifyEveryTarget(t *testing.T) {
+	failing := &fakeNotifier{err: fmt.Errorf("unreachable")}
+	working := &fakeNotifier{}
+	verdict := failedVerdict()
+
+	assert.EqualError(t, Notifiers{failing, working}.Notify(context.TODO(), verdict), "unreachable")
+	assert.Equal(t, []*PayloadVerdict{verdict}, failing.verdicts)
+	assert.Equal(t, []*PayloadVerdict{verdict}, working.verdicts)
+
+	assert.NoError(t, Notifiers{}.Notify(context.TODO(), verdict))
+}
+
+func TestFailuresOnlyNotifier(t *testing.T) {
+	delegate := &fakeNotifier{}
+	notifier := failuresOnlyNotifier{delegate: delegate}
+
+	passed := failedVerdict()
+	passed.Passed = true
+	assert.NoError(t, notifier.Notify(context.TODO(), passed))
+	assert.Empty(t, delegate.verdicts)
+
+	failed := failedVerdict()
+	assert.NoError(t, notifier.Notify(context.TODO(), failed))
+	assert.Equal(t, []*PayloadVerdict{failed}, delegate.verdicts)
+}
+
+func TestSlackNotifier(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	assert.NoError(t, newSlackNotifier(server.URL).Notify(context.TODO(), failedVerdict()))
+	assert.Equal(t, map[string]string{
+		"text": ":x: analyze-job-runs failed 4.15.0-0.ci-2023-10-01-000000 for periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn-upgrade\n" +
+			"1 failed tests:\n" +
+			"• `disruption/kube-api should be available`",
+	}, received)
+}
+
+type fakeSlackPoster struct {
+	channel string
+	text    string
+}
+
+func (p *fakeSlackPoster) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	p.channel = channelID
+	_, values, err := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+	p.text = values.Get("text")
+	return "", "", err
+}
+
+func TestSlackChannelNotifier(t *testing.T) {
+	poster := &fakeSlackPoster{}
+	notifier := &slackChannelNotifier{client: poster, channel: "#forum-trt"}
+	verdict := failedVerdict()
+	verdict.Tests[1].FailedJobRunURLs = []string{
+		"https://prow.ci.openshift.org/view/gs/test-platform-results/logs/periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn-upgrade/1",
+		"https://prow.ci.openshift.org/view/gs/test-platform-results/logs/periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn-upgrade/2",
+	}
+
+	assert.NoError(t, notifier.Notify(context.TODO(), verdict))
+	assert.Equal(t, "#forum-trt", poster.channel)
+	assert.Equal(t, ":x: analyze-job-runs failed 4.15.0-0.ci-2023-10-01-000000 for periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn-upgrade\n"+
+		"1 failed tests:\n"+
+		"• `disruption/kube-api should be available`\n"+
+		"    <https://prow.ci.openshift.org/view/gs/test-platform-results/logs/periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn-upgrade/1|1>\n"+
+		"    <https://prow.ci.openshift.org/view/gs/test-platform-results/logs/periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn-upgrade/2|2>", poster.text)
+}
+
+func TestEmailNotifier(t *testing.T) {
+	notifier := newEmailNotifier("smtp.example.com:587", "", "", "ci@example.com", []string{"trt@example.com", "release@example.com"})
+	var sentTo []string
+	var sentMessage string
+	notifier.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Equal(t, "smtp.example.com:587", addr)
+		assert.Nil(t, a)
+		sentTo = to
+		sentMessage = string(msg)
+		return nil
+	}
+
+	assert.NoError(t, notifier.Notify(context.TODO(), failedVerdict()))
+	assert.Equal(t, []string{"trt@example.com", "release@example.com"}, sentTo)
+	assert.Equal(t, "From: ci@example.com\r\n"+
+		"To: trt@example.com, release@example.com\r\n"+
+		"Subject: analyze-job-runs failed 4.15.0-0.ci-2023-10-01-000000 for periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn-upgrade\r\n"+
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n"+
+		"analyze-job-runs failed 4.15.0-0.ci-2023-10-01-000000 for periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn-upgrade at 2023-10-01T12:00:00Z.\r\n"+
+		"\r\n1 failed tests:\r\n"+
+		"  disruption/kube-api should be available", sentMessage)
+
+	notifier.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		return fmt.Errorf("connection refused")
+	}
+	assert.EqualError(t, notifier.Notify(context.TODO(), failedVerdict()), "failed to email verdict: connection refused")
+}
+
+func TestNotifierFlagsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   NotifierFlags
+		wantErr string
+	}{
+		{
+			name:  "nothing configured",
+			flags: NotifierFlags{},
+		},
+		{
+			name:  "webhook and email",
+			flags: NotifierFlags{WebhookURLs: []string{"https://example.com/hook"}, EmailTo: []string{"trt@example.com"}, EmailFrom: "ci@example.com", SMTPServer: "smtp.example.com:587"},
+		},
+		{
+			name:    "invalid webhook",
+			flags:   NotifierFlags{WebhookURLs: []string{"example"}},
+			wantErr: `invalid notification URL "example": parse "example": invalid URI for request`,
+		},
+		{
+			name:    "email without server",
+			flags:   NotifierFlags{EmailTo: []string{"trt@example.com"}, EmailFrom: "ci@example.com"},
+			wantErr: "--notify-email-to requires --notify-smtp-server and --notify-email-from",
+		},
+		{
+			name:    "invalid email",
+			flags:   NotifierFlags{EmailTo: []string{"trt"}, EmailFrom: "ci@example.com", SMTPServer: "smtp.example.com:587"},
+			wantErr: `invalid email address "trt": mail: missing '@' or angle-addr`,
+		},
+		{
+			name:    "slack channel without token",
+			flags:   NotifierFlags{SlackChannel: "#forum-trt"},
+			wantErr: "--notify-slack-token-file and --notify-slack-channel must be specified together",
+		},
+		{
+			name:    "username without password",
+			flags:   NotifierFlags{SMTPUsername: "ci"},
+			wantErr: "--notify-smtp-username requires --notify-smtp-password-file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.flags.Validate()
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
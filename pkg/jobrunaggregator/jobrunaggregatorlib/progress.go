@@ -0,0 +1,127 @@
+package jobrunaggregatorlib
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+// ProgressReporter tells whoever runs an analysis how far along it is.
+type ProgressReporter interface {
+	// JobsLocated is called once the jobs to analyze are known.
+	JobsLocated(jobs int)
+	// JobRunsStatus is called every time the job runs are checked for completion.
+	JobRunsStatus(finished, pending int)
+	// CheckerStatus is called when a checker starts or finishes.
+	CheckerStatus(checker, status string)
+}
+
+// NewProgressReporter renders a live progress view when out is a terminal, and logs progress otherwise so that
+// non-interactive runs keep plain structured logs.  The live view takes over the output of logrus, which is expected
+// to write to the same terminal, so that log lines are printed above the view instead of through it.
+func NewProgressReporter(out *os.File) ProgressReporter {
+	if term.IsTerminal(int(out.Fd())) {
+		reporter := &terminalProgressReporter{out: out, started: time.Now(), checkers: map[string]string{}}
+		logrus.SetOutput(reporter)
+		return reporter
+	}
+	return logProgressReporter{}
+}
+
+type logProgressReporter struct{}
+
+func (logProgressReporter) JobsLocated(jobs int) {
+	logrus.WithField("jobs", jobs).Info("located jobs")
+}
+
+func (logProgressReporter) JobRunsStatus(finished, pending int) {
+	logrus.WithFields(logrus.Fields{"finished": finished, "pending": pending}).Info("job run status")
+}
+
+func (logProgressReporter) CheckerStatus(checker, status string) {
+	logrus.WithFields(logrus.Fields{"checker": checker, "status": status}).Info("checker status")
+}
+
+// terminalProgressReporter redraws a small status block in place on every update.
+type terminalProgressReporter struct {
+	lock          sync.Mutex
+	out           io.Writer
+	started       time.Time
+	renderedLines int
+
+	jobs              int
+	finished, pending int
+	checkers          map[string]string
+}
+
+func (r *terminalProgressReporter) JobsLocated(jobs int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.jobs = jobs
+	r.render()
+}
+
+func (r *terminalProgressReporter) JobRunsStatus(finished, pending int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.finished, r.pending = finished, pending
+	r.render()
+}
+
+func (r *terminalProgressReporter) CheckerStatus(checker, status string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.checkers[checker] = status
+	r.render()
+}
+
+func (r *terminalProgressReporter) render() {
+	lines := []string{
+		fmt.Sprintf("elapsed: %s", time.Since(r.started).Round(time.Second)),
+		fmt.Sprintf("jobs located: %d", r.jobs),
+		fmt.Sprintf("job runs: %d finished, %d pending", r.finished, r.pending),
+	}
+	checkers := make([]string, 0, len(r.checkers))
+	for checker := range r.checkers {
+		checkers = append(checkers, checker)
+	}
+	sort.Strings(checkers)
+	for _, checker := range checkers {
+		lines = append(lines, fmt.Sprintf("checker %s: %s", checker, r.checkers[checker]))
+	}
+
+	sb := &strings.Builder{}
+	// move the cursor back to the start of the previous block and clear every line before rewriting it
+	if r.renderedLines > 0 {
+		fmt.Fprintf(sb, "\033[%dA", r.renderedLines)
+	}
+	for _, line := range lines {
+		fmt.Fprintf(sb, "\r\033[K%s\n", line)
+	}
+	fmt.Fprint(r.out, sb.String())
+	r.renderedLines = len(lines)
+}
+
+// Write prints a log line in place of the block, then draws the block again below it.
+func (r *terminalProgressReporter) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.renderedLines == 0 {
+		return r.out.Write(p)
+	}
+	// move the cursor back to the start of the block and clear everything below it
+	if _, err := fmt.Fprintf(r.out, "\033[%dA\r\033[J", r.renderedLines); err != nil {
+		return 0, err
+	}
+	r.renderedLines = 0
+	n, err := r.out.Write(p)
+	r.render()
+	return n, err
+}
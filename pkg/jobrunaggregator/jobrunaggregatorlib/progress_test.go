@@ -0,0 +1,43 @@
+package jobrunaggregatorlib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerminalProgressReporter(t *testing.T) {
+	out := &bytes.Buffer{}
+	reporter := &terminalProgressReporter{out: out, started: time.Now(), checkers: map[string]string{}}
+
+	reporter.JobsLocated(3)
+	assert.Equal(t, "\r\033[Kelapsed: 0s\n\r\033[Kjobs located: 3\n\r\033[Kjob runs: 0 finished, 0 pending\n", out.String())
+
+	out.Reset()
+	reporter.JobRunsStatus(5, 2)
+	reporter.CheckerStatus("install should succeed: overall", "running")
+	// every update redraws the previous block in place
+	assert.True(t, strings.HasPrefix(out.String(), "\033[3A"), out.String())
+	assert.Contains(t, out.String(), "\033[3A\r\033[Kelapsed: 0s\n\r\033[Kjobs located: 3\n\r\033[Kjob runs: 5 finished, 2 pending\n\r\033[Kchecker install should succeed: overall: running\n")
+	assert.Equal(t, 4, reporter.renderedLines)
+}
+
+func TestTerminalProgressReporterWrite(t *testing.T) {
+	out := &bytes.Buffer{}
+	reporter := &terminalProgressReporter{out: out, started: time.Now(), checkers: map[string]string{}}
+
+	// nothing to redraw before the first update
+	_, err := reporter.Write([]byte("before\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "before\n", out.String())
+
+	reporter.JobsLocated(3)
+	out.Reset()
+	_, err = reporter.Write([]byte("log line\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "\033[3A\r\033[Jlog line\n\r\033[Kelapsed: 0s\n\r\033[Kjobs located: 3\n\r\033[Kjob runs: 0 finished, 0 pending\n", out.String())
+	assert.Equal(t, 3, reporter.renderedLines)
+}
@@ -0,0 +1,43 @@
+package jobrunaggregatorlib
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowjobv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+// FilterableProwJobStates are the states job run discovery can be restricted to.  Only completed states are
+// listed, runs that haven't finished are never loaded.
+var FilterableProwJobStates = sets.New[string](
+	string(prowjobv1.SuccessState),
+	string(prowjobv1.FailureState),
+	string(prowjobv1.AbortedState),
+	string(prowjobv1.ErrorState),
+)
+
+// ParseProwJobStates validates the prowjob states passed on the command line.
+func ParseProwJobStates(states []string) (sets.Set[prowjobv1.ProwJobState], error) {
+	ret := sets.New[prowjobv1.ProwJobState]()
+	for _, state := range states {
+		state = strings.ToLower(strings.TrimSpace(state))
+		if !FilterableProwJobStates.Has(state) {
+			return nil, fmt.Errorf("unsupported prowjob state %q, must be one of %s", state, strings.Join(sets.List(FilterableProwJobStates), ", "))
+		}
+		ret.Insert(prowjobv1.ProwJobState(state))
+	}
+	return ret, nil
+}
+
+// NewProwJobMatcherFuncForStates matches the prowjobs in one of the given states.  It is evaluated as soon as
+// prowjob.json has been read, so that job runs we don't care about are dropped before their junit is downloaded.
+// An empty set of states matches every prowjob.
+func NewProwJobMatcherFuncForStates(states sets.Set[prowjobv1.ProwJobState]) ProwJobMatcherFunc {
+	return func(prowJob *prowjobv1.ProwJob) bool {
+		if len(states) == 0 {
+			return true
+		}
+		return states.Has(prowJob.Status.State)
+	}
+}
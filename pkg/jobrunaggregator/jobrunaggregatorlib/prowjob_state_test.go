@@ -0,0 +1,45 @@
+package jobrunaggregatorlib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowjobv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+func TestParseProwJobStates(t *testing.T) {
+	tests := []struct {
+		name        string
+		states      []string
+		expected    sets.Set[prowjobv1.ProwJobState]
+		expectedErr string
+	}{
+		{
+			name:     "no states",
+			expected: sets.New[prowjobv1.ProwJobState](),
+		},
+		{
+			name:     "completed states",
+			states:   []string{"success", " Failure"},
+			expected: sets.New(prowjobv1.SuccessState, prowjobv1.FailureState),
+		},
+		{
+			name:        "pending is not a completed state",
+			states:      []string{"success", "pending"},
+			expectedErr: `unsupported prowjob state "pending", must be one of aborted, error, failure, success`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ParseProwJobStates(tt.states)
+			if len(tt.expectedErr) > 0 {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"google.golang.org/api/googleapi"
+)
+
+type QueryCostFlags struct {
+	MaxBytesBilled int64
+}
+
+func NewQueryCostFlags() *QueryCostFlags {
+	return &QueryCostFlags{}
+}
+
+func (f *QueryCostFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.Int64Var(&f.MaxBytesBilled, "max-bytes-billed", f.MaxBytesBilled, "When set, BigQuery fails the queries that would bill more bytes instead of running them, like 1099511627776 for 1TiB. 0 is unbounded.")
+}
+
+func (f *QueryCostFlags) Validate() error {
+	if f.MaxBytesBilled < 0 {
+		return fmt.Errorf("--max-bytes-billed must not be negative")
+	}
+	return nil
+}
+
+// NewCIDataClient returns a CIDataClient whose queries are run under the --max-bytes-billed guardrail.
+func (f *QueryCostFlags) NewCIDataClient(dataCoordinates BigQueryDataCoordinates, client *bigquery.Client) CIDataClient {
+	return &ciDataClient{
+		dataCoordinates: dataCoordinates,
+		client:          client,
+		maxBytesBilled:  f.MaxBytesBilled,
+	}
+}
+
+var (
+	bigQueryBytesBilledTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "jobrunaggregator_bigquery_bytes_billed_total",
+			Help: "Bytes BigQuery billed for the queries of the CI data client, by query.",
+		},
+		[]string{"query"},
+	)
+	bigQuerySlotMillisecondsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "jobrunaggregator_bigquery_slot_milliseconds_total",
+			Help: "Slot milliseconds BigQuery spent on the queries of the CI data client, by query.",
+		},
+		[]string{"query"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(bigQueryBytesBilledTotal, bigQuerySlotMillisecondsTotal)
+}
+
+// readQuery runs the query under the maxBytesBilled guardrail, then logs what it cost.  name identifies the query in
+// the logs and metrics, like the method running it.
+func (c *ciDataClient) readQuery(ctx context.Context, name string, query *bigquery.Query) (*bigquery.RowIterator, error) {
+	if c.maxBytesBilled > 0 {
+		query.QueryConfig.MaxBytesBilled = c.maxBytesBilled
+	}
+	rows, err := query.Read(ctx)
+	if isBytesBilledLimitExceeded(err) {
+		return nil, fmt.Errorf("%s would bill more than the %d bytes of --max-bytes-billed: %w", name, c.maxBytesBilled, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	logQueryCost(ctx, name, rows.SourceJob())
+	return rows, nil
+}
+
+// logQueryCost reads the statistics of the job of the query.  They are only telemetry, so failing to read them is
+// not an error.
+func logQueryCost(ctx context.Context, name string, job *bigquery.Job) {
+	logger := logrus.WithField("query", name)
+	if job == nil {
+		return
+	}
+	status, err := job.Status(ctx)
+	if err != nil {
+		logger.WithError(err).Debug("failed to read the statistics of the query")
+		return
+	}
+	if status.Statistics == nil {
+		return
+	}
+	fields := logrus.Fields{
+		"job":            job.ID(),
+		"bytesProcessed": status.Statistics.TotalBytesProcessed,
+	}
+	if details, ok := status.Statistics.Details.(*bigquery.QueryStatistics); ok {
+		fields["bytesBilled"] = details.TotalBytesBilled
+		fields["slotMillis"] = details.SlotMillis
+		fields["cacheHit"] = details.CacheHit
+		bigQueryBytesBilledTotal.WithLabelValues(name).Add(float64(details.TotalBytesBilled))
+		bigQuerySlotMillisecondsTotal.WithLabelValues(name).Add(float64(details.SlotMillis))
+	}
+	logger.WithFields(fields).Info("ran query")
+}
+
+func isBytesBilledLimitExceeded(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, item := range apiErr.Errors {
+		if item.Reason == "bytesBilledLimitExceeded" {
+			return true
+		}
+	}
+	return false
+}
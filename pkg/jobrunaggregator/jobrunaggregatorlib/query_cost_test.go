@@ -0,0 +1,47 @@
+package jobrunaggregatorlib
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/googleapi"
+)
+
+func TestIsBytesBilledLimitExceeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name: "limit exceeded",
+			err: fmt.Errorf("wrapped: %w", &googleapi.Error{
+				Code:   http.StatusBadRequest,
+				Errors: []googleapi.ErrorItem{{Reason: "bytesBilledLimitExceeded"}},
+			}),
+			expected: true,
+		},
+		{
+			name: "other API error",
+			err: &googleapi.Error{
+				Code:   http.StatusBadRequest,
+				Errors: []googleapi.ErrorItem{{Reason: "invalidQuery"}},
+			},
+		},
+		{
+			name: "not an API error",
+			err:  errors.New("bytesBilledLimitExceeded"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isBytesBilledLimitExceeded(tc.err))
+		})
+	}
+}
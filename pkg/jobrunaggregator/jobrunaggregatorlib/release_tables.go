@@ -0,0 +1,71 @@
+package jobrunaggregatorlib
+
+import (
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// ReleaseTableSpecs are the tables the release controller payloads are loaded into
+var ReleaseTableSpecs = []TableSpec{
+	{
+		Name:        ReleaseTableName,
+		Description: "Payloads the release controller accepted or rejected",
+		Row:         jobrunaggregatorapi.ReleaseTagRow{},
+		ColumnDescriptions: map[string]string{
+			"phase":              "Overall status of the payload, e.g. Accepted or Rejected",
+			"release":            "X.Y version of the payload, e.g. 4.8",
+			"stream":             "Stream of the payload, e.g. nightly or ci",
+			"architecture":       "Architecture of the payload, e.g. amd64",
+			"releaseTag":         "Version of the payload, e.g. 4.8.0-0.nightly-2021-10-28-013428",
+			"releaseTime":        "Time the payload was created",
+			"previousReleaseTag": "Previously accepted payload the changelog is based on",
+			"kubernetesVersion":  "Kubernetes version, e.g. 1.22.1",
+			"currentOSVersion":   "Machine OS version",
+			"previousOSVersion":  "Prior machine OS version when the payload upgrades it",
+			"currentOSURL":       "Release page of the machine OS version",
+			"previousOSURL":      "Release page of the prior machine OS version",
+			"osDiffURL":          "Release page diffing the two machine OS versions",
+		},
+	},
+	{
+		Name:        ReleaseJobRunTableName,
+		Description: "Job runs the release controller ran to decide on payloads",
+		Row:         jobrunaggregatorapi.ReleaseJobRunRow{},
+		ColumnDescriptions: map[string]string{
+			"name":           "Prow name of the job run",
+			"releaseTag":     "Payload the job run tested",
+			"jobName":        "Short job name known by the release controller, e.g. aws-serial",
+			"kind":           "Blocking or Informing",
+			"state":          "Overall status of the job run, e.g. Failed",
+			"url":            "Link to Prow",
+			"transitionTime": "Transition time from the release controller",
+			"retries":        "Number of retries of the job for the payload",
+			"upgradesFrom":   "Source version of an upgrade",
+			"upgradesTo":     "Target version of an upgrade",
+			"upgrade":        "Whether the job run was an upgrade",
+		},
+	},
+	{
+		Name:        ReleaseRepositoryTableName,
+		Description: "Repositories whose content changed in payloads",
+		Row:         jobrunaggregatorapi.ReleaseRepositoryRow{},
+		ColumnDescriptions: map[string]string{
+			"name":           "Name of the repository in the payload",
+			"releaseTag":     "Payload the repository changed in",
+			"repositoryHead": "Link to the head of the repository",
+			"fullChangeLog":  "Link diffing the repository from the prior accepted payload",
+		},
+	},
+	{
+		Name:        ReleasePullRequestsTableName,
+		Description: "Pull requests included for the first time in payloads",
+		Row:         jobrunaggregatorapi.ReleasePullRequestRow{},
+		ColumnDescriptions: map[string]string{
+			"pullRequestID": "GitHub pull request number",
+			"releaseTag":    "Payload the pull request was first included in",
+			"name":          "Name of the repository in the payload",
+			"description":   "Pull request description",
+			"url":           "Link to the pull request",
+			"bugURL":        "Link to the bug, if any",
+		},
+	},
+}
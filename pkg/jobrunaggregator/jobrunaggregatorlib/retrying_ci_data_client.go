@@ -46,6 +46,16 @@ func (c *retryingCIDataClient) GetBackendDisruptionStatisticsByJob(ctx context.C
 	return ret, err
 }
 
+func (c *retryingCIDataClient) ListBackendDisruptionHistogramForJob(ctx context.Context, jobName, masterNodesUpdated string, start, end time.Time) ([]jobrunaggregatorapi.BackendDisruptionHistogramRow, error) {
+	var ret []jobrunaggregatorapi.BackendDisruptionHistogramRow
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListBackendDisruptionHistogramForJob(ctx, jobName, masterNodesUpdated, start, end)
+		return innerErr
+	})
+	return ret, err
+}
+
 func (c *retryingCIDataClient) ListAllJobs(ctx context.Context) ([]jobrunaggregatorapi.JobRowWithVariants, error) {
 	var ret []jobrunaggregatorapi.JobRowWithVariants
 	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
@@ -56,11 +66,42 @@ func (c *retryingCIDataClient) ListAllJobs(ctx context.Context) ([]jobrunaggrega
 	return ret, err
 }
 
-func (c *retryingCIDataClient) ListProwJobRunsSince(ctx context.Context, since *time.Time) ([]*jobrunaggregatorapi.TestPlatformProwJobRow, error) {
+func (c *retryingCIDataClient) ListJobRunLoadExceptions(ctx context.Context) ([]jobrunaggregatorapi.JobRunLoadExceptionRow, error) {
+	var ret []jobrunaggregatorapi.JobRunLoadExceptionRow
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListJobRunLoadExceptions(ctx)
+		return innerErr
+	})
+	return ret, err
+}
+
+func (c *retryingCIDataClient) ListJobRunCheckpoints(ctx context.Context, loader string) (map[string]jobrunaggregatorapi.JobRunCheckpointRow, error) {
+	var ret map[string]jobrunaggregatorapi.JobRunCheckpointRow
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListJobRunCheckpoints(ctx, loader)
+		return innerErr
+	})
+	return ret, err
+}
+
+func (c *retryingCIDataClient) DeleteJobRunRowsFromTables(ctx context.Context, tables []string, jobRunNames []string) (int64, error) {
+	var ret int64
+	// deleting the same rows again deletes nothing, so the deletion is safe to retry
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.DeleteJobRunRowsFromTables(ctx, tables, jobRunNames)
+		return innerErr
+	})
+	return ret, err
+}
+
+func (c *retryingCIDataClient) ListProwJobRunsSince(ctx context.Context, since *time.Time, checkpoints map[string]jobrunaggregatorapi.JobRunCheckpointRow) ([]*jobrunaggregatorapi.TestPlatformProwJobRow, error) {
 	var ret []*jobrunaggregatorapi.TestPlatformProwJobRow
 	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
 		var innerErr error
-		ret, innerErr = c.delegate.ListProwJobRunsSince(ctx, since)
+		ret, innerErr = c.delegate.ListProwJobRunsSince(ctx, since, checkpoints)
 		return innerErr
 	})
 	return ret, err
@@ -96,6 +137,86 @@ func (c *retryingCIDataClient) ListReleaseTags(ctx context.Context) (sets.Set[st
 	return ret, err
 }
 
+func (c *retryingCIDataClient) ListReleaseTagsForStream(ctx context.Context, release, stream, architecture string, since time.Time) ([]jobrunaggregatorapi.ReleaseTagRow, error) {
+	var ret []jobrunaggregatorapi.ReleaseTagRow
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListReleaseTagsForStream(ctx, release, stream, architecture, since)
+		return innerErr
+	})
+	return ret, err
+}
+
+func (c *retryingCIDataClient) ListReleaseJobRunsForReleaseTags(ctx context.Context, releaseTags []string) ([]jobrunaggregatorapi.ReleaseJobRunRow, error) {
+	var ret []jobrunaggregatorapi.ReleaseJobRunRow
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListReleaseJobRunsForReleaseTags(ctx, releaseTags)
+		return innerErr
+	})
+	return ret, err
+}
+
+func (c *retryingCIDataClient) ListGateOverridesForPayloadTags(ctx context.Context, payloadTags []string) ([]jobrunaggregatorapi.GateOverrideRow, error) {
+	var ret []jobrunaggregatorapi.GateOverrideRow
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListGateOverridesForPayloadTags(ctx, payloadTags)
+		return innerErr
+	})
+	return ret, err
+}
+
+func (c *retryingCIDataClient) ListGateResultsForPayloadTags(ctx context.Context, analyzer, jobName string, payloadTags []string) ([]jobrunaggregatorapi.GateResultRow, error) {
+	var ret []jobrunaggregatorapi.GateResultRow
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListGateResultsForPayloadTags(ctx, analyzer, jobName, payloadTags)
+		return innerErr
+	})
+	return ret, err
+}
+
+func (c *retryingCIDataClient) ListJobsWithoutSuccessfulRunsSince(ctx context.Context, since time.Time) (sets.Set[string], error) {
+	var ret sets.Set[string]
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListJobsWithoutSuccessfulRunsSince(ctx, since)
+		return innerErr
+	})
+	return ret, err
+}
+
+func (c *retryingCIDataClient) ListJobRunDurationStatistics(ctx context.Context, jobNames []string, since time.Time) ([]jobrunaggregatorapi.JobRunDurationStatisticsRow, error) {
+	var ret []jobrunaggregatorapi.JobRunDurationStatisticsRow
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListJobRunDurationStatistics(ctx, jobNames, since)
+		return innerErr
+	})
+	return ret, err
+}
+
+func (c *retryingCIDataClient) ListJobRunSuccessStatistics(ctx context.Context, jobNames []string, since time.Time) ([]jobrunaggregatorapi.JobRunSuccessStatisticsRow, error) {
+	var ret []jobrunaggregatorapi.JobRunSuccessStatisticsRow
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListJobRunSuccessStatistics(ctx, jobNames, since)
+		return innerErr
+	})
+	return ret, err
+}
+
+func (c *retryingCIDataClient) ListReleaseMilestoneTags(ctx context.Context, releases []string) ([]jobrunaggregatorapi.ReleaseTagRow, error) {
+	var ret []jobrunaggregatorapi.ReleaseTagRow
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListReleaseMilestoneTags(ctx, releases)
+		return innerErr
+	})
+	return ret, err
+}
+
 func (c *retryingCIDataClient) ListReleases(ctx context.Context) ([]jobrunaggregatorapi.ReleaseRow, error) {
 	var ret []jobrunaggregatorapi.ReleaseRow
 	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
@@ -126,6 +247,26 @@ func (c *retryingCIDataClient) GetJobRunForJobNameAfterTime(ctx context.Context,
 	return ret, err
 }
 
+func (c *retryingCIDataClient) ListJobRunNamesBetween(ctx context.Context, jobName string, start, end time.Time) ([]string, error) {
+	var ret []string
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListJobRunNamesBetween(ctx, jobName, start, end)
+		return innerErr
+	})
+	return ret, err
+}
+
+func (c *retryingCIDataClient) ListJobRunNamesInRange(ctx context.Context, jobName, startingJobRunID, endingJobRunID string) ([]string, error) {
+	var ret []string
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListJobRunNamesInRange(ctx, jobName, startingJobRunID, endingJobRunID)
+		return innerErr
+	})
+	return ret, err
+}
+
 func (c *retryingCIDataClient) ListAggregatedTestRunsForJob(ctx context.Context, frequency, jobName string, startDay time.Time) ([]jobrunaggregatorapi.AggregatedTestRunRow, error) {
 	var ret []jobrunaggregatorapi.AggregatedTestRunRow
 	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
@@ -136,6 +277,16 @@ func (c *retryingCIDataClient) ListAggregatedTestRunsForJob(ctx context.Context,
 	return ret, err
 }
 
+func (c *retryingCIDataClient) ListLocatedJobRuns(ctx context.Context, jobName, matchID string) ([]jobrunaggregatorapi.LocatedJobRunRow, error) {
+	var ret []jobrunaggregatorapi.LocatedJobRunRow
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListLocatedJobRuns(ctx, jobName, matchID)
+		return innerErr
+	})
+	return ret, err
+}
+
 func (c *retryingCIDataClient) ListDisruptionHistoricalData(ctx context.Context) ([]jobrunaggregatorapi.HistoricalData, error) {
 	var ret []jobrunaggregatorapi.HistoricalData
 	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
@@ -156,6 +307,26 @@ func (c *retryingCIDataClient) ListAlertHistoricalData(ctx context.Context) ([]*
 	return ret, err
 }
 
+func (c *retryingCIDataClient) ListBackendDisruptionHistogram(ctx context.Context, start, end time.Time) ([]jobrunaggregatorapi.BackendDisruptionHistogramRow, error) {
+	var ret []jobrunaggregatorapi.BackendDisruptionHistogramRow
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListBackendDisruptionHistogram(ctx, start, end)
+		return innerErr
+	})
+	return ret, err
+}
+
+func (c *retryingCIDataClient) ListAlertHistogram(ctx context.Context, start, end time.Time) ([]jobrunaggregatorapi.AlertHistogramRow, error) {
+	var ret []jobrunaggregatorapi.AlertHistogramRow
+	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
+		var innerErr error
+		ret, innerErr = c.delegate.ListAlertHistogram(ctx, start, end)
+		return innerErr
+	})
+	return ret, err
+}
+
 func (c *retryingCIDataClient) ListAllKnownAlerts(ctx context.Context) ([]*jobrunaggregatorapi.KnownAlertRow, error) {
 	var ret []*jobrunaggregatorapi.KnownAlertRow
 	err := retry.OnError(slowBackoff, isReadQuotaError, func() error {
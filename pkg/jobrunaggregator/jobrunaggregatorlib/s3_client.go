@@ -0,0 +1,225 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// S3Flags read the job runs from an S3 compatible bucket instead of GCS, for Prow deployments outside of GCP.
+// Credentials come from the usual AWS environment variables and files.
+type S3Flags struct {
+	Endpoint string
+	Region   string
+}
+
+func NewS3Flags() *S3Flags {
+	return &S3Flags{
+		Region: "us-east-1",
+	}
+}
+
+func (f *S3Flags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.Endpoint, "s3-endpoint", f.Endpoint, "When set, like https://minio.example.com, job runs are read from the --google-storage-bucket bucket of this S3 compatible endpoint instead of GCS")
+	fs.StringVar(&f.Region, "s3-region", f.Region, "The region of --s3-endpoint")
+}
+
+func (f *S3Flags) Validate() error {
+	if len(f.Endpoint) > 0 && len(f.Region) == 0 {
+		return fmt.Errorf("--s3-region must be specified with --s3-endpoint")
+	}
+	return nil
+}
+
+// Enabled tells whether the job runs are read from S3
+func (f *S3Flags) Enabled() bool {
+	return len(f.Endpoint) > 0
+}
+
+func (f *S3Flags) NewCIGCSClient(bucketName string, options CIGCSClientOptions) (CIGCSClient, error) {
+	awsSession, err := session.NewSession(&aws.Config{
+		Endpoint: aws.String(f.Endpoint),
+		Region:   aws.String(f.Region),
+		// MinIO and most S3 compatible storages don't serve buckets as subdomains
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the S3 session: %w", err)
+	}
+	return NewS3CIGCSClient(s3.New(awsSession), bucketName, options), nil
+}
+
+type s3CIGCSClient struct {
+	client     s3iface.S3API
+	bucketName string
+	bucket     jobrunaggregatorapi.ObjectBucket
+	options    CIGCSClientOptions
+}
+
+// NewS3CIGCSClient lists and reads job runs from an S3 bucket laid out like the GCS bucket of Prow
+func NewS3CIGCSClient(client s3iface.S3API, bucketName string, options CIGCSClientOptions) CIGCSClient {
+	return &s3CIGCSClient{
+		client:     client,
+		bucketName: bucketName,
+		bucket:     jobrunaggregatorapi.NewS3ObjectBucket(client, bucketName),
+		options:    options,
+	}
+}
+
+func (o *s3CIGCSClient) ForBucket(bucketName string) CIGCSClient {
+	if len(bucketName) == 0 || bucketName == o.bucketName {
+		return o
+	}
+	return NewS3CIGCSClient(o.client, bucketName, o.options)
+}
+
+// listJobRunPrefixes lists the job run directories of the job from startingJobRunID, included like the StartOffset of
+// GCS, up to endingJobRunID, excluded, in the order of their IDs.
+func (o *s3CIGCSClient) listJobRunPrefixes(ctx context.Context, gcsPrefix, startingJobRunID, endingJobRunID string) ([]string, error) {
+	endOffset := ""
+	if len(endingJobRunID) > 0 {
+		endOffset = fmt.Sprintf("%s/%s", gcsPrefix, endingJobRunID)
+	}
+	prefixes := []string{}
+	err := o.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket:     aws.String(o.bucketName),
+		Prefix:     aws.String(fmt.Sprintf("%s/", gcsPrefix)),
+		StartAfter: aws.String(fmt.Sprintf("%s/%s", gcsPrefix, startingJobRunID)),
+		// restrict the query to just one level down
+		Delimiter: aws.String("/"),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, commonPrefix := range page.CommonPrefixes {
+			prefix := aws.StringValue(commonPrefix.Prefix)
+			if len(endOffset) > 0 && prefix >= endOffset {
+				return false
+			}
+			prefixes = append(prefixes, prefix)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prefixes, nil
+}
+
+func (o *s3CIGCSClient) ListJobRunNamesBetween(ctx context.Context, gcsPrefix, startingJobRunID, endingJobRunID string, start, end time.Time) ([]string, error) {
+	defer observeJobListing(path.Base(gcsPrefix), time.Now())
+	if len(startingJobRunID) == 0 {
+		startingJobRunID = o.options.listingStartingJobRunID()
+	}
+	prefixes, err := o.listJobRunPrefixes(ctx, gcsPrefix, startingJobRunID, endingJobRunID)
+	if err != nil {
+		return nil, err
+	}
+	jobRunIDs := []string{}
+	for _, prefix := range prefixes {
+		head, err := o.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(o.bucketName),
+			Key:    aws.String(prefix + "started.json"),
+		})
+		if err != nil {
+			logrus.WithError(err).Debugf("skipping %s without readable started.json", prefix)
+			continue
+		}
+		created := aws.TimeValue(head.LastModified)
+		if created.Before(start) {
+			continue
+		}
+		// job run IDs grow with time, so no later job run can be in the window either
+		if !created.Before(end) {
+			break
+		}
+		jobRunIDs = append(jobRunIDs, jobRunIDFromPrefix(prefix))
+	}
+	return jobRunIDs, nil
+}
+
+func (o *s3CIGCSClient) ReadJobRunFromGCS(ctx context.Context, jobGCSRootLocation, jobName, jobRunID string, logger logrus.FieldLogger, opts ...ReadJobRunOption) (jobrunaggregatorapi.JobRunInfo, error) {
+	logger.Debugf("reading job run %s/%s", jobGCSRootLocation, jobRunID)
+
+	jobRun := jobrunaggregatorapi.NewObjectBucketJobRun(o.bucket, jobGCSRootLocation, jobName, jobRunID, o.bucketName, o.options.JobRunArtifactOptions)
+	jobRun.SetGCSProwJobPath(fmt.Sprintf("%s/%s/prowjob.json", jobGCSRootLocation, jobRunID))
+	if NewReadJobRunOptions(opts...).SkipProwJobValidation {
+		return jobRun, nil
+	}
+	if _, err := jobRun.GetProwJob(ctx); err != nil {
+		logger.WithError(err).Error("failed to get prowjob")
+		return nil, fmt.Errorf("failed to get prowjob for %q/%q: %w", jobName, jobRunID, err)
+	}
+	return jobRun, nil
+}
+
+func (o *s3CIGCSClient) ReadJobRunsFromGCS(ctx context.Context, jobGCSRootLocation, jobName string, jobRunIDs []string, logger logrus.FieldLogger) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	if len(jobRunIDs) == 0 {
+		return nil, nil
+	}
+	defer observeJobListing(jobName, time.Now())
+
+	sortedJobRunIDs, startingJobRunID, endingJobRunID := jobRunListingBounds(jobRunIDs)
+	prefixes, err := o.listJobRunPrefixes(ctx, jobGCSRootLocation, startingJobRunID, endingJobRunID)
+	if err != nil {
+		return nil, err
+	}
+
+	return readJobRunsConcurrently(ctx, prefixes, sets.New[string](sortedJobRunIDs...), func(ctx context.Context, prefix string) (jobrunaggregatorapi.JobRunInfo, error) {
+		jobRunID := jobRunIDFromPrefix(prefix)
+		jobRun := jobrunaggregatorapi.NewObjectBucketJobRun(o.bucket, jobGCSRootLocation, jobName, jobRunID, o.bucketName, o.options.JobRunArtifactOptions)
+		jobRun.SetGCSProwJobPath(prefix + "prowjob.json")
+		if _, err := jobRun.GetProwJob(ctx); err != nil {
+			if errors.Is(err, storage.ErrObjectNotExist) {
+				logger.Debugf("skipping %s without prowjob", prefix)
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get prowjob for %q/%q: %w", jobName, jobRunID, err)
+		}
+		return jobRun, nil
+	})
+}
+
+func (o *s3CIGCSClient) ReadRelatedJobRuns(ctx context.Context,
+	jobName, gcsPrefix, startingJobRunID, endingJobRunID string,
+	matcherFunc ProwJobMatcherFunc) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	defer observeJobListing(jobName, time.Now())
+
+	if len(startingJobRunID) == 0 {
+		startingJobRunID = o.options.listingStartingJobRunID()
+	}
+	prefixes, err := o.listJobRunPrefixes(ctx, gcsPrefix, startingJobRunID, endingJobRunID)
+	if err != nil {
+		return nil, err
+	}
+
+	relatedJobRuns := []jobrunaggregatorapi.JobRunInfo{}
+	for _, prefix := range prefixes {
+		jobRunID := jobRunIDFromPrefix(prefix)
+		jobRun := jobrunaggregatorapi.NewObjectBucketJobRun(o.bucket, gcsPrefix, jobName, jobRunID, o.bucketName, o.options.JobRunArtifactOptions)
+		jobRun.SetGCSProwJobPath(prefix + "prowjob.json")
+		prowJob, err := jobRun.GetProwJob(ctx)
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			// the job run hasn't uploaded its prowjob yet
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get prowjob for %q/%q: %w", jobName, jobRunID, err)
+		}
+		if matcherFunc(prowJob) {
+			relatedJobRuns = append(relatedJobRuns, jobRun)
+		}
+	}
+	return relatedJobRuns, nil
+}
@@ -0,0 +1,164 @@
+package jobrunaggregatorlib
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	prowjobv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+// fakeS3 serves objects from memory, listings come in a single page
+type fakeS3 struct {
+	s3iface.S3API
+	objects map[string]string
+	// lastModified of the objects, the zero time when missing
+	lastModified map[string]time.Time
+	// heads are the keys asked for by HeadObject
+	heads []string
+}
+
+func (f *fakeS3) ListObjectsV2PagesWithContext(_ aws.Context, input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, _ ...request.Option) error {
+	page := &s3.ListObjectsV2Output{}
+	seenPrefixes := map[string]bool{}
+	for _, key := range sortedKeys(f.objects) {
+		if !strings.HasPrefix(key, aws.StringValue(input.Prefix)) || key <= aws.StringValue(input.StartAfter) {
+			continue
+		}
+		rest := strings.TrimPrefix(key, aws.StringValue(input.Prefix))
+		if delimiter := aws.StringValue(input.Delimiter); len(delimiter) > 0 && strings.Contains(rest, delimiter) {
+			prefix := aws.StringValue(input.Prefix) + rest[:strings.Index(rest, delimiter)+1]
+			if !seenPrefixes[prefix] {
+				seenPrefixes[prefix] = true
+				page.CommonPrefixes = append(page.CommonPrefixes, &s3.CommonPrefix{Prefix: aws.String(prefix)})
+			}
+			continue
+		}
+		page.Contents = append(page.Contents, &s3.Object{Key: aws.String(key)})
+	}
+	fn(page, true)
+	return nil
+}
+
+func (f *fakeS3) HeadObjectWithContext(_ aws.Context, input *s3.HeadObjectInput, _ ...request.Option) (*s3.HeadObjectOutput, error) {
+	key := aws.StringValue(input.Key)
+	f.heads = append(f.heads, key)
+	if _, ok := f.objects[key]; !ok {
+		return nil, awserr.NewRequestFailure(awserr.New("NotFound", "not found", nil), 404, "")
+	}
+	return &s3.HeadObjectOutput{ETag: aws.String("etag"), LastModified: aws.Time(f.lastModified[key])}, nil
+}
+
+func (f *fakeS3) GetObjectWithContext(_ aws.Context, input *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	content, ok := f.objects[aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.NewRequestFailure(awserr.New(s3.ErrCodeNoSuchKey, "not found", nil), 404, "")
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewBufferString(content))}, nil
+}
+
+func sortedKeys(objects map[string]string) []string {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestS3CIGCSClientReadRelatedJobRuns(t *testing.T) {
+	prowJob := func(tag string) string {
+		return `{"metadata": {"name": "prowjob", "labels": {"release.openshift.io/analysis": "` + tag + `"}}, "status": {"state": "success"}}`
+	}
+	client := NewS3CIGCSClient(&fakeS3{objects: map[string]string{
+		"logs/job/100/prowjob.json":            prowJob("payload"),
+		"logs/job/200/prowjob.json":            prowJob("payload"),
+		"logs/job/200/artifacts/junit_e2e.xml": "<testsuite/>",
+		"logs/job/300/prowjob.json":            prowJob("other-payload"),
+		"logs/job/400/started.json":            "{}",
+		"logs/job/500/prowjob.json":            prowJob("payload"),
+	}}, "bucket", DefaultCIGCSClientOptions)
+
+	jobRuns, err := client.ReadRelatedJobRuns(context.TODO(), "job", "logs/job", "100", "500", func(prowJob *prowjobv1.ProwJob) bool {
+		return prowJob.Labels["release.openshift.io/analysis"] == "payload"
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, jobRuns, 2) {
+		assert.Equal(t, "100", jobRuns[0].GetJobRunID())
+		assert.Equal(t, "200", jobRuns[1].GetJobRunID())
+		assert.NoError(t, jobRuns[1].GetJobRunFromGCS(context.TODO()))
+		assert.Equal(t, []string{"logs/job/200/artifacts/junit_e2e.xml"}, jobRuns[1].GetGCSJunitPaths())
+	}
+}
+
+func TestS3CIGCSClientReadJobRunsFromGCS(t *testing.T) {
+	prowJob := `{"metadata": {"name": "prowjob"}, "status": {"state": "success"}}`
+	client := NewS3CIGCSClient(&fakeS3{objects: map[string]string{
+		"logs/job/100/prowjob.json":  prowJob,
+		"logs/job/200/prowjob.json":  prowJob,
+		"logs/job/300/prowjob.json":  prowJob,
+		"logs/job/400/started.json":  "{}",
+		"logs/job/500/prowjob.json":  prowJob,
+		"logs/job/5000/prowjob.json": prowJob,
+	}}, "bucket", DefaultCIGCSClientOptions)
+
+	// 400 has no prowjob yet and 450 doesn't exist, 5000 is listed between 500 and 600 but not asked for
+	jobRuns, err := client.ReadJobRunsFromGCS(context.TODO(), "logs/job", "job", []string{"500", "200", "400", "450", "200"}, logrus.New())
+	assert.NoError(t, err)
+	jobRunIDs := []string{}
+	for _, jobRun := range jobRuns {
+		jobRunIDs = append(jobRunIDs, jobRun.GetJobRunID())
+	}
+	assert.Equal(t, []string{"200", "500"}, jobRunIDs)
+
+	jobRuns, err = client.ReadJobRunsFromGCS(context.TODO(), "logs/job", "job", nil, logrus.New())
+	assert.NoError(t, err)
+	assert.Empty(t, jobRuns)
+}
+
+func TestS3CIGCSClientListJobRunNamesBetween(t *testing.T) {
+	start := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	fake := &fakeS3{
+		objects: map[string]string{
+			"logs/job/100/started.json": "{}",
+			"logs/job/200/started.json": "{}",
+			"logs/job/300/started.json": "{}",
+			"logs/job/400/started.json": "{}",
+			"logs/job/500/started.json": "{}",
+		},
+		lastModified: map[string]time.Time{
+			"logs/job/100/started.json": start.Add(-2 * time.Hour),
+			"logs/job/200/started.json": start.Add(-time.Minute),
+			"logs/job/300/started.json": start.Add(time.Minute),
+			"logs/job/400/started.json": end,
+			"logs/job/500/started.json": end.Add(time.Hour),
+		},
+	}
+	client := NewS3CIGCSClient(fake, "bucket", DefaultCIGCSClientOptions)
+
+	jobRunIDs, err := client.ListJobRunNamesBetween(context.TODO(), "logs/job", "", "", start, end)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"300"}, jobRunIDs)
+	// the listing stops at the first job run past the window
+	assert.Equal(t, []string{"logs/job/100/started.json", "logs/job/200/started.json", "logs/job/300/started.json", "logs/job/400/started.json"}, fake.heads)
+
+	// the job runs before the starting job run, and from the ending one, are not even looked at
+	fake.heads = nil
+	jobRunIDs, err = client.ListJobRunNamesBetween(context.TODO(), "logs/job", "200", "400", start, end)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"300"}, jobRunIDs)
+	assert.Equal(t, []string{"logs/job/200/started.json", "logs/job/300/started.json"}, fake.heads)
+}
@@ -0,0 +1,60 @@
+package jobrunaggregatorlib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SippyExporter is the Notifier posting verdicts to a Sippy ingestion endpoint, so that Sippy shows gate decisions
+// without waiting for its own BigQuery sync.  A nil SippyExporter exports nothing.
+type SippyExporter struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewSippyExporter returns nil when no endpoint is configured.
+func NewSippyExporter(endpoint string) *SippyExporter {
+	if len(endpoint) == 0 {
+		return nil
+	}
+	return &SippyExporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: time.Minute},
+	}
+}
+
+func (e *SippyExporter) Notify(ctx context.Context, verdict *PayloadVerdict) error {
+	if e == nil {
+		return nil
+	}
+	return postJSON(ctx, e.client, e.endpoint, "sippy", verdict)
+}
+
+// postJSON posts the payload to the endpoint, target names the receiver in errors.
+func postJSON(ctx context.Context, client *http.Client, endpoint, target string, payload interface{}) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payloadJSON))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post verdict to %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s rejected verdict with %s: %s", target, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+func TestSippyExporterNotify(t *testing.T) {
+	details := &TestCaseDetails{Passes: []TestCasePass{{JobRunID: "1"}}, Failures: []TestCaseFailure{{JobRunID: "2"}}}
+	passing := &junit.TestCase{Name: "install should succeed: overall"}
+	assert.NoError(t, SetTestCaseDetails(passing, details))
+	suite := &junit.TestSuite{
+		Name: "payload-cross-jobs",
+		Children: []*junit.TestSuite{
+			{
+				Name: "cluster install",
+				TestCases: []*junit.TestCase{
+					passing,
+					{Name: "install should succeed: infrastructure", FailureOutput: &junit.FailureOutput{Message: "required minimum successful count 3, got 1"}},
+				},
+			},
+		},
+	}
+
+	var received *PayloadVerdict
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		received = &PayloadVerdict{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	verdict := &PayloadVerdict{
+		Analyzer:   "analyze-test-case",
+		PayloadTag: "4.15.0-0.nightly-2023-10-01-000000",
+		Passed:     false,
+		Tests:      PayloadTestResultsFromSuite(suite),
+	}
+	assert.NoError(t, NewSippyExporter(server.URL).Notify(context.TODO(), verdict))
+	assert.Equal(t, []PayloadTestResult{
+		{TestSuiteName: "payload-cross-jobs|||cluster install", TestName: "install should succeed: overall", Passed: true, Passes: 1, Failures: 1},
+		{TestSuiteName: "payload-cross-jobs|||cluster install", TestName: "install should succeed: infrastructure", Passed: false},
+	}, received.Tests)
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer failingServer.Close()
+	assert.EqualError(t, NewSippyExporter(failingServer.URL).Notify(context.TODO(), verdict), "sippy rejected verdict with 400 Bad Request: bad payload")
+
+	// without an endpoint nothing is exported
+	assert.NoError(t, NewSippyExporter("").Notify(context.TODO(), verdict))
+}
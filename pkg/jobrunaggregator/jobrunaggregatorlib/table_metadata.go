@@ -0,0 +1,115 @@
+package jobrunaggregatorlib
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/spf13/pflag"
+)
+
+var labelRegex = regexp.MustCompile(`^[a-z0-9_-]{0,63}$`)
+
+// TableSpec describes a table beyond its schema, so that the dataset documents itself.
+type TableSpec struct {
+	Name        string
+	Description string
+	// Row is the struct the schema is inferred from
+	Row interface{}
+	// ColumnDescriptions are keyed by column name
+	ColumnDescriptions map[string]string
+
+	// PartitionColumn partitions the table by day of this TIMESTAMP column, so that queries filtering on it only
+	// scan the days they need.  It can only be set when the table is created.
+	PartitionColumn string
+	// ClusterColumns sort the rows of every partition, so that queries filtering on them scan less, up to 4 columns
+	ClusterColumns []string
+}
+
+// TablePolicyFlags are the governance settings applied to the tables a command creates.
+type TablePolicyFlags struct {
+	Owner           string
+	RetentionPolicy string
+	Expiration      time.Duration
+}
+
+func NewTablePolicyFlags() *TablePolicyFlags {
+	return &TablePolicyFlags{
+		Owner:           "trt",
+		RetentionPolicy: "keep",
+	}
+}
+
+func (f *TablePolicyFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.Owner, "table-owner", f.Owner, "Value of the owner label of the created tables")
+	fs.StringVar(&f.RetentionPolicy, "table-retention-policy", f.RetentionPolicy, "Value of the retention-policy label of the created tables")
+	fs.DurationVar(&f.Expiration, "table-expiration", f.Expiration, "When set, the created tables are deleted after this long.  Meant for scratch datasets.")
+}
+
+func (f *TablePolicyFlags) Validate() error {
+	if !labelRegex.MatchString(f.Owner) {
+		return fmt.Errorf("--table-owner must only contain lowercase letters, digits, underscores and dashes: %q", f.Owner)
+	}
+	if !labelRegex.MatchString(f.RetentionPolicy) {
+		return fmt.Errorf("--table-retention-policy must only contain lowercase letters, digits, underscores and dashes: %q", f.RetentionPolicy)
+	}
+	if f.Expiration < 0 {
+		return fmt.Errorf("--table-expiration must not be negative")
+	}
+	return nil
+}
+
+// NewTableMetadata infers the schema of the table, and sets the descriptions and the policy of the table on it.
+func NewTableMetadata(spec TableSpec, policy *TablePolicyFlags, now time.Time) (*bigquery.TableMetadata, error) {
+	schema, err := bigquery.InferSchema(spec.Row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to infer the schema of %s: %w", spec.Name, err)
+	}
+	for _, field := range schema {
+		field.Description = spec.ColumnDescriptions[field.Name]
+	}
+	if len(spec.PartitionColumn) > 0 {
+		field := findField(schema, spec.PartitionColumn)
+		if field == nil || (field.Type != bigquery.TimestampFieldType && field.Type != bigquery.DateFieldType) || field.Repeated {
+			return nil, fmt.Errorf("%s can't be partitioned by %s, which must be a TIMESTAMP or DATE column", spec.Name, spec.PartitionColumn)
+		}
+	}
+	if len(spec.ClusterColumns) > 4 {
+		return nil, fmt.Errorf("%s can't be clustered by more than 4 columns", spec.Name)
+	}
+	for _, column := range spec.ClusterColumns {
+		if findField(schema, column) == nil {
+			return nil, fmt.Errorf("%s can't be clustered by %s, which is not one of its columns", spec.Name, column)
+		}
+	}
+
+	metadata := &bigquery.TableMetadata{
+		Name:        spec.Name,
+		Description: spec.Description,
+		Schema:      schema,
+		Labels:      map[string]string{},
+	}
+	if len(spec.PartitionColumn) > 0 {
+		metadata.TimePartitioning = &bigquery.TimePartitioning{
+			Type:  bigquery.DayPartitioningType,
+			Field: spec.PartitionColumn,
+		}
+	}
+	if len(spec.ClusterColumns) > 0 {
+		metadata.Clustering = &bigquery.Clustering{Fields: spec.ClusterColumns}
+	}
+	if policy == nil {
+		return metadata, nil
+	}
+	if len(policy.Owner) > 0 {
+		metadata.Labels["owner"] = policy.Owner
+	}
+	if len(policy.RetentionPolicy) > 0 {
+		metadata.Labels["retention-policy"] = policy.RetentionPolicy
+	}
+	if policy.Expiration > 0 {
+		metadata.ExpirationTime = now.Add(policy.Expiration)
+	}
+	return metadata, nil
+}
@@ -0,0 +1,111 @@
+package jobrunaggregatorlib
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func TestNewTableMetadata(t *testing.T) {
+	type row struct {
+		Name    string `bigquery:"name"`
+		Retries int
+	}
+	spec := TableSpec{
+		Name:               "Rows",
+		Description:        "Some rows",
+		Row:                row{},
+		ColumnDescriptions: map[string]string{"name": "Name of the row"},
+	}
+	now := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	metadata, err := NewTableMetadata(spec, &TablePolicyFlags{Owner: "trt", RetentionPolicy: "keep", Expiration: 24 * time.Hour}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Some rows", metadata.Description)
+	assert.Equal(t, "Name of the row", metadata.Schema[0].Description)
+	assert.Equal(t, "", metadata.Schema[1].Description)
+	assert.Equal(t, map[string]string{"owner": "trt", "retention-policy": "keep"}, metadata.Labels)
+	assert.Equal(t, now.Add(24*time.Hour), metadata.ExpirationTime)
+
+	metadata, err = NewTableMetadata(spec, &TablePolicyFlags{}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, metadata.Labels)
+	assert.True(t, metadata.ExpirationTime.IsZero())
+}
+
+func TestTablePolicyFlagsValidate(t *testing.T) {
+	assert.NoError(t, NewTablePolicyFlags().Validate())
+	assert.Error(t, (&TablePolicyFlags{Owner: "TRT"}).Validate())
+	assert.Error(t, (&TablePolicyFlags{Expiration: -time.Hour}).Validate())
+}
+
+func TestNewTableMetadataPartitioning(t *testing.T) {
+	type row struct {
+		JobName   string
+		StartTime time.Time
+	}
+	spec := TableSpec{Name: "Rows", Row: row{}, PartitionColumn: "StartTime", ClusterColumns: []string{"JobName"}}
+
+	metadata, err := NewTableMetadata(spec, nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: "StartTime"}, metadata.TimePartitioning)
+	assert.Equal(t, &bigquery.Clustering{Fields: []string{"JobName"}}, metadata.Clustering)
+
+	spec.PartitionColumn = "JobName"
+	_, err = NewTableMetadata(spec, nil, time.Now())
+	assert.Error(t, err)
+
+	spec.PartitionColumn = ""
+	spec.ClusterColumns = []string{"Missing"}
+	_, err = NewTableMetadata(spec, nil, time.Now())
+	assert.Error(t, err)
+}
+
+// TestDeclaredTableSpecs keeps the descriptions and the partitioning in sync with the row types
+func TestDeclaredTableSpecs(t *testing.T) {
+	specs := DeclaredTableSpecs()
+	assert.Equal(t, jobrunaggregatorapi.SchemaMigrationsTableName, specs[0].Name, "the migrations must be recorded first")
+	names := map[string]bool{}
+	for _, spec := range specs {
+		assert.False(t, names[spec.Name], "%s is declared twice", spec.Name)
+		names[spec.Name] = true
+
+		metadata, err := NewTableMetadata(spec, nil, time.Now())
+		if err != nil {
+			t.Fatalf("%s: %v", spec.Name, err)
+		}
+		for _, field := range metadata.Schema {
+			assert.NotEmpty(t, field.Description, "%s: column %s has no description", spec.Name, field.Name)
+		}
+		assert.Len(t, spec.ColumnDescriptions, len(metadata.Schema), spec.Name)
+	}
+
+	// the commands write to these tables, a fresh dataset needs all of them
+	for _, name := range []string{
+		jobrunaggregatorapi.JobsTableName,
+		jobrunaggregatorapi.JobRunsTableName,
+		jobrunaggregatorapi.TestRunsTableName,
+		jobrunaggregatorapi.BackendDisruptionTableName,
+		jobrunaggregatorapi.AlertsTableName,
+		jobrunaggregatorapi.JobRunCheckpointsTableName,
+		jobrunaggregatorapi.JobRunLoadExceptionsTableName,
+		jobrunaggregatorapi.JunitArtifactStatsTableName,
+		jobrunaggregatorapi.GateOverridesTableName,
+		jobrunaggregatorapi.GateResultsTableName,
+		jobrunaggregatorapi.TestCaseAnalysisTableName,
+		jobrunaggregatorapi.LocatedJobRunsTableName,
+		jobrunaggregatorapi.HistoricalDataSnapshotsTableName,
+	} {
+		assert.True(t, names[name], "%s is not declared", name)
+	}
+}
@@ -0,0 +1,285 @@
+package jobrunaggregatorlib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/googleapi"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// SchemaMigrationsTableSpec is the table the migrations applied by CreateOrMigrateTable are recorded in
+var SchemaMigrationsTableSpec = TableSpec{
+	Name:        jobrunaggregatorapi.SchemaMigrationsTableName,
+	Description: "Columns added to existing tables to match their declared schema",
+	Row:         jobrunaggregatorapi.SchemaMigrationRow{},
+	ColumnDescriptions: map[string]string{
+		"TableName":    "Table the columns were added to",
+		"AddedColumns": "Paths of the added columns, like parent.child for the columns of records",
+		"AppliedTime":  "Time the columns were added",
+	},
+}
+
+// SchemaDiff is how the schema of an existing table differs from its declared schema
+type SchemaDiff struct {
+	// AddedColumns are the declared columns missing from the table, which can be added
+	AddedColumns []string
+	// UndeclaredColumns are in the table but not declared, they are left alone
+	UndeclaredColumns []string
+	// Incompatible are the differences that adding columns can't fix, like a column whose type changed
+	Incompatible []string
+	// Schema is the schema of the table with the added columns
+	Schema bigquery.Schema
+}
+
+// DiffTableSchema compares the schema of an existing table with the declared one, down to the columns of records.
+// Columns are matched by name regardless of case, like BigQuery does.
+func DiffTableSchema(live, declared bigquery.Schema) SchemaDiff {
+	diff := SchemaDiff{}
+	diff.Schema = diffFields("", live, declared, &diff)
+	return diff
+}
+
+func diffFields(parent string, live, declared bigquery.Schema, diff *SchemaDiff) bigquery.Schema {
+	merged := bigquery.Schema{}
+	for _, liveField := range live {
+		column := parent + liveField.Name
+		declaredField := findField(declared, liveField.Name)
+		if declaredField == nil {
+			diff.UndeclaredColumns = append(diff.UndeclaredColumns, column)
+			merged = append(merged, liveField)
+			continue
+		}
+		switch {
+		case liveField.Type != declaredField.Type:
+			diff.Incompatible = append(diff.Incompatible, fmt.Sprintf("column %s is %s but declared %s", column, liveField.Type, declaredField.Type))
+		case liveField.Repeated != declaredField.Repeated || liveField.Required != declaredField.Required:
+			diff.Incompatible = append(diff.Incompatible, fmt.Sprintf("column %s is %s but declared %s", column, fieldMode(liveField), fieldMode(declaredField)))
+		case liveField.Type == bigquery.RecordFieldType:
+			mergedField := *liveField
+			mergedField.Schema = diffFields(column+".", liveField.Schema, declaredField.Schema, diff)
+			merged = append(merged, &mergedField)
+			continue
+		}
+		merged = append(merged, liveField)
+	}
+
+	for _, declaredField := range declared {
+		if findField(live, declaredField.Name) != nil {
+			continue
+		}
+		column := parent + declaredField.Name
+		if declaredField.Required {
+			diff.Incompatible = append(diff.Incompatible, fmt.Sprintf("column %s is missing but REQUIRED columns can't be added", column))
+			continue
+		}
+		diff.AddedColumns = append(diff.AddedColumns, column)
+		merged = append(merged, declaredField)
+	}
+	return merged
+}
+
+func findField(schema bigquery.Schema, name string) *bigquery.FieldSchema {
+	for _, field := range schema {
+		if strings.EqualFold(field.Name, name) {
+			return field
+		}
+	}
+	return nil
+}
+
+func fieldMode(field *bigquery.FieldSchema) string {
+	switch {
+	case field.Repeated:
+		return "REPEATED"
+	case field.Required:
+		return "REQUIRED"
+	default:
+		return "NULLABLE"
+	}
+}
+
+// Empty tells there is nothing to report
+func (d SchemaDiff) Empty() bool {
+	return len(d.AddedColumns) == 0 && len(d.UndeclaredColumns) == 0 && len(d.Incompatible) == 0
+}
+
+// Drifted tells the table lacks declared columns or has incompatible ones, so that writing the declared rows fails
+func (d SchemaDiff) Drifted() bool {
+	return len(d.AddedColumns) > 0 || len(d.Incompatible) > 0
+}
+
+// Print writes the differences one per line, like a diff of the columns
+func (d SchemaDiff) Print(out io.Writer, tableName string) {
+	for _, column := range d.AddedColumns {
+		fmt.Fprintf(out, "%s: + %s\n", tableName, column)
+	}
+	for _, column := range d.UndeclaredColumns {
+		fmt.Fprintf(out, "%s: ? %s is not declared\n", tableName, column)
+	}
+	for _, incompatible := range d.Incompatible {
+		fmt.Fprintf(out, "%s: ! %s\n", tableName, incompatible)
+	}
+}
+
+// CreateOrMigrateTable creates the table of the spec when it is missing, or adds the declared columns it is missing
+// and changes its clustering to the declared one.  The differences are printed to out, and only printed when dryRun
+// is set.  Added columns are recorded with migrationInserter, into the table of SchemaMigrationsTableSpec.
+// Differences that adding columns can't fix fail the migration, and have to be handled by hand.  So does a different
+// partitioning, which is only printed.
+func CreateOrMigrateTable(ctx context.Context, dataSet *bigquery.Dataset, spec TableSpec, policy *TablePolicyFlags, migrationInserter BigQueryInserter, dryRun bool, out io.Writer) error {
+	logger := logrus.WithField("table", spec.Name)
+	declared, err := NewTableMetadata(spec, policy, time.Now())
+	if err != nil {
+		return err
+	}
+
+	table := dataSet.Table(spec.Name)
+	live, err := table.Metadata(ctx)
+	if isNotFound(err) {
+		fmt.Fprintf(out, "%s: created\n", spec.Name)
+		if dryRun {
+			return nil
+		}
+		return table.Create(ctx, declared)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the metadata of %s: %w", spec.Name, err)
+	}
+
+	diff := DiffTableSchema(live.Schema, declared.Schema)
+	diff.Print(out, spec.Name)
+	// changing the partitioning means copying the rows to a new table, which is left to be done by hand, while the
+	// clustering is updated in place along with the columns
+	printPartitioningDiff(out, spec.Name, live, declared)
+	if len(diff.Incompatible) > 0 {
+		return fmt.Errorf("%s has %d differences with its declared schema that can't be migrated by adding columns", spec.Name, len(diff.Incompatible))
+	}
+	recluster := clusteringDiffers(live, declared)
+	if len(diff.AddedColumns) == 0 && !recluster {
+		logger.Info("table is up to date")
+		return nil
+	}
+	if dryRun {
+		return nil
+	}
+
+	update := bigquery.TableMetadataToUpdate{}
+	if len(diff.AddedColumns) > 0 {
+		update.Schema = diff.Schema
+	}
+	if recluster {
+		// an empty clustering removes it, a nil one leaves it alone
+		update.Clustering = &bigquery.Clustering{}
+		if declared.Clustering != nil {
+			update.Clustering = declared.Clustering
+		}
+	}
+	// the etag fails the update if the table changed since its schema was read
+	if _, err := table.Update(ctx, update, live.ETag); err != nil {
+		return fmt.Errorf("failed to migrate %s: %w", spec.Name, err)
+	}
+	if recluster {
+		logger.WithField("columns", clusterColumns(declared.Clustering)).Info("changed the clustering, rows written from now on are clustered by the new columns")
+	}
+	if len(diff.AddedColumns) == 0 {
+		return nil
+	}
+	logger.WithField("columns", diff.AddedColumns).Info("added columns")
+	return migrationInserter.Put(ctx, jobrunaggregatorapi.SchemaMigrationRow{
+		TableName:    spec.Name,
+		AddedColumns: diff.AddedColumns,
+		AppliedTime:  time.Now(),
+	})
+}
+
+// CreateOrMigrateTables creates or migrates the tables of the specs in order, and records the migrations into the
+// table of SchemaMigrationsTableSpec, which should come first when it is one of them.  A table failing to migrate
+// doesn't keep the others from being migrated.
+func CreateOrMigrateTables(ctx context.Context, dataSet *bigquery.Dataset, specs []TableSpec, policy *TablePolicyFlags, dryRun bool, out io.Writer) error {
+	migrationInserter := NewDryRunInserter(out, jobrunaggregatorapi.SchemaMigrationsTableName)
+	if !dryRun {
+		migrationInserter = dataSet.Table(jobrunaggregatorapi.SchemaMigrationsTableName).Inserter()
+	}
+
+	errs := []error{}
+	for _, spec := range specs {
+		if err := CreateOrMigrateTable(ctx, dataSet, spec, policy, migrationInserter, dryRun, out); err != nil {
+			logrus.WithError(err).WithField("table", spec.Name).Error("failed to create or migrate table")
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// VerifyTableSchema prints how the table of the spec differs from its declared schema, and tells whether the table
+// drifted from it: it is missing, or it lacks declared columns or has incompatible ones, which would fail the rows
+// written to it.  Undeclared columns and a different partitioning are printed, but they don't fail writes.
+func VerifyTableSchema(ctx context.Context, dataSet *bigquery.Dataset, spec TableSpec, out io.Writer) (bool, error) {
+	declared, err := NewTableMetadata(spec, nil, time.Now())
+	if err != nil {
+		return false, err
+	}
+	live, err := dataSet.Table(spec.Name).Metadata(ctx)
+	if isNotFound(err) {
+		fmt.Fprintf(out, "%s: ! table is missing\n", spec.Name)
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read the metadata of %s: %w", spec.Name, err)
+	}
+
+	diff := DiffTableSchema(live.Schema, declared.Schema)
+	diff.Print(out, spec.Name)
+	printPartitioningDiff(out, spec.Name, live, declared)
+	return diff.Drifted(), nil
+}
+
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
+
+// printPartitioningDiff prints how the partitioning and the clustering of the table differ from the declared ones.
+// The clustering is changed by CreateOrMigrateTable, the partitioning has to be migrated by hand as the help of
+// create-tables explains.
+func printPartitioningDiff(out io.Writer, tableName string, live, declared *bigquery.TableMetadata) {
+	if livePartitioning, declaredPartitioning := partitionColumn(live.TimePartitioning), partitionColumn(declared.TimePartitioning); livePartitioning != declaredPartitioning {
+		fmt.Fprintf(out, "%s: ? partitioned by %s but declared partitioned by %s\n", tableName, livePartitioning, declaredPartitioning)
+	}
+	if clusteringDiffers(live, declared) {
+		fmt.Fprintf(out, "%s: ~ clustered by %s but declared clustered by %s\n", tableName, clusterColumns(live.Clustering), clusterColumns(declared.Clustering))
+	}
+}
+
+func clusteringDiffers(live, declared *bigquery.TableMetadata) bool {
+	return clusterColumns(live.Clustering) != clusterColumns(declared.Clustering)
+}
+
+func partitionColumn(partitioning *bigquery.TimePartitioning) string {
+	switch {
+	case partitioning == nil:
+		return "nothing"
+	case len(partitioning.Field) == 0:
+		return "_PARTITIONTIME"
+	default:
+		return partitioning.Field
+	}
+}
+
+func clusterColumns(clustering *bigquery.Clustering) string {
+	if clustering == nil || len(clustering.Fields) == 0 {
+		return "nothing"
+	}
+	return strings.Join(clustering.Fields, ",")
+}
@@ -0,0 +1,140 @@
+package jobrunaggregatorlib
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTableSchema(t *testing.T) {
+	stringField := func(name string) *bigquery.FieldSchema {
+		return &bigquery.FieldSchema{Name: name, Type: bigquery.StringFieldType}
+	}
+	record := func(name string, fields ...*bigquery.FieldSchema) *bigquery.FieldSchema {
+		return &bigquery.FieldSchema{Name: name, Type: bigquery.RecordFieldType, Schema: fields}
+	}
+
+	tests := []struct {
+		name           string
+		live           bigquery.Schema
+		declared       bigquery.Schema
+		expected       SchemaDiff
+		expectedOutput string
+		expectedDrift  bool
+	}{
+		{
+			name:     "up to date",
+			live:     bigquery.Schema{stringField("JobName"), stringField("JobRunName")},
+			declared: bigquery.Schema{stringField("jobname"), stringField("JobRunName")},
+			expected: SchemaDiff{Schema: bigquery.Schema{stringField("JobName"), stringField("JobRunName")}},
+		},
+		{
+			name:     "added columns, also in records",
+			live:     bigquery.Schema{stringField("JobName"), record("Labels", stringField("Owner"))},
+			declared: bigquery.Schema{stringField("JobName"), stringField("Cluster"), record("Labels", stringField("Owner"), stringField("Team"))},
+			expected: SchemaDiff{
+				AddedColumns: []string{"Labels.Team", "Cluster"},
+				Schema:       bigquery.Schema{stringField("JobName"), record("Labels", stringField("Owner"), stringField("Team")), stringField("Cluster")},
+			},
+			expectedOutput: "Jobs: + Labels.Team\nJobs: + Cluster\n",
+			expectedDrift:  true,
+		},
+		{
+			name:     "undeclared columns are kept",
+			live:     bigquery.Schema{stringField("JobName"), stringField("Deprecated")},
+			declared: bigquery.Schema{stringField("JobName")},
+			expected: SchemaDiff{
+				UndeclaredColumns: []string{"Deprecated"},
+				Schema:            bigquery.Schema{stringField("JobName"), stringField("Deprecated")},
+			},
+			expectedOutput: "Jobs: ? Deprecated is not declared\n",
+		},
+		{
+			name:     "incompatible changes",
+			live:     bigquery.Schema{stringField("JobName"), stringField("Count")},
+			declared: bigquery.Schema{{Name: "JobName", Type: bigquery.StringFieldType, Repeated: true}, {Name: "Count", Type: bigquery.IntegerFieldType}, {Name: "Required", Type: bigquery.StringFieldType, Required: true}},
+			expected: SchemaDiff{
+				Incompatible: []string{
+					"column JobName is NULLABLE but declared REPEATED",
+					"column Count is STRING but declared INTEGER",
+					"column Required is missing but REQUIRED columns can't be added",
+				},
+				Schema: bigquery.Schema{stringField("JobName"), stringField("Count")},
+			},
+			expectedOutput: "Jobs: ! column JobName is NULLABLE but declared REPEATED\nJobs: ! column Count is STRING but declared INTEGER\nJobs: ! column Required is missing but REQUIRED columns can't be added\n",
+			expectedDrift:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff := DiffTableSchema(tc.live, tc.declared)
+			assert.Equal(t, tc.expected, diff)
+			assert.Equal(t, len(tc.expectedOutput) == 0, diff.Empty())
+			assert.Equal(t, tc.expectedDrift, diff.Drifted())
+			out := &bytes.Buffer{}
+			diff.Print(out, "Jobs")
+			assert.Equal(t, tc.expectedOutput, out.String())
+		})
+	}
+}
+
+func TestSchemaMigrationsTableSpecDescribesEveryColumn(t *testing.T) {
+	metadata, err := NewTableMetadata(SchemaMigrationsTableSpec, nil, time.Time{})
+	assert.NoError(t, err)
+	for _, field := range metadata.Schema {
+		assert.NotEmpty(t, field.Description, field.Name)
+	}
+	assert.Len(t, SchemaMigrationsTableSpec.ColumnDescriptions, len(metadata.Schema))
+}
+
+func TestPrintPartitioningDiff(t *testing.T) {
+	partitioned := func(column string, clusterColumns ...string) *bigquery.TableMetadata {
+		metadata := &bigquery.TableMetadata{}
+		if len(column) > 0 {
+			metadata.TimePartitioning = &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: column}
+		}
+		if len(clusterColumns) > 0 {
+			metadata.Clustering = &bigquery.Clustering{Fields: clusterColumns}
+		}
+		return metadata
+	}
+
+	tests := []struct {
+		name              string
+		live              *bigquery.TableMetadata
+		declared          *bigquery.TableMetadata
+		expectedOutput    string
+		expectedRecluster bool
+	}{
+		{
+			name:     "up to date",
+			live:     partitioned("StartTime", "JobName"),
+			declared: partitioned("StartTime", "JobName"),
+		},
+		{
+			name:              "clustering changed",
+			live:              partitioned("StartTime"),
+			declared:          partitioned("StartTime", "JobName"),
+			expectedOutput:    "JobRuns: ~ clustered by nothing but declared clustered by JobName\n",
+			expectedRecluster: true,
+		},
+		{
+			name:              "partitioning and clustering changed",
+			live:              partitioned(""),
+			declared:          partitioned("StartTime", "JobName"),
+			expectedOutput:    "JobRuns: ? partitioned by nothing but declared partitioned by StartTime\nJobRuns: ~ clustered by nothing but declared clustered by JobName\n",
+			expectedRecluster: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			printPartitioningDiff(out, "JobRuns", tc.live, tc.declared)
+			assert.Equal(t, tc.expectedOutput, out.String())
+			assert.Equal(t, tc.expectedRecluster, clusteringDiffers(tc.live, tc.declared))
+		})
+	}
+}
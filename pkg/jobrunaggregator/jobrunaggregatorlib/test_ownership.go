@@ -0,0 +1,95 @@
+package jobrunaggregatorlib
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// TestOwnership maps test names matching Pattern to the component and team responsible for them.
+type TestOwnership struct {
+	// Pattern is a regular expression matched against the test name.
+	Pattern   string `json:"pattern"`
+	Component string `json:"component"`
+	Team      string `json:"team,omitempty"`
+}
+
+func (o TestOwnership) String() string {
+	if len(o.Team) == 0 {
+		return fmt.Sprintf("component: %s", o.Component)
+	}
+	return fmt.Sprintf("component: %s, team: %s", o.Component, o.Team)
+}
+
+// TestOwners resolves the owner of a test.  The first matching entry wins, so more specific patterns
+// should be listed first.
+type TestOwners struct {
+	ownerships []TestOwnership
+	patterns   []*regexp.Regexp
+}
+
+// NewTestOwners reads a YAML (or JSON) list of TestOwnership from path.  An empty path returns nil,
+// which is a valid TestOwners that never finds an owner.
+func NewTestOwners(path string) (*TestOwners, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test ownership file %q: %w", path, err)
+	}
+	ownerships := []TestOwnership{}
+	if err := yaml.Unmarshal(data, &ownerships); err != nil {
+		return nil, fmt.Errorf("failed to parse test ownership file %q: %w", path, err)
+	}
+
+	owners := &TestOwners{}
+	for _, ownership := range ownerships {
+		if len(ownership.Component) == 0 {
+			return nil, fmt.Errorf("test ownership for pattern %q is missing a component", ownership.Pattern)
+		}
+		pattern, err := regexp.Compile(ownership.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid test ownership pattern %q: %w", ownership.Pattern, err)
+		}
+		owners.ownerships = append(owners.ownerships, ownership)
+		owners.patterns = append(owners.patterns, pattern)
+	}
+	return owners, nil
+}
+
+// OwnerFor returns the owner of testName, if there is one.
+func (t *TestOwners) OwnerFor(testName string) (TestOwnership, bool) {
+	if t == nil {
+		return TestOwnership{}, false
+	}
+	for i, pattern := range t.patterns {
+		if pattern.MatchString(testName) {
+			return t.ownerships[i], true
+		}
+	}
+	return TestOwnership{}, false
+}
+
+// AnnotateFailures adds the owner to the failure message of every failed test case in the suite tree,
+// so that whoever reads the junit knows where to route the failure.
+func (t *TestOwners) AnnotateFailures(suite *junit.TestSuite) {
+	if t == nil || suite == nil {
+		return
+	}
+	for _, testCase := range suite.TestCases {
+		if !IsFailedTestCase(testCase) {
+			continue
+		}
+		if owner, ok := t.OwnerFor(testCase.Name); ok {
+			testCase.FailureOutput.Message = fmt.Sprintf("%s [%s]", testCase.FailureOutput.Message, owner)
+		}
+	}
+	for _, child := range suite.Children {
+		t.AnnotateFailures(child)
+	}
+}
@@ -0,0 +1,55 @@
+package jobrunaggregatorlib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+func TestTestOwnersAnnotateFailures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "owners.yaml")
+	ownersYAML := `
+- pattern: '\[sig-network\].*ingress'
+  component: Routing
+  team: network-edge
+- pattern: '\[sig-network\]'
+  component: Networking
+`
+	if err := os.WriteFile(path, []byte(ownersYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	owners, err := NewTestOwners(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suite := &junit.TestSuite{
+		TestCases: []*junit.TestCase{
+			{Name: "[sig-network] ingress should work", FailureOutput: &junit.FailureOutput{Message: "failed"}},
+		},
+		Children: []*junit.TestSuite{
+			{
+				TestCases: []*junit.TestCase{
+					{Name: "[sig-network] pods should talk", FailureOutput: &junit.FailureOutput{Message: "failed"}},
+					{Name: "[sig-network] passing"},
+					{Name: "[sig-storage] volumes", FailureOutput: &junit.FailureOutput{Message: "failed"}},
+				},
+			},
+		},
+	}
+	owners.AnnotateFailures(suite)
+
+	assert.Equal(t, "failed [component: Routing, team: network-edge]", suite.TestCases[0].FailureOutput.Message)
+	assert.Equal(t, "failed [component: Networking]", suite.Children[0].TestCases[0].FailureOutput.Message)
+	assert.Nil(t, suite.Children[0].TestCases[1].FailureOutput)
+	assert.Equal(t, "failed", suite.Children[0].TestCases[2].FailureOutput.Message)
+
+	// a nil TestOwners is valid and never finds an owner
+	var noOwners *TestOwners
+	_, ok := noOwners.OwnerFor("[sig-network] ingress should work")
+	assert.False(t, ok)
+}
@@ -0,0 +1,131 @@
+package jobrunaggregatorlib
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// TestRename maps the name a test used to have to the name it has now.
+type TestRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// TestRenames translates old test names to current ones, both in junit and in historical data, so that a
+// renamed test keeps its history.
+type TestRenames struct {
+	newNameByOldName map[string]string
+}
+
+// NewTestRenames reads a YAML (or JSON) list of TestRename from path.  An empty path returns nil, which is a
+// valid TestRenames that never renames anything.
+func NewTestRenames(path string) (*TestRenames, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test rename file %q: %w", path, err)
+	}
+	renames := []TestRename{}
+	if err := yaml.Unmarshal(data, &renames); err != nil {
+		return nil, fmt.Errorf("failed to parse test rename file %q: %w", path, err)
+	}
+	return newTestRenames(renames)
+}
+
+func newTestRenames(renames []TestRename) (*TestRenames, error) {
+	ret := &TestRenames{newNameByOldName: map[string]string{}}
+	for _, rename := range renames {
+		if len(rename.From) == 0 || len(rename.To) == 0 {
+			return nil, fmt.Errorf("test rename %q -> %q must specify both names", rename.From, rename.To)
+		}
+		if existing, ok := ret.newNameByOldName[rename.From]; ok && existing != rename.To {
+			return nil, fmt.Errorf("test %q is renamed to both %q and %q", rename.From, existing, rename.To)
+		}
+		ret.newNameByOldName[rename.From] = rename.To
+	}
+	// a test may have been renamed more than once, make sure following the chain ends
+	for oldName := range ret.newNameByOldName {
+		seen := map[string]bool{oldName: true}
+		for name, ok := ret.newNameByOldName[oldName]; ok; name, ok = ret.newNameByOldName[name] {
+			if seen[name] {
+				return nil, fmt.Errorf("test renames starting at %q form a cycle", oldName)
+			}
+			seen[name] = true
+		}
+	}
+	return ret, nil
+}
+
+// CurrentName returns the name testName has now, following renames of renames.
+func (t *TestRenames) CurrentName(testName string) string {
+	if t == nil {
+		return testName
+	}
+	for {
+		newName, ok := t.newNameByOldName[testName]
+		if !ok {
+			return testName
+		}
+		testName = newName
+	}
+}
+
+// RenameTestCases renames every test case in the suites read from junit.
+func (t *TestRenames) RenameTestCases(testSuites *junit.TestSuites) {
+	if t == nil || testSuites == nil {
+		return
+	}
+	for _, suite := range testSuites.Suites {
+		t.renameTestCasesInSuite(suite)
+	}
+}
+
+func (t *TestRenames) renameTestCasesInSuite(suite *junit.TestSuite) {
+	for _, testCase := range suite.TestCases {
+		testCase.Name = t.CurrentName(testCase.Name)
+	}
+	for _, child := range suite.Children {
+		t.renameTestCasesInSuite(child)
+	}
+}
+
+// RenameAggregatedTestRuns renames the historical rows.  When history has rows for both the old and the new name,
+// because the rename happened during the aggregation period, they are combined into one row.
+func (t *TestRenames) RenameAggregatedTestRuns(rows []jobrunaggregatorapi.AggregatedTestRunRow) []jobrunaggregatorapi.AggregatedTestRunRow {
+	if t == nil {
+		return rows
+	}
+	type rowKey struct {
+		testName  string
+		testSuite string
+		jobName   string
+	}
+	ret := []jobrunaggregatorapi.AggregatedTestRunRow{}
+	indexByKey := map[rowKey]int{}
+	for _, row := range rows {
+		row.TestName = t.CurrentName(row.TestName)
+		key := rowKey{testName: row.TestName, testSuite: row.TestSuiteName.StringVal, jobName: row.JobName}
+		i, ok := indexByKey[key]
+		if !ok {
+			indexByKey[key] = len(ret)
+			ret = append(ret, row)
+			continue
+		}
+		combined := &ret[i]
+		combined.PassCount += row.PassCount
+		combined.FailCount += row.FailCount
+		combined.FlakeCount += row.FlakeCount
+		if total := combined.PassCount + combined.FailCount + combined.FlakeCount; total > 0 {
+			combined.PassPercentage = float64(combined.PassCount) * 100 / float64(total)
+			combined.WorkingPercentage = float64(combined.PassCount+combined.FlakeCount) * 100 / float64(total)
+		}
+	}
+	return ret
+}
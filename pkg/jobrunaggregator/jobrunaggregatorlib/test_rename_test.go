@@ -0,0 +1,97 @@
+package jobrunaggregatorlib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+func TestTestRenamesRenameTestCases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "renames.yaml")
+	renamesYAML := `
+- from: '[sig-network] old name'
+  to: '[sig-network] intermediate name'
+- from: '[sig-network] intermediate name'
+  to: '[sig-network] new name'
+`
+	if err := os.WriteFile(path, []byte(renamesYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	renames, err := NewTestRenames(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testSuites := &junit.TestSuites{Suites: []*junit.TestSuite{{
+		TestCases: []*junit.TestCase{{Name: "[sig-network] old name"}},
+		Children: []*junit.TestSuite{{
+			TestCases: []*junit.TestCase{{Name: "[sig-network] intermediate name"}, {Name: "[sig-storage] unrelated"}},
+		}},
+	}}}
+	renames.RenameTestCases(testSuites)
+
+	assert.Equal(t, "[sig-network] new name", testSuites.Suites[0].TestCases[0].Name)
+	assert.Equal(t, "[sig-network] new name", testSuites.Suites[0].Children[0].TestCases[0].Name)
+	assert.Equal(t, "[sig-storage] unrelated", testSuites.Suites[0].Children[0].TestCases[1].Name)
+
+	var noRenames *TestRenames
+	assert.Equal(t, "[sig-network] old name", noRenames.CurrentName("[sig-network] old name"))
+}
+
+func TestNewTestRenamesInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		renames     []TestRename
+		expectedErr string
+	}{
+		{
+			name:        "missing new name",
+			renames:     []TestRename{{From: "a"}},
+			expectedErr: `test rename "a" -> "" must specify both names`,
+		},
+		{
+			name:        "conflicting renames",
+			renames:     []TestRename{{From: "a", To: "b"}, {From: "a", To: "c"}},
+			expectedErr: `test "a" is renamed to both "b" and "c"`,
+		},
+		{
+			name:        "cycle",
+			renames:     []TestRename{{From: "a", To: "b"}, {From: "b", To: "a"}},
+			expectedErr: "form a cycle",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestRenames(tt.renames)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestTestRenamesRenameAggregatedTestRuns(t *testing.T) {
+	renames, err := newTestRenames([]TestRename{{From: "old", To: "new"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite := bigquery.NullString{StringVal: "openshift-tests", Valid: true}
+	rows := []jobrunaggregatorapi.AggregatedTestRunRow{
+		{TestName: "old", TestSuiteName: suite, JobName: "job", PassCount: 6, FailCount: 2, PassPercentage: 75, WorkingPercentage: 75},
+		{TestName: "new", TestSuiteName: suite, JobName: "job", PassCount: 1, FailCount: 0, FlakeCount: 1, PassPercentage: 50, WorkingPercentage: 100},
+		{TestName: "other", TestSuiteName: suite, JobName: "job", PassCount: 1, PassPercentage: 100, WorkingPercentage: 100},
+	}
+
+	actual := renames.RenameAggregatedTestRuns(rows)
+	assert.Equal(t, []jobrunaggregatorapi.AggregatedTestRunRow{
+		{TestName: "new", TestSuiteName: suite, JobName: "job", PassCount: 7, FailCount: 2, FlakeCount: 1, PassPercentage: 70, WorkingPercentage: 80},
+		{TestName: "other", TestSuiteName: suite, JobName: "job", PassCount: 1, PassPercentage: 100, WorkingPercentage: 100},
+	}, actual)
+	assert.Equal(t, "old", rows[0].TestName, "the rows read from history are not modified")
+}
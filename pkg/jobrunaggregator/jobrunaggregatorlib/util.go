@@ -139,6 +139,15 @@ func getAllFinishedJobRuns(ctx context.Context, relatedJobRuns []jobrunaggregato
 type BigQueryJobRunWaiter struct {
 	JobRunGetter      JobRunGetter
 	TimeToStopWaiting time.Time
+
+	// AdaptiveWait optionally replaces TimeToStopWaiting with a per-job time based on historical job run durations.
+	AdaptiveWait *AdaptiveWait
+	// Progress is optionally told how many job runs are finished every time they are checked.
+	Progress ProgressReporter
+
+	// DoneWaiting optionally stops the wait before every job run finished, like when the finished job runs already
+	// decide the verdict.  It is asked every time job runs are checked, as long as some are unfinished.
+	DoneWaiting func(ctx context.Context, finishedJobRuns []jobrunaggregatorapi.JobRunInfo) bool
 }
 
 func (w *BigQueryJobRunWaiter) Wait(ctx context.Context) ([]JobRunIdentifier, error) {
@@ -148,6 +157,26 @@ func (w *BigQueryJobRunWaiter) Wait(ctx context.Context) ([]JobRunIdentifier, er
 		return nil, err
 	}
 
+	jobStopWaitingTimes := map[string]time.Time{}
+	if w.AdaptiveWait != nil && len(relatedJobRuns) > 0 {
+		jobNames := sets.Set[string]{}
+		for _, jobRun := range relatedJobRuns {
+			jobNames.Insert(jobRun.GetJobName())
+		}
+		jobStopWaitingTimes, err = w.AdaptiveWait.StopWaitingTimes(ctx, sets.List(jobNames))
+		if err != nil {
+			// fixed stop-waiting times still work, so don't fail the whole analysis
+			logrus.WithError(err).Warn("falling back to a fixed stop-waiting time")
+			jobStopWaitingTimes = map[string]time.Time{}
+		}
+	}
+	stopWaitingTimeFor := func(jobName string) time.Time {
+		if stopWaiting, ok := jobStopWaitingTimes[jobName]; ok {
+			return stopWaiting
+		}
+		return w.TimeToStopWaiting
+	}
+
 	var finishedJobRuns, unfinishedJobRuns []jobrunaggregatorapi.JobRunInfo
 	var unfinishedJobRunNames []string
 
@@ -155,17 +184,40 @@ func (w *BigQueryJobRunWaiter) Wait(ctx context.Context) ([]JobRunIdentifier, er
 		fmt.Println() // for prettier logs
 
 		finishedJobRuns, unfinishedJobRuns, _, unfinishedJobRunNames = getAllFinishedJobRuns(ctx, relatedJobRuns)
+		if w.Progress != nil {
+			w.Progress.JobRunsStatus(len(finishedJobRuns), len(unfinishedJobRuns))
+		}
 
-		// ready or not, it's time to check
-		if clock.Now().After(w.TimeToStopWaiting) {
-			logrus.Infof("waited long enough. Ready or not, here I come. (readyOrNot=%v now=%v)", w.TimeToStopWaiting, clock.Now())
+		// ready or not, it's time to check.  Each unfinished job run is only waited on until its job's stop-waiting time.
+		now := clock.Now()
+		var nextStopWaiting time.Time
+		for _, jobRun := range unfinishedJobRuns {
+			stopWaiting := stopWaitingTimeFor(jobRun.GetJobName())
+			if now.After(stopWaiting) {
+				continue
+			}
+			if nextStopWaiting.IsZero() || stopWaiting.Before(nextStopWaiting) {
+				nextStopWaiting = stopWaiting
+			}
+		}
+		if len(unfinishedJobRuns) > 0 && nextStopWaiting.IsZero() {
+			logrus.Infof("waited long enough. Ready or not, here I come. (readyOrNot=%v now=%v)", w.TimeToStopWaiting, now)
+			break
+		}
+		if len(unfinishedJobRuns) > 0 && w.DoneWaiting != nil && w.DoneWaiting(ctx, finishedJobRuns) {
+			logrus.Infof("not waiting for %d unfinished jobRuns, the finished ones are enough", len(unfinishedJobRuns))
 			break
 		}
 
 		if len(unfinishedJobRunNames) > 0 {
 			logrus.Infof("found %d unfinished related jobRuns: %v\n", len(unfinishedJobRunNames), strings.Join(unfinishedJobRunNames, ", "))
+			// check again early if that is when we would stop waiting for some job
+			pollInterval := 10 * time.Minute
+			if untilStopWaiting := nextStopWaiting.Sub(now) + time.Second; untilStopWaiting < pollInterval {
+				pollInterval = untilStopWaiting
+			}
 			select {
-			case <-time.After(10 * time.Minute):
+			case <-time.After(pollInterval):
 				continue
 			case <-ctx.Done():
 				return nil, ctx.Err()
@@ -202,18 +254,34 @@ type ClusterJobRunWaiter struct {
 	ProwJobClient      *prowjobclientset.Clientset
 	TimeToStopWaiting  time.Time
 	ProwJobMatcherFunc ProwJobMatcherFunc
+	// SampleJobRuns, when set, returns which of the matched job runs of a job are waited on.  The others are ignored.
+	SampleJobRuns func(jobName string, jobRunIDs []string) sets.Set[string]
 }
 
 func (w *ClusterJobRunWaiter) allProwJobsFinished(allItems []*prowv1.ProwJob) (bool, map[string]*prowv1.ProwJob) {
 	uncompletedJobMap := map[string]*prowv1.ProwJob{}
 	matchedJobMap := map[string]*prowv1.ProwJob{}
 
+	jobRunIDsByJob := map[string][]string{}
 	for _, prowJob := range allItems {
 		if !w.ProwJobMatcherFunc(prowJob) {
 			continue
 		}
 		jobRunID := prowJob.Labels[prowJobJobRunIDLabel]
 		matchedJobMap[jobRunID] = prowJob
+		jobRunIDsByJob[prowJob.Spec.Job] = append(jobRunIDsByJob[prowJob.Spec.Job], jobRunID)
+	}
+	if w.SampleJobRuns != nil {
+		for jobName, jobRunIDs := range jobRunIDsByJob {
+			sampled := w.SampleJobRuns(jobName, jobRunIDs)
+			for _, jobRunID := range jobRunIDs {
+				if !sampled.Has(jobRunID) {
+					delete(matchedJobMap, jobRunID)
+				}
+			}
+		}
+	}
+	for jobRunID, prowJob := range matchedJobMap {
 		if prowJob.Status.CompletionTime != nil {
 			continue
 		}
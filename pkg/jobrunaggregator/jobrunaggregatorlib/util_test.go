@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 )
 
@@ -24,6 +25,7 @@ func TestAllProwJobFinished(t *testing.T) {
 		name               string
 		allItems           []*prowv1.ProwJob
 		ProwJobMatcherFunc ProwJobMatcherFunc
+		sampleJobRuns      func(jobName string, jobRunIDs []string) sets.Set[string]
 		result             bool
 	}{
 		{
@@ -186,12 +188,47 @@ func TestAllProwJobFinished(t *testing.T) {
 			},
 			result: false,
 		},
+		{
+			name:               "Uncompleted job run dropped by sampling test",
+			ProwJobMatcherFunc: fakeProwJobMatcherFunc,
+			sampleJobRuns: func(jobName string, jobRunIDs []string) sets.Set[string] {
+				return sets.New[string](jobRunIDs...).Intersection(sets.New("Job3"))
+			},
+			allItems: []*prowv1.ProwJob{
+				{
+					Spec:   prowv1.ProwJobSpec{Job: "job"},
+					Status: prowv1.ProwJobStatus{},
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							prowJobJobRunIDLabel: "Job1",
+							fakeMatchingLabel:    "match",
+						},
+					},
+				},
+				{
+					Spec: prowv1.ProwJobSpec{Job: "job"},
+					Status: prowv1.ProwJobStatus{
+						CompletionTime: &metav1.Time{
+							Time: time.Now(),
+						},
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							prowJobJobRunIDLabel: "Job3",
+							fakeMatchingLabel:    "match",
+						},
+					},
+				},
+			},
+			result: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			waiter := ClusterJobRunWaiter{
 				TimeToStopWaiting:  time.Now(),
 				ProwJobMatcherFunc: tt.ProwJobMatcherFunc,
+				SampleJobRuns:      tt.sampleJobRuns,
 			}
 			result, _ := waiter.allProwJobsFinished(tt.allItems)
 			assert.Equal(t, tt.result, result, "Test %s expecting %v, got %v", tt.name, tt.result, result)
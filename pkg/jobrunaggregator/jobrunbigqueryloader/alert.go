@@ -7,6 +7,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/bigquery"
 	"github.com/sirupsen/logrus"
@@ -21,27 +22,53 @@ import (
 
 type BigQueryAlertUploadFlags struct {
 	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
+	QueryCost       *jobrunaggregatorlib.QueryCostFlags
 	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
-
-	DryRun    bool
-	LogLevel  string
-	GCSBucket string
+	LoadExceptions  *JobRunLoadExceptionFlags
+	ArtifactCache   *jobrunaggregatorlib.ArtifactCacheFlags
+	InsertBatch     *jobrunaggregatorlib.InsertBatchFlags
+	LoadJob         *jobrunaggregatorlib.LoadJobFlags
+	DryRunOutput    *jobrunaggregatorlib.DryRunOutputFlags
+
+	DryRun        bool
+	LogLevel      string
+	GCSBucket     string
+	ProwJobStates []string
+	// UseJobRunCheckpoints needs the JobRunCheckpoints table
+	UseJobRunCheckpoints bool
+	// RecordJunitArtifactStats is only offered here because the alert loader is the one importing every job run.
+	RecordJunitArtifactStats bool
 }
 
 func NewBigQueryAlertUploadFlags() *BigQueryAlertUploadFlags {
 	return &BigQueryAlertUploadFlags{
 		DataCoordinates: jobrunaggregatorlib.NewBigQueryDataCoordinates(),
+		QueryCost:       jobrunaggregatorlib.NewQueryCostFlags(),
 		Authentication:  jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+		LoadExceptions:  NewJobRunLoadExceptionFlags(),
+		ArtifactCache:   jobrunaggregatorlib.NewArtifactCacheFlags(),
+		InsertBatch:     jobrunaggregatorlib.NewInsertBatchFlags(),
+		LoadJob:         jobrunaggregatorlib.NewLoadJobFlags(),
+		DryRunOutput:    jobrunaggregatorlib.NewDryRunOutputFlags(),
 	}
 }
 
 func (f *BigQueryAlertUploadFlags) BindFlags(fs *pflag.FlagSet) {
 	f.DataCoordinates.BindFlags(fs)
+	f.QueryCost.BindFlags(fs)
 	f.Authentication.BindFlags(fs)
+	f.LoadExceptions.BindFlags(fs)
+	f.ArtifactCache.BindFlags(fs)
+	f.InsertBatch.BindFlags(fs)
+	f.LoadJob.BindFlags(fs)
+	f.DryRunOutput.BindFlags(fs)
 
 	fs.BoolVar(&f.DryRun, "dry-run", f.DryRun, "Run the command, but don't mutate data.")
 	fs.StringVar(&f.LogLevel, "log-level", "info", "Log level (trace,debug,info,warn,error) (default: info)")
 	fs.StringVar(&f.GCSBucket, "google-storage-bucket", "test-platform-results", "The optional GCS Bucket holding test artifacts")
+	fs.BoolVar(&f.RecordJunitArtifactStats, "record-junit-artifact-stats", f.RecordJunitArtifactStats, "Also record the junit file count, size and parse duration of every job run in the "+jobrunaggregatorapi.JunitArtifactStatsTableName+" table. This downloads every junit file.")
+	fs.StringSliceVar(&f.ProwJobStates, "prowjob-state", f.ProwJobStates, "Only load job runs whose prowjob is in one of these states (success,failure,aborted,error). Runs in other states are skipped before their junit is read. Default: all states.")
+	fs.BoolVar(&f.UseJobRunCheckpoints, "use-job-run-checkpoints", f.UseJobRunCheckpoints, "Skip the job runs before the last checkpoint of their job, and commit new checkpoints in the "+jobrunaggregatorapi.JobRunCheckpointsTableName+" table once job runs are loaded.")
 }
 
 func NewBigQueryAlertUploadFlagsCommand() *cobra.Command {
@@ -83,9 +110,27 @@ func (f *BigQueryAlertUploadFlags) Validate() error {
 	if err := f.DataCoordinates.Validate(); err != nil {
 		return err
 	}
+	if err := f.QueryCost.Validate(); err != nil {
+		return err
+	}
 	if err := f.Authentication.Validate(); err != nil {
 		return err
 	}
+	if err := f.LoadExceptions.Validate(); err != nil {
+		return err
+	}
+	if err := f.ArtifactCache.Validate(); err != nil {
+		return err
+	}
+	if err := f.InsertBatch.Validate(); err != nil {
+		return err
+	}
+	if err := f.LoadJob.Validate(); err != nil {
+		return err
+	}
+	if _, err := jobrunaggregatorlib.ParseProwJobStates(f.ProwJobStates); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -93,8 +138,17 @@ func (f *BigQueryAlertUploadFlags) Validate() error {
 // ToOptions goes from the user input to the runtime values need to run the command.
 // Expect to see unit tests on the options, but not on the flags which are simply value mappings.
 func (f *BigQueryAlertUploadFlags) ToOptions(ctx context.Context) (*allJobsLoaderOptions, error) {
+	prowJobStates, err := jobrunaggregatorlib.ParseProwJobStates(f.ProwJobStates)
+	if err != nil {
+		return nil, err
+	}
+
+	// the loaders have no working dir to cache in by default
+	gcsOptions := jobrunaggregatorlib.DefaultCIGCSClientOptions
+	gcsOptions.ArtifactCache = f.ArtifactCache.NewArtifactCache("")
+
 	// Create a new GCS Client
-	gcsClient, err := f.Authentication.NewCIGCSClient(ctx, f.GCSBucket)
+	gcsClient, err := f.Authentication.NewCIGCSClient(ctx, f.GCSBucket, gcsOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -104,25 +158,59 @@ func (f *BigQueryAlertUploadFlags) ToOptions(ctx context.Context) (*allJobsLoade
 		return nil, err
 	}
 	ciDataClient := jobrunaggregatorlib.NewRetryingCIDataClient(
-		jobrunaggregatorlib.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
+		f.QueryCost.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
 	)
 
-	var backendAlertTableInserter jobrunaggregatorlib.BigQueryInserter
+	var backendAlertTableInserter, jobRunLoadExceptionInserter, junitArtifactStatsInserter, jobRunCheckpointInserter jobrunaggregatorlib.BigQueryInserter
 	if !f.DryRun {
 		ciDataSet := bigQueryClient.Dataset(f.DataCoordinates.DataSetID)
 		backendAlertTable := ciDataSet.Table(jobrunaggregatorapi.AlertsTableName)
 		backendAlertTableInserter = backendAlertTable.Inserter()
+		junitArtifactStatsTable := ciDataSet.Table(jobrunaggregatorapi.JunitArtifactStatsTableName)
+		junitArtifactStatsInserter = junitArtifactStatsTable.Inserter()
+		if f.LoadJob.Enabled() {
+			storageClient, err := f.Authentication.NewGCSClient(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if backendAlertTableInserter, err = f.LoadJob.NewInserter(storageClient, backendAlertTable); err != nil {
+				return nil, err
+			}
+			if junitArtifactStatsInserter, err = f.LoadJob.NewInserter(storageClient, junitArtifactStatsTable); err != nil {
+				return nil, err
+			}
+		}
+		jobRunLoadExceptionInserter = ciDataSet.Table(jobrunaggregatorapi.JobRunLoadExceptionsTableName).Inserter()
+		if f.UseJobRunCheckpoints {
+			jobRunCheckpointInserter = ciDataSet.Table(jobrunaggregatorapi.JobRunCheckpointsTableName).Inserter()
+		}
 	} else {
-		backendAlertTableInserter = jobrunaggregatorlib.NewDryRunInserter(os.Stdout, jobrunaggregatorapi.AlertsTableName)
+		backendAlertTableInserter = f.DryRunOutput.NewInserter(os.Stdout, jobrunaggregatorapi.AlertsTableName)
+		jobRunLoadExceptionInserter = f.DryRunOutput.NewInserter(os.Stdout, jobrunaggregatorapi.JobRunLoadExceptionsTableName)
+		if f.UseJobRunCheckpoints {
+			jobRunCheckpointInserter = f.DryRunOutput.NewInserter(os.Stdout, jobrunaggregatorapi.JobRunCheckpointsTableName)
+		}
+		junitArtifactStatsInserter = f.DryRunOutput.NewInserter(os.Stdout, jobrunaggregatorapi.JunitArtifactStatsTableName)
+	}
+	alertBatchingInserter := jobrunaggregatorlib.NewBatchingInserter(backendAlertTableInserter, f.InsertBatch.BatchSize)
+	junitArtifactStatsBatchingInserter := jobrunaggregatorlib.NewBatchingInserter(junitArtifactStatsInserter, f.InsertBatch.BatchSize)
+	jobRunLoadExceptions, err := f.LoadExceptions.toExceptions(time.Now())
+	if err != nil {
+		return nil, err
 	}
 	pendingUploadLister := newAlertPendingUploadLister(ciDataClient)
-	alertUploader, err := newAlertUploader(backendAlertTableInserter, ciDataClient)
+	alertUploader, err := newAlertUploader(alertBatchingInserter, ciDataClient)
 	if err != nil {
 		return nil, err
 	}
 
 	jobRunUploaderRegistry := JobRunUploaderRegistry{}
 	jobRunUploaderRegistry.Register("alertUploader", alertUploader)
+	reprocessTables := []string{jobrunaggregatorapi.AlertsTableName}
+	if f.RecordJunitArtifactStats {
+		jobRunUploaderRegistry.Register("junitArtifactStatsUploader", newJunitArtifactStatsUploader(junitArtifactStatsBatchingInserter))
+		reprocessTables = append(reprocessTables, jobrunaggregatorapi.JunitArtifactStatsTableName)
+	}
 	return &allJobsLoaderOptions{
 		ciDataClient: ciDataClient,
 		gcsClient:    gcsClient,
@@ -130,9 +218,17 @@ func (f *BigQueryAlertUploadFlags) ToOptions(ctx context.Context) (*allJobsLoade
 		shouldCollectedDataForJobFn: func(job jobrunaggregatorapi.JobRowWithVariants) bool {
 			return true
 		},
-		jobRunUploaderRegistry:  jobRunUploaderRegistry,
-		pendingUploadJobsLister: pendingUploadLister,
-		logLevel:                f.LogLevel,
+		jobRunUploaderRegistry:      jobRunUploaderRegistry,
+		pendingUploadJobsLister:     pendingUploadLister,
+		logLevel:                    f.LogLevel,
+		prowJobMatcherFunc:          jobrunaggregatorlib.NewProwJobMatcherFuncForStates(prowJobStates),
+		jobRunLoadExceptions:        jobRunLoadExceptions,
+		jobRunLoadExceptionInserter: jobRunLoadExceptionInserter,
+		reprocessTables:             reprocessTables,
+		dryRun:                      f.DryRun,
+		loaderName:                  "alert",
+		jobRunCheckpointInserter:    jobRunCheckpointInserter,
+		batchingInserters:           []jobrunaggregatorlib.BatchingInserter{alertBatchingInserter, junitArtifactStatsBatchingInserter},
 	}, nil
 }
 
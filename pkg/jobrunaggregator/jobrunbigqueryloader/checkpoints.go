@@ -0,0 +1,96 @@
+package jobrunbigqueryloader
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// jobRunIDLess orders job run IDs, which are numbers growing with time
+func jobRunIDLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// jobRunCheckpointTracker records the outcome of loading the job runs queued by a loader run, to find how far every
+// job can be checkpointed.  It is called by the concurrent workers.
+type jobRunCheckpointTracker struct {
+	lock sync.Mutex
+	// loaded and failed are the job run IDs by job name.  Job runs which are not ready to be loaded yet count as
+	// failed, job runs which are not loaded on purpose are not recorded.
+	loaded map[string][]string
+	failed map[string][]string
+}
+
+func newJobRunCheckpointTracker() *jobRunCheckpointTracker {
+	return &jobRunCheckpointTracker{
+		loaded: map[string][]string{},
+		failed: map[string][]string{},
+	}
+}
+
+func (t *jobRunCheckpointTracker) record(jobName, jobRunID string, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if err != nil {
+		t.failed[jobName] = append(t.failed[jobName], jobRunID)
+		return
+	}
+	t.loaded[jobName] = append(t.loaded[jobName], jobRunID)
+}
+
+// uploadedJobRuns returns the IDs of the job runs that every uploader uploaded, by job name
+func (t *jobRunCheckpointTracker) uploadedJobRuns() map[string][]string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	ret := map[string][]string{}
+	for jobName, loaded := range t.loaded {
+		ret[jobName] = append([]string{}, loaded...)
+	}
+	return ret
+}
+
+// checkpoints returns the new checkpoint of every job that moved past its previous one.  A job is checkpointed at its
+// last loaded job run before the first one that failed, so that failed job runs are retried by the next loader run.
+// completedBefore is when the queued job runs were listed, they all completed before it.
+func (t *jobRunCheckpointTracker) checkpoints(loader string, previous map[string]jobrunaggregatorapi.JobRunCheckpointRow, completedBefore, now time.Time) []jobrunaggregatorapi.JobRunCheckpointRow {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	ret := []jobrunaggregatorapi.JobRunCheckpointRow{}
+	for jobName, loaded := range t.loaded {
+		firstFailed := ""
+		for _, jobRunID := range t.failed[jobName] {
+			if len(firstFailed) == 0 || jobRunIDLess(jobRunID, firstFailed) {
+				firstFailed = jobRunID
+			}
+		}
+		last := ""
+		for _, jobRunID := range loaded {
+			if len(firstFailed) > 0 && !jobRunIDLess(jobRunID, firstFailed) {
+				continue
+			}
+			if len(last) == 0 || jobRunIDLess(last, jobRunID) {
+				last = jobRunID
+			}
+		}
+		if len(last) == 0 {
+			continue
+		}
+		if checkpoint, ok := previous[jobName]; ok && !jobRunIDLess(checkpoint.JobRunName, last) {
+			continue
+		}
+		ret = append(ret, jobrunaggregatorapi.JobRunCheckpointRow{
+			Loader:          loader,
+			JobName:         jobName,
+			JobRunName:      last,
+			CompletedBefore: completedBefore,
+			CommittedTime:   now,
+		})
+	}
+	return ret
+}
@@ -0,0 +1,41 @@
+package jobrunbigqueryloader
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func TestJobRunCheckpointTracker(t *testing.T) {
+	listed := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	now := listed.Add(time.Hour)
+
+	tracker := newJobRunCheckpointTracker()
+	// job-a is checkpointed at its last job run
+	tracker.record("job-a", "999", nil)
+	tracker.record("job-a", "1000", nil)
+	// job-b stops before its failed job run, so that it is retried
+	tracker.record("job-b", "2000", nil)
+	tracker.record("job-b", "2001", errors.New("failed"))
+	tracker.record("job-b", "2002", nil)
+	// job-c failed its only job run
+	tracker.record("job-c", "3000", errors.New("failed"))
+	// job-d only loaded a job run reprocessed before its checkpoint
+	tracker.record("job-d", "4000", nil)
+
+	previous := map[string]jobrunaggregatorapi.JobRunCheckpointRow{
+		"job-a": {JobName: "job-a", JobRunName: "998"},
+		"job-d": {JobName: "job-d", JobRunName: "4500"},
+	}
+	checkpoints := tracker.checkpoints("alert", previous, listed, now)
+	sort.Slice(checkpoints, func(i, j int) bool { return checkpoints[i].JobName < checkpoints[j].JobName })
+	assert.Equal(t, []jobrunaggregatorapi.JobRunCheckpointRow{
+		{Loader: "alert", JobName: "job-a", JobRunName: "1000", CompletedBefore: listed, CommittedTime: now},
+		{Loader: "alert", JobName: "job-b", JobRunName: "2000", CompletedBefore: listed, CommittedTime: now},
+	}, checkpoints)
+}
@@ -3,6 +3,7 @@ package jobrunbigqueryloader
 import (
 	"context"
 	"os"
+	"time"
 
 	"cloud.google.com/go/bigquery"
 	"github.com/sirupsen/logrus"
@@ -17,27 +18,50 @@ import (
 
 type BigQueryDisruptionUploadFlags struct {
 	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
+	QueryCost       *jobrunaggregatorlib.QueryCostFlags
 	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
-
-	DryRun    bool
-	LogLevel  string
-	GCSBucket string
+	LoadExceptions  *JobRunLoadExceptionFlags
+	ArtifactCache   *jobrunaggregatorlib.ArtifactCacheFlags
+	InsertBatch     *jobrunaggregatorlib.InsertBatchFlags
+	LoadJob         *jobrunaggregatorlib.LoadJobFlags
+	DryRunOutput    *jobrunaggregatorlib.DryRunOutputFlags
+
+	DryRun        bool
+	LogLevel      string
+	GCSBucket     string
+	ProwJobStates []string
+	// UseJobRunCheckpoints needs the JobRunCheckpoints table
+	UseJobRunCheckpoints bool
 }
 
 func NewBigQueryDisruptionUploadFlags() *BigQueryDisruptionUploadFlags {
 	return &BigQueryDisruptionUploadFlags{
 		DataCoordinates: jobrunaggregatorlib.NewBigQueryDataCoordinates(),
+		QueryCost:       jobrunaggregatorlib.NewQueryCostFlags(),
 		Authentication:  jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+		LoadExceptions:  NewJobRunLoadExceptionFlags(),
+		ArtifactCache:   jobrunaggregatorlib.NewArtifactCacheFlags(),
+		InsertBatch:     jobrunaggregatorlib.NewInsertBatchFlags(),
+		LoadJob:         jobrunaggregatorlib.NewLoadJobFlags(),
+		DryRunOutput:    jobrunaggregatorlib.NewDryRunOutputFlags(),
 	}
 }
 
 func (f *BigQueryDisruptionUploadFlags) BindFlags(fs *pflag.FlagSet) {
 	f.DataCoordinates.BindFlags(fs)
+	f.QueryCost.BindFlags(fs)
 	f.Authentication.BindFlags(fs)
+	f.LoadExceptions.BindFlags(fs)
+	f.ArtifactCache.BindFlags(fs)
+	f.InsertBatch.BindFlags(fs)
+	f.LoadJob.BindFlags(fs)
+	f.DryRunOutput.BindFlags(fs)
 
 	fs.BoolVar(&f.DryRun, "dry-run", f.DryRun, "Run the command, but don't mutate data.")
 	fs.StringVar(&f.LogLevel, "log-level", "info", "Log level (trace,debug,info,warn,error) (default: info)")
 	fs.StringVar(&f.GCSBucket, "google-storage-bucket", "test-platform-results", "The optional GCS Bucket holding test artifacts")
+	fs.StringSliceVar(&f.ProwJobStates, "prowjob-state", f.ProwJobStates, "Only load job runs whose prowjob is in one of these states (success,failure,aborted,error). Runs in other states are skipped before their junit is read. Default: all states.")
+	fs.BoolVar(&f.UseJobRunCheckpoints, "use-job-run-checkpoints", f.UseJobRunCheckpoints, "Skip the job runs before the last checkpoint of their job, and commit new checkpoints in the "+jobrunaggregatorapi.JobRunCheckpointsTableName+" table once job runs are loaded.")
 }
 
 func NewBigQueryDisruptionUploadFlagsCommand() *cobra.Command {
@@ -79,9 +103,27 @@ func (f *BigQueryDisruptionUploadFlags) Validate() error {
 	if err := f.DataCoordinates.Validate(); err != nil {
 		return err
 	}
+	if err := f.QueryCost.Validate(); err != nil {
+		return err
+	}
 	if err := f.Authentication.Validate(); err != nil {
 		return err
 	}
+	if err := f.LoadExceptions.Validate(); err != nil {
+		return err
+	}
+	if err := f.ArtifactCache.Validate(); err != nil {
+		return err
+	}
+	if err := f.InsertBatch.Validate(); err != nil {
+		return err
+	}
+	if err := f.LoadJob.Validate(); err != nil {
+		return err
+	}
+	if _, err := jobrunaggregatorlib.ParseProwJobStates(f.ProwJobStates); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -89,8 +131,17 @@ func (f *BigQueryDisruptionUploadFlags) Validate() error {
 // ToOptions goes from the user input to the runtime values need to run the command.
 // Expect to see unit tests on the options, but not on the flags which are simply value mappings.
 func (f *BigQueryDisruptionUploadFlags) ToOptions(ctx context.Context) (*allJobsLoaderOptions, error) {
+	prowJobStates, err := jobrunaggregatorlib.ParseProwJobStates(f.ProwJobStates)
+	if err != nil {
+		return nil, err
+	}
+
+	// the loaders have no working dir to cache in by default
+	gcsOptions := jobrunaggregatorlib.DefaultCIGCSClientOptions
+	gcsOptions.ArtifactCache = f.ArtifactCache.NewArtifactCache("")
+
 	// Create a new GCS Client
-	gcsClient, err := f.Authentication.NewCIGCSClient(ctx, f.GCSBucket)
+	gcsClient, err := f.Authentication.NewCIGCSClient(ctx, f.GCSBucket, gcsOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -100,21 +151,43 @@ func (f *BigQueryDisruptionUploadFlags) ToOptions(ctx context.Context) (*allJobs
 		return nil, err
 	}
 	ciDataClient := jobrunaggregatorlib.NewRetryingCIDataClient(
-		jobrunaggregatorlib.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
+		f.QueryCost.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
 	)
 
-	var backendDisruptionTableInserter jobrunaggregatorlib.BigQueryInserter
+	var backendDisruptionTableInserter, jobRunLoadExceptionInserter, jobRunCheckpointInserter jobrunaggregatorlib.BigQueryInserter
 	if !f.DryRun {
 		ciDataSet := bigQueryClient.Dataset(f.DataCoordinates.DataSetID)
 		backendDisruptionTable := ciDataSet.Table(jobrunaggregatorapi.BackendDisruptionTableName)
 		backendDisruptionTableInserter = backendDisruptionTable.Inserter()
+		if f.LoadJob.Enabled() {
+			storageClient, err := f.Authentication.NewGCSClient(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if backendDisruptionTableInserter, err = f.LoadJob.NewInserter(storageClient, backendDisruptionTable); err != nil {
+				return nil, err
+			}
+		}
+		jobRunLoadExceptionInserter = ciDataSet.Table(jobrunaggregatorapi.JobRunLoadExceptionsTableName).Inserter()
+		if f.UseJobRunCheckpoints {
+			jobRunCheckpointInserter = ciDataSet.Table(jobrunaggregatorapi.JobRunCheckpointsTableName).Inserter()
+		}
 	} else {
-		backendDisruptionTableInserter = jobrunaggregatorlib.NewDryRunInserter(os.Stdout, jobrunaggregatorapi.BackendDisruptionTableName)
+		backendDisruptionTableInserter = f.DryRunOutput.NewInserter(os.Stdout, jobrunaggregatorapi.BackendDisruptionTableName)
+		jobRunLoadExceptionInserter = f.DryRunOutput.NewInserter(os.Stdout, jobrunaggregatorapi.JobRunLoadExceptionsTableName)
+		if f.UseJobRunCheckpoints {
+			jobRunCheckpointInserter = f.DryRunOutput.NewInserter(os.Stdout, jobrunaggregatorapi.JobRunCheckpointsTableName)
+		}
+	}
+	jobRunLoadExceptions, err := f.LoadExceptions.toExceptions(time.Now())
+	if err != nil {
+		return nil, err
 	}
 
+	disruptionBatchingInserter := jobrunaggregatorlib.NewBatchingInserter(backendDisruptionTableInserter, f.InsertBatch.BatchSize)
 	pendingUploadLister := newDisruptionPendingUploadLister(ciDataClient)
 	jobRunUploaderRegistry := JobRunUploaderRegistry{}
-	jobRunUploaderRegistry.Register("disruptionUploader", newDisruptionUploader(backendDisruptionTableInserter, ciDataClient))
+	jobRunUploaderRegistry.Register("disruptionUploader", newDisruptionUploader(disruptionBatchingInserter, ciDataClient))
 	return &allJobsLoaderOptions{
 		ciDataClient: ciDataClient,
 		gcsClient:    gcsClient,
@@ -123,6 +196,14 @@ func (f *BigQueryDisruptionUploadFlags) ToOptions(ctx context.Context) (*allJobs
 		jobRunUploaderRegistry:      jobRunUploaderRegistry,
 		pendingUploadJobsLister:     pendingUploadLister,
 		logLevel:                    f.LogLevel,
+		prowJobMatcherFunc:          jobrunaggregatorlib.NewProwJobMatcherFuncForStates(prowJobStates),
+		jobRunLoadExceptions:        jobRunLoadExceptions,
+		jobRunLoadExceptionInserter: jobRunLoadExceptionInserter,
+		reprocessTables:             []string{jobrunaggregatorapi.BackendDisruptionTableName},
+		dryRun:                      f.DryRun,
+		loaderName:                  "disruption",
+		jobRunCheckpointInserter:    jobRunCheckpointInserter,
+		batchingInserters:           []jobrunaggregatorlib.BatchingInserter{disruptionBatchingInserter},
 	}, nil
 }
 
@@ -0,0 +1,92 @@
+package jobrunbigqueryloader
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+type junitArtifactStatsUploader struct {
+	statsInserter jobrunaggregatorlib.BigQueryInserter
+}
+
+func newJunitArtifactStatsUploader(statsInserter jobrunaggregatorlib.BigQueryInserter) uploader {
+	return &junitArtifactStatsUploader{
+		statsInserter: statsInserter,
+	}
+}
+
+func (o *junitArtifactStatsUploader) uploadContent(ctx context.Context, jobRun jobrunaggregatorapi.JobRunInfo,
+	jobRelease string, jobRunRow *jobrunaggregatorapi.JobRunRow, logger logrus.FieldLogger) error {
+	logger.Debug("uploading junit artifact stats")
+	row, err := getJunitArtifactStats(ctx, jobRun, time.Now)
+	if err != nil {
+		return err
+	}
+	row.JobRunStartTime = jobRunRow.StartTime
+	row.JobRunEndTime = jobRunRow.EndTime
+	row.Release = jobRelease
+	return o.statsInserter.Put(ctx, []*jobrunaggregatorapi.JunitArtifactStatsRow{row})
+}
+
+func getJunitArtifactStats(ctx context.Context, jobRun jobrunaggregatorapi.JobRunInfo, now func() time.Time) (*jobrunaggregatorapi.JunitArtifactStatsRow, error) {
+	row := &jobrunaggregatorapi.JunitArtifactStatsRow{
+		JobName:    jobRun.GetJobName(),
+		JobRunName: jobRun.GetJobRunID(),
+	}
+	// download everything first, we only want to time the parsing
+	for _, junitPath := range jobRun.GetGCSJunitPaths() {
+		content, err := jobRun.GetContent(ctx, junitPath)
+		if err != nil {
+			return nil, err
+		}
+		size := int64(len(content))
+		row.JunitFileCount++
+		row.JunitTotalBytes += size
+		if size > row.LargestJunitFileBytes {
+			row.LargestJunitFile = junitPath
+			row.LargestJunitFileBytes = size
+		}
+		if isTruncatedXML(content) {
+			row.TruncatedJunitFiles++
+		}
+	}
+
+	start := now()
+	testSuites, err := jobRun.GetCombinedJUnitTestSuites(ctx)
+	if err != nil {
+		return nil, err
+	}
+	row.ParseDurationSeconds = now().Sub(start).Seconds()
+	for _, testSuite := range testSuites.Suites {
+		row.TestCaseCount += countTestCases(testSuite)
+	}
+	return row, nil
+}
+
+// isTruncatedXML is true when the content stops in the middle of the document, usually because the upload of the
+// artifact was interrupted.
+func isTruncatedXML(content []byte) bool {
+	if len(content) == 0 {
+		return false
+	}
+	err := xml.Unmarshal(content, &struct{}{})
+	var syntaxErr *xml.SyntaxError
+	return errors.Is(err, io.ErrUnexpectedEOF) || (errors.As(err, &syntaxErr) && syntaxErr.Msg == "unexpected EOF")
+}
+
+func countTestCases(testSuite *junit.TestSuite) int {
+	count := len(testSuite.TestCases)
+	for _, child := range testSuite.Children {
+		count += countTestCases(child)
+	}
+	return count
+}
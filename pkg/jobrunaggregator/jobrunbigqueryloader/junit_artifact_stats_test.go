@@ -0,0 +1,56 @@
+package jobrunbigqueryloader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+func TestGetJunitArtifactStats(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.TODO()
+	complete := []byte(`<testsuite name="e2e"><testcase name="a"></testcase><testcase name="b"></testcase></testsuite>`)
+	truncated := []byte(`<testsuite name="e2e"><testcase name="a"></testcase><testca`)
+
+	jobRun := jobrunaggregatorapi.NewMockJobRunInfo(mockCtrl)
+	jobRun.EXPECT().GetJobName().Return("job").AnyTimes()
+	jobRun.EXPECT().GetJobRunID().Return("1").AnyTimes()
+	jobRun.EXPECT().GetGCSJunitPaths().Return([]string{"junit/complete.xml", "junit/truncated.xml"})
+	jobRun.EXPECT().GetContent(ctx, "junit/complete.xml").Return(complete, nil)
+	jobRun.EXPECT().GetContent(ctx, "junit/truncated.xml").Return(truncated, nil)
+	jobRun.EXPECT().GetCombinedJUnitTestSuites(ctx).Return(&junit.TestSuites{Suites: []*junit.TestSuite{{
+		Name:      "e2e",
+		TestCases: []*junit.TestCase{{Name: "a"}, {Name: "b"}},
+		Children:  []*junit.TestSuite{{TestCases: []*junit.TestCase{{Name: "c"}}}},
+	}}}, nil)
+
+	start := time.Now()
+	clock := []time.Time{start, start.Add(1500 * time.Millisecond)}
+	now := func() time.Time {
+		ret := clock[0]
+		clock = clock[1:]
+		return ret
+	}
+
+	actual, err := getJunitArtifactStats(ctx, jobRun, now)
+	assert.NoError(t, err)
+	assert.Equal(t, &jobrunaggregatorapi.JunitArtifactStatsRow{
+		JobName:               "job",
+		JobRunName:            "1",
+		JunitFileCount:        2,
+		JunitTotalBytes:       int64(len(complete) + len(truncated)),
+		LargestJunitFile:      "junit/complete.xml",
+		LargestJunitFileBytes: int64(len(complete)),
+		TestCaseCount:         3,
+		ParseDurationSeconds:  1.5,
+		TruncatedJunitFiles:   1,
+	}, actual)
+}
@@ -0,0 +1,125 @@
+package jobrunbigqueryloader
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// JobRunLoadExceptionFlags adds one-off exceptions on top of the ones operators record in the JobRunLoadExceptions table.
+type JobRunLoadExceptionFlags struct {
+	SkipJobRuns      []string
+	ReprocessJobRuns []string
+}
+
+func NewJobRunLoadExceptionFlags() *JobRunLoadExceptionFlags {
+	return &JobRunLoadExceptionFlags{}
+}
+
+func (f *JobRunLoadExceptionFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&f.SkipJobRuns, "skip-job-run", f.SkipJobRuns, "A job run to never load, as <job name>/<job run ID>. Can be repeated. Use the JobRunLoadExceptions table for permanent exceptions.")
+	fs.StringSliceVar(&f.ReprocessJobRuns, "reprocess-job-run", f.ReprocessJobRuns, "A job run to load again even though it was already loaded, as <job name>/<job run ID>. Can be repeated. The rows previously loaded for the job run are deleted before it is loaded again.")
+}
+
+func (f *JobRunLoadExceptionFlags) Validate() error {
+	_, err := f.toExceptions(time.Now())
+	return err
+}
+
+func (f *JobRunLoadExceptionFlags) toExceptions(now time.Time) ([]jobrunaggregatorapi.JobRunLoadExceptionRow, error) {
+	ret := []jobrunaggregatorapi.JobRunLoadExceptionRow{}
+	for action, jobRuns := range map[string][]string{
+		jobrunaggregatorapi.JobRunLoadExceptionSkip:      f.SkipJobRuns,
+		jobrunaggregatorapi.JobRunLoadExceptionReprocess: f.ReprocessJobRuns,
+	} {
+		for _, jobRun := range jobRuns {
+			jobName, jobRunID, ok := strings.Cut(jobRun, "/")
+			if !ok || len(jobName) == 0 || len(jobRunID) == 0 || strings.Contains(jobRunID, "/") {
+				return nil, fmt.Errorf("job run %q must be formatted as <job name>/<job run ID>", jobRun)
+			}
+			ret = append(ret, jobrunaggregatorapi.JobRunLoadExceptionRow{
+				JobName:     jobName,
+				JobRunName:  jobRunID,
+				Action:      action,
+				Reason:      "requested on the command line",
+				CreatedTime: now,
+			})
+		}
+	}
+	return ret, nil
+}
+
+// jobRunLoadExceptions are the job runs that are loaded differently than the last upload time alone would decide.
+type jobRunLoadExceptions struct {
+	// skip holds the IDs of the job runs that are never loaded
+	skip sets.Set[string]
+	// reprocess holds the pending reprocess requests by job run ID
+	reprocess map[string]jobrunaggregatorapi.JobRunLoadExceptionRow
+}
+
+// newJobRunLoadExceptions replays the rows in the order they were created, so that a reprocess request is dropped
+// once it has been carried out, and requested again by any later reprocess row.
+func newJobRunLoadExceptions(rows []jobrunaggregatorapi.JobRunLoadExceptionRow) *jobRunLoadExceptions {
+	rows = append([]jobrunaggregatorapi.JobRunLoadExceptionRow{}, rows...)
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].CreatedTime.Before(rows[j].CreatedTime)
+	})
+
+	ret := &jobRunLoadExceptions{
+		skip:      sets.New[string](),
+		reprocess: map[string]jobrunaggregatorapi.JobRunLoadExceptionRow{},
+	}
+	for _, row := range rows {
+		switch row.Action {
+		case jobrunaggregatorapi.JobRunLoadExceptionSkip:
+			ret.skip.Insert(row.JobRunName)
+		case jobrunaggregatorapi.JobRunLoadExceptionReprocess:
+			ret.reprocess[row.JobRunName] = row
+		case jobrunaggregatorapi.JobRunLoadExceptionReprocessed:
+			delete(ret.reprocess, row.JobRunName)
+		}
+	}
+	// corrupt artifacts don't get any better by reprocessing them
+	for jobRunID := range ret.skip {
+		delete(ret.reprocess, jobRunID)
+	}
+	return ret
+}
+
+func (e *jobRunLoadExceptions) shouldSkip(jobRunID string) bool {
+	return e.skip.Has(jobRunID)
+}
+
+func (e *jobRunLoadExceptions) shouldReprocess(jobRunID string) bool {
+	_, ok := e.reprocess[jobRunID]
+	return ok
+}
+
+// reprocessedRows completes the reprocess requests of the uploaded job runs, which are job run IDs by job name, so that
+// they are not loaded again by the next loader run.  Their rows were deleted first, so the requests of the job runs
+// that failed or were not ready to upload must stay pending.
+func (e *jobRunLoadExceptions) reprocessedRows(loaded map[string][]string, now time.Time) []jobrunaggregatorapi.JobRunLoadExceptionRow {
+	ret := []jobrunaggregatorapi.JobRunLoadExceptionRow{}
+	for jobName, jobRunIDs := range loaded {
+		for _, jobRunID := range jobRunIDs {
+			if !e.shouldReprocess(jobRunID) {
+				continue
+			}
+			ret = append(ret, jobrunaggregatorapi.JobRunLoadExceptionRow{
+				JobName:     jobName,
+				JobRunName:  jobRunID,
+				Action:      jobrunaggregatorapi.JobRunLoadExceptionReprocessed,
+				Reason:      "reprocessed by the loader",
+				CreatedTime: now,
+			})
+		}
+	}
+	return ret
+}
@@ -0,0 +1,117 @@
+package jobrunbigqueryloader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func TestNewJobRunLoadExceptions(t *testing.T) {
+	now := time.Now()
+	row := func(jobRunID, action string, age time.Duration) jobrunaggregatorapi.JobRunLoadExceptionRow {
+		return jobrunaggregatorapi.JobRunLoadExceptionRow{JobName: "job", JobRunName: jobRunID, Action: action, CreatedTime: now.Add(-age)}
+	}
+	tests := []struct {
+		name              string
+		rows              []jobrunaggregatorapi.JobRunLoadExceptionRow
+		expectedSkip      sets.Set[string]
+		expectedReprocess sets.Set[string]
+	}{
+		{
+			name:              "no exceptions",
+			expectedSkip:      sets.New[string](),
+			expectedReprocess: sets.New[string](),
+		},
+		{
+			name: "pending reprocess request",
+			rows: []jobrunaggregatorapi.JobRunLoadExceptionRow{
+				row("1", jobrunaggregatorapi.JobRunLoadExceptionSkip, time.Hour),
+				row("2", jobrunaggregatorapi.JobRunLoadExceptionReprocess, time.Hour),
+			},
+			expectedSkip:      sets.New("1"),
+			expectedReprocess: sets.New("2"),
+		},
+		{
+			name: "reprocessed request is done",
+			rows: []jobrunaggregatorapi.JobRunLoadExceptionRow{
+				row("2", jobrunaggregatorapi.JobRunLoadExceptionReprocessed, time.Minute),
+				row("2", jobrunaggregatorapi.JobRunLoadExceptionReprocess, time.Hour),
+			},
+			expectedSkip:      sets.New[string](),
+			expectedReprocess: sets.New[string](),
+		},
+		{
+			name: "reprocess requested again after it was done",
+			rows: []jobrunaggregatorapi.JobRunLoadExceptionRow{
+				row("2", jobrunaggregatorapi.JobRunLoadExceptionReprocess, 2*time.Hour),
+				row("2", jobrunaggregatorapi.JobRunLoadExceptionReprocessed, time.Hour),
+				row("2", jobrunaggregatorapi.JobRunLoadExceptionReprocess, time.Minute),
+			},
+			expectedSkip:      sets.New[string](),
+			expectedReprocess: sets.New("2"),
+		},
+		{
+			name: "skip wins over reprocess",
+			rows: []jobrunaggregatorapi.JobRunLoadExceptionRow{
+				row("3", jobrunaggregatorapi.JobRunLoadExceptionReprocess, time.Minute),
+				row("3", jobrunaggregatorapi.JobRunLoadExceptionSkip, time.Hour),
+			},
+			expectedSkip:      sets.New("3"),
+			expectedReprocess: sets.New[string](),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exceptions := newJobRunLoadExceptions(tt.rows)
+			assert.Equal(t, tt.expectedSkip, exceptions.skip)
+			reprocess := sets.New[string]()
+			for jobRunID := range exceptions.reprocess {
+				reprocess.Insert(jobRunID)
+			}
+			assert.Equal(t, tt.expectedReprocess, reprocess)
+		})
+	}
+}
+
+func TestReprocessedRows(t *testing.T) {
+	now := time.Now()
+	exceptions := newJobRunLoadExceptions([]jobrunaggregatorapi.JobRunLoadExceptionRow{
+		{JobName: "job-a", JobRunName: "2", Action: jobrunaggregatorapi.JobRunLoadExceptionReprocess, CreatedTime: now.Add(-time.Hour)},
+		{JobName: "job-b", JobRunName: "5", Action: jobrunaggregatorapi.JobRunLoadExceptionReprocess, CreatedTime: now.Add(-time.Hour)},
+	})
+
+	// job run 5 failed to load, its request stays pending
+	rows := exceptions.reprocessedRows(map[string][]string{"job-a": {"1", "2", "3"}, "job-b": {"4"}}, now)
+	assert.Equal(t, []jobrunaggregatorapi.JobRunLoadExceptionRow{{
+		JobName:     "job-a",
+		JobRunName:  "2",
+		Action:      jobrunaggregatorapi.JobRunLoadExceptionReprocessed,
+		Reason:      "reprocessed by the loader",
+		CreatedTime: now,
+	}}, rows)
+}
+
+func TestJobRunLoadExceptionFlags(t *testing.T) {
+	f := &JobRunLoadExceptionFlags{
+		SkipJobRuns:      []string{"periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn/1800000000000000001"},
+		ReprocessJobRuns: []string{"periodic-ci-openshift-release-master-ci-4.16-e2e-gcp-ovn/1800000000000000002"},
+	}
+	assert.NoError(t, f.Validate())
+	exceptions := newJobRunLoadExceptions(func() []jobrunaggregatorapi.JobRunLoadExceptionRow {
+		rows, _ := f.toExceptions(time.Now())
+		return rows
+	}())
+	assert.True(t, exceptions.shouldSkip("1800000000000000001"))
+	assert.True(t, exceptions.shouldReprocess("1800000000000000002"))
+	assert.Equal(t, "periodic-ci-openshift-release-master-ci-4.16-e2e-gcp-ovn", exceptions.reprocess["1800000000000000002"].JobName)
+
+	for _, invalid := range []string{"1800000000000000001", "job/", "/1800000000000000001", "job/logs/1800000000000000001"} {
+		f := &JobRunLoadExceptionFlags{ReprocessJobRuns: []string{invalid}}
+		assert.Error(t, f.Validate(), invalid)
+	}
+}
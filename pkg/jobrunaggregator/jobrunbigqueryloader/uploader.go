@@ -2,13 +2,16 @@ package jobrunbigqueryloader
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
@@ -27,6 +30,10 @@ func wantsDisruptionData(job jobrunaggregatorapi.JobRowWithVariants) bool {
 	return job.CollectDisruption
 }
 
+// errJobRunNotReady is returned for job runs without a prowjob.json or that haven't finished yet.  They are not loaded
+// by this loader run, but a later one loads them.
+var errJobRunNotReady = errors.New("job run is not ready to be loaded")
+
 type JobRunUploaderRegistry struct {
 	JobRunUploaders map[string]uploader
 }
@@ -52,6 +59,24 @@ type allJobsLoaderOptions struct {
 	jobRunUploaderRegistry      JobRunUploaderRegistry
 	pendingUploadJobsLister     pendingUploadLister
 	logLevel                    string
+	// jobRunLoadExceptions are added to the exceptions read from the JobRunLoadExceptions table
+	jobRunLoadExceptions []jobrunaggregatorapi.JobRunLoadExceptionRow
+	// jobRunLoadExceptionInserter records the reprocess requests that have been carried out
+	jobRunLoadExceptionInserter jobrunaggregatorlib.BigQueryInserter
+	// reprocessTables are the tables the uploaders insert into.  The rows of the job runs to reprocess are deleted
+	// from them before the job runs are loaded again.  Job runs are not reprocessed without any.
+	reprocessTables []string
+	// dryRun leaves the rows of the job runs to reprocess in place, the other rows are written by dry-run inserters
+	dryRun bool
+	// prowJobMatcherFunc is checked once prowjob.json has been read, job runs it doesn't match are not loaded.
+	prowJobMatcherFunc jobrunaggregatorlib.ProwJobMatcherFunc
+
+	// loaderName keys the checkpoints of the loader, the job runs before the checkpoint of their job are not loaded
+	// again.  jobRunCheckpointInserter commits the checkpoints, none are used without it.
+	loaderName               string
+	jobRunCheckpointInserter jobrunaggregatorlib.BigQueryInserter
+	// batchingInserters hold the rows of the uploaders until they are flushed, once all job runs are processed
+	batchingInserters []jobrunaggregatorlib.BatchingInserter
 }
 
 func (o *allJobsLoaderOptions) Run(ctx context.Context) error {
@@ -106,19 +131,52 @@ func (o *allJobsLoaderOptions) Run(ctx context.Context) error {
 	}
 	logrus.WithField("count", len(existingJobRunIDs)).Info("found job run IDs within our window already imported")
 
+	checkpoints := map[string]jobrunaggregatorapi.JobRunCheckpointRow{}
+	if o.jobRunCheckpointInserter != nil {
+		checkpoints, err = o.ciDataClient.ListJobRunCheckpoints(ctx, o.loaderName)
+		if err != nil {
+			return fmt.Errorf("error listing job run checkpoints: %w", err)
+		}
+		logrus.WithField("jobs", len(checkpoints)).Info("found job run checkpoints")
+	}
+
 	// Lookup the jobs that have run and we may need to import. There will be some overlap with what we already have.
-	jobRunsToImport, err := o.ciDataClient.ListProwJobRunsSince(ctx, &listProwJobsSince)
+	// The job runs before the checkpoint of their job are not even listed.
+	listedTime := time.Now()
+	jobRunsToImport, err := o.ciDataClient.ListProwJobRunsSince(ctx, &listProwJobsSince, checkpoints)
 	if err != nil {
 		return fmt.Errorf("error listing job runs to import: %w", err)
 	}
 	logrus.WithField("count", len(jobRunsToImport)).Info("found job runs to potentially import")
 
-	// Populate a channel with all the job runs we want to import, worker threads will pull
-	// from here until there's nothing left.
-	jobRunsToImportCh := make(chan *jobrunaggregatorapi.TestPlatformProwJobRow, len(jobRunsToImport))
+	exceptionRows, err := o.ciDataClient.ListJobRunLoadExceptions(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing job run load exceptions: %w", err)
+	}
+	exceptions := newJobRunLoadExceptions(append(exceptionRows, o.jobRunLoadExceptions...))
+	logrus.WithFields(logrus.Fields{"skip": exceptions.skip.Len(), "reprocess": len(exceptions.reprocess)}).Info("found job run load exceptions")
+
+	// Reprocess requests are usually for job runs that ended long before our window.
+	queuedJobRunIDs := sets.New[string]()
+	for _, jr := range jobRunsToImport {
+		queuedJobRunIDs.Insert(jr.BuildID)
+	}
+	for jobRunID, exception := range exceptions.reprocess {
+		if !queuedJobRunIDs.Has(jobRunID) {
+			jobRunsToImport = append(jobRunsToImport, &jobrunaggregatorapi.TestPlatformProwJobRow{JobName: exception.JobName, BuildID: jobRunID})
+		}
+	}
+
+	filteredJobRuns := []*jobrunaggregatorapi.TestPlatformProwJobRow{}
+	reprocessJobRunIDs := []string{}
 	for i := range jobRunsToImport {
 		jr := jobRunsToImport[i]
 
+		if exceptions.shouldSkip(jr.BuildID) {
+			logrus.WithFields(logrus.Fields{"job": jr.JobName, "run": jr.BuildID}).Info("skipping job run on the skip list")
+			continue
+		}
+
 		// skip if the run is not from a job we care about:
 		jobRow, ok := jobRowsMap[jr.JobName]
 		if !ok {
@@ -131,25 +189,49 @@ func (o *allJobsLoaderOptions) Run(ctx context.Context) error {
 			continue
 		}
 
-		// skip if we already have it:
-		if _, ok := existingJobRunIDs[jr.BuildID]; ok {
+		if exceptions.shouldReprocess(jr.BuildID) {
+			reprocessJobRunIDs = append(reprocessJobRunIDs, jr.BuildID)
+		} else if _, ok := existingJobRunIDs[jr.BuildID]; ok {
+			// skip if we already have it, unless we were asked to load it again:
 			logrus.WithFields(logrus.Fields{"job": jr.JobName, "run": jr.BuildID}).Debug("skipping job run we already have imported")
 			continue
 		}
+		filteredJobRuns = append(filteredJobRuns, jr)
+	}
+
+	errs := []error{}
+
+	// the rows loaded before must go, or the reprocessed job runs would be counted twice
+	if err := o.deleteReprocessedRows(ctx, reprocessJobRunIDs); err != nil {
+		logrus.WithError(err).Error("error deleting the rows of the job runs to reprocess, they are not reprocessed")
+		errs = append(errs, err)
+		reprocessed := sets.New[string](reprocessJobRunIDs...)
+		remaining := []*jobrunaggregatorapi.TestPlatformProwJobRow{}
+		for _, jr := range filteredJobRuns {
+			if !reprocessed.Has(jr.BuildID) {
+				remaining = append(remaining, jr)
+			}
+		}
+		filteredJobRuns = remaining
+	}
+
+	// Populate a channel with all the job runs we want to import, worker threads will pull
+	// from here until there's nothing left.
+	jobRunsToImportCh := make(chan *jobrunaggregatorapi.TestPlatformProwJobRow, len(filteredJobRuns))
+	for _, jr := range filteredJobRuns {
 		jobRunsToImportCh <- jr
 	}
 	close(jobRunsToImportCh)
 	runsToImportCount := len(jobRunsToImportCh)
 	logrus.WithField("runsToImport", runsToImportCount).Info("job runs to import after filtering")
 
-	errs := []error{}
-
 	logrus.WithField("workers", workerCount).Info("Launching goroutines for concurrent uploads")
 	wg := sync.WaitGroup{}
 	errChan := make(chan error, jobCount)
+	checkpointTracker := newJobRunCheckpointTracker()
 	for i := 0; i < workerCount; i++ {
 		wg.Add(1)
-		go o.processJobRuns(ctx, jobRowsMap, &wg, i, runsToImportCount, jobRunsToImportCh, errChan)
+		go o.processJobRuns(ctx, jobRowsMap, checkpointTracker, &wg, i, runsToImportCount, jobRunsToImportCh, errChan)
 	}
 
 	wg.Wait()
@@ -161,6 +243,42 @@ func (o *allJobsLoaderOptions) Run(ctx context.Context) error {
 		errs = append(errs, e)
 	}
 
+	flushed := true
+	for _, inserter := range o.batchingInserters {
+		if err := inserter.Flush(ctx); err != nil {
+			logrus.WithError(err).Error("error flushing batched rows")
+			errs = append(errs, err)
+			flushed = false
+		}
+	}
+
+	// only the job runs every uploader uploaded are reprocessed, and a failed batch may hold the rows of reprocessed
+	// job runs, the other requests are left pending to reprocess them again
+	if reprocessed := exceptions.reprocessedRows(checkpointTracker.uploadedJobRuns(), time.Now()); len(reprocessed) > 0 && flushed {
+		if err := o.jobRunLoadExceptionInserter.Put(ctx, reprocessed); err != nil {
+			logrus.WithError(err).Error("error recording the job runs as reprocessed")
+			errs = append(errs, err)
+		} else {
+			logrus.WithField("jobRuns", len(reprocessed)).Info("recorded the job runs as reprocessed")
+		}
+	}
+
+	// a failed batch may hold the rows of job runs that were marked as loaded, so they must be loaded again
+	if o.jobRunCheckpointInserter != nil && flushed {
+		// job runs are inserted slightly out of order like above, so the ones that completed just before the listing
+		// may not have been listed yet
+		completedBefore := listedTime.Add(-30 * time.Minute)
+		newCheckpoints := checkpointTracker.checkpoints(o.loaderName, checkpoints, completedBefore, time.Now())
+		if len(newCheckpoints) > 0 {
+			if err := o.jobRunCheckpointInserter.Put(ctx, newCheckpoints); err != nil {
+				logrus.WithError(err).Error("error committing job run checkpoints")
+				errs = append(errs, err)
+			} else {
+				logrus.WithField("jobs", len(newCheckpoints)).Info("committed job run checkpoints")
+			}
+		}
+	}
+
 	duration := time.Since(start)
 	logrus.WithFields(logrus.Fields{
 		"duration": duration,
@@ -170,9 +288,32 @@ func (o *allJobsLoaderOptions) Run(ctx context.Context) error {
 	return utilerrors.NewAggregate(errs)
 }
 
+// deleteReprocessedRows deletes the rows previously loaded for the job runs from every table of the uploaders at once,
+// so that when it fails the rows are still in every table and the job runs can be reprocessed later.  It refuses to
+// reprocess job runs when the loader doesn't know its tables.
+func (o *allJobsLoaderOptions) deleteReprocessedRows(ctx context.Context, jobRunIDs []string) error {
+	if len(jobRunIDs) == 0 {
+		return nil
+	}
+	if len(o.reprocessTables) == 0 {
+		return fmt.Errorf("cannot reprocess %d job runs without deleting their rows first, no tables are known", len(jobRunIDs))
+	}
+	logger := logrus.WithFields(logrus.Fields{"tables": o.reprocessTables, "jobRuns": len(jobRunIDs)})
+	if o.dryRun {
+		logger.Info("dry run, not deleting the rows of the job runs to reprocess")
+		return nil
+	}
+	deleted, err := o.ciDataClient.DeleteJobRunRowsFromTables(ctx, o.reprocessTables, jobRunIDs)
+	if err != nil {
+		return fmt.Errorf("error deleting the rows of the job runs to reprocess from %s: %w", strings.Join(o.reprocessTables, ", "), err)
+	}
+	logger.WithField("rows", deleted).Info("deleted the rows of the job runs to reprocess")
+	return nil
+}
+
 // processJobRuns is started in several concurrent goroutines to pull job runs to process from the channel. Errors are sent
 // to the errChan for aggregation in the main thread.
-func (o *allJobsLoaderOptions) processJobRuns(ctx context.Context, jobsMap map[string]jobrunaggregatorapi.JobRowWithVariants, wg *sync.WaitGroup, workerThread, origRunsToImportCount int, jobRunsToImportCh <-chan *jobrunaggregatorapi.TestPlatformProwJobRow, errChan chan<- error) {
+func (o *allJobsLoaderOptions) processJobRuns(ctx context.Context, jobsMap map[string]jobrunaggregatorapi.JobRowWithVariants, checkpointTracker *jobRunCheckpointTracker, wg *sync.WaitGroup, workerThread, origRunsToImportCount int, jobRunsToImportCh <-chan *jobrunaggregatorapi.TestPlatformProwJobRow, errChan chan<- error) {
 	defer wg.Done()
 	for job := range jobRunsToImportCh {
 		jrLogger := logrus.WithFields(logrus.Fields{
@@ -186,9 +327,18 @@ func (o *allJobsLoaderOptions) processJobRuns(ctx context.Context, jobsMap map[s
 
 		jobRunInserter := o.newJobRunBigQueryLoaderOptions(job.JobName, job.BuildID,
 			jobsMap[job.JobName].Release, jrLogger)
-		if err := jobRunInserter.Run(ctx); err != nil {
+		uploaded, err := jobRunInserter.Run(ctx)
+		switch {
+		case errors.Is(err, errJobRunNotReady):
+			// holds the checkpoint back like a failure, so that the job run is loaded once it is ready
+			checkpointTracker.record(job.JobName, job.BuildID, err)
+			jrLogger.WithError(err).Info("job run is not loaded yet")
+		case err != nil:
+			checkpointTracker.record(job.JobName, job.BuildID, err)
 			jrLogger.WithError(err).Error("error inserting job run")
 			errChan <- err
+		case uploaded:
+			checkpointTracker.record(job.JobName, job.BuildID, nil)
 		}
 		jrLogger.Debug("finished processing job run")
 	}
@@ -201,6 +351,7 @@ func (o *allJobsLoaderOptions) newJobRunBigQueryLoaderOptions(jobName, jobRunID,
 		jobRunID:               jobRunID,
 		jobRelease:             jobRelease,
 		gcsClient:              o.gcsClient,
+		prowJobMatcherFunc:     o.prowJobMatcherFunc,
 		jobRunUploaderRegistry: o.jobRunUploaderRegistry,
 		logger:                 logger.WithField("jobRun", jobRunID),
 	}
@@ -235,24 +386,30 @@ type jobRunLoaderOptions struct {
 	jobRelease string
 
 	// GCSClient is used to read the prowjob data
-	gcsClient jobrunaggregatorlib.CIGCSClient
+	gcsClient          jobrunaggregatorlib.CIGCSClient
+	prowJobMatcherFunc jobrunaggregatorlib.ProwJobMatcherFunc
 
 	jobRunUploaderRegistry JobRunUploaderRegistry
 	logger                 logrus.FieldLogger
 }
 
-func (o *jobRunLoaderOptions) Run(ctx context.Context) error {
+// Run returns whether the job run was uploaded, job runs filtered out by their prowjob state are not.  Job runs that
+// are not ready to be loaded return errJobRunNotReady.
+func (o *jobRunLoaderOptions) Run(ctx context.Context) (bool, error) {
 
 	o.logger.Debug("Analyzing jobrun")
 
 	jobRun, err := o.readJobRunFromGCS(ctx)
+	if errors.Is(err, errJobRunNotReady) {
+		return false, err
+	}
 	if err != nil {
 		o.logger.WithError(err).Error("error reading job run from GCS")
-		return err
+		return false, err
 	}
-	// this can happen if there is no prowjob.json, so no work to do.
+	// the prowjob state is filtered out, so no work to do.
 	if jobRun == nil {
-		return nil
+		return false, nil
 	}
 
 	// Initialize our junits and file names.
@@ -261,14 +418,14 @@ func (o *jobRunLoaderOptions) Run(ctx context.Context) error {
 	err = jobRun.GetJobRunFromGCS(ctx)
 	if err != nil {
 		o.logger.WithError(err).Error("error getting job run from GCS")
-		return err
+		return false, err
 	}
 
 	if err := o.uploadJobRun(ctx, jobRun); err != nil {
-		return fmt.Errorf("jobrun/%v/%v failed to upload to bigquery: %w", o.jobName, o.jobRunID, err)
+		return false, fmt.Errorf("jobrun/%v/%v failed to upload to bigquery: %w", o.jobName, o.jobRunID, err)
 	}
 
-	return nil
+	return true, nil
 }
 
 func (o *jobRunLoaderOptions) uploadJobRun(ctx context.Context, jobRun jobrunaggregatorapi.JobRunInfo) error {
@@ -284,26 +441,30 @@ func (o *jobRunLoaderOptions) uploadJobRun(ctx context.Context, jobRun jobrunagg
 	masterNodesUpdated := jobrunaggregatorlib.GetMasterNodesUpdatedStatusFromClusterData(clusterData)
 
 	jobRunRow := newJobRunRow(jobRun, prowJob, masterNodesUpdated)
+	// every uploader gets its chance, the job run is only uploaded once all of them succeeded
+	errs := []error{}
 	for name, jobRunUploader := range o.jobRunUploaderRegistry.JobRunUploaders {
 		if err := jobRunUploader.uploadContent(ctx, jobRun, o.jobRelease, jobRunRow, o.logger); err != nil {
 			o.logger.WithError(err).Errorf("error uploading content for: %s", name)
+			errs = append(errs, fmt.Errorf("error uploading content for %s: %w", name, err))
 		}
 	}
 
-	return nil
+	return utilerrors.NewAggregate(errs)
 }
 
 // associateJobRuns returns allJobRuns and currentAggregationTargetJobRuns
 func (o *jobRunLoaderOptions) readJobRunFromGCS(ctx context.Context) (jobrunaggregatorapi.JobRunInfo, error) {
-	jobRunInfo, err := o.gcsClient.ReadJobRunFromGCS(ctx, "logs/"+o.jobName, o.jobName, o.jobRunID, o.logger)
+	// the prowjob is read right below, reading it to validate the job run would read it twice
+	jobRunInfo, err := o.gcsClient.ReadJobRunFromGCS(ctx, "logs/"+o.jobName, o.jobName, o.jobRunID, o.logger, jobrunaggregatorlib.WithoutProwJobValidation())
 	if err != nil {
 		o.logger.WithError(err).Error("error in ReadJobRunFromGCS")
 		return nil, err
 	}
-	// this can happen if there is no prowjob.json
+	// this can happen if there is no prowjob.json yet
 	if jobRunInfo == nil {
 		o.logger.Debug("no prowjob.json found")
-		return nil, nil
+		return nil, fmt.Errorf("jobrun/%v/%v has no prowjob.json: %w", o.jobName, o.jobRunID, errJobRunNotReady)
 	}
 	prowjob, err := jobRunInfo.GetProwJob(ctx)
 	if err != nil {
@@ -312,6 +473,10 @@ func (o *jobRunLoaderOptions) readJobRunFromGCS(ctx context.Context) (jobrunaggr
 	}
 	if prowjob.Status.CompletionTime == nil {
 		o.logger.Info("Removing job run because it isn't finished")
+		return nil, fmt.Errorf("jobrun/%v/%v isn't finished: %w", o.jobName, o.jobRunID, errJobRunNotReady)
+	}
+	if o.prowJobMatcherFunc != nil && !o.prowJobMatcherFunc(prowjob) {
+		o.logger.WithField("state", prowjob.Status.State).Debug("Removing job run because its prowjob state is filtered out")
 		return nil, nil
 	}
 
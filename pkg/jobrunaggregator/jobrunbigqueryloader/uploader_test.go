@@ -0,0 +1,253 @@
+package jobrunbigqueryloader
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowjobv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+)
+
+func TestReadJobRunFromGCSFiltersProwJobStates(t *testing.T) {
+	completed := metav1.Now()
+	tests := []struct {
+		name         string
+		states       sets.Set[prowjobv1.ProwJobState]
+		prowJobState prowjobv1.ProwJobState
+		expectLoaded bool
+	}{
+		{
+			name:         "no filter loads aborted runs",
+			prowJobState: prowjobv1.AbortedState,
+			expectLoaded: true,
+		},
+		{
+			name:         "aborted runs are skipped",
+			states:       sets.New(prowjobv1.SuccessState, prowjobv1.FailureState),
+			prowJobState: prowjobv1.AbortedState,
+		},
+		{
+			name:         "failed runs are loaded",
+			states:       sets.New(prowjobv1.SuccessState, prowjobv1.FailureState),
+			prowJobState: prowjobv1.FailureState,
+			expectLoaded: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			ctx := context.TODO()
+			jobRun := jobrunaggregatorapi.NewMockJobRunInfo(mockCtrl)
+			jobRun.EXPECT().GetProwJob(ctx).Return(&prowjobv1.ProwJob{
+				Status: prowjobv1.ProwJobStatus{State: tt.prowJobState, CompletionTime: &completed},
+			}, nil)
+			gcsClient := jobrunaggregatorlib.NewMockCIGCSClient(mockCtrl)
+			gcsClient.EXPECT().ReadJobRunFromGCS(ctx, "logs/job", "job", "1", gomock.Any(), gomock.Any()).Return(jobRun, nil)
+
+			o := &jobRunLoaderOptions{
+				jobName:            "job",
+				jobRunID:           "1",
+				gcsClient:          gcsClient,
+				prowJobMatcherFunc: jobrunaggregatorlib.NewProwJobMatcherFuncForStates(tt.states),
+				logger:             logrus.WithField("test", tt.name),
+			}
+			actual, err := o.readJobRunFromGCS(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectLoaded, actual != nil)
+		})
+	}
+}
+
+func TestDeleteReprocessedRows(t *testing.T) {
+	ctx := context.TODO()
+	jobRunIDs := []string{"1", "2"}
+
+	t.Run("nothing to reprocess", func(t *testing.T) {
+		o := &allJobsLoaderOptions{}
+		assert.NoError(t, o.deleteReprocessedRows(ctx, nil))
+	})
+
+	t.Run("no tables", func(t *testing.T) {
+		o := &allJobsLoaderOptions{}
+		assert.ErrorContains(t, o.deleteReprocessedRows(ctx, jobRunIDs), "cannot reprocess 2 job runs")
+	})
+
+	t.Run("every table", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		ciDataClient := jobrunaggregatorlib.NewMockCIDataClient(mockCtrl)
+		ciDataClient.EXPECT().DeleteJobRunRowsFromTables(ctx, []string{jobrunaggregatorapi.AlertsTableName, jobrunaggregatorapi.JunitArtifactStatsTableName}, jobRunIDs).Return(int64(12), nil)
+
+		o := &allJobsLoaderOptions{
+			ciDataClient:    ciDataClient,
+			reprocessTables: []string{jobrunaggregatorapi.AlertsTableName, jobrunaggregatorapi.JunitArtifactStatsTableName},
+		}
+		assert.NoError(t, o.deleteReprocessedRows(ctx, jobRunIDs))
+	})
+
+	// the rows are deleted from every table at once, a failure in the second table must not leave the first one
+	// without the rows of the job runs that are then not loaded again
+	t.Run("deletion fails in the second table", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		ciDataClient := jobrunaggregatorlib.NewMockCIDataClient(mockCtrl)
+		ciDataClient.EXPECT().DeleteJobRunRowsFromTables(ctx, []string{jobrunaggregatorapi.AlertsTableName, jobrunaggregatorapi.JunitArtifactStatsTableName}, jobRunIDs).Return(int64(0), errors.New("junit artifact stats rows are in the streaming buffer"))
+
+		o := &allJobsLoaderOptions{
+			ciDataClient:    ciDataClient,
+			reprocessTables: []string{jobrunaggregatorapi.AlertsTableName, jobrunaggregatorapi.JunitArtifactStatsTableName},
+		}
+		assert.ErrorContains(t, o.deleteReprocessedRows(ctx, jobRunIDs), "streaming buffer")
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		o := &allJobsLoaderOptions{
+			ciDataClient:    jobrunaggregatorlib.NewMockCIDataClient(mockCtrl),
+			reprocessTables: []string{jobrunaggregatorapi.AlertsTableName},
+			dryRun:          true,
+		}
+		assert.NoError(t, o.deleteReprocessedRows(ctx, jobRunIDs))
+	})
+}
+
+// failingUploader fails to upload the content of the job runs it is given
+type failingUploader struct {
+	failedJobRunIDs sets.Set[string]
+}
+
+func (u failingUploader) uploadContent(ctx context.Context, jobRun jobrunaggregatorapi.JobRunInfo, release string, jobRunRow *jobrunaggregatorapi.JobRunRow, logger logrus.FieldLogger) error {
+	if u.failedJobRunIDs.Has(jobRun.GetJobRunID()) {
+		return errors.New("streaming insert failed")
+	}
+	return nil
+}
+
+// expectJobRun has the GCS client read the job run, which isn't finished without a completion time
+func expectJobRun(mockCtrl *gomock.Controller, gcsClient *jobrunaggregatorlib.MockCIGCSClient, jobName, jobRunID string, completionTime *metav1.Time) {
+	jobRun := jobrunaggregatorapi.NewMockJobRunInfo(mockCtrl)
+	jobRun.EXPECT().GetProwJob(gomock.Any()).Return(&prowjobv1.ProwJob{
+		Status: prowjobv1.ProwJobStatus{State: prowjobv1.SuccessState, StartTime: metav1.Now(), CompletionTime: completionTime},
+	}, nil).AnyTimes()
+	jobRun.EXPECT().GetJobRunFromGCS(gomock.Any()).Return(nil).AnyTimes()
+	jobRun.EXPECT().GetOpenShiftTestsFilesWithPrefix(gomock.Any(), "cluster-data").Return(nil, nil).AnyTimes()
+	jobRun.EXPECT().GetJobRunID().Return(jobRunID).AnyTimes()
+	jobRun.EXPECT().GetJobName().Return(jobName).AnyTimes()
+	gcsClient.EXPECT().ReadJobRunFromGCS(gomock.Any(), "logs/"+jobName, jobName, jobRunID, gomock.Any(), gomock.Any()).Return(jobRun, nil)
+}
+
+// processJobRuns processes the job runs in a single worker and returns the errors it sent
+func processJobRuns(o *allJobsLoaderOptions, checkpointTracker *jobRunCheckpointTracker, jobRuns []*jobrunaggregatorapi.TestPlatformProwJobRow) []error {
+	jobRunsCh := make(chan *jobrunaggregatorapi.TestPlatformProwJobRow, len(jobRuns))
+	for _, jr := range jobRuns {
+		jobRunsCh <- jr
+	}
+	close(jobRunsCh)
+	errChan := make(chan error, len(jobRuns))
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	o.processJobRuns(context.TODO(), map[string]jobrunaggregatorapi.JobRowWithVariants{}, checkpointTracker, &wg, 0, len(jobRuns), jobRunsCh, errChan)
+	close(errChan)
+
+	errs := []error{}
+	for err := range errChan {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func TestProcessJobRunsCheckpoints(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	completed := metav1.Now()
+	gcsClient := jobrunaggregatorlib.NewMockCIGCSClient(mockCtrl)
+	// the alerts of job-a/2 fail to upload, job-a/3 must not move the checkpoint past it
+	expectJobRun(mockCtrl, gcsClient, "job-a", "2", &completed)
+	expectJobRun(mockCtrl, gcsClient, "job-a", "3", &completed)
+	// job-b/10 hasn't finished yet and job-c/20 has no prowjob.json yet, they are loaded by a later loader run
+	expectJobRun(mockCtrl, gcsClient, "job-b", "10", nil)
+	expectJobRun(mockCtrl, gcsClient, "job-b", "11", &completed)
+	gcsClient.EXPECT().ReadJobRunFromGCS(gomock.Any(), "logs/job-c", "job-c", "20", gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	o := &allJobsLoaderOptions{gcsClient: gcsClient}
+	o.jobRunUploaderRegistry.Register("alerts", failingUploader{failedJobRunIDs: sets.New("2")})
+	o.jobRunUploaderRegistry.Register("disruption", failingUploader{})
+
+	jobRuns := []*jobrunaggregatorapi.TestPlatformProwJobRow{
+		{JobName: "job-a", BuildID: "2"},
+		{JobName: "job-a", BuildID: "3"},
+		{JobName: "job-b", BuildID: "10"},
+		{JobName: "job-b", BuildID: "11"},
+		{JobName: "job-c", BuildID: "20"},
+	}
+	checkpointTracker := newJobRunCheckpointTracker()
+	errs := processJobRuns(o, checkpointTracker, jobRuns)
+	assert.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "jobrun/job-a/2 failed to upload to bigquery: error uploading content for alerts: streaming insert failed")
+
+	loaded := checkpointTracker.uploadedJobRuns()
+	for _, jobRunIDs := range loaded {
+		sort.Strings(jobRunIDs)
+	}
+	assert.Equal(t, map[string][]string{"job-a": {"3"}, "job-b": {"11"}}, loaded)
+
+	previous := map[string]jobrunaggregatorapi.JobRunCheckpointRow{
+		"job-a": {JobName: "job-a", JobRunName: "1"},
+	}
+	now := time.Now()
+	assert.Empty(t, checkpointTracker.checkpoints("alert", previous, now, now))
+}
+
+// the rows of the reprocessed job runs are deleted before they are uploaded again, a request whose upload failed must
+// stay pending or the job run is left without rows
+func TestReprocessedJobRunsFailingToUpload(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	completed := metav1.Now()
+	gcsClient := jobrunaggregatorlib.NewMockCIGCSClient(mockCtrl)
+	expectJobRun(mockCtrl, gcsClient, "job", "1", &completed)
+	expectJobRun(mockCtrl, gcsClient, "job", "2", &completed)
+	expectJobRun(mockCtrl, gcsClient, "job", "3", nil)
+
+	o := &allJobsLoaderOptions{gcsClient: gcsClient}
+	o.jobRunUploaderRegistry.Register("alerts", failingUploader{failedJobRunIDs: sets.New("2")})
+
+	now := time.Now()
+	exceptions := newJobRunLoadExceptions([]jobrunaggregatorapi.JobRunLoadExceptionRow{
+		{JobName: "job", JobRunName: "1", Action: jobrunaggregatorapi.JobRunLoadExceptionReprocess, CreatedTime: now.Add(-time.Hour)},
+		{JobName: "job", JobRunName: "2", Action: jobrunaggregatorapi.JobRunLoadExceptionReprocess, CreatedTime: now.Add(-time.Hour)},
+		{JobName: "job", JobRunName: "3", Action: jobrunaggregatorapi.JobRunLoadExceptionReprocess, CreatedTime: now.Add(-time.Hour)},
+	})
+	checkpointTracker := newJobRunCheckpointTracker()
+	processJobRuns(o, checkpointTracker, []*jobrunaggregatorapi.TestPlatformProwJobRow{
+		{JobName: "job", BuildID: "1"},
+		{JobName: "job", BuildID: "2"},
+		{JobName: "job", BuildID: "3"},
+	})
+
+	assert.Equal(t, []jobrunaggregatorapi.JobRunLoadExceptionRow{{
+		JobName:     "job",
+		JobRunName:  "1",
+		Action:      jobrunaggregatorapi.JobRunLoadExceptionReprocessed,
+		Reason:      "reprocessed by the loader",
+		CreatedTime: now,
+	}}, exceptions.reprocessedRows(checkpointTracker.uploadedJobRuns(), now))
+}
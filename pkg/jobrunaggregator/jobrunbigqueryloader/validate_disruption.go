@@ -0,0 +1,156 @@
+package jobrunbigqueryloader
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+)
+
+const backendDisruptionFilePrefix = "backend-disruption"
+
+type DisruptionValidateFlags struct {
+	ArtifactDir      string
+	RequiredBackends []string
+	MaxDisruption    time.Duration
+}
+
+func NewDisruptionValidateFlags() *DisruptionValidateFlags {
+	return &DisruptionValidateFlags{
+		RequiredBackends: jobrunaggregatorlib.RequiredDisruptionTests().List(),
+		MaxDisruption:    time.Hour,
+	}
+}
+
+func (f *DisruptionValidateFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.ArtifactDir, "artifact-dir", f.ArtifactDir, fmt.Sprintf("The locally downloaded artifacts of a job run.  Every %s*.json file below it is validated", backendDisruptionFilePrefix))
+	fs.StringSliceVar(&f.RequiredBackends, "required-backends", f.RequiredBackends, "Backends that must be reported by the job run.  Pass an empty value for jobs that do not measure upgrade disruption")
+	fs.DurationVar(&f.MaxDisruption, "max-disruption", f.MaxDisruption, "Disruption longer than this for a single backend is reported as suspicious")
+}
+
+func NewDisruptionValidateCommand() *cobra.Command {
+	f := NewDisruptionValidateFlags()
+
+	cmd := &cobra.Command{
+		Use: "validate-disruption",
+		Long: `Validate the backend-disruption artifacts of a job run before they are uploaded to bigquery.
+Reports files that do not match the expected schema, suspicious values like negative or absurdly long
+disruption, and required backends that are missing.`,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			if err := f.Validate(); err != nil {
+				logrus.WithError(err).Fatal("Flags are invalid")
+			}
+			o, err := f.ToOptions(ctx)
+			if err != nil {
+				logrus.WithError(err).Fatal("Failed to build runtime options")
+			}
+
+			if err := o.Run(ctx); err != nil {
+				logrus.WithError(err).Fatal("Command failed")
+			}
+
+			return nil
+		},
+
+		Args: jobrunaggregatorlib.NoArgs,
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+// Validate checks to see if the user-input is likely to produce functional runtime options
+func (f *DisruptionValidateFlags) Validate() error {
+	if len(f.ArtifactDir) == 0 {
+		return fmt.Errorf("missing --artifact-dir")
+	}
+	if f.MaxDisruption < 0 {
+		return fmt.Errorf("--max-disruption must not be negative")
+	}
+	return nil
+}
+
+// ToOptions goes from the user input to the runtime values need to run the command.
+func (f *DisruptionValidateFlags) ToOptions(ctx context.Context) (*DisruptionValidateOptions, error) {
+	requiredBackends := sets.Set[string]{}
+	for _, backend := range f.RequiredBackends {
+		if backend = strings.TrimSpace(backend); len(backend) > 0 {
+			requiredBackends.Insert(backend)
+		}
+	}
+	return &DisruptionValidateOptions{
+		artifactDir:      f.ArtifactDir,
+		requiredBackends: requiredBackends,
+		maxDisruption:    f.MaxDisruption,
+	}, nil
+}
+
+type DisruptionValidateOptions struct {
+	artifactDir      string
+	requiredBackends sets.Set[string]
+	maxDisruption    time.Duration
+}
+
+func (o *DisruptionValidateOptions) Run(ctx context.Context) error {
+	backendDisruptionData, err := readBackendDisruptionFiles(o.artifactDir)
+	if err != nil {
+		return err
+	}
+	if len(backendDisruptionData) == 0 {
+		return fmt.Errorf("no %s files found in %q", backendDisruptionFilePrefix, o.artifactDir)
+	}
+
+	problems := jobrunaggregatorlib.ValidateBackendDisruptionData(backendDisruptionData, o.requiredBackends, o.maxDisruption)
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problems in %d %s files", len(problems), len(backendDisruptionData), backendDisruptionFilePrefix)
+	}
+	fmt.Printf("%d %s files are valid\n", len(backendDisruptionData), backendDisruptionFilePrefix)
+	return nil
+}
+
+// readBackendDisruptionFiles returns the content of the backend-disruption files below dir, keyed by their path
+// relative to dir, matching what GetOpenShiftTestsFilesWithPrefix returns for a job run in GCS.
+func readBackendDisruptionFiles(dir string) (map[string]string, error) {
+	ret := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), backendDisruptionFilePrefix) || filepath.Ext(entry.Name()) != ".json" {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		ret[relativePath] = string(content)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s files from %q: %w", backendDisruptionFilePrefix, dir, err)
+	}
+	return ret, nil
+}
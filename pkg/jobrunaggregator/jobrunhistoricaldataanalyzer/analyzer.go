@@ -6,6 +6,11 @@ import (
 	"fmt"
 	"os"
 	"text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/version"
 
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
@@ -20,6 +25,16 @@ type JobRunHistoricalDataAnalyzerOptions struct {
 	leeway          float64
 	targetRelease   string
 	previousRelease string
+	exclusions      *exclusionConfig
+	// queryParallelism bounds the day ranges queried concurrently, the precomputed views are read at once when it is 1
+	queryParallelism int
+
+	// phaseLeeway overrides leeway depending on the release phase, which is computed from the milestone release tags.
+	phaseLeeway map[string]float64
+
+	// snapshotInserter records every published snapshot, snapshotSource describes where the new data came from.
+	snapshotInserter jobrunaggregatorlib.BigQueryInserter
+	snapshotSource   string
 }
 
 func (o *JobRunHistoricalDataAnalyzerOptions) Run(ctx context.Context) error {
@@ -42,16 +57,21 @@ func (o *JobRunHistoricalDataAnalyzerOptions) Run(ctx context.Context) error {
 			return err
 		}
 	}
-	fmt.Printf("Using target release: %s, previous release: %s\n", targetRelease, previousRelease)
+	logrus.WithFields(logrus.Fields{"release": targetRelease, "previousRelease": previousRelease}).Info("Comparing releases")
 
-	currentHistoricalData, err := readHistoricalDataFile(o.currentFile, o.dataType)
+	currentHistoricalData, currentMetadata, err := readHistoricalDataFile(o.currentFile, o.dataType)
 	if err != nil {
 		return err
 	}
+	if currentMetadata != nil {
+		logrus.WithFields(logrus.Fields{"generatedTime": currentMetadata.GeneratedTime.Format(time.RFC3339), "source": currentMetadata.Source}).Info("Read current data")
+	}
 	if len(currentHistoricalData) == 0 {
 		return fmt.Errorf("current historical data is empty, can not compare")
 	}
 
+	// data read from a file keeps the provenance recorded in it
+	newMetadata := newHistoricalDataMetadata(o.dataType, o.snapshotSource, time.Now().UTC())
 	switch {
 	case o.newFile == "" && o.dataType == "alerts":
 		newHistoricalData, err = o.getAlertData(ctx)
@@ -59,15 +79,23 @@ func (o *JobRunHistoricalDataAnalyzerOptions) Run(ctx context.Context) error {
 			return fmt.Errorf("failed while attempting to read Alert Historical Data: %w", err)
 		}
 	case o.newFile == "" && o.dataType == "disruptions":
-		newHistoricalData, err = o.ciDataClient.ListDisruptionHistoricalData(ctx)
+		newHistoricalData, err = o.getDisruptionData(ctx)
 		if err != nil {
 			return err
 		}
 	default:
-		newHistoricalData, err = readHistoricalDataFile(o.newFile, o.dataType)
+		var fileMetadata *historicalDataMetadata
+		newHistoricalData, fileMetadata, err = readHistoricalDataFile(o.newFile, o.dataType)
 		if err != nil {
 			return err
 		}
+		if fileMetadata != nil {
+			newMetadata = fileMetadata
+			newMetadata.ToolVersion = version.Version
+		} else {
+			// a file without metadata doesn't tell which job runs it was computed from
+			newMetadata.QueryWindowStart, newMetadata.QueryWindowEnd = time.Time{}, time.Time{}
+		}
 	}
 
 	if len(newHistoricalData) == 0 {
@@ -78,24 +106,68 @@ func (o *JobRunHistoricalDataAnalyzerOptions) Run(ctx context.Context) error {
 	newDataMap := convertToMap(newHistoricalData)
 	currentDataMap := convertToMap(currentHistoricalData)
 
-	previousResult := o.compareAndUpdate(newDataMap, currentDataMap, previousRelease)
-	currentResult := o.compareAndUpdate(newDataMap, currentDataMap, targetRelease)
+	releaseLeeway, err := o.resolveReleaseLeeway(ctx, previousRelease, targetRelease)
+	if err != nil {
+		return err
+	}
+
+	previousResult := o.compareAndUpdate(newDataMap, currentDataMap, previousRelease, releaseLeeway[previousRelease])
+	currentResult := o.compareAndUpdate(newDataMap, currentDataMap, targetRelease, releaseLeeway[targetRelease])
 	result := mergeResults(previousResult, currentResult)
+	appliedLeeway := formatReleaseLeeway(releaseLeeway, previousRelease, targetRelease)
 
-	err = o.renderResultFiles(result)
+	err = o.renderResultFiles(result, newMetadata, appliedLeeway)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("successfully compared (%s) with specified leeway of %.2f%%\n", o.dataType, o.leeway)
+	o.recordSnapshot(ctx, targetRelease, result.jobs)
+
+	logrus.WithField("dataType", o.dataType).Infof("successfully compared with leeway of %s", appliedLeeway)
 	return nil
 }
 
+func (o *JobRunHistoricalDataAnalyzerOptions) getDisruptionData(ctx context.Context) ([]jobrunaggregatorapi.HistoricalData, error) {
+	if o.queryParallelism <= 1 {
+		return o.ciDataClient.ListDisruptionHistoricalData(ctx)
+	}
+	jobs, err := o.ciDataClient.ListAllJobs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	dayRanges := lookbackDayRanges(time.Now(), disruptionLookbackDays, o.queryParallelism)
+	histogram, err := listPartitionsInParallel(ctx, dayRanges, o.queryParallelism, func(ctx context.Context, days dayRange) ([]jobrunaggregatorapi.BackendDisruptionHistogramRow, error) {
+		return o.ciDataClient.ListBackendDisruptionHistogram(ctx, days.start, days.end)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jobrunaggregatorlib.NewDisruptionHistoricalData(jobs, histogram), nil
+}
+
+func (o *JobRunHistoricalDataAnalyzerOptions) listAlertHistoricalData(ctx context.Context) ([]*jobrunaggregatorapi.AlertHistoricalDataRow, error) {
+	if o.queryParallelism <= 1 {
+		return o.ciDataClient.ListAlertHistoricalData(ctx)
+	}
+	jobs, err := o.ciDataClient.ListAllJobs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	dayRanges := lookbackDayRanges(time.Now(), alertLookbackDays, o.queryParallelism)
+	histogram, err := listPartitionsInParallel(ctx, dayRanges, o.queryParallelism, func(ctx context.Context, days dayRange) ([]jobrunaggregatorapi.AlertHistogramRow, error) {
+		return o.ciDataClient.ListAlertHistogram(ctx, days.start, days.end)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jobrunaggregatorlib.NewAlertHistoricalData(jobs, histogram), nil
+}
+
 func (o *JobRunHistoricalDataAnalyzerOptions) getAlertData(ctx context.Context) ([]jobrunaggregatorapi.HistoricalData, error) {
 	var allKnownAlerts []*jobrunaggregatorapi.KnownAlertRow
 	var newHistoricalData []*jobrunaggregatorapi.AlertHistoricalDataRow
 
-	newHistoricalData, err := o.ciDataClient.ListAlertHistoricalData(ctx)
+	newHistoricalData, err := o.listAlertHistoricalData(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list alert historical data: %w", err)
 	}
@@ -119,6 +191,49 @@ func (o *JobRunHistoricalDataAnalyzerOptions) getAlertData(ctx context.Context)
 	return jobrunaggregatorapi.ConvertToHistoricalData(newHistoricalData), nil
 }
 
+// recordSnapshot writes the published data into the snapshots table so that the published percentiles can be
+// trended across runs.  The result files are already written by then, so failing to record the snapshot is only
+// logged rather than failing the run.
+func (o *JobRunHistoricalDataAnalyzerOptions) recordSnapshot(ctx context.Context, targetRelease string, jobs []parsedJobData) {
+	if o.snapshotInserter == nil || len(jobs) == 0 {
+		return
+	}
+
+	rows := newSnapshotRows(o.dataType, o.snapshotSource, targetRelease, jobs, time.Now().UTC())
+	if err := o.snapshotInserter.Put(ctx, rows); err != nil {
+		logrus.WithError(err).WithField("dataType", o.dataType).Warn("failed to record historical data snapshot")
+		return
+	}
+	logrus.WithField("dataType", o.dataType).Infof("recorded %d rows in the snapshot", len(rows))
+}
+
+func newSnapshotRows(dataType, source, targetRelease string, jobs []parsedJobData, snapshotTime time.Time) []*jobrunaggregatorapi.HistoricalDataSnapshotRow {
+	rows := make([]*jobrunaggregatorapi.HistoricalDataSnapshotRow, 0, len(jobs))
+	for _, job := range jobs {
+		jobData := job.GetJobData()
+		rows = append(rows, &jobrunaggregatorapi.HistoricalDataSnapshotRow{
+			SnapshotTime:  snapshotTime,
+			DataType:      dataType,
+			Source:        source,
+			TargetRelease: targetRelease,
+			Key:           job.GetKey(),
+			Name:          job.GetName(),
+			Release:       jobData.Release,
+			FromRelease:   jobData.FromRelease,
+			Platform:      jobData.Platform,
+			Architecture:  jobData.Architecture,
+			Network:       jobData.Network,
+			Topology:      jobData.Topology,
+			JobRuns:       job.GetJobRuns(),
+			P50:           job.DurationP50.Seconds(),
+			P75:           job.DurationP75.Seconds(),
+			P95:           job.DurationP95.Seconds(),
+			P99:           job.DurationP99.Seconds(),
+		})
+	}
+	return rows
+}
+
 func mergeResults(previousResult, currentResult compareResults) compareResults {
 	// Append elements from previousResult and currentResult to the mergedResults
 	var mergedResults compareResults
@@ -135,12 +250,12 @@ func mergeResults(previousResult, currentResult compareResults) compareResults {
 //
 // If we're in a normal cycle, we then run through our regular comparisons, for P95 and P99 (we only count jobs for P95).
 // We check if the old P95 and/or P99 values are higher, than the new, by calculating the time difference and the percentage difference.
-// If a new value is higher AND the percent difference is higher than the leeway desired, we count (for P95 only) that as an increase and record it as part of the results,
+// If a new value is higher AND the percent difference is higher than the leeway desired for the release phase, we count (for P95 only) that as an increase and record it as part of the results,
 // we also record the decreases.
 //
 // Once we've completed recording the results of the compare, we then do a check to see which jobs were removed and we make a note of those jobs to present.
 // The missing jobs are not added back to the final list, the final list is always driven by the new data supplied for comparison gathered from Big Query.
-func (o *JobRunHistoricalDataAnalyzerOptions) compareAndUpdate(newData, currentData map[string]jobrunaggregatorapi.HistoricalData, release string) compareResults {
+func (o *JobRunHistoricalDataAnalyzerOptions) compareAndUpdate(newData, currentData map[string]jobrunaggregatorapi.HistoricalData, release string, leeway float64) compareResults {
 	increaseCountP99 := 0
 	decreaseCountP99 := 0
 	results := []parsedJobData{}
@@ -158,6 +273,18 @@ func (o *JobRunHistoricalDataAnalyzerOptions) compareAndUpdate(newData, currentD
 		newP50 := getDurationFromString(new.GetP50())
 		d := parsedJobData{}
 
+		// Entries held constant keep publishing their current values and are never compared.
+		if old, ok := currentData[key]; ok && o.exclusions.isHeldConstant(new) {
+			d.HistoricalData = old
+			d.DurationP99 = getDurationFromString(old.GetP99())
+			d.DurationP95 = getDurationFromString(old.GetP95())
+			d.DurationP75 = getDurationFromString(old.GetP75())
+			d.DurationP50 = getDurationFromString(old.GetP50())
+			d.JobResults = old.GetJobRuns()
+			results = append(results, d)
+			continue
+		}
+
 		// If the current data contains the new data, check and record the time diff
 		if old, ok := currentData[key]; ok {
 			oldP95 := getDurationFromString(old.GetP95())
@@ -170,6 +297,12 @@ func (o *JobRunHistoricalDataAnalyzerOptions) compareAndUpdate(newData, currentD
 			d.DurationP50 = newP50
 			d.JobResults = new.GetJobRuns()
 
+			// Excluded entries are published with their new values, but must not force a review on their own.
+			if o.exclusions.isExcluded(new) {
+				results = append(results, d)
+				continue
+			}
+
 			timeDiffP95 := newP95 - oldP95
 			timeDiffP99 := newP99 - oldP99
 			percentDiffP95 := 0.0
@@ -180,12 +313,12 @@ func (o *JobRunHistoricalDataAnalyzerOptions) compareAndUpdate(newData, currentD
 			if oldP99 != 0 {
 				percentDiffP99 = (float64(timeDiffP99) / float64(oldP99)) * 100
 			}
-			if newP95 > oldP95 && percentDiffP95 > o.leeway {
+			if newP95 > oldP95 && percentDiffP95 > leeway {
 				d.TimeDiffP95 = timeDiffP95
 				d.PercentTimeDiffP95 = percentDiffP95
 				d.PrevP95 = oldP95
 			}
-			if newP99 > oldP99 && percentDiffP99 > o.leeway {
+			if newP99 > oldP99 && percentDiffP99 > leeway {
 				increaseCountP99 += 1
 				d.TimeDiffP99 = timeDiffP99
 				d.PercentTimeDiffP99 = percentDiffP99
@@ -226,7 +359,8 @@ func (o *JobRunHistoricalDataAnalyzerOptions) compareAndUpdate(newData, currentD
 	}
 }
 
-func (o *JobRunHistoricalDataAnalyzerOptions) renderResultFiles(result compareResults) error {
+// renderResultFiles writes the results, leeway describes the leeway applied to each release.
+func (o *JobRunHistoricalDataAnalyzerOptions) renderResultFiles(result compareResults, metadata *historicalDataMetadata, leeway string) error {
 	funcs := map[string]any{
 		"formatTableOutput": formatTableOutput,
 	}
@@ -245,7 +379,7 @@ func (o *JobRunHistoricalDataAnalyzerOptions) renderResultFiles(result compareRe
 		Jobs           []parsedJobData
 	}{
 		DataType:       o.dataType,
-		Leeway:         fmt.Sprintf("%.2f%%", o.leeway),
+		Leeway:         leeway,
 		IncreasedCount: result.increaseCount,
 		DecreasedCount: result.decreaseCount,
 		AddedJobs:      result.addedJobs,
@@ -254,14 +388,14 @@ func (o *JobRunHistoricalDataAnalyzerOptions) renderResultFiles(result compareRe
 	}
 
 	if result.increaseCount > 0 {
-		log := fmt.Sprintf("(%s) had (%d) results increased in duration beyond specified leeway of %.2f%%\n", o.dataType, result.increaseCount, o.leeway)
+		log := fmt.Sprintf("(%s) had (%d) results increased in duration beyond the leeway of %s\n", o.dataType, result.increaseCount, leeway)
 		if err := requireReviewFile(log); err != nil {
 			return err
 		}
 	}
 
 	if result.decreaseCount > 0 {
-		log := fmt.Sprintf("(%s) had (%d) results decreased in duration beyond specified leeway of %.2f%%\n", o.dataType, result.decreaseCount, o.leeway)
+		log := fmt.Sprintf("(%s) had (%d) results decreased in duration beyond the leeway of %s\n", o.dataType, result.decreaseCount, leeway)
 		if err := requireReviewFile(log); err != nil {
 			return err
 		}
@@ -276,7 +410,7 @@ func (o *JobRunHistoricalDataAnalyzerOptions) renderResultFiles(result compareRe
 		return err
 	}
 
-	out, err := formatOutput(result.jobs, "json")
+	out, err := formatOutput(result.jobs, "json", metadata)
 	if err != nil {
 		return fmt.Errorf("error merging missing release data %w", err)
 	}
@@ -0,0 +1,57 @@
+package jobrunhistoricaldataanalyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func TestNewSnapshotRows(t *testing.T) {
+	snapshotTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	jobs := []parsedJobData{
+		{
+			DurationP50: 500 * time.Millisecond,
+			DurationP75: time.Second,
+			DurationP95: 2 * time.Second,
+			DurationP99: 3500 * time.Millisecond,
+			HistoricalData: &jobrunaggregatorapi.DisruptionHistoricalDataRow{
+				BackendName: "kube-api-new-connections",
+				HistoricalJobData: jobrunaggregatorapi.HistoricalJobData{
+					Release:      "4.16",
+					FromRelease:  "4.15",
+					Platform:     "aws",
+					Architecture: "amd64",
+					Network:      "ovn",
+					Topology:     "ha",
+					JobRuns:      120,
+				},
+			},
+		},
+	}
+
+	rows := newSnapshotRows("disruptions", "bigquery:openshift-ci-data-analysis.ci_data", "4.16", jobs, snapshotTime)
+	assert.Equal(t, []*jobrunaggregatorapi.HistoricalDataSnapshotRow{
+		{
+			SnapshotTime:  snapshotTime,
+			DataType:      "disruptions",
+			Source:        "bigquery:openshift-ci-data-analysis.ci_data",
+			TargetRelease: "4.16",
+			Key:           "kube-api-new-connections_4.15_4.16_amd64_aws_ovn_ha",
+			Name:          "kube-api-new-connections",
+			Release:       "4.16",
+			FromRelease:   "4.15",
+			Platform:      "aws",
+			Architecture:  "amd64",
+			Network:       "ovn",
+			Topology:      "ha",
+			JobRuns:       120,
+			P50:           0.5,
+			P75:           1,
+			P95:           2,
+			P99:           3.5,
+		},
+	}, rows)
+}
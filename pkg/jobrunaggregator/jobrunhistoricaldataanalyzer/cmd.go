@@ -3,6 +3,7 @@ package jobrunhistoricaldataanalyzer
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -10,12 +11,15 @@ import (
 
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
 )
 
 type JobRunHistoricalDataAnalyzerFlags struct {
 	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
+	QueryCost       *jobrunaggregatorlib.QueryCostFlags
 	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
+	DryRunOutput    *jobrunaggregatorlib.DryRunOutputFlags
 
 	NewFile         string
 	CurrentFile     string
@@ -24,6 +28,12 @@ type JobRunHistoricalDataAnalyzerFlags struct {
 	OutputFile      string
 	TargetRelease   string
 	PreviousRelease string
+	ExclusionsFile  string
+	DryRun          bool
+
+	QueryParallelism int
+
+	PhaseLeeway map[string]string
 }
 
 var supportedDataTypes = sets.New[string]("alerts", "disruptions")
@@ -31,13 +41,19 @@ var supportedDataTypes = sets.New[string]("alerts", "disruptions")
 func NewJobRunHistoricalDataAnalyzerFlags() *JobRunHistoricalDataAnalyzerFlags {
 	return &JobRunHistoricalDataAnalyzerFlags{
 		DataCoordinates: jobrunaggregatorlib.NewBigQueryDataCoordinates(),
+		QueryCost:       jobrunaggregatorlib.NewQueryCostFlags(),
 		Authentication:  jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+		DryRunOutput:    jobrunaggregatorlib.NewDryRunOutputFlags(),
+
+		QueryParallelism: 4,
 	}
 }
 
 func (f *JobRunHistoricalDataAnalyzerFlags) BindFlags(fs *pflag.FlagSet) {
 	f.DataCoordinates.BindFlags(fs)
+	f.QueryCost.BindFlags(fs)
 	f.Authentication.BindFlags(fs)
+	f.DryRunOutput.BindFlags(fs)
 
 	fs.StringVar(&f.DataType, "data-type", f.DataType, fmt.Sprintf("data type we are fetching %s", sets.List(supportedDataTypes)))
 	fs.StringVar(&f.NewFile, "new", f.NewFile, "local file with the new query results to compare against")
@@ -45,13 +61,20 @@ func (f *JobRunHistoricalDataAnalyzerFlags) BindFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&f.OutputFile, "output-file", f.OutputFile, "output file for the resulting comparison results")
 	fs.StringVar(&f.TargetRelease, "target-release", f.TargetRelease, "override for release to generate data for, omit to use the most recent release. Be sure to checkout the correct branch for --current.")
 	fs.StringVar(&f.PreviousRelease, "previous-release", f.PreviousRelease, "override for previous release to generate data when we do not have enough for target release. Must be specified if using --target-release.")
+	fs.StringVar(&f.ExclusionsFile, "exclusions-file", f.ExclusionsFile, "optional YAML file listing backends or alerts and job variants to exclude from the comparison or to hold at their current values")
+	fs.BoolVar(&f.DryRun, "dry-run", f.DryRun, "Run the command, but don't record the published snapshot in bigquery.")
 	fs.Float64Var(&f.Leeway, "leeway", f.Leeway, "percent leeway threshold for increased time diff")
+	fs.StringToStringVar(&f.PhaseLeeway, "phase-leeway", f.PhaseLeeway, fmt.Sprintf("percent leeway threshold by release phase, like development=20,ga-candidate=5. Phases are %v, --leeway is used for phases not listed. The phase of a release comes from its accepted payloads in the ReleaseTags table: it is in feature freeze from its first feature candidate (X.Y.0-fc.N), a GA candidate from its first release candidate (X.Y.0-rc.N) or GA, and in development before.", sets.List(knownReleasePhases)))
+	fs.IntVar(&f.QueryParallelism, "query-parallelism", f.QueryParallelism, "number of day ranges whose historical data is queried concurrently. 1 reads the percentiles the views precompute in a single query. More splits the lookback days in as many ranges, queries the histograms of the disruption and alert tables, which are partitioned by day, for every range and computes the percentiles from them, so the concurrent queries scan about the same data as a single one.")
 }
 
 func (f *JobRunHistoricalDataAnalyzerFlags) Validate() error {
 	if err := f.DataCoordinates.Validate(); err != nil && f.NewFile == "" {
 		return err
 	}
+	if err := f.QueryCost.Validate(); err != nil {
+		return err
+	}
 	if err := f.Authentication.Validate(); err != nil && f.NewFile == "" {
 		return err
 	}
@@ -68,6 +91,17 @@ func (f *JobRunHistoricalDataAnalyzerFlags) Validate() error {
 		return fmt.Errorf("leeway percent must be above 0")
 	}
 
+	if _, err := parsePhaseLeeway(f.PhaseLeeway); err != nil {
+		return err
+	}
+	if len(f.PhaseLeeway) > 0 && f.Authentication.Validate() != nil {
+		return fmt.Errorf("--phase-leeway requires release tags from bigquery, bigquery credentials must be provided")
+	}
+
+	if f.QueryParallelism < 1 {
+		return fmt.Errorf("--query-parallelism must be at least 1")
+	}
+
 	if f.TargetRelease != "" && f.PreviousRelease == "" {
 		return fmt.Errorf("must specify --previous-release with --target-release")
 	}
@@ -82,22 +116,50 @@ func (f *JobRunHistoricalDataAnalyzerFlags) ToOptions(ctx context.Context) (*Job
 	}
 
 	ciDataClient := jobrunaggregatorlib.NewRetryingCIDataClient(
-		jobrunaggregatorlib.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
+		f.QueryCost.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
 	)
 
+	exclusions, err := readExclusionConfig(f.ExclusionsFile)
+	if err != nil {
+		return nil, err
+	}
+	phaseLeeway, err := parsePhaseLeeway(f.PhaseLeeway)
+	if err != nil {
+		return nil, err
+	}
+
+	// snapshots are only recorded when we can reach bigquery
+	var snapshotInserter jobrunaggregatorlib.BigQueryInserter
+	switch {
+	case f.DryRun:
+		snapshotInserter = f.DryRunOutput.NewInserter(os.Stdout, jobrunaggregatorapi.HistoricalDataSnapshotsTableName)
+	case bigQueryClient != nil:
+		snapshotInserter = bigQueryClient.Dataset(f.DataCoordinates.DataSetID).Table(jobrunaggregatorapi.HistoricalDataSnapshotsTableName).Inserter()
+	}
+	snapshotSource := fmt.Sprintf("bigquery:%s.%s", f.DataCoordinates.ProjectID, f.DataCoordinates.DataSetID)
+	if f.NewFile != "" {
+		snapshotSource = fmt.Sprintf("file:%s", f.NewFile)
+	}
+
 	if f.OutputFile == "" {
 		f.OutputFile = fmt.Sprintf("results_%s.json", f.DataType)
 	}
 
 	return &JobRunHistoricalDataAnalyzerOptions{
-		ciDataClient:    ciDataClient,
-		newFile:         f.NewFile,
-		currentFile:     f.CurrentFile,
-		leeway:          f.Leeway,
-		dataType:        f.DataType,
-		outputFile:      f.OutputFile,
-		targetRelease:   f.TargetRelease,
-		previousRelease: f.PreviousRelease,
+		ciDataClient:     ciDataClient,
+		newFile:          f.NewFile,
+		currentFile:      f.CurrentFile,
+		leeway:           f.Leeway,
+		dataType:         f.DataType,
+		outputFile:       f.OutputFile,
+		targetRelease:    f.TargetRelease,
+		previousRelease:  f.PreviousRelease,
+		exclusions:       exclusions,
+		queryParallelism: f.QueryParallelism,
+		snapshotInserter: snapshotInserter,
+		snapshotSource:   snapshotSource,
+
+		phaseLeeway: phaseLeeway,
 	}, nil
 }
 
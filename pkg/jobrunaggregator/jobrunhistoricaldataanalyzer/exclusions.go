@@ -0,0 +1,102 @@
+package jobrunhistoricaldataanalyzer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// exclusionConfig lists backends (or alerts) and job variants that should not drive the weekly comparison,
+// usually because they are known to be broken and under investigation.
+//
+// Example:
+//
+//	exclude:
+//	- name: ingress-to-console-new-connections
+//	  platform: metal
+//	  reason: https://issues.redhat.com/browse/OCPBUGS-0000
+//	holdConstant:
+//	- name: kube-api-new-connections
+//	  release: "4.15"
+type exclusionConfig struct {
+	// Exclude entries are still written with their new values, but are never counted as increases or decreases,
+	// so they cannot force a manual review on their own.
+	Exclude []exclusionRule `json:"exclude,omitempty"`
+	// HoldConstant entries keep their current values, the new values are ignored.
+	HoldConstant []exclusionRule `json:"holdConstant,omitempty"`
+}
+
+// exclusionRule matches historical data entries.  Empty fields match everything.
+type exclusionRule struct {
+	// Name is the backend name for disruptions, or the alert name for alerts.
+	Name         string `json:"name,omitempty"`
+	Release      string `json:"release,omitempty"`
+	FromRelease  string `json:"fromRelease,omitempty"`
+	Platform     string `json:"platform,omitempty"`
+	Architecture string `json:"architecture,omitempty"`
+	Network      string `json:"network,omitempty"`
+	Topology     string `json:"topology,omitempty"`
+	// Reason is not used for matching, but documents why the rule exists.
+	Reason string `json:"reason,omitempty"`
+}
+
+func readExclusionConfig(filePath string) (*exclusionConfig, error) {
+	config := &exclusionConfig{}
+	if len(filePath) == 0 {
+		return config, nil
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open exclusions file at path (%s): %w", filePath, err)
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse exclusions file (%s): %w", filePath, err)
+	}
+	for _, rule := range append(config.Exclude, config.HoldConstant...) {
+		if rule == (exclusionRule{Reason: rule.Reason}) {
+			return nil, fmt.Errorf("exclusions file (%s) contains a rule that matches everything", filePath)
+		}
+	}
+	return config, nil
+}
+
+func (r exclusionRule) matches(data jobrunaggregatorapi.HistoricalData) bool {
+	jobData := data.GetJobData()
+	name := data.GetName()
+	// alerts are named "<alert> <namespace> <level>", rules only need the alert name
+	if fields := strings.Fields(name); len(fields) > 0 && r.Name == fields[0] {
+		name = fields[0]
+	}
+	return matchesField(r.Name, name) &&
+		matchesField(r.Release, jobData.Release) &&
+		matchesField(r.FromRelease, jobData.FromRelease) &&
+		matchesField(r.Platform, jobData.Platform) &&
+		matchesField(r.Architecture, jobData.Architecture) &&
+		matchesField(r.Network, jobData.Network) &&
+		matchesField(r.Topology, jobData.Topology)
+}
+
+func matchesField(ruleValue, value string) bool {
+	return len(ruleValue) == 0 || ruleValue == value
+}
+
+func (c *exclusionConfig) isExcluded(data jobrunaggregatorapi.HistoricalData) bool {
+	return c != nil && anyRuleMatches(c.Exclude, data)
+}
+
+func (c *exclusionConfig) isHeldConstant(data jobrunaggregatorapi.HistoricalData) bool {
+	return c != nil && anyRuleMatches(c.HoldConstant, data)
+}
+
+func anyRuleMatches(rules []exclusionRule, data jobrunaggregatorapi.HistoricalData) bool {
+	for _, rule := range rules {
+		if rule.matches(data) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package jobrunhistoricaldataanalyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func disruptionRow(backend, platform, p95, p99 string, jobRuns int) *jobrunaggregatorapi.DisruptionHistoricalDataRow {
+	return &jobrunaggregatorapi.DisruptionHistoricalDataRow{
+		BackendName: backend,
+		HistoricalJobData: jobrunaggregatorapi.HistoricalJobData{
+			Release:      "4.16",
+			FromRelease:  "4.15",
+			Platform:     platform,
+			Architecture: "amd64",
+			Network:      "ovn",
+			Topology:     "ha",
+			JobRuns:      jobRuns,
+		},
+		P95: p95,
+		P99: p99,
+	}
+}
+
+func TestExclusionRuleMatches(t *testing.T) {
+	disruption := disruptionRow("kube-api-new-connections", "aws", "1.0", "2.0", 100)
+	alert := &jobrunaggregatorapi.AlertHistoricalDataRow{
+		AlertName:         "KubeAPIErrorBudgetBurn",
+		AlertNamespace:    "openshift-kube-apiserver",
+		AlertLevel:        "warning",
+		HistoricalJobData: disruption.HistoricalJobData,
+	}
+
+	tests := []struct {
+		name     string
+		rule     exclusionRule
+		data     jobrunaggregatorapi.HistoricalData
+		expected bool
+	}{
+		{
+			name:     "name only matches every job variant",
+			rule:     exclusionRule{Name: "kube-api-new-connections"},
+			data:     disruption,
+			expected: true,
+		},
+		{
+			name:     "other name",
+			rule:     exclusionRule{Name: "ingress-to-console-new-connections"},
+			data:     disruption,
+			expected: false,
+		},
+		{
+			name:     "all fields match",
+			rule:     exclusionRule{Name: "kube-api-new-connections", Release: "4.16", FromRelease: "4.15", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"},
+			data:     disruption,
+			expected: true,
+		},
+		{
+			name:     "job variant without name matches every backend",
+			rule:     exclusionRule{Platform: "aws"},
+			data:     disruption,
+			expected: true,
+		},
+		{
+			name:     "other platform",
+			rule:     exclusionRule{Name: "kube-api-new-connections", Platform: "metal"},
+			data:     disruption,
+			expected: false,
+		},
+		{
+			name:     "other release",
+			rule:     exclusionRule{Name: "kube-api-new-connections", Release: "4.15"},
+			data:     disruption,
+			expected: false,
+		},
+		{
+			name:     "reason is not matched",
+			rule:     exclusionRule{Name: "kube-api-new-connections", Reason: "https://issues.redhat.com/browse/OCPBUGS-0000"},
+			data:     disruption,
+			expected: true,
+		},
+		{
+			name:     "alert name matches every namespace and level",
+			rule:     exclusionRule{Name: "KubeAPIErrorBudgetBurn"},
+			data:     alert,
+			expected: true,
+		},
+		{
+			name:     "full alert name",
+			rule:     exclusionRule{Name: "KubeAPIErrorBudgetBurn openshift-kube-apiserver warning"},
+			data:     alert,
+			expected: true,
+		},
+		{
+			name:     "alert name prefix",
+			rule:     exclusionRule{Name: "KubeAPI"},
+			data:     alert,
+			expected: false,
+		},
+		{
+			name:     "alert in another level",
+			rule:     exclusionRule{Name: "KubeAPIErrorBudgetBurn openshift-kube-apiserver critical"},
+			data:     alert,
+			expected: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.rule.matches(tc.data))
+		})
+	}
+}
+
+func TestExclusionConfig(t *testing.T) {
+	data := disruptionRow("kube-api-new-connections", "aws", "1.0", "2.0", 100)
+
+	var unset *exclusionConfig
+	assert.False(t, unset.isExcluded(data))
+	assert.False(t, unset.isHeldConstant(data))
+
+	config := &exclusionConfig{
+		Exclude:      []exclusionRule{{Name: "ingress-to-console-new-connections"}, {Name: "kube-api-new-connections", Platform: "aws"}},
+		HoldConstant: []exclusionRule{{Name: "kube-api-new-connections", Platform: "metal"}},
+	}
+	assert.True(t, config.isExcluded(data))
+	assert.False(t, config.isHeldConstant(data))
+}
+
+func TestCompareAndUpdateWithExclusions(t *testing.T) {
+	currentHeld := disruptionRow("held", "aws", "1.0", "2.0", 100)
+	currentExcluded := disruptionRow("excluded", "aws", "1.0", "2.0", 100)
+	currentCompared := disruptionRow("compared", "aws", "1.0", "2.0", 100)
+	newHeld := disruptionRow("held", "aws", "5.0", "9.0", 120)
+	newExcluded := disruptionRow("excluded", "aws", "5.0", "9.0", 120)
+	newCompared := disruptionRow("compared", "aws", "5.0", "9.0", 120)
+	// entries only held constant once they have a current value
+	newHeldAdded := disruptionRow("held", "metal", "5.0", "9.0", 120)
+
+	currentData := map[string]jobrunaggregatorapi.HistoricalData{}
+	for _, row := range []*jobrunaggregatorapi.DisruptionHistoricalDataRow{currentHeld, currentExcluded, currentCompared} {
+		currentData[row.GetKey()] = row
+	}
+	newData := map[string]jobrunaggregatorapi.HistoricalData{}
+	for _, row := range []*jobrunaggregatorapi.DisruptionHistoricalDataRow{newHeld, newExcluded, newCompared, newHeldAdded} {
+		newData[row.GetKey()] = row
+	}
+
+	o := &JobRunHistoricalDataAnalyzerOptions{
+		exclusions: &exclusionConfig{
+			Exclude:      []exclusionRule{{Name: "excluded"}},
+			HoldConstant: []exclusionRule{{Name: "held"}},
+		},
+	}
+	result := o.compareAndUpdate(newData, currentData, "4.16", 10)
+
+	// only the compared entry counts as an increase
+	assert.Equal(t, 1, result.increaseCount)
+	assert.Equal(t, 0, result.decreaseCount)
+	assert.Equal(t, []string{newHeldAdded.GetKey()}, result.addedJobs)
+	assert.Empty(t, result.missingJobs)
+
+	jobs := map[string]parsedJobData{}
+	for _, job := range result.jobs {
+		jobs[job.GetKey()] = job
+	}
+	assert.Len(t, jobs, 4)
+
+	held := jobs[currentHeld.GetKey()]
+	assert.Same(t, currentHeld, held.HistoricalData, "held entries keep their current values")
+	assert.Equal(t, time.Second, held.DurationP95)
+	assert.Equal(t, 2*time.Second, held.DurationP99)
+	assert.Equal(t, 100, held.JobResults)
+	assert.Zero(t, held.TimeDiffP99)
+
+	excluded := jobs[currentExcluded.GetKey()]
+	assert.Same(t, newExcluded, excluded.HistoricalData, "excluded entries are published with their new values")
+	assert.Equal(t, 9*time.Second, excluded.DurationP99)
+	assert.Zero(t, excluded.TimeDiffP99)
+
+	compared := jobs[currentCompared.GetKey()]
+	assert.Same(t, newCompared, compared.HistoricalData)
+	assert.Equal(t, 7*time.Second, compared.TimeDiffP99)
+	assert.Equal(t, 2*time.Second, compared.PrevP99)
+
+	heldAdded := jobs[newHeldAdded.GetKey()]
+	assert.Same(t, newHeldAdded, heldAdded.HistoricalData)
+	assert.Equal(t, 9*time.Second, heldAdded.DurationP99)
+}
@@ -0,0 +1,92 @@
+package jobrunhistoricaldataanalyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/test-infra/prow/version"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+// historicalDataLookback is how far back the bigquery views behind each data type look.
+var historicalDataLookback = map[string]time.Duration{
+	"alerts":      7 * 24 * time.Hour,
+	"disruptions": 30 * 24 * time.Hour,
+}
+
+// historicalDataMetadata records where a historical data file came from, so that consumers can tell when the
+// thresholds were generated and from what data.
+type historicalDataMetadata struct {
+	DataType      string    `json:"dataType"`
+	GeneratedTime time.Time `json:"generatedTime"`
+	// QueryWindowStart and QueryWindowEnd bound the job runs the percentiles were computed from.
+	QueryWindowStart time.Time `json:"queryWindowStart"`
+	QueryWindowEnd   time.Time `json:"queryWindowEnd"`
+	// Source is the bigquery dataset the data was queried from, or the file it was read from.
+	Source      string `json:"source"`
+	ToolVersion string `json:"toolVersion"`
+	RowCount    int    `json:"rowCount"`
+}
+
+// historicalDataFile is the format of the files read and written.  Files holding a bare list of rows predate the
+// metadata and are still read.
+type historicalDataFile struct {
+	Metadata *historicalDataMetadata `json:"metadata"`
+	Data     json.RawMessage         `json:"data"`
+}
+
+func newHistoricalDataMetadata(dataType, source string, generatedTime time.Time) *historicalDataMetadata {
+	return &historicalDataMetadata{
+		DataType:         dataType,
+		GeneratedTime:    generatedTime,
+		QueryWindowStart: generatedTime.Add(-historicalDataLookback[dataType]),
+		QueryWindowEnd:   generatedTime,
+		Source:           source,
+		ToolVersion:      version.Version,
+	}
+}
+
+func (m *historicalDataMetadata) validate(dataType string, rows int) error {
+	if m.DataType != dataType {
+		return fmt.Errorf("metadata is for data type %q, expected %q", m.DataType, dataType)
+	}
+	if m.GeneratedTime.IsZero() {
+		return fmt.Errorf("metadata is missing the generated time")
+	}
+	if m.QueryWindowEnd.Before(m.QueryWindowStart) {
+		return fmt.Errorf("metadata query window ends (%s) before it starts (%s)", m.QueryWindowEnd, m.QueryWindowStart)
+	}
+	if len(m.Source) == 0 {
+		return fmt.Errorf("metadata is missing the source")
+	}
+	if m.RowCount != rows {
+		return fmt.Errorf("metadata claims %d rows, file has %d", m.RowCount, rows)
+	}
+	return nil
+}
+
+// splitHistoricalDataFile returns the rows of a historical data file, along with its metadata when it has some.
+func splitHistoricalDataFile(content []byte) (json.RawMessage, *historicalDataMetadata, error) {
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '[' {
+		return content, nil, nil
+	}
+	file := historicalDataFile{}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, nil, err
+	}
+	if file.Metadata == nil {
+		return nil, nil, fmt.Errorf("historical data file has no metadata")
+	}
+	return file.Data, file.Metadata, nil
+}
+
+func marshalHistoricalDataFile(metadata *historicalDataMetadata, data []jobrunaggregatorapi.HistoricalData) ([]byte, error) {
+	rows, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(historicalDataFile{Metadata: metadata, Data: rows}, "", "  ")
+}
@@ -0,0 +1,101 @@
+package jobrunhistoricaldataanalyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+func TestReadHistoricalDataFile(t *testing.T) {
+	generated := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	rows := `[{"BackendName": "kube-api-new-connections", "Release": "4.16", "JobRuns": 120, "P95": "1.0", "P99": "2.0"}]`
+
+	tests := []struct {
+		name             string
+		content          string
+		expectedMetadata *historicalDataMetadata
+		expectedErr      string
+	}{
+		{
+			name:    "file without metadata",
+			content: rows,
+		},
+		{
+			name:    "file with metadata",
+			content: `{"metadata": {"dataType": "disruptions", "generatedTime": "2024-03-01T12:00:00Z", "queryWindowStart": "2024-01-31T12:00:00Z", "queryWindowEnd": "2024-03-01T12:00:00Z", "source": "bigquery:openshift-ci-data-analysis.ci_data", "toolVersion": "v20240301-abcdef", "rowCount": 1}, "data": ` + rows + `}`,
+			expectedMetadata: &historicalDataMetadata{
+				DataType:         "disruptions",
+				GeneratedTime:    generated,
+				QueryWindowStart: generated.Add(-30 * 24 * time.Hour),
+				QueryWindowEnd:   generated,
+				Source:           "bigquery:openshift-ci-data-analysis.ci_data",
+				ToolVersion:      "v20240301-abcdef",
+				RowCount:         1,
+			},
+		},
+		{
+			name:        "row count mismatch",
+			content:     `{"metadata": {"dataType": "disruptions", "generatedTime": "2024-03-01T12:00:00Z", "source": "file:new.json", "rowCount": 3}, "data": ` + rows + `}`,
+			expectedErr: "metadata claims 3 rows, file has 1",
+		},
+		{
+			name:        "data type mismatch",
+			content:     `{"metadata": {"dataType": "alerts", "generatedTime": "2024-03-01T12:00:00Z", "source": "file:new.json", "rowCount": 1}, "data": ` + rows + `}`,
+			expectedErr: `metadata is for data type "alerts", expected "disruptions"`,
+		},
+		{
+			name:        "object without metadata",
+			content:     `{"data": ` + rows + `}`,
+			expectedErr: "historical data file has no metadata",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "data.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			data, metadata, err := readHistoricalDataFile(path, "disruptions")
+			if len(tt.expectedErr) > 0 {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.expectedErr)
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Len(t, data, 1)
+			assert.Equal(t, tt.expectedMetadata, metadata)
+		})
+	}
+}
+
+func TestHistoricalDataFileRoundTrip(t *testing.T) {
+	metadata := newHistoricalDataMetadata("disruptions", "bigquery:openshift-ci-data-analysis.ci_data", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	jobs := []parsedJobData{{
+		HistoricalData: &jobrunaggregatorapi.DisruptionHistoricalDataRow{BackendName: "kube-api-new-connections", HistoricalJobData: jobrunaggregatorapi.HistoricalJobData{Release: "4.16", JobRuns: 120}, P95: "1.0", P99: "2.0"},
+	}}
+	out, err := formatOutput(jobs, "json", metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, readMetadata, err := readHistoricalDataFile(path, "disruptions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, metadata, readMetadata)
+	assert.Equal(t, 1, readMetadata.RowCount)
+	assert.Equal(t, "kube-api-new-connections", data[0].GetName())
+}
@@ -0,0 +1,96 @@
+package jobrunhistoricaldataanalyzer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// disruptionLookbackDays and alertLookbackDays are the days of job runs the historical data views aggregate
+	disruptionLookbackDays = 30
+	alertLookbackDays      = 7
+)
+
+// dayRange is a partition of the historical data: the job runs that started in [start, end).
+type dayRange struct {
+	start time.Time
+	end   time.Time
+}
+
+func (r dayRange) String() string {
+	return fmt.Sprintf("%s/%s", r.start.Format(time.DateOnly), r.end.Format(time.DateOnly))
+}
+
+// lookbackDayRanges splits the lookbackDays up to and including the day of now in at most parts ranges of whole days,
+// oldest first.  The tables are partitioned by day, so every range only scans its own days.
+func lookbackDayRanges(now time.Time, lookbackDays, parts int) []dayRange {
+	day := 24 * time.Hour
+	end := now.UTC().Truncate(day).Add(day)
+	start := end.Add(-time.Duration(lookbackDays) * day)
+	if parts > lookbackDays {
+		parts = lookbackDays
+	}
+
+	ranges := []dayRange{}
+	for i := 0; i < parts; i++ {
+		// the first ranges take the remaining days
+		days := lookbackDays / parts
+		if i < lookbackDays%parts {
+			days++
+		}
+		rangeEnd := start.Add(time.Duration(days) * day)
+		ranges = append(ranges, dayRange{start: start, end: rangeEnd})
+		start = rangeEnd
+	}
+	return ranges
+}
+
+// listPartitionsInParallel runs the query of every partition with at most parallelism queries at a time, and merges
+// their rows in the order of the partitions. The first failure cancels the queries that are still running.
+func listPartitionsInParallel[P any, T any](ctx context.Context, partitions []P, parallelism int, list func(ctx context.Context, partition P) ([]T, error)) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]T, len(partitions))
+	errs := make([]error, len(partitions))
+	slots := make(chan struct{}, parallelism)
+	wg := sync.WaitGroup{}
+	for i, partition := range partitions {
+		wg.Add(1)
+		go func(i int, partition P) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			logrus.WithField("partition", partition).Debug("querying partition")
+			results[i], errs[i] = list(ctx, partition)
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i, partition)
+	}
+	wg.Wait()
+
+	var merged []T
+	for i, partition := range partitions {
+		if errs[i] != nil {
+			// report the failure which canceled the others rather than a cancellation
+			for j := range errs {
+				if errs[j] != nil && errs[j] != context.Canceled {
+					return nil, fmt.Errorf("failed to query partition %v: %w", partitions[j], errs[j])
+				}
+			}
+			return nil, fmt.Errorf("failed to query partition %v: %w", partition, errs[i])
+		}
+		merged = append(merged, results[i]...)
+	}
+	return merged, nil
+}
@@ -0,0 +1,102 @@
+package jobrunhistoricaldataanalyzer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPartitionsInParallel(t *testing.T) {
+	partitions := []string{"4.14", "4.15", "4.16", "4.17"}
+
+	tests := []struct {
+		name        string
+		failing     string
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "rows are merged in the order of the partitions",
+			expected: []string{"4.14-a", "4.14-b", "4.15-a", "4.15-b", "4.16-a", "4.16-b", "4.17-a", "4.17-b"},
+		},
+		{
+			name:        "a failing partition fails the query",
+			failing:     "4.16",
+			expectedErr: "failed to query partition 4.16: quota exceeded",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			lock := sync.Mutex{}
+			running, maxRunning := 0, 0
+			list := func(ctx context.Context, partition string) ([]string, error) {
+				lock.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				lock.Unlock()
+				defer func() {
+					lock.Lock()
+					running--
+					lock.Unlock()
+				}()
+
+				if partition == tc.failing {
+					return nil, fmt.Errorf("quota exceeded")
+				}
+				return []string{partition + "-a", partition + "-b"}, nil
+			}
+
+			actual, err := listPartitionsInParallel(context.TODO(), partitions, 2, list)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+			assert.LessOrEqual(t, maxRunning, 2)
+		})
+	}
+}
+
+func TestLookbackDayRanges(t *testing.T) {
+	now := time.Date(2024, 5, 30, 13, 45, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name         string
+		lookbackDays int
+		parts        int
+		expected     []dayRange
+	}{
+		{
+			name:         "the first ranges take the remaining days",
+			lookbackDays: 7,
+			parts:        3,
+			expected: []dayRange{
+				{start: day(24), end: day(27)},
+				{start: day(27), end: day(29)},
+				{start: day(29), end: day(31)},
+			},
+		},
+		{
+			name:         "ranges are at least a day",
+			lookbackDays: 2,
+			parts:        4,
+			expected: []dayRange{
+				{start: day(29), end: day(30)},
+				{start: day(30), end: day(31)},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, lookbackDayRanges(now, tc.lookbackDays, tc.parts))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package jobrunhistoricaldataanalyzer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+)
+
+const (
+	releasePhaseDevelopment   = "development"
+	releasePhaseFeatureFreeze = "feature-freeze"
+	releasePhaseGACandidate   = "ga-candidate"
+)
+
+var knownReleasePhases = sets.New[string](releasePhaseDevelopment, releasePhaseFeatureFreeze, releasePhaseGACandidate)
+
+// parsePhaseLeeway converts the phase=percent pairs from the command line into leeway percentages.
+func parsePhaseLeeway(phaseLeeway map[string]string) (map[string]float64, error) {
+	parsed := map[string]float64{}
+	for phase, value := range phaseLeeway {
+		if !knownReleasePhases.Has(phase) {
+			return nil, fmt.Errorf("unknown release phase %q, valid values are: %v", phase, sets.List(knownReleasePhases))
+		}
+		leeway, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid leeway %q for release phase %q: %w", value, phase, err)
+		}
+		if leeway < 0 {
+			return nil, fmt.Errorf("leeway percent for release phase %q must be above 0", phase)
+		}
+		parsed[phase] = leeway
+	}
+	return parsed, nil
+}
+
+// releasePhaseFor determines how mature the release is from the milestone payloads accepted by the given time.  A
+// release enters feature freeze with its first feature candidate, like 4.16.0-fc.0, and becomes a GA candidate with
+// its first release candidate, like 4.16.0-rc.0.  A release that already went GA is treated as a GA candidate, since
+// it should be the most stable.  Until then, with engineering candidates only, it is in development.
+func releasePhaseFor(release string, milestoneTags []jobrunaggregatorapi.ReleaseTagRow, now time.Time) string {
+	phase := releasePhaseDevelopment
+	for _, tag := range milestoneTags {
+		if tag.Release != release || tag.ReleaseTime.After(now) {
+			continue
+		}
+		switch {
+		case tag.ReleaseTag == release+".0" || strings.HasPrefix(tag.ReleaseTag, release+".0-rc."):
+			return releasePhaseGACandidate
+		case strings.HasPrefix(tag.ReleaseTag, release+".0-fc."):
+			phase = releasePhaseFeatureFreeze
+		}
+	}
+	return phase
+}
+
+// resolveReleaseLeeway looks up the phase of each release and returns the leeway to use for it.
+// Releases without a configured phase leeway fall back to the default leeway.
+func (o *JobRunHistoricalDataAnalyzerOptions) resolveReleaseLeeway(ctx context.Context, releases ...string) (map[string]float64, error) {
+	releaseLeeway := map[string]float64{}
+	for _, release := range releases {
+		releaseLeeway[release] = o.leeway
+	}
+	if len(o.phaseLeeway) == 0 {
+		return releaseLeeway, nil
+	}
+
+	milestoneTags, err := o.ciDataClient.ListReleaseMilestoneTags(ctx, sets.List(sets.KeySet(releaseLeeway)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list release milestone tags: %w", err)
+	}
+	now := time.Now()
+	for _, release := range sets.List(sets.KeySet(releaseLeeway)) {
+		phase := releasePhaseFor(release, milestoneTags, now)
+		if leeway, ok := o.phaseLeeway[phase]; ok {
+			releaseLeeway[release] = leeway
+		}
+		logrus.WithFields(logrus.Fields{"release": release, "phase": phase}).Infof("using leeway of %.2f%%", releaseLeeway[release])
+	}
+	return releaseLeeway, nil
+}
+
+// formatReleaseLeeway describes the leeway applied to each release, in the order of the releases.
+func formatReleaseLeeway(releaseLeeway map[string]float64, releases ...string) string {
+	described := make([]string, 0, len(releases))
+	for _, release := range releases {
+		described = append(described, fmt.Sprintf("%.2f%% for %s", releaseLeeway[release], release))
+	}
+	return strings.Join(described, ", ")
+}
@@ -0,0 +1,163 @@
+package jobrunhistoricaldataanalyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+)
+
+func TestReleasePhaseFor(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tag := func(releaseTag string, days int) jobrunaggregatorapi.ReleaseTagRow {
+		release := strings.Join(strings.SplitN(releaseTag, ".", 3)[:2], ".")
+		return jobrunaggregatorapi.ReleaseTagRow{Release: release, ReleaseTag: releaseTag, ReleaseTime: start.AddDate(0, 0, days)}
+	}
+	milestoneTags := []jobrunaggregatorapi.ReleaseTagRow{
+		tag("4.16.0-ec.1", 0),
+		tag("4.16.0-ec.2", 14),
+		tag("4.16.0-fc.0", 40),
+		tag("4.16.0-rc.0", 80),
+		tag("4.16.0", 110),
+		tag("4.17.0-fc.0", 20),
+	}
+
+	tests := []struct {
+		name     string
+		release  string
+		now      time.Time
+		expected string
+	}{
+		{
+			name:     "no milestone tags",
+			release:  "4.18",
+			now:      start.AddDate(1, 0, 0),
+			expected: releasePhaseDevelopment,
+		},
+		{
+			name:     "engineering candidates only",
+			release:  "4.16",
+			now:      start.AddDate(0, 0, 39),
+			expected: releasePhaseDevelopment,
+		},
+		{
+			name:     "first feature candidate",
+			release:  "4.16",
+			now:      start.AddDate(0, 0, 40),
+			expected: releasePhaseFeatureFreeze,
+		},
+		{
+			name:     "feature candidate of another release",
+			release:  "4.16",
+			now:      start.AddDate(0, 0, 30),
+			expected: releasePhaseDevelopment,
+		},
+		{
+			name:     "first release candidate",
+			release:  "4.16",
+			now:      start.AddDate(0, 0, 80),
+			expected: releasePhaseGACandidate,
+		},
+		{
+			name:     "released",
+			release:  "4.16",
+			now:      start.AddDate(0, 0, 200),
+			expected: releasePhaseGACandidate,
+		},
+		{
+			name:     "next release in feature freeze",
+			release:  "4.17",
+			now:      start.AddDate(0, 0, 200),
+			expected: releasePhaseFeatureFreeze,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, releasePhaseFor(tc.release, milestoneTags, tc.now))
+		})
+	}
+}
+
+func TestResolveReleaseLeeway(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("no phase leeway", func(t *testing.T) {
+		o := &JobRunHistoricalDataAnalyzerOptions{leeway: 10}
+		releaseLeeway, err := o.resolveReleaseLeeway(ctx, "4.15", "4.16")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]float64{"4.15": 10, "4.16": 10}, releaseLeeway)
+		assert.Equal(t, "10.00% for 4.15, 10.00% for 4.16", formatReleaseLeeway(releaseLeeway, "4.15", "4.16"))
+	})
+
+	t.Run("by phase", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		ciDataClient := jobrunaggregatorlib.NewMockCIDataClient(mockCtrl)
+		ciDataClient.EXPECT().ListReleaseMilestoneTags(ctx, []string{"4.15", "4.16"}).Return([]jobrunaggregatorapi.ReleaseTagRow{
+			{Release: "4.15", ReleaseTag: "4.15.0", ReleaseTime: time.Now().AddDate(0, -6, 0)},
+			{Release: "4.16", ReleaseTag: "4.16.0-ec.3", ReleaseTime: time.Now().AddDate(0, 0, -7)},
+		}, nil)
+
+		o := &JobRunHistoricalDataAnalyzerOptions{
+			ciDataClient: ciDataClient,
+			leeway:       10,
+			phaseLeeway:  map[string]float64{releasePhaseDevelopment: 20, releasePhaseFeatureFreeze: 15},
+		}
+		releaseLeeway, err := o.resolveReleaseLeeway(ctx, "4.15", "4.16")
+		assert.NoError(t, err)
+		// GA candidates have no phase leeway, so they keep the default one
+		assert.Equal(t, map[string]float64{"4.15": 10, "4.16": 20}, releaseLeeway)
+		assert.Equal(t, "10.00% for 4.15, 20.00% for 4.16", formatReleaseLeeway(releaseLeeway, "4.15", "4.16"))
+	})
+}
+
+func TestParsePhaseLeeway(t *testing.T) {
+	tests := []struct {
+		name        string
+		phaseLeeway map[string]string
+		expected    map[string]float64
+		expectedErr string
+	}{
+		{
+			name:     "none",
+			expected: map[string]float64{},
+		},
+		{
+			name:        "every phase",
+			phaseLeeway: map[string]string{"development": "20", "feature-freeze": "10.5", "ga-candidate": "0"},
+			expected:    map[string]float64{"development": 20, "feature-freeze": 10.5, "ga-candidate": 0},
+		},
+		{
+			name:        "unknown phase",
+			phaseLeeway: map[string]string{"released": "5"},
+			expectedErr: `unknown release phase "released", valid values are: [development feature-freeze ga-candidate]`,
+		},
+		{
+			name:        "not a number",
+			phaseLeeway: map[string]string{"development": "twenty"},
+			expectedErr: `invalid leeway "twenty" for release phase "development"`,
+		},
+		{
+			name:        "negative",
+			phaseLeeway: map[string]string{"ga-candidate": "-5"},
+			expectedErr: `leeway percent for release phase "ga-candidate" must be above 0`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := parsePhaseLeeway(tc.phaseLeeway)
+			if len(tc.expectedErr) > 0 {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, parsed)
+		})
+	}
+}
@@ -20,26 +20,39 @@ import (
 // data file, and let origin sort out what to do with that data.
 const minJobRuns = 100
 
-func readHistoricalDataFile(filePath, dataType string) ([]jobrunaggregatorapi.HistoricalData, error) {
+// readHistoricalDataFile returns the rows of the file, and its metadata when the file has some.
+func readHistoricalDataFile(filePath, dataType string) ([]jobrunaggregatorapi.HistoricalData, *historicalDataMetadata, error) {
 	currentData, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file at path (%s): %w", filePath, err)
+		return nil, nil, fmt.Errorf("failed to open file at path (%s): %w", filePath, err)
+	}
+	rows, metadata, err := splitHistoricalDataFile(currentData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read historical data file (%s): %w", filePath, err)
 	}
 
+	var historicalData []jobrunaggregatorapi.HistoricalData
 	switch dataType {
 	case "alerts":
-		historicalData := []*jobrunaggregatorapi.AlertHistoricalDataRow{}
-		if err := json.Unmarshal(currentData, &historicalData); err != nil {
-			return nil, err
+		alertData := []*jobrunaggregatorapi.AlertHistoricalDataRow{}
+		if err := json.Unmarshal(rows, &alertData); err != nil {
+			return nil, nil, err
 		}
-		return jobrunaggregatorapi.ConvertToHistoricalData(historicalData), nil
+		historicalData = jobrunaggregatorapi.ConvertToHistoricalData(alertData)
 	default:
-		historicalData := []*jobrunaggregatorapi.DisruptionHistoricalDataRow{}
-		if err := json.Unmarshal(currentData, &historicalData); err != nil {
-			return nil, err
+		disruptionData := []*jobrunaggregatorapi.DisruptionHistoricalDataRow{}
+		if err := json.Unmarshal(rows, &disruptionData); err != nil {
+			return nil, nil, err
+		}
+		historicalData = jobrunaggregatorapi.ConvertToHistoricalData(disruptionData)
+	}
+
+	if metadata != nil {
+		if err := metadata.validate(dataType, len(historicalData)); err != nil {
+			return nil, nil, fmt.Errorf("invalid metadata in historical data file (%s): %w", filePath, err)
 		}
-		return jobrunaggregatorapi.ConvertToHistoricalData(historicalData), nil
 	}
+	return historicalData, metadata, nil
 }
 
 func convertToMap(data []jobrunaggregatorapi.HistoricalData) map[string]jobrunaggregatorapi.HistoricalData {
@@ -148,7 +161,7 @@ func formatTableOutput(data []parsedJobData, filter bool) string {
 	return buffer.String()
 }
 
-func formatOutput(data []parsedJobData, format string) ([]byte, error) {
+func formatOutput(data []parsedJobData, format string, metadata *historicalDataMetadata) ([]byte, error) {
 	if len(data) == 0 {
 		return nil, nil
 	}
@@ -161,7 +174,8 @@ func formatOutput(data []parsedJobData, format string) ([]byte, error) {
 		sort.SliceStable(collectedResults, func(i, j int) bool {
 			return collectedResults[i].GetKey() < collectedResults[j].GetKey()
 		})
-		return json.MarshalIndent(collectedResults, "", "  ")
+		metadata.RowCount = len(collectedResults)
+		return marshalHistoricalDataFile(metadata, collectedResults)
 	default:
 		return nil, fmt.Errorf("invalid output format (%s)", format)
 	}
@@ -0,0 +1,201 @@
+package jobruntablecreator
+
+import (
+	"context"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+)
+
+type TableCreateFlags struct {
+	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
+	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
+	TablePolicy     *jobrunaggregatorlib.TablePolicyFlags
+
+	DryRun bool
+}
+
+func NewTableCreateFlags() *TableCreateFlags {
+	return &TableCreateFlags{
+		DataCoordinates: jobrunaggregatorlib.NewBigQueryDataCoordinates(),
+		Authentication:  jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+		TablePolicy:     jobrunaggregatorlib.NewTablePolicyFlags(),
+	}
+}
+
+func (f *TableCreateFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DataCoordinates.BindFlags(fs)
+	f.Authentication.BindFlags(fs)
+	f.TablePolicy.BindFlags(fs)
+	fs.BoolVar(&f.DryRun, "dry-run", f.DryRun, "Print the tables and views that would be created and the columns that would be added to existing tables, without changing them.")
+}
+
+func NewTableCreateCommand() *cobra.Command {
+	f := NewTableCreateFlags()
+
+	cmd := &cobra.Command{
+		Use: "create-tables",
+		Long: `Create the tables of the aggregator in bigquery, or add the columns they are missing.
+
+The tables are the Jobs table, the tables of the job runs loaded by the loaders and of their checkpoints, the
+tables the analyzers record their outcomes to, and the release tables, all declared with their schema,
+descriptions and partitioning in one place.  Once the tables are up to date, the
+materialized views selecting from them, like the BackendDisruptionHistogram the disruption statistics are
+computed from, are created, or replaced when their query changed.
+
+The clustering of existing tables is changed in place, the rows written from then on are clustered by the
+declared columns.  Their partitioning can't be changed in place, so a different partitioning is only printed.
+To partition an existing table, pause the commands writing to it, copy its rows into a table created with the
+declared partitioning, and replace the table with the copy, e.g. for JobRuns:
+
+  bq query --use_legacy_sql=false 'CREATE TABLE <dataset>.JobRuns_partitioned
+    PARTITION BY DATE(StartTime) CLUSTER BY JobName AS SELECT * FROM <dataset>.JobRuns'
+  bq rm -f -t <dataset>.JobRuns
+  bq cp <dataset>.JobRuns_partitioned <dataset>.JobRuns
+  bq rm -f -t <dataset>.JobRuns_partitioned
+
+then run create-tables again to restore the descriptions and labels of the table.`,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			if err := f.Validate(); err != nil {
+				logrus.WithError(err).Fatal("Flags are invalid")
+			}
+			o, err := f.ToOptions(ctx)
+			if err != nil {
+				logrus.WithError(err).Fatal("Failed to build runtime options")
+			}
+
+			if err := o.Run(ctx); err != nil {
+				logrus.WithError(err).Fatal("Command failed")
+			}
+
+			return nil
+		},
+
+		Args: jobrunaggregatorlib.NoArgs,
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+// Validate checks to see if the user-input is likely to produce functional runtime options
+func (f *TableCreateFlags) Validate() error {
+	if err := f.DataCoordinates.Validate(); err != nil {
+		return err
+	}
+	if err := f.Authentication.Validate(); err != nil {
+		return err
+	}
+	if err := f.TablePolicy.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ToOptions goes from the user input to the runtime values need to run the command.
+// Expect to see unit tests on the options, but not on the flags which are simply value mappings.
+func (f *TableCreateFlags) ToOptions(ctx context.Context) (*tableCreatorOptions, error) {
+	bigQueryClient, err := f.Authentication.NewBigQueryClient(ctx, f.DataCoordinates.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tableCreatorOptions{
+		ciDataSet:   bigQueryClient.Dataset(f.DataCoordinates.DataSetID),
+		tablePolicy: f.TablePolicy,
+		dryRun:      f.DryRun,
+	}, nil
+}
+
+type SchemaVerifyFlags struct {
+	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
+	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
+}
+
+func NewSchemaVerifyFlags() *SchemaVerifyFlags {
+	return &SchemaVerifyFlags{
+		DataCoordinates: jobrunaggregatorlib.NewBigQueryDataCoordinates(),
+		Authentication:  jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+	}
+}
+
+func (f *SchemaVerifyFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DataCoordinates.BindFlags(fs)
+	f.Authentication.BindFlags(fs)
+}
+
+func NewSchemaVerifyCommand() *cobra.Command {
+	f := NewSchemaVerifyFlags()
+
+	cmd := &cobra.Command{
+		Use: "verify-schemas",
+		Long: `Compare the tables and views in bigquery with the ones declared by create-tables, and fail when they drifted.
+
+Every difference is printed on its own line, prefixed with the table:
+  + column    the column is declared but missing from the table, create-tables adds it
+  ! message   the table or view is missing, the query of the view changed, or the column is incompatible
+  ? message   the column is not declared or the partitioning differs, which doesn't fail
+
+The command fails on + and ! lines, since writing the declared rows to those tables fails.`,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			if err := f.Validate(); err != nil {
+				logrus.WithError(err).Fatal("Flags are invalid")
+			}
+			o, err := f.ToOptions(ctx)
+			if err != nil {
+				logrus.WithError(err).Fatal("Failed to build runtime options")
+			}
+
+			if err := o.Run(ctx); err != nil {
+				logrus.WithError(err).Fatal("Command failed")
+			}
+
+			return nil
+		},
+
+		Args: jobrunaggregatorlib.NoArgs,
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+// Validate checks to see if the user-input is likely to produce functional runtime options
+func (f *SchemaVerifyFlags) Validate() error {
+	if err := f.DataCoordinates.Validate(); err != nil {
+		return err
+	}
+	if err := f.Authentication.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ToOptions goes from the user input to the runtime values need to run the command.
+func (f *SchemaVerifyFlags) ToOptions(ctx context.Context) (*schemaVerifierOptions, error) {
+	bigQueryClient, err := f.Authentication.NewBigQueryClient(ctx, f.DataCoordinates.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemaVerifierOptions{
+		ciDataSet: bigQueryClient.Dataset(f.DataCoordinates.DataSetID),
+		out:       os.Stdout,
+	}, nil
+}
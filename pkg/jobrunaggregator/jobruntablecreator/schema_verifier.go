@@ -0,0 +1,53 @@
+package jobruntablecreator
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/sirupsen/logrus"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+)
+
+type schemaVerifierOptions struct {
+	ciDataSet *bigquery.Dataset
+	out       io.Writer
+}
+
+func (o *schemaVerifierOptions) Run(ctx context.Context) error {
+	errs := []error{}
+	drifted := []string{}
+	specs := jobrunaggregatorlib.DeclaredTableSpecs()
+	for _, spec := range specs {
+		tableDrifted, err := jobrunaggregatorlib.VerifyTableSchema(ctx, o.ciDataSet, spec, o.out)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if tableDrifted {
+			drifted = append(drifted, spec.Name)
+		}
+	}
+	viewSpecs := jobrunaggregatorlib.DeclaredMaterializedViewSpecs()
+	for _, spec := range viewSpecs {
+		viewDrifted, err := jobrunaggregatorlib.VerifyMaterializedView(ctx, o.ciDataSet, spec, o.out)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if viewDrifted {
+			drifted = append(drifted, spec.Name)
+		}
+	}
+	if len(drifted) > 0 {
+		errs = append(errs, fmt.Errorf("%d tables drifted from their declaration, run create-tables or fix them by hand: %v", len(drifted), drifted))
+	}
+	if len(errs) == 0 {
+		logrus.Infof("the %d declared tables and %d declared views match their schema", len(specs), len(viewSpecs))
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,26 @@
+package jobruntablecreator
+
+import (
+	"context"
+	"os"
+
+	"cloud.google.com/go/bigquery"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+)
+
+type tableCreatorOptions struct {
+	ciDataSet   *bigquery.Dataset
+	tablePolicy *jobrunaggregatorlib.TablePolicyFlags
+	// dryRun prints the tables and views that would be created and the columns that would be added, without changing
+	// them
+	dryRun bool
+}
+
+func (o *tableCreatorOptions) Run(ctx context.Context) error {
+	if err := jobrunaggregatorlib.CreateOrMigrateTables(ctx, o.ciDataSet, jobrunaggregatorlib.DeclaredTableSpecs(), o.tablePolicy, o.dryRun, os.Stdout); err != nil {
+		// the views select from the tables, so they wait for the tables to be fixed
+		return err
+	}
+	return jobrunaggregatorlib.CreateOrReplaceMaterializedViews(ctx, o.ciDataSet, jobrunaggregatorlib.DeclaredMaterializedViewSpecs(), o.dryRun, os.Stdout)
+}
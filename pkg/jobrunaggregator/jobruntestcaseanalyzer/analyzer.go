@@ -3,15 +3,17 @@ package jobruntestcaseanalyzer
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v2"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 	prowjobv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
@@ -25,6 +27,46 @@ import (
 type testIdentifier struct {
 	testSuites []string
 	testName   string
+	// regexes, when set, match the suites and the test by regular expressions instead of by name, for tests whose
+	// names encode versions or platforms
+	regexes *testIdentifierRegexes
+}
+
+type testIdentifierRegexes struct {
+	testSuites []*regexp.Regexp
+	testName   *regexp.Regexp
+}
+
+// key identifies the test across checkers, identifiers with the same suites and name are the same test
+func (id testIdentifier) key() string {
+	return strings.Join(append(append([]string{}, id.testSuites...), id.testName), "\x00")
+}
+
+func (id testIdentifier) matchesTopSuite(suiteName string) bool {
+	if len(id.testSuites) == 0 {
+		return false
+	}
+	if id.regexes != nil {
+		return id.regexes.testSuites[0].MatchString(suiteName)
+	}
+	return id.testSuites[0] == suiteName
+}
+
+func (id testIdentifier) matchesTestName(testName string) bool {
+	if id.regexes != nil {
+		return id.regexes.testName.MatchString(testName)
+	}
+	return id.testName == testName
+}
+
+// childIdentifier returns the identifier of the test within the top suite
+func (id testIdentifier) childIdentifier() testIdentifier {
+	child := id
+	child.testSuites = id.testSuites[1:]
+	if id.regexes != nil {
+		child.regexes = &testIdentifierRegexes{testSuites: id.regexes.testSuites[1:], testName: id.regexes.testName}
+	}
+	return child
 }
 
 var (
@@ -44,6 +86,20 @@ var (
 	upgradeTestSuite      = []string{"openshift-tests-upgrade"}
 	upgradeTest           = "[sig-arch][Feature:ClusterUpgrade] Cluster should be upgradeable after finishing upgrade [Late][Suite:upgrade]"
 	upgradeTestIdentifier = testIdentifier{testSuites: upgradeTestSuite, testName: upgradeTest}
+
+	// openshift-tests records the result of the whole conformance suite run as a synthetic test, unlike the step
+	// graph it only fails when conformance tests failed, not when the job failed in another phase.
+	conformanceTestGroup      = "conformance"
+	conformanceTestSuite      = []string{"openshift-tests"}
+	conformanceTest           = "[sig-arch] openshift-tests should work"
+	conformanceTestIdentifier = testIdentifier{testSuites: conformanceTestSuite, testName: conformanceTest}
+
+	testIdentifiersByGroup = map[string]testIdentifier{
+		installTestGroup:     installTestIdentifier,
+		overallTestGroup:     overallTestIdentifier,
+		upgradeTestGroup:     upgradeTestIdentifier,
+		conformanceTestGroup: conformanceTestIdentifier,
+	}
 )
 
 // JobGetter gets related jobs for further analysis
@@ -51,17 +107,27 @@ type JobGetter interface {
 	GetJobs(ctx context.Context) ([]jobrunaggregatorapi.JobRowWithVariants, error)
 }
 
-func NewTestCaseAnalyzerJobGetter(platform, infrastructure, network, testNameSuffix string,
-	excludeJobNames, includeJobNames []string,
+// neverPassingJobsReporter is implemented by JobGetters that drop jobs without recent successful runs.
+type neverPassingJobsReporter interface {
+	NeverPassingJobs() []string
+}
+
+func NewTestCaseAnalyzerJobGetter(platform, architecture, infrastructure, network, release, testNameSuffix string, variants []jobVariant,
+	excludeJobNames, includeJobNames, includeExactJobNames []string, excludeJobRegexes []*regexp.Regexp, neverPassingLookback time.Duration,
 	jobGCSPrefixes *[]jobGCSPrefix, ciDataClient jobrunaggregatorlib.CIDataClient) *testCaseAnalyzerJobGetter {
 	jobGetter := &testCaseAnalyzerJobGetter{
-		platform:       platform,
-		infrastructure: infrastructure,
-		network:        network,
-		testNameSuffix: testNameSuffix,
-		jobGCSPrefixes: jobGCSPrefixes,
-		ciDataClient:   ciDataClient,
-		jobNames:       sets.Set[string]{},
+		platform:             platform,
+		architecture:         architecture,
+		infrastructure:       infrastructure,
+		network:              network,
+		release:              release,
+		testNameSuffix:       testNameSuffix,
+		variants:             variants,
+		excludeJobRegexes:    excludeJobRegexes,
+		neverPassingLookback: neverPassingLookback,
+		jobGCSPrefixes:       jobGCSPrefixes,
+		ciDataClient:         ciDataClient,
+		jobNames:             sets.Set[string]{},
 	}
 	if jobGCSPrefixes != nil && len(*jobGCSPrefixes) > 0 {
 		for i := range *jobGCSPrefixes {
@@ -79,11 +145,16 @@ func NewTestCaseAnalyzerJobGetter(platform, infrastructure, network, testNameSuf
 		jobGetter.includeJobNames.Insert(includeJobNames...)
 	}
 
+	if len(includeExactJobNames) > 0 {
+		jobGetter.includeExactJobNames = sets.New[string](includeExactJobNames...)
+	}
+
 	return jobGetter
 }
 
 type testCaseAnalyzerJobGetter struct {
 	platform        string
+	architecture    string
 	infrastructure  string
 	network         string
 	excludeJobNames sets.Set[string]
@@ -92,6 +163,21 @@ type testCaseAnalyzerJobGetter struct {
 	jobGCSPrefixes  *[]jobGCSPrefix
 	ciDataClient    jobrunaggregatorlib.CIDataClient
 	jobNames        sets.Set[string]
+
+	// includeExactJobNames, when set, restricts the analysis to exactly these jobs, to pilot it on a few jobs
+	includeExactJobNames sets.Set[string]
+	// excludeJobRegexes exclude the jobs matching any of them, like excludeJobNames does for substrings
+	excludeJobRegexes []*regexp.Regexp
+	// variants, when set, select the jobs matching any of them instead of platform, network and infrastructure
+	variants []jobVariant
+	// release, when set, only selects the jobs of this release, like 4.16, by the Release column of the jobs table
+	// rather than by their name
+	release string
+
+	// neverPassingLookback, when set, excludes jobs that ran during the lookback but never succeeded.
+	// Such jobs are chronically broken and would otherwise fail every payload they are selected for.
+	neverPassingLookback time.Duration
+	neverPassingJobs     []string
 }
 
 func (s *testCaseAnalyzerJobGetter) shouldAggregateJob(prowJob *prowjobv1.ProwJob) bool {
@@ -105,18 +191,18 @@ func (s *testCaseAnalyzerJobGetter) shouldAggregateJob(prowJob *prowjobv1.ProwJo
 		if len(s.platform) != 0 && !strings.Contains(strings.ToLower(jobName), s.platform) {
 			return false
 		}
-		if len(s.network) != 0 {
-			network := "sdn"
-			if strings.Contains(strings.ToLower(jobName), "ovn") {
-				network = "ovn"
-			}
-			if s.network != network {
-				return false
-			}
+		if len(s.architecture) != 0 && s.architecture != architectureFromJobName(jobName) {
+			return false
+		}
+		if len(s.network) != 0 && s.network != networkFromJobName(jobName) {
+			return false
 		}
 		if len(s.infrastructure) != 0 && s.infrastructure != getJobInfrastructure(jobName) {
 			return false
 		}
+		if len(s.variants) > 0 && !anyVariantMatchesJob(s.variants, jobrunaggregatorapi.JobRowWithVariants{JobName: jobName}) {
+			return false
+		}
 
 		if !s.isJobNameIncluded(jobName) {
 			return false
@@ -145,10 +231,43 @@ func (s *testCaseAnalyzerJobGetter) GetJobs(ctx context.Context) ([]jobrunaggreg
 	} else {
 		// Non PR payload, select by criteria
 		jobs = s.filterJobsForPayload(jobs)
+		if jobs, err = s.filterNeverPassingJobs(ctx, jobs); err != nil {
+			return nil, err
+		}
 	}
 	return jobs, nil
 }
 
+func (s *testCaseAnalyzerJobGetter) filterNeverPassingJobs(ctx context.Context, jobs []jobrunaggregatorapi.JobRowWithVariants) ([]jobrunaggregatorapi.JobRowWithVariants, error) {
+	if s.neverPassingLookback <= 0 {
+		return jobs, nil
+	}
+	neverPassing, err := s.ciDataClient.ListJobsWithoutSuccessfulRunsSince(ctx, time.Now().Add(-s.neverPassingLookback))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs without successful runs: %w", err)
+	}
+
+	s.neverPassingJobs = nil
+	ret := []jobrunaggregatorapi.JobRowWithVariants{}
+	for i := range jobs {
+		if neverPassing.Has(jobs[i].JobName) {
+			s.neverPassingJobs = append(s.neverPassingJobs, jobs[i].JobName)
+			continue
+		}
+		ret = append(ret, jobs[i])
+	}
+	sort.Strings(s.neverPassingJobs)
+	for _, jobName := range s.neverPassingJobs {
+		logrus.WithField("job", jobName).Warnf("excluding job without a successful run in the last %s", s.neverPassingLookback)
+	}
+	return ret, nil
+}
+
+// NeverPassingJobs returns the jobs excluded by the last GetJobs because they did not succeed during the lookback.
+func (s *testCaseAnalyzerJobGetter) NeverPassingJobs() []string {
+	return s.neverPassingJobs
+}
+
 func getJobInfrastructure(name string) string {
 	if strings.Contains(name, "upi") {
 		return "upi"
@@ -156,13 +275,25 @@ func getJobInfrastructure(name string) string {
 	return "ipi"
 }
 
+func networkFromJobName(jobName string) string {
+	if strings.Contains(strings.ToLower(jobName), "ovn") {
+		return "ovn"
+	}
+	return "sdn"
+}
+
 func (s *testCaseAnalyzerJobGetter) filterJobsForPayload(allJobs []jobrunaggregatorapi.JobRowWithVariants) []jobrunaggregatorapi.JobRowWithVariants {
 	jobs := []jobrunaggregatorapi.JobRowWithVariants{}
 	for i := range allJobs {
 		job := allJobs[i]
 		if (len(s.platform) != 0 && job.Platform != s.platform) ||
+			(len(s.architecture) != 0 && s.architecture != jobArchitecture(job)) ||
 			(len(s.network) != 0 && job.Network != s.network) ||
-			(len(s.infrastructure) != 0 && s.infrastructure != getJobInfrastructure(job.JobName)) {
+			(len(s.infrastructure) != 0 && s.infrastructure != getJobInfrastructure(job.JobName)) ||
+			(len(s.release) != 0 && job.Release != s.release) {
+			continue
+		}
+		if len(s.variants) > 0 && !anyVariantMatchesJob(s.variants, job) {
 			continue
 		}
 
@@ -179,11 +310,11 @@ func (s *testCaseAnalyzerJobGetter) filterJobsForPayload(allJobs []jobrunaggrega
 	return jobs
 }
 
-// isJobNameIncluded checks to see the job name contains all strings defined in includeJobNames
+// isJobNameIncluded checks to see the job name is one of includeExactJobNames and contains all strings defined in
+// includeJobNames
 func (s *testCaseAnalyzerJobGetter) isJobNameIncluded(jobName string) bool {
-
-	if s.includeJobNames == nil {
-		return true
+	if s.includeExactJobNames != nil && !s.includeExactJobNames.Has(jobName) {
+		return false
 	}
 
 	for key := range s.includeJobNames {
@@ -196,17 +327,18 @@ func (s *testCaseAnalyzerJobGetter) isJobNameIncluded(jobName string) bool {
 }
 
 func (s *testCaseAnalyzerJobGetter) isJobNameExcluded(jobName string) bool {
-
-	if s.excludeJobNames == nil {
-		return false
-	}
-
 	for key := range s.excludeJobNames {
 		if strings.Contains(jobName, key) {
 			return true
 		}
 	}
 
+	for _, re := range s.excludeJobRegexes {
+		if re.MatchString(jobName) {
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -233,6 +365,24 @@ type minimumRequiredPassesTestCaseChecker struct {
 	// be created. This might include variant info like platform, network and infrastructure etc.
 	testNameSuffix         string
 	requiredNumberOfPasses int
+	// autoRequiredPasses, when set, replaces requiredNumberOfPasses with a minimum derived from history for the jobs
+	// of architecture, or of all architectures when it is empty
+	autoRequiredPasses *autoRequiredPasses
+	architecture       string
+
+	// suiteName replaces the name of the suite of the checker, minimumRequiredPassesSuiteName when empty
+	suiteName string
+}
+
+func (r minimumRequiredPassesTestCaseChecker) String() string {
+	return r.id.testName
+}
+
+func (r minimumRequiredPassesTestCaseChecker) minimumPasses() int {
+	if r.autoRequiredPasses != nil {
+		return r.autoRequiredPasses.requiredPasses(r.architecture)
+	}
+	return r.requiredNumberOfPasses
 }
 
 type testStatus int
@@ -241,33 +391,25 @@ const (
 	testSkipped testStatus = iota
 	testPassed
 	testFailed
+	// testFlaked is a test that failed and then passed on a rerun in the same suite, it counts as a pass
+	testFlaked
+	// testUnreadable is a test of a job run whose junit could not be read in time
+	testUnreadable
 )
 
 func getTestStatus(id testIdentifier, testSuite *junit.TestSuite) testStatus {
-	if len(id.testSuites) == 0 || id.testSuites[0] != testSuite.Name {
+	if !id.matchesTopSuite(testSuite.Name) {
 		return testSkipped
 	}
 	// We have a top level suite match, search for test case
 	if len(id.testSuites) == 1 {
-		for _, testCase := range testSuite.TestCases {
-			// Exact match will result in either pass or fail
-			if testCase.Name == id.testName {
-				if testCase.FailureOutput == nil {
-					return testPassed
-				} else {
-					return testFailed
-				}
-			}
-		}
-		return testSkipped
+		return getTestCasesStatus(id, testSuite.TestCases)
 	}
 	// Search next level
-	next := id
-	next.testSuites = id.testSuites[1:]
+	next := id.childIdentifier()
 	for _, childSuite := range testSuite.Children {
-		if len(next.testSuites) > 0 && next.testSuites[0] == childSuite.Name {
-			status := getTestStatus(next, childSuite)
-			if status == testPassed || status == testFailed {
+		if next.matchesTopSuite(childSuite.Name) {
+			if status := getTestStatus(next, childSuite); status != testSkipped {
 				return status
 			}
 		}
@@ -275,9 +417,77 @@ func getTestStatus(id testIdentifier, testSuite *junit.TestSuite) testStatus {
 	return testSkipped
 }
 
+// getTestCasesStatus returns the status of the test cases the identifier matches.  A test case that failed and
+// passed on a later rerun, under the same name, flaked.  A regular expression matching several test cases evaluates
+// every matched name on its own: one of them failing without a later pass fails the test.
+func getTestCasesStatus(id testIdentifier, testCases []*junit.TestCase) testStatus {
+	// failedLast holds the matched names by whether their last run failed, flaked the names that passed on a rerun
+	failedLast := map[string]bool{}
+	flaked := sets.New[string]()
+	for _, testCase := range testCases {
+		if !id.matchesTestName(testCase.Name) {
+			continue
+		}
+		if testCase.FailureOutput != nil {
+			failedLast[testCase.Name] = true
+			continue
+		}
+		if failedLast[testCase.Name] {
+			flaked.Insert(testCase.Name)
+		}
+		failedLast[testCase.Name] = false
+	}
+	if len(failedLast) == 0 {
+		return testSkipped
+	}
+	for _, failed := range failedLast {
+		if failed {
+			return testFailed
+		}
+	}
+	if flaked.Len() > 0 {
+		return testFlaked
+	}
+	return testPassed
+}
+
+// getTestStatusInJobRun returns the result of the first test suite of the job run that ran the test.  Job runs
+// without junit are the ones whose junit could not be read in time, unfinishedJobRunJunit fails every test.
+func getTestStatusInJobRun(id testIdentifier, testSuites *junit.TestSuites) testStatus {
+	if testSuites == nil {
+		return testUnreadable
+	}
+	if testSuites == unfinishedJobRunJunit {
+		return testFailed
+	}
+	for _, testSuite := range testSuites.Suites {
+		if status := getTestStatus(id, testSuite); status != testSkipped {
+			return status
+		}
+	}
+	return testSkipped
+}
+
 func (r minimumRequiredPassesTestCaseChecker) addTestResultToDetails(currDetails *jobrunaggregatorlib.TestCaseDetails,
 	jobRun jobrunaggregatorapi.JobRunInfo, status testStatus) {
 	switch status {
+	case testUnreadable:
+		currDetails.Unreadable = append(
+			currDetails.Unreadable,
+			jobrunaggregatorlib.TestCaseUnreadable{
+				JobRunID:       jobRun.GetJobRunID(),
+				HumanURL:       jobRun.GetHumanURL(),
+				GCSArtifactURL: jobRun.GetGCSArtifactURL(),
+			})
+	case testFlaked:
+		currDetails.Flakes = append(
+			currDetails.Flakes,
+			jobrunaggregatorlib.TestCaseFlake{
+				JobRunID:       jobRun.GetJobRunID(),
+				HumanURL:       jobRun.GetHumanURL(),
+				GCSArtifactURL: jobRun.GetGCSArtifactURL(),
+			})
+		fallthrough
 	case testPassed:
 		currDetails.Passes = append(
 			currDetails.Passes,
@@ -320,27 +530,14 @@ func addToTestSuiteFromSuiteNames(topSuite *junit.TestSuite, suiteNames []string
 	return previousSuite
 }
 
-func updateTestCountsInSuite(suite *junit.TestSuite) {
-	var numTests, numFailed uint
-	for _, test := range suite.TestCases {
-		numTests++
-		if test.FailureOutput != nil {
-			numFailed++
-		}
-	}
-	for _, child := range suite.Children {
-		updateTestCountsInSuite(child)
-		numTests += child.NumTests
-		numFailed += child.NumFailed
-	}
-	suite.NumTests = numTests
-	suite.NumFailed = numFailed
-}
-
 // CheckTestCase returns a test case based on whether a test has passed certain criteria across job runs
 func (r minimumRequiredPassesTestCaseChecker) CheckTestCase(ctx context.Context, jobRunJunits map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) *junit.TestSuite {
+	suiteName := r.suiteName
+	if len(suiteName) == 0 {
+		suiteName = minimumRequiredPassesSuiteName
+	}
 	topSuite := &junit.TestSuite{
-		Name:      "minimum-required-passes-checker",
+		Name:      suiteName,
 		TestCases: []*junit.TestCase{},
 	}
 	bottomSuite := addToTestSuiteFromSuiteNames(topSuite, r.id.testSuites)
@@ -361,37 +558,23 @@ func (r minimumRequiredPassesTestCaseChecker) CheckTestCase(ctx context.Context,
 		TestSuiteName: strings.Join(r.id.testSuites, jobrunaggregatorlib.TestSuitesSeparator),
 	}
 	for jobRun, testSuites := range jobRunJunits {
-		status := testSkipped
-		// Now go through test suites check test result
-		for _, testSuite := range testSuites.Suites {
-			status = getTestStatus(r.id, testSuite)
-			found := false
-			switch status {
-			case testPassed:
-				found = true
-				successCount++
-			case testFailed:
-				found = true
-			}
-			if found {
-				break
-			}
+		status := getTestStatusInJobRun(r.id, testSuites)
+		if status == testPassed || status == testFlaked {
+			successCount++
 		}
 		r.addTestResultToDetails(currDetails, jobRun, status)
 	}
 	currDetails.Summary = fmt.Sprintf("Total job runs: %d, passes: %d, failures: %d, skips %d", len(jobRunJunits), len(currDetails.Passes), len(currDetails.Failures), len(currDetails.Skips))
-	detailsYaml, err := yaml.Marshal(currDetails)
-	if err != nil {
+	if err := jobrunaggregatorlib.SetTestCaseDetails(testCase, currDetails); err != nil {
 		return nil
 	}
 	testCase.Duration = time.Since(start).Seconds()
-	testCase.SystemOut = string(detailsYaml)
-	if successCount < r.requiredNumberOfPasses {
+	if requiredPasses := r.minimumPasses(); successCount < requiredPasses {
 		testCase.FailureOutput = &junit.FailureOutput{
-			Message: fmt.Sprintf("required minimum successful count %d, got %d", r.requiredNumberOfPasses, successCount),
+			Message: fmt.Sprintf("required minimum successful count %d, got %d", requiredPasses, successCount),
 		}
 	}
-	updateTestCountsInSuite(topSuite)
+	jobrunaggregatorlib.UpdateTestCountsInSuite(topSuite)
 	return topSuite
 }
 
@@ -401,9 +584,12 @@ func (r minimumRequiredPassesTestCaseChecker) CheckTestCase(ctx context.Context,
 // 3. runs all test case checkers and constructs a synthetic junit
 type JobRunTestCaseAnalyzerOptions struct {
 	payloadTag string
+	// payloads is only set when several payload tags are analyzed at once, payloadTag is then empty
+	payloads   []payloadToAnalyze
 	workingDir string
 	// jobRunStartEstimate is used by job run locator to calculate the time window to search for job runs.
 	jobRunStartEstimate time.Time
+	jobSearchWindow     jobrunaggregatorlib.JobSearchWindow
 	timeout             time.Duration
 	ciDataClient        jobrunaggregatorlib.CIDataClient
 	ciGCSClient         jobrunaggregatorlib.CIGCSClient
@@ -415,9 +601,62 @@ type JobRunTestCaseAnalyzerOptions struct {
 	prowJobClient       *prowjobclientset.Clientset
 	jobStateQuerySource string
 	prowJobMatcherFunc  jobrunaggregatorlib.ProwJobMatcherFunc
+	// adaptiveWait stops waiting for each job based on its historical duration instead of at a fixed time
+	adaptiveWait bool
+	// stopWaitingAtMinimumPasses stops waiting for unfinished job runs as soon as the finished ones pass the checkers
+	stopWaitingAtMinimumPasses bool
+	// unfinishedPolicy decides whether job runs still unfinished once waiting is over are skipped, failed, or waited
+	// for longer
+	unfinishedPolicy string
+	// sampler bounds the number of job runs waited on and analyzed per job, nil samples nothing
+	sampler *jobRunSampler
+	// progress is optional, it shows an interactive user what the analyzer is doing
+	progress jobrunaggregatorlib.ProgressReporter
+	// jobArchitectures is only set when the minimum passes are required per architecture
+	jobArchitectures *jobArchitectures
+	// autoRequiredPasses is only set when the minimum passes are derived from history
+	autoRequiredPasses *autoRequiredPasses
+	// optionalJobs are reported on without deciding the verdict
+	optionalJobs *optionalJobs
+	// variantJobs is only set when several variants are analyzed at once
+	variantJobs *variantJobs
+	// findJobRunsRetryPolicy decides how searching for the job runs of a job is retried, the retries are counted in
+	// findJobRunsRetries
+	findJobRunsRetryPolicy retryPolicy
+	findJobRunsRetries     *retryTelemetry
+	// junitFetchTimeout, when set, bounds reading the junit of every job run
+	junitFetchTimeout time.Duration
 
 	staticJobRunIdentifiers []jobrunaggregatorlib.JobRunIdentifier
 	gcsBucket               string
+
+	testGroup string
+	// gateOverride, when set, force-accepts failed test cases.  Every override is recorded with gateOverrideInserter.
+	gateOverride         *jobrunaggregatorlib.GateOverride
+	gateOverrideInserter jobrunaggregatorlib.BigQueryInserter
+	// locatedJobRunInserter records the job runs found for the payload, so later analyzers don't search GCS again.  It
+	// is nil when the located job runs aren't cached, they are then always searched for.
+	locatedJobRunInserter jobrunaggregatorlib.BigQueryInserter
+	// gateResultInserter keeps the verdict of every test case checker, it is nil when results aren't retained
+	gateResultInserter jobrunaggregatorlib.BigQueryInserter
+	// testCaseAnalysisInserter keeps the pass and fail counts every checker decided on, it is nil when they aren't
+	// retained
+	testCaseAnalysisInserter jobrunaggregatorlib.BigQueryInserter
+
+	// testOwners names the component responsible for failed test cases
+	testOwners *jobrunaggregatorlib.TestOwners
+	// testRenames gives test cases that were renamed upstream the name the test identifier uses
+	testRenames *jobrunaggregatorlib.TestRenames
+
+	// notifier is told the verdict, it is nil when no notification target is configured
+	notifier jobrunaggregatorlib.Notifier
+	// metricsExporter pushes the metrics once the analysis is over, it is nil when metrics aren't pushed
+	metricsExporter *jobrunaggregatorlib.MetricsExporter
+
+	// topSuiteName replaces the name of the suite of the analysis, defaultTopSuiteName when empty
+	topSuiteName string
+	// evidenceUploader stores the evidence of failed test cases, it is nil when no location is configured
+	evidenceUploader jobrunaggregatorlib.EvidenceUploader
 }
 
 func (o *JobRunTestCaseAnalyzerOptions) shouldAggregateJob(prowJob *prowjobv1.ProwJob) bool {
@@ -449,47 +688,26 @@ func (o *JobRunTestCaseAnalyzerOptions) findJobRunsWithRetry(ctx context.Context
 		return o.loadStaticJobRuns(ctx, jobName, jobRunLocator)
 	}
 
-	errorsInARow := 0
-	for {
-		jobRuns, err := jobRunLocator.FindRelatedJobs(ctx)
-		if err != nil {
-			if errorsInARow > 20 {
-				fmt.Printf("give up finding job runs for %s after retries: %v", jobName, err)
-				return nil, err
-			}
-			errorsInARow++
-			fmt.Printf("error finding job runs for %s: %v", jobName, err)
-		} else {
-			return jobRuns, nil
-		}
-
-		fmt.Printf("   waiting and will attempt to find related jobs for %s in a minute\n", jobName)
-		select {
-		case <-ctx.Done():
-			// Simply return. Caller will check ctx and return error
-			return nil, ctx.Err()
-		case <-time.After(1 * time.Minute):
-			continue
-		}
-	}
+	return o.findJobRunsRetryPolicy.findJobRuns(ctx, jobName, o.findJobRunsRetries, func(ctx context.Context) ([]jobrunaggregatorapi.JobRunInfo, error) {
+		return jobRunLocator.FindRelatedJobs(ctx)
+	})
 }
 
 func (o *JobRunTestCaseAnalyzerOptions) loadStaticJobRuns(ctx context.Context, jobName string, jobRunLocator jobrunaggregatorlib.JobRunLocator) ([]jobrunaggregatorapi.JobRunInfo, error) {
-	var outputRuns []jobrunaggregatorapi.JobRunInfo
+	var jobRunIDs []string
 	for _, jobRunIdentifier := range o.staticJobRunIdentifiers {
-		if jobRunIdentifier.JobName != jobName {
-			continue
+		if jobRunIdentifier.JobName == jobName {
+			jobRunIDs = append(jobRunIDs, jobRunIdentifier.JobRunID)
 		}
+	}
+	if len(jobRunIDs) == 0 {
+		return nil, nil
+	}
 
-		jobRun, err := jobRunLocator.FindJob(ctx, jobRunIdentifier.JobRunID)
-		if err != nil {
-			// Do not fail when one job fetch fails
-			logrus.WithError(err).Errorf("error finding job %s", jobRunIdentifier.JobRunID)
-			continue
-		}
-		if jobRun != nil {
-			outputRuns = append(outputRuns, jobRun)
-		}
+	outputRuns, err := jobRunLocator.FindJobs(ctx, jobRunIDs)
+	if err != nil {
+		// Do not fail when some job fetches fail
+		logrus.WithError(err).Errorf("error finding jobs of %s", jobName)
 	}
 	return outputRuns, nil
 }
@@ -531,37 +749,72 @@ func (o *JobRunTestCaseAnalyzerOptions) GetRelatedJobRuns(ctx context.Context) (
 	if err != nil {
 		return nil, fmt.Errorf("failed to get related jobs: %w", err)
 	}
+	if o.progress != nil {
+		o.progress.JobsLocated(len(jobs))
+	}
+	if o.jobArchitectures != nil {
+		o.jobArchitectures.record(jobs)
+	}
+	if o.optionalJobs != nil {
+		o.optionalJobs.record(jobs)
+	}
+	if o.variantJobs != nil {
+		o.variantJobs.record(jobs)
+	}
+	if o.autoRequiredPasses != nil {
+		if err := o.autoRequiredPasses.record(ctx, jobs); err != nil {
+			return nil, err
+		}
+	}
 
 	waitGroup := sync.WaitGroup{}
 	resultCh := make(chan []jobrunaggregatorapi.JobRunInfo, len(jobs))
 	for i := range jobs {
 		job := jobs[i]
 		var jobRunLocator jobrunaggregatorlib.JobRunLocator
+		var prowJobMatcherFunc jobrunaggregatorlib.ProwJobMatcherFunc
+		var matchID string
 
 		if len(o.payloadTag) > 0 {
+			matchID = o.payloadTag
 			jobRunLocator = jobrunaggregatorlib.NewPayloadAnalysisJobLocatorForReleaseController(
 				job.JobName,
 				o.payloadTag,
 				o.jobRunStartEstimate,
+				o.jobSearchWindow,
 				o.ciDataClient,
 				o.ciGCSClient,
 				o.gcsBucket,
 			)
+			prowJobMatcherFunc = jobrunaggregatorlib.NewProwJobMatcherFuncForReleaseController(job.JobName, o.payloadTag)
 		}
 		if len(o.payloadInvocationID) > 0 {
+			matchID = o.payloadInvocationID
 			jobRunLocator = jobrunaggregatorlib.NewPayloadAnalysisJobLocatorForPR(
 				job.JobName,
 				o.payloadInvocationID,
 				jobrunaggregatorlib.ProwJobPayloadInvocationIDLabel,
 				o.jobRunStartEstimate,
+				o.jobSearchWindow,
 				o.ciDataClient,
 				o.ciGCSClient,
-				o.gcsBucket,
+				(*o.jobGCSPrefixes)[i].bucketOrDefault(o.gcsBucket),
 				(*o.jobGCSPrefixes)[i].gcsPrefix,
 			)
+			prowJobMatcherFunc = jobrunaggregatorlib.NewProwJobMatcherFuncForPR(job.JobName, o.payloadInvocationID, jobrunaggregatorlib.ProwJobPayloadInvocationIDLabel)
+		}
+		if o.locatedJobRunInserter != nil {
+			jobRunLocator = jobrunaggregatorlib.NewCachingJobRunLocator(
+				jobRunLocator,
+				job.JobName,
+				matchID,
+				prowJobMatcherFunc,
+				o.ciDataClient,
+				o.locatedJobRunInserter,
+			)
 		}
 
-		fmt.Printf("  launching findJobRunsWithRetry for %q\n", job.JobName)
+		logrus.WithField("job", job.JobName).Debug("launching findJobRunsWithRetry")
 
 		waitGroup.Add(1)
 
@@ -586,41 +839,262 @@ func (o *JobRunTestCaseAnalyzerOptions) GetRelatedJobRuns(ctx context.Context) (
 	default:
 		break
 	}
-	return jobRunsToReturn, nil
+	// the waiter only waits on the job runs returned here, so the dropped ones are never waited on nor read
+	return o.sampler.sample(jobRunsToReturn), nil
 }
 
+// runTestCaseCheckers returns the suite of every checker, along with the junit of every job run the checkers read.
 func (o *JobRunTestCaseAnalyzerOptions) runTestCaseCheckers(ctx context.Context,
-	finishedJobRuns []jobrunaggregatorapi.JobRunInfo, unfinishedJobRuns []jobrunaggregatorapi.JobRunInfo) *junit.TestSuite {
-	suiteName := "payload-cross-jobs"
+	finishedJobRuns []jobrunaggregatorapi.JobRunInfo, unfinishedJobRuns []jobrunaggregatorapi.JobRunInfo) (*junit.TestSuite, map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) {
+	suiteName := o.topSuiteName
+	if len(suiteName) == 0 {
+		suiteName = defaultTopSuiteName
+	}
 	topSuite := &junit.TestSuite{
 		Name:      suiteName,
 		TestCases: []*junit.TestCase{},
 	}
 
+	finishedJobRunIDs := sets.Set[string]{}
+	for _, jobRun := range finishedJobRuns {
+		finishedJobRunIDs.Insert(jobRun.GetJobRunID())
+	}
+
 	allJobRuns := append(finishedJobRuns, unfinishedJobRuns...)
 	jobRunJunitMap := map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{}
+	missingArtifacts := map[jobrunaggregatorapi.JobRunInfo]string{}
 	for i := range allJobRuns {
 		jobRun := allJobRuns[i]
+		if o.unfinishedPolicy == unfinishedPolicyFail && !finishedJobRunIDs.Has(jobRun.GetJobRunID()) {
+			jobRunJunitMap[jobRun] = unfinishedJobRunJunit
+			continue
+		}
 
-		testSuites, err := jobRun.GetCombinedJUnitTestSuites(ctx)
-		if err != nil {
+		testSuites, err := o.getJUnitTestSuites(ctx, jobRun)
+		switch {
+		case errors.Is(err, errJUnitFetchTimeout):
+			junitFetchErrorsTotal.WithLabelValues(jobRun.GetJobName(), "timeout").Inc()
+			logrus.WithError(err).WithFields(logrus.Fields{"job": jobRun.GetJobName(), "jobRunID": jobRun.GetJobRunID()}).Warn("giving up on reading the junit of the job run")
+			if finishedJobRunIDs.Has(jobRun.GetJobRunID()) {
+				missingArtifacts[jobRun] = err.Error()
+			}
+			// the job run stays in the analysis without junit, so the checkers report it as unreadable
+			jobRunJunitMap[jobRun] = nil
 			continue
+		case err != nil:
+			junitFetchErrorsTotal.WithLabelValues(jobRun.GetJobName(), "error").Inc()
+			if finishedJobRunIDs.Has(jobRun.GetJobRunID()) {
+				missingArtifacts[jobRun] = fmt.Sprintf("error reading junit: %v", err)
+			}
+			continue
+		case testSuites == nil:
+			testSuites = &junit.TestSuites{}
+		}
+		o.testRenames.RenameTestCases(testSuites)
+		if len(testSuites.Suites) == 0 && finishedJobRunIDs.Has(jobRun.GetJobRunID()) {
+			missingArtifacts[jobRun] = "job run finished without producing any junit"
 		}
 		jobRunJunitMap[jobRun] = testSuites
 	}
-	for _, checker := range o.testCaseCheckers {
-		testSuite := checker.CheckTestCase(ctx, jobRunJunitMap)
+	requiredJunits, optionalJunits := o.optionalJobs.splitOptionalJobRuns(jobRunJunitMap)
+	for _, testSuite := range o.checkJobRuns(ctx, o.testCaseCheckers, requiredJunits) {
+		if testSuite == nil {
+			continue
+		}
 		topSuite.Children = append(topSuite.Children, testSuite)
 		topSuite.NumTests += testSuite.NumTests
 		topSuite.NumFailed += testSuite.NumFailed
 	}
-	return topSuite
+	if len(optionalJunits) > 0 {
+		optionalSuite := o.checkOptionalJobRuns(ctx, optionalJunits)
+		topSuite.Children = append(topSuite.Children, optionalSuite)
+		topSuite.NumTests += optionalSuite.NumTests
+	}
+	if len(missingArtifacts) > 0 {
+		missingSuite := missingArtifactsTestSuite(missingArtifacts)
+		topSuite.Children = append(topSuite.Children, missingSuite)
+		topSuite.NumTests += missingSuite.NumTests
+		topSuite.NumSkipped += missingSuite.NumSkipped
+	}
+	if reporter, ok := o.jobGetter.(neverPassingJobsReporter); ok && len(reporter.NeverPassingJobs()) > 0 {
+		neverPassingSuite := neverPassingJobsTestSuite(reporter.NeverPassingJobs())
+		topSuite.Children = append(topSuite.Children, neverPassingSuite)
+		topSuite.NumTests += neverPassingSuite.NumTests
+		topSuite.NumSkipped += neverPassingSuite.NumSkipped
+	}
+	return topSuite, jobRunJunitMap
+}
+
+// errJUnitFetchTimeout tells a job run whose junit was not read within the junit fetch timeout
+var errJUnitFetchTimeout = errors.New("timed out reading junit")
+
+// getJUnitTestSuites reads the junit of a job run, giving up after junitFetchTimeout so that a single slow or huge
+// artifact can't hold the analysis until the global timeout.  Parsing doesn't watch the context, so reading is
+// abandoned to its goroutine rather than waited for.
+func (o *JobRunTestCaseAnalyzerOptions) getJUnitTestSuites(ctx context.Context, jobRun jobrunaggregatorapi.JobRunInfo) (*junit.TestSuites, error) {
+	if o.junitFetchTimeout <= 0 {
+		return jobRun.GetCombinedJUnitTestSuites(ctx)
+	}
+	fetchCtx, cancel := context.WithTimeout(ctx, o.junitFetchTimeout)
+	defer cancel()
+
+	type fetchResult struct {
+		testSuites *junit.TestSuites
+		err        error
+	}
+	resultCh := make(chan fetchResult, 1)
+	go func() {
+		testSuites, err := jobRun.GetCombinedJUnitTestSuites(fetchCtx)
+		resultCh <- fetchResult{testSuites: testSuites, err: err}
+	}()
+	select {
+	case result := <-resultCh:
+		if result.err != nil && fetchCtx.Err() != nil && ctx.Err() == nil {
+			return nil, fmt.Errorf("%w within %s: %v", errJUnitFetchTimeout, o.junitFetchTimeout, result.err)
+		}
+		return result.testSuites, result.err
+	case <-fetchCtx.Done():
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w within %s", errJUnitFetchTimeout, o.junitFetchTimeout)
+	}
+}
+
+// checkJobRuns returns the suite of every checker.  Checkers only read the shared junit map, so they can run
+// concurrently.  Results are stored by checker index to keep the output order stable regardless of which checker
+// finishes first.
+func (o *JobRunTestCaseAnalyzerOptions) checkJobRuns(ctx context.Context, checkers []TestCaseChecker, jobRunJunitMap map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) []*junit.TestSuite {
+	checkerSuites := make([]*junit.TestSuite, len(checkers))
+	waitGroup := sync.WaitGroup{}
+	for i := range checkers {
+		waitGroup.Add(1)
+		go func(i int) {
+			defer waitGroup.Done()
+			checkerName := fmt.Sprintf("checker-%d", i)
+			if stringer, ok := checkers[i].(fmt.Stringer); ok {
+				checkerName = stringer.String()
+			}
+			if o.progress != nil {
+				o.progress.CheckerStatus(checkerName, "running")
+			}
+			checkerSuites[i] = checkers[i].CheckTestCase(ctx, jobRunJunitMap)
+			if o.progress != nil {
+				status := "passed"
+				if checkerSuites[i] != nil && checkerSuites[i].NumFailed > 0 {
+					status = "failed"
+				}
+				o.progress.CheckerStatus(checkerName, status)
+			}
+		}(i)
+	}
+	waitGroup.Wait()
+	return checkerSuites
+}
+
+// checkOptionalJobRuns runs the checkers in report-only mode against the runs of optional jobs, so that how they
+// fared is reported next to, but apart from, the verdict.
+func (o *JobRunTestCaseAnalyzerOptions) checkOptionalJobRuns(ctx context.Context, optionalJunits map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) *junit.TestSuite {
+	checkers := make([]TestCaseChecker, 0, len(o.testCaseCheckers))
+	for _, checker := range o.testCaseCheckers {
+		if _, ok := checker.(reportOnlyTestCaseChecker); !ok {
+			checker = reportOnlyTestCaseChecker{checker: checker}
+		}
+		checkers = append(checkers, checker)
+	}
+
+	suite := &junit.TestSuite{
+		Name:      "optional-jobs",
+		TestCases: []*junit.TestCase{},
+	}
+	for _, testSuite := range o.checkJobRuns(ctx, checkers, optionalJunits) {
+		if testSuite != nil {
+			suite.Children = append(suite.Children, testSuite)
+		}
+	}
+	jobrunaggregatorlib.UpdateTestCountsInSuite(suite)
+	return suite
+}
+
+// neverPassingJobsTestSuite reports the jobs left out of the analysis because they have not succeeded recently,
+// so that they remain visible to whoever looks at the payload.
+func neverPassingJobsTestSuite(jobNames []string) *junit.TestSuite {
+	suite := &junit.TestSuite{
+		Name:      "permanently-failing-jobs",
+		TestCases: []*junit.TestCase{},
+	}
+	for _, jobName := range jobNames {
+		suite.TestCases = append(suite.TestCases, &junit.TestCase{
+			Name: fmt.Sprintf("job/%s excluded", jobName),
+			SkipMessage: &junit.SkipMessage{
+				Message: "job has no successful runs during the lookback period",
+			},
+		})
+	}
+	suite.NumTests = uint(len(suite.TestCases))
+	suite.NumSkipped = suite.NumTests
+	return suite
+}
+
+// missingArtifactsTestSuite creates one skipped test case per finished job run that did not produce usable junit,
+// typically because of an infrastructure failure.  Without it, such job runs silently vanish from the analysis.
+func missingArtifactsTestSuite(missingArtifacts map[jobrunaggregatorapi.JobRunInfo]string) *junit.TestSuite {
+	suite := &junit.TestSuite{
+		Name:      "missing-artifacts",
+		TestCases: []*junit.TestCase{},
+	}
+	jobRuns := make([]jobrunaggregatorapi.JobRunInfo, 0, len(missingArtifacts))
+	for jobRun := range missingArtifacts {
+		jobRuns = append(jobRuns, jobRun)
+	}
+	sort.Slice(jobRuns, func(i, j int) bool {
+		if jobRuns[i].GetJobName() != jobRuns[j].GetJobName() {
+			return jobRuns[i].GetJobName() < jobRuns[j].GetJobName()
+		}
+		return jobRuns[i].GetJobRunID() < jobRuns[j].GetJobRunID()
+	})
+
+	for _, jobRun := range jobRuns {
+		suite.TestCases = append(suite.TestCases, &junit.TestCase{
+			Name: fmt.Sprintf("jobrun/%s/%s artifacts missing", jobRun.GetJobName(), jobRun.GetJobRunID()),
+			SkipMessage: &junit.SkipMessage{
+				Message: missingArtifacts[jobRun],
+			},
+			SystemOut: fmt.Sprintf("HumanURL: %s\nGCSArtifactURL: %s\n", jobRun.GetHumanURL(), jobRun.GetGCSArtifactURL()),
+		})
+	}
+	suite.NumTests = uint(len(suite.TestCases))
+	suite.NumSkipped = suite.NumTests
+	return suite
 }
 
 func (o *JobRunTestCaseAnalyzerOptions) Run(ctx context.Context) error {
+	// pushed with the context of the caller, so that metrics of analyses that timed out are pushed as well
+	defer o.pushMetrics(ctx)
 	ctx, cancel := context.WithTimeout(ctx, o.timeout)
 	defer cancel()
 
+	result, err := o.analyze(ctx)
+	if err != nil {
+		return err
+	}
+	if !result.Passed {
+		return fmt.Errorf("some test checker failed,  see above for details")
+	}
+	return nil
+}
+
+func (o *JobRunTestCaseAnalyzerOptions) analyze(ctx context.Context) (*AnalysisResult, error) {
+	if len(o.payloads) > 0 {
+		return o.analyzePayloads(ctx)
+	}
+	_, result, err := o.analyzePayload(ctx)
+	return result, err
+}
+
+// analyzePayload analyzes the job runs of the payload tag or of the payload invocation ID, writes the outputs of the
+// analysis in a directory named after it and returns the junit and the result of the analysis.
+func (o *JobRunTestCaseAnalyzerOptions) analyzePayload(ctx context.Context) (*junit.TestSuite, *AnalysisResult, error) {
 	matchID := o.payloadTag
 	if len(matchID) == 0 {
 		matchID = o.payloadInvocationID
@@ -628,51 +1102,170 @@ func (o *JobRunTestCaseAnalyzerOptions) Run(ctx context.Context) error {
 
 	outputDir := filepath.Join(o.workingDir, matchID)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("error creating output directory %q: %w", outputDir, err)
+		return nil, nil, fmt.Errorf("error creating output directory %q: %w", outputDir, err)
 	}
+	startTime := time.Now()
 
 	// if it hasn't been more than two hours since the jobRuns started, the list isn't complete.
 	readyAt := o.jobRunStartEstimate.Add(10 * time.Minute)
 
 	durationToWait := o.timeout - 20*time.Minute
-	timeToStopWaiting := o.jobRunStartEstimate.Add(durationToWait)
+	timeToStopWaiting := o.timeToStopWaiting(ctx, o.jobRunStartEstimate.Add(durationToWait))
 
-	fmt.Printf("Analyzing test status for job runs for %q.  now=%v, ReadyAt=%v, timeToStopWaiting=%v.\n", matchID, time.Now(), readyAt, timeToStopWaiting)
+	logrus.WithFields(logrus.Fields{"payloadTag": matchID, "readyAt": readyAt, "timeToStopWaiting": timeToStopWaiting}).Info("Analyzing test status for job runs")
 
 	err := jobrunaggregatorlib.WaitUntilTime(ctx, readyAt)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	var jobRunWaiter jobrunaggregatorlib.JobRunWaiter
 	if o.jobStateQuerySource == jobrunaggregatorlib.JobStateQuerySourceBigQuery || o.prowJobClient == nil {
-		jobRunWaiter = &jobrunaggregatorlib.BigQueryJobRunWaiter{JobRunGetter: o, TimeToStopWaiting: timeToStopWaiting}
+		bigQueryJobRunWaiter := &jobrunaggregatorlib.BigQueryJobRunWaiter{JobRunGetter: o, TimeToStopWaiting: timeToStopWaiting, Progress: o.progress}
+		if o.adaptiveWait {
+			bigQueryJobRunWaiter.AdaptiveWait = &jobrunaggregatorlib.AdaptiveWait{
+				CIDataClient:        o.ciDataClient,
+				JobRunStartEstimate: o.jobRunStartEstimate,
+				Latest:              timeToStopWaiting,
+			}
+		}
+		if o.stopWaitingAtMinimumPasses {
+			bigQueryJobRunWaiter.DoneWaiting = o.minimumPassesMet
+		}
+		jobRunWaiter = bigQueryJobRunWaiter
 	} else {
 		jobRunWaiter = &jobrunaggregatorlib.ClusterJobRunWaiter{
 			ProwJobClient:      o.prowJobClient,
 			TimeToStopWaiting:  timeToStopWaiting,
 			ProwJobMatcherFunc: o.shouldAggregateJob,
+			SampleJobRuns:      o.sampler.sampleJobRunIDs,
 		}
 	}
 
+	waitStart := time.Now()
 	finishedJobRuns, unfinishedJobRuns, _, _, err := jobrunaggregatorlib.WaitAndGetAllFinishedJobRuns(ctx, o, jobRunWaiter, outputDir, o.testNameSuffix)
+	observeAnalysisPhase(analysisPhaseWaitForJobRuns, waitStart)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	jobRunsLocated.WithLabelValues(jobRunStatusFinished).Set(float64(len(finishedJobRuns)))
+	jobRunsLocated.WithLabelValues(jobRunStatusUnfinished).Set(float64(len(unfinishedJobRuns)))
+
+	if o.sampler.enabled() {
+		logrus.WithFields(logrus.Fields{
+			"size":    o.sampler.size,
+			"seed":    o.sampler.seed,
+			"dropped": o.sampler.dropped(),
+		}).Info("sampled job runs")
 	}
 
-	testSuite := o.runTestCaseCheckers(ctx, finishedJobRuns, unfinishedJobRuns)
+	checkStart := time.Now()
+	testSuite, jobRunJunitMap := o.runTestCaseCheckers(ctx, finishedJobRuns, unfinishedJobRuns)
+	observeAnalysisPhase(analysisPhaseCheckTestCases, checkStart)
+	testSuite.Properties = append(testSuite.Properties, o.invocationProperties(startTime)...)
+	recordStart := time.Now()
+	defer observeAnalysisPhase(analysisPhaseRecordResults, recordStart)
+	if o.sampler.enabled() {
+		testSuite.Properties = append(testSuite.Properties, o.sampler.property())
+	}
+	if o.findJobRunsRetries != nil {
+		o.findJobRunsRetries.log()
+		testSuite.Properties = append(testSuite.Properties, o.findJobRunsRetries.property())
+	}
+	if err := o.applyGateOverride(ctx, matchID, testSuite); err != nil {
+		return nil, nil, err
+	}
+	o.testOwners.AnnotateFailures(testSuite)
+	o.exportEvidence(ctx, matchID, testSuite, jobRunJunitMap)
+	o.recordGateResults(ctx, matchID, testSuite)
 	jobrunaggregatorlib.OutputTestCaseFailures([]string{"root"}, testSuite)
 
 	// Done with all tests
 	junitXML, err := xml.Marshal(testSuite)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	if err := os.WriteFile(filepath.Join(outputDir, "junit-test-case-analysis.xml"), junitXML, 0644); err != nil {
-		return err
+		return nil, nil, err
 	}
-	if testSuite.NumFailed > 0 {
-		return fmt.Errorf("some test checker failed,  see above for details")
+	result := newAnalysisResult(matchID, testSuite, finishedJobRuns, unfinishedJobRuns, o.optionalJobs)
+	if err := writeAnalysisResult(result, outputDir); err != nil {
+		return nil, nil, err
+	}
+	o.recordTestCaseAnalysis(ctx, result)
+	if err := writeAnalysisSummaryHTML(result, jobRunJunitMap, outputDir); err != nil {
+		return nil, nil, err
+	}
+	if err := writeTestGrid(newTestGrid(o.testCaseCheckers, jobRunJunitMap, o.optionalJobs), outputDir); err != nil {
+		return nil, nil, err
+	}
+	if o.notifier != nil {
+		// notifications are informational, failing to send them must not change the verdict
+		if err := o.notifier.Notify(ctx, &jobrunaggregatorlib.PayloadVerdict{
+			Analyzer:            "analyze-test-case",
+			PayloadTag:          o.payloadTag,
+			PayloadInvocationID: o.payloadInvocationID,
+			AnalyzedTime:        time.Now(),
+			Passed:              testSuite.NumFailed == 0,
+			Tests:               jobrunaggregatorlib.PayloadTestResultsFromSuite(testSuite),
+		}); err != nil {
+			logrus.WithError(err).Warn("failed to notify the verdict")
+		}
+	}
+	return testSuite, result, nil
+}
+
+// recordGateResults stores the verdict of every checker along with the evidence bundles exported for it, for
+// comparisons across payloads.  Storing them is best effort, the analysis verdict doesn't depend on it.
+func (o *JobRunTestCaseAnalyzerOptions) recordGateResults(ctx context.Context, matchID string, testSuite *junit.TestSuite) {
+	if o.gateResultInserter == nil {
+		return
+	}
+	rows := jobrunaggregatorlib.NewGateResultRows("analyze-test-case", o.testGroup, matchID, testSuite, time.Now())
+	if err := o.gateResultInserter.Put(ctx, rows); err != nil {
+		logrus.WithError(err).Warn("failed to record gate results")
+	}
+}
+
+// recordTestCaseAnalysis stores the outcome of every checker to trend gating over time.  Like the gate results,
+// storing it must not change the verdict.
+func (o *JobRunTestCaseAnalyzerOptions) recordTestCaseAnalysis(ctx context.Context, result *AnalysisResult) {
+	if o.testCaseAnalysisInserter == nil {
+		return
+	}
+	if err := o.testCaseAnalysisInserter.Put(ctx, testCaseAnalysisRows(result, o.testGroup, time.Now())); err != nil {
+		logrus.WithError(err).Warn("failed to record the test case analysis")
+	}
+}
+
+// applyGateOverride force-accepts failed test cases when an override was supplied and records who did it and
+// what the original verdict was.  An override that can't be recorded must not take effect, so failing to store the
+// audit rows fails the analysis.
+func (o *JobRunTestCaseAnalyzerOptions) applyGateOverride(ctx context.Context, matchID string, testSuite *junit.TestSuite) error {
+	if o.gateOverride == nil {
+		return nil
+	}
+	// the test cases span many jobs, so the overrides are recorded for the test group rather than for a job
+	rows := jobrunaggregatorlib.ApplyGateOverride(o.gateOverride, "", matchID, testSuite)
+	for i := range rows {
+		rows[i].TestGroup = o.testGroup
+	}
+	if len(rows) == 0 {
+		logrus.WithField("issuedBy", o.gateOverride.IssuedBy).Info("gate override supplied, but no failed test cases matched")
+		return nil
+	}
+	if o.gateOverrideInserter == nil {
+		return fmt.Errorf("gate override issued by %s cannot be recorded, so it is not applied", o.gateOverride.IssuedBy)
+	}
+	if err := o.gateOverrideInserter.Put(ctx, rows); err != nil {
+		return fmt.Errorf("failed to record the gate override issued by %s, so it is not applied: %w", o.gateOverride.IssuedBy, err)
+	}
+	for _, row := range rows {
+		logrus.WithFields(logrus.Fields{
+			"issuedBy": row.IssuedBy,
+			"reason":   row.Reason,
+			"test":     row.TestName,
+		}).Warn("failed test case accepted by gate override")
 	}
 	return nil
 }
@@ -2,7 +2,12 @@ package jobruntestcaseanalyzer
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/spf13/pflag"
@@ -11,6 +16,7 @@ import (
 
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+	"github.com/openshift/ci-tools/pkg/junit"
 )
 
 func TestGetJobs(t *testing.T) {
@@ -19,10 +25,11 @@ func TestGetJobs(t *testing.T) {
 		expectedJobNames sets.Set[string]
 		filters          map[string][]string
 	}{
-		"test upgrade filter":   {expectedJobNames: sets.Set[string]{"periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-sdn-serial-ipv4": sets.Empty{}, "periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-serial-ovn-ipv6": sets.Empty{}}, filters: map[string][]string{"exclude-job-names": {"upgrade"}}},
-		"test no filter":        {expectedJobNames: sets.Set[string]{"periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-sdn-serial-ipv4": sets.Empty{}, "periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-serial-ovn-ipv6": sets.Empty{}, "periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-sdn-upgrade": sets.Empty{}}},
-		"test multiple filters": {expectedJobNames: sets.Set[string]{"periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-sdn-serial-ipv4": sets.Empty{}}, filters: map[string][]string{"exclude-job-names": {"upgrade", "ipv6"}}},
-		"test include arg":      {expectedJobNames: sets.Set[string]{"periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-serial-ovn-ipv6": sets.Empty{}}, filters: map[string][]string{"include-job-names": {"ipv6"}}},
+		"test upgrade filter":    {expectedJobNames: sets.Set[string]{"periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-sdn-serial-ipv4": sets.Empty{}, "periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-serial-ovn-ipv6": sets.Empty{}}, filters: map[string][]string{"exclude-job-names": {"upgrade"}}},
+		"test no filter":         {expectedJobNames: sets.Set[string]{"periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-sdn-serial-ipv4": sets.Empty{}, "periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-serial-ovn-ipv6": sets.Empty{}, "periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-sdn-upgrade": sets.Empty{}}},
+		"test multiple filters":  {expectedJobNames: sets.Set[string]{"periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-sdn-serial-ipv4": sets.Empty{}}, filters: map[string][]string{"exclude-job-names": {"upgrade", "ipv6"}}},
+		"test include arg":       {expectedJobNames: sets.Set[string]{"periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-serial-ovn-ipv6": sets.Empty{}}, filters: map[string][]string{"include-job-names": {"ipv6"}}},
+		"test include exact arg": {expectedJobNames: sets.Set[string]{"periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-sdn-upgrade": sets.Empty{}}, filters: map[string][]string{"include-exact-job-names": {"periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-sdn-upgrade", "periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-sdn"}}},
 	}
 
 	for name, tc := range tests {
@@ -56,6 +63,7 @@ func TestGetJobs(t *testing.T) {
 
 			fs.StringArrayVar(&f.ExcludeJobNames, "exclude-job-names", f.ExcludeJobNames, "Applied only when --explicit-gcs-prefixes is not specified.  The flag can be specified multiple times to create a list of substrings used to filter JobNames from the analysis")
 			fs.StringArrayVar(&f.IncludeJobNames, "include-job-names", f.IncludeJobNames, "Applied only when --explicit-gcs-prefixes is not specified.  The flag can be specified multiple times to create a list of substrings to include in matching JobNames for analysis")
+			fs.StringArrayVar(&f.IncludeExactJobNames, "include-exact-job-names", f.IncludeExactJobNames, "Applied only when --explicit-gcs-prefixes is not specified.  The flag can be specified multiple times to create a list of the only job names to analyze")
 
 			if err := fs.Parse(args); err != nil {
 				t.Fatalf("%s flag set parse returned error %#v", name, err)
@@ -71,6 +79,10 @@ func TestGetJobs(t *testing.T) {
 				jobGetter.includeJobNames.Insert(f.IncludeJobNames...)
 			}
 
+			if len(f.IncludeExactJobNames) > 0 {
+				jobGetter.includeExactJobNames = sets.New[string](f.IncludeExactJobNames...)
+			}
+
 			returnedJobs, err := jobGetter.GetJobs(ctx)
 
 			if nil != err {
@@ -81,6 +93,10 @@ func TestGetJobs(t *testing.T) {
 				t.Fatalf("%s returned nil jobs", name)
 			}
 
+			if len(returnedJobs) != len(tc.expectedJobNames) {
+				t.Fatalf("%s expected %d jobs, got %d", name, len(tc.expectedJobNames), len(returnedJobs))
+			}
+
 			for key := range tc.expectedJobNames {
 				foundIt := false
 
@@ -109,3 +125,1379 @@ func createJobs() []jobrunaggregatorapi.JobRowWithVariants {
 
 	return jobs
 }
+
+func newMockJobRun(mockCtrl *gomock.Controller, jobName, jobRunID string, testSuites *junit.TestSuites, err error) *jobrunaggregatorapi.MockJobRunInfo {
+	jobRun := jobrunaggregatorapi.NewMockJobRunInfo(mockCtrl)
+	jobRun.EXPECT().GetJobName().Return(jobName).AnyTimes()
+	jobRun.EXPECT().GetJobRunID().Return(jobRunID).AnyTimes()
+	jobRun.EXPECT().GetHumanURL().Return("https://prow.ci.openshift.org/view/gs/test-platform-results/logs/" + jobName + "/" + jobRunID).AnyTimes()
+	jobRun.EXPECT().GetGCSArtifactURL().Return("https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/logs/" + jobName + "/" + jobRunID).AnyTimes()
+	jobRun.EXPECT().GetCombinedJUnitTestSuites(gomock.Any()).Return(testSuites, err).AnyTimes()
+	return jobRun
+}
+
+func TestFilterJobsForPayloadByArchitecture(t *testing.T) {
+	jobs := []jobrunaggregatorapi.JobRowWithVariants{
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.14-e2e-aws-ovn", Platform: "aws", Network: "ovn", Architecture: "amd64"},
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.14-ocp-e2e-aws-ovn-arm64", Platform: "aws", Network: "ovn", Architecture: "arm64"},
+		{JobName: "periodic-ci-openshift-multiarch-master-nightly-4.14-ocp-e2e-aws-ovn-arm64-single-node", Platform: "aws", Network: "ovn"},
+		{JobName: "periodic-ci-openshift-multiarch-master-nightly-4.14-ocp-e2e-ovn-remote-libvirt-s390x", Platform: "libvirt", Network: "ovn", Architecture: "s390x"},
+	}
+
+	tests := map[string]struct {
+		architecture     string
+		expectedJobNames []string
+	}{
+		"no architecture": {
+			expectedJobNames: []string{jobs[0].JobName, jobs[1].JobName, jobs[2].JobName, jobs[3].JobName},
+		},
+		"amd64": {
+			architecture:     "amd64",
+			expectedJobNames: []string{jobs[0].JobName},
+		},
+		"arm64 falls back to the job name": {
+			architecture:     "arm64",
+			expectedJobNames: []string{jobs[1].JobName, jobs[2].JobName},
+		},
+		"s390x": {
+			architecture:     "s390x",
+			expectedJobNames: []string{jobs[3].JobName},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			jobGetter := &testCaseAnalyzerJobGetter{architecture: tc.architecture}
+			var jobNames []string
+			for _, job := range jobGetter.filterJobsForPayload(jobs) {
+				jobNames = append(jobNames, job.JobName)
+			}
+			if !reflect.DeepEqual(tc.expectedJobNames, jobNames) {
+				t.Errorf("expected jobs %v, got %v", tc.expectedJobNames, jobNames)
+			}
+		})
+	}
+}
+
+func TestExcludeJobRegex(t *testing.T) {
+	var regexes []*regexp.Regexp
+	fs := pflag.NewFlagSet(t.Name(), pflag.ContinueOnError)
+	fs.Var(&regexpSlice{&regexes}, "exclude-job-regex", "")
+	if err := fs.Parse([]string{"--exclude-job-regex=("}); err == nil {
+		t.Fatalf("expected an invalid regular expression to fail the flag parsing")
+	}
+	if err := fs.Parse([]string{"--exclude-job-regex=.*(ipv6|proxy)-upgrade$", "--exclude-job-regex=-serial-"}); err != nil {
+		t.Fatalf("unexpected error parsing flags: %v", err)
+	}
+
+	jobGetter := &testCaseAnalyzerJobGetter{excludeJobRegexes: regexes}
+	for jobName, expected := range map[string]bool{
+		"periodic-ci-openshift-release-master-nightly-4.14-e2e-aws-ovn-ipv6-upgrade":          true,
+		"periodic-ci-openshift-release-master-nightly-4.14-e2e-aws-ovn-proxy-upgrade":         true,
+		"periodic-ci-openshift-release-master-nightly-4.14-e2e-aws-ovn-ipv6-upgrade-rollback": false,
+		"periodic-ci-openshift-release-master-nightly-4.14-e2e-metal-ipi-sdn-serial-ipv4":     true,
+		"periodic-ci-openshift-release-master-nightly-4.14-e2e-aws-ovn-upgrade":               false,
+	} {
+		if actual := jobGetter.isJobNameExcluded(jobName); actual != expected {
+			t.Errorf("expected %s to be excluded: %t, got %t", jobName, expected, actual)
+		}
+	}
+}
+
+func TestRunTestCaseCheckersMissingArtifacts(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	installPassed := &junit.TestSuites{
+		Suites: []*junit.TestSuite{
+			{
+				Name:      installTestSuites[0],
+				TestCases: []*junit.TestCase{{Name: installTest}},
+			},
+		},
+	}
+	finishedJobRuns := []jobrunaggregatorapi.JobRunInfo{
+		newMockJobRun(mockCtrl, "job-a", "1", installPassed, nil),
+		newMockJobRun(mockCtrl, "job-b", "3", &junit.TestSuites{}, nil),
+		newMockJobRun(mockCtrl, "job-a", "2", nil, fmt.Errorf("no prowjob.json")),
+	}
+	unfinishedJobRuns := []jobrunaggregatorapi.JobRunInfo{
+		newMockJobRun(mockCtrl, "job-c", "4", nil, fmt.Errorf("not finished")),
+	}
+
+	o := &JobRunTestCaseAnalyzerOptions{
+		testCaseCheckers: []TestCaseChecker{minimumRequiredPassesTestCaseChecker{id: installTestIdentifier, testNameSuffix: "", requiredNumberOfPasses: 1}},
+	}
+	topSuite, _ := o.runTestCaseCheckers(ctx, finishedJobRuns, unfinishedJobRuns)
+
+	if topSuite.NumFailed != 0 {
+		t.Fatalf("expected no failures, got %d", topSuite.NumFailed)
+	}
+	if len(topSuite.Children) != 2 {
+		t.Fatalf("expected checker and missing artifacts suites, got %d suites", len(topSuite.Children))
+	}
+	missingSuite := topSuite.Children[1]
+	expectedNames := []string{"jobrun/job-a/2 artifacts missing", "jobrun/job-b/3 artifacts missing"}
+	if len(missingSuite.TestCases) != len(expectedNames) {
+		t.Fatalf("expected %d missing artifact test cases, got %d", len(expectedNames), len(missingSuite.TestCases))
+	}
+	for i, testCase := range missingSuite.TestCases {
+		if testCase.Name != expectedNames[i] {
+			t.Errorf("expected test case %q, got %q", expectedNames[i], testCase.Name)
+		}
+		if testCase.SkipMessage == nil {
+			t.Errorf("expected test case %q to be skipped", testCase.Name)
+		}
+	}
+	if missingSuite.NumSkipped != 2 || topSuite.NumSkipped != 2 {
+		t.Errorf("expected 2 skipped tests, got %d in suite and %d at top level", missingSuite.NumSkipped, topSuite.NumSkipped)
+	}
+}
+
+func TestRunTestCaseCheckersConcurrently(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	jobRunJunits := &junit.TestSuites{
+		Suites: []*junit.TestSuite{
+			{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest}}},
+			{Name: upgradeTestSuite[0], TestCases: []*junit.TestCase{{Name: upgradeTest, FailureOutput: &junit.FailureOutput{}}}},
+		},
+	}
+	o := &JobRunTestCaseAnalyzerOptions{
+		testCaseCheckers: []TestCaseChecker{
+			minimumRequiredPassesTestCaseChecker{id: upgradeTestIdentifier, testNameSuffix: "", requiredNumberOfPasses: 1},
+			minimumRequiredPassesTestCaseChecker{id: installTestIdentifier, testNameSuffix: "", requiredNumberOfPasses: 1},
+			minimumRequiredPassesTestCaseChecker{id: overallTestIdentifier, testNameSuffix: "", requiredNumberOfPasses: 1},
+		},
+	}
+	topSuite, _ := o.runTestCaseCheckers(ctx, []jobrunaggregatorapi.JobRunInfo{newMockJobRun(mockCtrl, "job-a", "1", jobRunJunits, nil)}, nil)
+
+	if len(topSuite.Children) != 3 {
+		t.Fatalf("expected 3 checker suites, got %d", len(topSuite.Children))
+	}
+	// results must keep the order the checkers were configured in
+	for i, suiteName := range []string{upgradeTestSuite[0], installTestSuites[0], overallTestsSuite[0]} {
+		if child := topSuite.Children[i].Children[0].Name; child != suiteName {
+			t.Errorf("expected checker %d to report suite %q, got %q", i, suiteName, child)
+		}
+	}
+	if topSuite.NumFailed != 2 {
+		t.Errorf("expected upgrade and overall checkers to fail, got %d failures", topSuite.NumFailed)
+	}
+}
+
+func TestRunTestCaseCheckersOptionalJobs(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	passed := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest}}}}}
+	failed := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest, FailureOutput: &junit.FailureOutput{}}}}}}
+	o := &JobRunTestCaseAnalyzerOptions{
+		testCaseCheckers: []TestCaseChecker{
+			zeroToleranceTestCaseChecker{id: installTestIdentifier},
+		},
+		optionalJobs: newOptionalJobs([]string{"job-informing"}),
+	}
+	topSuite, _ := o.runTestCaseCheckers(ctx, []jobrunaggregatorapi.JobRunInfo{
+		newMockJobRun(mockCtrl, "job-blocking", "1", passed, nil),
+		newMockJobRun(mockCtrl, "job-informing", "2", failed, nil),
+	}, nil)
+
+	if topSuite.NumFailed != 0 {
+		t.Errorf("expected the failure of the optional job not to fail the analysis, got %d failures", topSuite.NumFailed)
+	}
+	if len(topSuite.Children) != 2 || topSuite.Children[1].Name != "optional-jobs" {
+		t.Fatalf("expected the checker suite followed by the optional-jobs suite, got %d suites", len(topSuite.Children))
+	}
+	optionalCase := topSuite.Children[1].Children[0].Children[0].TestCases[0]
+	if !strings.Contains(optionalCase.SystemOut, "report-only checker failed") {
+		t.Errorf("expected the failure of the optional job to be reported, got %q", optionalCase.SystemOut)
+	}
+}
+
+func TestRunTestCaseCheckersJUnitFetchTimeout(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	passed := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest}}}}}
+	slowJobRun := jobrunaggregatorapi.NewMockJobRunInfo(mockCtrl)
+	slowJobRun.EXPECT().GetJobName().Return("job-slow").AnyTimes()
+	slowJobRun.EXPECT().GetJobRunID().Return("2").AnyTimes()
+	slowJobRun.EXPECT().GetHumanURL().Return("https://prow.ci.openshift.org/view/gs/test-platform-results/logs/job-slow/2").AnyTimes()
+	slowJobRun.EXPECT().GetGCSArtifactURL().Return("").AnyTimes()
+	// the artifact never arrives, the read only ends when it is abandoned
+	slowJobRun.EXPECT().GetCombinedJUnitTestSuites(gomock.Any()).DoAndReturn(func(ctx context.Context) (*junit.TestSuites, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}).AnyTimes()
+
+	o := &JobRunTestCaseAnalyzerOptions{
+		testCaseCheckers: []TestCaseChecker{
+			minimumRequiredPassesTestCaseChecker{id: installTestIdentifier, requiredNumberOfPasses: 1},
+		},
+		junitFetchTimeout: 10 * time.Millisecond,
+	}
+	topSuite, jobRunJunitMap := o.runTestCaseCheckers(ctx, []jobrunaggregatorapi.JobRunInfo{
+		newMockJobRun(mockCtrl, "job-a", "1", passed, nil),
+		slowJobRun,
+	}, nil)
+
+	if topSuite.NumFailed != 0 {
+		t.Errorf("expected the analysis to go on with the readable job run, got %d failures", topSuite.NumFailed)
+	}
+	if testSuites, ok := jobRunJunitMap[slowJobRun]; !ok || testSuites != nil {
+		t.Errorf("expected the slow job run to be kept without junit, got %v", testSuites)
+	}
+	details, err := jobrunaggregatorlib.GetTestCaseDetails(topSuite.Children[0].Children[0].TestCases[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(details.Passes) != 1 || len(details.Unreadable) != 1 || details.Unreadable[0].JobRunID != "2" {
+		t.Errorf("expected one pass and the slow job run unreadable, got %+v", details)
+	}
+	missingSuite := topSuite.Children[len(topSuite.Children)-1]
+	if missingSuite.Name != "missing-artifacts" || !strings.Contains(missingSuite.TestCases[0].SkipMessage.Message, "timed out reading junit") {
+		t.Errorf("expected the slow job run to be reported with the missing artifacts, got suite %q", missingSuite.Name)
+	}
+}
+
+func TestGetJobsExcludesNeverPassingJobs(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	neverPassingJob := "periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ipi-sdn-upgrade"
+	mockCIDataClient := jobrunaggregatorlib.NewMockCIDataClient(mockCtrl)
+	mockCIDataClient.EXPECT().ListAllJobs(ctx).Return(createJobs(), nil)
+	mockCIDataClient.EXPECT().ListJobsWithoutSuccessfulRunsSince(ctx, gomock.Any()).Return(sets.New[string](neverPassingJob, "some-other-job"), nil)
+
+	jobGetter := NewTestCaseAnalyzerJobGetter("metal", "", "", "sdn", "", "", nil, nil, nil, nil, nil, 7*24*time.Hour, &[]jobGCSPrefix{}, mockCIDataClient)
+	returnedJobs, err := jobGetter.GetJobs(ctx)
+	if err != nil {
+		t.Fatalf("GetJobs returned error %v", err)
+	}
+	if len(returnedJobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(returnedJobs))
+	}
+	for _, job := range returnedJobs {
+		if job.JobName == neverPassingJob {
+			t.Errorf("expected %q to be excluded", neverPassingJob)
+		}
+	}
+	if neverPassing := jobGetter.NeverPassingJobs(); len(neverPassing) != 1 || neverPassing[0] != neverPassingJob {
+		t.Errorf("expected only %q to be reported as never passing, got %v", neverPassingJob, neverPassing)
+	}
+
+	o := &JobRunTestCaseAnalyzerOptions{jobGetter: jobGetter}
+	topSuite, _ := o.runTestCaseCheckers(ctx, nil, nil)
+	if len(topSuite.Children) != 1 || topSuite.Children[0].Name != "permanently-failing-jobs" {
+		t.Fatalf("expected a permanently-failing-jobs suite, got %v", topSuite.Children)
+	}
+	if topSuite.NumSkipped != 1 || topSuite.NumFailed != 0 {
+		t.Errorf("expected 1 skipped and no failed tests, got %d skipped and %d failed", topSuite.NumSkipped, topSuite.NumFailed)
+	}
+}
+
+func TestJobRunSamplerSample(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var jobRuns []jobrunaggregatorapi.JobRunInfo
+	for i := 0; i < 10; i++ {
+		jobRuns = append(jobRuns, newMockJobRun(mockCtrl, "job-a", fmt.Sprintf("a%d", i), nil, nil))
+	}
+	jobRuns = append(jobRuns, newMockJobRun(mockCtrl, "job-b", "b0", nil, nil), newMockJobRun(mockCtrl, "job-b", "b1", nil, nil))
+	sampledIDs := func(jobRuns []jobrunaggregatorapi.JobRunInfo) sets.Set[string] {
+		ret := sets.New[string]()
+		for _, jobRun := range jobRuns {
+			ret.Insert(jobRun.GetJobRunID())
+		}
+		return ret
+	}
+
+	sampler := newJobRunSampler(2, 42)
+	sampled := sampler.sample(jobRuns)
+	if dropped := sampler.dropped(); dropped != 8 {
+		t.Errorf("expected 8 dropped job runs, got %d", dropped)
+	}
+	sampledPerJob := map[string]int{}
+	for _, jobRun := range sampled {
+		sampledPerJob[jobRun.GetJobName()]++
+	}
+	if sampledPerJob["job-a"] != 2 || sampledPerJob["job-b"] != 2 {
+		t.Errorf("expected 2 job runs per job, got %v", sampledPerJob)
+	}
+
+	// the waiter locates the job runs again as it polls, the job runs located later don't replace the sampled ones
+	relocated := append([]jobrunaggregatorapi.JobRunInfo{newMockJobRun(mockCtrl, "job-a", "a10", nil, nil)}, jobRuns...)
+	if resampled := sampledIDs(sampler.sample(relocated)); !resampled.Equal(sampledIDs(sampled)) {
+		t.Errorf("expected the same job runs to stay sampled, got %v and %v", sets.List(sampledIDs(sampled)), sets.List(resampled))
+	}
+	if dropped := sampler.dropped(); dropped != 9 {
+		t.Errorf("expected 9 dropped job runs, got %d", dropped)
+	}
+
+	// the same seed must pick the same job runs regardless of the order they were located in
+	reversed := make([]jobrunaggregatorapi.JobRunInfo, len(jobRuns))
+	for i := range jobRuns {
+		reversed[len(jobRuns)-1-i] = jobRuns[i]
+	}
+	if resampled := sampledIDs(newJobRunSampler(2, 42).sample(reversed)); !resampled.Equal(sampledIDs(sampled)) {
+		t.Errorf("expected the same sample for the same seed, got %v and %v", sets.List(sampledIDs(sampled)), sets.List(resampled))
+	}
+
+	var unsampled *jobRunSampler
+	if len(unsampled.sample(jobRuns)) != len(jobRuns) || unsampled.dropped() != 0 {
+		t.Errorf("expected no sampling without a sampler")
+	}
+	if len(newJobRunSampler(0, 42).sample(jobRuns)) != len(jobRuns) {
+		t.Errorf("expected no sampling without a size")
+	}
+}
+
+func TestPerArchitectureTestCaseChecker(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	installPassed := &junit.TestSuites{
+		Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest}}}},
+	}
+	architectures := newJobArchitectures()
+	architectures.record([]jobrunaggregatorapi.JobRowWithVariants{
+		{JobName: "periodic-ci-openshift-multiarch-master-nightly-4.16-ocp-e2e-aws-ovn-arm64", Architecture: "arm64"},
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn", Architecture: "amd64"},
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-e2e-gcp-ovn"},
+		{JobName: "periodic-ci-openshift-multiarch-master-nightly-4.16-ocp-e2e-ibmcloud-ovn-ppc64le"},
+	})
+	jobRunJunits := map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{}
+	for i, jobName := range []string{
+		"periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn",
+		"periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn",
+		"periodic-ci-openshift-release-master-nightly-4.16-e2e-gcp-ovn",
+		"periodic-ci-openshift-multiarch-master-nightly-4.16-ocp-e2e-aws-ovn-arm64",
+	} {
+		jobRunJunits[newMockJobRun(mockCtrl, jobName, fmt.Sprintf("%d", i), installPassed, nil)] = installPassed
+	}
+
+	checker := perArchitectureTestCaseChecker{
+		checker:       minimumRequiredPassesTestCaseChecker{id: installTestIdentifier, testNameSuffix: "platform:aws", requiredNumberOfPasses: 2},
+		architectures: architectures,
+	}
+	topSuite := checker.CheckTestCase(ctx, jobRunJunits)
+
+	// amd64 has 3 passes and arm64 only 1, ppc64le has no job runs at all
+	expected := map[string]bool{
+		"architecture amd64":   true,
+		"architecture arm64":   false,
+		"architecture ppc64le": false,
+	}
+	if len(topSuite.Children) != len(expected) {
+		t.Fatalf("expected %d architecture suites, got %d", len(expected), len(topSuite.Children))
+	}
+	for _, architectureSuite := range topSuite.Children {
+		passed, ok := expected[architectureSuite.Name]
+		if !ok {
+			t.Errorf("unexpected suite %q", architectureSuite.Name)
+			continue
+		}
+		if (architectureSuite.NumFailed == 0) != passed {
+			t.Errorf("expected suite %q to pass: %t, got %d failures", architectureSuite.Name, passed, architectureSuite.NumFailed)
+		}
+	}
+	if topSuite.NumTests != 3 || topSuite.NumFailed != 2 {
+		t.Errorf("expected 3 tests with 2 failures, got %d tests with %d failures", topSuite.NumTests, topSuite.NumFailed)
+	}
+}
+
+func TestVariantTestCaseChecker(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	installPassed := &junit.TestSuites{
+		Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest}}}},
+	}
+	jobs := newVariantJobs()
+	jobs.record([]jobrunaggregatorapi.JobRowWithVariants{
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn", Platform: "aws", Network: "ovn"},
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn-upgrade", Platform: "aws", Network: "ovn"},
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-e2e-gcp-ovn", Platform: "gcp", Network: "ovn"},
+	})
+	jobRunJunits := map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{}
+	for i, jobName := range []string{
+		"periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn",
+		"periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn-upgrade",
+		"periodic-ci-openshift-release-master-nightly-4.16-e2e-gcp-ovn",
+	} {
+		jobRunJunits[newMockJobRun(mockCtrl, jobName, fmt.Sprintf("%d", i), installPassed, nil)] = installPassed
+	}
+
+	var checkers []TestCaseChecker
+	for _, value := range []string{"aws,ovn,ipi", "gcp,ovn,ipi"} {
+		variant, err := parseJobVariant(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkers = append(checkers, variantTestCaseChecker{
+			variant:  variant,
+			checkers: []TestCaseChecker{minimumRequiredPassesTestCaseChecker{id: installTestIdentifier, testNameSuffix: variant.testNameSuffix(), requiredNumberOfPasses: 2}},
+			jobs:     jobs,
+		})
+	}
+
+	// aws has 2 passes, gcp only 1
+	expected := map[string]bool{"variant aws,ovn,ipi": true, "variant gcp,ovn,ipi": false}
+	for _, checker := range checkers {
+		suite := checker.CheckTestCase(ctx, jobRunJunits)
+		passed, ok := expected[suite.Name]
+		if !ok {
+			t.Errorf("unexpected suite %q", suite.Name)
+			continue
+		}
+		if suite.NumTests != 1 || (suite.NumFailed == 0) != passed {
+			t.Errorf("expected suite %q to pass: %t, got %d tests with %d failures", suite.Name, passed, suite.NumTests, suite.NumFailed)
+		}
+	}
+
+	if _, err := parseJobVariant("aws,ovn"); err == nil {
+		t.Errorf("expected an error for a variant without infrastructure")
+	}
+	if _, err := parseJobVariant("aws,calico,ipi"); err == nil {
+		t.Errorf("expected an error for an unknown network")
+	}
+}
+
+func TestAutoRequiredPasses(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	now := time.Date(2023, 10, 15, 0, 0, 0, 0, time.UTC)
+	jobs := []jobrunaggregatorapi.JobRowWithVariants{
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn", Architecture: "amd64"},
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-e2e-gcp-ovn", Architecture: "amd64"},
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-e2e-azure-ovn", Architecture: "amd64"},
+		{JobName: "periodic-ci-openshift-multiarch-master-nightly-4.16-ocp-e2e-aws-ovn-arm64", Architecture: "arm64"},
+	}
+	mockDataClient := jobrunaggregatorlib.NewMockCIDataClient(mockCtrl)
+	mockDataClient.EXPECT().ListJobRunSuccessStatistics(gomock.Any(), []string{
+		"periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn",
+		"periodic-ci-openshift-release-master-nightly-4.16-e2e-gcp-ovn",
+		"periodic-ci-openshift-release-master-nightly-4.16-e2e-azure-ovn",
+		"periodic-ci-openshift-multiarch-master-nightly-4.16-ocp-e2e-aws-ovn-arm64",
+	}, now.Add(-autoMinimumLookback)).Return([]jobrunaggregatorapi.JobRunSuccessStatisticsRow{
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn", JobRuns: 10, SuccessfulJobRuns: 9},
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-e2e-gcp-ovn", JobRuns: 10, SuccessfulJobRuns: 8},
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-e2e-azure-ovn", JobRuns: 4, SuccessfulJobRuns: 3},
+		{JobName: "periodic-ci-openshift-multiarch-master-nightly-4.16-ocp-e2e-aws-ovn-arm64", JobRuns: 10, SuccessfulJobRuns: 2},
+	}, nil)
+
+	architectures := newJobArchitectures()
+	architectures.record(jobs)
+	autoPasses := newAutoRequiredPasses(mockDataClient, architectures)
+	autoPasses.now = func() time.Time { return now }
+	if err := autoPasses.record(context.TODO(), jobs); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPassRates := map[string]float64{
+		"periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn":             0.9,
+		"periodic-ci-openshift-release-master-nightly-4.16-e2e-gcp-ovn":             0.8,
+		"periodic-ci-openshift-release-master-nightly-4.16-e2e-azure-ovn":           0.75,
+		"periodic-ci-openshift-multiarch-master-nightly-4.16-ocp-e2e-aws-ovn-arm64": 0.2,
+	}
+	if !reflect.DeepEqual(expectedPassRates, autoPasses.passRateByJobName) {
+		t.Errorf("unexpected pass rates %v", autoPasses.passRateByJobName)
+	}
+	// 2.45 expected passes on amd64, half of it rounded down
+	if actual := autoPasses.requiredPasses("amd64"); actual != 1 {
+		t.Errorf("expected 1 required pass for amd64, got %d", actual)
+	}
+	// 2.65 expected passes across architectures
+	if actual := autoPasses.requiredPasses(""); actual != 1 {
+		t.Errorf("expected 1 required pass across architectures, got %d", actual)
+	}
+
+	for i := 0; i < 6; i++ {
+		autoPasses.passRateByJobName[fmt.Sprintf("periodic-ci-openshift-release-master-nightly-4.16-e2e-vsphere-ovn-%d", i)] = 1
+	}
+	// 8.45 expected passes on amd64
+	if actual := autoPasses.requiredPasses("amd64"); actual != 4 {
+		t.Errorf("expected 4 required passes for amd64, got %d", actual)
+	}
+	// arm64 rarely passes, but at least one pass is always required
+	if actual := autoPasses.requiredPasses("arm64"); actual != 1 {
+		t.Errorf("expected 1 required pass for arm64, got %d", actual)
+	}
+
+	checker := minimumRequiredPassesTestCaseChecker{id: installTestIdentifier, requiredNumberOfPasses: 10, autoRequiredPasses: autoPasses}
+	if actual := checker.minimumPasses(); actual != 4 {
+		t.Errorf("expected the derived minimum to replace the configured one, got %d", actual)
+	}
+}
+
+func TestMinimumSuccessfulCountValue(t *testing.T) {
+	f := NewJobRunsTestCaseAnalyzerFlags()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f.BindFlags(fs)
+
+	if err := fs.Parse([]string{"--minimum-successful-count=auto"}); err != nil {
+		t.Fatal(err)
+	}
+	if !f.MinimumSuccessfulTestCountAuto {
+		t.Errorf("expected auto to be set")
+	}
+	if err := fs.Parse([]string{"--minimum-successful-count=5"}); err != nil {
+		t.Fatal(err)
+	}
+	if f.MinimumSuccessfulTestCountAuto || f.MinimumSuccessfulTestCount != 5 {
+		t.Errorf("expected 5 without auto, got %d with auto %t", f.MinimumSuccessfulTestCount, f.MinimumSuccessfulTestCountAuto)
+	}
+	if err := fs.Parse([]string{"--minimum-successful-count=some"}); err == nil {
+		t.Errorf("expected an error for an invalid count")
+	}
+}
+
+func TestZeroToleranceTestCaseChecker(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	passed := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest}}}}}
+	failed := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest, FailureOutput: &junit.FailureOutput{}}}}}}
+	jobRunJunits := map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{}
+	for i := 0; i < 9; i++ {
+		jobRunJunits[newMockJobRun(mockCtrl, "job-a", fmt.Sprintf("%d", i), passed, nil)] = passed
+	}
+
+	checker := zeroToleranceTestCaseChecker{id: installTestIdentifier}
+	if suite := checker.CheckTestCase(ctx, jobRunJunits); suite.NumFailed != 0 {
+		t.Errorf("expected no failure when every job run passed, got %d", suite.NumFailed)
+	}
+
+	jobRunJunits[newMockJobRun(mockCtrl, "job-b", "9", failed, nil)] = failed
+	suite := checker.CheckTestCase(ctx, jobRunJunits)
+	if suite.NumFailed != 1 {
+		t.Fatalf("expected a single failure to fail the checker despite 9 passes, got %d failures", suite.NumFailed)
+	}
+	failure := suite.Children[0].TestCases[0].FailureOutput
+	if failure.Message != "zero tolerance test failed in 1 of 10 job runs" || !strings.HasSuffix(failure.Output, "/job-b/9") {
+		t.Errorf("unexpected failure %#v", failure)
+	}
+}
+
+func TestFlakedTestCountsAsPass(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	flaked := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{
+		{Name: installTest, FailureOutput: &junit.FailureOutput{}},
+		{Name: installTest},
+	}}}}
+	if status := getTestStatusInJobRun(installTestIdentifier, flaked); status != testFlaked {
+		t.Fatalf("expected a failed then passed test to flake, got %v", status)
+	}
+	failedAfterPassing := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{
+		{Name: installTest},
+		{Name: installTest, FailureOutput: &junit.FailureOutput{}},
+	}}}}
+	if status := getTestStatusInJobRun(installTestIdentifier, failedAfterPassing); status != testFailed {
+		t.Fatalf("expected a passed then failed test to fail, got %v", status)
+	}
+
+	flakedJobRun := newMockJobRun(mockCtrl, "job-a", "1", flaked, nil)
+	jobRunJunits := map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{flakedJobRun: flaked}
+
+	minimumPasses := minimumRequiredPassesTestCaseChecker{id: installTestIdentifier, requiredNumberOfPasses: 1}
+	suite := minimumPasses.CheckTestCase(ctx, jobRunJunits)
+	if suite.NumFailed != 0 {
+		t.Errorf("expected the flake to count as a pass, got %d failures", suite.NumFailed)
+	}
+	details, err := jobrunaggregatorlib.GetTestCaseDetails(suite.Children[0].TestCases[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(details.Passes) != 1 || len(details.Failures) != 0 || len(details.Flakes) != 1 || details.Flakes[0].JobRunID != "1" {
+		t.Errorf("expected the flake to be tracked as a pass and a flake, got %+v", details)
+	}
+
+	zeroTolerance := zeroToleranceTestCaseChecker{id: installTestIdentifier}
+	if suite := zeroTolerance.CheckTestCase(ctx, jobRunJunits); suite.NumFailed != 1 {
+		t.Errorf("expected a flake to fail the zero tolerance checker, got %d failures", suite.NumFailed)
+	}
+}
+
+func TestMaximumFailuresTestCaseChecker(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	passed := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest}}}}}
+	failed := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest, FailureOutput: &junit.FailureOutput{}}}}}}
+	jobRunJunits := map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{}
+	for i := 0; i < 2; i++ {
+		jobRunJunits[newMockJobRun(mockCtrl, "job-a", fmt.Sprintf("%d", i), failed, nil)] = failed
+	}
+
+	checker := maximumFailuresTestCaseChecker{id: installTestIdentifier, maximumFailures: 2}
+	if suite := checker.CheckTestCase(ctx, jobRunJunits); suite.NumFailed != 0 {
+		t.Errorf("expected no failure with as many failures as allowed and no passes, got %d", suite.NumFailed)
+	}
+
+	for i := 2; i < 12; i++ {
+		jobRunJunits[newMockJobRun(mockCtrl, "job-a", fmt.Sprintf("%d", i), passed, nil)] = passed
+	}
+	jobRunJunits[newMockJobRun(mockCtrl, "job-b", "12", failed, nil)] = failed
+	suite := checker.CheckTestCase(ctx, jobRunJunits)
+	if suite.NumFailed != 1 {
+		t.Fatalf("expected a failure more than allowed to fail the checker despite 10 passes, got %d failures", suite.NumFailed)
+	}
+	failure := suite.Children[0].TestCases[0].FailureOutput
+	if failure.Message != "allowed maximum failure count 2, got 3" || !strings.Contains(failure.Output, "/job-b/12") {
+		t.Errorf("unexpected failure %#v", failure)
+	}
+}
+
+func TestReportOnlyTestCaseChecker(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	failed := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest, FailureOutput: &junit.FailureOutput{}}}}}}
+	jobRunJunits := map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{
+		newMockJobRun(mockCtrl, "job-a", "1", failed, nil): failed,
+	}
+
+	checker := zeroToleranceTestCaseChecker{id: installTestIdentifier}
+	if !isReportOnly(checker, []testIdentifier{installTestIdentifier}) {
+		t.Fatalf("expected the checker of the install test to be report-only")
+	}
+	if isReportOnly(checker, []testIdentifier{upgradeTestIdentifier}) {
+		t.Errorf("expected the checker of the install test not to be report-only for the upgrade test")
+	}
+
+	suite := reportOnlyTestCaseChecker{checker: checker}.CheckTestCase(ctx, jobRunJunits)
+	if suite.NumFailed != 0 || suite.NumTests != 1 {
+		t.Fatalf("expected 1 passing test, got %d tests and %d failures", suite.NumTests, suite.NumFailed)
+	}
+	testCase := suite.Children[0].TestCases[0]
+	if verdict := jobrunaggregatorlib.GetReportOnlyVerdict(testCase); verdict != jobrunaggregatorapi.GateVerdictFailed {
+		t.Errorf("expected the failed verdict to be recorded, got %q", verdict)
+	}
+	if !strings.Contains(testCase.SystemOut, "report-only checker failed: zero tolerance test failed in 1 of 1 job runs") {
+		t.Errorf("expected the failure to be kept in the output, got %q", testCase.SystemOut)
+	}
+
+	result := newAnalysisResult("4.14.0-0.nightly-2023-10-01-000000", &junit.TestSuite{Name: "payload-cross-jobs", Children: []*junit.TestSuite{suite}}, nil, nil, newOptionalJobs(nil))
+	rows := testCaseAnalysisRows(result, "install", time.Unix(0, 0))
+	if len(rows) != 1 {
+		t.Fatalf("expected one row, got %v", rows)
+	}
+	if row := rows[0]; row.Verdict != jobrunaggregatorapi.GateVerdictPassed || row.ReportOnlyVerdict != jobrunaggregatorapi.GateVerdictFailed {
+		t.Errorf("expected the report-only failure to be recorded in a passing row, got %+v", row)
+	}
+}
+
+func TestNewTestCaseCheckersReportOnly(t *testing.T) {
+	f := NewJobRunsTestCaseAnalyzerFlags()
+	f.TestGroup = installTestGroup + "," + upgradeTestGroup
+	f.MaximumFailureCount = 2
+	f.ReportOnlyTests = []string{installTestGroup}
+	checkers, err := f.newTestCaseCheckers("", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reportOnlyCheckers := 0
+	for _, checker := range checkers {
+		if _, ok := checker.(reportOnlyTestCaseChecker); ok {
+			reportOnlyCheckers++
+			if gated := checker.(gatedTestsReporter).gatedTests(); gated[0].key() != installTestIdentifier.key() {
+				t.Errorf("expected only the checkers of the install test group to be report-only, got %v", gated)
+			}
+		}
+	}
+	if reportOnlyCheckers != 2 {
+		t.Errorf("expected both checkers of the install test group to be report-only, got %d", reportOnlyCheckers)
+	}
+
+	// a test of the group is not the group, it would otherwise silence the other tests the group gates on
+	f.ReportOnlyTests = []string{strings.Join(installTestSuites, jobrunaggregatorlib.TestSuitesSeparator) + "=other test"}
+	if _, err := f.newTestCaseCheckers("", nil, nil); err == nil || !strings.Contains(err.Error(), "gates no checker") {
+		t.Errorf("expected a test no checker gates on to be rejected, got %v", err)
+	}
+}
+
+type fakeEvidenceUploader struct {
+	bundles map[string]*jobrunaggregatorlib.EvidenceBundle
+}
+
+func (f *fakeEvidenceUploader) Upload(ctx context.Context, name string, bundle *jobrunaggregatorlib.EvidenceBundle) (string, error) {
+	f.bundles[name] = bundle
+	return "gs://evidence/" + name, nil
+}
+
+func TestExportEvidence(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	failed := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest, FailureOutput: &junit.FailureOutput{Message: "bootstrap failed"}}}}}}
+	jobRun := newMockJobRun(mockCtrl, "job-a", "1", failed, nil)
+	jobRun.EXPECT().GetProwJob(gomock.Any()).Return(nil, fmt.Errorf("not found"))
+	jobRun.EXPECT().GetGCSProwJobPath().Return("logs/job-a/1/prowjob.json")
+	jobRun.EXPECT().GetContent(gomock.Any(), "logs/job-a/1/build-log.txt").Return([]byte("level=error msg=\"bootstrap failed\"\n"), nil)
+	jobRunJunits := map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{jobRun: failed}
+
+	uploader := &fakeEvidenceUploader{bundles: map[string]*jobrunaggregatorlib.EvidenceBundle{}}
+	o := &JobRunTestCaseAnalyzerOptions{evidenceUploader: uploader}
+	suite := zeroToleranceTestCaseChecker{id: installTestIdentifier}.CheckTestCase(ctx, jobRunJunits)
+	o.exportEvidence(ctx, "4.15.0-0.nightly-2023-10-01-000000", suite, jobRunJunits)
+
+	if len(uploader.bundles) != 1 {
+		t.Fatalf("expected one bundle, got %d", len(uploader.bundles))
+	}
+	for name, bundle := range uploader.bundles {
+		if len(bundle.JobRuns) != 1 {
+			t.Fatalf("expected the failed job run in the bundle, got %v", bundle.JobRuns)
+		}
+		evidence := bundle.JobRuns[0]
+		if len(evidence.JunitFailures) != 1 || evidence.JunitFailures[0].Message != "bootstrap failed" {
+			t.Errorf("expected the junit failure in the bundle, got %v", evidence.JunitFailures)
+		}
+		if evidence.BuildLogExcerpt != `level=error msg="bootstrap failed"` || len(evidence.Errors) != 1 {
+			t.Errorf("expected the build log and the prowjob error in the bundle, got %q and %v", evidence.BuildLogExcerpt, evidence.Errors)
+		}
+		if testCase := suite.Children[0].TestCases[0]; !strings.Contains(testCase.SystemOut, "evidence bundle: gs://evidence/"+name) {
+			t.Errorf("expected the test case to reference the bundle, got %q", testCase.SystemOut)
+		}
+	}
+}
+
+// gateOverrideInserter keeps the rows it is given, and fails to store them when it has an error
+type gateOverrideInserter struct {
+	rows []jobrunaggregatorapi.GateOverrideRow
+	err  error
+}
+
+func (f *gateOverrideInserter) Put(ctx context.Context, src interface{}) error {
+	f.rows = append(f.rows, src.([]jobrunaggregatorapi.GateOverrideRow)...)
+	return f.err
+}
+
+func TestApplyGateOverride(t *testing.T) {
+	newSuite := func() *junit.TestSuite {
+		suite := &junit.TestSuite{Children: []*junit.TestSuite{{
+			Name: minimumRequiredPassesSuiteName,
+			TestCases: []*junit.TestCase{
+				{Name: installTest, FailureOutput: &junit.FailureOutput{Message: "required minimum successful count 3, got 1"}},
+				{Name: "upgrade"},
+			},
+		}}}
+		jobrunaggregatorlib.UpdateTestCountsInSuite(suite)
+		return suite
+	}
+	override := &jobrunaggregatorlib.GateOverride{IssuedBy: "release-architect", Reason: "known infra outage"}
+
+	inserter := &gateOverrideInserter{}
+	o := &JobRunTestCaseAnalyzerOptions{
+		testGroup:            "install",
+		gateOverride:         override,
+		gateOverrideInserter: inserter,
+	}
+	suite := newSuite()
+	if err := o.applyGateOverride(context.TODO(), "4.15.0-0.nightly-2023-10-01-000000", suite); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if suite.NumFailed != 0 || suite.NumTests != 2 {
+		t.Errorf("expected the suite to be recounted with no failures, got %d failed of %d", suite.NumFailed, suite.NumTests)
+	}
+	if len(inserter.rows) != 1 {
+		t.Fatalf("expected one override row, got %v", inserter.rows)
+	}
+	if row := inserter.rows[0]; row.TestGroup != "install" || len(row.JobName) > 0 {
+		t.Errorf("expected the override to be recorded for the test group and no job, got %q and %q", row.TestGroup, row.JobName)
+	}
+
+	// an override without an audit trail fails the analysis rather than accepting the failures
+	o.gateOverrideInserter = &gateOverrideInserter{err: fmt.Errorf("quota exceeded")}
+	err := o.applyGateOverride(context.TODO(), "4.15.0-0.nightly-2023-10-01-000000", newSuite())
+	if err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("expected the failure to record the override, got %v", err)
+	}
+	o.gateOverrideInserter = nil
+	if err := o.applyGateOverride(context.TODO(), "4.15.0-0.nightly-2023-10-01-000000", newSuite()); err == nil {
+		t.Errorf("expected an override that can't be recorded to fail")
+	}
+}
+
+func TestRegexTestIdentifier(t *testing.T) {
+	id, err := parseTestIdentifier("cluster install=~install should succeed: .*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSuites := func(testCases ...*junit.TestCase) *junit.TestSuites {
+		return &junit.TestSuites{Suites: []*junit.TestSuite{{Name: "cluster install", TestCases: testCases}}}
+	}
+	testCases := []struct {
+		name       string
+		testSuites *junit.TestSuites
+		expected   testStatus
+	}{
+		{name: "matching test passed", testSuites: testSuites(&junit.TestCase{Name: "install should succeed: overall 4.16 aws"}), expected: testPassed},
+		{name: "matching test failed", testSuites: testSuites(&junit.TestCase{Name: "install should succeed: overall 4.16 gcp", FailureOutput: &junit.FailureOutput{}}), expected: testFailed},
+		{
+			name: "another matching test failed",
+			testSuites: testSuites(
+				&junit.TestCase{Name: "install should succeed: overall 4.16 aws"},
+				&junit.TestCase{Name: "install should succeed: overall 4.16 gcp", FailureOutput: &junit.FailureOutput{}},
+			),
+			expected: testFailed,
+		},
+		{
+			name: "another matching test flaked",
+			testSuites: testSuites(
+				&junit.TestCase{Name: "install should succeed: overall 4.16 gcp", FailureOutput: &junit.FailureOutput{}},
+				&junit.TestCase{Name: "install should succeed: overall 4.16 aws"},
+				&junit.TestCase{Name: "install should succeed: overall 4.16 gcp"},
+			),
+			expected: testFlaked,
+		},
+		{name: "only the prefix matches", testSuites: testSuites(&junit.TestCase{Name: "cluster install should succeed: overall"}), expected: testSkipped},
+		{name: "other suite", testSuites: &junit.TestSuites{Suites: []*junit.TestSuite{{Name: "cluster install again", TestCases: []*junit.TestCase{{Name: "install should succeed: overall"}}}}}, expected: testSkipped},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := getTestStatusInJobRun(id, tc.testSuites); actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+
+	failed := testSuites(&junit.TestCase{Name: "install should succeed: overall 4.16 gcp", FailureOutput: &junit.FailureOutput{}})
+	if found := findFailedTestCases(id, failed); len(found) != 1 {
+		t.Errorf("expected the failed test case to be found, got %v", found)
+	}
+}
+
+func TestRegexTestIdentifierFailsOnAnyMatchedFailure(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	id, err := parseTestIdentifier("cluster install=~install should succeed: .*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSuites := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: "cluster install", TestCases: []*junit.TestCase{
+		{Name: "install should succeed: overall 4.16 aws"},
+		{Name: "install should succeed: overall 4.16 gcp", FailureOutput: &junit.FailureOutput{}},
+	}}}}
+	jobRunJunits := map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{newMockJobRun(mockCtrl, "job-a", "1", testSuites, nil): testSuites}
+
+	if suite := (minimumRequiredPassesTestCaseChecker{id: id, requiredNumberOfPasses: 1}).CheckTestCase(ctx, jobRunJunits); suite.NumFailed != 1 {
+		t.Errorf("expected the pass of one matched test not to hide the failure of another, got %d failures", suite.NumFailed)
+	}
+	if suite := (maximumFailuresTestCaseChecker{id: id, maximumFailures: 0}).CheckTestCase(ctx, jobRunJunits); suite.NumFailed != 1 {
+		t.Errorf("expected the failure of a matched test to count, got %d failures", suite.NumFailed)
+	}
+}
+
+func TestParseTestIdentifier(t *testing.T) {
+	testCases := []struct {
+		value       string
+		expected    testIdentifier
+		expectedErr bool
+	}{
+		{value: "upgrade", expected: upgradeTestIdentifier},
+		{value: "conformance", expected: testIdentifier{testSuites: []string{"openshift-tests"}, testName: "[sig-arch] openshift-tests should work"}},
+		{
+			value:    "openshift-tests|||Conformance=[sig-storage] data must persist across upgrade",
+			expected: testIdentifier{testSuites: []string{"openshift-tests", "Conformance"}, testName: "[sig-storage] data must persist across upgrade"},
+		},
+		{value: "missing test name=", expectedErr: true},
+		{value: "cluster install=~install should succeed: (", expectedErr: true},
+		{value: "unknown", expectedErr: true},
+	}
+	for _, tc := range testCases {
+		actual, err := parseTestIdentifier(tc.value)
+		if (err != nil) != tc.expectedErr {
+			t.Errorf("%q: expected error %t, got %v", tc.value, tc.expectedErr, err)
+			continue
+		}
+		if !reflect.DeepEqual(tc.expected, actual) {
+			t.Errorf("%q: expected %#v, got %#v", tc.value, tc.expected, actual)
+		}
+	}
+}
+
+func TestNewTestGrid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	installPassedUpgradeFailed := &junit.TestSuites{
+		Suites: []*junit.TestSuite{
+			{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest}}},
+			{Name: upgradeTestSuite[0], TestCases: []*junit.TestCase{{Name: upgradeTest, FailureOutput: &junit.FailureOutput{}}}},
+		},
+	}
+	installFailed := &junit.TestSuites{
+		Suites: []*junit.TestSuite{
+			{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest, FailureOutput: &junit.FailureOutput{}}}},
+		},
+	}
+	jobRunJunits := map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{
+		newMockJobRun(mockCtrl, "job-b", "1", installFailed, nil):              installFailed,
+		newMockJobRun(mockCtrl, "job-a", "2", installPassedUpgradeFailed, nil): installPassedUpgradeFailed,
+	}
+	installChecker := minimumRequiredPassesTestCaseChecker{id: installTestIdentifier, testNameSuffix: "", requiredNumberOfPasses: 1}
+	checkers := []TestCaseChecker{
+		installChecker,
+		minimumRequiredPassesTestCaseChecker{id: upgradeTestIdentifier, testNameSuffix: "", requiredNumberOfPasses: 1},
+		perArchitectureTestCaseChecker{checker: installChecker, architectures: newJobArchitectures()},
+	}
+
+	grid := newTestGrid(checkers, jobRunJunits, newOptionalJobs([]string{"job-b"}))
+
+	if len(grid.JobRuns) != 2 || grid.JobRuns[0].JobName != "job-a" || grid.JobRuns[1].JobName != "job-b" {
+		t.Fatalf("expected job runs sorted by job name, got %v", grid.JobRuns)
+	}
+	if len(grid.Tests) != 2 {
+		t.Fatalf("expected each gated test once, got %d rows", len(grid.Tests))
+	}
+	expected := map[string][]string{
+		installTest: {"pass", "fail"},
+		upgradeTest: {"fail", "skip"},
+	}
+	for _, row := range grid.Tests {
+		if !reflect.DeepEqual(expected[row.TestName], row.Results) {
+			t.Errorf("expected results %v for %q, got %v", expected[row.TestName], row.TestName, row.Results)
+		}
+	}
+	if grid.JobRuns[0].Optional || !grid.JobRuns[1].Optional {
+		t.Errorf("expected only the job run of job-b to be optional, got %v", grid.JobRuns)
+	}
+	gridHTML := htmlForTestGrid(grid)
+	if !strings.Contains(gridHTML, `<th class="jobrun optional">`) {
+		t.Errorf("expected the optional job run to be marked, got %s", gridHTML)
+	}
+	if !strings.Contains(gridHTML, `<td class="fail"><a target="_blank" href="https://prow.ci.openshift.org/view/gs/test-platform-results/logs/job-b/1">fail</a></td>`) {
+		t.Errorf("expected the failed cell to link to the job run, got %s", gridHTML)
+	}
+}
+
+func TestNewAnalysisResult(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	installFailed := &junit.TestSuites{
+		Suites: []*junit.TestSuite{
+			{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest, FailureOutput: &junit.FailureOutput{}}}},
+		},
+	}
+	installPassed := &junit.TestSuites{
+		Suites: []*junit.TestSuite{
+			{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest}}},
+		},
+	}
+	failedJobRun := newMockJobRun(mockCtrl, "job-b", "1", installFailed, nil)
+	passedJobRun := newMockJobRun(mockCtrl, "job-a", "2", installPassed, nil)
+	unfinishedJobRun := newMockJobRun(mockCtrl, "job-a", "3", nil, nil)
+	checker := minimumRequiredPassesTestCaseChecker{id: installTestIdentifier, requiredNumberOfPasses: 2}
+	testSuite := &junit.TestSuite{Name: "payload-cross-jobs"}
+	checkerSuite := checker.CheckTestCase(context.TODO(), map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{
+		failedJobRun: installFailed,
+		passedJobRun: installPassed,
+	})
+	testSuite.Children = append(testSuite.Children, checkerSuite)
+	testSuite.NumFailed = checkerSuite.NumFailed
+
+	result := newAnalysisResult("4.14.0-0.nightly-2023-10-01-000000", testSuite,
+		[]jobrunaggregatorapi.JobRunInfo{failedJobRun, passedJobRun}, []jobrunaggregatorapi.JobRunInfo{unfinishedJobRun}, newOptionalJobs(nil))
+
+	if result.Passed {
+		t.Errorf("expected the analysis to fail")
+	}
+	expectedJobRuns := []string{"job-a/2 finished", "job-a/3 unfinished", "job-b/1 finished"}
+	var jobRuns []string
+	for _, jobRun := range result.JobRuns {
+		jobRuns = append(jobRuns, fmt.Sprintf("%s/%s %s", jobRun.JobName, jobRun.JobRunID, jobRun.Status))
+	}
+	if !reflect.DeepEqual(expectedJobRuns, jobRuns) {
+		t.Errorf("expected job runs %v, got %v", expectedJobRuns, jobRuns)
+	}
+	if len(result.Checkers) != 1 {
+		t.Fatalf("expected one checker result, got %v", result.Checkers)
+	}
+	actual := result.Checkers[0]
+	if actual.Verdict != jobrunaggregatorapi.GateVerdictFailed || actual.Passes != 1 || actual.Failures != 1 || actual.Skips != 0 {
+		t.Errorf("expected a failed verdict with one pass and one failure, got %+v", actual)
+	}
+	expectedSuiteName := strings.Join(append([]string{"payload-cross-jobs", "minimum-required-passes-checker"}, installTestSuites...), jobrunaggregatorlib.TestSuitesSeparator)
+	if actual.TestSuiteName != expectedSuiteName {
+		t.Errorf("expected suite name %q, got %q", expectedSuiteName, actual.TestSuiteName)
+	}
+
+	rows := testCaseAnalysisRows(result, "install", time.Unix(0, 0))
+	if len(rows) != 1 {
+		t.Fatalf("expected one row, got %v", rows)
+	}
+	if row := rows[0]; row.Checker != "minimum-required-passes-checker" || row.TestGroup != "install" || row.PayloadTag != result.MatchID || row.Passes != 1 || row.Failures != 1 {
+		t.Errorf("unexpected row %+v", row)
+	}
+}
+
+func TestHTMLForAnalysisSummary(t *testing.T) {
+	result := &AnalysisResult{
+		MatchID: "4.14.0-0.nightly-2023-10-01-000000",
+		JobRuns: []AnalysisResultJobRun{
+			{JobName: "job-a", JobRunID: "1", HumanURL: "https://example.com/job-a/1", Status: jobRunStatusFinished},
+			{JobName: "job-b", JobRunID: "2", HumanURL: "https://example.com/job-b/2", Status: jobRunStatusFinished, Optional: true},
+			{JobName: "job-a", JobRunID: "3", HumanURL: "https://example.com/job-a/3", Status: jobRunStatusUnfinished},
+		},
+	}
+	summary := htmlForAnalysisSummary(result, map[string]testStatus{"1": testPassed, "2": testFailed})
+
+	for _, expected := range []string{
+		"Verdict for 4.14.0-0.nightly-2023-10-01-000000: Failed",
+		`<a target="_blank" href="https://example.com/job-a/1">job-a/1</a> install: pass</li>`,
+		`<a target="_blank" href="https://example.com/job-b/2">job-b/2</a> install: fail (optional)</li>`,
+		`<a target="_blank" href="https://example.com/job-a/3">job-a/3</a></li>`,
+	} {
+		if !strings.Contains(summary, expected) {
+			t.Errorf("expected the summary to contain %q, got:\n%s", expected, summary)
+		}
+	}
+	if strings.Index(summary, "Unfinished Jobs") > strings.Index(summary, "Finished Jobs") {
+		t.Errorf("expected the unfinished jobs to be listed first, got:\n%s", summary)
+	}
+}
+
+func TestRetryPolicyFindJobRuns(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	jobRun := newMockJobRun(mockCtrl, "job-a", "1", nil, nil)
+
+	failingTimes := func(failures int) func(context.Context) ([]jobrunaggregatorapi.JobRunInfo, error) {
+		return func(ctx context.Context) ([]jobrunaggregatorapi.JobRunInfo, error) {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Errorf("expected every attempt to have a timeout")
+			}
+			if failures > 0 {
+				failures--
+				return nil, fmt.Errorf("bigquery unavailable")
+			}
+			return []jobrunaggregatorapi.JobRunInfo{jobRun}, nil
+		}
+	}
+
+	for _, backoff := range []string{retryBackoffFixed, retryBackoffExponential} {
+		t.Run(backoff, func(t *testing.T) {
+			policy := retryPolicy{maxAttempts: 3, backoff: backoff, interval: time.Millisecond, attemptTimeout: time.Minute}
+			telemetry := newRetryTelemetry()
+
+			jobRuns, err := policy.findJobRuns(context.TODO(), "job-a", telemetry, failingTimes(2))
+			if err != nil || len(jobRuns) != 1 {
+				t.Fatalf("expected the job run after two failed attempts, got %v, %v", jobRuns, err)
+			}
+			if _, err := policy.findJobRuns(context.TODO(), "job-b", telemetry, failingTimes(3)); err == nil {
+				t.Fatalf("expected to give up after 3 failed attempts")
+			}
+			if _, err := policy.findJobRuns(context.TODO(), "job-c", telemetry, failingTimes(0)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if retried := telemetry.retriedJobs(); !reflect.DeepEqual([]string{"job-a", "job-b"}, retried) {
+				t.Errorf("expected job-a and job-b to be retried, got %v", retried)
+			}
+			if value := telemetry.property().Value; !strings.HasPrefix(value, "jobs=3 retried-jobs=2 attempts=7 failures=5 waited=") {
+				t.Errorf("unexpected telemetry %q", value)
+			}
+		})
+	}
+}
+
+func TestPayloadTagCreationTime(t *testing.T) {
+	testCases := []struct {
+		payloadTag  string
+		expected    time.Time
+		expectedErr bool
+	}{
+		{payloadTag: "4.11.0-0.nightly-2022-04-28-102605", expected: time.Date(2022, 4, 28, 10, 26, 5, 0, time.UTC)},
+		{payloadTag: "4.9.0-0.ci-2021-07-19-185802", expected: time.Date(2021, 7, 19, 18, 58, 2, 0, time.UTC)},
+		{payloadTag: "4.11.0-rc.1", expectedErr: true},
+		{payloadTag: "4.11.0-0.nightly-2022-04-28-102605-multi", expectedErr: true},
+	}
+	for _, tc := range testCases {
+		actual, err := payloadTagCreationTime(tc.payloadTag)
+		if (err != nil) != tc.expectedErr {
+			t.Errorf("%q: expected error %t, got %v", tc.payloadTag, tc.expectedErr, err)
+			continue
+		}
+		if !actual.Equal(tc.expected) {
+			t.Errorf("%q: expected %s, got %s", tc.payloadTag, tc.expected, actual)
+		}
+	}
+}
+
+func TestMinimumPassesMet(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	passed := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest}}}}}
+	failed := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest, FailureOutput: &junit.FailureOutput{}}}}}}
+	o := &JobRunTestCaseAnalyzerOptions{
+		testCaseCheckers: []TestCaseChecker{
+			minimumRequiredPassesTestCaseChecker{id: installTestIdentifier, requiredNumberOfPasses: 2},
+		},
+		optionalJobs: newOptionalJobs([]string{"job-informing"}),
+	}
+
+	finishedJobRuns := []jobrunaggregatorapi.JobRunInfo{
+		newMockJobRun(mockCtrl, "job-a", "1", passed, nil),
+		newMockJobRun(mockCtrl, "job-b", "2", failed, nil),
+		newMockJobRun(mockCtrl, "job-informing", "3", passed, nil),
+		newMockJobRun(mockCtrl, "job-c", "4", nil, fmt.Errorf("no junit yet")),
+	}
+	if o.minimumPassesMet(ctx, finishedJobRuns) {
+		t.Errorf("expected the pass of the optional job not to count towards the minimum")
+	}
+	finishedJobRuns = append(finishedJobRuns, newMockJobRun(mockCtrl, "job-d", "5", passed, nil))
+	if !o.minimumPassesMet(ctx, finishedJobRuns) {
+		t.Errorf("expected two passes to meet the minimum")
+	}
+
+	o.testCaseCheckers = append(o.testCaseCheckers, erroringTestCaseChecker{})
+	if o.minimumPassesMet(ctx, finishedJobRuns) {
+		t.Errorf("expected a checker failing to check the job runs to keep waiting")
+	}
+}
+
+// erroringTestCaseChecker returns no suite, like checkers do when they fail to check the job runs
+type erroringTestCaseChecker struct{}
+
+func (erroringTestCaseChecker) CheckTestCase(ctx context.Context, jobRunJunits map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) *junit.TestSuite {
+	return nil
+}
+
+func TestRunTestCaseCheckersUnfinishedPolicy(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	passed := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest}}}}}
+	for _, tc := range []struct {
+		policy           string
+		expectedFailures uint
+	}{
+		{policy: unfinishedPolicySkip, expectedFailures: 0},
+		{policy: unfinishedPolicyWait, expectedFailures: 0},
+		{policy: unfinishedPolicyFail, expectedFailures: 1},
+	} {
+		o := &JobRunTestCaseAnalyzerOptions{
+			testCaseCheckers: []TestCaseChecker{zeroToleranceTestCaseChecker{id: installTestIdentifier}},
+			unfinishedPolicy: tc.policy,
+		}
+		// the unfinished job run passed install already, only the fail policy disregards it
+		topSuite, jobRunJunitMap := o.runTestCaseCheckers(ctx,
+			[]jobrunaggregatorapi.JobRunInfo{newMockJobRun(mockCtrl, "job-a", "1", passed, nil)},
+			[]jobrunaggregatorapi.JobRunInfo{newMockJobRun(mockCtrl, "job-b", "2", passed, nil)},
+		)
+		if topSuite.NumFailed != tc.expectedFailures {
+			t.Errorf("%s: expected %d failures, got %d", tc.policy, tc.expectedFailures, topSuite.NumFailed)
+		}
+		if len(jobRunJunitMap) != 2 {
+			t.Errorf("%s: expected both job runs to be checked, got %d", tc.policy, len(jobRunJunitMap))
+		}
+	}
+}
+
+func TestTimeToStopWaitingUnfinishedPolicy(t *testing.T) {
+	timeToStopWaiting := time.Now().Add(time.Hour)
+	deadline := time.Now().Add(3 * time.Hour)
+	ctx, cancel := context.WithDeadline(context.TODO(), deadline)
+	defer cancel()
+
+	o := &JobRunTestCaseAnalyzerOptions{unfinishedPolicy: unfinishedPolicySkip}
+	if actual := o.timeToStopWaiting(ctx, timeToStopWaiting); !actual.Equal(timeToStopWaiting) {
+		t.Errorf("expected the skip policy to keep the wait, got %s", actual)
+	}
+	o.unfinishedPolicy = unfinishedPolicyWait
+	if actual := o.timeToStopWaiting(ctx, timeToStopWaiting); !actual.Equal(deadline.Add(-unfinishedWaitMargin)) {
+		t.Errorf("expected the wait policy to wait until shortly before the timeout, got %s", actual)
+	}
+}
+
+func TestSuiteNamesAndInvocationProperties(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	passed := &junit.TestSuites{Suites: []*junit.TestSuite{{Name: installTestSuites[0], TestCases: []*junit.TestCase{{Name: installTest}}}}}
+	o := &JobRunTestCaseAnalyzerOptions{
+		payloadTag:     "4.11.0-0.nightly-2022-04-28-102605",
+		testGroup:      "install",
+		testNameSuffix: "platform:aws",
+		topSuiteName:   "payload-cross-jobs-aws",
+		testCaseCheckers: []TestCaseChecker{
+			minimumRequiredPassesTestCaseChecker{id: installTestIdentifier, requiredNumberOfPasses: 1, suiteName: "install-passes"},
+		},
+	}
+	topSuite, _ := o.runTestCaseCheckers(ctx, []jobrunaggregatorapi.JobRunInfo{newMockJobRun(mockCtrl, "job-a", "1", passed, nil)}, nil)
+	if topSuite.Name != "payload-cross-jobs-aws" || topSuite.Children[0].Name != "install-passes" {
+		t.Errorf("expected the configured suite names, got %q and %q", topSuite.Name, topSuite.Children[0].Name)
+	}
+
+	properties := map[string]string{}
+	for _, property := range o.invocationProperties(time.Date(2022, 4, 28, 12, 0, 0, 0, time.UTC)) {
+		properties[property.Name] = property.Value
+	}
+	for name, expected := range map[string]string{
+		"analyzer":    "analyze-test-case",
+		"start-time":  "2022-04-28T12:00:00Z",
+		"payload-tag": "4.11.0-0.nightly-2022-04-28-102605",
+		"test-group":  "install",
+		"filters":     "platform:aws",
+	} {
+		if properties[name] != expected {
+			t.Errorf("expected property %s to be %q, got %q", name, expected, properties[name])
+		}
+	}
+	if _, ok := properties["payload-invocation-id"]; ok {
+		t.Errorf("expected no payload-invocation-id property for a payload tag")
+	}
+}
+
+func TestFilterJobsForPayloadByRelease(t *testing.T) {
+	jobs := []jobrunaggregatorapi.JobRowWithVariants{
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn", Platform: "aws", Network: "ovn", Release: "4.16"},
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-upgrade-from-stable-4.15-e2e-aws-ovn-upgrade", Platform: "aws", Network: "ovn", Release: "4.16"},
+		// the name says 4.16, the jobs table says otherwise
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn-4.17-preview", Platform: "aws", Network: "ovn", Release: "4.17"},
+		{JobName: "periodic-ci-openshift-release-master-nightly-4.15-e2e-aws-ovn", Platform: "aws", Network: "ovn", Release: "4.15"},
+	}
+	jobGetter := &testCaseAnalyzerJobGetter{release: "4.16"}
+	var jobNames []string
+	for _, job := range jobGetter.filterJobsForPayload(jobs) {
+		jobNames = append(jobNames, job.JobName)
+	}
+	if expected := []string{jobs[0].JobName, jobs[1].JobName}; !reflect.DeepEqual(expected, jobNames) {
+		t.Errorf("expected jobs %v, got %v", expected, jobNames)
+	}
+}
+
+func TestAnalysisRequestToFlags(t *testing.T) {
+	jobStartTime := time.Date(2023, 5, 4, 3, 2, 1, 0, time.UTC)
+	f := AnalysisRequest{
+		PayloadInvocationID: "abc",
+		JobGCSPrefixes:      []AnalysisJobGCSPrefix{{JobName: "job", GCSPrefix: "logs/job"}},
+		JobStartTime:        jobStartTime,
+		Platform:            "aws",
+		Release:             "4.14",
+	}.toFlags()
+
+	if f.PayloadInvocationID != "abc" || len(f.PayloadTags) != 0 {
+		t.Errorf("unexpected payload selection, invocation ID %q and tags %v", f.PayloadInvocationID, f.PayloadTags)
+	}
+	if !reflect.DeepEqual(f.JobGCSPrefixes, []jobGCSPrefix{{jobName: "job", gcsPrefix: "logs/job"}}) {
+		t.Errorf("unexpected job GCS prefixes %v", f.JobGCSPrefixes)
+	}
+	if parsed, err := time.Parse(kubeTimeSerializationLayout, f.EstimatedJobStartTimeString); err != nil || !parsed.Equal(jobStartTime) {
+		t.Errorf("unexpected job start time %q: %v", f.EstimatedJobStartTimeString, err)
+	}
+	if f.Platform != "aws" || f.Release != "4.14" {
+		t.Errorf("unexpected filters, platform %q and release %q", f.Platform, f.Release)
+	}
+
+	defaults := NewJobRunsTestCaseAnalyzerFlags()
+	if f.TestGroup != installTestGroup {
+		t.Errorf("expected the %q test group by default, got %q", installTestGroup, f.TestGroup)
+	}
+	if f.MinimumSuccessfulTestCount != defaults.MinimumSuccessfulTestCount || f.Timeout != defaults.Timeout || f.WorkingDir != defaults.WorkingDir {
+		t.Errorf("expected the defaults of the command to be kept")
+	}
+	if f.Authentication == nil || f.DataCoordinates == nil {
+		t.Errorf("expected the default clients to be kept")
+	}
+}
+
+func TestJobGCSPrefixSliceSet(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    []jobGCSPrefix
+		expectedErr bool
+		// printed defaults to value
+		printed string
+	}{
+		{
+			name:     "prefixes in the bucket of the command",
+			value:    "job-a=logs/job-a,job-b=pr-logs/job-b",
+			expected: []jobGCSPrefix{{jobName: "job-a", gcsPrefix: "logs/job-a"}, {jobName: "job-b", gcsPrefix: "pr-logs/job-b"}},
+		},
+		{
+			name:  "prefix in another bucket",
+			value: "job-a=logs/job-a,job-b=gs://qe-private-deck/logs/job-b",
+			expected: []jobGCSPrefix{
+				{jobName: "job-a", gcsPrefix: "logs/job-a"},
+				{jobName: "job-b", gcsPrefix: "logs/job-b", gcsBucket: "qe-private-deck"},
+			},
+		},
+		{
+			name:  "job names read from the prefixes",
+			value: "logs/job-a,gs://qe-private-deck/pr-logs/pull/openshift_origin/123/job-b",
+			expected: []jobGCSPrefix{
+				{jobName: "job-a", gcsPrefix: "logs/job-a"},
+				{jobName: "job-b", gcsPrefix: "pr-logs/pull/openshift_origin/123/job-b", gcsBucket: "qe-private-deck"},
+			},
+			printed: "job-a=logs/job-a,job-b=gs://qe-private-deck/pr-logs/pull/openshift_origin/123/job-b",
+		},
+		{
+			name:        "job run prefix without job name",
+			value:       "pr-logs/pull/openshift_origin/123/job-b/1000",
+			expectedErr: true,
+		},
+		{
+			name:        "bucket without prefix",
+			value:       "job-a=gs://qe-private-deck",
+			expectedErr: true,
+		},
+		{
+			name:        "missing job name",
+			value:       "job-a/1000",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var values []jobGCSPrefix
+			s := &jobGCSPrefixSlice{values: &values}
+			err := s.Set(tc.value)
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("expected an error parsing %q", tc.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(values, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, values)
+			}
+			printed := tc.printed
+			if len(printed) == 0 {
+				printed = tc.value
+			}
+			if s.String() != printed {
+				t.Errorf("expected the prefixes to print as %q, got %q", printed, s.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package jobruntestcaseanalyzer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+)
+
+// AnalysisRequest selects the payload, the jobs and the tests of an analysis for programs calling RunAnalysis, like
+// the flags of analyze-test-case do for the command.  Zero values keep the defaults of the command.
+type AnalysisRequest struct {
+	// PayloadTag or PayloadInvocationID select the job runs of a release controller or of a PR payload
+	PayloadTag          string
+	PayloadInvocationID string
+	// JobGCSPrefixes are the jobs of a PR payload, only used with PayloadInvocationID
+	JobGCSPrefixes []AnalysisJobGCSPrefix
+	// JobStartTime is when the jobs of the payload started, job runs are searched around it
+	JobStartTime time.Time
+
+	// TestGroup is one or more comma-separated test groups, like install
+	TestGroup              string
+	MinimumSuccessfulCount int
+	ZeroToleranceTests     []string
+
+	Platform        string
+	Architecture    string
+	Network         string
+	Infrastructure  string
+	Release         string
+	IncludeJobNames []string
+	ExcludeJobNames []string
+
+	WorkingDir string
+	Timeout    time.Duration
+
+	// Authentication and DataCoordinates default to those of the command
+	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
+	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
+}
+
+// AnalysisJobGCSPrefix is a job of a PR payload along with the GCS prefix of its job runs
+type AnalysisJobGCSPrefix struct {
+	JobName   string
+	GCSPrefix string
+	// GCSBucket is only needed for jobs uploading to another bucket than the one of the command
+	GCSBucket string
+}
+
+// toFlags configures the flags of the command with the request, so that it is validated and run the same way
+func (r AnalysisRequest) toFlags() *JobRunsTestCaseAnalyzerFlags {
+	f := NewJobRunsTestCaseAnalyzerFlags()
+	if len(r.PayloadTag) > 0 {
+		f.PayloadTags = []string{r.PayloadTag}
+	}
+	f.PayloadInvocationID = r.PayloadInvocationID
+	for _, prefix := range r.JobGCSPrefixes {
+		f.JobGCSPrefixes = append(f.JobGCSPrefixes, jobGCSPrefix{jobName: prefix.JobName, gcsPrefix: prefix.GCSPrefix, gcsBucket: prefix.GCSBucket})
+	}
+	if !r.JobStartTime.IsZero() {
+		f.EstimatedJobStartTimeString = r.JobStartTime.Format(kubeTimeSerializationLayout)
+	}
+
+	f.TestGroup = r.TestGroup
+	if len(f.TestGroup) == 0 {
+		f.TestGroup = installTestGroup
+	}
+	if r.MinimumSuccessfulCount > 0 {
+		f.MinimumSuccessfulTestCount = r.MinimumSuccessfulCount
+	}
+	f.ZeroToleranceTests = r.ZeroToleranceTests
+
+	f.Platform = r.Platform
+	f.Architecture = r.Architecture
+	f.Network = r.Network
+	f.Infrastructure = r.Infrastructure
+	f.Release = r.Release
+	f.IncludeJobNames = r.IncludeJobNames
+	f.ExcludeJobNames = r.ExcludeJobNames
+
+	if len(r.WorkingDir) > 0 {
+		f.WorkingDir = r.WorkingDir
+	}
+	if r.Timeout > 0 {
+		f.Timeout = r.Timeout
+	}
+	if r.Authentication != nil {
+		f.Authentication = r.Authentication
+	}
+	if r.DataCoordinates != nil {
+		f.DataCoordinates = r.DataCoordinates
+	}
+	return f
+}
+
+// RunAnalysis analyzes the test cases of a payload like analyze-test-case does, for programs that want the result
+// rather than the junit.  Unlike the command, checkers failing is not an error: it is what AnalysisResult.Passed
+// tells.  The outputs of the command are still written to the working dir.
+func RunAnalysis(ctx context.Context, request AnalysisRequest) (*AnalysisResult, error) {
+	f := request.toFlags()
+	if err := f.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid analysis request: %w", err)
+	}
+	o, err := f.ToOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer o.pushMetrics(ctx)
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+	return o.analyze(ctx)
+}
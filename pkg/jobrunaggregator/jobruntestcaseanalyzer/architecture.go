@@ -0,0 +1,126 @@
+package jobruntestcaseanalyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+const defaultArchitecture = "amd64"
+
+// jobArchitectures maps job names to the architecture they run on.  The checkers are created before the jobs are
+// located, so it is filled in later by GetRelatedJobRuns.
+type jobArchitectures struct {
+	lock      sync.RWMutex
+	byJobName map[string]string
+}
+
+func newJobArchitectures() *jobArchitectures {
+	return &jobArchitectures{byJobName: map[string]string{}}
+}
+
+func (a *jobArchitectures) record(jobs []jobrunaggregatorapi.JobRowWithVariants) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for _, job := range jobs {
+		a.byJobName[job.JobName] = jobArchitecture(job)
+	}
+}
+
+func (a *jobArchitectures) architectureOf(jobName string) string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	if architecture, ok := a.byJobName[jobName]; ok {
+		return architecture
+	}
+	return architectureFromJobName(jobName)
+}
+
+func (a *jobArchitectures) all() sets.Set[string] {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	ret := sets.New[string]()
+	for _, architecture := range a.byJobName {
+		ret.Insert(architecture)
+	}
+	return ret
+}
+
+// jobArchitecture returns the architecture of the Jobs table, or guesses it from the name of the job for the
+// static job runs and PR payloads which only know the job name
+func jobArchitecture(job jobrunaggregatorapi.JobRowWithVariants) string {
+	if len(job.Architecture) > 0 {
+		return job.Architecture
+	}
+	return architectureFromJobName(job.JobName)
+}
+
+func architectureFromJobName(jobName string) string {
+	for _, architecture := range []string{"arm64", "ppc64le", "s390x", "multi"} {
+		if strings.Contains(jobName, "-"+architecture) {
+			return architecture
+		}
+	}
+	return defaultArchitecture
+}
+
+// perArchitectureTestCaseChecker requires the minimum number of passes independently for every architecture, so
+// that a set of jobs dominated by amd64 can't satisfy the requirement on its own for a multi payload.
+type perArchitectureTestCaseChecker struct {
+	checker       minimumRequiredPassesTestCaseChecker
+	architectures *jobArchitectures
+}
+
+func (r perArchitectureTestCaseChecker) String() string {
+	return r.checker.String()
+}
+
+func (r perArchitectureTestCaseChecker) CheckTestCase(ctx context.Context, jobRunJunits map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) *junit.TestSuite {
+	jobRunJunitsByArchitecture := map[string]map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{}
+	// architectures whose jobs produced no job runs still have to meet the minimum
+	for architecture := range r.architectures.all() {
+		jobRunJunitsByArchitecture[architecture] = map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{}
+	}
+	for jobRun, testSuites := range jobRunJunits {
+		architecture := r.architectures.architectureOf(jobRun.GetJobName())
+		if _, ok := jobRunJunitsByArchitecture[architecture]; !ok {
+			jobRunJunitsByArchitecture[architecture] = map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{}
+		}
+		jobRunJunitsByArchitecture[architecture][jobRun] = testSuites
+	}
+	if len(jobRunJunitsByArchitecture) == 0 {
+		return r.checker.CheckTestCase(ctx, jobRunJunits)
+	}
+
+	architectures := make([]string, 0, len(jobRunJunitsByArchitecture))
+	for architecture := range jobRunJunitsByArchitecture {
+		architectures = append(architectures, architecture)
+	}
+	sort.Strings(architectures)
+
+	topSuite := &junit.TestSuite{
+		Name:      "minimum-required-passes-per-architecture-checker",
+		TestCases: []*junit.TestCase{},
+	}
+	for _, architecture := range architectures {
+		checker := r.checker
+		checker.testNameSuffix = strings.TrimSpace(fmt.Sprintf("%s architecture:%s", checker.testNameSuffix, architecture))
+		checker.architecture = architecture
+		architectureSuite := checker.CheckTestCase(ctx, jobRunJunitsByArchitecture[architecture])
+		if architectureSuite == nil {
+			return nil
+		}
+		architectureSuite.Name = fmt.Sprintf("architecture %s", architecture)
+		topSuite.Children = append(topSuite.Children, architectureSuite)
+	}
+	jobrunaggregatorlib.UpdateTestCountsInSuite(topSuite)
+	return topSuite
+}
@@ -0,0 +1,127 @@
+package jobruntestcaseanalyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+)
+
+const (
+	autoMinimumSuccessfulTestCount = "auto"
+	// autoMinimumLookback is how far back job runs are considered to know how often a job succeeds
+	autoMinimumLookback = 14 * 24 * time.Hour
+	// autoMinimumExpectedPassesFraction of the passes expected from history are required, so that a payload isn't
+	// rejected for an unlucky draw of flaky job runs.
+	autoMinimumExpectedPassesFraction = 0.5
+)
+
+// minimumSuccessfulCountValue is --minimum-successful-count, either a number or auto.
+type minimumSuccessfulCountValue struct {
+	count *int
+	auto  *bool
+}
+
+func (v *minimumSuccessfulCountValue) String() string {
+	if v.auto != nil && *v.auto {
+		return autoMinimumSuccessfulTestCount
+	}
+	if v.count == nil {
+		return ""
+	}
+	return strconv.Itoa(*v.count)
+}
+
+func (v *minimumSuccessfulCountValue) Set(value string) error {
+	if value == autoMinimumSuccessfulTestCount {
+		*v.auto = true
+		return nil
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("must be a number or %s", autoMinimumSuccessfulTestCount)
+	}
+	*v.count = count
+	*v.auto = false
+	return nil
+}
+
+func (v *minimumSuccessfulCountValue) Type() string {
+	return "int|auto"
+}
+
+// autoRequiredPasses derives the minimum number of passes from how often the selected jobs succeeded recently, so
+// that the minimum follows the set of jobs matching the variants as jobs are added and removed.  The checkers are
+// created before the jobs are located, so it is filled in later by GetRelatedJobRuns.
+type autoRequiredPasses struct {
+	ciDataClient jobrunaggregatorlib.CIDataClient
+	// architectures is only set when the minimum passes are required per architecture
+	architectures *jobArchitectures
+	now           func() time.Time
+
+	lock              sync.RWMutex
+	passRateByJobName map[string]float64
+}
+
+func newAutoRequiredPasses(ciDataClient jobrunaggregatorlib.CIDataClient, architectures *jobArchitectures) *autoRequiredPasses {
+	return &autoRequiredPasses{
+		ciDataClient:      ciDataClient,
+		architectures:     architectures,
+		now:               time.Now,
+		passRateByJobName: map[string]float64{},
+	}
+}
+
+func (a *autoRequiredPasses) record(ctx context.Context, jobs []jobrunaggregatorapi.JobRowWithVariants) error {
+	jobNames := []string{}
+	for _, job := range jobs {
+		jobNames = append(jobNames, job.JobName)
+	}
+	statistics, err := a.ciDataClient.ListJobRunSuccessStatistics(ctx, jobNames, a.now().Add(-autoMinimumLookback))
+	if err != nil {
+		return fmt.Errorf("failed to get job run success statistics: %w", err)
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	// jobs without finished runs during the lookback aren't expected to pass
+	for _, jobName := range jobNames {
+		a.passRateByJobName[jobName] = 0
+	}
+	for _, row := range statistics {
+		if row.JobRuns > 0 {
+			a.passRateByJobName[row.JobName] = float64(row.SuccessfulJobRuns) / float64(row.JobRuns)
+		}
+	}
+	logrus.Infof("minimum successful count derived from history is %d", a.requiredPassesLocked(""))
+	return nil
+}
+
+// requiredPasses returns the minimum for the jobs of the architecture, or for all jobs when architecture is empty.
+func (a *autoRequiredPasses) requiredPasses(architecture string) int {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.requiredPassesLocked(architecture)
+}
+
+func (a *autoRequiredPasses) requiredPassesLocked(architecture string) int {
+	expectedPasses := 0.0
+	for jobName, passRate := range a.passRateByJobName {
+		if len(architecture) > 0 && a.architectures != nil && a.architectures.architectureOf(jobName) != architecture {
+			continue
+		}
+		expectedPasses += passRate
+	}
+	required := int(math.Floor(expectedPasses * autoMinimumExpectedPassesFraction))
+	if required < defaultMinimumSuccessfulTestCount {
+		return defaultMinimumSuccessfulTestCount
+	}
+	return required
+}
@@ -3,6 +3,8 @@ package jobruntestcaseanalyzer
 import (
 	"context"
 	"fmt"
+	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	prowjobclientset "k8s.io/test-infra/prow/client/clientset/versioned"
 
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
 )
 
@@ -32,13 +35,36 @@ var (
 		"ovirt":   sets.Empty{},
 		"vsphere": sets.Empty{},
 	}
+	knownArchitectures   = sets.New[string]("amd64", "arm64", "multi", "ppc64le", "s390x")
 	knownNetworks        = sets.Set[string]{"ovn": sets.Empty{}, "sdn": sets.Empty{}}
 	knownInfrastructures = sets.Set[string]{"upi": sets.Empty{}, "ipi": sets.Empty{}}
+	// releasePattern matches the releases of the jobs table, like 4.16
+	releasePattern = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
 )
 
 type jobGCSPrefix struct {
 	jobName   string
 	gcsPrefix string
+	// gcsBucket is the bucket the job uploads to, empty for the bucket of the command
+	gcsBucket string
+}
+
+// gcsBucketURLScheme prefixes GCS prefixes located in another bucket, like gs://qe-private-deck/logs/some-job
+const gcsBucketURLScheme = "gs://"
+
+func (p jobGCSPrefix) String() string {
+	if len(p.gcsBucket) == 0 {
+		return fmt.Sprintf("%s=%s", p.jobName, p.gcsPrefix)
+	}
+	return fmt.Sprintf("%s=%s%s/%s", p.jobName, gcsBucketURLScheme, p.gcsBucket, p.gcsPrefix)
+}
+
+// bucketOrDefault returns the bucket of the job, or defaultBucket when the job uploads to the bucket of the command
+func (p jobGCSPrefix) bucketOrDefault(defaultBucket string) string {
+	if len(p.gcsBucket) == 0 {
+		return defaultBucket
+	}
+	return p.gcsBucket
 }
 
 type jobGCSPrefixSlice struct {
@@ -51,7 +77,7 @@ func (s *jobGCSPrefixSlice) String() string {
 	}
 	var jobPairs []string
 	for _, value := range *s.values {
-		jobPairs = append(jobPairs, fmt.Sprintf("%s=%s", value.jobName, value.gcsPrefix))
+		jobPairs = append(jobPairs, value.String())
 	}
 	return strings.Join(jobPairs, ",")
 }
@@ -66,11 +92,35 @@ func (s *jobGCSPrefixSlice) Set(value string) error {
 		return fmt.Errorf("need at least one GCS prefix configured with explicit-gcs-prefixes")
 	}
 	for _, jobPair := range jobPairs {
+		prefix := jobGCSPrefix{}
 		jStrs := strings.Split(jobPair, "=")
-		if len(jStrs) != 2 {
+		switch len(jStrs) {
+		case 1:
+			// the job name is read from the prefix itself
+			prefix.gcsPrefix = jStrs[0]
+		case 2:
+			prefix.jobName, prefix.gcsPrefix = jStrs[0], jStrs[1]
+		default:
 			return fmt.Errorf("GCS prefix should consist of job name and GCS prefix separated by '='")
 		}
-		*s.values = append(*s.values, jobGCSPrefix{jobName: jStrs[0], gcsPrefix: jStrs[1]})
+		if bucketAndPrefix, ok := strings.CutPrefix(prefix.gcsPrefix, gcsBucketURLScheme); ok {
+			bucket, gcsPrefix, found := strings.Cut(bucketAndPrefix, "/")
+			if !found || len(bucket) == 0 || len(gcsPrefix) == 0 {
+				return fmt.Errorf("GCS prefix %q should consist of a bucket and a prefix, like %sbucket/logs/job", prefix.gcsPrefix, gcsBucketURLScheme)
+			}
+			prefix.gcsBucket, prefix.gcsPrefix = bucket, gcsPrefix
+		}
+		if len(prefix.jobName) == 0 {
+			location, err := jobrunaggregatorapi.ParseJobRunGCSLocation(prefix.gcsPrefix)
+			if err != nil {
+				return fmt.Errorf("GCS prefix without job name should be a logs/ or pr-logs/ prefix: %w", err)
+			}
+			if len(location.JobRunID) > 0 {
+				return fmt.Errorf("GCS prefix %q should be the prefix of a job rather than of one of its job runs", prefix.gcsPrefix)
+			}
+			prefix.jobName = location.JobName
+		}
+		*s.values = append(*s.values, prefix)
 	}
 	return nil
 }
@@ -79,39 +129,135 @@ func (s *jobGCSPrefixSlice) Type() string {
 	return "jobGCSPrefixSlice"
 }
 
+// regexpSlice compiles every value as it is set so that an invalid expression fails the flag parsing
+type regexpSlice struct {
+	values *[]*regexp.Regexp
+}
+
+func (s *regexpSlice) String() string {
+	var expressions []string
+	for _, value := range *s.values {
+		expressions = append(expressions, value.String())
+	}
+	return strings.Join(expressions, ",")
+}
+
+func (s *regexpSlice) Set(value string) error {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return fmt.Errorf("invalid regular expression %q: %w", value, err)
+	}
+	*s.values = append(*s.values, re)
+	return nil
+}
+
+func (s *regexpSlice) Type() string {
+	return "regexpSlice"
+}
+
 type JobRunsTestCaseAnalyzerFlags struct {
 	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
+	QueryCost       *jobrunaggregatorlib.QueryCostFlags
 	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
 
-	TestGroup                   string
-	WorkingDir                  string
-	PayloadTag                  string
+	TestGroup  string
+	WorkingDir string
+	// PayloadTags are analyzed together when there are several of them, each in its own suite
+	PayloadTags                 []string
 	Timeout                     time.Duration
 	EstimatedJobStartTimeString string
 	Platform                    string
+	Architecture                string
 	Infrastructure              string
 	Network                     string
-	MinimumSuccessfulTestCount  int
-	PayloadInvocationID         string
-	JobGCSPrefixes              []jobGCSPrefix
-	ExcludeJobNames             []string
-	IncludeJobNames             []string
-	JobStateQuerySource         string
+	// Variants are <platform>,<network>,<infrastructure> tuples analyzed in a single invocation, each in its own suite
+	Variants                   []string
+	MinimumSuccessfulTestCount int
+	// MinimumSuccessfulTestCountAuto derives MinimumSuccessfulTestCount from history
+	MinimumSuccessfulTestCountAuto bool
+	MinimumSuccessfulPerArch       bool
+	MaximumFailureCount            int
+	ZeroToleranceTests             []string
+	ReportOnlyTests                []string
+	PayloadInvocationID            string
+	JobGCSPrefixes                 []jobGCSPrefix
+	ExcludeJobNames                []string
+	ExcludeJobRegexes              []*regexp.Regexp
+	IncludeJobNames                []string
+	IncludeExactJobNames           []string
+	OptionalJobNames               []string
+	EvidenceGCSLocation            string
+	JobStateQuerySource            string
+	ExcludeNeverPassingDays        int
+	AdaptiveWait                   bool
+	SampleSize                     int
+	FindJobRunsMaxAttempts         int
+	FindJobRunsBackoff             string
+	FindJobRunsInterval            time.Duration
+	FindJobRunsAttemptTimeout      time.Duration
+	JUnitFetchTimeout              time.Duration
+	SampleSeed                     int64
 
 	StaticJobRunIdentifierPath string
 	StaticJobRunIdentifierJSON string
 	GCSBucket                  string
+
+	GateOverridePath string
+	GateOverrideJSON string
+
+	TestOwnershipFile string
+	TestRenameFile    string
+
+	// the Record and Cache flags write to the dataset, which local runs must not do unless asked to
+	RecordTestCaseAnalysis bool
+	RecordGateResults      bool
+	CacheLocatedJobRuns    bool
+	// ListJobRunsFromBigQuery lists the job runs from the JobRuns table instead of the GCS bucket
+	ListJobRunsFromBigQuery bool
+
+	// StopWaitingAtMinimumSuccessfulCount ends the wait once the finished job runs pass
+	StopWaitingAtMinimumSuccessfulCount bool
+	// UnfinishedPolicy is one of skip, fail or wait
+	UnfinishedPolicy string
+	// Release, like 4.16, restricts the analysis to the jobs of a single release
+	Release string
+
+	TopSuiteName                   string
+	MinimumRequiredPassesSuiteName string
+
+	Notifier         *jobrunaggregatorlib.NotifierFlags
+	JunitParseBudget *jobrunaggregatorlib.JunitParseBudgetFlags
+	ArtifactCache    *jobrunaggregatorlib.ArtifactCacheFlags
+	QueryCache       *jobrunaggregatorlib.QueryCacheFlags
+	Metrics          *jobrunaggregatorlib.MetricsFlags
+	JobSearchWindow  *jobrunaggregatorlib.JobSearchWindowFlags
 }
 
 func NewJobRunsTestCaseAnalyzerFlags() *JobRunsTestCaseAnalyzerFlags {
 	return &JobRunsTestCaseAnalyzerFlags{
-		DataCoordinates: jobrunaggregatorlib.NewBigQueryDataCoordinates(),
-		Authentication:  jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+		DataCoordinates:  jobrunaggregatorlib.NewBigQueryDataCoordinates(),
+		QueryCost:        jobrunaggregatorlib.NewQueryCostFlags(),
+		Authentication:   jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+		Notifier:         jobrunaggregatorlib.NewNotifierFlags(),
+		JunitParseBudget: jobrunaggregatorlib.NewJunitParseBudgetFlags(),
+		ArtifactCache:    jobrunaggregatorlib.NewArtifactCacheFlags(),
+		QueryCache:       jobrunaggregatorlib.NewQueryCacheFlags(),
+		Metrics:          jobrunaggregatorlib.NewMetricsFlags(),
+		JobSearchWindow:  jobrunaggregatorlib.NewJobSearchWindowFlags(),
 
 		WorkingDir:                  "test-case-analyzer-working-dir",
 		EstimatedJobStartTimeString: time.Now().Format(kubeTimeSerializationLayout),
 		Timeout:                     3*time.Hour + 30*time.Minute,
+		FindJobRunsMaxAttempts:      20,
+		FindJobRunsBackoff:          retryBackoffFixed,
+		FindJobRunsInterval:         time.Minute,
+		JUnitFetchTimeout:           10 * time.Minute,
 		MinimumSuccessfulTestCount:  defaultMinimumSuccessfulTestCount,
+		MaximumFailureCount:         -1,
+		UnfinishedPolicy:            unfinishedPolicySkip,
+
+		TopSuiteName:                   defaultTopSuiteName,
+		MinimumRequiredPassesSuiteName: minimumRequiredPassesSuiteName,
 	}
 }
 
@@ -119,25 +265,58 @@ const kubeTimeSerializationLayout = time.RFC3339
 
 func (f *JobRunsTestCaseAnalyzerFlags) BindFlags(fs *pflag.FlagSet) {
 	f.DataCoordinates.BindFlags(fs)
+	f.QueryCost.BindFlags(fs)
 	f.Authentication.BindFlags(fs)
-
-	fs.StringVar(&f.TestGroup, "test-group", "install", "Test group to analyze, like install or overall")
-	fs.StringVar(&f.PayloadTag, "payload-tag", f.PayloadTag, "The release controller payload tag to analyze test case status, like 4.9.0-0.ci-2021-07-19-185802")
-	fs.StringVar(&f.EstimatedJobStartTimeString, "job-start-time", f.EstimatedJobStartTimeString, fmt.Sprintf("Start time in RFC822Z: %s. This defines the search window for job runs. Only job runs whose start time is in between job-start-time - %s and job-start-time + %s will be included.", kubeTimeSerializationLayout, jobrunaggregatorlib.JobSearchWindowStartOffset, jobrunaggregatorlib.JobSearchWindowEndOffset))
+	f.Notifier.BindFlags(fs)
+	f.JunitParseBudget.BindFlags(fs)
+	f.ArtifactCache.BindFlags(fs)
+	f.QueryCache.BindFlags(fs)
+	f.Metrics.BindFlags(fs)
+	f.JobSearchWindow.BindFlags(fs)
+
+	fs.StringVar(&f.TestGroup, "test-group", "install", "Test group to analyze, like install, overall or conformance.  Multiple comma-separated test groups are checked concurrently against the same job runs")
+	fs.StringArrayVar(&f.PayloadTags, "payload-tag", f.PayloadTags, "The release controller payload tag to analyze test case status, like 4.9.0-0.ci-2021-07-19-185802.  The flag can be specified multiple times, like for the last three nightlies, to analyze every payload in its own suite of a single junit.  The job runs of each payload are then searched around the creation time ending its tag instead of --job-start-time")
+	fs.StringVar(&f.EstimatedJobStartTimeString, "job-start-time", f.EstimatedJobStartTimeString, fmt.Sprintf("Start time in RFC822Z: %s. This defines the search window for job runs. Only job runs whose start time is in between job-start-time - --job-search-window-start-offset and job-start-time + --job-search-window-end-offset will be included.", kubeTimeSerializationLayout))
 	fs.StringVar(&f.Platform, "platform", f.Platform, "The platform used to narrow down a subset of the jobs to analyze, ex: aws|gcp|azure|vsphere")
+	fs.StringVar(&f.Architecture, "architecture", f.Architecture, fmt.Sprintf("The architecture used to narrow down a subset of the jobs to analyze, ex: %s", strings.Join(sets.List(knownArchitectures), "|")))
 	fs.StringVar(&f.Infrastructure, "infrastructure", f.Infrastructure, "The infrastructure used to narrow down a subset of the jobs to analyze, ex: upi|ipi")
 	fs.StringVar(&f.Network, "network", f.Network, "The network used to narrow down a subset of the jobs to analyze, ex: sdn|ovn")
-	fs.IntVar(&f.MinimumSuccessfulTestCount, "minimum-successful-count", defaultMinimumSuccessfulTestCount, "minimum number of successful test counts among jobs meeting criteria")
+	fs.StringVar(&f.Release, "release", f.Release, "The release used to narrow down a subset of the jobs to analyze, like 4.16.  Jobs are selected by the release the jobs table records for them, not by their name.  Applied only when --explicit-gcs-prefixes is not specified")
+	fs.StringArrayVar(&f.Variants, "variant", f.Variants, "A <platform>,<network>,<infrastructure> tuple, like aws,ovn,ipi, whose jobs are analyzed apart from the jobs of the other variants.  Empty values match any job.  The flag can be specified multiple times instead of --platform, --network and --infrastructure to gate several variants with a single invocation")
+	fs.Var(&minimumSuccessfulCountValue{count: &f.MinimumSuccessfulTestCount, auto: &f.MinimumSuccessfulTestCountAuto}, "minimum-successful-count", fmt.Sprintf("minimum number of successful test counts among jobs meeting criteria, or %s to require half of the passes expected from how often the jobs succeeded in the last %s", autoMinimumSuccessfulTestCount, autoMinimumLookback))
+	fs.BoolVar(&f.MinimumSuccessfulPerArch, "minimum-successful-count-per-architecture", f.MinimumSuccessfulPerArch, "require --minimum-successful-count independently for the jobs of every architecture, like for multi payloads, instead of across all jobs")
+	fs.IntVar(&f.MaximumFailureCount, "maximum-failure-count", f.MaximumFailureCount, "When not negative, the maximum number of job runs the tests of the test group may fail in among jobs meeting criteria, whatever the number of passes")
+	fs.StringArrayVar(&f.ReportOnlyTests, "report-only-test", f.ReportOnlyTests, "A test whose checkers run in report-only mode: their test cases are emitted, but their failures don't fail the analysis.  Meant to burn in new gate criteria.  It must be one of the --test-group values or a --zero-tolerance-test, in the same format, and applies to every test case of the checkers gating on it.  The flag can be specified multiple times")
+	fs.StringArrayVar(&f.ZeroToleranceTests, "zero-tolerance-test", f.ZeroToleranceTests, fmt.Sprintf("A test that must not fail in any job run, whatever the number of passes.  Either a test group, <suite>=<test name>, or <suite>=~<test name> where the suites and the test name are regular expressions, with nested suites separated by %s.  The flag can be specified multiple times", jobrunaggregatorlib.TestSuitesSeparator))
 	usage := fmt.Sprintf("mutually exclusive to --payload-tag.  Matches the .label[%s] on the prowjob, which is a UID", jobrunaggregatorlib.ProwJobPayloadInvocationIDLabel)
 	fs.StringVar(&f.PayloadInvocationID, "payload-invocation-id", f.PayloadInvocationID, usage)
 
 	fs.StringVar(&f.WorkingDir, "working-dir", f.WorkingDir, "The directory to store caches, output, and the like.")
 	fs.DurationVar(&f.Timeout, "timeout", f.Timeout, "Time to wait for analyzing job to complete.")
-	fs.Var(&jobGCSPrefixSlice{&f.JobGCSPrefixes}, "explicit-gcs-prefixes", "a list of gcs prefixes for jobs created for payload. Only used by per PR payload promotion jobs. The format is comma-separated elements, each consisting of job name and gcs prefix separated by =, like openshift-machine-config-operator=3028-ci-4.11-e2e-aws-ovn-upgrade~logs/openshift-machine-config-operator-3028-ci-4.11-e2e-aws-ovn-upgrade")
+	fs.Var(&jobGCSPrefixSlice{&f.JobGCSPrefixes}, "explicit-gcs-prefixes", "a list of gcs prefixes for jobs created for payload. Only used by per PR payload promotion jobs. The format is comma-separated elements, each consisting of job name and gcs prefix separated by =, like openshift-machine-config-operator=3028-ci-4.11-e2e-aws-ovn-upgrade~logs/openshift-machine-config-operator-3028-ci-4.11-e2e-aws-ovn-upgrade. Prefixes of jobs uploading to another bucket start with gs://<bucket>/. The job name can be omitted for logs/<job> and pr-logs/pull/<org_repo>/<pr>/<job> prefixes")
 
 	fs.StringArrayVar(&f.ExcludeJobNames, "exclude-job-names", f.ExcludeJobNames, "Applied only when --explicit-gcs-prefixes is not specified.  The flag can be specified multiple times to create a list of substrings used to filter JobNames from the analysis")
+	fs.Var(&regexpSlice{&f.ExcludeJobRegexes}, "exclude-job-regex", "Applied only when --explicit-gcs-prefixes is not specified.  The flag can be specified multiple times to create a list of regular expressions, like '.*(ipv6|proxy)-upgrade$', used to filter JobNames from the analysis")
+	fs.StringVar(&f.EvidenceGCSLocation, "evidence-gcs-location", f.EvidenceGCSLocation, "When set, like gs://<bucket>/<prefix>, an evidence bundle with the junit failures, prowjob and build log excerpt of every failed job run is uploaded there for every failed test case")
+	fs.BoolVar(&f.RecordTestCaseAnalysis, "record-test-case-analysis", f.RecordTestCaseAnalysis, "Record the verdict and the job run counts of every checker in the TestCaseAnalysis table")
+	fs.BoolVar(&f.RecordGateResults, "record-gate-results", f.RecordGateResults, "Record the verdict of every test case of the analysis in the GateResults table")
+	fs.BoolVar(&f.CacheLocatedJobRuns, "cache-located-job-runs", f.CacheLocatedJobRuns, "Reuse the job runs of the payload located by earlier analyzers, and record the ones this analysis locates, in the LocatedJobRuns table")
+	fs.BoolVar(&f.ListJobRunsFromBigQuery, "list-job-runs-from-bigquery", f.ListJobRunsFromBigQuery, "List the job runs of the jobs from the JobRuns table the loaders maintain instead of walking the GCS bucket, which is much faster for jobs with many job runs.  The table only has the job runs the loaders uploaded once they finished, so this is meant for analyzing past windows: job runs still running are not found")
+	fs.StringArrayVar(&f.OptionalJobNames, "optional-job-name", f.OptionalJobNames, "A job whose runs are reported on, but don't decide whether the analysis fails, like an informing job.  Jobs marked optional in the jobs table are optional as well.  The flag can be specified multiple times")
 	fs.StringArrayVar(&f.IncludeJobNames, "include-job-names", f.IncludeJobNames, "Applied only when --explicit-gcs-prefixes is not specified.  The flag can be specified multiple times to create a list of substrings to include in matching JobNames for analysis")
+	fs.StringArrayVar(&f.IncludeExactJobNames, "include-exact-job-names", f.IncludeExactJobNames, "Applied only when --explicit-gcs-prefixes is not specified.  The flag can be specified multiple times to create a list of the only job names to analyze, like to pilot the analysis on a handful of jobs.  Unlike --include-job-names, the names must match exactly")
+	fs.IntVar(&f.ExcludeNeverPassingDays, "exclude-jobs-without-success-days", f.ExcludeNeverPassingDays, "Applied only when --explicit-gcs-prefixes is not specified.  When greater than zero, jobs that ran but never succeeded during this many days are excluded from the analysis and reported separately")
 	fs.StringVar(&f.JobStateQuerySource, "query-source", jobrunaggregatorlib.JobStateQuerySourceBigQuery, "The source from which job states are found. It is either bigquery or cluster")
+	fs.IntVar(&f.FindJobRunsMaxAttempts, "find-job-runs-max-attempts", f.FindJobRunsMaxAttempts, "The number of times searching for the job runs of a job is attempted before giving up on the job")
+	fs.StringVar(&f.FindJobRunsBackoff, "find-job-runs-backoff", f.FindJobRunsBackoff, fmt.Sprintf("How long to wait between attempts to search for job runs, %s waits --find-job-runs-interval, %s doubles it with jitter after every attempt, up to %s", retryBackoffFixed, retryBackoffExponential, exponentialRetryCap))
+	fs.DurationVar(&f.FindJobRunsInterval, "find-job-runs-interval", f.FindJobRunsInterval, "The wait after the first failed attempt to search for job runs")
+	fs.DurationVar(&f.FindJobRunsAttemptTimeout, "find-job-runs-attempt-timeout", f.FindJobRunsAttemptTimeout, "When set, an attempt to search for job runs taking longer is abandoned and retried")
+	fs.DurationVar(&f.JUnitFetchTimeout, "junit-fetch-timeout", f.JUnitFetchTimeout, "The longest the junit of a single job run is read for.  Job runs whose junit takes longer are reported as unreadable and the analysis goes on without them.  Zero waits until the analysis times out")
+	fs.IntVar(&f.SampleSize, "sample-size", f.SampleSize, "When greater than zero, randomly sample at most this many job runs per job to bound the cost of analyzing very large payloads")
+	fs.Int64Var(&f.SampleSeed, "sample-seed", f.SampleSeed, "The seed used with --sample-size, to reproduce a previous analysis.  A random seed is used when not set, it is recorded in the junit either way")
+	fs.BoolVar(&f.StopWaitingAtMinimumSuccessfulCount, "stop-waiting-at-minimum-successful-count", f.StopWaitingAtMinimumSuccessfulCount, "Stop waiting for the unfinished job runs as soon as the finished ones meet --minimum-successful-count.  Cannot be specified with checkers that later job runs could fail, like --zero-tolerance-test or --maximum-failure-count.  Only applies to --query-source=bigquery")
+	fs.StringVar(&f.UnfinishedPolicy, "unfinished-policy", f.UnfinishedPolicy, fmt.Sprintf("What to do with job runs still unfinished once waiting is over: %s checks the junit they have so far, tests they didn't run are skips, %s counts every test of theirs as failed, %s waits for them until %s before --timeout, then skips", unfinishedPolicySkip, unfinishedPolicyFail, unfinishedPolicyWait, unfinishedWaitMargin))
+	fs.BoolVar(&f.AdaptiveWait, "adaptive-wait", f.AdaptiveWait, "Stop waiting for the unfinished runs of each job based on how long runs of that job historically take instead of a fixed time.  Only applies to --query-source=bigquery")
 
 	// optional for local use or potentially gangway results
 	fs.StringVar(&f.StaticJobRunIdentifierPath, "static-run-info-path", f.StaticJobRunIdentifierPath, "The optional path to a file containing JSON formatted JobRunIdentifier array used for aggregated analysis")
@@ -145,33 +324,17 @@ func (f *JobRunsTestCaseAnalyzerFlags) BindFlags(fs *pflag.FlagSet) {
 
 	fs.StringVar(&f.GCSBucket, "google-storage-bucket", "test-platform-results", "The optional GCS Bucket holding test artifacts")
 
+	fs.StringVar(&f.GateOverridePath, "gate-override-path", f.GateOverridePath, "The optional path to a file (like a mounted ConfigMap key) containing a JSON formatted GateOverride used to force-accept failed test cases")
+	fs.StringVar(&f.GateOverrideJSON, "gate-override-json", f.GateOverrideJSON, "The optional JSON formatted GateOverride used to force-accept failed test cases")
+	fs.StringVar(&f.TestOwnershipFile, "test-ownership-file", f.TestOwnershipFile, "The optional path to a YAML list of {pattern, component, team} used to name the owner of failed test case tests")
+	fs.StringVar(&f.TopSuiteName, "top-level-suite-name", f.TopSuiteName, "The name of the junit suite of the analysis, like to tell several analyses of the same payload apart in Sippy")
+	fs.StringVar(&f.MinimumRequiredPassesSuiteName, "minimum-required-passes-suite-name", f.MinimumRequiredPassesSuiteName, "The name of the junit suite of the --minimum-successful-count checker")
+	fs.StringVar(&f.TestRenameFile, "test-rename-file", f.TestRenameFile, "The optional path to a YAML list of {from, to} test names. Old names in junit and in historical data are replaced by the new ones")
 }
 
 func NewJobRunsTestCaseAnalyzerCommand() *cobra.Command {
 	f := NewJobRunsTestCaseAnalyzerFlags()
 
-	/* Example runs for release controller:
-	 ./job-run-aggregator analyze-test-case
-	    --google-service-account-credential-file=credential.json
-	    --test-group=install
-	    --platform=aws
-	    --network=sdn
-	    --infrastructure=ipi
-	    --payload-tag=4.11.0-0.nightly-2022-04-28-102605
-	    --job-start-time=2022-04-28T10:28:48Z
-	    --minimum-successful-count=10
-		--exclude-job-names=upgrade
-		--exclude-job-names=ipv6
-
-	Example runs for PR based paylaod:
-	 ./job-run-aggregator analyze-test-case
-	    --google-service-account-credential-file=credential.json
-	    --test-group=install
-	    --payload-invocation-id=09406e30ea661e228c17120f28eff3c6
-	    --job-start-time=2022-03-18T13:10:20Z
-	    --minimum-successful-count=10
-	    --explicit-gcs-prefixes=periodic-ci-openshift-release-master-ci-4.11-e2e-aws-ovn-upgrade=logs/openshift-machine-config-operator-3028-ci-4.11-e2e-aws-ovn-upgrade,periodic-ci-openshift-release-master-ci-4.11-upgrade-from-stable-4.10-e2e-aws-ovn-upgrade=/logs/openshift-machine-config-operator-3028-ci-4.11-upgrade-from-stable-4.10-e2e-aws-ovn-upgrade
-	*/
 	cmd := &cobra.Command{
 		Use: "analyze-test-case",
 		Long: `Analyze status of a test case of certain group to make sure they meet minimum criteria specified.
@@ -184,23 +347,60 @@ also be a subset of jobs started by PR payload command. For nightly or CI payloa
 is used to select jobs that belong to the particular payload run. For PR payload jobs, we use 
 payload-invocation-id to select the jobs.
 
-Each group is matched to a subset of known tests. Currently only 'install' group is supported. Other 
-groups like 'upgrade' can be added in the future.
+Each group is matched to a subset of known tests: 'install', 'overall', 'upgrade' and 'conformance', which
+requires successful full runs of the openshift-tests conformance suite.
 `,
 		SilenceUsage: true,
 
 		Example: `To make sure there are at least 10 successful installs for all aws sdn ipi jobs for
 payload 4.11.0-0.nightly-2022-04-28-102605, run this command:
 
-./job-run-aggregator analyze-test-case
---google-service-account-credential-file=credential.json
---test-group=install
---platform=aws
---network=sdn
---infrastructure=ipi
---payload-tag=4.11.0-0.nightly-2022-04-28-102605
---job-start-time=2022-04-28T10:28:48Z
---minimum-successful-count=10
+./job-run-aggregator analyze-test-case \
+  --google-service-account-credential-file=credential.json \
+  --test-group=install \
+  --platform=aws \
+  --network=sdn \
+  --infrastructure=ipi \
+  --payload-tag=4.11.0-0.nightly-2022-04-28-102605 \
+  --job-start-time=2022-04-28T10:28:48Z \
+  --minimum-successful-count=10
+
+To analyze the jobs of a PR payload, select them by invocation id and give their GCS prefixes:
+
+./job-run-aggregator analyze-test-case \
+  --google-service-account-credential-file=credential.json \
+  --test-group=install \
+  --payload-invocation-id=09406e30ea661e228c17120f28eff3c6 \
+  --job-start-time=2022-03-18T13:10:20Z \
+  --minimum-successful-count=10 \
+  --explicit-gcs-prefixes=periodic-ci-openshift-release-master-ci-4.11-e2e-aws-ovn-upgrade=logs/openshift-machine-config-operator-3028-ci-4.11-e2e-aws-ovn-upgrade
+
+To gate several variants at once, each in its own suite, repeat --variant instead of --platform, --network
+and --infrastructure:
+
+./job-run-aggregator analyze-test-case \
+  --google-service-account-credential-file=credential.json \
+  --test-group=install \
+  --variant=aws,ovn,ipi \
+  --variant=gcp,ovn,ipi \
+  --payload-tag=4.11.0-0.nightly-2022-04-28-102605 \
+  --job-start-time=2022-04-28T10:28:48Z \
+  --minimum-successful-count=10
+
+To put the verdict of a payload in the context of the previous ones, repeat --payload-tag.  Every payload is
+analyzed in its own suite of junit-payload-tags-test-case-analysis.xml:
+
+./job-run-aggregator analyze-test-case \
+  --google-service-account-credential-file=credential.json \
+  --test-group=install \
+  --platform=aws \
+  --payload-tag=4.11.0-0.nightly-2022-04-28-102605 \
+  --payload-tag=4.11.0-0.nightly-2022-04-27-150010 \
+  --payload-tag=4.11.0-0.nightly-2022-04-27-031533 \
+  --minimum-successful-count=10
+
+Values of --platform, --network, --infrastructure, --test-group and --payload-tag are completed by the
+shell once completions are installed, see "job-run-aggregator completion --help".
 `,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -213,6 +413,8 @@ payload 4.11.0-0.nightly-2022-04-28-102605, run this command:
 			if err != nil {
 				logrus.WithError(err).Fatal("Failed to build runtime options")
 			}
+			// logs are written to stderr, progress is shown along with them
+			o.progress = jobrunaggregatorlib.NewProgressReporter(os.Stderr)
 
 			if err := o.Run(ctx); err != nil {
 				logrus.WithError(err).Fatal("Command failed")
@@ -225,10 +427,29 @@ payload 4.11.0-0.nightly-2022-04-28-102605, run this command:
 	}
 
 	f.BindFlags(cmd.Flags())
+	f.bindFlagCompletions(cmd)
 
 	return cmd
 }
 
+// bindFlagCompletions completes the values of the flags that select the jobs and the tests to analyze.
+func (f *JobRunsTestCaseAnalyzerFlags) bindFlagCompletions(cmd *cobra.Command) {
+	completions := map[string]jobrunaggregatorlib.CompletionFunc{
+		"platform":          jobrunaggregatorlib.CompleteValues(knownPlatforms),
+		"architecture":      jobrunaggregatorlib.CompleteValues(knownArchitectures),
+		"network":           jobrunaggregatorlib.CompleteValues(knownNetworks),
+		"infrastructure":    jobrunaggregatorlib.CompleteValues(knownInfrastructures),
+		"test-group":        jobrunaggregatorlib.CompleteCommaSeparatedValues(sets.KeySet(testIdentifiersByGroup)),
+		"payload-tag":       jobrunaggregatorlib.CompletePayloadTags(f.Authentication, f.DataCoordinates),
+		"unfinished-policy": jobrunaggregatorlib.CompleteValues(knownUnfinishedPolicies),
+	}
+	for flagName, complete := range completions {
+		if err := cmd.RegisterFlagCompletionFunc(flagName, complete); err != nil {
+			logrus.WithError(err).Fatalf("Failed to register the completion of --%s", flagName)
+		}
+	}
+}
+
 // Validate checks to see if the user-input is likely to produce functional runtime options
 func (f *JobRunsTestCaseAnalyzerFlags) Validate() error {
 	if len(f.WorkingDir) == 0 {
@@ -240,23 +461,68 @@ func (f *JobRunsTestCaseAnalyzerFlags) Validate() error {
 	if err := f.DataCoordinates.Validate(); err != nil {
 		return err
 	}
+	if err := f.QueryCost.Validate(); err != nil {
+		return err
+	}
 	if err := f.Authentication.Validate(); err != nil {
 		return err
 	}
+	if err := f.Notifier.Validate(); err != nil {
+		return err
+	}
+	if err := f.JunitParseBudget.Validate(); err != nil {
+		return err
+	}
+	if err := f.ArtifactCache.Validate(); err != nil {
+		return err
+	}
+	if err := f.QueryCache.Validate(); err != nil {
+		return err
+	}
+	if err := f.Metrics.Validate(); err != nil {
+		return err
+	}
+	if err := f.JobSearchWindow.Validate(); err != nil {
+		return err
+	}
 	if f.TestGroup == "" {
 		return fmt.Errorf("test group has to be specified")
 	}
-	if len(f.PayloadTag) > 0 && len(f.PayloadInvocationID) > 0 {
+	if len(f.PayloadTags) > 0 && len(f.PayloadInvocationID) > 0 {
 		return fmt.Errorf("cannot specify both --payload-tag and --payload-invocation-id")
 	}
-	if len(f.PayloadTag) == 0 && len(f.PayloadInvocationID) == 0 {
+	if len(f.PayloadTags) == 0 && len(f.PayloadInvocationID) == 0 {
 		return fmt.Errorf("exactly one of --payload-tag or --payload-invocation-id must be specified")
 	}
+	if len(f.PayloadTags) > 1 {
+		if len(f.StaticJobRunIdentifierPath) > 0 || len(f.StaticJobRunIdentifierJSON) > 0 {
+			return fmt.Errorf("--static-run-info-path and --static-run-info-json can only be specified with a single --payload-tag")
+		}
+		if len(f.GateOverridePath) > 0 || len(f.GateOverrideJSON) > 0 {
+			return fmt.Errorf("--gate-override-path and --gate-override-json can only be specified with a single --payload-tag")
+		}
+		if sets.New(f.PayloadTags...).Len() != len(f.PayloadTags) {
+			return fmt.Errorf("--payload-tag must not be repeated with the same payload tag")
+		}
+		for _, payloadTag := range f.PayloadTags {
+			if _, err := payloadTagCreationTime(payloadTag); err != nil {
+				return fmt.Errorf("invalid --payload-tag: %w", err)
+			}
+		}
+	}
 	if len(f.PayloadInvocationID) > 0 && len(f.JobGCSPrefixes) == 0 {
 		return fmt.Errorf("if --payload-invocation-id is specified, you must specify --explicit-gcs-prefixes")
 	}
-	if len(f.PayloadInvocationID) > 0 && (len(f.Platform) > 0 || len(f.Network) > 0 || len(f.Infrastructure) > 0) {
-		return fmt.Errorf("if --payload-invocation-id is specified, --platform, --network or --infrastructure cannot be specified")
+	if len(f.PayloadInvocationID) > 0 && (len(f.Platform) > 0 || len(f.Architecture) > 0 || len(f.Network) > 0 || len(f.Infrastructure) > 0) {
+		return fmt.Errorf("if --payload-invocation-id is specified, --platform, --architecture, --network or --infrastructure cannot be specified")
+	}
+	if len(f.Variants) > 0 && (len(f.PayloadInvocationID) > 0 || len(f.Platform) > 0 || len(f.Network) > 0 || len(f.Infrastructure) > 0) {
+		return fmt.Errorf("--variant cannot be specified with --payload-invocation-id, --platform, --network or --infrastructure")
+	}
+	for _, variant := range f.Variants {
+		if _, err := parseJobVariant(variant); err != nil {
+			return fmt.Errorf("invalid --variant: %w", err)
+		}
 	}
 
 	if len(f.Platform) > 0 {
@@ -265,12 +531,20 @@ func (f *JobRunsTestCaseAnalyzerFlags) Validate() error {
 		}
 	}
 
+	if len(f.Architecture) > 0 && !knownArchitectures.Has(f.Architecture) {
+		return fmt.Errorf("unknown architecture %s, valid values are: %+q", f.Architecture, sets.List(knownArchitectures))
+	}
+
 	if len(f.Network) > 0 {
 		if _, ok := knownNetworks[f.Network]; !ok {
 			return fmt.Errorf("unknown network %s, valid values are: %+q", f.Network, sets.List(knownNetworks))
 		}
 	}
 
+	if len(f.Release) > 0 && !releasePattern.MatchString(f.Release) {
+		return fmt.Errorf("invalid --release %s, like 4.16", f.Release)
+	}
+
 	if len(f.Infrastructure) > 0 {
 
 		if _, ok := knownInfrastructures[f.Infrastructure]; !ok {
@@ -287,6 +561,71 @@ func (f *JobRunsTestCaseAnalyzerFlags) Validate() error {
 			return fmt.Errorf("unknown query-source %s, valid values are: %+q", f.JobStateQuerySource, sets.List(jobrunaggregatorlib.KnownQuerySources))
 		}
 	}
+	if len(f.GateOverridePath) > 0 && len(f.GateOverrideJSON) > 0 {
+		return fmt.Errorf("cannot specify both --gate-override-path and --gate-override-json")
+	}
+	if f.FindJobRunsMaxAttempts < 1 {
+		return fmt.Errorf("--find-job-runs-max-attempts must be at least 1")
+	}
+	if !knownRetryBackoffs.Has(f.FindJobRunsBackoff) {
+		return fmt.Errorf("unknown --find-job-runs-backoff %s, valid values are: %+q", f.FindJobRunsBackoff, sets.List(knownRetryBackoffs))
+	}
+	if f.FindJobRunsInterval <= 0 {
+		return fmt.Errorf("--find-job-runs-interval must be positive")
+	}
+	if f.FindJobRunsAttemptTimeout < 0 {
+		return fmt.Errorf("--find-job-runs-attempt-timeout must not be negative")
+	}
+	if f.JUnitFetchTimeout < 0 {
+		return fmt.Errorf("--junit-fetch-timeout must not be negative")
+	}
+	if f.SampleSize < 0 {
+		return fmt.Errorf("--sample-size must not be negative")
+	}
+	if f.MaximumFailureCount < -1 {
+		return fmt.Errorf("--maximum-failure-count must be -1 to disable it, or not negative")
+	}
+	for _, zeroToleranceTest := range f.ZeroToleranceTests {
+		if _, err := parseTestIdentifier(zeroToleranceTest); err != nil {
+			return fmt.Errorf("invalid --zero-tolerance-test: %w", err)
+		}
+	}
+	for _, reportOnlyTest := range f.ReportOnlyTests {
+		if _, err := parseTestIdentifier(reportOnlyTest); err != nil {
+			return fmt.Errorf("invalid --report-only-test: %w", err)
+		}
+	}
+	if len(f.EvidenceGCSLocation) > 0 {
+		if _, _, err := jobrunaggregatorlib.ParseGCSLocation(f.EvidenceGCSLocation); err != nil {
+			return fmt.Errorf("invalid --evidence-gcs-location: %w", err)
+		}
+	}
+	if f.StopWaitingAtMinimumSuccessfulCount && (len(f.ZeroToleranceTests) > 0 || f.MaximumFailureCount >= 0) {
+		return fmt.Errorf("--stop-waiting-at-minimum-successful-count cannot be specified with --zero-tolerance-test or --maximum-failure-count, unfinished job runs could still fail them")
+	}
+	for _, suiteName := range []struct {
+		flag  string
+		value string
+	}{
+		{flag: "top-level-suite-name", value: f.TopSuiteName},
+		{flag: "minimum-required-passes-suite-name", value: f.MinimumRequiredPassesSuiteName},
+	} {
+		if len(suiteName.value) == 0 {
+			return fmt.Errorf("--%s must not be empty", suiteName.flag)
+		}
+		if strings.Contains(suiteName.value, jobrunaggregatorlib.TestSuitesSeparator) {
+			return fmt.Errorf("--%s must not contain %s, it separates nested suites", suiteName.flag, jobrunaggregatorlib.TestSuitesSeparator)
+		}
+	}
+	if !knownUnfinishedPolicies.Has(f.UnfinishedPolicy) {
+		return fmt.Errorf("unknown --unfinished-policy %s, valid values are: %+q", f.UnfinishedPolicy, sets.List(knownUnfinishedPolicies))
+	}
+	if f.UnfinishedPolicy == unfinishedPolicyWait && f.AdaptiveWait {
+		return fmt.Errorf("--unfinished-policy=%s cannot be specified with --adaptive-wait", unfinishedPolicyWait)
+	}
+	if f.ExcludeNeverPassingDays < 0 {
+		return fmt.Errorf("--exclude-jobs-without-success-days must not be negative")
+	}
 
 	return nil
 }
@@ -297,19 +636,31 @@ func (f *JobRunsTestCaseAnalyzerFlags) testNameSuffix() string {
 	if len(f.Platform) > 0 {
 		suffix += fmt.Sprintf("platform:%s ", f.Platform)
 	}
+	if len(f.Architecture) > 0 {
+		suffix += fmt.Sprintf("architecture:%s ", f.Architecture)
+	}
 	if len(f.Network) > 0 {
 		suffix += fmt.Sprintf("network:%s ", f.Network)
 	}
 	if len(f.Infrastructure) > 0 {
 		suffix += fmt.Sprintf("infrastructure:%s ", f.Infrastructure)
 	}
+	if len(f.Release) > 0 {
+		suffix += fmt.Sprintf("release:%s ", f.Release)
+	}
 
 	if len(f.IncludeJobNames) > 0 {
 		suffix += fmt.Sprintf("including:%s ", strings.Join(f.IncludeJobNames, ","))
 	}
+	if len(f.IncludeExactJobNames) > 0 {
+		suffix += fmt.Sprintf("only:%s ", strings.Join(f.IncludeExactJobNames, ","))
+	}
 	if len(f.ExcludeJobNames) > 0 {
 		suffix += fmt.Sprintf("excluding:%s ", strings.Join(f.ExcludeJobNames, ","))
 	}
+	if len(f.ExcludeJobRegexes) > 0 {
+		suffix += fmt.Sprintf("excluding-regex:%s ", (&regexpSlice{&f.ExcludeJobRegexes}).String())
+	}
 
 	return strings.TrimSpace(suffix)
 }
@@ -325,16 +676,37 @@ func (f *JobRunsTestCaseAnalyzerFlags) ToOptions(ctx context.Context) (*JobRunTe
 	if err != nil {
 		return nil, err
 	}
-	ciDataClient := jobrunaggregatorlib.NewRetryingCIDataClient(
-		jobrunaggregatorlib.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
+	ciDataClient := f.QueryCache.Wrap(
+		jobrunaggregatorlib.NewRetryingCIDataClient(
+			f.QueryCost.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
+		),
+		*f.DataCoordinates,
+		f.WorkingDir,
 	)
 
-	ciGCSClient, err := f.Authentication.NewCIGCSClient(ctx, f.GCSBucket)
+	ciGCSClient, err := f.Authentication.NewCIGCSClient(ctx, f.GCSBucket, jobrunaggregatorlib.CIGCSClientOptions{
+		StartingJobRunID: f.JobSearchWindow.StartingJobRunID,
+		JobRunArtifactOptions: jobrunaggregatorapi.JobRunArtifactOptions{
+			JunitParseBudget: f.JunitParseBudget.ToBudget(),
+			ArtifactCache:    f.ArtifactCache.NewArtifactCache(f.WorkingDir),
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
+	if f.ListJobRunsFromBigQuery {
+		ciGCSClient = jobrunaggregatorlib.NewIndexedCIGCSClient(ciGCSClient, ciDataClient)
+	}
 
-	jobGetter := NewTestCaseAnalyzerJobGetter(f.Platform, f.Infrastructure, f.Network, f.testNameSuffix(), f.ExcludeJobNames, f.IncludeJobNames, &f.JobGCSPrefixes, ciDataClient)
+	var variants []jobVariant
+	for _, value := range f.Variants {
+		variant, err := parseJobVariant(value)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+	jobGetter := NewTestCaseAnalyzerJobGetter(f.Platform, f.Architecture, f.Infrastructure, f.Network, f.Release, f.testNameSuffix(), variants, f.ExcludeJobNames, f.IncludeJobNames, f.IncludeExactJobNames, f.ExcludeJobRegexes, time.Duration(f.ExcludeNeverPassingDays)*24*time.Hour, &f.JobGCSPrefixes, ciDataClient)
 
 	var staticJobRunIdentifiers []jobrunaggregatorlib.JobRunIdentifier
 	if len(f.StaticJobRunIdentifierJSON) > 0 || len(f.StaticJobRunIdentifierPath) > 0 {
@@ -344,16 +716,79 @@ func (f *JobRunsTestCaseAnalyzerFlags) ToOptions(ctx context.Context) (*JobRunTe
 		}
 	}
 
-	var testIdentifierOpt testIdentifier
-	switch f.TestGroup {
-	case installTestGroup:
-		testIdentifierOpt = installTestIdentifier
-	case overallTestGroup:
-		testIdentifierOpt = overallTestIdentifier
-	case upgradeTestGroup:
-		testIdentifierOpt = upgradeTestIdentifier
-	default:
-		return nil, fmt.Errorf("unknown test group: %s", f.TestGroup)
+	gateOverride, err := jobrunaggregatorlib.GetGateOverride(f.GateOverrideJSON, f.GateOverridePath)
+	if err != nil {
+		return nil, err
+	}
+	testOwners, err := jobrunaggregatorlib.NewTestOwners(f.TestOwnershipFile)
+	if err != nil {
+		return nil, err
+	}
+	testRenames, err := jobrunaggregatorlib.NewTestRenames(f.TestRenameFile)
+	if err != nil {
+		return nil, err
+	}
+	notifier, err := f.Notifier.ToNotifier()
+	if err != nil {
+		return nil, err
+	}
+	ciDataSet := bigQueryClient.Dataset(f.DataCoordinates.DataSetID)
+
+	var architectures *jobArchitectures
+	if f.MinimumSuccessfulPerArch {
+		architectures = newJobArchitectures()
+	}
+	var autoPasses *autoRequiredPasses
+	if f.MinimumSuccessfulTestCountAuto {
+		autoPasses = newAutoRequiredPasses(ciDataClient, architectures)
+	}
+
+	testCaseCheckers, err := f.newTestCaseCheckers(f.testNameSuffix(), autoPasses, architectures)
+	if err != nil {
+		return nil, err
+	}
+	var jobsOfVariants *variantJobs
+	if len(variants) > 0 {
+		// every variant gets its own checkers, fed with the job runs of its jobs only
+		jobsOfVariants = newVariantJobs()
+		testCaseCheckers = nil
+		for _, variant := range variants {
+			checkers, err := f.newTestCaseCheckers(strings.TrimSpace(f.testNameSuffix()+" "+variant.testNameSuffix()), autoPasses, architectures)
+			if err != nil {
+				return nil, err
+			}
+			testCaseCheckers = append(testCaseCheckers, variantTestCaseChecker{variant: variant, checkers: checkers, jobs: jobsOfVariants})
+		}
+	}
+
+	sampleSeed := f.SampleSeed
+	if f.SampleSize > 0 && sampleSeed == 0 {
+		sampleSeed = time.Now().UnixNano()
+	}
+	sampler := newJobRunSampler(f.SampleSize, sampleSeed)
+
+	var evidenceUploader jobrunaggregatorlib.EvidenceUploader
+	if len(f.EvidenceGCSLocation) > 0 {
+		gcsClient, err := f.Authentication.NewGCSClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if evidenceUploader, err = jobrunaggregatorlib.NewGCSEvidenceUploader(gcsClient, f.EvidenceGCSLocation); err != nil {
+			return nil, err
+		}
+	}
+
+	var testCaseAnalysisInserter jobrunaggregatorlib.BigQueryInserter
+	if f.RecordTestCaseAnalysis {
+		testCaseAnalysisInserter = ciDataSet.Table(jobrunaggregatorapi.TestCaseAnalysisTableName).Inserter()
+	}
+	var gateResultInserter jobrunaggregatorlib.BigQueryInserter
+	if f.RecordGateResults {
+		gateResultInserter = ciDataSet.Table(jobrunaggregatorapi.GateResultsTableName).Inserter()
+	}
+	var locatedJobRunInserter jobrunaggregatorlib.BigQueryInserter
+	if f.CacheLocatedJobRuns {
+		locatedJobRunInserter = ciDataSet.Table(jobrunaggregatorapi.LocatedJobRunsTableName).Inserter()
 	}
 
 	var prowJobClient *prowjobclientset.Clientset
@@ -364,14 +799,31 @@ func (f *JobRunsTestCaseAnalyzerFlags) ToOptions(ctx context.Context) (*JobRunTe
 		}
 	}
 
+	var payloadTag string
+	var payloads []payloadToAnalyze
+	switch {
+	case len(f.PayloadTags) == 1:
+		payloadTag = f.PayloadTags[0]
+	case len(f.PayloadTags) > 1:
+		for _, tag := range f.PayloadTags {
+			creationTime, err := payloadTagCreationTime(tag)
+			if err != nil {
+				return nil, err
+			}
+			payloads = append(payloads, payloadToAnalyze{tag: tag, jobRunStartEstimate: creationTime})
+		}
+	}
+
 	return &JobRunTestCaseAnalyzerOptions{
-		payloadTag:          f.PayloadTag,
+		payloadTag:          payloadTag,
+		payloads:            payloads,
 		workingDir:          f.WorkingDir,
 		jobRunStartEstimate: estimatedStartTime,
+		jobSearchWindow:     f.JobSearchWindow.ToJobSearchWindow(),
 		timeout:             f.Timeout,
 		ciDataClient:        ciDataClient,
 		ciGCSClient:         ciGCSClient,
-		testCaseCheckers:    []TestCaseChecker{minimumRequiredPassesTestCaseChecker{testIdentifierOpt, f.testNameSuffix(), f.MinimumSuccessfulTestCount}},
+		testCaseCheckers:    testCaseCheckers,
 		testNameSuffix:      f.testNameSuffix(),
 		payloadInvocationID: f.PayloadInvocationID,
 		jobGCSPrefixes:      &f.JobGCSPrefixes,
@@ -379,8 +831,102 @@ func (f *JobRunsTestCaseAnalyzerFlags) ToOptions(ctx context.Context) (*JobRunTe
 		prowJobClient:       prowJobClient,
 		jobStateQuerySource: f.JobStateQuerySource,
 		prowJobMatcherFunc:  jobGetter.shouldAggregateJob,
+		adaptiveWait:        f.AdaptiveWait,
+		sampler:             sampler,
+		jobArchitectures:    architectures,
+		optionalJobs:        newOptionalJobs(f.OptionalJobNames),
+		autoRequiredPasses:  autoPasses,
+		variantJobs:         jobsOfVariants,
+
+		findJobRunsRetryPolicy: retryPolicy{
+			maxAttempts:    f.FindJobRunsMaxAttempts,
+			backoff:        f.FindJobRunsBackoff,
+			interval:       f.FindJobRunsInterval,
+			attemptTimeout: f.FindJobRunsAttemptTimeout,
+		},
+		findJobRunsRetries: newRetryTelemetry(),
+		junitFetchTimeout:  f.JUnitFetchTimeout,
 
 		staticJobRunIdentifiers: staticJobRunIdentifiers,
 		gcsBucket:               f.GCSBucket,
+
+		testGroup:             f.TestGroup,
+		gateOverride:          gateOverride,
+		gateOverrideInserter:  ciDataSet.Table(jobrunaggregatorapi.GateOverridesTableName).Inserter(),
+		locatedJobRunInserter: locatedJobRunInserter,
+		gateResultInserter:    gateResultInserter,
+		testOwners:            testOwners,
+		testRenames:           testRenames,
+		notifier:              notifier,
+		metricsExporter:       f.Metrics.ToMetricsExporter("analyze-test-case"),
+		evidenceUploader:      evidenceUploader,
+
+		stopWaitingAtMinimumPasses: f.StopWaitingAtMinimumSuccessfulCount,
+		unfinishedPolicy:           f.UnfinishedPolicy,
+		topSuiteName:               f.TopSuiteName,
+
+		testCaseAnalysisInserter: testCaseAnalysisInserter,
 	}, nil
 }
+
+// newTestCaseCheckers creates the checkers of the test groups and of the individual tests, naming the variant they
+// check with testNameSuffix.
+func (f *JobRunsTestCaseAnalyzerFlags) newTestCaseCheckers(testNameSuffix string, autoPasses *autoRequiredPasses, architectures *jobArchitectures) ([]TestCaseChecker, error) {
+	// multiple test groups can be analyzed against the same set of job runs, each with its own checker
+	var testCaseCheckers []TestCaseChecker
+	for _, testGroup := range strings.Split(f.TestGroup, ",") {
+		testIdentifierOpt, ok := testIdentifiersByGroup[strings.TrimSpace(testGroup)]
+		if !ok {
+			return nil, fmt.Errorf("unknown test group: %s", testGroup)
+		}
+		checker := minimumRequiredPassesTestCaseChecker{
+			id:                     testIdentifierOpt,
+			testNameSuffix:         testNameSuffix,
+			requiredNumberOfPasses: f.MinimumSuccessfulTestCount,
+			autoRequiredPasses:     autoPasses,
+			suiteName:              f.MinimumRequiredPassesSuiteName,
+		}
+		if f.MaximumFailureCount >= 0 {
+			testCaseCheckers = append(testCaseCheckers, maximumFailuresTestCaseChecker{
+				id:              testIdentifierOpt,
+				testNameSuffix:  testNameSuffix,
+				maximumFailures: f.MaximumFailureCount,
+			})
+		}
+		if architectures != nil {
+			testCaseCheckers = append(testCaseCheckers, perArchitectureTestCaseChecker{checker: checker, architectures: architectures})
+			continue
+		}
+		testCaseCheckers = append(testCaseCheckers, checker)
+	}
+	for _, zeroToleranceTest := range f.ZeroToleranceTests {
+		id, err := parseTestIdentifier(zeroToleranceTest)
+		if err != nil {
+			return nil, err
+		}
+		testCaseCheckers = append(testCaseCheckers, zeroToleranceTestCaseChecker{id: id, testNameSuffix: testNameSuffix})
+	}
+	var reportOnlyTests []testIdentifier
+	for _, reportOnlyTest := range f.ReportOnlyTests {
+		id, err := parseTestIdentifier(reportOnlyTest)
+		if err != nil {
+			return nil, err
+		}
+		reportOnlyTests = append(reportOnlyTests, id)
+	}
+	for i, reportOnlyTest := range reportOnlyTests {
+		matched := false
+		for _, checker := range testCaseCheckers {
+			matched = matched || isReportOnly(checker, []testIdentifier{reportOnlyTest})
+		}
+		if !matched {
+			return nil, fmt.Errorf("--report-only-test %s gates no checker, it must be one of the --test-group values or a --zero-tolerance-test", f.ReportOnlyTests[i])
+		}
+	}
+	for i := range testCaseCheckers {
+		if isReportOnly(testCaseCheckers[i], reportOnlyTests) {
+			testCaseCheckers[i] = reportOnlyTestCaseChecker{checker: testCaseCheckers[i]}
+		}
+	}
+	return testCaseCheckers, nil
+}
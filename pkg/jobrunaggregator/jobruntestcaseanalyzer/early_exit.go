@@ -0,0 +1,38 @@
+package jobruntestcaseanalyzer
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// minimumPassesMet tells whether the checkers already pass on the finished job runs alone.  It is only asked when
+// every checker requires a minimum number of passes: job runs finishing later can't fail those, so waiting for them
+// can't change the verdict.
+func (o *JobRunTestCaseAnalyzerOptions) minimumPassesMet(ctx context.Context, finishedJobRuns []jobrunaggregatorapi.JobRunInfo) bool {
+	jobRunJunitMap := map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{}
+	for _, jobRun := range finishedJobRuns {
+		testSuites, err := o.getJUnitTestSuites(ctx, jobRun)
+		if err != nil {
+			// the job run is read again once waiting is over, until then it just doesn't count
+			logrus.WithError(err).WithFields(logrus.Fields{"job": jobRun.GetJobName(), "jobRunID": jobRun.GetJobRunID()}).Debug("skipping job run while checking for minimum passes")
+			continue
+		}
+		if testSuites == nil {
+			testSuites = &junit.TestSuites{}
+		}
+		o.testRenames.RenameTestCases(testSuites)
+		jobRunJunitMap[jobRun] = testSuites
+	}
+	requiredJunits, _ := o.optionalJobs.splitOptionalJobRuns(jobRunJunitMap)
+	for _, testSuite := range o.checkJobRuns(ctx, o.testCaseCheckers, requiredJunits) {
+		// checkers return no suite when they failed to check the job runs, which is no reason to stop waiting
+		if testSuite == nil || testSuite.NumFailed > 0 {
+			return false
+		}
+	}
+	return true
+}
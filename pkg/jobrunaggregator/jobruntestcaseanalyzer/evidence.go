@@ -0,0 +1,113 @@
+package jobruntestcaseanalyzer
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// exportEvidence uploads an evidence bundle for every failed test case of the analysis and references it from the
+// test case.  Bundles only help triage, failing to export them doesn't change the verdict.
+func (o *JobRunTestCaseAnalyzerOptions) exportEvidence(ctx context.Context, matchID string, testSuite *junit.TestSuite, jobRunJunitMap map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) {
+	if o.evidenceUploader == nil {
+		return
+	}
+	jobRunsByID := map[string]jobrunaggregatorapi.JobRunInfo{}
+	for jobRun := range jobRunJunitMap {
+		jobRunsByID[jobRun.GetJobRunID()] = jobRun
+	}
+	gatedTestIdentifiers := map[string]testIdentifier{}
+	for _, checker := range o.testCaseCheckers {
+		if reporter, ok := checker.(gatedTestsReporter); ok {
+			for _, id := range reporter.gatedTests() {
+				gatedTestIdentifiers[id.key()] = id
+			}
+		}
+	}
+	for _, testCase := range failedTestCases(testSuite) {
+		logger := logrus.WithField("test", testCase.Name)
+		details, err := jobrunaggregatorlib.GetTestCaseDetails(testCase)
+		if err != nil || len(details.Failures) == 0 {
+			// only the checkers of individual tests know which job runs failed
+			continue
+		}
+		id := testIdentifier{testSuites: strings.Split(details.TestSuiteName, jobrunaggregatorlib.TestSuitesSeparator), testName: details.Name}
+		// the details only keep the names, the checker's identifier knows whether they are regular expressions
+		if gatedID, ok := gatedTestIdentifiers[id.key()]; ok {
+			id = gatedID
+		}
+		bundle := &jobrunaggregatorlib.EvidenceBundle{
+			TestName:      details.Name,
+			TestSuiteName: details.TestSuiteName,
+			Summary:       details.Summary,
+			CollectedTime: time.Now(),
+		}
+		for _, failure := range details.Failures {
+			jobRun, ok := jobRunsByID[failure.JobRunID]
+			if !ok {
+				continue
+			}
+			bundle.JobRuns = append(bundle.JobRuns, jobrunaggregatorlib.NewEvidenceJobRun(ctx, jobRun, details.TestSuiteName, findFailedTestCases(id, jobRunJunitMap[jobRun])))
+		}
+
+		location, err := o.evidenceUploader.Upload(ctx, jobrunaggregatorlib.EvidenceBundleObjectName(matchID, details.TestSuiteName, testCase.Name), bundle)
+		if err != nil {
+			logger.WithError(err).Warn("failed to upload evidence bundle")
+			continue
+		}
+		jobrunaggregatorlib.SetEvidenceBundleLocation(testCase, location)
+		logger.WithField("location", location).Info("uploaded evidence bundle")
+	}
+}
+
+func failedTestCases(suite *junit.TestSuite) []*junit.TestCase {
+	var ret []*junit.TestCase
+	for _, testCase := range suite.TestCases {
+		if testCase.FailureOutput != nil {
+			ret = append(ret, testCase)
+		}
+	}
+	for _, child := range suite.Children {
+		ret = append(ret, failedTestCases(child)...)
+	}
+	return ret
+}
+
+// findFailedTestCases returns the failed test cases matching the identifier in the junit of a job run, it follows
+// the same suite nesting as getTestStatus.
+func findFailedTestCases(id testIdentifier, testSuites *junit.TestSuites) []*junit.TestCase {
+	if testSuites == nil {
+		return nil
+	}
+	var ret []*junit.TestCase
+	for _, testSuite := range testSuites.Suites {
+		ret = append(ret, findFailedTestCasesInSuite(id, testSuite)...)
+	}
+	return ret
+}
+
+func findFailedTestCasesInSuite(id testIdentifier, testSuite *junit.TestSuite) []*junit.TestCase {
+	if !id.matchesTopSuite(testSuite.Name) {
+		return nil
+	}
+	if len(id.testSuites) == 1 {
+		var ret []*junit.TestCase
+		for _, testCase := range testSuite.TestCases {
+			if id.matchesTestName(testCase.Name) && testCase.FailureOutput != nil {
+				ret = append(ret, testCase)
+			}
+		}
+		return ret
+	}
+	var ret []*junit.TestCase
+	for _, child := range testSuite.Children {
+		ret = append(ret, findFailedTestCasesInSuite(id.childIdentifier(), child)...)
+	}
+	return ret
+}
@@ -0,0 +1,39 @@
+package jobruntestcaseanalyzer
+
+import (
+	"time"
+
+	"k8s.io/test-infra/prow/version"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+const (
+	// defaultTopSuiteName is the suite of the analysis, Sippy and spyglass attribute the results by it
+	defaultTopSuiteName            = "payload-cross-jobs"
+	minimumRequiredPassesSuiteName = "minimum-required-passes-checker"
+)
+
+// invocationProperties describe how the analysis was invoked, so that whoever reads the junit can tell which
+// payload and jobs the results are for and which analyzer produced them.
+func (o *JobRunTestCaseAnalyzerOptions) invocationProperties(startTime time.Time) []*junit.TestSuiteProperty {
+	properties := []*junit.TestSuiteProperty{
+		{Name: "analyzer", Value: "analyze-test-case"},
+		{Name: "analyzer-version", Value: version.Version},
+		{Name: "start-time", Value: startTime.UTC().Format(time.RFC3339)},
+		{Name: "job-run-start-estimate", Value: o.jobRunStartEstimate.UTC().Format(time.RFC3339)},
+	}
+	if len(o.payloadTag) > 0 {
+		properties = append(properties, &junit.TestSuiteProperty{Name: "payload-tag", Value: o.payloadTag})
+	}
+	if len(o.payloadInvocationID) > 0 {
+		properties = append(properties, &junit.TestSuiteProperty{Name: "payload-invocation-id", Value: o.payloadInvocationID})
+	}
+	if len(o.testGroup) > 0 {
+		properties = append(properties, &junit.TestSuiteProperty{Name: "test-group", Value: o.testGroup})
+	}
+	if len(o.testNameSuffix) > 0 {
+		properties = append(properties, &junit.TestSuiteProperty{Name: "filters", Value: o.testNameSuffix})
+	}
+	return properties
+}
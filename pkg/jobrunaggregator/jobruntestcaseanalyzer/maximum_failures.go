@@ -0,0 +1,74 @@
+package jobruntestcaseanalyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// maximumFailuresTestCaseChecker fails when the test failed in more job runs than allowed, however many times it
+// passed.  It bounds the failures of a test when the number of job runs, and so of passes, varies between payloads.
+type maximumFailuresTestCaseChecker struct {
+	id              testIdentifier
+	testNameSuffix  string
+	maximumFailures int
+}
+
+func (r maximumFailuresTestCaseChecker) String() string {
+	return r.id.testName
+}
+
+func (r maximumFailuresTestCaseChecker) gatedTests() []testIdentifier {
+	return []testIdentifier{r.id}
+}
+
+func (r maximumFailuresTestCaseChecker) CheckTestCase(ctx context.Context, jobRunJunits map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) *junit.TestSuite {
+	topSuite := &junit.TestSuite{
+		Name:      "maximum-failures-checker",
+		TestCases: []*junit.TestCase{},
+	}
+	bottomSuite := addToTestSuiteFromSuiteNames(topSuite, r.id.testSuites)
+
+	testName := fmt.Sprintf("test '%s' fails at most %d times across payload jobs", r.id.testName, r.maximumFailures)
+	if len(r.testNameSuffix) > 0 {
+		testName += fmt.Sprintf(" for %s", r.testNameSuffix)
+	}
+	testCase := &junit.TestCase{
+		Name: testName,
+	}
+	bottomSuite.TestCases = append(bottomSuite.TestCases, testCase)
+
+	start := time.Now()
+	currDetails := &jobrunaggregatorlib.TestCaseDetails{
+		Name:          r.id.testName,
+		TestSuiteName: strings.Join(r.id.testSuites, jobrunaggregatorlib.TestSuitesSeparator),
+	}
+	details := minimumRequiredPassesTestCaseChecker{id: r.id}
+	for jobRun, testSuites := range jobRunJunits {
+		details.addTestResultToDetails(currDetails, jobRun, getTestStatusInJobRun(r.id, testSuites))
+	}
+	currDetails.Summary = fmt.Sprintf("Total job runs: %d, passes: %d, failures: %d, skips %d", len(jobRunJunits), len(currDetails.Passes), len(currDetails.Failures), len(currDetails.Skips))
+	if err := jobrunaggregatorlib.SetTestCaseDetails(testCase, currDetails); err != nil {
+		return nil
+	}
+	testCase.Duration = time.Since(start).Seconds()
+	if len(currDetails.Failures) > r.maximumFailures {
+		failedJobRuns := []string{}
+		for _, failure := range currDetails.Failures {
+			failedJobRuns = append(failedJobRuns, failure.HumanURL)
+		}
+		sort.Strings(failedJobRuns)
+		testCase.FailureOutput = &junit.FailureOutput{
+			Message: fmt.Sprintf("allowed maximum failure count %d, got %d", r.maximumFailures, len(currDetails.Failures)),
+			Output:  strings.Join(failedJobRuns, "\n"),
+		}
+	}
+	jobrunaggregatorlib.UpdateTestCountsInSuite(topSuite)
+	return topSuite
+}
@@ -0,0 +1,62 @@
+package jobruntestcaseanalyzer
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	analysisPhaseWaitForJobRuns = "wait_for_job_runs"
+	analysisPhaseCheckTestCases = "check_test_cases"
+	analysisPhaseRecordResults  = "record_results"
+)
+
+var (
+	jobRunsLocated = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "jobrunaggregator_test_case_analyzer_job_runs_located",
+			Help: "Number of job runs located for the analyzed payload once waiting for them was over, by whether they finished.",
+		},
+		[]string{"state"},
+	)
+	junitFetchErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "jobrunaggregator_test_case_analyzer_junit_fetch_errors_total",
+			Help: "Number of job runs whose junit could not be read, by job and reason.",
+		},
+		[]string{"job_name", "reason"},
+	)
+	findJobRunsFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "jobrunaggregator_test_case_analyzer_find_job_runs_failures_total",
+			Help: "Number of failed attempts to find the job runs of a job, each of them retried until the attempts run out.",
+		},
+		[]string{"job_name"},
+	)
+	analysisPhaseSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "jobrunaggregator_test_case_analyzer_phase_seconds",
+			Help: "Wall-clock seconds the last analysis spent in each of its phases.",
+		},
+		[]string{"phase"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(jobRunsLocated, junitFetchErrorsTotal, findJobRunsFailuresTotal, analysisPhaseSeconds)
+}
+
+// observeAnalysisPhase records the time spent in the phase since start
+func observeAnalysisPhase(phase string, start time.Time) {
+	analysisPhaseSeconds.WithLabelValues(phase).Set(time.Since(start).Seconds())
+}
+
+// pushMetrics is best effort: metrics tell how gating is doing, not what the verdict is.
+func (o *JobRunTestCaseAnalyzerOptions) pushMetrics(ctx context.Context) {
+	if err := o.metricsExporter.Push(ctx); err != nil {
+		logrus.WithError(err).Warn("failed to push metrics")
+	}
+}
@@ -0,0 +1,54 @@
+package jobruntestcaseanalyzer
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// optionalJobs are the jobs whose runs contribute to the reports but not to the verdict, like informing jobs.
+// They come from the command line, and from the jobs table as the jobs are located.
+type optionalJobs struct {
+	lock     sync.RWMutex
+	jobNames sets.Set[string]
+}
+
+func newOptionalJobs(jobNames []string) *optionalJobs {
+	return &optionalJobs{jobNames: sets.New[string](jobNames...)}
+}
+
+func (j *optionalJobs) record(jobs []jobrunaggregatorapi.JobRowWithVariants) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	for _, job := range jobs {
+		if job.Optional {
+			j.jobNames.Insert(job.JobName)
+		}
+	}
+}
+
+func (j *optionalJobs) isOptional(jobName string) bool {
+	if j == nil {
+		return false
+	}
+	j.lock.RLock()
+	defer j.lock.RUnlock()
+	return j.jobNames.Has(jobName)
+}
+
+// splitOptionalJobRuns separates the junit of the runs of optional jobs from the junit of the runs deciding the verdict
+func (j *optionalJobs) splitOptionalJobRuns(jobRunJunits map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) (required, optional map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) {
+	required = map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{}
+	optional = map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{}
+	for jobRun, testSuites := range jobRunJunits {
+		if j.isOptional(jobRun.GetJobName()) {
+			optional[jobRun] = testSuites
+			continue
+		}
+		required[jobRun] = testSuites
+	}
+	return required, optional
+}
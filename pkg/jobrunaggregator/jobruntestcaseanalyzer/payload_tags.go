@@ -0,0 +1,92 @@
+package jobruntestcaseanalyzer
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// payloadTagsJunitFileName is written in the working dir, next to the directories of the analyzed payload tags
+const payloadTagsJunitFileName = "junit-payload-tags-test-case-analysis.xml"
+
+// payloadToAnalyze is one of several payload tags analyzed in a single invocation, like the last three nightlies
+type payloadToAnalyze struct {
+	tag                 string
+	jobRunStartEstimate time.Time
+}
+
+var payloadTagCreationTimeRegex = regexp.MustCompile(`([0-9]{4}-[0-9]{2}-[0-9]{2}-[0-9]{6})$`)
+
+// payloadTagCreationTime reads the creation time payload tags end with, like 4.11.0-0.nightly-2022-04-28-102605.
+// When several payloads are analyzed, it stands for --job-start-time, which can only describe one of them.
+func payloadTagCreationTime(payloadTag string) (time.Time, error) {
+	match := payloadTagCreationTimeRegex.FindStringSubmatch(payloadTag)
+	if len(match) < 2 {
+		return time.Time{}, fmt.Errorf("payload tag %q does not end with its creation time, like 2022-04-28-102605", payloadTag)
+	}
+	return time.Parse("2006-01-02-150405", match[1])
+}
+
+// analyzePayloads analyzes every payload on its own, like a single --payload-tag does, then gathers their junit in
+// a suite per payload tag so that the trend across payloads reads from a single artifact.  The analysis fails when
+// any of the payloads fails.
+func (o *JobRunTestCaseAnalyzerOptions) analyzePayloads(ctx context.Context) (*AnalysisResult, error) {
+	topSuite := &junit.TestSuite{
+		Name:      "payload-tags",
+		TestCases: []*junit.TestCase{},
+	}
+	result := &AnalysisResult{
+		Passed:   true,
+		JobRuns:  []AnalysisResultJobRun{},
+		Checkers: []AnalysisResultChecker{},
+	}
+	var payloadTags []string
+	for _, payload := range o.payloads {
+		payloadOptions := *o
+		payloadOptions.payloads = nil
+		payloadOptions.payloadTag = payload.tag
+		payloadOptions.jobRunStartEstimate = payload.jobRunStartEstimate
+		if o.findJobRunsRetries != nil {
+			// retries are reported per payload
+			payloadOptions.findJobRunsRetries = newRetryTelemetry()
+		}
+
+		testSuite, payloadResult, err := payloadOptions.analyzePayload(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze payload %s: %w", payload.tag, err)
+		}
+		payloadTags = append(payloadTags, payload.tag)
+		result.Passed = result.Passed && payloadResult.Passed
+		result.Payloads = append(result.Payloads, payloadResult)
+		testSuite.Name = payloadTagSuiteName(payload.tag)
+		logrus.WithFields(logrus.Fields{"payloadTag": payload.tag, "failed": testSuite.NumFailed}).Info("analyzed payload")
+		topSuite.Children = append(topSuite.Children, testSuite)
+		// the counts of every payload are final, recounting them would lose what the checkers decided
+		topSuite.NumTests += testSuite.NumTests
+		topSuite.NumFailed += testSuite.NumFailed
+		topSuite.NumSkipped += testSuite.NumSkipped
+	}
+
+	junitXML, err := xml.Marshal(topSuite)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(o.workingDir, payloadTagsJunitFileName), junitXML, 0644); err != nil {
+		return nil, err
+	}
+	result.MatchID = strings.Join(payloadTags, ",")
+	return result, nil
+}
+
+func payloadTagSuiteName(payloadTag string) string {
+	return fmt.Sprintf("payload %s", payloadTag)
+}
@@ -0,0 +1,81 @@
+package jobruntestcaseanalyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// reportOnlyTestCaseChecker runs a checker whose failures must not reject the payload yet.  Its test cases are
+// emitted as usual, so that new gate criteria can be burned in against real payloads, but failures are turned
+// into passes that keep the failure in their output and in the report-only-verdict property.
+type reportOnlyTestCaseChecker struct {
+	checker TestCaseChecker
+}
+
+func (r reportOnlyTestCaseChecker) String() string {
+	if stringer, ok := r.checker.(fmt.Stringer); ok {
+		return stringer.String() + " (report-only)"
+	}
+	return "report-only"
+}
+
+func (r reportOnlyTestCaseChecker) gatedTests() []testIdentifier {
+	if reporter, ok := r.checker.(gatedTestsReporter); ok {
+		return reporter.gatedTests()
+	}
+	return nil
+}
+
+func (r reportOnlyTestCaseChecker) CheckTestCase(ctx context.Context, jobRunJunits map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) *junit.TestSuite {
+	suite := r.checker.CheckTestCase(ctx, jobRunJunits)
+	if suite == nil {
+		return nil
+	}
+	reportOnly(suite)
+	jobrunaggregatorlib.UpdateTestCountsInSuite(suite)
+	return suite
+}
+
+func reportOnly(suite *junit.TestSuite) {
+	for _, testCase := range suite.TestCases {
+		verdict := jobrunaggregatorapi.GateVerdictPassed
+		switch {
+		case testCase.FailureOutput != nil:
+			verdict = jobrunaggregatorapi.GateVerdictFailed
+			if len(testCase.SystemOut) > 0 && !strings.HasSuffix(testCase.SystemOut, "\n") {
+				testCase.SystemOut += "\n"
+			}
+			testCase.SystemOut += fmt.Sprintf("report-only checker failed: %s\n%s", testCase.FailureOutput.Message, testCase.FailureOutput.Output)
+			testCase.FailureOutput = nil
+		case testCase.SkipMessage != nil:
+			verdict = jobrunaggregatorapi.GateVerdictSkipped
+		}
+		testCase.Properties = append(testCase.Properties, &junit.TestSuiteProperty{Name: jobrunaggregatorlib.ReportOnlyVerdictPropertyName, Value: verdict})
+	}
+	for _, child := range suite.Children {
+		reportOnly(child)
+	}
+}
+
+// isReportOnly tells whether the checker gates on any of the tests registered in report-only mode.  Identifiers are
+// matched as they were given, so a test group makes its checkers report-only, with all the test cases they emit, but
+// a single test of the group doesn't match them.
+func isReportOnly(checker TestCaseChecker, reportOnlyTests []testIdentifier) bool {
+	reporter, ok := checker.(gatedTestsReporter)
+	if !ok {
+		return false
+	}
+	for _, id := range reporter.gatedTests() {
+		for _, reportOnlyTest := range reportOnlyTests {
+			if id.key() == reportOnlyTest.key() {
+				return true
+			}
+		}
+	}
+	return false
+}
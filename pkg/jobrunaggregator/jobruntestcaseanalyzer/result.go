@@ -0,0 +1,151 @@
+package jobruntestcaseanalyzer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+const (
+	analysisResultFileName = "test-case-analysis.json"
+
+	jobRunStatusFinished   = "finished"
+	jobRunStatusUnfinished = "unfinished"
+)
+
+// AnalysisResult is the outcome of the analysis for automation, which would otherwise have to read the details of
+// every checker back from the junit.
+type AnalysisResult struct {
+	MatchID  string                  `json:"matchID"`
+	Passed   bool                    `json:"passed"`
+	JobRuns  []AnalysisResultJobRun  `json:"jobRuns"`
+	Checkers []AnalysisResultChecker `json:"checkers"`
+
+	// Payloads are the results of every payload tag when several were analyzed at once.  MatchID then lists the
+	// payload tags, and the job runs and checkers are those of the payloads.
+	Payloads []*AnalysisResult `json:"payloads,omitempty"`
+}
+
+type AnalysisResultJobRun struct {
+	JobName  string `json:"jobName"`
+	JobRunID string `json:"jobRunID"`
+	HumanURL string `json:"humanURL"`
+	Status   string `json:"status"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// AnalysisResultChecker is a test case produced by a checker, the passes, failures and skips are counted in job runs
+type AnalysisResultChecker struct {
+	// Checker is the suite of the checker that produced the test case, like minimum-required-passes-checker
+	Checker       string `json:"checker"`
+	TestSuiteName string `json:"testSuiteName"`
+	TestName      string `json:"testName"`
+	Verdict       string `json:"verdict"`
+	// ReportOnlyVerdict is the verdict the checker reached in report-only mode, Verdict is then always Passed
+	ReportOnlyVerdict string  `json:"reportOnlyVerdict,omitempty"`
+	Message           string  `json:"message,omitempty"`
+	Passes            int     `json:"passes"`
+	Failures          int     `json:"failures"`
+	Skips             int     `json:"skips"`
+	DurationSeconds   float64 `json:"durationSeconds"`
+}
+
+func newAnalysisResult(matchID string, testSuite *junit.TestSuite, finishedJobRuns, unfinishedJobRuns []jobrunaggregatorapi.JobRunInfo, optionalJobs *optionalJobs) *AnalysisResult {
+	result := &AnalysisResult{
+		MatchID:  matchID,
+		Passed:   testSuite.NumFailed == 0,
+		JobRuns:  []AnalysisResultJobRun{},
+		Checkers: []AnalysisResultChecker{},
+	}
+	for status, jobRuns := range map[string][]jobrunaggregatorapi.JobRunInfo{jobRunStatusFinished: finishedJobRuns, jobRunStatusUnfinished: unfinishedJobRuns} {
+		for _, jobRun := range jobRuns {
+			result.JobRuns = append(result.JobRuns, AnalysisResultJobRun{
+				JobName:  jobRun.GetJobName(),
+				JobRunID: jobRun.GetJobRunID(),
+				HumanURL: jobRun.GetHumanURL(),
+				Status:   status,
+				Optional: optionalJobs.isOptional(jobRun.GetJobName()),
+			})
+		}
+	}
+	sort.Slice(result.JobRuns, func(i, j int) bool {
+		if result.JobRuns[i].JobName != result.JobRuns[j].JobName {
+			return result.JobRuns[i].JobName < result.JobRuns[j].JobName
+		}
+		return result.JobRuns[i].JobRunID < result.JobRuns[j].JobRunID
+	})
+	addAnalysisResultCheckers(nil, testSuite, result)
+	return result
+}
+
+func addAnalysisResultCheckers(parents []string, suite *junit.TestSuite, result *AnalysisResult) {
+	suiteNames := append(append([]string{}, parents...), suite.Name)
+	for _, testCase := range suite.TestCases {
+		checker := AnalysisResultChecker{
+			TestSuiteName:   strings.Join(suiteNames, jobrunaggregatorlib.TestSuitesSeparator),
+			TestName:        testCase.Name,
+			Verdict:         jobrunaggregatorapi.GateVerdictPassed,
+			DurationSeconds: testCase.Duration,
+		}
+		// the first suite is the one of the whole analysis
+		if len(suiteNames) > 1 {
+			checker.Checker = suiteNames[1]
+		}
+		switch {
+		case testCase.SkipMessage != nil:
+			checker.Verdict = jobrunaggregatorapi.GateVerdictSkipped
+			checker.Message = testCase.SkipMessage.Message
+		case jobrunaggregatorlib.IsFailedTestCase(testCase):
+			checker.Verdict = jobrunaggregatorapi.GateVerdictFailed
+			checker.Message = testCase.FailureOutput.Message
+		}
+		checker.ReportOnlyVerdict = jobrunaggregatorlib.GetReportOnlyVerdict(testCase)
+		// test cases that aren't about job runs, like the missing artifacts, have no details to count
+		if details, err := jobrunaggregatorlib.GetTestCaseDetails(testCase); err == nil {
+			checker.Passes = len(details.Passes)
+			checker.Failures = len(details.Failures)
+			checker.Skips = len(details.Skips)
+		}
+		result.Checkers = append(result.Checkers, checker)
+	}
+	for _, child := range suite.Children {
+		addAnalysisResultCheckers(suiteNames, child, result)
+	}
+}
+
+// testCaseAnalysisRows flattens the result into a row per checker test case for the TestCaseAnalysis table
+func testCaseAnalysisRows(result *AnalysisResult, testGroup string, analysisTime time.Time) []jobrunaggregatorapi.TestCaseAnalysisRow {
+	rows := []jobrunaggregatorapi.TestCaseAnalysisRow{}
+	for _, checker := range result.Checkers {
+		rows = append(rows, jobrunaggregatorapi.TestCaseAnalysisRow{
+			AnalysisTime:      analysisTime,
+			PayloadTag:        result.MatchID,
+			TestGroup:         testGroup,
+			Checker:           checker.Checker,
+			TestSuiteName:     checker.TestSuiteName,
+			TestName:          checker.TestName,
+			Verdict:           checker.Verdict,
+			ReportOnlyVerdict: checker.ReportOnlyVerdict,
+			Passes:            checker.Passes,
+			Failures:          checker.Failures,
+			Skips:             checker.Skips,
+			DurationSeconds:   checker.DurationSeconds,
+		})
+	}
+	return rows
+}
+
+func writeAnalysisResult(result *AnalysisResult, outputDir string) error {
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outputDir, analysisResultFileName), resultJSON, 0644)
+}
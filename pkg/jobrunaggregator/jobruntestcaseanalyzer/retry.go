@@ -0,0 +1,165 @@
+package jobruntestcaseanalyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+const (
+	retryBackoffFixed       = "fixed"
+	retryBackoffExponential = "exponential"
+
+	// exponentialRetryCap keeps exponential backoff from sleeping through most of the analysis timeout
+	exponentialRetryCap = 10 * time.Minute
+)
+
+var knownRetryBackoffs = sets.New[string](retryBackoffFixed, retryBackoffExponential)
+
+// retryPolicy decides how often and how long job runs are searched for when searching fails
+type retryPolicy struct {
+	maxAttempts int
+	backoff     string
+	interval    time.Duration
+	// attemptTimeout, when set, bounds every attempt so that a hanging search is retried
+	attemptTimeout time.Duration
+}
+
+func (p retryPolicy) newBackoff() wait.Backoff {
+	if p.backoff == retryBackoffExponential {
+		return wait.Backoff{Duration: p.interval, Factor: 2, Jitter: 0.2, Steps: p.maxAttempts, Cap: exponentialRetryCap}
+	}
+	return wait.Backoff{Duration: p.interval, Factor: 1, Steps: p.maxAttempts}
+}
+
+// findJobRuns calls find until it succeeds or maxAttempts attempts failed, waiting between attempts as the backoff says
+func (p retryPolicy) findJobRuns(ctx context.Context, jobName string, telemetry *retryTelemetry,
+	find func(context.Context) ([]jobrunaggregatorapi.JobRunInfo, error)) ([]jobrunaggregatorapi.JobRunInfo, error) {
+	logger := logrus.WithField("job", jobName)
+	backoff := p.newBackoff()
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if p.attemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, p.attemptTimeout)
+		}
+		jobRuns, err := find(attemptCtx)
+		cancel()
+		telemetry.recordAttempt(jobName, err)
+		if err == nil {
+			return jobRuns, nil
+		}
+		findJobRunsFailuresTotal.WithLabelValues(jobName).Inc()
+		if attempt >= p.maxAttempts {
+			logger.WithError(err).WithField("attempts", attempt).Error("give up finding job runs after retries")
+			return nil, err
+		}
+
+		delay := backoff.Step()
+		logger.WithError(err).WithFields(logrus.Fields{"attempt": attempt, "delay": delay}).Warn("error finding job runs, will attempt to find related jobs again")
+		select {
+		case <-ctx.Done():
+			// Simply return. Caller will check ctx and return error
+			return nil, ctx.Err()
+		case <-time.After(delay):
+			telemetry.recordWait(jobName, delay)
+		}
+	}
+}
+
+// retryTelemetry counts the attempts made to find the job runs of every job, to tune the retry policy
+type retryTelemetry struct {
+	lock  sync.Mutex
+	byJob map[string]*jobRetries
+}
+
+type jobRetries struct {
+	attempts int
+	failures int
+	waited   time.Duration
+}
+
+func newRetryTelemetry() *retryTelemetry {
+	return &retryTelemetry{byJob: map[string]*jobRetries{}}
+}
+
+func (t *retryTelemetry) jobRetries(jobName string) *jobRetries {
+	if t.byJob[jobName] == nil {
+		t.byJob[jobName] = &jobRetries{}
+	}
+	return t.byJob[jobName]
+}
+
+func (t *retryTelemetry) recordAttempt(jobName string, err error) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	retries := t.jobRetries(jobName)
+	retries.attempts++
+	if err != nil {
+		retries.failures++
+	}
+}
+
+func (t *retryTelemetry) recordWait(jobName string, waited time.Duration) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.jobRetries(jobName).waited += waited
+}
+
+// retriedJobs lists the jobs whose job runs were not found at the first attempt
+func (t *retryTelemetry) retriedJobs() []string {
+	ret := []string{}
+	for jobName, retries := range t.byJob {
+		if retries.failures > 0 {
+			ret = append(ret, jobName)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// property summarizes the retries in the junit so gating job durations can be tuned from past analyses
+func (t *retryTelemetry) property() *junit.TestSuiteProperty {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var attempts, failures int
+	var waited time.Duration
+	for _, retries := range t.byJob {
+		attempts += retries.attempts
+		failures += retries.failures
+		waited += retries.waited
+	}
+	return &junit.TestSuiteProperty{
+		Name:  "find-job-runs-retries",
+		Value: fmt.Sprintf("jobs=%d retried-jobs=%d attempts=%d failures=%d waited=%s", len(t.byJob), len(t.retriedJobs()), attempts, failures, waited),
+	}
+}
+
+func (t *retryTelemetry) log() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, jobName := range t.retriedJobs() {
+		retries := t.byJob[jobName]
+		logrus.WithFields(logrus.Fields{
+			"job":      jobName,
+			"attempts": retries.attempts,
+			"failures": retries.failures,
+			"waited":   retries.waited,
+		}).Info("retried finding job runs")
+	}
+}
@@ -0,0 +1,135 @@
+package jobruntestcaseanalyzer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// jobRunSampler bounds the number of job runs analyzed per job.  The job runs are sampled as soon as they are
+// located, so the dropped job runs are never waited on and their junit is never read.  The waiter locates the job
+// runs again every time it polls: a sampled job run stays sampled, and the job runs located later only fill the room
+// left.  A run can be reproduced by passing the seed recorded in the junit, as long as the job runs are located in
+// the same order.
+type jobRunSampler struct {
+	size int
+	seed int64
+
+	lock sync.Mutex
+	// jobs holds the sample of every job by name
+	jobs map[string]*jobSample
+}
+
+type jobSample struct {
+	random  *rand.Rand
+	sampled sets.Set[string]
+	// dropped is how many of the job runs located last were not sampled
+	dropped int
+}
+
+func newJobRunSampler(size int, seed int64) *jobRunSampler {
+	return &jobRunSampler{
+		size: size,
+		seed: seed,
+		jobs: map[string]*jobSample{},
+	}
+}
+
+// enabled is false for a nil sampler, which samples nothing.
+func (s *jobRunSampler) enabled() bool {
+	return s != nil && s.size > 0
+}
+
+// sampleJobRunIDs returns which of the located job runs of the job are sampled.
+func (s *jobRunSampler) sampleJobRunIDs(jobName string, jobRunIDs []string) sets.Set[string] {
+	located := sets.New[string](jobRunIDs...)
+	if !s.enabled() {
+		return located
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	job, ok := s.jobs[jobName]
+	if !ok {
+		// every job has its own source, so that its sample doesn't depend on the order the jobs are located in
+		hash := fnv.New64a()
+		_, _ = hash.Write([]byte(jobName))
+		job = &jobSample{
+			random:  rand.New(rand.NewSource(s.seed ^ int64(hash.Sum64()))),
+			sampled: sets.New[string](),
+		}
+		s.jobs[jobName] = job
+	}
+	// sorted before shuffling so the sample does not depend on the order the job runs were located in
+	candidates := sets.List(located.Difference(job.sampled))
+	job.random.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	for _, jobRunID := range candidates {
+		if job.sampled.Len() >= s.size {
+			break
+		}
+		job.sampled.Insert(jobRunID)
+	}
+	ret := located.Intersection(job.sampled)
+	job.dropped = located.Len() - ret.Len()
+	return ret
+}
+
+// sample keeps up to size of the located job runs of every job.
+func (s *jobRunSampler) sample(jobRuns []jobrunaggregatorapi.JobRunInfo) []jobrunaggregatorapi.JobRunInfo {
+	if !s.enabled() {
+		return jobRuns
+	}
+	sampledByJob := map[string]sets.Set[string]{}
+	for jobName, jobJobRuns := range groupJobRunsByJob(jobRuns) {
+		jobRunIDs := []string{}
+		for _, jobRun := range jobJobRuns {
+			jobRunIDs = append(jobRunIDs, jobRun.GetJobRunID())
+		}
+		sampledByJob[jobName] = s.sampleJobRunIDs(jobName, jobRunIDs)
+	}
+	ret := []jobrunaggregatorapi.JobRunInfo{}
+	for _, jobRun := range jobRuns {
+		if sampledByJob[jobRun.GetJobName()].Has(jobRun.GetJobRunID()) {
+			ret = append(ret, jobRun)
+		}
+	}
+	return ret
+}
+
+// dropped returns how many of the job runs located last were not sampled.
+func (s *jobRunSampler) dropped() int {
+	if !s.enabled() {
+		return 0
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ret := 0
+	for _, job := range s.jobs {
+		ret += job.dropped
+	}
+	return ret
+}
+
+// property records the sampling decision in the junit.
+func (s *jobRunSampler) property() *junit.TestSuiteProperty {
+	return &junit.TestSuiteProperty{
+		Name:  "job-run-sampling",
+		Value: fmt.Sprintf("size=%d seed=%d dropped=%d", s.size, s.seed, s.dropped()),
+	}
+}
+
+func groupJobRunsByJob(jobRuns []jobrunaggregatorapi.JobRunInfo) map[string][]jobrunaggregatorapi.JobRunInfo {
+	ret := map[string][]jobrunaggregatorapi.JobRunInfo{}
+	for _, jobRun := range jobRuns {
+		ret[jobRun.GetJobName()] = append(ret[jobRun.GetJobName()], jobRun)
+	}
+	return ret
+}
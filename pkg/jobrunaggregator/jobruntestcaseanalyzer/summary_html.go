@@ -0,0 +1,97 @@
+package jobruntestcaseanalyzer
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+const analysisSummaryHTMLFileName = "test-case-analysis-summary.html"
+
+// installStatuses returns the status of the install test of every job run that produced a junit, by job run ID.
+func installStatuses(jobRunJunitMap map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) map[string]testStatus {
+	ret := map[string]testStatus{}
+	for jobRun, testSuites := range jobRunJunitMap {
+		ret[jobRun.GetJobRunID()] = getTestStatusInJobRun(installTestIdentifier, testSuites)
+	}
+	return ret
+}
+
+func writeAnalysisSummaryHTML(result *AnalysisResult, jobRunJunitMap map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites, outputDir string) error {
+	summaryHTML := htmlForAnalysisSummary(result, installStatuses(jobRunJunitMap))
+	return os.WriteFile(filepath.Join(outputDir, analysisSummaryHTMLFileName), []byte(summaryHTML), 0644)
+}
+
+// htmlForAnalysisSummary renders the analysis in the style of the job-run-summary of the payload aggregator, so
+// both read the same in spyglass.
+func htmlForAnalysisSummary(result *AnalysisResult, installStatuses map[string]testStatus) string {
+	verdict := jobrunaggregatorapi.GateVerdictPassed
+	if !result.Passed {
+		verdict = jobrunaggregatorapi.GateVerdictFailed
+	}
+	ret := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<title>
+test-case-analysis-summary for %[1]s
+</title>
+<style>
+a {
+	color: #ff8caa;
+}
+a:visited {
+	color: #ff8caa;
+}
+a:hover {
+	color: #ffffff;
+}
+body {
+	background-color: rgba(0,0,0,.54);
+	color: #ffffff;
+}
+</style>
+</head>
+<body>
+<h2>Verdict for %[1]s: %[2]s</h2>
+`, html.EscapeString(result.MatchID), verdict)
+
+	for _, status := range []string{jobRunStatusUnfinished, jobRunStatusFinished} {
+		items := ""
+		for _, jobRun := range result.JobRuns {
+			if jobRun.Status != status {
+				continue
+			}
+			items += fmt.Sprintf(`<li><a target="_blank" href="%s">%s/%s</a>`,
+				html.EscapeString(jobRun.HumanURL), html.EscapeString(jobRun.JobName), html.EscapeString(jobRun.JobRunID))
+			if installStatus, ok := installStatuses[jobRun.JobRunID]; ok {
+				items += fmt.Sprintf(" install: %s", installStatus)
+			}
+			if jobRun.Optional {
+				items += " (optional)"
+			}
+			items += "</li>\n"
+		}
+		if len(items) == 0 {
+			continue
+		}
+		heading := "Finished Jobs"
+		if status == jobRunStatusUnfinished {
+			heading = "Unfinished Jobs"
+		}
+		ret += fmt.Sprintf(`
+<h2>%s</h2>
+<ol>
+%s</ol>
+<br/>
+`, heading, items)
+	}
+
+	ret += `
+</body>
+</html>`
+	return ret
+}
@@ -0,0 +1,165 @@
+package jobruntestcaseanalyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+const (
+	testGridJSONFileName = "test-grid.json"
+	testGridHTMLFileName = "test-grid.html"
+)
+
+// gatedTestsReporter is implemented by the TestCaseCheckers that gate on individual tests.
+type gatedTestsReporter interface {
+	gatedTests() []testIdentifier
+}
+
+func (r minimumRequiredPassesTestCaseChecker) gatedTests() []testIdentifier {
+	return []testIdentifier{r.id}
+}
+
+func (r perArchitectureTestCaseChecker) gatedTests() []testIdentifier {
+	return r.checker.gatedTests()
+}
+
+// testGrid shows the result of every gated test in every job run of the payload, the view release reviewers
+// otherwise rebuild by hand from the junit of every job run.
+type testGrid struct {
+	JobRuns []testGridJobRun `json:"jobRuns"`
+	Tests   []testGridRow    `json:"tests"`
+}
+
+type testGridJobRun struct {
+	JobName  string `json:"jobName"`
+	JobRunID string `json:"jobRunID"`
+	HumanURL string `json:"humanURL"`
+	// Optional job runs are shown, but don't decide the verdict
+	Optional bool `json:"optional,omitempty"`
+}
+
+type testGridRow struct {
+	TestSuites []string `json:"testSuites"`
+	TestName   string   `json:"testName"`
+	// Results has one entry per job run, in the order of the job runs of the grid
+	Results []string `json:"results"`
+}
+
+func (s testStatus) String() string {
+	switch s {
+	case testPassed:
+		return "pass"
+	case testFailed:
+		return "fail"
+	case testFlaked:
+		return "flake"
+	case testUnreadable:
+		return "unreadable"
+	default:
+		return "skip"
+	}
+}
+
+func newTestGrid(checkers []TestCaseChecker, jobRunJunits map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites, optionalJobs *optionalJobs) *testGrid {
+	jobRuns := make([]jobrunaggregatorapi.JobRunInfo, 0, len(jobRunJunits))
+	for jobRun := range jobRunJunits {
+		jobRuns = append(jobRuns, jobRun)
+	}
+	sort.Slice(jobRuns, func(i, j int) bool {
+		if jobRuns[i].GetJobName() != jobRuns[j].GetJobName() {
+			return jobRuns[i].GetJobName() < jobRuns[j].GetJobName()
+		}
+		return jobRuns[i].GetJobRunID() < jobRuns[j].GetJobRunID()
+	})
+
+	grid := &testGrid{JobRuns: []testGridJobRun{}, Tests: []testGridRow{}}
+	for _, jobRun := range jobRuns {
+		grid.JobRuns = append(grid.JobRuns, testGridJobRun{
+			JobName:  jobRun.GetJobName(),
+			JobRunID: jobRun.GetJobRunID(),
+			HumanURL: jobRun.GetHumanURL(),
+			Optional: optionalJobs.isOptional(jobRun.GetJobName()),
+		})
+	}
+
+	seen := map[string]bool{}
+	for _, checker := range checkers {
+		reporter, ok := checker.(gatedTestsReporter)
+		if !ok {
+			continue
+		}
+		for _, id := range reporter.gatedTests() {
+			if seen[id.key()] {
+				continue
+			}
+			seen[id.key()] = true
+
+			row := testGridRow{TestSuites: id.testSuites, TestName: id.testName, Results: []string{}}
+			for _, jobRun := range jobRuns {
+				row.Results = append(row.Results, getTestStatusInJobRun(id, jobRunJunits[jobRun]).String())
+			}
+			grid.Tests = append(grid.Tests, row)
+		}
+	}
+	return grid
+}
+
+func writeTestGrid(grid *testGrid, outputDir string) error {
+	gridJSON, err := json.MarshalIndent(grid, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, testGridJSONFileName), gridJSON, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outputDir, testGridHTMLFileName), []byte(htmlForTestGrid(grid)), 0644)
+}
+
+func htmlForTestGrid(grid *testGrid) string {
+	ret := `<!DOCTYPE html>
+<html>
+<head>
+<style>
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; }
+th.jobrun { writing-mode: vertical-rl; }
+th.optional { font-style: italic; background-color: #f2f2f2; }
+td.pass { background-color: #9fdf9f; }
+td.fail { background-color: #f29494; }
+td.flake { background-color: #f2d494; }
+td.unreadable { background-color: #c9b3e6; }
+td.skip { background-color: #e6e6e6; }
+</style>
+</head>
+<body>
+<table>
+<tr><th>Test</th>`
+	for _, jobRun := range grid.JobRuns {
+		class, label := "jobrun", ""
+		if jobRun.Optional {
+			class, label = "jobrun optional", " (optional)"
+		}
+		ret += fmt.Sprintf(`<th class="%s"><a target="_blank" href="%s">%s/%s</a>%s</th>`,
+			class, html.EscapeString(jobRun.HumanURL), html.EscapeString(jobRun.JobName), html.EscapeString(jobRun.JobRunID), label)
+	}
+	ret += "</tr>\n"
+	for _, test := range grid.Tests {
+		ret += fmt.Sprintf(`<tr><th title="%s">%s</th>`, html.EscapeString(strings.Join(test.TestSuites, " / ")), html.EscapeString(test.TestName))
+		for i, result := range test.Results {
+			ret += fmt.Sprintf(`<td class="%s"><a target="_blank" href="%s">%s</a></td>`, result, html.EscapeString(grid.JobRuns[i].HumanURL), result)
+		}
+		ret += "</tr>\n"
+	}
+	ret += `</table>
+</body>
+</html>`
+	return ret
+}
@@ -0,0 +1,43 @@
+package jobruntestcaseanalyzer
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+const (
+	// unfinishedPolicySkip reads whatever junit unfinished job runs have, tests they didn't run yet are skips
+	unfinishedPolicySkip = "skip"
+	// unfinishedPolicyFail counts every test of unfinished job runs as failed
+	unfinishedPolicyFail = "fail"
+	// unfinishedPolicyWait waits for unfinished job runs until shortly before the analysis times out, then skips
+	unfinishedPolicyWait = "wait"
+
+	// unfinishedWaitMargin is left between the end of the extended wait and the analysis timeout to check test cases
+	unfinishedWaitMargin = 10 * time.Minute
+)
+
+var knownUnfinishedPolicies = sets.New[string](unfinishedPolicySkip, unfinishedPolicyFail, unfinishedPolicyWait)
+
+// unfinishedJobRunJunit stands for the junit of job runs that didn't finish when unfinished job runs fail: whatever
+// the test, its status in them is a failure.
+var unfinishedJobRunJunit = &junit.TestSuites{}
+
+// timeToStopWaiting extends the wait to the analysis timeout when unfinished job runs are waited for
+func (o *JobRunTestCaseAnalyzerOptions) timeToStopWaiting(ctx context.Context, timeToStopWaiting time.Time) time.Time {
+	if o.unfinishedPolicy != unfinishedPolicyWait {
+		return timeToStopWaiting
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return timeToStopWaiting
+	}
+	if extended := deadline.Add(-unfinishedWaitMargin); extended.After(timeToStopWaiting) {
+		return extended
+	}
+	return timeToStopWaiting
+}
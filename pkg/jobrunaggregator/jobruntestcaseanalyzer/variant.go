@@ -0,0 +1,165 @@
+package jobruntestcaseanalyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// jobVariant is a platform, network and infrastructure tuple analyzed on its own, like --platform, --network and
+// --infrastructure do for a single one.  Empty values match any job.
+type jobVariant struct {
+	platform       string
+	network        string
+	infrastructure string
+}
+
+// parseJobVariant reads <platform>,<network>,<infrastructure>, like aws,ovn,ipi
+func parseJobVariant(value string) (jobVariant, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 3 {
+		return jobVariant{}, fmt.Errorf("%q is not <platform>,<network>,<infrastructure>", value)
+	}
+	variant := jobVariant{
+		platform:       strings.TrimSpace(parts[0]),
+		network:        strings.TrimSpace(parts[1]),
+		infrastructure: strings.TrimSpace(parts[2]),
+	}
+	for _, field := range []struct {
+		name  string
+		value string
+		known sets.Set[string]
+	}{
+		{name: "platform", value: variant.platform, known: knownPlatforms},
+		{name: "network", value: variant.network, known: knownNetworks},
+		{name: "infrastructure", value: variant.infrastructure, known: knownInfrastructures},
+	} {
+		if len(field.value) > 0 && !field.known.Has(field.value) {
+			return jobVariant{}, fmt.Errorf("unknown %s %s in variant %q, valid values are: %+q", field.name, field.value, value, sets.List(field.known))
+		}
+	}
+	return variant, nil
+}
+
+func (v jobVariant) String() string {
+	return strings.Join([]string{v.platform, v.network, v.infrastructure}, ",")
+}
+
+// testNameSuffix names the variant in the test names, the way the suffix built from the flags does
+func (v jobVariant) testNameSuffix() string {
+	suffix := ""
+	if len(v.platform) > 0 {
+		suffix += fmt.Sprintf("platform:%s ", v.platform)
+	}
+	if len(v.network) > 0 {
+		suffix += fmt.Sprintf("network:%s ", v.network)
+	}
+	if len(v.infrastructure) > 0 {
+		suffix += fmt.Sprintf("infrastructure:%s ", v.infrastructure)
+	}
+	return strings.TrimSpace(suffix)
+}
+
+func (v jobVariant) matchesJob(job jobrunaggregatorapi.JobRowWithVariants) bool {
+	// static job runs and PR payloads only know the job name
+	if len(job.Platform) == 0 {
+		return v.matchesJobName(job.JobName)
+	}
+	return (len(v.platform) == 0 || v.platform == job.Platform) &&
+		(len(v.network) == 0 || v.network == job.Network) &&
+		(len(v.infrastructure) == 0 || v.infrastructure == getJobInfrastructure(job.JobName))
+}
+
+func (v jobVariant) matchesJobName(jobName string) bool {
+	return (len(v.platform) == 0 || strings.Contains(strings.ToLower(jobName), v.platform)) &&
+		(len(v.network) == 0 || v.network == networkFromJobName(jobName)) &&
+		(len(v.infrastructure) == 0 || v.infrastructure == getJobInfrastructure(jobName))
+}
+
+func anyVariantMatchesJob(variants []jobVariant, job jobrunaggregatorapi.JobRowWithVariants) bool {
+	for _, variant := range variants {
+		if variant.matchesJob(job) {
+			return true
+		}
+	}
+	return false
+}
+
+// variantJobs keeps the rows of the jobs located for the payload, the checkers only see job runs and have to tell
+// their variant from the row of their job.  Like jobArchitectures, it is filled in by GetRelatedJobRuns.
+type variantJobs struct {
+	lock      sync.RWMutex
+	byJobName map[string]jobrunaggregatorapi.JobRowWithVariants
+}
+
+func newVariantJobs() *variantJobs {
+	return &variantJobs{byJobName: map[string]jobrunaggregatorapi.JobRowWithVariants{}}
+}
+
+func (v *variantJobs) record(jobs []jobrunaggregatorapi.JobRowWithVariants) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	for _, job := range jobs {
+		v.byJobName[job.JobName] = job
+	}
+}
+
+func (v *variantJobs) matches(variant jobVariant, jobName string) bool {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+	job, ok := v.byJobName[jobName]
+	if !ok {
+		job = jobrunaggregatorapi.JobRowWithVariants{JobName: jobName}
+	}
+	return variant.matchesJob(job)
+}
+
+// variantTestCaseChecker runs the checkers of a variant against the job runs of the jobs of that variant only, so
+// that a single analysis gates several variants, each in its own suite.
+type variantTestCaseChecker struct {
+	variant  jobVariant
+	checkers []TestCaseChecker
+	jobs     *variantJobs
+}
+
+func (r variantTestCaseChecker) String() string {
+	return fmt.Sprintf("variant %s", r.variant)
+}
+
+func (r variantTestCaseChecker) gatedTests() []testIdentifier {
+	var ret []testIdentifier
+	for _, checker := range r.checkers {
+		if reporter, ok := checker.(gatedTestsReporter); ok {
+			ret = append(ret, reporter.gatedTests()...)
+		}
+	}
+	return ret
+}
+
+func (r variantTestCaseChecker) CheckTestCase(ctx context.Context, jobRunJunits map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) *junit.TestSuite {
+	variantJobRunJunits := map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites{}
+	for jobRun, testSuites := range jobRunJunits {
+		if r.jobs.matches(r.variant, jobRun.GetJobName()) {
+			variantJobRunJunits[jobRun] = testSuites
+		}
+	}
+
+	topSuite := &junit.TestSuite{
+		Name:      r.String(),
+		TestCases: []*junit.TestCase{},
+	}
+	for _, checker := range r.checkers {
+		if suite := checker.CheckTestCase(ctx, variantJobRunJunits); suite != nil {
+			topSuite.Children = append(topSuite.Children, suite)
+		}
+	}
+	jobrunaggregatorlib.UpdateTestCountsInSuite(topSuite)
+	return topSuite
+}
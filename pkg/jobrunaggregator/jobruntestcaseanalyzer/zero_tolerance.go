@@ -0,0 +1,124 @@
+package jobruntestcaseanalyzer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+// zeroToleranceTestCaseChecker fails on a single failure of the test across the job runs, whatever the number of
+// passes.  It is meant for tests guarding against regressions like data loss, where a flake can't be assumed.
+type zeroToleranceTestCaseChecker struct {
+	id             testIdentifier
+	testNameSuffix string
+}
+
+func (r zeroToleranceTestCaseChecker) String() string {
+	return r.id.testName
+}
+
+func (r zeroToleranceTestCaseChecker) gatedTests() []testIdentifier {
+	return []testIdentifier{r.id}
+}
+
+func (r zeroToleranceTestCaseChecker) CheckTestCase(ctx context.Context, jobRunJunits map[jobrunaggregatorapi.JobRunInfo]*junit.TestSuites) *junit.TestSuite {
+	topSuite := &junit.TestSuite{
+		Name:      "zero-tolerance-checker",
+		TestCases: []*junit.TestCase{},
+	}
+	bottomSuite := addToTestSuiteFromSuiteNames(topSuite, r.id.testSuites)
+
+	testName := fmt.Sprintf("test '%s' never fails across payload jobs", r.id.testName)
+	if len(r.testNameSuffix) > 0 {
+		testName += fmt.Sprintf(" for %s", r.testNameSuffix)
+	}
+	testCase := &junit.TestCase{
+		Name: testName,
+	}
+	bottomSuite.TestCases = append(bottomSuite.TestCases, testCase)
+
+	start := time.Now()
+	currDetails := &jobrunaggregatorlib.TestCaseDetails{
+		Name:          r.id.testName,
+		TestSuiteName: strings.Join(r.id.testSuites, jobrunaggregatorlib.TestSuitesSeparator),
+	}
+	// the details are shared with the minimum passes checker, so the reports look the same
+	details := minimumRequiredPassesTestCaseChecker{id: r.id}
+	for jobRun, testSuites := range jobRunJunits {
+		details.addTestResultToDetails(currDetails, jobRun, getTestStatusInJobRun(r.id, testSuites))
+	}
+	currDetails.Summary = fmt.Sprintf("Total job runs: %d, passes: %d, failures: %d, skips %d", len(jobRunJunits), len(currDetails.Passes), len(currDetails.Failures), len(currDetails.Skips))
+	if err := jobrunaggregatorlib.SetTestCaseDetails(testCase, currDetails); err != nil {
+		return nil
+	}
+	testCase.Duration = time.Since(start).Seconds()
+	// a test that passed on a rerun still failed once, which is not tolerated either
+	failedJobRuns := []string{}
+	for _, failure := range currDetails.Failures {
+		failedJobRuns = append(failedJobRuns, failure.HumanURL)
+	}
+	for _, flake := range currDetails.Flakes {
+		failedJobRuns = append(failedJobRuns, flake.HumanURL)
+	}
+	if len(failedJobRuns) > 0 {
+		sort.Strings(failedJobRuns)
+		testCase.FailureOutput = &junit.FailureOutput{
+			Message: fmt.Sprintf("zero tolerance test failed in %d of %d job runs", len(failedJobRuns), len(jobRunJunits)),
+			Output:  strings.Join(failedJobRuns, "\n"),
+		}
+	}
+	jobrunaggregatorlib.UpdateTestCountsInSuite(topSuite)
+	return topSuite
+}
+
+// parseTestIdentifier reads a test group name, or <suite>[|||<child suite>...]=<test name>, or
+// <suite>[|||<child suite>...]=~<test name> where the suites and the test name are regular expressions matching
+// whole names
+func parseTestIdentifier(value string) (testIdentifier, error) {
+	if id, ok := testIdentifiersByGroup[value]; ok {
+		return id, nil
+	}
+	if suites, testName, found := strings.Cut(value, "=~"); found {
+		return parseTestIdentifierRegexes(value, suites, testName)
+	}
+	suites, testName, found := strings.Cut(value, "=")
+	if !found || len(suites) == 0 || len(testName) == 0 {
+		return testIdentifier{}, fmt.Errorf("%q is neither a test group nor <suite>=<test name>", value)
+	}
+	return testIdentifier{testSuites: strings.Split(suites, jobrunaggregatorlib.TestSuitesSeparator), testName: testName}, nil
+}
+
+func parseTestIdentifierRegexes(value, suites, testName string) (testIdentifier, error) {
+	if len(suites) == 0 || len(testName) == 0 {
+		return testIdentifier{}, fmt.Errorf("%q is neither a test group nor <suite>=~<test name regex>", value)
+	}
+	id := testIdentifier{
+		testSuites: strings.Split(suites, jobrunaggregatorlib.TestSuitesSeparator),
+		testName:   testName,
+		regexes:    &testIdentifierRegexes{},
+	}
+	for _, suite := range id.testSuites {
+		re, err := compileWholeNameRegex(suite)
+		if err != nil {
+			return testIdentifier{}, fmt.Errorf("invalid suite in %q: %w", value, err)
+		}
+		id.regexes.testSuites = append(id.regexes.testSuites, re)
+	}
+	re, err := compileWholeNameRegex(testName)
+	if err != nil {
+		return testIdentifier{}, fmt.Errorf("invalid test name in %q: %w", value, err)
+	}
+	id.regexes.testName = re
+	return id, nil
+}
+
+func compileWholeNameRegex(expression string) (*regexp.Regexp, error) {
+	return regexp.Compile(fmt.Sprintf("^(?:%s)$", expression))
+}
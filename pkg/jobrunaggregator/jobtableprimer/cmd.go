@@ -14,7 +14,9 @@ import (
 
 type primeJobTableFlags struct {
 	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
+	QueryCost       *jobrunaggregatorlib.QueryCostFlags
 	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
+	DryRunOutput    *jobrunaggregatorlib.DryRunOutputFlags
 
 	DryRun    bool
 	GCSBucket string
@@ -23,13 +25,17 @@ type primeJobTableFlags struct {
 func newPrimeJobTableFlags() *primeJobTableFlags {
 	return &primeJobTableFlags{
 		DataCoordinates: jobrunaggregatorlib.NewBigQueryDataCoordinates(),
+		QueryCost:       jobrunaggregatorlib.NewQueryCostFlags(),
 		Authentication:  jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+		DryRunOutput:    jobrunaggregatorlib.NewDryRunOutputFlags(),
 	}
 }
 
 func (f *primeJobTableFlags) BindFlags(fs *pflag.FlagSet) {
 	f.DataCoordinates.BindFlags(fs)
+	f.QueryCost.BindFlags(fs)
 	f.Authentication.BindFlags(fs)
+	f.DryRunOutput.BindFlags(fs)
 
 	fs.BoolVar(&f.DryRun, "dry-run", f.DryRun, "Run the command, but don't mutate data.")
 	fs.StringVar(&f.GCSBucket, "google-storage-bucket", "test-platform-results", "The optional GCS Bucket holding test artifacts")
@@ -74,6 +80,9 @@ func (f *primeJobTableFlags) Validate() error {
 	if err := f.DataCoordinates.Validate(); err != nil {
 		return err
 	}
+	if err := f.QueryCost.Validate(); err != nil {
+		return err
+	}
 	if err := f.Authentication.Validate(); err != nil {
 		return err
 	}
@@ -94,12 +103,12 @@ func (f *primeJobTableFlags) ToOptions(ctx context.Context) (*CreateJobsOptions,
 		jobTable := ciDataSet.Table(jobrunaggregatorapi.JobsTableName)
 		jobTableInserter = jobTable.Inserter()
 	} else {
-		jobTableInserter = jobrunaggregatorlib.NewDryRunInserter(os.Stdout, jobrunaggregatorapi.JobsTableName)
+		jobTableInserter = f.DryRunOutput.NewInserter(os.Stdout, jobrunaggregatorapi.JobsTableName)
 	}
 
 	return &CreateJobsOptions{
 		ciDataClient: jobrunaggregatorlib.NewRetryingCIDataClient(
-			jobrunaggregatorlib.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
+			f.QueryCost.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
 		),
 
 		jobInserter: jobTableInserter,
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
 	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
 )
@@ -59,13 +61,13 @@ func (o *CreateJobsOptions) createJobRowsFromReleases(ctx context.Context, ciDat
 }
 
 func (o *CreateJobsOptions) Run(ctx context.Context) error {
-	fmt.Printf("Creating jobs from releases\n")
+	logrus.Info("Creating jobs from releases")
 	jobsToCreate, err := o.createJobRowsFromReleases(ctx, o.ciDataClient)
 	if err != nil {
 		return fmt.Errorf("failed to create job rows from releases: %w", err)
 	}
 
-	fmt.Printf("Priming jobs\n")
+	logrus.Info("Priming jobs")
 
 	existingJobs, err := o.ciDataClient.ListAllJobs(ctx)
 	if err != nil {
@@ -90,7 +92,7 @@ func (o *CreateJobsOptions) Run(ctx context.Context) error {
 		missingJobs = append(missingJobs, jobToCreate)
 	}
 
-	fmt.Printf("Inserting %d jobs\n", len(missingJobs))
+	logrus.WithField("jobs", len(missingJobs)).Info("Inserting jobs")
 	if err := o.jobInserter.Put(ctx, missingJobs); err != nil {
 		return err
 	}
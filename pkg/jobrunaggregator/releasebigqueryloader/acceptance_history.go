@@ -0,0 +1,194 @@
+package releasebigqueryloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+)
+
+const (
+	acceptanceHistoryFormatMarkdown = "markdown"
+	acceptanceHistoryFormatJSON     = "json"
+
+	// aggregatedJobPrefix names the release controller jobs that run job-run-aggregator, their result is the
+	// verdict of the analyzer.
+	aggregatedJobPrefix = "aggregated-"
+)
+
+type acceptanceHistoryOptions struct {
+	ciDataClient jobrunaggregatorlib.CIDataClient
+
+	release      string
+	stream       string
+	architecture string
+	since        time.Time
+	outputFormat string
+	out          io.Writer
+}
+
+// payloadAcceptance is the decision of the release controller on one payload, along with what led to it.
+type payloadAcceptance struct {
+	ReleaseTag  string    `json:"releaseTag"`
+	Phase       string    `json:"phase"`
+	ReleaseTime time.Time `json:"releaseTime"`
+	// FailedBlockingJobs are the reasons of a rejection.  Aggregated jobs are listed first, they carry the verdict of
+	// the analyzer.
+	FailedBlockingJobs []failedReleaseJob `json:"failedBlockingJobs"`
+	// GateOverrides are the test cases release architects force-accepted for the payload.
+	GateOverrides []gateOverride `json:"gateOverrides"`
+}
+
+type failedReleaseJob struct {
+	JobName    string `json:"jobName"`
+	Aggregated bool   `json:"aggregated"`
+	State      string `json:"state"`
+	Retries    int    `json:"retries"`
+	URL        string `json:"url"`
+}
+
+type gateOverride struct {
+	JobName         string `json:"jobName"`
+	TestGroup       string `json:"testGroup,omitempty"`
+	TestName        string `json:"testName"`
+	OriginalMessage string `json:"originalMessage"`
+	IssuedBy        string `json:"issuedBy"`
+	Reason          string `json:"reason"`
+}
+
+func (o *acceptanceHistoryOptions) Run(ctx context.Context) error {
+	history, err := o.getAcceptanceHistory(ctx)
+	if err != nil {
+		return err
+	}
+	if o.outputFormat == acceptanceHistoryFormatJSON {
+		encoder := json.NewEncoder(o.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(history)
+	}
+	_, err = io.WriteString(o.out, markdownForAcceptanceHistory(fmt.Sprintf("%s.0-0.%s", o.release, o.stream), o.architecture, history))
+	return err
+}
+
+func (o *acceptanceHistoryOptions) getAcceptanceHistory(ctx context.Context) ([]payloadAcceptance, error) {
+	releaseTags, err := o.ciDataClient.ListReleaseTagsForStream(ctx, o.release, o.stream, o.architecture, o.since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payloads of %s %s %s: %w", o.release, o.stream, o.architecture, err)
+	}
+	if len(releaseTags) == 0 {
+		return []payloadAcceptance{}, nil
+	}
+	tagNames := []string{}
+	for _, releaseTag := range releaseTags {
+		tagNames = append(tagNames, releaseTag.ReleaseTag)
+	}
+	jobRuns, err := o.ciDataClient.ListReleaseJobRunsForReleaseTags(ctx, tagNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list release job runs: %w", err)
+	}
+	overrides, err := o.ciDataClient.ListGateOverridesForPayloadTags(ctx, tagNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list gate overrides: %w", err)
+	}
+	return newAcceptanceHistory(releaseTags, jobRuns, overrides), nil
+}
+
+func newAcceptanceHistory(releaseTags []jobrunaggregatorapi.ReleaseTagRow, jobRuns []jobrunaggregatorapi.ReleaseJobRunRow, overrides []jobrunaggregatorapi.GateOverrideRow) []payloadAcceptance {
+	failedJobsByTag := map[string][]failedReleaseJob{}
+	for _, jobRun := range jobRuns {
+		if jobRun.Kind != "Blocking" || jobRun.State != "Failed" {
+			continue
+		}
+		failedJobsByTag[jobRun.ReleaseTag] = append(failedJobsByTag[jobRun.ReleaseTag], failedReleaseJob{
+			JobName:    jobRun.JobName,
+			Aggregated: strings.HasPrefix(jobRun.JobName, aggregatedJobPrefix),
+			State:      jobRun.State,
+			Retries:    jobRun.Retries,
+			URL:        jobRun.URL,
+		})
+	}
+	overridesByTag := map[string][]gateOverride{}
+	for _, override := range overrides {
+		overridesByTag[override.PayloadTag] = append(overridesByTag[override.PayloadTag], gateOverride{
+			JobName:         override.JobName,
+			TestGroup:       override.TestGroup,
+			TestName:        override.TestName,
+			OriginalMessage: override.OriginalMessage,
+			IssuedBy:        override.IssuedBy,
+			Reason:          override.Reason,
+		})
+	}
+
+	ret := []payloadAcceptance{}
+	for _, releaseTag := range releaseTags {
+		failedJobs := failedJobsByTag[releaseTag.ReleaseTag]
+		if failedJobs == nil {
+			failedJobs = []failedReleaseJob{}
+		}
+		// aggregated jobs first, the order is otherwise the one of the query
+		aggregated, others := []failedReleaseJob{}, []failedReleaseJob{}
+		for _, failedJob := range failedJobs {
+			if failedJob.Aggregated {
+				aggregated = append(aggregated, failedJob)
+			} else {
+				others = append(others, failedJob)
+			}
+		}
+		gateOverrides := overridesByTag[releaseTag.ReleaseTag]
+		if gateOverrides == nil {
+			gateOverrides = []gateOverride{}
+		}
+		ret = append(ret, payloadAcceptance{
+			ReleaseTag:         releaseTag.ReleaseTag,
+			Phase:              releaseTag.Phase,
+			ReleaseTime:        releaseTag.ReleaseTime,
+			FailedBlockingJobs: append(aggregated, others...),
+			GateOverrides:      gateOverrides,
+		})
+	}
+	return ret
+}
+
+func markdownForAcceptanceHistory(streamName, architecture string, history []payloadAcceptance) string {
+	accepted := 0
+	for _, payload := range history {
+		if payload.Phase == "Accepted" {
+			accepted++
+		}
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "# %s %s acceptance history\n\n", streamName, architecture)
+	fmt.Fprintf(sb, "%d of %d payloads accepted.\n\n", accepted, len(history))
+	if len(history) == 0 {
+		return sb.String()
+	}
+	fmt.Fprintf(sb, "| Payload | Phase | Created | Reasons |\n")
+	fmt.Fprintf(sb, "| --- | --- | --- | --- |\n")
+	for _, payload := range history {
+		reasons := []string{}
+		for _, failedJob := range payload.FailedBlockingJobs {
+			reason := fmt.Sprintf("[%s](%s) failed", failedJob.JobName, failedJob.URL)
+			if failedJob.Retries > 0 {
+				reason += fmt.Sprintf(" after %d retries", failedJob.Retries)
+			}
+			reasons = append(reasons, reason)
+		}
+		for _, override := range payload.GateOverrides {
+			overriddenIn := override.JobName
+			if len(overriddenIn) == 0 {
+				overriddenIn = "test group " + override.TestGroup
+			}
+			reasons = append(reasons, fmt.Sprintf("%s overrode `%s` in %s: %s", override.IssuedBy, override.TestName, overriddenIn, override.Reason))
+		}
+		fmt.Fprintf(sb, "| %s | %s | %s | %s |\n",
+			payload.ReleaseTag, payload.Phase, payload.ReleaseTime.UTC().Format(time.RFC3339),
+			strings.ReplaceAll(strings.Join(reasons, "<br>"), "|", `\|`))
+	}
+	return sb.String()
+}
@@ -0,0 +1,61 @@
+package releasebigqueryloader
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorapi"
+	"github.com/openshift/ci-tools/pkg/jobrunaggregator/jobrunaggregatorlib"
+)
+
+func TestAcceptanceHistoryRun(t *testing.T) {
+	since := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	rejectedTag := "4.15.0-0.nightly-2023-10-03-000000"
+	acceptedTag := "4.15.0-0.nightly-2023-10-02-000000"
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockDataClient := jobrunaggregatorlib.NewMockCIDataClient(mockCtrl)
+	mockDataClient.EXPECT().ListReleaseTagsForStream(gomock.Any(), "4.15", "nightly", "amd64", since).Return([]jobrunaggregatorapi.ReleaseTagRow{
+		{ReleaseTag: rejectedTag, Phase: "Rejected", ReleaseTime: since.Add(48 * time.Hour)},
+		{ReleaseTag: acceptedTag, Phase: "Accepted", ReleaseTime: since.Add(24 * time.Hour)},
+	}, nil)
+	mockDataClient.EXPECT().ListReleaseJobRunsForReleaseTags(gomock.Any(), []string{rejectedTag, acceptedTag}).Return([]jobrunaggregatorapi.ReleaseJobRunRow{
+		{ReleaseTag: rejectedTag, JobName: "aws-ovn-serial", Kind: "Blocking", State: "Failed", Retries: 2, URL: "https://prow/1"},
+		{ReleaseTag: rejectedTag, JobName: "aggregated-aws-ovn-upgrade-4.15-micro", Kind: "Blocking", State: "Failed", URL: "https://prow/2"},
+		{ReleaseTag: rejectedTag, JobName: "metal-ipi", Kind: "Informing", State: "Failed", URL: "https://prow/3"},
+		{ReleaseTag: acceptedTag, JobName: "aggregated-aws-ovn-upgrade-4.15-micro", Kind: "Blocking", State: "Succeeded", URL: "https://prow/4"},
+	}, nil)
+	mockDataClient.EXPECT().ListGateOverridesForPayloadTags(gomock.Any(), []string{rejectedTag, acceptedTag}).Return([]jobrunaggregatorapi.GateOverrideRow{
+		{PayloadTag: acceptedTag, JobName: "aggregated-aws-ovn-upgrade-4.15-micro", TestName: "[sig-network] pods should work", IssuedBy: "release-architect", Reason: "OCPBUGS-1"},
+		{PayloadTag: acceptedTag, TestGroup: "install", TestName: "install should succeed: overall", IssuedBy: "release-architect", Reason: "OCPBUGS-2"},
+	}, nil)
+
+	out := &bytes.Buffer{}
+	o := &acceptanceHistoryOptions{
+		ciDataClient: mockDataClient,
+		release:      "4.15",
+		stream:       "nightly",
+		architecture: "amd64",
+		since:        since,
+		outputFormat: acceptanceHistoryFormatMarkdown,
+		out:          out,
+	}
+	if err := o.Run(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# 4.15.0-0.nightly amd64 acceptance history\n\n" +
+		"1 of 2 payloads accepted.\n\n" +
+		"| Payload | Phase | Created | Reasons |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| 4.15.0-0.nightly-2023-10-03-000000 | Rejected | 2023-10-03T00:00:00Z | [aggregated-aws-ovn-upgrade-4.15-micro](https://prow/2) failed<br>[aws-ovn-serial](https://prow/1) failed after 2 retries |\n" +
+		"| 4.15.0-0.nightly-2023-10-02-000000 | Accepted | 2023-10-02T00:00:00Z | release-architect overrode `[sig-network] pods should work` in aggregated-aws-ovn-upgrade-4.15-micro: OCPBUGS-1<br>release-architect overrode `install should succeed: overall` in test group install: OCPBUGS-2 |\n"
+	if out.String() != expected {
+		t.Errorf("unexpected markdown:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
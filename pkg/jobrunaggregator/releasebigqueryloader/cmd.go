@@ -2,7 +2,9 @@ package releasebigqueryloader
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -104,18 +106,24 @@ func (f *BigQueryReleaseUploadFlags) ToOptions(ctx context.Context) (*allRelease
 type BigQueryReleaseTableCreateFlags struct {
 	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
 	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
+	TablePolicy     *jobrunaggregatorlib.TablePolicyFlags
+
+	DryRun bool
 }
 
 func NewBigQueryReleaseTableCreateFlags() *BigQueryReleaseTableCreateFlags {
 	return &BigQueryReleaseTableCreateFlags{
 		DataCoordinates: jobrunaggregatorlib.NewBigQueryDataCoordinates(),
 		Authentication:  jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+		TablePolicy:     jobrunaggregatorlib.NewTablePolicyFlags(),
 	}
 }
 
 func (f *BigQueryReleaseTableCreateFlags) BindFlags(fs *pflag.FlagSet) {
 	f.DataCoordinates.BindFlags(fs)
 	f.Authentication.BindFlags(fs)
+	f.TablePolicy.BindFlags(fs)
+	fs.BoolVar(&f.DryRun, "dry-run", f.DryRun, "Print the tables that would be created and the columns that would be added to existing tables, without changing them.")
 }
 
 func NewBigQueryReleaseTableCreateFlagsCommand() *cobra.Command {
@@ -123,7 +131,7 @@ func NewBigQueryReleaseTableCreateFlagsCommand() *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:          "create-releases",
-		Long:         `Create release tables in bigquery`,
+		Long:         `Create release tables in bigquery, or add the columns they are missing`,
 		SilenceUsage: true,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -160,6 +168,9 @@ func (f *BigQueryReleaseTableCreateFlags) Validate() error {
 	if err := f.Authentication.Validate(); err != nil {
 		return err
 	}
+	if err := f.TablePolicy.Validate(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -179,5 +190,122 @@ func (f *BigQueryReleaseTableCreateFlags) ToOptions(ctx context.Context) (*allRe
 	return &allReleaseTableCreatorOptions{
 		ciDataClient: ciDataClient,
 		ciDataSet:    ciDataSet,
+		tablePolicy:  f.TablePolicy,
+		dryRun:       f.DryRun,
+	}, nil
+}
+
+type AcceptanceHistoryFlags struct {
+	DataCoordinates *jobrunaggregatorlib.BigQueryDataCoordinates
+	Authentication  *jobrunaggregatorlib.GoogleAuthenticationFlags
+
+	Release      string
+	Stream       string
+	Architecture string
+	Lookback     time.Duration
+	OutputFormat string
+}
+
+func NewAcceptanceHistoryFlags() *AcceptanceHistoryFlags {
+	return &AcceptanceHistoryFlags{
+		DataCoordinates: jobrunaggregatorlib.NewBigQueryDataCoordinates(),
+		Authentication:  jobrunaggregatorlib.NewGoogleAuthenticationFlags(),
+
+		Stream:       "nightly",
+		Architecture: "amd64",
+		Lookback:     14 * 24 * time.Hour,
+		OutputFormat: acceptanceHistoryFormatMarkdown,
+	}
+}
+
+func (f *AcceptanceHistoryFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DataCoordinates.BindFlags(fs)
+	f.Authentication.BindFlags(fs)
+	fs.StringVar(&f.Release, "release", f.Release, "The X.Y release of the stream, like 4.15")
+	fs.StringVar(&f.Stream, "stream", f.Stream, "The release stream, like nightly or ci")
+	fs.StringVar(&f.Architecture, "architecture", f.Architecture, "The architecture of the release stream, like amd64")
+	fs.DurationVar(&f.Lookback, "lookback", f.Lookback, "How far back to list payloads")
+	fs.StringVar(&f.OutputFormat, "output-format", f.OutputFormat, "The output format, either markdown or json")
+}
+
+func NewAcceptanceHistoryCommand() *cobra.Command {
+	f := NewAcceptanceHistoryFlags()
+
+	cmd := &cobra.Command{
+		Use:          "payload-acceptance-history",
+		Long:         `Print which payloads of a release stream were accepted or rejected, and why`,
+		SilenceUsage: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			if err := f.Validate(); err != nil {
+				logrus.WithError(err).Fatal("Flags are invalid")
+			}
+			o, err := f.ToOptions(ctx)
+			if err != nil {
+				logrus.WithError(err).Fatal("Failed to build runtime options")
+			}
+
+			if err := o.Run(ctx); err != nil {
+				logrus.WithError(err).Fatal("Command failed")
+			}
+
+			return nil
+		},
+
+		Args: jobrunaggregatorlib.NoArgs,
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+// Validate checks to see if the user-input is likely to produce functional runtime options
+func (f *AcceptanceHistoryFlags) Validate() error {
+	if err := f.DataCoordinates.Validate(); err != nil {
+		return err
+	}
+	if err := f.Authentication.Validate(); err != nil {
+		return err
+	}
+	if len(f.Release) == 0 {
+		return fmt.Errorf("missing --release")
+	}
+	if len(f.Stream) == 0 || len(f.Architecture) == 0 {
+		return fmt.Errorf("--stream and --architecture must not be empty")
+	}
+	if f.Lookback <= 0 {
+		return fmt.Errorf("--lookback must be positive")
+	}
+	switch f.OutputFormat {
+	case acceptanceHistoryFormatMarkdown, acceptanceHistoryFormatJSON:
+	default:
+		return fmt.Errorf("--output-format must be %s or %s", acceptanceHistoryFormatMarkdown, acceptanceHistoryFormatJSON)
+	}
+
+	return nil
+}
+
+// ToOptions goes from the user input to the runtime values need to run the command.
+// Expect to see unit tests on the options, but not on the flags which are simply value mappings.
+func (f *AcceptanceHistoryFlags) ToOptions(ctx context.Context) (*acceptanceHistoryOptions, error) {
+	bigQueryClient, err := f.Authentication.NewBigQueryClient(ctx, f.DataCoordinates.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	ciDataClient := jobrunaggregatorlib.NewRetryingCIDataClient(
+		jobrunaggregatorlib.NewCIDataClient(*f.DataCoordinates, bigQueryClient),
+	)
+
+	return &acceptanceHistoryOptions{
+		ciDataClient: ciDataClient,
+		release:      f.Release,
+		stream:       f.Stream,
+		architecture: f.Architecture,
+		since:        time.Now().Add(-f.Lookback),
+		outputFormat: f.OutputFormat,
+		out:          os.Stdout,
 	}, nil
 }
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"os"
 	"path"
 	"regexp"
 	"strings"
@@ -14,6 +13,7 @@ import (
 
 	"cloud.google.com/go/bigquery"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	"k8s.io/klog/v2"
 
@@ -65,18 +65,18 @@ func (r *allReleaseUploaderOptions) Run(ctx context.Context) error {
 	}
 
 	for _, release := range r.releases {
-		fmt.Fprintf(os.Stderr, "Fetching release %s from release controller...\n", release)
+		logrus.WithField("release", release).Info("Fetching release from release controller")
 		allTags := r.fetchReleaseTags(release)
 
 		for _, tags := range allTags {
 			for _, tag := range tags.Tags {
 				// Skip release tags that are already in BigQuery
 				if _, ok := releaseTagSet[tag.Name]; ok {
-					fmt.Fprintf(os.Stderr, "%s is already present, skipping...\n", tag.Name)
+					logrus.WithField("payloadTag", tag.Name).Debug("Release tag is already present, skipping")
 					continue
 				}
 
-				fmt.Fprintf(os.Stderr, "Fetching tag %s from release controller...\n", tag.Name)
+				logrus.WithField("payloadTag", tag.Name).Info("Fetching tag from release controller")
 				releaseDetails := r.fetchReleaseDetails(tags.Architecture, release, tag)
 				releaseTag, repositories, pullRequests := releaseDetailsToBigQuery(tags.Architecture, tag, releaseDetails)
 				// We skip releases that aren't fully baked (i.e. all jobs run and changelog calculated)
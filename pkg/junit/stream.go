@@ -0,0 +1,249 @@
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DecodeOptions tune DecodeTestSuites
+type DecodeOptions struct {
+	// MaxOutputBytes truncates the system-out and system-err of test cases to this many bytes when positive
+	MaxOutputBytes int
+}
+
+// DecodeTestSuites reads jUnit holding either <testsuites> or a single <testsuite> one test case at a time, and calls
+// fn with every top-level suite once it was read.  Unlike xml.Unmarshal, the whole document is never held in memory,
+// and the outputs over options.MaxOutputBytes are dropped as they are read.  When reading fails, fn is still called
+// with the suite that was being read, minus the test case that was cut, and the error is returned.
+func DecodeTestSuites(reader io.Reader, options DecodeOptions, fn func(*TestSuite)) error {
+	decoder := xml.NewDecoder(reader)
+	root, err := nextStartElement(decoder)
+	if err != nil {
+		return err
+	}
+	switch root.Name.Local {
+	case "testsuites":
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			switch element := token.(type) {
+			case xml.StartElement:
+				if element.Name.Local != "testsuite" {
+					if err := decoder.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				suite, err := decodeTestSuite(decoder, element, options)
+				fn(suite)
+				if err != nil {
+					return err
+				}
+			case xml.EndElement:
+				return nil
+			}
+		}
+	case "testsuite":
+		suite, err := decodeTestSuite(decoder, root, options)
+		fn(suite)
+		return err
+	default:
+		return fmt.Errorf("expected element type <testsuites> or <testsuite> but have <%s>", root.Name.Local)
+	}
+}
+
+func nextStartElement(decoder *xml.Decoder) (xml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+func decodeTestSuite(decoder *xml.Decoder, start xml.StartElement, options DecodeOptions) (*TestSuite, error) {
+	suite := &TestSuite{XMLName: start.Name}
+	for _, attr := range start.Attr {
+		var err error
+		switch attr.Name.Local {
+		case "name":
+			suite.Name = attr.Value
+		case "tests":
+			suite.NumTests, err = parseUint(attr.Value)
+		case "skipped":
+			suite.NumSkipped, err = parseUint(attr.Value)
+		case "failures":
+			suite.NumFailed, err = parseUint(attr.Value)
+		case "time":
+			suite.Duration, err = parseFloat(attr.Value)
+		}
+		if err != nil {
+			return suite, err
+		}
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return suite, err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "testcase":
+				testCase, err := decodeTestCase(decoder, element, options)
+				if err != nil {
+					// a test case cut before its <failure> would count as a pass
+					return suite, err
+				}
+				suite.TestCases = append(suite.TestCases, testCase)
+			case "testsuite":
+				child, err := decodeTestSuite(decoder, element, options)
+				suite.Children = append(suite.Children, child)
+				if err != nil {
+					return suite, err
+				}
+			case "properties":
+				properties, err := decodeProperties(decoder, element)
+				if err != nil {
+					return suite, err
+				}
+				suite.Properties = append(suite.Properties, properties...)
+			default:
+				if err := decoder.Skip(); err != nil {
+					return suite, err
+				}
+			}
+		case xml.EndElement:
+			return suite, nil
+		}
+	}
+}
+
+func decodeTestCase(decoder *xml.Decoder, start xml.StartElement, options DecodeOptions) (*TestCase, error) {
+	testCase := &TestCase{XMLName: start.Name}
+	for _, attr := range start.Attr {
+		var err error
+		switch attr.Name.Local {
+		case "name":
+			testCase.Name = attr.Value
+		case "classname":
+			testCase.Classname = attr.Value
+		case "time":
+			testCase.Duration, err = parseFloat(attr.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "skipped":
+				testCase.SkipMessage = &SkipMessage{}
+				err = decoder.DecodeElement(testCase.SkipMessage, &element)
+			case "failure":
+				testCase.FailureOutput = &FailureOutput{}
+				err = decoder.DecodeElement(testCase.FailureOutput, &element)
+			case "properties":
+				var properties []*TestSuiteProperty
+				properties, err = decodeProperties(decoder, element)
+				testCase.Properties = append(testCase.Properties, properties...)
+			case "system-out":
+				testCase.SystemOut, err = decodeOutput(decoder, options.MaxOutputBytes)
+			case "system-err":
+				testCase.SystemErr, err = decodeOutput(decoder, options.MaxOutputBytes)
+			default:
+				err = decoder.Skip()
+			}
+			if err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			return testCase, nil
+		}
+	}
+}
+
+func decodeProperties(decoder *xml.Decoder, start xml.StartElement) ([]*TestSuiteProperty, error) {
+	properties := struct {
+		Properties []*TestSuiteProperty `xml:"property"`
+	}{}
+	if err := decoder.DecodeElement(&properties, &start); err != nil {
+		return nil, err
+	}
+	return properties.Properties, nil
+}
+
+// decodeOutput reads the text of the element up to maxBytes when positive, the rest is counted but not kept
+func decodeOutput(decoder *xml.Decoder, maxBytes int) (string, error) {
+	output := strings.Builder{}
+	dropped := 0
+	for depth := 0; ; {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", err
+		}
+		switch element := token.(type) {
+		case xml.CharData:
+			if depth > 0 {
+				continue
+			}
+			kept := element
+			if maxBytes > 0 && output.Len()+len(kept) > maxBytes {
+				// cut on a rune boundary, the outputs are stored as UTF-8 strings
+				cut := maxBytes - output.Len()
+				for cut > 0 && !utf8.RuneStart(kept[cut]) {
+					cut--
+				}
+				kept = kept[:cut]
+			}
+			output.Write(kept)
+			dropped += len(element) - len(kept)
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				if dropped > 0 {
+					fmt.Fprintf(&output, "\n... %d more bytes were dropped", dropped)
+				}
+				return output.String(), nil
+			}
+			depth--
+		}
+	}
+}
+
+// parseUint reads an empty value as 0, like xml.Unmarshal does
+func parseUint(value string) (uint, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return 0, nil
+	}
+	parsed, err := strconv.ParseUint(value, 10, strconv.IntSize)
+	return uint(parsed), err
+}
+
+// parseFloat reads an empty value as 0, like xml.Unmarshal does
+func parseFloat(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
@@ -0,0 +1,104 @@
+package junit
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDecodeTestSuites(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		junit          string
+		maxOutputBytes int
+		expectedErr    bool
+	}{
+		{
+			name:  "test suites",
+			junit: junitXML,
+		},
+		{
+			name:  "single test suite with nested suites",
+			junit: `<testsuite name="top" tests="2"><testsuite name="child"><testcase name="nested"><failure message="boom">output</failure></testcase></testsuite><testcase name="skipped"><skipped message="not now"/></testcase></testsuite>`,
+		},
+		{
+			name:  "outputs",
+			junit: `<testsuites><testsuite name="e2e"><testcase name="noisy"><system-out>stdout</system-out><system-err><![CDATA[stderr]]></system-err></testcase></testsuite></testsuites>`,
+		},
+		{
+			name:  "empty attributes",
+			junit: `<testsuites><testsuite name="e2e" tests="" skipped="" failures="" time=""><testcase name="untimed" time=""></testcase></testsuite></testsuites>`,
+		},
+		{
+			name:        "cut",
+			junit:       `<testsuites><testsuite name="e2e"><testcase name="passes"></testcase><testcase name="fails"><fail`,
+			expectedErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var suites []*TestSuite
+			err := DecodeTestSuites(strings.NewReader(testCase.junit), DecodeOptions{}, func(suite *TestSuite) {
+				suites = append(suites, suite)
+			})
+			if testCase.expectedErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", testCase.expectedErr, err)
+			}
+			if testCase.expectedErr {
+				if len(suites) != 1 || len(suites[0].TestCases) != 1 || suites[0].TestCases[0].Name != "passes" {
+					t.Errorf("expected the suite read before the error without the cut test case, got %+v", suites)
+				}
+				return
+			}
+
+			// the streamed suites must be the unmarshalled ones
+			expected := &TestSuites{}
+			if err := xml.Unmarshal([]byte(testCase.junit), expected); err != nil {
+				expected.Suites = []*TestSuite{{}}
+				if err := xml.Unmarshal([]byte(testCase.junit), expected.Suites[0]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !reflect.DeepEqual(expected.Suites, suites) {
+				t.Errorf("expected %+v, got %+v", expected.Suites, suites)
+			}
+		})
+	}
+}
+
+func TestDecodeTestSuitesMaxOutputBytes(t *testing.T) {
+	junit := `<testsuite name="e2e"><testcase name="noisy"><system-out>0123456789</system-out><system-err>short</system-err></testcase></testsuite>`
+	var suites []*TestSuite
+	if err := DecodeTestSuites(strings.NewReader(junit), DecodeOptions{MaxOutputBytes: 6}, func(suite *TestSuite) {
+		suites = append(suites, suite)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	testCase := suites[0].TestCases[0]
+	if expected := "012345\n... 4 more bytes were dropped"; testCase.SystemOut != expected {
+		t.Errorf("expected system-out %q, got %q", expected, testCase.SystemOut)
+	}
+	if testCase.SystemErr != "short" {
+		t.Errorf("expected system-err %q, got %q", "short", testCase.SystemErr)
+	}
+}
+
+func TestDecodeTestSuitesMaxOutputBytesUTF8(t *testing.T) {
+	// "é" is two bytes, the cut falls in the middle of the second one
+	junit := `<testsuite name="e2e"><testcase name="noisy"><system-out>aééé</system-out></testcase></testsuite>`
+	var suites []*TestSuite
+	if err := DecodeTestSuites(strings.NewReader(junit), DecodeOptions{MaxOutputBytes: 4}, func(suite *TestSuite) {
+		suites = append(suites, suite)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	systemOut := suites[0].TestCases[0].SystemOut
+	if expected := "aé\n... 4 more bytes were dropped"; systemOut != expected {
+		t.Errorf("expected system-out %q, got %q", expected, systemOut)
+	}
+	if !utf8.ValidString(systemOut) {
+		t.Errorf("expected valid UTF-8, got %q", systemOut)
+	}
+}
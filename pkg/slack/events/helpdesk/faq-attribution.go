@@ -0,0 +1,59 @@
+package helpdesk
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	helpdeskfaq "github.com/openshift/ci-tools/pkg/helpdesk-faq"
+)
+
+// answerAttributor tells official helpdesk answers apart from community answers. The helpdesk
+// user group is kept in sync with the helpdesk rotation, and is looked up at most once.
+type answerAttributor struct {
+	client    slackClient
+	userGroup string
+	logger    *logrus.Entry
+
+	loaded  bool
+	members []string
+}
+
+func newAnswerAttributor(client slackClient, userGroup string, logger *logrus.Entry) *answerAttributor {
+	return &answerAttributor{client: client, userGroup: userGroup, logger: logger}
+}
+
+// attribution is empty when the rotation can't be determined, rather than wrongly claiming a community answer
+func (a *answerAttributor) attribution(author string) helpdeskfaq.AnswerAttribution {
+	if !a.loaded {
+		members, err := getUserGroupMembers(a.client, a.userGroup)
+		if err != nil {
+			a.logger.WithError(err).Warn("unable to determine the helpdesk rotation, answers won't be attributed")
+		}
+		a.members = members
+		a.loaded = true
+	}
+	if a.members == nil {
+		return ""
+	}
+	if slices.Contains(a.members, author) {
+		return helpdeskfaq.AnswerAttributionHelpdesk
+	}
+	return helpdeskfaq.AnswerAttributionCommunity
+}
+
+func getUserGroupMembers(client slackClient, handle string) ([]string, error) {
+	groups, err := client.GetUserGroups(slack.GetUserGroupsOptionIncludeUsers(true))
+	if err != nil {
+		return nil, fmt.Errorf("could not query Slack for groups: %w", err)
+	}
+	for _, group := range groups {
+		if group.Handle == handle {
+			// an empty rotation is still a known rotation
+			return append([]string{}, group.Users...), nil
+		}
+	}
+	return nil, fmt.Errorf("could not find user group %s", handle)
+}
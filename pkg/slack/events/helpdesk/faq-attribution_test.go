@@ -0,0 +1,74 @@
+package helpdesk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	helpdeskfaq "github.com/openshift/ci-tools/pkg/helpdesk-faq"
+)
+
+type fakeUserGroupClient struct {
+	slackClient
+	groups []slack.UserGroup
+	err    error
+	calls  int
+}
+
+func (c *fakeUserGroupClient) GetUserGroups(...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	c.calls++
+	return c.groups, c.err
+}
+
+func TestAnswerAttribution(t *testing.T) {
+	groups := []slack.UserGroup{
+		{Handle: "dptp-triage", Users: []string{"U1"}},
+		{Handle: "dptp-helpdesk", Users: []string{"U2"}},
+	}
+	testCases := []struct {
+		name      string
+		groups    []slack.UserGroup
+		err       error
+		userGroup string
+		expected  map[string]helpdeskfaq.AnswerAttribution
+	}{
+		{
+			name:      "rotation member gives an official answer",
+			groups:    groups,
+			userGroup: "dptp-helpdesk",
+			expected: map[string]helpdeskfaq.AnswerAttribution{
+				"U1": helpdeskfaq.AnswerAttributionCommunity,
+				"U2": helpdeskfaq.AnswerAttributionHelpdesk,
+				"U3": helpdeskfaq.AnswerAttributionCommunity,
+			},
+		},
+		{
+			name:      "unknown user group leaves answers unattributed",
+			groups:    groups,
+			userGroup: "dptp-helpdesk-missing",
+			expected:  map[string]helpdeskfaq.AnswerAttribution{"U1": "", "U2": ""},
+		},
+		{
+			name:      "slack error leaves answers unattributed",
+			err:       errors.New("ratelimited"),
+			userGroup: "dptp-helpdesk",
+			expected:  map[string]helpdeskfaq.AnswerAttribution{"U2": ""},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeUserGroupClient{groups: tc.groups, err: tc.err}
+			attributor := newAnswerAttributor(client, tc.userGroup, logrus.NewEntry(logrus.New()))
+			for author, expected := range tc.expected {
+				if actual := attributor.attribution(author); actual != expected {
+					t.Errorf("expected %s to be attributed %q, got %q", author, expected, actual)
+				}
+			}
+			if client.calls != 1 {
+				t.Errorf("expected the rotation to be looked up once, got %d", client.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package helpdesk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/fsnotify.v1"
+
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/interrupts"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	helpdeskfaq "github.com/openshift/ci-tools/pkg/helpdesk-faq"
+	"github.com/openshift/ci-tools/pkg/slack/users"
+)
+
+// authorizedUsersRefreshInterval is how often group members are looked up again
+const authorizedUsersRefreshInterval = 30 * time.Minute
+
+// FAQConfig holds the settings of the FAQ handler that can be changed at runtime
+type FAQConfig struct {
+	// QuestionReaction is the reaction marking a top-level message as a question
+	QuestionReaction string `json:"questionReaction,omitempty"`
+	// AnswerReaction is the reaction marking a reply as an answer
+	AnswerReaction string `json:"answerReaction,omitempty"`
+	// DocLinkReaction is the reaction attaching the documentation links of a message in the thread to the question
+	DocLinkReaction string `json:"docLinkReaction,omitempty"`
+	// DocLinkHosts are the hosts of the documentation links that may be attached
+	DocLinkHosts []string `json:"docLinkHosts,omitempty"`
+	// AuthorizedGroups are the OpenShift groups whose members may add and remove FAQ items
+	AuthorizedGroups []string `json:"authorizedGroups,omitempty"`
+	// ChannelIDs are the channels the handler reacts in
+	ChannelIDs []string `json:"channelIds,omitempty"`
+	// HelpdeskUserGroup is the handle of the Slack user group holding the current helpdesk rotation
+	HelpdeskUserGroup string `json:"helpdeskUserGroup,omitempty"`
+	// Topics is the vocabulary question topics are normalized to. Any topic is accepted when it is empty.
+	// Topics are nested with slashes, e.g. ci-operator/images, and the parents of a topic are part of the vocabulary.
+	Topics []string `json:"topics,omitempty"`
+	// AnswerLint are the quality checks answers are held to, they are all skipped when it is empty
+	AnswerLint *AnswerLintConfig `json:"answerLint,omitempty"`
+}
+
+// DefaultFAQConfig returns the settings used when no config is provided,
+// or when the provided config leaves some of them unset
+func DefaultFAQConfig(forumChannelId, helpdeskAlias string) FAQConfig {
+	return FAQConfig{
+		QuestionReaction:  "channel_faq",
+		AnswerReaction:    "faq_answer",
+		DocLinkReaction:   "faq_docs",
+		DocLinkHosts:      []string{"docs.ci.openshift.org"},
+		AuthorizedGroups:  []string{"test-platform-ci-admins"},
+		ChannelIDs:        []string{forumChannelId},
+		HelpdeskUserGroup: strings.TrimPrefix(helpdeskAlias, "@"),
+		AnswerLint: &AnswerLintConfig{
+			MinimumLength: 40,
+			DocReferences: []string{"doc", "docs", "documentation", "readme"},
+			BareReplies:   []string{"fixed", "done", "resolved", "works now", "solved"},
+		},
+	}
+}
+
+func (c FAQConfig) withDefaults(defaults FAQConfig) FAQConfig {
+	if c.QuestionReaction == "" {
+		c.QuestionReaction = defaults.QuestionReaction
+	}
+	if c.AnswerReaction == "" {
+		c.AnswerReaction = defaults.AnswerReaction
+	}
+	if c.DocLinkReaction == "" {
+		c.DocLinkReaction = defaults.DocLinkReaction
+	}
+	if len(c.DocLinkHosts) == 0 {
+		c.DocLinkHosts = defaults.DocLinkHosts
+	}
+	if len(c.AuthorizedGroups) == 0 {
+		c.AuthorizedGroups = defaults.AuthorizedGroups
+	}
+	if len(c.ChannelIDs) == 0 {
+		c.ChannelIDs = defaults.ChannelIDs
+	}
+	if c.HelpdeskUserGroup == "" {
+		c.HelpdeskUserGroup = defaults.HelpdeskUserGroup
+	}
+	if len(c.Topics) == 0 {
+		c.Topics = defaults.Topics
+	}
+	if c.AnswerLint == nil {
+		c.AnswerLint = defaults.AnswerLint
+	}
+	return c
+}
+
+// validate checks that the topics form a hierarchy
+func (c FAQConfig) validate() error {
+	for _, topic := range c.Topics {
+		levels := helpdeskfaq.SplitTopic(topic)
+		if len(levels) == 0 || strings.Join(levels, helpdeskfaq.TopicSeparator) != topic {
+			return fmt.Errorf("topic %q must be levels separated by %q, without blank levels or surrounding spaces", topic, helpdeskfaq.TopicSeparator)
+		}
+	}
+	return nil
+}
+
+// normalizeTopic returns the vocabulary spelling of the topic, and whether it is part of the vocabulary. A topic
+// is matched level by level, so that "CI-Operator / Images" is normalized to "ci-operator/images".
+func (c FAQConfig) normalizeTopic(topic string) (string, bool) {
+	if len(c.Topics) == 0 {
+		return topic, true
+	}
+	levels := helpdeskfaq.SplitTopic(topic)
+	if len(levels) == 0 {
+		return topic, false
+	}
+	for _, known := range c.Topics {
+		knownLevels := helpdeskfaq.SplitTopic(known)
+		if len(knownLevels) < len(levels) {
+			continue
+		}
+		if slices.EqualFunc(levels, knownLevels[:len(levels)], strings.EqualFold) {
+			return strings.Join(knownLevels[:len(levels)], helpdeskfaq.TopicSeparator), true
+		}
+	}
+	return topic, false
+}
+
+// faqSettings is a consistent snapshot of the config along with the users it authorizes
+type faqSettings struct {
+	config          FAQConfig
+	authorizedUsers []string
+}
+
+func (s faqSettings) watchesChannel(channel string) bool {
+	return slices.Contains(s.config.ChannelIDs, channel)
+}
+
+// isFAQReaction tells whether the reaction marks a question, an answer or documentation links in a watched channel
+func (s faqSettings) isFAQReaction(channel, reaction string) bool {
+	return s.watchesChannel(channel) && (reaction == s.config.QuestionReaction || reaction == s.config.AnswerReaction || reaction == s.config.DocLinkReaction)
+}
+
+func (s faqSettings) isAuthorized(user string) bool {
+	return slices.Contains(s.authorizedUsers, user)
+}
+
+// FAQConfigAgent serves the current FAQ settings, reloading them whenever the config file changes
+type FAQConfigAgent struct {
+	path       string
+	defaults   FAQConfig
+	resolver   *users.Resolver
+	kubeClient ctrlruntimeclient.Client
+
+	lock     sync.RWMutex
+	settings faqSettings
+}
+
+// NewFAQConfigAgent loads the config at path, falling back to the defaults when path is empty
+func NewFAQConfigAgent(path string, defaults FAQConfig, resolver *users.Resolver, kubeClient ctrlruntimeclient.Client) (*FAQConfigAgent, error) {
+	agent := &FAQConfigAgent{
+		path:       path,
+		defaults:   defaults,
+		resolver:   resolver,
+		kubeClient: kubeClient,
+	}
+	if err := agent.reload(); err != nil {
+		return nil, err
+	}
+	return agent, nil
+}
+
+func (a *FAQConfigAgent) current() faqSettings {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.settings
+}
+
+// reload reads the config and resolves its authorized users. The previous settings are kept on failure.
+func (a *FAQConfigAgent) reload() error {
+	cfg := a.defaults
+	if a.path != "" {
+		raw, err := os.ReadFile(a.path)
+		if err != nil {
+			return fmt.Errorf("failed to read faq config: %w", err)
+		}
+		var loaded FAQConfig
+		if err := yaml.UnmarshalStrict(raw, &loaded); err != nil {
+			return fmt.Errorf("failed to unmarshal faq config: %w", err)
+		}
+		cfg = loaded.withDefaults(a.defaults)
+		if err := cfg.validate(); err != nil {
+			return fmt.Errorf("invalid faq config: %w", err)
+		}
+	}
+
+	authorizedUsers, err := getAuthorizedUsers(a.resolver, a.kubeClient, cfg.AuthorizedGroups, logrus.WithField("handler", "faq-handler"))
+	if err != nil {
+		return fmt.Errorf("couldn't get authorized users: %w", err)
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.settings = faqSettings{config: cfg, authorizedUsers: authorizedUsers}
+	return nil
+}
+
+// Start watches the config file until the process is interrupted. The file is
+// expected to be mounted from a ConfigMap, but a plain file is watched as well.
+// The authorized users are refreshed periodically to pick up group membership changes.
+func (a *FAQConfigAgent) Start() error {
+	logger := logrus.WithField("faq-config", a.path)
+	interrupts.TickLiteral(func() {
+		if err := a.reload(); err != nil {
+			logger.WithError(err).Error("Failed to refresh authorized users")
+		}
+	}, authorizedUsersRefreshInterval)
+	if a.path == "" {
+		return nil
+	}
+	eventFunc := func() error {
+		if err := a.reload(); err != nil {
+			return err
+		}
+		logger.Info("Reloaded faq config")
+		return nil
+	}
+	errFunc := func(err error, msg string) {
+		logger.WithError(err).Error(msg)
+	}
+
+	var watcher func(ctx context.Context)
+	dir := filepath.Dir(a.path)
+	isCMMount, err := config.IsConfigMapMount(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check if %s is a configmap mount: %w", dir, err)
+	}
+	if isCMMount {
+		watcher, err = config.GetCMMountWatcher(eventFunc, errFunc, dir)
+	} else {
+		watcher, err = config.GetFileWatcher(func(*fsnotify.Watcher) error { return eventFunc() }, errFunc, a.path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to watch faq config: %w", err)
+	}
+	interrupts.Run(watcher)
+	return nil
+}
@@ -0,0 +1,186 @@
+package helpdesk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/slack-go/slack"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	userv1 "github.com/openshift/api/user/v1"
+
+	"github.com/openshift/ci-tools/pkg/slack/users"
+)
+
+type fakeUserClient struct{}
+
+func (fakeUserClient) GetUserByEmail(email string) (*slack.User, error) {
+	if strings.HasPrefix(email, "unknown") {
+		return nil, fmt.Errorf("users_not_found")
+	}
+	return &slack.User{ID: "U-" + strings.TrimSuffix(email, "@redhat.com")}, nil
+}
+
+func TestFAQConfigAgentReload(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := userv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add userv1 to scheme: %v", err)
+	}
+	kubeClient := fakectrlruntimeclient.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&userv1.Group{ObjectMeta: metav1.ObjectMeta{Name: "test-platform-ci-admins"}, Users: userv1.OptionalNames{"admin", "unknown"}},
+		&userv1.Group{ObjectMeta: metav1.ObjectMeta{Name: "helpdesk"}, Users: userv1.OptionalNames{"helper", "admin"}},
+	).Build()
+	defaults := DefaultFAQConfig("CBN38N3MW", "@dptp-helpdesk")
+
+	testCases := []struct {
+		name        string
+		config      string
+		expected    faqSettings
+		expectedErr bool
+	}{
+		{
+			name:   "no config uses the defaults",
+			config: "",
+			expected: faqSettings{
+				config:          defaults,
+				authorizedUsers: []string{"U-admin"},
+			},
+		},
+		{
+			name:   "config overrides some of the defaults",
+			config: "answerReaction: accepted\nauthorizedGroups:\n- test-platform-ci-admins\n- helpdesk\nchannelIds:\n- CBN38N3MW\n- C12345\ntopics:\n- Prow\n- ci-operator\n",
+			expected: faqSettings{
+				config: FAQConfig{
+					QuestionReaction:  "channel_faq",
+					AnswerReaction:    "accepted",
+					DocLinkReaction:   "faq_docs",
+					DocLinkHosts:      []string{"docs.ci.openshift.org"},
+					AuthorizedGroups:  []string{"test-platform-ci-admins", "helpdesk"},
+					ChannelIDs:        []string{"CBN38N3MW", "C12345"},
+					HelpdeskUserGroup: "dptp-helpdesk",
+					Topics:            []string{"Prow", "ci-operator"},
+					AnswerLint:        defaults.AnswerLint,
+				},
+				authorizedUsers: []string{"U-admin", "U-helper"},
+			},
+		},
+		{
+			name:   "answer lint can be disabled",
+			config: "answerLint: {}\n",
+			expected: faqSettings{
+				config: FAQConfig{
+					QuestionReaction:  "channel_faq",
+					AnswerReaction:    "faq_answer",
+					DocLinkReaction:   "faq_docs",
+					DocLinkHosts:      []string{"docs.ci.openshift.org"},
+					AuthorizedGroups:  []string{"test-platform-ci-admins"},
+					ChannelIDs:        []string{"CBN38N3MW"},
+					HelpdeskUserGroup: "dptp-helpdesk",
+					AnswerLint:        &AnswerLintConfig{},
+				},
+				authorizedUsers: []string{"U-admin"},
+			},
+		},
+		{
+			name:        "unknown fields are rejected",
+			config:      "questionReactions: faq\n",
+			expectedErr: true,
+		},
+		{
+			name:        "missing group is an error",
+			config:      "authorizedGroups:\n- missing\n",
+			expectedErr: true,
+		},
+		{
+			name:        "topic with a blank level is rejected",
+			config:      "topics:\n- ci-operator//images\n",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var path string
+			if tc.config != "" {
+				path = filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(path, []byte(tc.config), 0644); err != nil {
+					t.Fatalf("failed to write config: %v", err)
+				}
+			}
+			agent, err := NewFAQConfigAgent(path, defaults, users.NewResolver(fakeUserClient{}), kubeClient)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.expected, agent.current(), cmp.AllowUnexported(faqSettings{})); diff != "" {
+				t.Fatalf("settings don't match expected, diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestNormalizeTopic(t *testing.T) {
+	testCases := []struct {
+		name          string
+		topics        []string
+		topic         string
+		expected      string
+		expectedKnown bool
+	}{
+		{
+			name:          "no vocabulary accepts any topic",
+			topic:         "whatever",
+			expected:      "whatever",
+			expectedKnown: true,
+		},
+		{
+			name:          "topic is normalized to the vocabulary spelling",
+			topics:        []string{"Prow", "ci-operator"},
+			topic:         "prow",
+			expected:      "Prow",
+			expectedKnown: true,
+		},
+		{
+			name:     "unknown topic is kept as is",
+			topics:   []string{"Prow", "ci-operator"},
+			topic:    "Boskos",
+			expected: "Boskos",
+		},
+		{
+			name:          "nested topic is normalized level by level",
+			topics:        []string{"Prow", "ci-operator/images", "ci-operator/tests"},
+			topic:         "CI-Operator / Images",
+			expected:      "ci-operator/images",
+			expectedKnown: true,
+		},
+		{
+			name:          "parent of a nested topic is known",
+			topics:        []string{"ci-operator/images"},
+			topic:         "ci-operator",
+			expected:      "ci-operator",
+			expectedKnown: true,
+		},
+		{
+			name:     "unknown child of a known topic is kept as is",
+			topics:   []string{"ci-operator/images"},
+			topic:    "ci-operator/leases",
+			expected: "ci-operator/leases",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			topic, known := FAQConfig{Topics: tc.topics}.normalizeTopic(tc.topic)
+			if topic != tc.expected || known != tc.expectedKnown {
+				t.Fatalf("expected %q (known: %t), got %q (known: %t)", tc.expected, tc.expectedKnown, topic, known)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package helpdesk
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	helpdeskfaq "github.com/openshift/ci-tools/pkg/helpdesk-faq"
+)
+
+// linkRegex finds the URLs of a message, slack wraps them as <https://...> or <https://...|label>
+var linkRegex = regexp.MustCompile(`https?://[^\s<>|]+`)
+
+// linkChecker tells whether a documentation link can be followed
+type linkChecker interface {
+	check(link string) error
+}
+
+type httpLinkChecker struct {
+	client *http.Client
+}
+
+func newHTTPLinkChecker() linkChecker {
+	return &httpLinkChecker{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *httpLinkChecker) check(link string) error {
+	resp, err := c.client.Head(link)
+	if err == nil && resp.StatusCode == http.StatusMethodNotAllowed {
+		// not every server answers HEAD requests
+		resp.Body.Close()
+		resp, err = c.client.Get(link)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("it returned %s", resp.Status)
+	}
+	return nil
+}
+
+// docLinks returns the distinct links of the message that point at one of the documentation hosts
+func docLinks(text string, hosts []string) []string {
+	var links []string
+	for _, link := range linkRegex.FindAllString(text, -1) {
+		parsed, err := url.Parse(link)
+		if err != nil || !slices.Contains(hosts, strings.ToLower(parsed.Hostname())) {
+			continue
+		}
+		if !slices.Contains(links, link) {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// getReactedMessage returns the message the reaction was added to and the timestamp of the question of its thread,
+// the reaction can be on the question itself or on any reply
+func getReactedMessage(client slackClient, channelId, messageTs string) (*slack.Message, string, error) {
+	replies, _, _, err := client.GetConversationReplies(&slack.GetConversationRepliesParameters{
+		ChannelID: channelId,
+		Timestamp: messageTs,
+		Inclusive: true,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(replies) == 0 {
+		return nil, "", nil
+	}
+	questionTs := replies[0].Msg.ThreadTimestamp
+	if questionTs == "" {
+		questionTs = messageTs
+	}
+	return &replies[0], questionTs, nil
+}
+
+func handleDocLinkAdded(event *slackevents.ReactionAddedEvent, client slackClient, faqItemClient helpdeskfaq.FaqItemClient, channelId string, settings faqSettings, checker linkChecker, logger *logrus.Entry) (bool, error) {
+	docLog := logger.WithField("type", "add-doc-link")
+	if !settings.isAuthorized(event.User) {
+		docLog.Infof("user with ID: %s is not authorized", event.User)
+		return false, nil
+	}
+	messageTs := event.Item.Timestamp
+	message, questionTs, err := getReactedMessage(client, channelId, messageTs)
+	if err != nil {
+		docLog.WithError(err).Error("unable to retrieve message that reaction was added for")
+		return false, err
+	}
+	if message == nil {
+		return false, nil
+	}
+	faqItem, err := faqItemClient.GetFAQItemIfExists(questionTs)
+	if err != nil {
+		docLog.WithError(err).Error("unable to get faq item")
+		return false, err
+	}
+	if faqItem == nil {
+		docLog.Info("requested documentation doesn't belong to an existing question, ignoring")
+		return false, nil
+	}
+
+	links := docLinks(message.Msg.Text, settings.config.DocLinkHosts)
+	if len(links) == 0 {
+		warnDocLinkCurator(client, channelId, questionTs, event.User, []string{fmt.Sprintf("the message doesn't link to %s", strings.Join(settings.config.DocLinkHosts, " or "))}, docLog)
+		return false, nil
+	}
+	var warnings []string
+	added := 0
+	for _, link := range links {
+		if slices.ContainsFunc(faqItem.DocLinks, func(existing helpdeskfaq.DocLink) bool { return existing.URL == link }) {
+			docLog.Debugf("link %s already exists, ignoring", link)
+			continue
+		}
+		if err := checker.check(link); err != nil {
+			docLog.WithError(err).Infof("link %s is not reachable", link)
+			warnings = append(warnings, fmt.Sprintf("%s was not attached, it is not reachable: %v", link, err))
+			continue
+		}
+		faqItem.DocLinks = append(faqItem.DocLinks, helpdeskfaq.DocLink{URL: link, Author: event.User, Timestamp: messageTs})
+		added++
+	}
+	if added > 0 {
+		if err := faqItemClient.UpsertItem(*faqItem); err != nil {
+			docLog.WithError(err).Error("unable to update helpdesk-faq item")
+			return false, err
+		}
+	}
+	warnDocLinkCurator(client, channelId, questionTs, event.User, warnings, docLog)
+	return added > 0, nil
+}
+
+func handleDocLinkRemoved(event *slackevents.ReactionRemovedEvent, client slackClient, faqItemClient helpdeskfaq.FaqItemClient, channelId string, settings faqSettings, logger *logrus.Entry) (bool, error) {
+	docLog := logger.WithField("type", "remove-doc-link")
+	if !settings.isAuthorized(event.User) {
+		docLog.Infof("user with ID: %s is not authorized", event.User)
+		return false, nil
+	}
+	messageTs := event.Item.Timestamp
+	message, questionTs, err := getReactedMessage(client, channelId, messageTs)
+	if err != nil {
+		docLog.WithError(err).Error("unable to retrieve message that reaction was removed for")
+		return false, err
+	}
+	if message == nil {
+		return false, nil
+	}
+	faqItem, err := faqItemClient.GetFAQItemIfExists(questionTs)
+	if err != nil || faqItem == nil {
+		docLog.WithError(err).Warn("unable to get faqItem")
+		return false, nil //Don't return the error, because this is due to the question not having been added
+	}
+
+	kept := slices.DeleteFunc(faqItem.DocLinks, func(link helpdeskfaq.DocLink) bool { return link.Timestamp == messageTs })
+	if len(kept) == len(faqItem.DocLinks) {
+		return false, nil
+	}
+	faqItem.DocLinks = kept
+	if err := faqItemClient.UpsertItem(*faqItem); err != nil {
+		docLog.WithError(err).Error("unable to update helpdesk-faq config map")
+		return false, err
+	}
+	return true, nil
+}
+
+// warnDocLinkCurator tells the curator in the thread which links couldn't be attached
+func warnDocLinkCurator(client slackClient, channelId, questionTs, curator string, warnings []string, logger *logrus.Entry) {
+	if len(warnings) == 0 {
+		return
+	}
+	message := fmt.Sprintf("<@%s> some documentation couldn't be attached to the FAQ:", curator)
+	for _, warning := range warnings {
+		message += "\n• " + warning
+	}
+	if _, _, err := client.PostMessage(channelId, slack.MsgOptionText(message, false), slack.MsgOptionTS(questionTs)); err != nil {
+		logger.WithError(err).Warn("unable to warn the curator about the documentation links")
+	}
+}
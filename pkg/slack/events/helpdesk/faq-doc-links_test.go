@@ -0,0 +1,142 @@
+package helpdesk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	helpdeskfaq "github.com/openshift/ci-tools/pkg/helpdesk-faq"
+)
+
+type fakeThreadClient struct {
+	slackClient
+	replies []slack.Message
+	posted  int
+}
+
+func (c *fakeThreadClient) GetConversationReplies(*slack.GetConversationRepliesParameters) ([]slack.Message, bool, string, error) {
+	return c.replies, false, "", nil
+}
+
+func (c *fakeThreadClient) PostMessage(string, ...slack.MsgOption) (string, string, error) {
+	c.posted++
+	return "", "", nil
+}
+
+type fakeFaqItemClient struct {
+	helpdeskfaq.FaqItemClient
+	items map[string]helpdeskfaq.FaqItem
+}
+
+func (c *fakeFaqItemClient) GetFAQItemIfExists(timestamp string) (*helpdeskfaq.FaqItem, error) {
+	item, ok := c.items[timestamp]
+	if !ok {
+		return nil, nil
+	}
+	return &item, nil
+}
+
+func (c *fakeFaqItemClient) UpsertItem(item helpdeskfaq.FaqItem) error {
+	c.items[item.Timestamp] = item
+	return nil
+}
+
+type fakeLinkChecker map[string]error
+
+func (c fakeLinkChecker) check(link string) error {
+	return c[link]
+}
+
+func TestDocLinks(t *testing.T) {
+	hosts := []string{"docs.ci.openshift.org"}
+	text := "See <https://docs.ci.openshift.org/docs/architecture/ci-operator/|the ci-operator docs> and " +
+		"<https://github.com/openshift/release>, or <https://docs.ci.openshift.org/docs/architecture/ci-operator/> again."
+	expected := []string{"https://docs.ci.openshift.org/docs/architecture/ci-operator/"}
+	if diff := cmp.Diff(expected, docLinks(text, hosts)); diff != "" {
+		t.Fatalf("links don't match expected, diff: %s", diff)
+	}
+}
+
+func TestHandleDocLinkAdded(t *testing.T) {
+	const (
+		curator    = "U1"
+		questionTs = "1700000000.000100"
+		replyTs    = "1700000000.000200"
+	)
+	settings := faqSettings{config: DefaultFAQConfig("C1", "@dptp-helpdesk"), authorizedUsers: []string{curator}}
+	reachable := "https://docs.ci.openshift.org/docs/how-tos/cluster-claim/"
+	unreachable := "https://docs.ci.openshift.org/docs/missing/"
+	existing := helpdeskfaq.DocLink{URL: "https://docs.ci.openshift.org/docs/", Author: curator, Timestamp: questionTs}
+
+	testCases := []struct {
+		name           string
+		user           string
+		text           string
+		expectedLinks  []helpdeskfaq.DocLink
+		expectedPosted int
+		expected       bool
+	}{
+		{
+			name:          "reachable link is attached",
+			user:          curator,
+			text:          "See <" + reachable + ">",
+			expectedLinks: []helpdeskfaq.DocLink{existing, {URL: reachable, Author: curator, Timestamp: replyTs}},
+			expected:      true,
+		},
+		{
+			name:           "unreachable link is reported to the curator",
+			user:           curator,
+			text:           "See <" + reachable + "> and <" + unreachable + ">",
+			expectedLinks:  []helpdeskfaq.DocLink{existing, {URL: reachable, Author: curator, Timestamp: replyTs}},
+			expectedPosted: 1,
+			expected:       true,
+		},
+		{
+			name:           "message without documentation is reported to the curator",
+			user:           curator,
+			text:           "See <https://github.com/openshift/release>",
+			expectedLinks:  []helpdeskfaq.DocLink{existing},
+			expectedPosted: 1,
+		},
+		{
+			name:          "link already attached is ignored",
+			user:          curator,
+			text:          "See <" + existing.URL + ">",
+			expectedLinks: []helpdeskfaq.DocLink{existing},
+		},
+		{
+			name:          "unauthorized user is ignored",
+			user:          "U2",
+			text:          "See <" + reachable + ">",
+			expectedLinks: []helpdeskfaq.DocLink{existing},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeThreadClient{replies: []slack.Message{{Msg: slack.Msg{Text: tc.text, ThreadTimestamp: questionTs}}}}
+			items := &fakeFaqItemClient{items: map[string]helpdeskfaq.FaqItem{
+				questionTs: {Timestamp: questionTs, DocLinks: []helpdeskfaq.DocLink{existing}},
+			}}
+			checker := fakeLinkChecker{unreachable: errors.New("it returned 404 Not Found")}
+			event := &slackevents.ReactionAddedEvent{User: tc.user, Item: slackevents.Item{Timestamp: replyTs}}
+
+			handled, err := handleDocLinkAdded(event, client, items, "C1", settings, checker, logrus.NewEntry(logrus.New()))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if handled != tc.expected {
+				t.Errorf("expected handled to be %t, got %t", tc.expected, handled)
+			}
+			if diff := cmp.Diff(tc.expectedLinks, items.items[questionTs].DocLinks); diff != "" {
+				t.Errorf("links don't match expected, diff: %s", diff)
+			}
+			if client.posted != tc.expectedPosted {
+				t.Errorf("expected %d messages to the curator, got %d", tc.expectedPosted, client.posted)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package helpdesk
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+var wordRegex = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// AnswerLintConfig holds the checks answers are held to before they are published in the FAQ. Answers failing
+// them are stored regardless, the curator is warned in the thread so that they can improve them.
+type AnswerLintConfig struct {
+	// MinimumLength is the number of characters an answer should have at least, it isn't checked when zero
+	MinimumLength int `json:"minimumLength,omitempty"`
+	// DocReferences are words referring to documentation, an answer using any of them should link to it
+	DocReferences []string `json:"docReferences,omitempty"`
+	// BareReplies are replies that tell nothing on their own, like "fixed"
+	BareReplies []string `json:"bareReplies,omitempty"`
+}
+
+// lint returns the reasons the answer falls short, none when it passes every check
+func (c *AnswerLintConfig) lint(body string) []string {
+	if c == nil {
+		return nil
+	}
+	body = strings.TrimSpace(body)
+	bare := strings.ToLower(strings.TrimRight(body, ".!: "))
+	for _, reply := range c.BareReplies {
+		if bare == strings.ToLower(reply) {
+			// the other checks would only repeat that there is nothing to the answer
+			return []string{fmt.Sprintf("it only says %q, explain what solved the problem", body)}
+		}
+	}
+
+	var warnings []string
+	if length := len([]rune(body)); c.MinimumLength > 0 && length < c.MinimumLength {
+		warnings = append(warnings, fmt.Sprintf("it is %d characters long, answers should have at least %d", length, c.MinimumLength))
+	}
+	if !strings.Contains(body, "http://") && !strings.Contains(body, "https://") {
+		for _, word := range wordRegex.FindAllString(strings.ToLower(body), -1) {
+			if slices.ContainsFunc(c.DocReferences, func(reference string) bool { return strings.EqualFold(reference, word) }) {
+				warnings = append(warnings, fmt.Sprintf("it refers to %q without linking to it", word))
+				break
+			}
+		}
+	}
+	return warnings
+}
+
+// warnCurator replies in the thread of the question when the answer the curator marked falls short. Failing to
+// warn doesn't fail the handler, the answer has been stored already.
+func warnCurator(client slackClient, channelId, questionTs, curator, answer string, settings faqSettings, logger *logrus.Entry) {
+	warnings := settings.config.AnswerLint.lint(answer)
+	if len(warnings) == 0 {
+		return
+	}
+	message := fmt.Sprintf("<@%s> the answer was added to the FAQ, but it could be better:", curator)
+	for _, warning := range warnings {
+		message += "\n• " + warning
+	}
+	if _, _, err := client.PostMessage(channelId, slack.MsgOptionText(message, false), slack.MsgOptionTS(questionTs)); err != nil {
+		logger.WithError(err).Warn("unable to warn the curator about the answer")
+	}
+}
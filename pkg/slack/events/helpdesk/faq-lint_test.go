@@ -0,0 +1,62 @@
+package helpdesk
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLintAnswer(t *testing.T) {
+	config := DefaultFAQConfig("CBN38N3MW", "@dptp-helpdesk").AnswerLint
+	testCases := []struct {
+		name     string
+		config   *AnswerLintConfig
+		answer   string
+		expected []string
+	}{
+		{
+			name:   "good answer",
+			config: config,
+			answer: "The cluster pool ran out of clusters, see https://docs.ci.openshift.org/docs/how-tos/cluster-claim/ for how to size it.",
+		},
+		{
+			name:     "bare reply",
+			config:   config,
+			answer:   "Fixed!",
+			expected: []string{`it only says "Fixed!", explain what solved the problem`},
+		},
+		{
+			name:     "short answer",
+			config:   config,
+			answer:   "Retest the job please.",
+			expected: []string{"it is 22 characters long, answers should have at least 40"},
+		},
+		{
+			name:     "documentation without a link",
+			config:   config,
+			answer:   "This is explained in the docs about configuring the step registry.",
+			expected: []string{`it refers to "docs" without linking to it`},
+		},
+		{
+			name:   "words containing a doc reference are fine",
+			config: config,
+			answer: "The pod was scheduled on a node running docker instead of cri-o.",
+		},
+		{
+			name:   "no checks",
+			config: &AnswerLintConfig{},
+			answer: "fixed",
+		},
+		{
+			name:   "nil config",
+			answer: "fixed",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, tc.config.lint(tc.answer)); diff != "" {
+				t.Fatalf("warnings don't match expected, diff: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package helpdesk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack/slackevents"
+
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/test-infra/prow/interrupts"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	faqEventsConfigMap = "helpdesk-faq-events"
+	faqEventsNamespace = "ci"
+
+	// replayInterval is how often pending events are processed again, and the buffer is persisted if that failed before
+	replayInterval = time.Minute
+	// maxReplayAttempts bounds the attempts to process an event, so that an event that can never be processed
+	// doesn't stay pending forever
+	maxReplayAttempts = 10
+	// bufferRetention is how long processed and failed events are kept to be inspected
+	bufferRetention = 24 * time.Hour
+
+	eventStatusPending   = "pending"
+	eventStatusProcessed = "processed"
+	eventStatusFailed    = "failed"
+)
+
+// faqEventProcessor handles a reaction event, it is the FAQ handler minus the buffering
+type faqEventProcessor func(data interface{}, logger *logrus.Entry) (bool, error)
+
+// bufferedEvent is a reaction event along with how far it got
+type bufferedEvent struct {
+	Type       string          `json:"type"`
+	Event      json.RawMessage `json:"event"`
+	Status     string          `json:"status"`
+	Attempts   int             `json:"attempts"`
+	ReceivedAt time.Time       `json:"receivedAt"`
+	LastError  string          `json:"lastError,omitempty"`
+}
+
+// FAQEventBuffer remembers the reaction events the FAQ handler received until they are processed. Events failing
+// on a transient error are replayed, and the buffer is persisted in a ConfigMap so that events survive a restart.
+// The buffer in memory is authoritative: when the Kube API is unavailable it is persisted once it is back.
+type FAQEventBuffer struct {
+	kubeClient ctrlruntimeclient.Client
+	now        func() time.Time
+
+	lock      sync.Mutex
+	events    map[string]*bufferedEvent
+	inFlight  map[string]bool
+	dirty     bool
+	processor faqEventProcessor
+}
+
+func NewFAQEventBuffer(kubeClient ctrlruntimeclient.Client) *FAQEventBuffer {
+	return &FAQEventBuffer{
+		kubeClient: kubeClient,
+		now:        time.Now,
+		events:     map[string]*bufferedEvent{},
+		inFlight:   map[string]bool{},
+	}
+}
+
+func (b *FAQEventBuffer) setProcessor(processor faqEventProcessor) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.processor = processor
+}
+
+// Start loads the events left pending by a previous run and replays them until the process is interrupted
+func (b *FAQEventBuffer) Start() error {
+	if err := b.load(); err != nil {
+		return err
+	}
+	interrupts.TickLiteral(b.replay, replayInterval)
+	return nil
+}
+
+func (b *FAQEventBuffer) load() error {
+	configMap := &v1.ConfigMap{}
+	err := b.kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: faqEventsNamespace, Name: faqEventsConfigMap}, configMap)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get configMap %s: %w", faqEventsConfigMap, err)
+	}
+	b.lock.Lock()
+	for key, raw := range configMap.Data {
+		event := &bufferedEvent{}
+		if err := json.Unmarshal([]byte(raw), event); err != nil {
+			logrus.WithError(err).WithField("event", key).Warn("Dropping unreadable buffered FAQ event")
+			continue
+		}
+		b.events[key] = event
+	}
+	b.lock.Unlock()
+	return nil
+}
+
+// handle records the event before processing it, so that it is replayed if processing fails
+func (b *FAQEventBuffer) handle(eventType, key string, data interface{}, logger *logrus.Entry) (bool, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return false, fmt.Errorf("unable to marshal event: %w", err)
+	}
+
+	b.lock.Lock()
+	if event, ok := b.events[key]; ok && event.Status != eventStatusPending {
+		b.lock.Unlock()
+		// Slack delivers again the events it didn't get a timely response for
+		logger.Debugf("event %s was already %s, ignoring", key, event.Status)
+		return false, nil
+	}
+	if b.inFlight[key] {
+		b.lock.Unlock()
+		logger.Debugf("event %s is being processed, ignoring", key)
+		return false, nil
+	}
+	if _, ok := b.events[key]; !ok {
+		b.events[key] = &bufferedEvent{Type: eventType, Event: raw, Status: eventStatusPending, ReceivedAt: b.now()}
+		b.dirty = true
+	}
+	b.inFlight[key] = true
+	processor := b.processor
+	b.lock.Unlock()
+	b.persist(logger)
+
+	handled, err := processor(data, logger)
+	b.finish(key, err, logger)
+	return handled, err
+}
+
+func (b *FAQEventBuffer) finish(key string, err error, logger *logrus.Entry) {
+	b.lock.Lock()
+	delete(b.inFlight, key)
+	event := b.events[key]
+	event.Attempts++
+	b.dirty = true
+	switch {
+	case err == nil:
+		event.Status = eventStatusProcessed
+		event.LastError = ""
+	case event.Attempts >= maxReplayAttempts:
+		logger.WithError(err).Errorf("giving up on event %s after %d attempts", key, event.Attempts)
+		event.Status = eventStatusFailed
+		event.LastError = err.Error()
+	default:
+		event.LastError = err.Error()
+	}
+	b.lock.Unlock()
+	b.persist(logger)
+}
+
+// replay processes the pending events again, oldest first
+func (b *FAQEventBuffer) replay() {
+	logger := logrus.WithField("handler", "helpdesk-faq-replay")
+
+	b.lock.Lock()
+	var pending []string
+	for key, event := range b.events {
+		if event.Status != eventStatusPending {
+			if b.now().Sub(event.ReceivedAt) > bufferRetention {
+				delete(b.events, key)
+				b.dirty = true
+			}
+			continue
+		}
+		if !b.inFlight[key] {
+			pending = append(pending, key)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return b.events[pending[i]].ReceivedAt.Before(b.events[pending[j]].ReceivedAt)
+	})
+	b.lock.Unlock()
+
+	for _, key := range pending {
+		b.lock.Lock()
+		event := b.events[key]
+		eventType, raw := event.Type, event.Event
+		b.lock.Unlock()
+
+		data, err := decodeReactionEvent(eventType, raw)
+		if err != nil {
+			logger.WithError(err).Errorf("unable to decode event %s", key)
+			continue
+		}
+		logger.Infof("replaying event %s", key)
+		if _, err := b.handle(eventType, key, data, logger.WithField("event", key)); err != nil {
+			logger.WithError(err).Warnf("replaying event %s failed", key)
+		}
+	}
+	b.persist(logger)
+}
+
+// persist writes the buffer to the ConfigMap when it changed. On failure, the next replay tries again.
+func (b *FAQEventBuffer) persist(logger *logrus.Entry) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if !b.dirty {
+		return
+	}
+	data := map[string]string{}
+	for key, event := range b.events {
+		raw, err := json.Marshal(event)
+		if err != nil {
+			logger.WithError(err).Errorf("unable to marshal buffered event %s", key)
+			continue
+		}
+		data[key] = string(raw)
+	}
+
+	configMap := &v1.ConfigMap{}
+	err := b.kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: faqEventsNamespace, Name: faqEventsConfigMap}, configMap)
+	switch {
+	case kerrors.IsNotFound(err):
+		configMap = &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: faqEventsNamespace, Name: faqEventsConfigMap}, Data: data}
+		err = b.kubeClient.Create(context.TODO(), configMap)
+	case err == nil:
+		configMap.Data = data
+		err = b.kubeClient.Update(context.TODO(), configMap)
+	}
+	if err != nil {
+		logger.WithError(err).Warn("unable to persist the FAQ event buffer, it will be tried again")
+		return
+	}
+	b.dirty = false
+}
+
+func decodeReactionEvent(eventType string, raw json.RawMessage) (interface{}, error) {
+	var data interface{}
+	switch eventType {
+	case slackevents.ReactionAdded:
+		data = &slackevents.ReactionAddedEvent{}
+	case slackevents.ReactionRemoved:
+		data = &slackevents.ReactionRemovedEvent{}
+	default:
+		return nil, fmt.Errorf("unknown event type %s", eventType)
+	}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// reactionEventKey identifies a reaction event, it is a valid ConfigMap key
+func reactionEventKey(eventType, user, eventTimestamp string) string {
+	return fmt.Sprintf("%s-%s-%s", eventType, eventTimestamp, user)
+}
@@ -0,0 +1,90 @@
+package helpdesk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack/slackevents"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFAQEventBufferReplay(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add v1 to scheme: %v", err)
+	}
+	kubeClient := fakectrlruntimeclient.NewClientBuilder().WithScheme(scheme).Build()
+	logger := logrus.WithField("test", t.Name())
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	event := &slackevents.ReactionAddedEvent{
+		Type:           slackevents.ReactionAdded,
+		User:           "U1",
+		Reaction:       "channel_faq",
+		Item:           slackevents.Item{Type: "message", Channel: "CBN38N3MW", Timestamp: "1696161600.000100"},
+		EventTimestamp: "1696161601.000200",
+	}
+	key := reactionEventKey(slackevents.ReactionAdded, event.User, event.EventTimestamp)
+
+	// the first run fails to create the FAQ item and restarts before replaying the event
+	buffer := NewFAQEventBuffer(kubeClient)
+	buffer.now = func() time.Time { return now }
+	buffer.setProcessor(func(interface{}, *logrus.Entry) (bool, error) {
+		return false, errors.New("the server is currently unable to handle the request")
+	})
+	if _, err := buffer.handle(slackevents.ReactionAdded, key, event, logger); err == nil {
+		t.Fatal("expected the processing error to be returned")
+	}
+
+	configMap := &v1.ConfigMap{}
+	if err := kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: faqEventsNamespace, Name: faqEventsConfigMap}, configMap); err != nil {
+		t.Fatalf("expected the buffer to be persisted: %v", err)
+	}
+	if _, ok := configMap.Data[key]; !ok {
+		t.Fatalf("expected event %s to be persisted, got %v", key, configMap.Data)
+	}
+
+	var replayed []*slackevents.ReactionAddedEvent
+	restarted := NewFAQEventBuffer(kubeClient)
+	restarted.now = func() time.Time { return now.Add(time.Minute) }
+	restarted.setProcessor(func(data interface{}, _ *logrus.Entry) (bool, error) {
+		replayed = append(replayed, data.(*slackevents.ReactionAddedEvent))
+		return true, nil
+	})
+	if err := restarted.load(); err != nil {
+		t.Fatalf("failed to load the buffer: %v", err)
+	}
+	restarted.replay()
+	if len(replayed) != 1 || replayed[0].Item.Timestamp != event.Item.Timestamp || replayed[0].User != event.User {
+		t.Fatalf("expected the event to be replayed once, got %v", replayed)
+	}
+	if status, attempts := restarted.events[key].Status, restarted.events[key].Attempts; status != eventStatusProcessed || attempts != 2 {
+		t.Errorf("expected the event to be processed after 2 attempts, got %s after %d", status, attempts)
+	}
+
+	// Slack delivering the event again doesn't process it twice
+	if _, err := restarted.handle(slackevents.ReactionAdded, key, event, logger); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	restarted.replay()
+	if len(replayed) != 1 {
+		t.Errorf("expected a processed event not to be processed again, got %d", len(replayed))
+	}
+
+	// processed events are eventually dropped
+	restarted.now = func() time.Time { return now.Add(bufferRetention + time.Hour) }
+	restarted.replay()
+	if err := kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: faqEventsNamespace, Name: faqEventsConfigMap}, configMap); err != nil {
+		t.Fatalf("failed to get the buffer: %v", err)
+	}
+	if len(configMap.Data) != 0 {
+		t.Errorf("expected the buffer to be empty, got %v", configMap.Data)
+	}
+}
@@ -18,11 +18,7 @@ import (
 
 	helpdeskfaq "github.com/openshift/ci-tools/pkg/helpdesk-faq"
 	"github.com/openshift/ci-tools/pkg/slack/events"
-)
-
-const (
-	questionReaction = "channel_faq"
-	answerReaction   = "faq_answer"
+	"github.com/openshift/ci-tools/pkg/slack/users"
 )
 
 var questionRegex = regexp.MustCompile(`(?smi)^(.*?)_Topic:_(?P<topic>.*)_Subject:_(?P<subject>.*)_Contains Proprietary Information:_(?P<proprietary>.*)_Question:_(?P<body>.*)$`)
@@ -30,17 +26,38 @@ var questionRegex = regexp.MustCompile(`(?smi)^(.*?)_Topic:_(?P<topic>.*)_Subjec
 type slackClient interface {
 	GetConversationHistory(params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error)
 	GetConversationReplies(params *slack.GetConversationRepliesParameters) (msgs []slack.Message, hasMore bool, nextCursor string, err error)
-	GetUserByEmail(email string) (*slack.User, error)
+	GetUserGroups(options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error)
+	PostMessage(channelID string, options ...slack.MsgOption) (string, string, error)
 }
 
-func FAQHandler(client slackClient, kubeClient ctrlruntimeclient.Client, forumChannelId string) events.PartialHandler {
-	// We only load the authorized users from the test-platform-ci-admins group on startup.
-	// This will result in the tool needing to be restarted if this list membership changes,
-	// but that is extremely infrequent, and the restart is likely to happen naturally in a timely manner anyway
-	authorizedUsers, err := getAuthorizedUsers(client, kubeClient, logrus.WithField("handler", "faq-handler"))
-	if err != nil {
-		logrus.WithError(err).Fatalf("couldn't get authorized users")
+// FAQHandler maintains the FAQ items from the reactions of authorized users. Reaction events are recorded in
+// the buffer when one is given, so that the ones failing on a transient error are replayed.
+func FAQHandler(client slackClient, kubeClient ctrlruntimeclient.Client, configAgent *FAQConfigAgent, buffer *FAQEventBuffer) events.PartialHandler {
+	process := func(data interface{}, log *logrus.Entry) (bool, error) {
+		// the settings are read once per event so that a reload can't change them mid-way
+		settings := configAgent.current()
+		cmClient := helpdeskfaq.NewCMClient(kubeClient)
+		switch event := data.(type) {
+		case *slackevents.ReactionAddedEvent:
+			if !settings.watchesChannel(event.Item.Channel) {
+				log.Debugf("not in correct channel. wanted one of: %v, reaction was in: %s", settings.config.ChannelIDs, event.Item.Channel)
+				return false, nil
+			}
+			return handleReactionAdded(event, client, &cmClient, event.Item.Channel, settings, log)
+		case *slackevents.ReactionRemovedEvent:
+			if !settings.watchesChannel(event.Item.Channel) {
+				log.Debugf("not in correct channel. wanted one of: %v, reaction was in: %s", settings.config.ChannelIDs, event.Item.Channel)
+				return false, nil
+			}
+			return handleReactionRemoved(event, client, &cmClient, event.Item.Channel, settings, log)
+		default:
+			return false, nil
+		}
+	}
+	if buffer != nil {
+		buffer.setProcessor(process)
 	}
+
 	return events.PartialHandlerFunc("helpdesk",
 		func(callback *slackevents.EventsAPIEvent, logger *logrus.Entry) (handled bool, err error) {
 			log := logger.WithField("handler", "helpdesk-faq")
@@ -49,56 +66,59 @@ func FAQHandler(client slackClient, kubeClient ctrlruntimeclient.Client, forumCh
 			if callback.Type != slackevents.CallbackEvent {
 				return false, nil
 			}
+			if buffer == nil {
+				return process(callback.InnerEvent.Data, log)
+			}
 
-			cmClient := helpdeskfaq.NewCMClient(kubeClient)
-			event, added := callback.InnerEvent.Data.(*slackevents.ReactionAddedEvent)
-			if added {
-				if event.Item.Channel != forumChannelId {
-					log.Debugf("not in correct channel. wanted: %s, reaction was in: %s", forumChannelId, event.Item.Channel)
-					return false, nil
+			// only the events the handler acts on are worth replaying
+			settings := configAgent.current()
+			switch event := callback.InnerEvent.Data.(type) {
+			case *slackevents.ReactionAddedEvent:
+				if settings.isFAQReaction(event.Item.Channel, event.Reaction) {
+					return buffer.handle(slackevents.ReactionAdded, reactionEventKey(slackevents.ReactionAdded, event.User, event.EventTimestamp), event, log)
 				}
-				return handleReactionAdded(event, client, &cmClient, forumChannelId, authorizedUsers, log)
-
-			} else {
-				event, removed := callback.InnerEvent.Data.(*slackevents.ReactionRemovedEvent)
-				if removed {
-					if event.Item.Channel != forumChannelId {
-						log.Debugf("not in correct channel. wanted: %s, reaction was in: %s", forumChannelId, event.Item.Channel)
-						return false, nil
-					}
-					return handleReactionRemoved(event, client, &cmClient, forumChannelId, authorizedUsers, log)
-				} else {
-					return false, nil
+			case *slackevents.ReactionRemovedEvent:
+				if settings.isFAQReaction(event.Item.Channel, event.Reaction) {
+					return buffer.handle(slackevents.ReactionRemoved, reactionEventKey(slackevents.ReactionRemoved, event.User, event.EventTimestamp), event, log)
 				}
 			}
+			return process(callback.InnerEvent.Data, log)
 		})
 }
 
-func getAuthorizedUsers(client slackClient, groupClient ctrlruntimeclient.Client, logger *logrus.Entry) ([]string, error) {
-	admins := &userv1.Group{}
-	if err := groupClient.Get(context.TODO(), types.NamespacedName{Name: "test-platform-ci-admins"}, admins); err != nil {
-		logger.WithError(err).Error("unable to get test-platform-ci-admins group")
-		return nil, err
+// getAuthorizedUsers resolves the members of the given groups to their slack user IDs
+func getAuthorizedUsers(resolver *users.Resolver, groupClient ctrlruntimeclient.Client, groups []string, logger *logrus.Entry) ([]string, error) {
+	var emails []string
+	for _, group := range groups {
+		admins := &userv1.Group{}
+		if err := groupClient.Get(context.TODO(), types.NamespacedName{Name: group}, admins); err != nil {
+			logger.WithError(err).Errorf("unable to get %s group", group)
+			return nil, err
+		}
+		for _, admin := range admins.Users {
+			emails = append(emails, fmt.Sprintf("%s@redhat.com", admin))
+		}
+	}
+	resolved, err := resolver.Resolve(emails, logger)
+	if err != nil {
+		// the members that were resolved are still authorized
+		logger.WithError(err).Error("unable to resolve some authorized users")
 	}
 	var slackUsers []string
-	for _, admin := range admins.Users {
-		email := fmt.Sprintf("%s@redhat.com", admin)
-		user, err := client.GetUserByEmail(email)
-		if err != nil {
-			logger.WithError(err).Errorf("unable to get user for email: %s", email)
-			continue
+	for _, email := range emails {
+		if id, ok := resolved[email]; ok && !slices.Contains(slackUsers, id) {
+			slackUsers = append(slackUsers, id)
 		}
-		slackUsers = append(slackUsers, user.ID)
 	}
 	return slackUsers, nil
 }
 
-func handleReactionRemoved(event *slackevents.ReactionRemovedEvent, client slackClient, faqItemClient helpdeskfaq.FaqItemClient, forumChannelId string, authorizedUsers []string, logger *logrus.Entry) (bool, error) {
+func handleReactionRemoved(event *slackevents.ReactionRemovedEvent, client slackClient, faqItemClient helpdeskfaq.FaqItemClient, channelId string, settings faqSettings, logger *logrus.Entry) (bool, error) {
 	logger.Debugf("%s emoji removed from message", event.Reaction)
 	switch event.Reaction {
-	case questionReaction:
+	case settings.config.QuestionReaction:
 		questionLog := logger.WithField("type", "remove-question")
-		if !slices.Contains(authorizedUsers, event.User) {
+		if !settings.isAuthorized(event.User) {
 			questionLog.Infof("user with ID: %s is not authorized", event.User)
 			return false, nil
 		}
@@ -106,15 +126,15 @@ func handleReactionRemoved(event *slackevents.ReactionRemovedEvent, client slack
 			questionLog.WithError(err).Error("unable to update helpdesk-faq config map")
 			return false, err
 		}
-	case answerReaction:
+	case settings.config.AnswerReaction:
 		answerLog := logger.WithField("type", "remove-answer")
-		if !slices.Contains(authorizedUsers, event.User) {
+		if !settings.isAuthorized(event.User) {
 			answerLog.Infof("user with ID: %s is not authorized", event.User)
 			return false, nil
 		}
 		messageTs := event.Item.Timestamp
 		replies, _, _, err := client.GetConversationReplies(&slack.GetConversationRepliesParameters{
-			ChannelID: forumChannelId,
+			ChannelID: channelId,
 			Timestamp: messageTs,
 			Inclusive: true,
 		})
@@ -146,6 +166,8 @@ func handleReactionRemoved(event *slackevents.ReactionRemovedEvent, client slack
 				return false, err
 			}
 		}
+	case settings.config.DocLinkReaction:
+		return handleDocLinkRemoved(event, client, faqItemClient, channelId, settings, logger)
 	default:
 		logger.Debugf("emoji we do not care about: %s", event.Reaction)
 		return false, nil
@@ -154,12 +176,12 @@ func handleReactionRemoved(event *slackevents.ReactionRemovedEvent, client slack
 	return true, nil
 }
 
-func handleReactionAdded(event *slackevents.ReactionAddedEvent, client slackClient, faqItemClient helpdeskfaq.FaqItemClient, forumChannelId string, authorizedUsers []string, logger *logrus.Entry) (bool, error) {
+func handleReactionAdded(event *slackevents.ReactionAddedEvent, client slackClient, faqItemClient helpdeskfaq.FaqItemClient, channelId string, settings faqSettings, logger *logrus.Entry) (bool, error) {
 	logger.Debugf("%s emoji added to message", event.Reaction)
 	switch event.Reaction {
-	case questionReaction:
+	case settings.config.QuestionReaction:
 		questionLog := logger.WithField("type", "add-question")
-		if !slices.Contains(authorizedUsers, event.User) {
+		if !settings.isAuthorized(event.User) {
 			questionLog.Infof("user with ID: %s is not authorized", event.User)
 			return false, nil
 		}
@@ -174,7 +196,7 @@ func handleReactionAdded(event *slackevents.ReactionAddedEvent, client slackClie
 			return false, nil
 		}
 
-		message, err := getTopLevelMessage(client, forumChannelId, messageTs, questionLog)
+		message, err := getTopLevelMessage(client, channelId, messageTs, questionLog)
 		if err != nil {
 			questionLog.WithError(err).Error("unable to get top-level message")
 			return false, err
@@ -190,22 +212,27 @@ func handleReactionAdded(event *slackevents.ReactionAddedEvent, client slackClie
 				questionLog.Errorf("expected to find: topic, subject, and body in question, but some values were missing")
 				return false, nil
 			}
+			topic, known := settings.config.normalizeTopic(formatItemField(topic))
+			if !known {
+				questionLog.Warnf("topic %q is not one of the configured topics: %v", topic, settings.config.Topics)
+			}
 			faqItem := helpdeskfaq.FaqItem{
 				Question: helpdeskfaq.Question{
 					Author:  message.User,
-					Topic:   formatItemField(topic),
+					Topic:   topic,
 					Subject: formatItemField(subject),
 					Body:    formatItemField(body),
 				},
 				Timestamp: messageTs,
 			}
 
+			attributor := newAnswerAttributor(client, settings.config.HelpdeskUserGroup, questionLog)
 			var cursor string
 			var hasMore bool
 			var replies []slack.Message
 			for {
 				replies, hasMore, cursor, err = client.GetConversationReplies(&slack.GetConversationRepliesParameters{
-					ChannelID: forumChannelId,
+					ChannelID: channelId,
 					Timestamp: messageTs,
 					Inclusive: true,
 					Cursor:    cursor,
@@ -217,12 +244,13 @@ func handleReactionAdded(event *slackevents.ReactionAddedEvent, client slackClie
 
 				for _, reply := range replies {
 					for _, reaction := range reply.Reactions {
-						if reaction.Name == answerReaction {
+						if reaction.Name == settings.config.AnswerReaction {
 							questionLog.Debugf("adding pre-marked answer with timestamp: %s", reply.Timestamp)
 							faqItem.Answers = append(faqItem.Answers, helpdeskfaq.Answer{
-								Author:    reply.User,
-								Timestamp: reply.Timestamp,
-								Body:      reply.Msg.Text,
+								Author:      reply.User,
+								Timestamp:   reply.Timestamp,
+								Body:        reply.Msg.Text,
+								Attribution: attributor.attribution(reply.User),
 							})
 						}
 					}
@@ -237,16 +265,19 @@ func handleReactionAdded(event *slackevents.ReactionAddedEvent, client slackClie
 				questionLog.WithError(err).Error("unable to create helpdesk-faq item")
 				return false, err
 			}
+			for _, answer := range faqItem.Answers {
+				warnCurator(client, channelId, messageTs, event.User, answer.Body, settings, questionLog)
+			}
 		}
-	case answerReaction:
+	case settings.config.AnswerReaction:
 		answerLog := logger.WithField("type", "add-answer")
-		if !slices.Contains(authorizedUsers, event.User) {
+		if !settings.isAuthorized(event.User) {
 			answerLog.Infof("user with ID: %s is not authorized", event.User)
 			return false, nil
 		}
 		messageTs := event.Item.Timestamp
 		replies, _, _, err := client.GetConversationReplies(&slack.GetConversationRepliesParameters{
-			ChannelID: forumChannelId,
+			ChannelID: channelId,
 			Timestamp: messageTs,
 			Inclusive: true,
 		})
@@ -274,16 +305,20 @@ func handleReactionAdded(event *slackevents.ReactionAddedEvent, client slackClie
 				}
 			}
 			faqItem.Answers = append(faqItem.Answers, helpdeskfaq.Answer{
-				Author:    reply.User,
-				Timestamp: messageTs,
-				Body:      formatItemField(reply.Msg.Text),
+				Author:      reply.User,
+				Timestamp:   messageTs,
+				Body:        formatItemField(reply.Msg.Text),
+				Attribution: newAnswerAttributor(client, settings.config.HelpdeskUserGroup, answerLog).attribution(reply.User),
 			})
 			if err := faqItemClient.UpsertItem(*faqItem); err != nil {
 				answerLog.WithError(err).Error("unable to update helpdesk-faq item")
 				return false, err
 			}
+			warnCurator(client, channelId, questionTs, event.User, reply.Msg.Text, settings, answerLog)
 
 		}
+	case settings.config.DocLinkReaction:
+		return handleDocLinkAdded(event, client, faqItemClient, channelId, settings, newHTTPLinkChecker(), logger)
 	default:
 		logger.Debugf("emoji we do not care about: %s", event.Reaction)
 		return false, nil
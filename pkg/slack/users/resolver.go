@@ -0,0 +1,161 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// defaultTTL is how long a resolved user is trusted before Resolve looks it up again
+	defaultTTL = 6 * time.Hour
+	// lookupWorkers bounds the concurrent lookups so that a batch stays well within the Slack rate limits
+	lookupWorkers = 4
+)
+
+type userByEmailGetter interface {
+	GetUserByEmail(email string) (*slack.User, error)
+}
+
+type cachedUser struct {
+	id       string
+	resolved time.Time
+}
+
+// Resolver resolves emails to Slack user IDs. Lookups are cached, retried on transient errors,
+// and fall back to the last known ID when Slack can't be reached, so that a flaky API doesn't
+// make users disappear from the handlers relying on it.
+type Resolver struct {
+	client  userByEmailGetter
+	ttl     time.Duration
+	backoff wait.Backoff
+	now     func() time.Time
+
+	lock  sync.RWMutex
+	cache map[string]cachedUser
+}
+
+func NewResolver(client userByEmailGetter) *Resolver {
+	return &Resolver{
+		client:  client,
+		ttl:     defaultTTL,
+		backoff: wait.Backoff{Steps: 5, Factor: 2, Duration: time.Second, Jitter: 0.1},
+		now:     time.Now,
+		cache:   map[string]cachedUser{},
+	}
+}
+
+// Resolve returns the Slack user ID for every email that could be resolved. The error lists
+// the emails that couldn't, the other emails are still returned.
+func (r *Resolver) Resolve(emails []string, logger *logrus.Entry) (map[string]string, error) {
+	ret := map[string]string{}
+	var toLookup []string
+	r.lock.RLock()
+	for _, email := range emails {
+		if cached, ok := r.cache[email]; ok && r.now().Sub(cached.resolved) < r.ttl {
+			ret[email] = cached.id
+		} else {
+			toLookup = append(toLookup, email)
+		}
+	}
+	r.lock.RUnlock()
+
+	ids, errs := r.lookup(toLookup, logger)
+	for email, id := range ids {
+		ret[email] = id
+	}
+	return ret, utilerrors.NewAggregate(errs)
+}
+
+func (r *Resolver) lookup(emails []string, logger *logrus.Entry) (map[string]string, []error) {
+	type result struct {
+		email string
+		id    string
+		err   error
+	}
+	emailCh := make(chan string, len(emails))
+	for _, email := range emails {
+		emailCh <- email
+	}
+	close(emailCh)
+
+	resultCh := make(chan result, len(emails))
+	wg := sync.WaitGroup{}
+	for i := 0; i < lookupWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for email := range emailCh {
+				id, err := r.lookupWithRetries(email)
+				resultCh <- result{email: email, id: id, err: err}
+			}
+		}()
+	}
+	wg.Wait()
+	close(resultCh)
+
+	ret := map[string]string{}
+	var errs []error
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for res := range resultCh {
+		if res.err == nil {
+			r.cache[res.email] = cachedUser{id: res.id, resolved: r.now()}
+			ret[res.email] = res.id
+			continue
+		}
+		if cached, ok := r.cache[res.email]; ok && isRetryable(res.err) {
+			logger.WithError(res.err).Warnf("unable to refresh user for email: %s, using the last known user", res.email)
+			ret[res.email] = cached.id
+			continue
+		}
+		// the user is gone for good, don't keep authorizing it
+		delete(r.cache, res.email)
+		errs = append(errs, fmt.Errorf("unable to get user for email %s: %w", res.email, res.err))
+	}
+	return ret, errs
+}
+
+func (r *Resolver) lookupWithRetries(email string) (string, error) {
+	var id string
+	var lastErr error
+	err := wait.ExponentialBackoff(r.backoff, func() (bool, error) {
+		user, err := r.client.GetUserByEmail(email)
+		if err == nil {
+			id = user.ID
+			return true, nil
+		}
+		lastErr = err
+		if !isRetryable(err) {
+			return false, err
+		}
+		var rateLimited *slack.RateLimitedError
+		if errors.As(err, &rateLimited) {
+			time.Sleep(rateLimited.RetryAfter)
+		}
+		return false, nil
+	})
+	if wait.Interrupted(err) {
+		return "", lastErr
+	}
+	return id, err
+}
+
+// isRetryable tells transient failures, like rate limiting or server and network errors,
+// apart from Slack answering that the user doesn't exist
+func isRetryable(err error) bool {
+	var retryable interface{ Retryable() bool }
+	if errors.As(err, &retryable) {
+		return retryable.Retryable()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
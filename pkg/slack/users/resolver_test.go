@@ -0,0 +1,144 @@
+package users
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type fakeClient struct {
+	lock  sync.Mutex
+	calls map[string]int
+	// responses are returned in order for every email, the last one is repeated
+	responses map[string][]response
+}
+
+type response struct {
+	id  string
+	err error
+}
+
+func (c *fakeClient) GetUserByEmail(email string) (*slack.User, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	responses := c.responses[email]
+	resp := responses[min(c.calls[email], len(responses)-1)]
+	c.calls[email]++
+	if resp.err != nil {
+		return nil, resp.err
+	}
+	return &slack.User{ID: resp.id}, nil
+}
+
+type retryableError struct{}
+
+func (retryableError) Error() string   { return "slack server error: 503 Service Unavailable" }
+func (retryableError) Retryable() bool { return true }
+
+func TestResolve(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name          string
+		responses     map[string][]response
+		cache         map[string]cachedUser
+		emails        []string
+		expected      map[string]string
+		expectedErr   string
+		expectedCalls map[string]int
+	}{
+		{
+			name: "users are looked up",
+			responses: map[string][]response{
+				"a@redhat.com": {{id: "UA"}},
+				"b@redhat.com": {{id: "UB"}},
+			},
+			emails:        []string{"a@redhat.com", "b@redhat.com"},
+			expected:      map[string]string{"a@redhat.com": "UA", "b@redhat.com": "UB"},
+			expectedCalls: map[string]int{"a@redhat.com": 1, "b@redhat.com": 1},
+		},
+		{
+			name: "fresh cached users are not looked up",
+			responses: map[string][]response{
+				"a@redhat.com": {{id: "UA"}},
+			},
+			cache:         map[string]cachedUser{"a@redhat.com": {id: "UA-cached", resolved: now.Add(-time.Hour)}},
+			emails:        []string{"a@redhat.com"},
+			expected:      map[string]string{"a@redhat.com": "UA-cached"},
+			expectedCalls: map[string]int{},
+		},
+		{
+			name: "stale cached users are revalidated",
+			responses: map[string][]response{
+				"a@redhat.com": {{id: "UA"}},
+			},
+			cache:         map[string]cachedUser{"a@redhat.com": {id: "UA-cached", resolved: now.Add(-defaultTTL)}},
+			emails:        []string{"a@redhat.com"},
+			expected:      map[string]string{"a@redhat.com": "UA"},
+			expectedCalls: map[string]int{"a@redhat.com": 1},
+		},
+		{
+			name: "transient errors are retried",
+			responses: map[string][]response{
+				"a@redhat.com": {{err: retryableError{}}, {err: &slack.RateLimitedError{}}, {id: "UA"}},
+			},
+			emails:        []string{"a@redhat.com"},
+			expected:      map[string]string{"a@redhat.com": "UA"},
+			expectedCalls: map[string]int{"a@redhat.com": 3},
+		},
+		{
+			name: "stale cached user is used when slack keeps failing",
+			responses: map[string][]response{
+				"a@redhat.com": {{err: retryableError{}}},
+			},
+			cache:         map[string]cachedUser{"a@redhat.com": {id: "UA-cached", resolved: now.Add(-defaultTTL)}},
+			emails:        []string{"a@redhat.com"},
+			expected:      map[string]string{"a@redhat.com": "UA-cached"},
+			expectedCalls: map[string]int{"a@redhat.com": 3},
+		},
+		{
+			name: "missing users are reported and the others returned",
+			responses: map[string][]response{
+				"a@redhat.com": {{id: "UA"}},
+				"b@redhat.com": {{err: errors.New("users_not_found")}},
+			},
+			cache:         map[string]cachedUser{"b@redhat.com": {id: "UB-cached", resolved: now.Add(-defaultTTL)}},
+			emails:        []string{"a@redhat.com", "b@redhat.com"},
+			expected:      map[string]string{"a@redhat.com": "UA"},
+			expectedErr:   "unable to get user for email b@redhat.com: users_not_found",
+			expectedCalls: map[string]int{"a@redhat.com": 1, "b@redhat.com": 1},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeClient{calls: map[string]int{}, responses: tc.responses}
+			resolver := NewResolver(client)
+			resolver.backoff = wait.Backoff{Steps: 3, Duration: time.Millisecond}
+			resolver.now = func() time.Time { return now }
+			if tc.cache != nil {
+				resolver.cache = tc.cache
+			}
+
+			actual, err := resolver.Resolve(tc.emails, logrus.NewEntry(logrus.New()))
+			var actualErr string
+			if err != nil {
+				actualErr = err.Error()
+			}
+			if actualErr != tc.expectedErr {
+				t.Fatalf("expected error %q, got %q", tc.expectedErr, actualErr)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("resolved users don't match expected, diff: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedCalls, client.calls); diff != "" {
+				t.Errorf("lookups don't match expected, diff: %s", diff)
+			}
+		})
+	}
+}